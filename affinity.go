@@ -0,0 +1,69 @@
+package dispatch
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// maxAffinityEntries bounds the affinity cache so high-cardinality
+// fingerprints cannot grow it without limit. Once full, new fingerprints are
+// matched normally but not cached.
+const maxAffinityEntries = 4096
+
+// WithSourceAffinity caches which source matched each message fingerprint,
+// so repeat message types skip discriminator evaluation entirely after the
+// first sighting.
+//
+// fingerprint must be cheap and must identify the source: two messages with
+// the same fingerprint are always routed to the same source. It returns false
+// for messages that should be matched normally. The cache is cleared when
+// sources are added.
+//
+// Example:
+//
+//	r := dispatch.New(dispatch.WithSourceAffinity(dispatch.JSONFingerprint("source", "detail-type")))
+func WithSourceAffinity(fingerprint func(raw []byte) (string, bool)) Option {
+	return func(r *Router) {
+		r.fingerprint = fingerprint
+	}
+}
+
+// JSONFingerprint returns a fingerprint function for WithSourceAffinity that
+// joins the string values at the given JSON paths. It returns false if any
+// path is missing or not a string.
+func JSONFingerprint(paths ...string) func(raw []byte) (string, bool) {
+	return func(raw []byte) (string, bool) {
+		var b strings.Builder
+		for i, r := range gjson.GetManyBytes(raw, paths...) {
+			if r.Type != gjson.String {
+				return "", false
+			}
+			if i > 0 {
+				b.WriteByte(0)
+			}
+			b.WriteString(r.Str)
+		}
+		return b.String(), true
+	}
+}
+
+// affinityFor returns the source cached for fingerprint fp.
+func (idx *matchIndex) affinityFor(fp string) (*compiledSource, bool) {
+	v, ok := idx.affinity.Load(fp)
+	if !ok {
+		return nil, false
+	}
+	return v.(*compiledSource), true
+}
+
+// remember caches cs as the source for fingerprint fp, unless the cache is
+// full.
+func (idx *matchIndex) remember(fp string, cs *compiledSource) {
+	if idx.affinitySize.Load() >= maxAffinityEntries {
+		return
+	}
+	if _, loaded := idx.affinity.LoadOrStore(fp, cs); !loaded {
+		idx.affinitySize.Add(1)
+	}
+}
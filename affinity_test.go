@@ -0,0 +1,81 @@
+package dispatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SourceAffinitySuite struct {
+	suite.Suite
+}
+
+func TestSourceAffinitySuite(t *testing.T) {
+	suite.Run(t, new(SourceAffinitySuite))
+}
+
+// countingDiscriminator counts Match calls.
+type countingDiscriminator struct {
+	Discriminator
+	calls *int
+}
+
+func (d countingDiscriminator) Match(v View) bool {
+	*d.calls++
+	return d.Discriminator.Match(v)
+}
+
+func (s *SourceAffinitySuite) TestJSONFingerprint() {
+	fp := JSONFingerprint("source", "detail-type")
+
+	got, ok := fp([]byte(`{"source": "billing", "detail-type": "Invoice"}`))
+	s.Require().True(ok)
+	s.Assert().Equal("billing\x00Invoice", got)
+
+	_, ok = fp([]byte(`{"source": "billing"}`))
+	s.Assert().False(ok)
+
+	_, ok = fp([]byte(`{"source": "billing", "detail-type": 1}`))
+	s.Assert().False(ok)
+}
+
+func (s *SourceAffinitySuite) TestSkipsDiscriminatorsAfterFirstSighting() {
+	var calls int
+	r := New(WithSourceAffinity(JSONFingerprint("kind")))
+	r.AddSource(SourceFunc("a", countingDiscriminator{FieldEquals("kind", "a"), &calls}, nil))
+	r.AddSource(SourceFunc("b", countingDiscriminator{FieldEquals("kind", "b"), &calls}, nil))
+
+	msg := []byte(`{"kind": "b"}`)
+	src, _ := r.match(msg)
+	s.Require().Equal("b", src.Name())
+	first := calls
+	s.Require().Positive(first)
+
+	for range 10 {
+		src, view := r.match(msg)
+		s.Require().Equal("b", src.Name())
+		s.Require().NotNil(view)
+	}
+	s.Assert().Equal(first, calls)
+}
+
+func (s *SourceAffinitySuite) TestUnfingerprintedMessagesMatchNormally() {
+	r := New(WithSourceAffinity(JSONFingerprint("kind")))
+	r.AddSource(SourceFunc("a", HasFields("other"), nil))
+
+	src, _ := r.match([]byte(`{"other": true}`))
+	s.Require().NotNil(src)
+	s.Assert().Equal("a", src.Name())
+}
+
+func (s *SourceAffinitySuite) TestCacheIsBounded() {
+	r := New(WithSourceAffinity(JSONFingerprint("id")))
+	r.AddSource(SourceFunc("a", HasFields("id"), nil))
+
+	for i := range maxAffinityEntries + 10 {
+		_, _ = r.match(fmt.Appendf(nil, `{"id": "%d"}`, i))
+	}
+
+	s.Assert().Equal(int64(maxAffinityEntries), r.matchIndex().affinitySize.Load())
+}
@@ -0,0 +1,254 @@
+package dispatch
+
+import (
+	"encoding"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AsyncAPIInfo describes the application in a generated AsyncAPI document.
+type AsyncAPIInfo struct {
+	Title       string
+	Version     string
+	Description string
+}
+
+// AsyncAPI returns an AsyncAPI 3.0 document, as JSON, describing the
+// messages d consumes, so consumer contracts can be published from code.
+//
+// Each registered key becomes a channel whose address is the key, with a
+// receive operation and a message whose payload schema is derived from the
+// handler's payload type, or taken from WithJSONSchema if given. Handlers
+// registered with RegisterFunc also declare a reply message for their
+// result. Source names are listed under the x-dispatch-sources extension.
+//
+// Payload schemas follow encoding/json: exported fields named by their json
+// tags, fields without omitempty or omitzero required, and named struct
+// types shared under components.schemas.
+//
+// Example:
+//
+//	doc, err := dispatch.AsyncAPI(r, dispatch.AsyncAPIInfo{Title: "users", Version: "1.0.0"})
+func AsyncAPI(d Dispatcher, info AsyncAPIInfo) ([]byte, error) {
+	r := d.router()
+	g := &schemaGen{defs: make(map[string]any), names: make(map[reflect.Type]string)}
+
+	channels := make(map[string]any)
+	operations := make(map[string]any)
+	messages := make(map[string]any)
+	for _, key := range slices.Sorted(maps.Keys(r.handlerTypes)) {
+		ht := r.handlerTypes[key]
+		id := asyncAPIID(key)
+
+		var payload any = ht.schema
+		if ht.schema == nil {
+			payload = g.schema(ht.payload)
+		}
+		messages[id] = map[string]any{
+			"name":        key,
+			"payload":     payload,
+			"contentType": "application/json",
+		}
+		channelMessages := map[string]any{id: ref("#/components/messages/" + id)}
+
+		op := map[string]any{
+			"action":   "receive",
+			"channel":  ref("#/channels/" + id),
+			"messages": []any{ref("#/channels/" + id + "/messages/" + id)},
+		}
+		if ht.result != nil {
+			replyID := id + ".reply"
+			messages[replyID] = map[string]any{
+				"name":        key + " reply",
+				"payload":     g.schema(ht.result),
+				"contentType": "application/json",
+			}
+			channelMessages[replyID] = ref("#/components/messages/" + replyID)
+			op["reply"] = map[string]any{
+				"address":  map[string]any{"location": "$message.header#/replyTo"},
+				"messages": []any{ref("#/channels/" + id + "/messages/" + replyID)},
+			}
+		}
+
+		channels[id] = map[string]any{
+			"address":  key,
+			"messages": channelMessages,
+		}
+		operations["receive."+id] = op
+	}
+
+	infoDoc := map[string]any{"title": info.Title, "version": info.Version}
+	if info.Description != "" {
+		infoDoc["description"] = info.Description
+	}
+	components := map[string]any{"messages": messages}
+	if len(g.defs) > 0 {
+		components["schemas"] = g.defs
+	}
+	doc := map[string]any{
+		"asyncapi":           "3.0.0",
+		"info":               infoDoc,
+		"channels":           channels,
+		"operations":         operations,
+		"components":         components,
+		"x-dispatch-sources": r.sourceNames(),
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// sourceNames returns the names of r's sources in registration order.
+func (r *Router) sourceNames() []string {
+	names := []string{}
+	for _, src := range r.defaultSources {
+		names = append(names, src.Name())
+	}
+	for _, g := range r.groups {
+		for _, src := range g.sources {
+			names = append(names, src.Name())
+		}
+	}
+	return names
+}
+
+// invalidIDChars matches characters not allowed in AsyncAPI component keys.
+var invalidIDChars = regexp.MustCompile(`[^a-zA-Z0-9.\-_]`)
+
+func asyncAPIID(key string) string {
+	return invalidIDChars.ReplaceAllString(key, "_")
+}
+
+func ref(path string) map[string]any {
+	return map[string]any{"$ref": path}
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// schemaGen derives JSON Schemas from Go types, collecting named struct
+// types into defs so they are described once and may be recursive.
+type schemaGen struct {
+	defs  map[string]any
+	names map[reflect.Type]string
+}
+
+func (g *schemaGen) schema(t reflect.Type) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return map[string]any{}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return map[string]any{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name, ok := g.names[t]
+		if !ok {
+			name = g.defName(t)
+			g.names[t] = name
+			g.defs[name] = nil // reserve the name for recursive types
+			g.defs[name] = g.object(t)
+		}
+		return ref("#/components/schemas/" + name)
+	default:
+		return map[string]any{}
+	}
+}
+
+// defName returns a unique components.schemas key for the named type t.
+func (g *schemaGen) defName(t reflect.Type) string {
+	name := asyncAPIID(t.Name())
+	if _, taken := g.defs[name]; !taken {
+		return name
+	}
+	pkg := t.PkgPath()
+	name = asyncAPIID(pkg[strings.LastIndex(pkg, "/")+1:] + "." + t.Name())
+	for i := 2; ; i++ {
+		if _, taken := g.defs[name]; !taken {
+			return name
+		}
+		name = asyncAPIID(t.Name()) + "_" + strconv.Itoa(i)
+	}
+}
+
+// object describes a struct the way encoding/json marshals it.
+func (g *schemaGen) object(t reflect.Type) map[string]any {
+	props := make(map[string]any)
+	required := []string{}
+	g.fields(t, props, &required)
+	obj := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		slices.Sort(required)
+		obj["required"] = required
+	}
+	return obj
+}
+
+func (g *schemaGen) fields(t reflect.Type, props map[string]any, required *[]string) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, props, required)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = g.schema(f.Type)
+		optional := slices.ContainsFunc(strings.Split(opts, ","), func(o string) bool {
+			return o == "omitempty" || o == "omitzero"
+		})
+		if !optional && f.Type.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type asyncAPIBase struct {
+	ID string `json:"id"`
+}
+
+type asyncAPINode struct {
+	asyncAPIBase
+	Name     string            `json:"name"`
+	Note     string            `json:"note,omitempty"`
+	Parent   *asyncAPINode     `json:"parent"`
+	Children []asyncAPINode    `json:"children,omitempty"`
+	Tags     map[string]int    `json:"tags,omitzero"`
+	At       time.Time         `json:"at"`
+	Data     []byte            `json:"data,omitempty"`
+	Extra    json.RawMessage   `json:"extra,omitempty"`
+	Anon     struct{ X bool }  `json:"anon"`
+	Skipped  string            `json:"-"`
+	Labels   map[string]string `json:",omitempty"`
+}
+
+type AsyncAPISuite struct {
+	suite.Suite
+	doc map[string]any
+}
+
+func TestAsyncAPISuite(t *testing.T) {
+	suite.Run(t, new(AsyncAPISuite))
+}
+
+func (s *AsyncAPISuite) SetupTest() {
+	r := New()
+	r.AddSource(&testSource{name: "test"})
+	r.AddGroup(JSONInspector(), &testSource{name: "other"})
+	RegisterProc(r, "user/created", &testHandler{})
+	RegisterFuncFunc(r, "node", func(ctx context.Context, n asyncAPINode) (float64, error) { return 0, nil })
+	RegisterProcFunc(r, "schema", func(ctx context.Context, p testPayload) error { return nil },
+		WithJSONSchema([]byte(`{"type": "object", "required": ["value"]}`)))
+
+	data, err := AsyncAPI(r, AsyncAPIInfo{Title: "users", Version: "1.0.0", Description: "User events"})
+	s.Require().NoError(err)
+	s.Require().NoError(json.Unmarshal(data, &s.doc))
+}
+
+// get walks the document along path.
+func (s *AsyncAPISuite) get(path ...string) any {
+	var v any = s.doc
+	for _, p := range path {
+		m, ok := v.(map[string]any)
+		s.Require().True(ok, "%v is not an object at %q", path, p)
+		v, ok = m[p]
+		s.Require().True(ok, "%v has no %q", path, p)
+	}
+	return v
+}
+
+func (s *AsyncAPISuite) TestDocument() {
+	s.Assert().Equal("3.0.0", s.get("asyncapi"))
+	s.Assert().Equal(map[string]any{"title": "users", "version": "1.0.0", "description": "User events"}, s.get("info"))
+	s.Assert().Equal([]any{"test", "other"}, s.get("x-dispatch-sources"))
+}
+
+func (s *AsyncAPISuite) TestProcChannel() {
+	s.Assert().Equal("user/created", s.get("channels", "user_created", "address"))
+	s.Assert().Equal(map[string]any{
+		"action":   "receive",
+		"channel":  map[string]any{"$ref": "#/channels/user_created"},
+		"messages": []any{map[string]any{"$ref": "#/channels/user_created/messages/user_created"}},
+	}, s.get("operations", "receive.user_created"))
+	s.Assert().Equal("#/components/schemas/testPayload", s.get("components", "messages", "user_created", "payload", "$ref"))
+	s.Assert().Equal(map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"value": map[string]any{"type": "string"}},
+		"required":   []any{"value"},
+	}, s.get("components", "schemas", "testPayload"))
+}
+
+func (s *AsyncAPISuite) TestFuncReply() {
+	s.Assert().Equal(map[string]any{
+		"address":  map[string]any{"location": "$message.header#/replyTo"},
+		"messages": []any{map[string]any{"$ref": "#/channels/node/messages/node.reply"}},
+	}, s.get("operations", "receive.node", "reply"))
+	s.Assert().Equal(map[string]any{"type": "number"}, s.get("components", "messages", "node.reply", "payload"))
+}
+
+func (s *AsyncAPISuite) TestJSONSchemaPayload() {
+	s.Assert().Equal(map[string]any{"type": "object", "required": []any{"value"}}, s.get("components", "messages", "schema", "payload"))
+}
+
+func (s *AsyncAPISuite) TestStructSchema() {
+	node := s.get("components", "schemas", "asyncAPINode").(map[string]any)
+	props := node["properties"].(map[string]any)
+
+	s.Assert().Equal([]any{"anon", "at", "id", "name"}, node["required"])
+	s.Assert().ElementsMatch([]string{"id", "name", "note", "parent", "children", "tags", "at", "data", "extra", "anon", "Labels"}, keysOf(props))
+	s.Assert().Equal(map[string]any{"$ref": "#/components/schemas/asyncAPINode"}, props["parent"])
+	s.Assert().Equal(map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/asyncAPINode"}}, props["children"])
+	s.Assert().Equal(map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "integer"}}, props["tags"])
+	s.Assert().Equal(map[string]any{"type": "string", "format": "date-time"}, props["at"])
+	s.Assert().Equal(map[string]any{"type": "string", "contentEncoding": "base64"}, props["data"])
+	s.Assert().Equal(map[string]any{}, props["extra"])
+	s.Assert().Equal(map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"X": map[string]any{"type": "boolean"}},
+		"required":   []any{"X"},
+	}, props["anon"])
+}
+
+func keysOf(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
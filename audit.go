@@ -0,0 +1,166 @@
+package dispatch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AuditOutcome describes how processing of an audited message ended.
+type AuditOutcome string
+
+// Audit outcomes.
+const (
+	// AuditSuccess means the handler ran and returned no error.
+	AuditSuccess AuditOutcome = "success"
+
+	// AuditFailure means the handler ran and returned an error.
+	AuditFailure AuditOutcome = "failure"
+
+	// AuditSkipped means a policy hook or WithMaxMessageAge skipped the
+	// message instead of running the handler.
+	AuditSkipped AuditOutcome = "skipped"
+
+	// AuditRejected means the message failed before a handler ran, for
+	// example because it could not be parsed or had no handler.
+	AuditRejected AuditOutcome = "rejected"
+)
+
+// AuditRecord is the canonical record produced for each processed message.
+type AuditRecord struct {
+	// Time is when the record was produced.
+	Time time.Time
+
+	// MessageID identifies the message. It is Message.MessageID unless
+	// WithAuditMessageID is set.
+	MessageID string
+
+	// CorrelationID is the message's CorrelationID.
+	CorrelationID string
+
+	// Source is the name of the source that parsed the message.
+	Source string
+
+	// Key is the routing key. It is empty if the message failed to parse.
+	Key string
+
+	// Version is the payload schema version, if the source provided one.
+	Version string
+
+	// Outcome describes how processing ended.
+	Outcome AuditOutcome
+
+	// Duration is how long the handler ran. It is zero for skipped and
+	// rejected messages.
+	Duration time.Duration
+
+	// PayloadHash is the hex-encoded SHA-256 of the payload. It is empty if
+	// the message failed to parse.
+	PayloadHash string
+
+	// Error is the error message for failed, skipped, and rejected messages.
+	Error string
+}
+
+// AuditSink receives audit records. Write is called synchronously on the
+// processing goroutine, so slow sinks should buffer.
+type AuditSink interface {
+	Write(ctx context.Context, rec AuditRecord)
+}
+
+// AuditSinkFunc is a function adapter for AuditSink.
+type AuditSinkFunc func(ctx context.Context, rec AuditRecord)
+
+// Write implements the AuditSink interface.
+func (f AuditSinkFunc) Write(ctx context.Context, rec AuditRecord) {
+	f(ctx, rec)
+}
+
+// AuditOption configures WithAudit.
+type AuditOption func(*auditConfig)
+
+type auditConfig struct {
+	messageID func(Message) string
+}
+
+// WithAuditMessageID sets the function used to fill AuditRecord.MessageID
+// from the parsed message, for sources that don't set Message.MessageID.
+func WithAuditMessageID(fn func(Message) string) AuditOption {
+	return func(c *auditConfig) {
+		c.messageID = fn
+	}
+}
+
+// WithAudit writes an AuditRecord to sink for every message that matched a
+// source, whether it succeeded, failed, was skipped by a policy hook, or was
+// rejected before reaching a handler. A handler or reply error that a
+// WithOnError hook skips is recorded twice: once when the handler finishes,
+// and again as skipped.
+//
+// Example:
+//
+//	r := dispatch.New(
+//	    dispatch.WithAudit(dispatch.AuditSinkFunc(func(ctx context.Context, rec dispatch.AuditRecord) {
+//	        auditLog.Append(ctx, rec)
+//	    })),
+//	)
+func WithAudit(sink AuditSink, opts ...AuditOption) Option {
+	var cfg auditConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	write := func(ctx context.Context, source, key string, outcome AuditOutcome, err error, d time.Duration) {
+		rec := AuditRecord{
+			Time:     time.Now(),
+			Source:   source,
+			Key:      key,
+			Outcome:  outcome,
+			Duration: d,
+		}
+		if err != nil {
+			rec.Error = err.Error()
+		}
+		if msg, ok := MessageFromContext(ctx); ok {
+			rec.Version = msg.Version
+			rec.MessageID = msg.MessageID
+			rec.CorrelationID = msg.CorrelationID
+			sum := sha256.Sum256(msg.Payload)
+			rec.PayloadHash = hex.EncodeToString(sum[:])
+			if cfg.messageID != nil {
+				rec.MessageID = cfg.messageID(msg)
+			}
+		}
+		sink.Write(ctx, rec)
+	}
+
+	return func(r *Router) {
+		r.hooks.onSuccess = append(r.hooks.onSuccess, func(ctx context.Context, source, key string, d time.Duration) {
+			write(ctx, source, key, AuditSuccess, nil, d)
+		})
+		r.hooks.onFailure = append(r.hooks.onFailure, func(ctx context.Context, source, key string, err error, d time.Duration) {
+			write(ctx, source, key, AuditFailure, err, d)
+		})
+		r.hooks.onSkip = append(r.hooks.onSkip, func(ctx context.Context, source, key string, cause error) {
+			if source == "" {
+				return // no source matched; nothing to audit
+			}
+			write(ctx, source, key, AuditSkipped, cause, 0)
+		})
+		r.hooks.onExpired = append(r.hooks.onExpired, func(ctx context.Context, source, key string, age time.Duration) {
+			write(ctx, source, key, AuditSkipped, fmt.Errorf("message expired: age %s", age), 0)
+		})
+		r.hooks.onDuplicate = append(r.hooks.onDuplicate, func(ctx context.Context, source, key string) {
+			write(ctx, source, key, AuditSkipped, errors.New("duplicate message"), 0)
+		})
+		r.hooks.onDisabled = append(r.hooks.onDisabled, func(ctx context.Context, source, key string) {
+			write(ctx, source, key, AuditSkipped, errors.New("handler disabled"), 0)
+		})
+		r.hooks.onReject = append(r.hooks.onReject, func(ctx context.Context, source, key string, err error) {
+			write(ctx, source, key, AuditRejected, err, 0)
+		})
+	}
+}
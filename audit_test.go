@@ -0,0 +1,119 @@
+package dispatch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditSuite struct {
+	suite.Suite
+	records []AuditRecord
+	sink    AuditSink
+}
+
+func (s *AuditSuite) SetupTest() {
+	s.records = nil
+	s.sink = AuditSinkFunc(func(ctx context.Context, rec AuditRecord) {
+		s.records = append(s.records, rec)
+	})
+}
+
+func TestAuditSuite(t *testing.T) {
+	suite.Run(t, new(AuditSuite))
+}
+
+func (s *AuditSuite) TestRecordsSuccess() {
+	r := New(WithAudit(s.sink, WithAuditMessageID(func(msg Message) string {
+		return "msg-" + msg.Key
+	})))
+	r.AddSource(&testSource{name: "test"})
+	RegisterProc(r, "test/event", &testHandler{})
+
+	err := r.Process(context.Background(), []byte(`{"type": "test/event", "payload": {"id": 1}}`))
+
+	s.Require().NoError(err)
+	s.Require().Len(s.records, 1)
+	rec := s.records[0]
+	sum := sha256.Sum256([]byte(`{"id": 1}`))
+	s.Assert().Equal("msg-test/event", rec.MessageID)
+	s.Assert().Equal("test", rec.Source)
+	s.Assert().Equal("test/event", rec.Key)
+	s.Assert().Equal(AuditSuccess, rec.Outcome)
+	s.Assert().Equal(hex.EncodeToString(sum[:]), rec.PayloadHash)
+	s.Assert().Empty(rec.Error)
+	s.Assert().False(rec.Time.IsZero())
+}
+
+func (s *AuditSuite) TestRecordsFailure() {
+	r := New(WithAudit(s.sink))
+	r.AddSource(&testSource{name: "test"})
+	RegisterProc(r, "test/event", &testHandler{err: errors.New("boom")})
+
+	_ = r.Process(context.Background(), []byte(`{"type": "test/event", "payload": {}}`))
+
+	s.Require().Len(s.records, 1)
+	s.Assert().Equal(AuditFailure, s.records[0].Outcome)
+	s.Assert().Equal("boom", s.records[0].Error)
+	s.Assert().Empty(s.records[0].MessageID)
+}
+
+func (s *AuditSuite) TestRecordsSkipped() {
+	r := New(
+		WithAudit(s.sink),
+		WithOnNoHandler(func(ctx context.Context, source, key string) error { return nil }),
+	)
+	r.AddSource(&testSource{name: "test"})
+
+	err := r.Process(context.Background(), []byte(`{"type": "unknown", "payload": {}}`))
+
+	s.Require().NoError(err)
+	s.Require().Len(s.records, 1)
+	s.Assert().Equal(AuditSkipped, s.records[0].Outcome)
+	s.Assert().Equal("unknown", s.records[0].Key)
+	s.Assert().NotEmpty(s.records[0].PayloadHash)
+	s.Assert().Zero(s.records[0].Duration)
+}
+
+func (s *AuditSuite) TestRecordsRejected() {
+	r := New(WithAudit(s.sink))
+	r.AddSource(&testSource{name: "test"})
+
+	err := r.Process(context.Background(), []byte(`{"type": "", "payload": {}}`))
+
+	s.Require().Error(err)
+	s.Require().Len(s.records, 1)
+	s.Assert().Equal(AuditRejected, s.records[0].Outcome)
+	s.Assert().Equal("test", s.records[0].Source)
+	s.Assert().Empty(s.records[0].Key)
+	s.Assert().Empty(s.records[0].PayloadHash)
+	s.Assert().Equal(err.Error(), s.records[0].Error)
+}
+
+func (s *AuditSuite) TestIgnoresUnmatchedMessages() {
+	r := New(
+		WithAudit(s.sink),
+		WithOnNoSource(func(ctx context.Context, raw []byte) error { return nil }),
+	)
+	r.AddSource(&testSource{name: "test"})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"other": true}`)))
+	s.Assert().Empty(s.records)
+}
+
+func (s *AuditSuite) TestUsesMessageIDs() {
+	r := New(WithAudit(s.sink))
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", MessageID: "m-1", CorrelationID: "c-1", Payload: []byte(`{}`)}, nil
+	}))
+	RegisterProc(r, "test", &testHandler{})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test"}`)))
+	s.Require().Len(s.records, 1)
+	s.Assert().Equal("m-1", s.records[0].MessageID)
+	s.Assert().Equal("c-1", s.records[0].CorrelationID)
+}
@@ -0,0 +1,156 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrBackpressure marks failures from a downstream that asked callers to
+// slow down, such as a throttled API. Errors returned by Backpressure wrap
+// it.
+var ErrBackpressure = errors.New("backpressure")
+
+// Backpressure returns an error that fails the message with err and asks
+// whoever feeds the router to pause for d before starting more messages,
+// instead of hammering a throttled downstream. The workers started with
+// StartWorkers pause on their own; other runners, such as an SQS poll loop,
+// read the delay with BackpressureDelay. If err is nil, ErrBackpressure is
+// used.
+//
+// Example:
+//
+//	func (p *SyncProc) Run(ctx context.Context, in Account) error {
+//	    err := p.crm.Update(ctx, in)
+//	    var throttled *crm.ThrottledError
+//	    if errors.As(err, &throttled) {
+//	        return dispatch.Backpressure(throttled.RetryAfter, err)
+//	    }
+//	    return err
+//	}
+func Backpressure(d time.Duration, err error) error {
+	if err == nil {
+		err = ErrBackpressure
+	}
+	return &backpressureError{delay: d, err: err}
+}
+
+// BackpressureDelay reports how long to pause if err, or an error it wraps,
+// was returned by Backpressure. Runners call it with the result of Process.
+//
+// Example:
+//
+//	if err := r.Process(ctx, body); err != nil {
+//	    if d, ok := dispatch.BackpressureDelay(err); ok {
+//	        time.Sleep(d) // stop polling while the downstream recovers
+//	    }
+//	}
+func BackpressureDelay(err error) (time.Duration, bool) {
+	var bp *backpressureError
+	if !errors.As(err, &bp) {
+		return 0, false
+	}
+	return bp.delay, true
+}
+
+// backpressureError carries a pause request without changing err's message.
+type backpressureError struct {
+	delay time.Duration
+	err   error
+}
+
+func (e *backpressureError) Error() string        { return e.err.Error() }
+func (e *backpressureError) Unwrap() error        { return e.err }
+func (e *backpressureError) Is(target error) bool { return target == ErrBackpressure }
+
+// OnPauseFunc is called when a message returns a Backpressure error and the
+// workers pause, or extend their pause, for d.
+type OnPauseFunc func(ctx context.Context, source, key string, d time.Duration)
+
+// OnResumeFunc is called when the workers resume after a pause.
+type OnResumeFunc func(ctx context.Context)
+
+// WithOnPause adds a hook called when the workers started with StartWorkers
+// pause because a handler returned a Backpressure error. Multiple hooks are
+// called in order.
+func WithOnPause(fn OnPauseFunc) Option {
+	return func(r *Router) {
+		r.hooks.onPause = append(r.hooks.onPause, fn)
+	}
+}
+
+// WithOnResume adds a hook called when the workers resume after a pause.
+// Multiple hooks are called in order.
+func WithOnResume(fn OnResumeFunc) Option {
+	return func(r *Router) {
+		r.hooks.onResume = append(r.hooks.onResume, fn)
+	}
+}
+
+// pauseFor pauses p's workers if err asks for backpressure, extending any
+// current pause, and calls the pause hooks.
+func (r *Router) pauseFor(ctx context.Context, p *workerPool, err error) {
+	d, ok := BackpressureDelay(err)
+	if !ok || d <= 0 {
+		return
+	}
+	until := time.Now().Add(d)
+
+	p.pauseMu.Lock()
+	if !until.After(p.resumeAt) {
+		p.pauseMu.Unlock()
+		return
+	}
+	p.resumeAt = until
+	if p.resumeTimer == nil {
+		p.resumeTimer = time.AfterFunc(d, func() { r.resume(p) })
+	}
+	p.pauseMu.Unlock()
+
+	var source, key string
+	var derr *DispatchError
+	if errors.As(err, &derr) {
+		source, key = derr.Source, derr.Key
+	}
+	ctx = r.withHandlerTypes(ctx)
+	for _, fn := range r.hooks.onPause {
+		fn(ctx, source, key, d)
+	}
+}
+
+// resume ends p's pause once its resume time has passed, rescheduling
+// itself if the pause was extended, and calls the resume hooks.
+func (r *Router) resume(p *workerPool) {
+	p.pauseMu.Lock()
+	if wait := time.Until(p.resumeAt); wait > 0 {
+		p.resumeTimer = time.AfterFunc(wait, func() { r.resume(p) })
+		p.pauseMu.Unlock()
+		return
+	}
+	p.resumeTimer = nil
+	p.pauseMu.Unlock()
+
+	for _, fn := range r.hooks.onResume {
+		fn(context.Background())
+	}
+}
+
+// waitPaused blocks while p is paused. It returns ctx.Err() if ctx is done
+// first.
+func (p *workerPool) waitPaused(ctx context.Context) error {
+	for {
+		p.pauseMu.Lock()
+		wait := time.Until(p.resumeAt)
+		p.pauseMu.Unlock()
+		if wait <= 0 {
+			return nil
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BackpressureSuite struct {
+	suite.Suite
+}
+
+func TestBackpressureSuite(t *testing.T) {
+	suite.Run(t, new(BackpressureSuite))
+}
+
+func (s *BackpressureSuite) TestError() {
+	cause := errors.New("throttled")
+	err := Backpressure(time.Second, cause)
+
+	s.Assert().EqualError(err, "throttled")
+	s.Assert().ErrorIs(err, cause)
+	s.Assert().ErrorIs(err, ErrBackpressure)
+	d, ok := BackpressureDelay(err)
+	s.Assert().True(ok)
+	s.Assert().Equal(time.Second, d)
+}
+
+func (s *BackpressureSuite) TestNilError() {
+	err := Backpressure(time.Second, nil)
+	s.Assert().ErrorIs(err, ErrBackpressure)
+}
+
+func (s *BackpressureSuite) TestDelayFromProcess() {
+	r := New()
+	r.AddSource(&testSource{name: "test"})
+	RegisterProcFunc(r, "test", func(ctx context.Context, p struct{}) error {
+		return Backpressure(time.Minute, errors.New("throttled"))
+	})
+
+	err := r.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`))
+	d, ok := BackpressureDelay(err)
+	s.Assert().True(ok)
+	s.Assert().Equal(time.Minute, d)
+}
+
+func (s *BackpressureSuite) TestNoDelay() {
+	_, ok := BackpressureDelay(errors.New("boom"))
+	s.Assert().False(ok)
+	_, ok = BackpressureDelay(nil)
+	s.Assert().False(ok)
+}
+
+func (s *BackpressureSuite) TestWorkersPause() {
+	var mu sync.Mutex
+	var events []string
+	record := func(e string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	}
+	resumed := make(chan struct{})
+	r := New(
+		WithOnPause(func(ctx context.Context, source, key string, d time.Duration) {
+			record("pause " + source + " " + key)
+		}),
+		WithOnResume(func(ctx context.Context) {
+			record("resume")
+			close(resumed)
+		}),
+	)
+	r.AddSource(&testSource{name: "test"})
+	var calls atomic.Int32
+	RegisterProcFunc(r, "test", func(ctx context.Context, p struct{}) error {
+		if calls.Add(1) == 1 {
+			return Backpressure(50*time.Millisecond, errors.New("throttled"))
+		}
+		return nil
+	})
+	r.StartWorkers(1)
+	defer r.StopWorkers(context.Background())
+
+	raw := []byte(`{"type": "test", "payload": {}}`)
+	s.Require().ErrorIs(<-r.Submit(context.Background(), raw), ErrBackpressure)
+
+	start := time.Now()
+	s.Require().NoError(<-r.Submit(context.Background(), raw))
+	s.Assert().GreaterOrEqual(time.Since(start), 40*time.Millisecond, "waited out the pause")
+
+	<-resumed
+	mu.Lock()
+	defer mu.Unlock()
+	s.Assert().Equal([]string{"pause test test", "resume"}, events)
+}
+
+func (s *BackpressureSuite) TestPausedSubmitCanceled() {
+	r := New()
+	r.AddSource(&testSource{name: "test"})
+	var calls atomic.Int32
+	RegisterProcFunc(r, "test", func(ctx context.Context, p struct{}) error {
+		calls.Add(1)
+		return Backpressure(time.Hour, nil)
+	})
+	r.StartWorkers(1)
+	defer r.StopWorkers(context.Background())
+
+	raw := []byte(`{"type": "test", "payload": {}}`)
+	s.Require().Error(<-r.Submit(context.Background(), raw))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	s.Assert().ErrorIs(<-r.Submit(ctx, raw), context.DeadlineExceeded)
+	s.Assert().Equal(int32(1), calls.Load(), "paused workers start no messages")
+}
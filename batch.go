@@ -0,0 +1,57 @@
+package dispatch
+
+import (
+	"cmp"
+	"context"
+	"slices"
+)
+
+// ProcessBatch processes several raw messages, such as one SQS receive, and
+// returns one error per message in input order.
+//
+// All messages are matched and parsed first, then dispatched one at a time in
+// descending Message.Priority order, so urgent commands in a mixed queue are
+// not stuck behind bulk backfill events. Messages with equal priority keep
+// their input order.
+//
+// Example:
+//
+//	errs := r.ProcessBatch(ctx, bodies)
+//	for i, err := range errs {
+//	    if err != nil {
+//	        failures = append(failures, ids[i])
+//	    }
+//	}
+func (r *Router) ProcessBatch(ctx context.Context, raws [][]byte) []error {
+	errs := make([]error, len(raws))
+	if !r.begin() {
+		for i := range errs {
+			errs[i] = ErrShutdown
+		}
+		return errs
+	}
+	defer r.end()
+
+	type item struct {
+		idx int
+		p   *parsed
+	}
+	items := make([]item, 0, len(raws))
+	for i, raw := range raws {
+		p, err := r.parse(ctx, raw, processConfig{})
+		if p == nil {
+			errs[i] = err
+			continue
+		}
+		items = append(items, item{idx: i, p: p})
+	}
+
+	slices.SortStableFunc(items, func(a, b item) int {
+		return cmp.Compare(b.p.msg.Priority, a.p.msg.Priority)
+	})
+
+	for _, it := range items {
+		errs[it.idx] = r.dispatch(ctx, it.p)
+	}
+	return errs
+}
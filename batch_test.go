@@ -0,0 +1,80 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ProcessBatchSuite struct {
+	suite.Suite
+	order []string
+	r     *Router
+}
+
+func (s *ProcessBatchSuite) SetupTest() {
+	s.order = nil
+	s.r = New()
+	s.r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		var env struct {
+			Type     string `json:"type"`
+			ID       string `json:"id"`
+			Priority int    `json:"priority"`
+		}
+		if err := json.Unmarshal(raw, &env); err != nil {
+			return Message{}, err
+		}
+		if env.Type == "" {
+			return Message{}, errors.New("missing type")
+		}
+		return Message{Key: env.Type, MessageID: env.ID, Priority: env.Priority, Payload: []byte(`{}`)}, nil
+	}))
+	RegisterProcFunc(s.r, "event", func(ctx context.Context, p struct{}) error {
+		s.order = append(s.order, MessageID(ctx))
+		return nil
+	})
+	RegisterProcFunc(s.r, "fail", func(ctx context.Context, p struct{}) error {
+		return errors.New("boom")
+	})
+}
+
+func TestProcessBatchSuite(t *testing.T) {
+	suite.Run(t, new(ProcessBatchSuite))
+}
+
+func (s *ProcessBatchSuite) TestHandlesHigherPriorityFirst() {
+	errs := s.r.ProcessBatch(context.Background(), [][]byte{
+		[]byte(`{"type": "event", "id": "bulk-1"}`),
+		[]byte(`{"type": "event", "id": "urgent", "priority": 10}`),
+		[]byte(`{"type": "event", "id": "bulk-2"}`),
+		[]byte(`{"type": "event", "id": "normal", "priority": 5}`),
+	})
+
+	s.Require().Len(errs, 4)
+	for _, err := range errs {
+		s.Assert().NoError(err)
+	}
+	s.Assert().Equal([]string{"urgent", "normal", "bulk-1", "bulk-2"}, s.order)
+}
+
+func (s *ProcessBatchSuite) TestReturnsErrorsInInputOrder() {
+	errs := s.r.ProcessBatch(context.Background(), [][]byte{
+		[]byte(`{"type": "event", "id": "a"}`),
+		[]byte(`{"other": true}`),
+		[]byte(`{"type": "fail", "priority": 1}`),
+		[]byte(`{"type": ""}`),
+	})
+
+	s.Require().Len(errs, 4)
+	s.Assert().NoError(errs[0])
+	s.Assert().Error(errs[1])
+	s.Assert().EqualError(errs[2], "boom")
+	s.Assert().ErrorContains(errs[3], "parse failed")
+}
+
+func (s *ProcessBatchSuite) TestEmptyBatch() {
+	s.Assert().Empty(s.r.ProcessBatch(context.Background(), nil))
+}
@@ -0,0 +1,123 @@
+package dispatch
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// benchRouter returns a router with n default sources, where only the last
+// source matches benchMessage.
+func benchRouter(n int) *Router {
+	r := New()
+	for i := range n - 1 {
+		r.AddSource(SourceFunc(fmt.Sprintf("other-%d", i), FieldEquals("source", fmt.Sprintf("other.%d", i)), nil))
+	}
+	r.AddSource(&testSource{name: "test"})
+	RegisterProcFunc(r, "bench", func(ctx context.Context, p struct{ N int }) error {
+		return nil
+	})
+	return r
+}
+
+var benchMessage = []byte(`{"type": "bench", "payload": {"n": 1}}`)
+
+func BenchmarkMatch(b *testing.B) {
+	for _, n := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("sources=%d", n), func(b *testing.B) {
+			r := benchRouter(n)
+			b.ReportAllocs()
+			for b.Loop() {
+				if src, _ := r.match(benchMessage); src == nil {
+					b.Fatal("no match")
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkMatchAll(b *testing.B) {
+	for _, n := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("sources=%d", n), func(b *testing.B) {
+			r := benchRouter(n)
+			b.ReportAllocs()
+			for b.Loop() {
+				cache := getViewCache(benchMessage)
+				if cs, _ := r.matchAll(cache); cs == nil {
+					b.Fatal("no match")
+				}
+				putViewCache(cache)
+			}
+		})
+	}
+}
+
+func BenchmarkProcess(b *testing.B) {
+	r := benchRouter(10)
+	ctx := context.Background()
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := r.Process(ctx, benchMessage); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchWideMessage has many top-level fields, with the ones discriminators
+// check at the end.
+var benchWideMessage = func() []byte {
+	var b []byte
+	b = append(b, '{')
+	for i := range 50 {
+		b = fmt.Appendf(b, `"field%d": {"id": %d, "name": "value %d", "tags": ["a", "b", "c"]}, `, i, i, i)
+	}
+	b = append(b, `"source": "my.service", "detail-type": "thing", "detail": {"id": 1}}`...)
+	return b
+}()
+
+func BenchmarkJSONView(b *testing.B) {
+	paths := []string{"source", "detail-type", "detail", "detail.id", "missing"}
+	b.ReportAllocs()
+	for b.Loop() {
+		v, err := JSONInspector().Inspect(benchWideMessage)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, p := range paths {
+			v.HasField(p)
+			v.GetString(p)
+		}
+	}
+}
+
+// wideMessage returns an object with n top-level fields, followed by the ones
+// discriminators check.
+func wideMessage(n int) []byte {
+	var b []byte
+	b = append(b, '{')
+	for i := range n {
+		b = fmt.Appendf(b, `"field%d": %d, `, i, i)
+	}
+	b = append(b, `"source": "my.service", "detail-type": "thing", "detail": {"id": 1}}`...)
+	return b
+}
+
+func BenchmarkJSONViewWide(b *testing.B) {
+	paths := []string{"source", "detail-type", "detail.id", "missing"}
+	for _, n := range []int{10, 100, 1000, 10000} {
+		raw := wideMessage(n)
+		b.Run(fmt.Sprintf("fields=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				v, err := JSONInspector().Inspect(raw)
+				if err != nil {
+					b.Fatal(err)
+				}
+				for _, p := range paths {
+					v.HasField(p)
+					v.GetString(p)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package dispatch
+
+import (
+	"context"
+	"maps"
+	"slices"
+)
+
+// CompiledRouter is a frozen copy of a Router, created with Router.Build. It
+// processes messages exactly as the Router would, but its configuration
+// cannot change: sources, handlers, and hooks cannot be added, and the
+// runtime source controls, EnableSource and SetSourcePriority, are not
+// available. Its match index is built once, by Build, and source order is
+// fixed there; adaptive ordering is off, so matching a message doesn't write
+// to state shared with other messages. It implements Dispatcher, so it runs
+// workers and works with Invoke, the debug package, and the other helpers
+// just as a Router does.
+//
+// Keep using Router for setup, then hand the CompiledRouter to consumers:
+//
+//	r := dispatch.New(opts...)
+//	r.AddSource(src)
+//	dispatch.RegisterProc(r, "user/created", proc)
+//	cr := r.Build()
+//
+//	err := cr.Process(ctx, raw)
+type CompiledRouter struct {
+	r *Router
+}
+
+// Dispatcher is the runtime API shared by *Router and *CompiledRouter:
+// processing, workers, lifecycle, quarantine replay, and introspection. The
+// helpers that only read or run a configured router, such as Invoke,
+// Resolve, RoutingTableHandler, and the debug package, accept a Dispatcher,
+// so they work with either. It is implemented only by this package's
+// routers.
+type Dispatcher interface {
+	Process(ctx context.Context, raw []byte, opts ...ProcessOption) error
+	ProcessBatch(ctx context.Context, raws [][]byte) []error
+	ProcessResult(ctx context.Context, raw []byte, opts ...ProcessOption) (Result, error)
+
+	StartWorkers(n int)
+	Submit(ctx context.Context, raw []byte, opts ...ProcessOption) <-chan error
+	StopWorkers(ctx context.Context) error
+
+	Start(ctx context.Context) error
+	Shutdown(ctx context.Context) error
+	Healthy(ctx context.Context) error
+
+	ReplayQuarantine(ctx context.Context, store QuarantineStore) (int, error)
+
+	Stats() Stats
+	RoutingTable() RoutingTable
+	HandlerInfo(key string) (HandlerInfo, bool)
+	Handlers() []HandlerInfo
+
+	// router returns the router that processes messages.
+	router() *Router
+}
+
+var (
+	_ Dispatcher = (*Router)(nil)
+	_ Dispatcher = (*CompiledRouter)(nil)
+)
+
+func (r *Router) router() *Router { return r }
+
+func (c *CompiledRouter) router() *Router { return c.r }
+
+// Build returns a CompiledRouter with a copy of the router's current sources,
+// handlers, hooks, options, and source settings, and builds its match index.
+// Later changes to r do not affect the compiled router. The compiled router
+// starts with its own, empty Stats.
+//
+// The compiled router takes over the lifecycles of the handlers registered
+// on r so far: Start and Shutdown on it start and close them, and Start and
+// Shutdown on r only handle those registered on r after Build.
+func (r *Router) Build() *CompiledRouter {
+	c := r.clone()
+	c.managed, r.managed = r.managed, nil
+	idx := c.compileIndex()
+	idx.frozen = true
+	c.index.Store(idx)
+	return &CompiledRouter{r: c}
+}
+
+// Process processes a raw message. See Router.Process.
+func (c *CompiledRouter) Process(ctx context.Context, raw []byte, opts ...ProcessOption) error {
+	return c.r.Process(ctx, raw, opts...)
+}
+
+// ProcessBatch processes a batch of raw messages. See Router.ProcessBatch.
+func (c *CompiledRouter) ProcessBatch(ctx context.Context, raws [][]byte) []error {
+	return c.r.ProcessBatch(ctx, raws)
+}
+
+// Start starts the compiled router's handlers. See Router.Start.
+func (c *CompiledRouter) Start(ctx context.Context) error {
+	return c.r.Start(ctx)
+}
+
+// Shutdown stops the compiled router and waits for in-flight messages. See
+// Router.Shutdown.
+func (c *CompiledRouter) Shutdown(ctx context.Context) error {
+	return c.r.Shutdown(ctx)
+}
+
+// Healthy pings the compiled router's sources. See Router.Healthy.
+func (c *CompiledRouter) Healthy(ctx context.Context) error {
+	return c.r.Healthy(ctx)
+}
+
+// Stats returns counters for messages processed by the compiled router.
+func (c *CompiledRouter) Stats() Stats {
+	return c.r.Stats()
+}
+
+// Clone returns a new Router with a copy of r's sources, groups, handlers,
+// hooks, and options. Registering sources, groups, or handlers on either
+// router afterwards does not affect the other, so a shared base router can be
+// specialized per tenant or per test. Source and handler values themselves
+// are shared, not copied.
+//
+// The clone starts with fresh runtime state: empty Stats, no workers, and
+// not shut down. Handler lifecycles stay with the router that registered the
+// handler: Start and Shutdown on the clone only start and close handlers
+// registered on the clone, so shared handlers are closed once, by r.
+//
+// Example:
+//
+//	base := dispatch.New(dispatch.WithSlog(logger))
+//	base.AddSource(eventbridge.NewSource())
+//
+//	tenant := base.Clone()
+//	dispatch.RegisterProc(tenant, "order/created", tenantOrderProc)
+func (r *Router) Clone() *Router {
+	return r.clone()
+}
+
+// clone returns a router with a copy of r's configuration and fresh runtime
+// state (stats and match index). Slices and maps are copied so that
+// registering on either router does not affect the other. Managed handlers
+// are not copied; see Clone.
+func (r *Router) clone() *Router {
+	c := &Router{
+		defaultInspector: r.defaultInspector,
+		defaultSources:   slices.Clone(r.defaultSources),
+		groups:           make([]group, len(r.groups)),
+		handlers:         maps.Clone(r.handlers),
+		handlerTypes:     maps.Clone(r.handlerTypes),
+		enabled:          maps.Clone(r.enabled),
+		shadows:          maps.Clone(r.shadows),
+		guards:           maps.Clone(r.guards),
+		canaries:         maps.Clone(r.canaries),
+		hooks:            r.hooks.clone(),
+		pprofLabels:      r.pprofLabels,
+		hookErrors:       r.hookErrors,
+		sourceFirst:      r.sourceFirst,
+		maxAge:           r.maxAge,
+		maxPayloadSize:   r.maxPayloadSize,
+		heartbeat:        r.heartbeat,
+		dedup:            r.dedup.clone(),
+		boundedKeys:      r.boundedKeys,
+		replyRetry:       r.replyRetry,
+		replierFactory:   r.replierFactory,
+		fingerprint:      r.fingerprint,
+		parallel:         r.parallel,
+		deadLetterer:     r.deadLetterer,
+		quarantine:       r.quarantine,
+		chaos:            r.chaos,
+		registry:         r.registry,
+		keys:             r.keys,
+		schemas:          r.schemas,
+		inits:            r.inits,
+	}
+	for i, g := range r.groups {
+		c.groups[i] = group{inspector: g.inspector, sources: slices.Clone(g.sources)}
+	}
+	c.settings.Store(r.settings.Load())
+	return c
+}
+
+// clone returns a copy of h whose slices don't share spare capacity with h,
+// so appending to either copy leaves the other unchanged.
+func (h hooks) clone() hooks {
+	return hooks{
+		onParse:           slices.Clip(h.onParse),
+		onDispatch:        slices.Clip(h.onDispatch),
+		onSuccess:         slices.Clip(h.onSuccess),
+		onFailure:         slices.Clip(h.onFailure),
+		onTimings:         slices.Clip(h.onTimings),
+		onExpired:         slices.Clip(h.onExpired),
+		onDuplicate:       slices.Clip(h.onDuplicate),
+		onPause:           slices.Clip(h.onPause),
+		onResume:          slices.Clip(h.onResume),
+		onDisabled:        slices.Clip(h.onDisabled),
+		onShadow:          slices.Clip(h.onShadow),
+		onReply:           slices.Clip(h.onReply),
+		onNoSource:        slices.Clip(h.onNoSource),
+		onParseError:      slices.Clip(h.onParseError),
+		onNoHandler:       slices.Clip(h.onNoHandler),
+		onUnmarshalError:  slices.Clip(h.onUnmarshalError),
+		onValidationError: slices.Clip(h.onValidationError),
+		onOversize:        slices.Clip(h.onOversize),
+		onError:           slices.Clip(h.onError),
+		onSkip:            slices.Clip(h.onSkip),
+		onReject:          slices.Clip(h.onReject),
+	}
+}
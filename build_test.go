@@ -0,0 +1,192 @@
+package dispatch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BuildSuite struct {
+	suite.Suite
+}
+
+func TestBuildSuite(t *testing.T) {
+	suite.Run(t, new(BuildSuite))
+}
+
+func (s *BuildSuite) TestProcessesLikeRouter() {
+	var successes int
+	r := New(WithOnSuccess(func(ctx context.Context, source, key string, d time.Duration) {
+		successes++
+	}))
+	r.AddSource(&testSource{name: "test"})
+	RegisterProc(r, "test", &testHandler{})
+
+	cr := r.Build()
+
+	s.Require().NoError(cr.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`)))
+	errs := cr.ProcessBatch(context.Background(), [][]byte{[]byte(`{"type": "test", "payload": {}}`)})
+	s.Require().Len(errs, 1)
+	s.Require().NoError(errs[0])
+
+	s.Assert().Equal(2, successes)
+	s.Assert().Equal(uint64(2), cr.Stats().Keys["test"].Processed)
+	s.Assert().Empty(r.Stats().Keys)
+	s.Assert().NotNil(cr.r.index.Load())
+}
+
+func (s *BuildSuite) TestUnaffectedByLaterChanges() {
+	r := New()
+	r.AddSource(&testSource{name: "test"})
+	cr := r.Build()
+
+	var hookCalled bool
+	RegisterProc(r, "test", &testHandler{})
+	WithOnNoHandler(func(ctx context.Context, source, key string) error {
+		hookCalled = true
+		return nil
+	})(r)
+	r.AddSource(SourceFunc("other", HasFields("other"), nil))
+
+	err := cr.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`))
+
+	s.Assert().EqualError(err, "no handler for key: test")
+	s.Assert().False(hookCalled)
+	s.Assert().Len(cr.r.defaultSources, 1)
+}
+
+func (s *BuildSuite) TestFixedSourceOrder() {
+	r := New()
+	r.AddSource(&testSource{name: "test"})
+	RegisterProc(r, "test", &testHandler{})
+	cr := r.Build()
+
+	for range reorderInterval {
+		s.Require().NoError(cr.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`)))
+	}
+
+	idx := cr.r.index.Load()
+	s.Assert().Nil(idx.hot.Load(), "no hot list is built")
+	s.Assert().Zero(idx.matches.Load())
+}
+
+func (s *BuildSuite) TestTakesOverLifecycles() {
+	var events []string
+	r := New()
+	RegisterProc(r, "built", &lifecycleHandler{name: "built", events: &events})
+	cr := r.Build()
+	RegisterProc(r, "later", &lifecycleHandler{name: "later", events: &events})
+
+	ctx := context.Background()
+	s.Require().NoError(cr.Shutdown(ctx))
+	s.Require().NoError(r.Shutdown(ctx))
+
+	s.Assert().Equal([]string{"close built", "close later"}, events)
+}
+
+func (s *BuildSuite) TestImplementsDispatcher() {
+	r := New()
+	r.AddSource(&testSource{name: "test"})
+	RegisterFuncFunc(r, "echo", func(ctx context.Context, p testPayload) (testPayload, error) {
+		return p, nil
+	})
+	var d Dispatcher = r.Build()
+	raw := []byte(`{"type": "echo", "payload": {"value": "1"}}`)
+
+	d.StartWorkers(1)
+	s.Require().NoError(<-d.Submit(context.Background(), raw))
+	s.Require().NoError(d.StopWorkers(context.Background()))
+
+	out, err := Invoke[testPayload, testPayload](context.Background(), d, "echo", testPayload{Value: "2"})
+	s.Require().NoError(err)
+	s.Assert().Equal("2", out.Value)
+
+	route, err := Resolve(d, raw)
+	s.Require().NoError(err)
+	s.Assert().Equal(Route{Source: "test", Key: "echo", Handled: true}, route)
+
+	s.Assert().Equal([]string{"echo"}, HandlerKeys(d).List())
+	s.Assert().Len(d.RoutingTable().Handlers, 1)
+
+	store := &MemoryQuarantine{}
+	s.Require().NoError(store.Put(context.Background(), QuarantinedMessage{ID: "q", Raw: raw}))
+	n, err := d.ReplayQuarantine(context.Background(), store)
+	s.Require().NoError(err)
+	s.Assert().Equal(1, n)
+
+	s.Assert().Equal(uint64(2), d.Stats().Keys["echo"].Processed)
+}
+
+type CloneSuite struct {
+	suite.Suite
+}
+
+func TestCloneSuite(t *testing.T) {
+	suite.Run(t, new(CloneSuite))
+}
+
+func (s *CloneSuite) TestSharesConfiguration() {
+	var parsed []string
+	base := New(WithOnParse(func(ctx context.Context, source, key string) context.Context {
+		parsed = append(parsed, key)
+		return ctx
+	}))
+	base.AddSource(&testSource{name: "test"})
+	RegisterProc(base, "shared", &testHandler{})
+
+	clone := base.Clone()
+
+	s.Require().NoError(clone.Process(context.Background(), []byte(`{"type": "shared", "payload": {}}`)))
+	s.Assert().Equal([]string{"shared"}, parsed)
+}
+
+func (s *CloneSuite) TestIsolatesRegistrations() {
+	base := New()
+	base.AddSource(&testSource{name: "test"})
+	base.AddGroup(JSONInspector(), SourceFunc("grouped", HasFields("grouped"), nil))
+	RegisterProc(base, "shared", &testHandler{})
+	WithOnDispatch(func(ctx context.Context, source, key string) {})(base)
+
+	clone := base.Clone()
+	RegisterProc(clone, "tenant", &testHandler{})
+	clone.AddSource(SourceFunc("tenant", HasFields("tenant"), nil))
+	clone.AddGroup(JSONInspector(), SourceFunc("other", HasFields("other"), nil))
+	WithOnDispatch(func(ctx context.Context, source, key string) {})(clone)
+
+	s.Assert().EqualError(base.Process(context.Background(), []byte(`{"type": "tenant", "payload": {}}`)), "no handler for key: tenant")
+	s.Assert().NoError(clone.Process(context.Background(), []byte(`{"type": "tenant", "payload": {}}`)))
+	s.Assert().Len(base.defaultSources, 1)
+	s.Assert().Len(base.groups, 1)
+	s.Assert().Len(base.hooks.onDispatch, 1)
+	s.Assert().Len(clone.hooks.onDispatch, 2)
+}
+
+func (s *CloneSuite) TestFreshRuntimeState() {
+	base := New()
+	base.AddSource(&testSource{name: "test"})
+	RegisterProc(base, "test", &testHandler{})
+	s.Require().NoError(base.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`)))
+	s.Require().NoError(base.Shutdown(context.Background()))
+
+	clone := base.Clone()
+
+	s.Assert().Empty(clone.Stats().Keys)
+	s.Assert().NoError(clone.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`)))
+}
+
+func (s *CloneSuite) TestSharedHandlersClosedOnce() {
+	var events []string
+	base := New()
+	RegisterProc(base, "shared", &lifecycleHandler{name: "shared", events: &events})
+
+	clone := base.Clone()
+	RegisterProc(clone, "tenant", &lifecycleHandler{name: "tenant", events: &events})
+
+	ctx := context.Background()
+	s.Require().NoError(clone.Shutdown(ctx))
+	s.Require().NoError(base.Shutdown(ctx))
+
+	s.Assert().Equal([]string{"close tenant", "close shared"}, events)
+}
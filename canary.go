@@ -0,0 +1,103 @@
+package dispatch
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+)
+
+// canaryBuckets is the resolution of canary percentages: 0.01%.
+const canaryBuckets = 10000
+
+// canary is a handler that receives a percentage of a key's messages.
+type canary struct {
+	handler invoker
+	buckets uint64 // messages hashing below this go to the canary
+}
+
+type canaryKey struct{}
+
+// RegisterCanaryProc sends percent (0 to 100) of key's messages to p instead
+// of the handler registered with RegisterProc or RegisterFunc, to roll out a
+// new implementation gradually. Raise percent by registering again.
+//
+// Messages are assigned by a hash of their CorrelationID, or MessageID if it
+// is empty, so redeliveries and every message of a workflow go to the same
+// version. Messages with neither always go to the primary handler. The
+// canary only runs for keys that also have a primary handler; WithEnabled
+// and the payload type registry apply to the key as a whole.
+//
+// It panics if percent is outside 0 to 100.
+//
+// Example:
+//
+//	dispatch.RegisterProc(r, "order/created", &OrderProc{})
+//	dispatch.RegisterCanaryProc(r, "order/created", &OrderProcV2{}, 5)
+func RegisterCanaryProc[T any](r *Router, key string, p Proc[T], percent float64, opts ...HandlerOption) {
+	r.checkKey(key)
+	r.checkRegistry(key, reflect.TypeFor[T]())
+	r.manage(canaryName(key), p)
+	r.setCanary(key, procInvoker(p, newHandlerConfig(opts)), percent)
+}
+
+// RegisterCanaryFunc sends percent of key's messages to f, as
+// RegisterCanaryProc does.
+func RegisterCanaryFunc[T, R any](r *Router, key string, f Func[T, R], percent float64, opts ...HandlerOption) {
+	r.checkKey(key)
+	r.checkRegistry(key, reflect.TypeFor[T]())
+	r.manage(canaryName(key), f)
+	r.setCanary(key, funcInvoker(f, newHandlerConfig(opts)), percent)
+}
+
+// IsCanary reports whether the message being processed was routed to a
+// handler registered with RegisterCanaryProc or RegisterCanaryFunc. It is
+// available to hooks from OnDispatch onward and to handlers, so metrics can
+// compare the two versions.
+//
+// Example:
+//
+//	dispatch.WithOnFailure(func(ctx context.Context, source, key string, err error, d time.Duration) {
+//	    metrics.Incr("failures", "key:"+key, fmt.Sprintf("canary:%t", dispatch.IsCanary(ctx)))
+//	})
+func IsCanary(ctx context.Context) bool {
+	v, _ := ctx.Value(canaryKey{}).(bool)
+	return v
+}
+
+// canaryName names a canary handler in lifecycle errors.
+func canaryName(key string) string {
+	return key + " (canary)"
+}
+
+func (r *Router) setCanary(key string, h invoker, percent float64) {
+	if percent < 0 || percent > 100 {
+		panic(fmt.Sprintf("dispatch: canary percent %v for %s is outside 0-100", percent, key))
+	}
+	r.canaries[key] = canary{handler: h, buckets: uint64(percent * canaryBuckets / 100)}
+}
+
+// route returns the handler for msg: the key's canary if msg hashes into
+// its share, or primary. The returned context marks canary messages for
+// IsCanary.
+func (r *Router) route(ctx context.Context, msg Message, primary invoker) (context.Context, invoker) {
+	c, ok := r.canaries[msg.Key]
+	if !ok || c.buckets == 0 {
+		return ctx, primary
+	}
+	id := msg.CorrelationID
+	if id == "" {
+		id = msg.MessageID
+	}
+	if id == "" || canaryBucket(id) >= c.buckets {
+		return ctx, primary
+	}
+	return context.WithValue(ctx, canaryKey{}, true), c.handler
+}
+
+// canaryBucket hashes id into one of canaryBuckets buckets.
+func canaryBucket(id string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	return h.Sum64() % canaryBuckets
+}
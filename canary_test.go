@@ -0,0 +1,177 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CanarySuite struct {
+	suite.Suite
+	versions map[string]string // correlation ID → version that handled it
+	r        *Router
+}
+
+func TestCanarySuite(t *testing.T) {
+	suite.Run(t, new(CanarySuite))
+}
+
+func (s *CanarySuite) SetupTest() {
+	s.versions = make(map[string]string)
+	s.r = New()
+	s.r.AddSource(SourceFunc("test", HasFields("id"), func(raw []byte) (Message, error) {
+		var env struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(raw, &env); err != nil {
+			return Message{}, err
+		}
+		return Message{Key: "test", CorrelationID: env.ID, Payload: []byte(`{}`)}, nil
+	}))
+	RegisterProcFunc(s.r, "test", s.handler("v1"))
+}
+
+func (s *CanarySuite) handler(version string) func(ctx context.Context, p struct{}) error {
+	return func(ctx context.Context, p struct{}) error {
+		s.versions[CorrelationID(ctx)] = version
+		return nil
+	}
+}
+
+func (s *CanarySuite) process(r *Router, id string) {
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"id": "`+id+`"}`)))
+}
+
+func (s *CanarySuite) TestRoutesPercentageToCanary() {
+	RegisterCanaryProc(s.r, "test", ProcFunc[struct{}](s.handler("v2")), 10)
+
+	canary := 0
+	for i := range 10000 {
+		id := fmt.Sprintf("msg-%d", i)
+		s.process(s.r, id)
+		if s.versions[id] == "v2" {
+			canary++
+		}
+	}
+
+	s.Assert().InDelta(1000, canary, 150)
+}
+
+func (s *CanarySuite) TestBucketingIsDeterministic() {
+	RegisterCanaryProc(s.r, "test", ProcFunc[struct{}](s.handler("v2")), 50)
+
+	first := make(map[string]string)
+	for i := range 100 {
+		id := fmt.Sprintf("msg-%d", i)
+		s.process(s.r, id)
+		first[id] = s.versions[id]
+	}
+	for id, version := range first {
+		s.process(s.r, id)
+		s.Assert().Equal(version, s.versions[id], id)
+	}
+}
+
+func (s *CanarySuite) TestRaisingPercentKeepsCanaryMessages() {
+	RegisterCanaryProc(s.r, "test", ProcFunc[struct{}](s.handler("v2")), 5)
+	var canaryIDs []string
+	for i := range 1000 {
+		id := fmt.Sprintf("msg-%d", i)
+		s.process(s.r, id)
+		if s.versions[id] == "v2" {
+			canaryIDs = append(canaryIDs, id)
+		}
+	}
+
+	RegisterCanaryProc(s.r, "test", ProcFunc[struct{}](s.handler("v2")), 50)
+	for _, id := range canaryIDs {
+		s.process(s.r, id)
+		s.Assert().Equal("v2", s.versions[id], id)
+	}
+}
+
+func (s *CanarySuite) TestZeroAndHundredPercent() {
+	RegisterCanaryProc(s.r, "test", ProcFunc[struct{}](s.handler("v2")), 0)
+	s.process(s.r, "a")
+	s.Assert().Equal("v1", s.versions["a"])
+
+	RegisterCanaryProc(s.r, "test", ProcFunc[struct{}](s.handler("v2")), 100)
+	s.process(s.r, "a")
+	s.Assert().Equal("v2", s.versions["a"])
+}
+
+func (s *CanarySuite) TestMessagesWithoutIDGoToPrimary() {
+	RegisterCanaryProc(s.r, "test", ProcFunc[struct{}](s.handler("v2")), 100)
+
+	s.process(s.r, "")
+
+	s.Assert().Equal("v1", s.versions[""])
+}
+
+func (s *CanarySuite) TestIsCanary() {
+	var seen []bool
+	r := New(WithOnSuccess(func(ctx context.Context, source, key string, d time.Duration) {
+		seen = append(seen, IsCanary(ctx))
+	}))
+	r.AddSource(SourceFunc("test", HasFields("id"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", MessageID: string(raw), Payload: []byte(`{}`)}, nil
+	}))
+	RegisterProcFunc(r, "test", func(ctx context.Context, p struct{}) error { return nil })
+	RegisterCanaryProc(r, "test", ProcFunc[struct{}](func(ctx context.Context, p struct{}) error { return nil }), 100)
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"id": 1}`)))
+	RegisterCanaryProc(r, "test", ProcFunc[struct{}](func(ctx context.Context, p struct{}) error { return nil }), 0)
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"id": 1}`)))
+
+	s.Assert().Equal([]bool{true, false}, seen)
+	s.Assert().False(IsCanary(context.Background()))
+}
+
+func (s *CanarySuite) TestCanaryFunc() {
+	replier := NewChannelReplier()
+	r := New()
+	r.AddSource(SourceFunc("test", HasFields("id"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", MessageID: "m", Payload: []byte(`{}`), Replier: replier}, nil
+	}))
+	RegisterFuncFunc(r, "test", func(ctx context.Context, p struct{}) (string, error) { return "v1", nil })
+	RegisterCanaryFunc(r, "test", FuncFunc[struct{}, string](func(ctx context.Context, p struct{}) (string, error) {
+		return "v2", nil
+	}), 100)
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"id": 1}`)))
+
+	result, err := replier.Wait(context.Background())
+	s.Require().NoError(err)
+	s.Assert().JSONEq(`"v2"`, string(result))
+}
+
+func (s *CanarySuite) TestPanicsOnInvalidPercent() {
+	s.Assert().PanicsWithValue("dispatch: canary percent 101 for test is outside 0-100", func() {
+		RegisterCanaryProc(s.r, "test", ProcFunc[struct{}](s.handler("v2")), 101)
+	})
+	s.Assert().Panics(func() {
+		RegisterCanaryProc(s.r, "test", ProcFunc[struct{}](s.handler("v2")), -1)
+	})
+}
+
+func (s *CanarySuite) TestCanaryWithoutPrimaryIsUnhandled() {
+	r := New()
+	r.AddSource(SourceFunc("test", HasFields("id"), func(raw []byte) (Message, error) {
+		return Message{Key: "other", MessageID: "m", Payload: []byte(`{}`)}, nil
+	}))
+	RegisterCanaryProc(r, "other", ProcFunc[struct{}](s.handler("v2")), 100)
+
+	s.Assert().ErrorIs(r.Process(context.Background(), []byte(`{"id": 1}`)), ErrNoHandler)
+}
+
+func (s *CanarySuite) TestCloneKeepsCanary() {
+	RegisterCanaryProc(s.r, "test", ProcFunc[struct{}](s.handler("v2")), 100)
+
+	s.process(s.r.Clone(), "a")
+
+	s.Assert().Equal("v2", s.versions["a"])
+}
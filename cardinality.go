@@ -0,0 +1,82 @@
+package dispatch
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+)
+
+// KeyBucketer maps a routing key with no registered handler to the label
+// passed to hooks in its place. It should return few distinct labels.
+type KeyBucketer func(key string) string
+
+// CollapseKeys returns a KeyBucketer that maps every key to label.
+func CollapseKeys(label string) KeyBucketer {
+	return func(string) string {
+		return label
+	}
+}
+
+// HashKeys returns a KeyBucketer that hashes keys into n labels, "other-0"
+// through "other-<n-1>", so unknown keys stay distinguishable in aggregate
+// without unbounded cardinality.
+func HashKeys(n int) KeyBucketer {
+	n = max(n, 1)
+	return func(key string) string {
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		return "other-" + strconv.Itoa(int(h.Sum32()%uint32(n)))
+	}
+}
+
+// WithBoundedKeys registers hook options that see keys with a registered
+// handler verbatim and every other key replaced by bucket(key). Use it for
+// metrics hooks, so wildcard, parameterized, or garbage keys don't explode
+// metric cardinality. Hooks outside it still see the original key. Options
+// other than hooks have no effect when passed here.
+//
+// Keys are checked against the handlers registered on the router processing
+// the message, so a clone with more handlers passes those keys verbatim too.
+// Keys are only known once a message is parsed, so WithOnNoSource and
+// WithOnParseError hooks are registered unchanged, as are WithOnResume hooks,
+// which have no key.
+//
+// Example:
+//
+//	dispatch.New(
+//	    dispatch.WithBoundedKeys(dispatch.CollapseKeys("unknown"),
+//	        statsd.Hooks(client)...,
+//	    ),
+//	)
+func WithBoundedKeys(bucket KeyBucketer, opts ...Option) Option {
+	return func(r *Router) {
+		r.boundedKeys = true
+		label := func(ctx context.Context, _, key string) (string, bool) {
+			if key == "" || registeredKey(ctx, key) {
+				return key, true
+			}
+			return bucket(key), true
+		}
+		r.hooks.add(mapHooks(collectHooks(opts), label, label))
+	}
+}
+
+// handlerTypesKey carries the router's handlerTypes for WithBoundedKeys.
+type handlerTypesKey struct{}
+
+// withHandlerTypes returns a context carrying the router's registered
+// handlers, if WithBoundedKeys needs them.
+func (r *Router) withHandlerTypes(ctx context.Context) context.Context {
+	if !r.boundedKeys {
+		return ctx
+	}
+	return context.WithValue(ctx, handlerTypesKey{}, r.handlerTypes)
+}
+
+// registeredKey reports whether key has a handler on the router processing
+// the message in ctx.
+func registeredKey(ctx context.Context, key string) bool {
+	types, _ := ctx.Value(handlerTypesKey{}).(map[string]handlerType)
+	_, ok := types[key]
+	return ok
+}
@@ -0,0 +1,119 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CardinalitySuite struct {
+	suite.Suite
+	keys []string
+}
+
+func TestCardinalitySuite(t *testing.T) {
+	suite.Run(t, new(CardinalitySuite))
+}
+
+func (s *CardinalitySuite) SetupTest() {
+	s.keys = nil
+}
+
+func (s *CardinalitySuite) record() []Option {
+	return []Option{
+		WithOnSuccess(func(ctx context.Context, source, key string, d time.Duration) {
+			s.keys = append(s.keys, "success:"+key)
+		}),
+		WithOnNoHandler(func(ctx context.Context, source, key string) error {
+			s.keys = append(s.keys, "nohandler:"+key)
+			return nil
+		}),
+	}
+}
+
+func (s *CardinalitySuite) router(bucket KeyBucketer) *Router {
+	r := New(WithBoundedKeys(bucket, s.record()...))
+	r.AddSource(&testSource{name: "test"})
+	RegisterProc(r, "known", &testHandler{})
+	return r
+}
+
+func (s *CardinalitySuite) process(r *Router, key string) {
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "`+key+`", "payload": {"value": "x"}}`)))
+}
+
+func (s *CardinalitySuite) TestRegisteredKeysVerbatim() {
+	r := s.router(CollapseKeys("unknown"))
+
+	s.process(r, "known")
+	s.process(r, "user/123/updated")
+
+	s.Assert().Equal([]string{"success:known", "nohandler:unknown"}, s.keys)
+}
+
+func (s *CardinalitySuite) TestOtherHooksSeeOriginalKey() {
+	var seen string
+	r := New(
+		WithBoundedKeys(CollapseKeys("unknown"), s.record()...),
+		WithOnNoHandler(func(ctx context.Context, source, key string) error {
+			seen = key
+			return ErrHookAbstain
+		}),
+	)
+	r.AddSource(&testSource{name: "test"})
+
+	s.process(r, "user/123/updated")
+	s.Assert().Equal("user/123/updated", seen)
+	s.Assert().Equal([]string{"nohandler:unknown"}, s.keys)
+}
+
+func (s *CardinalitySuite) TestHashKeys() {
+	bucket := HashKeys(4)
+	labels := map[string]bool{}
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		labels[bucket(key)] = true
+	}
+	s.Assert().LessOrEqual(len(labels), 4)
+	s.Assert().Equal(bucket("user/1"), bucket("user/1"))
+	s.Assert().Regexp(`^other-[0-3]$`, bucket("user/1"))
+}
+
+func (s *CardinalitySuite) TestCloneUsesItsOwnHandlers() {
+	base := s.router(CollapseKeys("unknown"))
+	clone := base.Clone()
+	RegisterProc(clone, "tenant", &testHandler{})
+
+	s.process(clone, "tenant")
+	s.process(base, "tenant")
+
+	s.Assert().Equal([]string{"success:tenant", "nohandler:unknown"}, s.keys)
+}
+
+func (s *CardinalitySuite) TestParseStageOutcomes() {
+	var records []AuditRecord
+	r := New(
+		WithBoundedKeys(CollapseKeys("unknown"), WithAudit(AuditSinkFunc(func(ctx context.Context, rec AuditRecord) {
+			records = append(records, rec)
+		}))),
+		WithReplierFactory(ReplierFactoryFunc(func(ctx context.Context, msg Message) (Replier, error) {
+			return nil, errors.New("no queue")
+		})),
+	)
+	r.AddSource(SourceFunc("reply", HasFields("type"), func(raw []byte) (Message, error) {
+		msg, err := (&testSource{}).Parse(raw)
+		msg.ReplyTo = "queue"
+		return msg, err
+	}))
+	RegisterProc(r, "known", &testHandler{})
+
+	s.Require().Error(r.Process(context.Background(), []byte(`{"type": "known", "payload": {}}`)))
+	s.Require().Error(r.Process(context.Background(), []byte(`{"type": "user/123/updated", "payload": {}}`)))
+
+	s.Require().Len(records, 2)
+	s.Assert().Equal(AuditRejected, records[0].Outcome)
+	s.Assert().Equal("known", records[0].Key)
+	s.Assert().Equal("unknown", records[1].Key)
+}
@@ -0,0 +1,67 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync/atomic"
+)
+
+// errAlreadyReplied is returned when a ChannelReplier is completed twice.
+var errAlreadyReplied = errors.New("dispatch: replier already completed")
+
+// ChannelReplier is a Replier that delivers the routed result in process, so
+// tests and in-memory transports can wait for it synchronously. It accepts a
+// single Reply or Fail.
+//
+// Example:
+//
+//	replier := dispatch.NewChannelReplier()
+//	go r.Process(ctx, raw) // source sets Message.Replier = replier
+//	result, err := replier.Wait(ctx)
+type ChannelReplier struct {
+	completed atomic.Bool
+	done      chan struct{} // closed once res is set
+	res       replyResult
+}
+
+type replyResult struct {
+	result json.RawMessage
+	err    error
+}
+
+// NewChannelReplier returns a ChannelReplier ready to receive one reply.
+func NewChannelReplier() *ChannelReplier {
+	return &ChannelReplier{done: make(chan struct{})}
+}
+
+// Reply implements the Replier interface.
+func (c *ChannelReplier) Reply(_ context.Context, result json.RawMessage) error {
+	return c.complete(replyResult{result: slices.Clone(result)})
+}
+
+// Fail implements the Replier interface. The error is returned from Wait.
+func (c *ChannelReplier) Fail(_ context.Context, err error) error {
+	return c.complete(replyResult{err: err})
+}
+
+func (c *ChannelReplier) complete(res replyResult) error {
+	if !c.completed.CompareAndSwap(false, true) {
+		return errAlreadyReplied
+	}
+	c.res = res
+	close(c.done)
+	return nil
+}
+
+// Wait blocks until Reply or Fail is called, or ctx is done. It returns the
+// result passed to Reply or the error passed to Fail, to every call.
+func (c *ChannelReplier) Wait(ctx context.Context) (json.RawMessage, error) {
+	select {
+	case <-c.done:
+		return c.res.result, c.res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
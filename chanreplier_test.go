@@ -0,0 +1,84 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ChannelReplierSuite struct {
+	suite.Suite
+}
+
+func TestChannelReplierSuite(t *testing.T) {
+	suite.Run(t, new(ChannelReplierSuite))
+}
+
+func (s *ChannelReplierSuite) router(replier Replier) *Router {
+	r := New()
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "double", Payload: []byte(`{"n": 21}`), Replier: replier}, nil
+	}))
+	return r
+}
+
+func (s *ChannelReplierSuite) TestWaitReturnsResult() {
+	replier := NewChannelReplier()
+	r := s.router(replier)
+	RegisterFuncFunc(r, "double", func(ctx context.Context, in struct{ N int }) (int, error) {
+		return in.N * 2, nil
+	})
+
+	go func() { _ = r.Process(context.Background(), []byte(`{"type": "x"}`)) }()
+
+	result, err := replier.Wait(context.Background())
+	s.Require().NoError(err)
+	s.Assert().JSONEq(`42`, string(result))
+}
+
+func (s *ChannelReplierSuite) TestWaitReturnsFailure() {
+	replier := NewChannelReplier()
+	r := s.router(replier)
+	RegisterProcFunc(r, "double", func(ctx context.Context, in struct{ N int }) error {
+		return errors.New("boom")
+	})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "x"}`)))
+
+	_, err := replier.Wait(context.Background())
+	s.Assert().EqualError(err, "boom")
+}
+
+func (s *ChannelReplierSuite) TestWaitHonorsContext() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewChannelReplier().Wait(ctx)
+	s.Assert().ErrorIs(err, context.Canceled)
+}
+
+func (s *ChannelReplierSuite) TestSecondReplyFails() {
+	replier := NewChannelReplier()
+
+	s.Require().NoError(replier.Reply(context.Background(), []byte(`{}`)))
+	s.Assert().Error(replier.Fail(context.Background(), errors.New("late")))
+}
+
+func (s *ChannelReplierSuite) TestCompletesOnceAfterWait() {
+	replier := NewChannelReplier()
+	s.Require().NoError(replier.Reply(context.Background(), []byte(`{"n": 1}`)))
+	_, err := replier.Wait(context.Background())
+	s.Require().NoError(err)
+
+	s.Assert().ErrorIs(replier.Reply(context.Background(), []byte(`{"n": 2}`)), errAlreadyReplied)
+	s.Assert().ErrorIs(replier.Fail(context.Background(), errors.New("late")), errAlreadyReplied)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	result, err := replier.Wait(ctx)
+	s.Require().NoError(err, "a second Wait returns the same result")
+	s.Assert().JSONEq(`{"n": 1}`, string(result))
+}
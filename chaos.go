@@ -0,0 +1,110 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// ErrChaos is the synthetic failure injected by WithChaos. Errors returned
+// by Process for injected failures wrap it.
+var ErrChaos = errors.New("chaos: injected failure")
+
+// ChaosOption configures WithChaos.
+type ChaosOption func(*chaos)
+
+// chaos holds the fault injection rules for each stage.
+type chaos struct {
+	latency map[Stage]chaosLatency
+	failure map[Stage]float64
+}
+
+type chaosLatency struct {
+	rate     float64
+	min, max time.Duration
+}
+
+// ChaosLatency delays stage by a random duration between min and max for a
+// fraction of messages given by rate between 0 and 1. The delay ends early
+// if the message's context is done.
+func ChaosLatency(stage Stage, rate float64, min, max time.Duration) ChaosOption {
+	return func(c *chaos) {
+		c.latency[stage] = chaosLatency{rate: rate, min: min, max: max}
+	}
+}
+
+// ChaosFailure fails stage with ErrChaos for a fraction of messages given by
+// rate between 0 and 1.
+func ChaosFailure(stage Stage, rate float64) ChaosOption {
+	return func(c *chaos) {
+		c.failure[stage] = rate
+	}
+}
+
+// WithChaos injects latency and synthetic failures into message processing,
+// for resilience testing of retry and dead-letter behavior in staging. Do not
+// enable it in production.
+//
+// Faults can be injected at three stages:
+//
+//   - StageParse: before the source parses the message. Failures are handled
+//     like parse errors, by WithOnParseError hooks.
+//   - StageHandle: before the handler runs. Failures are handled like handler
+//     errors, and the handler is not called.
+//   - StageReply: before each Replier.Reply or Replier.Fail attempt, so
+//     WithReplyRetry retries injected failures.
+//
+// Rules for other stages are ignored. Injected failures wrap ErrChaos, so
+// error policies can match them with ErrorIs(dispatch.ErrChaos, ...).
+//
+// Example:
+//
+//	r := dispatch.New(dispatch.WithChaos(
+//	    dispatch.ChaosLatency(dispatch.StageHandle, 0.1, 100*time.Millisecond, 2*time.Second),
+//	    dispatch.ChaosFailure(dispatch.StageHandle, 0.05),
+//	    dispatch.ChaosFailure(dispatch.StageReply, 0.05),
+//	))
+func WithChaos(opts ...ChaosOption) Option {
+	return func(r *Router) {
+		c := &chaos{
+			latency: make(map[Stage]chaosLatency),
+			failure: make(map[Stage]float64),
+		}
+		for _, opt := range opts {
+			opt(c)
+		}
+		r.chaos = c
+	}
+}
+
+// inject applies the rules for stage, sleeping and returning an error as
+// configured. It is a no-op on a nil *chaos.
+func (c *chaos) inject(ctx context.Context, stage Stage) error {
+	if c == nil {
+		return nil
+	}
+	if l, ok := c.latency[stage]; ok && chance(l.rate) {
+		d := l.min
+		if l.max > l.min {
+			d += rand.N(l.max - l.min)
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if rate, ok := c.failure[stage]; ok && chance(rate) {
+		return fmt.Errorf("%w at %s", ErrChaos, stage)
+	}
+	return nil
+}
+
+// chance reports true for a fraction of calls given by rate.
+func chance(rate float64) bool {
+	return rate >= 1 || (rate > 0 && rand.Float64() < rate)
+}
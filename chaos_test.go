@@ -0,0 +1,149 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// countingReplier counts Reply calls.
+type countingReplier struct {
+	replies int
+}
+
+func (c *countingReplier) Reply(ctx context.Context, result json.RawMessage) error {
+	c.replies++
+	return nil
+}
+
+func (c *countingReplier) Fail(ctx context.Context, err error) error {
+	return nil
+}
+
+type ChaosSuite struct {
+	suite.Suite
+	handler *testHandler
+}
+
+func TestChaosSuite(t *testing.T) {
+	suite.Run(t, new(ChaosSuite))
+}
+
+func (s *ChaosSuite) SetupTest() {
+	s.handler = &testHandler{}
+}
+
+func (s *ChaosSuite) router(replier Replier, opts ...Option) *Router {
+	r := New(opts...)
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		v, _ := JSONInspector().Inspect(raw)
+		key, _ := v.GetString("type")
+		payload, _ := v.GetBytes("payload")
+		return Message{Key: key, Payload: payload, Replier: replier}, nil
+	}))
+	RegisterProc(r, "test", s.handler)
+	return r
+}
+
+var chaosMessage = []byte(`{"type": "test", "payload": {}}`)
+
+func (s *ChaosSuite) TestParseFailure() {
+	var hookErr error
+	r := s.router(nil,
+		WithChaos(ChaosFailure(StageParse, 1)),
+		WithOnParseError(func(ctx context.Context, source string, err error) error {
+			hookErr = err
+			return err
+		}),
+	)
+
+	err := r.Process(context.Background(), chaosMessage)
+
+	s.Assert().ErrorIs(err, ErrChaos)
+	s.Assert().ErrorIs(hookErr, ErrChaos)
+	s.Assert().EqualError(err, "chaos: injected failure at parse")
+	s.Assert().False(s.handler.called)
+}
+
+func (s *ChaosSuite) TestHandleFailureSkipsHandler() {
+	r := s.router(nil, WithChaos(ChaosFailure(StageHandle, 1)))
+
+	err := r.Process(context.Background(), chaosMessage)
+
+	s.Assert().ErrorIs(err, ErrChaos)
+	var de *DispatchError
+	s.Require().ErrorAs(err, &de)
+	s.Assert().Equal(StageHandle, de.Stage)
+	s.Assert().False(s.handler.called)
+}
+
+func (s *ChaosSuite) TestReplyFailureIsRetried() {
+	replier := &countingReplier{}
+	r := s.router(replier,
+		WithChaos(ChaosFailure(StageReply, 1)),
+		WithReplyRetry(3, time.Microsecond),
+	)
+
+	err := r.Process(context.Background(), chaosMessage)
+
+	s.Assert().ErrorIs(err, ErrChaos)
+	s.Assert().True(s.handler.called)
+	s.Assert().Zero(replier.replies)
+}
+
+func (s *ChaosSuite) TestErrorPolicyCanSkipInjectedFailures() {
+	r := s.router(nil,
+		WithChaos(ChaosFailure(StageHandle, 1)),
+		WithErrorRules(ErrorIs(ErrChaos, ActionSkip)),
+	)
+
+	s.Assert().NoError(r.Process(context.Background(), chaosMessage))
+}
+
+func (s *ChaosSuite) TestLatency() {
+	r := s.router(nil, WithChaos(ChaosLatency(StageHandle, 1, 20*time.Millisecond, 30*time.Millisecond)))
+
+	start := time.Now()
+	s.Require().NoError(r.Process(context.Background(), chaosMessage))
+
+	s.Assert().GreaterOrEqual(time.Since(start), 20*time.Millisecond)
+	s.Assert().True(s.handler.called)
+}
+
+func (s *ChaosSuite) TestLatencyStopsWhenContextDone() {
+	r := s.router(nil, WithChaos(ChaosLatency(StageParse, 1, time.Hour, time.Hour)))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := r.Process(ctx, chaosMessage)
+
+	s.Assert().ErrorIs(err, context.DeadlineExceeded)
+}
+
+func (s *ChaosSuite) TestZeroRateInjectsNothing() {
+	r := s.router(nil, WithChaos(
+		ChaosFailure(StageParse, 0),
+		ChaosFailure(StageHandle, 0),
+		ChaosLatency(StageHandle, 0, time.Hour, time.Hour),
+	))
+
+	s.Require().NoError(r.Process(context.Background(), chaosMessage))
+	s.Assert().True(s.handler.called)
+}
+
+func (s *ChaosSuite) TestRateIsApproximate() {
+	c := &chaos{failure: map[Stage]float64{StageHandle: 0.5}}
+
+	failed := 0
+	for range 1000 {
+		if errors.Is(c.inject(context.Background(), StageHandle), ErrChaos) {
+			failed++
+		}
+	}
+
+	s.Assert().InDelta(500, failed, 100)
+}
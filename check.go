@@ -0,0 +1,196 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AmbiguityError reports a sample message matched by more than one source.
+type AmbiguityError struct {
+	// Sample is the name of the sample passed to CheckSources.
+	Sample string
+
+	// Sources lists the names of every matching source in match order.
+	Sources []string
+}
+
+func (e *AmbiguityError) Error() string {
+	return fmt.Sprintf("sample %s matched multiple sources: %s", e.Sample, strings.Join(e.Sources, ", "))
+}
+
+// CheckSources evaluates every source's discriminator against each sample
+// and returns an error when more than one source matches the same sample.
+// Such samples are routed by source order alone, which is easy to break by
+// reordering AddSource calls. Disabled sources and fallbacks are not checked.
+//
+// The returned error joins one *AmbiguityError per ambiguous sample, in
+// sample name order. Use it in tests to catch overlapping discriminators:
+//
+//	func TestSourcesAreExclusive(t *testing.T) {
+//	    err := dispatch.CheckSources(newRouter(), map[string][]byte{
+//	        "eventbridge": eventBridgeFixture,
+//	        "sns":         snsFixture,
+//	    })
+//	    require.NoError(t, err)
+//	}
+func CheckSources(d Dispatcher, samples map[string][]byte) error {
+	r := d.router()
+	names := make([]string, 0, len(samples))
+	for name := range samples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		matched := r.matchingSources(samples[name])
+		if len(matched) > 1 {
+			errs = append(errs, &AmbiguityError{Sample: name, Sources: matched})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// matchingSources returns the names of all sources whose discriminator
+// matches the raw message, in the order the router would try them.
+func (r *Router) matchingSources(raw []byte) []string {
+	cache := getViewCache(raw)
+	defer putViewCache(cache)
+	var names []string
+
+	settings := r.settings.Load()
+	add := func(insp Inspector, sources []Source) {
+		for _, src := range settings.order(sources) {
+			if !settings.enabled(src.Name()) || isFallback(src) {
+				continue
+			}
+			srcInsp := insp
+			if own := sourceInspector(src); own != nil {
+				srcInsp = own
+			}
+			if view, ok := cache.get(srcInsp); ok && src.Discriminator().Match(view) {
+				names = append(names, src.Name())
+			}
+		}
+	}
+	add(r.defaultInspector, r.defaultSources)
+	for _, g := range r.groups {
+		add(g.inspector, g.sources)
+	}
+	return names
+}
+
+// CoverageError reports a gap between registered handlers and the samples
+// passed to CheckCoverage.
+type CoverageError struct {
+	// Sample is the name of the sample without a handler. It is empty when
+	// Key is a registered key that no sample covers.
+	Sample string
+
+	// Key is the routing key without a sample or handler.
+	Key string
+}
+
+func (e *CoverageError) Error() string {
+	if e.Sample == "" {
+		return fmt.Sprintf("key %s has no sample", e.Key)
+	}
+	return fmt.Sprintf("sample %s has no handler for key %s", e.Sample, e.Key)
+}
+
+// CheckCoverage cross-references registered handler keys against sample
+// messages, such as fixtures captured from producers. It returns an error
+// when a registered key has no sample, a sample routes to a key with no
+// handler, or a sample can't be routed at all, so producer and consumer
+// contracts can't drift apart unnoticed.
+//
+// The returned error joins one error per problem: sample errors in sample
+// name order, then a *CoverageError per uncovered key in key order.
+//
+//	func TestContracts(t *testing.T) {
+//	    err := dispatch.CheckCoverage(newRouter(), map[string][]byte{
+//	        "user-created": userCreatedFixture,
+//	        "user-deleted": userDeletedFixture,
+//	    })
+//	    require.NoError(t, err)
+//	}
+func CheckCoverage(d Dispatcher, samples map[string][]byte) error {
+	r := d.router()
+	names := make([]string, 0, len(samples))
+	for name := range samples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	covered := make(map[string]bool, len(r.handlers))
+	for _, name := range names {
+		route, err := Resolve(r, samples[name])
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("sample %s: %w", name, err))
+		case !route.Handled:
+			errs = append(errs, &CoverageError{Sample: name, Key: route.Key})
+		default:
+			covered[route.Key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(r.handlers))
+	for key := range r.handlers {
+		if !covered[key] {
+			keys = append(keys, key)
+		}
+	}
+	for key := range r.guards {
+		if _, ok := r.handlers[key]; !ok && !covered[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		errs = append(errs, &CoverageError{Key: key})
+	}
+	return errors.Join(errs...)
+}
+
+// Route describes where the router sends a message.
+type Route struct {
+	// Source is the name of the source that parses the message.
+	Source string
+
+	// Key is the routing key the source extracted.
+	Key string
+
+	// Handled reports whether a handler is registered for Key, including a
+	// guarded handler that accepts the payload.
+	Handled bool
+}
+
+// Resolve matches and parses raw the way Process does and reports where it
+// would be routed, without running hooks or handlers. Errors wrap
+// ErrNoSource or the source's parse error, as *DispatchError values.
+//
+// Use it in tests to pin captured messages to their source and key:
+//
+//	route, err := dispatch.Resolve(r, fixture)
+//	require.NoError(t, err)
+//	assert.Equal(t, "user/created", route.Key)
+func Resolve(d Dispatcher, raw []byte) (Route, error) {
+	r := d.router()
+	cs, view := r.matchSource(raw)
+	if cs == nil {
+		return Route{}, dispatchError(StageMatch, "", "", ErrNoSource)
+	}
+	name := cs.source.Name()
+	msg, err := parseSource(cs.source, view, raw)
+	if err != nil {
+		return Route{Source: name}, dispatchError(StageParse, name, "", err)
+	}
+	p := &parsed{raw: raw, view: view, insp: r.inspectorFor(cs), msg: msg}
+	_, _, handled := r.handlerFor(context.Background(), p)
+	return Route{Source: name, Key: msg.Key, Handled: handled}, nil
+}
@@ -0,0 +1,158 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CheckSourcesSuite struct {
+	suite.Suite
+	router *Router
+}
+
+func (s *CheckSourcesSuite) SetupTest() {
+	parse := func([]byte) (Message, error) { return Message{}, nil }
+
+	s.router = New()
+	s.router.AddSource(SourceFunc("eventbridge", HasFields("source", "detail-type"), parse))
+	s.router.AddSource(SourceFunc("sns", FieldEquals("Type", "Notification"), parse))
+	s.router.AddSource(SourceFunc("loose", HasFields("source"), parse))
+	s.router.AddGroup(JSONInspector(), SourceFunc("grouped", HasFields("Type"), parse))
+}
+
+func TestCheckSourcesSuite(t *testing.T) {
+	suite.Run(t, new(CheckSourcesSuite))
+}
+
+func (s *CheckSourcesSuite) TestReturnsNilWhenExclusive() {
+	err := CheckSources(s.router, map[string][]byte{
+		"other":   []byte(`{"type": "x"}`),
+		"invalid": []byte(`not json`),
+	})
+
+	s.Assert().NoError(err)
+}
+
+func (s *CheckSourcesSuite) TestReportsAmbiguousSamples() {
+	err := CheckSources(s.router, map[string][]byte{
+		"eventbridge": []byte(`{"source": "my.app", "detail-type": "X"}`),
+		"sns":         []byte(`{"Type": "Notification"}`),
+	})
+
+	s.Require().Error(err)
+
+	var amb *AmbiguityError
+	s.Require().ErrorAs(err, &amb)
+	s.Assert().Equal("eventbridge", amb.Sample)
+	s.Assert().Equal([]string{"eventbridge", "loose"}, amb.Sources)
+	s.Assert().Contains(err.Error(), "sample sns matched multiple sources: sns, grouped")
+}
+
+type ResolveSuite struct {
+	suite.Suite
+	router *Router
+	called bool
+}
+
+func TestResolveSuite(t *testing.T) {
+	suite.Run(t, new(ResolveSuite))
+}
+
+func (s *ResolveSuite) SetupTest() {
+	s.called = false
+	s.router = New(WithOnNoSource(func(ctx context.Context, raw []byte) error {
+		s.called = true
+		return nil
+	}))
+	s.router.AddSource(&testSource{name: "test"})
+	RegisterProcFunc(s.router, "known", func(ctx context.Context, p struct{}) error {
+		s.called = true
+		return nil
+	})
+}
+
+func (s *ResolveSuite) TestRoutesWithoutRunningHandlers() {
+	route, err := Resolve(s.router, []byte(`{"type": "known", "payload": {}}`))
+
+	s.Require().NoError(err)
+	s.Assert().Equal(Route{Source: "test", Key: "known", Handled: true}, route)
+	s.Assert().False(s.called)
+}
+
+func (s *ResolveSuite) TestReportsUnhandledKeys() {
+	route, err := Resolve(s.router, []byte(`{"type": "other", "payload": {}}`))
+
+	s.Require().NoError(err)
+	s.Assert().Equal(Route{Source: "test", Key: "other"}, route)
+}
+
+func (s *ResolveSuite) TestNoSource() {
+	_, err := Resolve(s.router, []byte(`{"other": true}`))
+
+	s.Assert().ErrorIs(err, ErrNoSource)
+	s.Assert().False(s.called)
+}
+
+func (s *ResolveSuite) TestParseError() {
+	parseErr := errors.New("bad")
+	r := New()
+	r.AddSource(SourceFunc("broken", HasFields("type"), func([]byte) (Message, error) { return Message{}, parseErr }))
+
+	route, err := Resolve(r, []byte(`{"type": "x"}`))
+
+	s.Assert().ErrorIs(err, parseErr)
+	s.Assert().Equal("broken", route.Source)
+	var de *DispatchError
+	s.Require().ErrorAs(err, &de)
+	s.Assert().Equal(StageParse, de.Stage)
+}
+
+type CheckCoverageSuite struct {
+	suite.Suite
+	router *Router
+}
+
+func TestCheckCoverageSuite(t *testing.T) {
+	suite.Run(t, new(CheckCoverageSuite))
+}
+
+func (s *CheckCoverageSuite) SetupTest() {
+	s.router = New()
+	s.router.AddSource(&testSource{name: "test"})
+	for _, key := range []string{"user/created", "user/deleted"} {
+		RegisterProcFunc(s.router, key, func(ctx context.Context, p struct{}) error { return nil })
+	}
+}
+
+func (s *CheckCoverageSuite) TestReturnsNilWhenCovered() {
+	err := CheckCoverage(s.router, map[string][]byte{
+		"created":       []byte(`{"type": "user/created", "payload": {}}`),
+		"created-again": []byte(`{"type": "user/created", "payload": {"x": 1}}`),
+		"deleted":       []byte(`{"type": "user/deleted", "payload": {}}`),
+	})
+
+	s.Assert().NoError(err)
+}
+
+func (s *CheckCoverageSuite) TestReportsGaps() {
+	err := CheckCoverage(s.router, map[string][]byte{
+		"created": []byte(`{"type": "user/created", "payload": {}}`),
+		"renamed": []byte(`{"type": "user/renamed", "payload": {}}`),
+		"unknown": []byte(`{"other": true}`),
+	})
+
+	s.Require().Error(err)
+	s.Assert().ErrorIs(err, ErrNoSource)
+	var cerr *CoverageError
+	s.Require().ErrorAs(err, &cerr)
+	s.Assert().Equal(&CoverageError{Sample: "renamed", Key: "user/renamed"}, cerr)
+	s.Assert().Equal(
+		"sample renamed has no handler for key user/renamed\n"+
+			"sample unknown: no source matched message\n"+
+			"key user/deleted has no sample",
+		err.Error(),
+	)
+}
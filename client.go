@@ -0,0 +1,110 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// RemoteError is a failure reported by the handler of a Call, as passed to
+// its Replier's Fail method.
+type RemoteError struct {
+	Message string
+}
+
+func (e *RemoteError) Error() string {
+	return e.Message
+}
+
+// Client sends requests to a Func handler over a Transport and waits for the
+// result on a reply address, for request-response over queues. It is the
+// caller's side of Func and Replier.
+//
+// Each request carries the client's reply address in Event.ReplyTo and a
+// unique correlation ID. Whatever consumes the reply queue passes replies to
+// Deliver, which completes the matching Call.
+//
+// Example:
+//
+//	client := dispatch.NewClient(Events, sqs.NewPublisher(sqsClient, requestsURL), replyQueueURL)
+//	go consumeReplies(replyQueueURL, client) // calls client.Deliver for each reply
+//
+//	user, err := dispatch.Call[LookupUser, User](ctx, client, LookupUser{ID: "42"})
+type Client struct {
+	registry  *Registry
+	transport Transport
+	replyTo   string
+
+	mu      sync.Mutex
+	pending map[string]*ChannelReplier
+}
+
+// NewClient returns a Client that looks up keys in reg, sends requests with
+// t, and asks for replies at replyTo.
+func NewClient(reg *Registry, t Transport, replyTo string) *Client {
+	return &Client{
+		registry:  reg,
+		transport: t,
+		replyTo:   replyTo,
+		pending:   make(map[string]*ChannelReplier),
+	}
+}
+
+// Call sends payload with the key registered for T and waits until its reply
+// is delivered or ctx is done, then unmarshals the result into R. A failure
+// reported by the handler is returned as a *RemoteError.
+//
+// The request's correlation ID is its message ID, so replies can be matched
+// to it; it does not carry the correlation ID of ctx.
+//
+// Call is a package-level function because methods cannot have type
+// parameters.
+func Call[T, R any](ctx context.Context, c *Client, payload T, opts ...PublishOption) (R, error) {
+	var zero R
+	e, err := newEvent(ctx, c.registry, payload, opts)
+	if err != nil {
+		return zero, err
+	}
+	e.CorrelationID = e.MessageID
+	e.ReplyTo = c.replyTo
+
+	replier := NewChannelReplier()
+	c.mu.Lock()
+	c.pending[e.CorrelationID] = replier
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, e.CorrelationID)
+		c.mu.Unlock()
+	}()
+
+	if err := c.transport.Send(ctx, e); err != nil {
+		return zero, err
+	}
+	result, err := replier.Wait(ctx)
+	if err != nil {
+		return zero, err
+	}
+	var out R
+	if err := json.Unmarshal(result, &out); err != nil {
+		return zero, fmt.Errorf("unmarshal %s reply: %w", e.Key, err)
+	}
+	return out, nil
+}
+
+// Deliver completes the Call waiting for correlationID with result, or with
+// err if the handler failed. It reports whether a Call was waiting; replies
+// for calls that timed out, or were already completed, are dropped.
+func (c *Client) Deliver(correlationID string, result json.RawMessage, err error) bool {
+	c.mu.Lock()
+	replier, ok := c.pending[correlationID]
+	c.mu.Unlock()
+	if !ok {
+		return false
+	}
+	if err != nil {
+		return replier.Fail(context.Background(), err) == nil
+	}
+	return replier.Reply(context.Background(), result) == nil
+}
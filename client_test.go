@@ -0,0 +1,105 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ClientSuite struct {
+	suite.Suite
+	reg    *Registry
+	sent   []Event
+	reply  func(e Event)
+	client *Client
+}
+
+func TestClientSuite(t *testing.T) {
+	suite.Run(t, new(ClientSuite))
+}
+
+func (s *ClientSuite) SetupTest() {
+	s.reg = NewRegistry()
+	Register[testPayload](s.reg, "test")
+	s.sent = nil
+	s.reply = func(Event) {}
+	s.client = NewClient(s.reg, TransportFunc(func(ctx context.Context, e Event) error {
+		s.sent = append(s.sent, e)
+		go s.reply(e)
+		return nil
+	}), "replies")
+}
+
+func (s *ClientSuite) TestCallReturnsResult() {
+	s.reply = func(e Event) {
+		s.client.Deliver(e.CorrelationID, []byte(`{"value": "pong"}`), nil)
+	}
+
+	got, err := Call[testPayload, testPayload](context.Background(), s.client, testPayload{Value: "ping"})
+
+	s.Require().NoError(err)
+	s.Assert().Equal("pong", got.Value)
+	s.Require().Len(s.sent, 1)
+	e := s.sent[0]
+	s.Assert().Equal("test", e.Key)
+	s.Assert().JSONEq(`{"value": "ping"}`, string(e.Payload))
+	s.Assert().Equal("replies", e.ReplyTo)
+	s.Assert().Equal(e.MessageID, e.CorrelationID)
+}
+
+func (s *ClientSuite) TestCallReturnsRemoteError() {
+	s.reply = func(e Event) {
+		s.client.Deliver(e.CorrelationID, nil, &RemoteError{Message: "boom"})
+	}
+
+	_, err := Call[testPayload, testPayload](context.Background(), s.client, testPayload{})
+
+	var remote *RemoteError
+	s.Require().ErrorAs(err, &remote)
+	s.Assert().Equal("boom", remote.Message)
+}
+
+func (s *ClientSuite) TestCallTimesOut() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := Call[testPayload, testPayload](ctx, s.client, testPayload{})
+
+	s.Assert().ErrorIs(err, context.DeadlineExceeded)
+	s.Assert().False(s.client.Deliver(s.sent[0].CorrelationID, []byte(`{}`), nil))
+}
+
+func (s *ClientSuite) TestCallReturnsSendError() {
+	sendErr := errors.New("send")
+	client := NewClient(s.reg, TransportFunc(func(ctx context.Context, e Event) error {
+		return sendErr
+	}), "replies")
+
+	_, err := Call[testPayload, testPayload](context.Background(), client, testPayload{})
+
+	s.Assert().ErrorIs(err, sendErr)
+}
+
+func (s *ClientSuite) TestCallRejectsUnregisteredType() {
+	_, err := Call[validatablePayload, testPayload](context.Background(), s.client, validatablePayload{})
+
+	s.Assert().ErrorIs(err, ErrNotRegistered)
+	s.Assert().Empty(s.sent)
+}
+
+func (s *ClientSuite) TestCallRejectsBadResult() {
+	s.reply = func(e Event) {
+		s.client.Deliver(e.CorrelationID, []byte(`"text"`), nil)
+	}
+
+	_, err := Call[testPayload, testPayload](context.Background(), s.client, testPayload{})
+
+	s.Assert().ErrorContains(err, "unmarshal test reply")
+}
+
+func (s *ClientSuite) TestDeliverUnknownCall() {
+	s.Assert().False(s.client.Deliver("missing", []byte(`{}`), nil))
+}
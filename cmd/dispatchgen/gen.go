@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// initialisms are written in upper case in Go names, as golint expects.
+var initialisms = map[string]bool{
+	"API": true, "ARN": true, "CPU": true, "DNS": true, "HTML": true, "HTTP": true,
+	"HTTPS": true, "ID": true, "IP": true, "JSON": true, "SQL": true, "SQS": true,
+	"SKU": true, "SNS": true, "TTL": true, "UI": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goName converts a key, property, or schema name such as "user/created" or
+// "user_id" to an exported Go identifier such as UserCreated or UserID.
+func goName(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, w := range words {
+		if upper := strings.ToUpper(w); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	name := b.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// goType is a generated named type.
+type goType struct {
+	name   string
+	doc    string
+	fields []goField
+}
+
+type goField struct {
+	name string
+	typ  string
+	tag  string
+	doc  string
+}
+
+// generator turns JSON Schemas into Go types.
+type generator struct {
+	spec    *spec
+	pkg     string
+	source  string
+	types   []*goType
+	byRef   map[string]string
+	taken   map[string]bool
+	imports map[string]bool
+}
+
+func newGenerator(s *spec, pkg, source string) *generator {
+	return &generator{
+		spec:    s,
+		pkg:     pkg,
+		source:  source,
+		byRef:   make(map[string]string),
+		taken:   make(map[string]bool),
+		imports: make(map[string]bool),
+	}
+}
+
+// unique returns name, or name with a numeric suffix if it is already used.
+func (g *generator) unique(name string) string {
+	candidate := name
+	for i := 2; g.taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+	g.taken[candidate] = true
+	return candidate
+}
+
+// typeFor returns the Go type expression for schema, defining named struct
+// types as needed. hint names a struct defined inline.
+func (g *generator) typeFor(schema any, hint string) (string, error) {
+	if b, ok := schema.(bool); ok && b || schema == nil {
+		g.imports["encoding/json"] = true
+		return "json.RawMessage", nil
+	}
+	o, ok := schema.(*object)
+	if !ok {
+		return "", fmt.Errorf("%s: schema must be an object", hint)
+	}
+
+	if ref := o.str("$ref"); ref != "" {
+		if name, ok := g.byRef[ref]; ok {
+			return name, nil
+		}
+		target, err := g.spec.resolve(ref)
+		if err != nil {
+			return "", err
+		}
+		if isStruct(target) {
+			name := g.unique(goName(ref[strings.LastIndex(ref, "/")+1:]))
+			g.byRef[ref] = name
+			return name, g.defineStruct(name, target.(*object))
+		}
+		return g.typeFor(target, hint)
+	}
+
+	types := schemaTypes(o)
+	nullable := slices.Contains(types, "null")
+	types = slices.DeleteFunc(types, func(t string) bool { return t == "null" })
+	if len(types) > 1 {
+		g.imports["encoding/json"] = true
+		return "json.RawMessage", nil
+	}
+	typ := ""
+	if len(types) == 1 {
+		typ = types[0]
+	} else if o.obj("properties") != nil {
+		typ = "object"
+	}
+
+	var expr string
+	switch typ {
+	case "string":
+		switch {
+		case o.str("format") == "date-time":
+			g.imports["time"] = true
+			expr = "time.Time"
+		case o.str("contentEncoding") == "base64":
+			return "[]byte", nil
+		default:
+			expr = "string"
+		}
+	case "integer":
+		expr = "int64"
+	case "number":
+		expr = "float64"
+	case "boolean":
+		expr = "bool"
+	case "array":
+		elem, err := g.typeFor(o.get("items"), hint+"Item")
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	case "object":
+		if isStruct(o) {
+			name := g.unique(hint)
+			if err := g.defineStruct(name, o); err != nil {
+				return "", err
+			}
+			expr = name
+			break
+		}
+		elem, err := g.typeFor(o.get("additionalProperties"), hint+"Value")
+		if err != nil {
+			return "", err
+		}
+		return "map[string]" + elem, nil
+	default:
+		g.imports["encoding/json"] = true
+		return "json.RawMessage", nil
+	}
+	if nullable {
+		return "*" + expr, nil
+	}
+	return expr, nil
+}
+
+func schemaTypes(o *object) []string {
+	switch v := o.get("type").(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var types []string
+		for _, t := range v {
+			if s, ok := t.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// isStruct reports whether schema describes an object with properties.
+func isStruct(schema any) bool {
+	o, ok := schema.(*object)
+	return ok && o.obj("properties") != nil
+}
+
+func (g *generator) defineStruct(name string, o *object) error {
+	t := &goType{name: name, doc: o.str("description")}
+	g.types = append(g.types, t)
+
+	var required []string
+	if list, ok := o.get("required").([]any); ok {
+		for _, r := range list {
+			if s, ok := r.(string); ok {
+				required = append(required, s)
+			}
+		}
+	}
+
+	props := o.obj("properties")
+	fieldNames := make(map[string]bool)
+	for _, prop := range props.keys {
+		fieldName := goName(prop)
+		for i := 2; fieldNames[fieldName]; i++ {
+			fieldName = fmt.Sprintf("%s%d", goName(prop), i)
+		}
+		fieldNames[fieldName] = true
+
+		typ, err := g.typeFor(props.get(prop), name+fieldName)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", name, prop, err)
+		}
+		tag := prop
+		optional := !slices.Contains(required, prop)
+		if optional {
+			tag += ",omitempty"
+		}
+		if g.isStructType(typ) && (optional || typ == name) {
+			typ = "*" + typ
+		}
+		doc := ""
+		if p, ok := props.get(prop).(*object); ok {
+			doc = p.str("description")
+		}
+		t.fields = append(t.fields, goField{name: fieldName, typ: typ, tag: fmt.Sprintf("`json:%q`", tag), doc: doc})
+	}
+	return nil
+}
+
+func (g *generator) isStructType(typ string) bool {
+	return slices.ContainsFunc(g.types, func(t *goType) bool { return t.name == typ })
+}
+
+// handler is an event with its resolved Go types.
+type handler struct {
+	event
+	payloadType string
+	replyType   string // empty for Procs
+}
+
+// generate returns the formatted source of the generated file, and of the
+// handler stubs file.
+func (g *generator) generate() (code, stubs []byte, err error) {
+	var handlers []handler
+	names := make(map[string]bool)
+	for _, e := range g.spec.events {
+		for i := 2; names[e.name]; i++ {
+			e.name = fmt.Sprintf("%s%d", goName(e.key), i)
+		}
+		names[e.name] = true
+		h := handler{event: e}
+		if h.payloadType, err = g.typeFor(e.payload, e.name); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", e.key, err)
+		}
+		if e.reply != nil {
+			if h.replyType, err = g.typeFor(e.reply, e.name+"Reply"); err != nil {
+				return nil, nil, fmt.Errorf("%s reply: %w", e.key, err)
+			}
+		}
+		handlers = append(handlers, h)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by dispatchgen from %s. DO NOT EDIT.\n\n", g.source)
+	fmt.Fprintf(&b, "package %s\n\n", g.pkg)
+	b.WriteString("import (\n")
+	for _, imp := range slices.Sorted(maps.Keys(g.imports)) {
+		fmt.Fprintf(&b, "\t%q\n", imp)
+	}
+	b.WriteString("\n\t\"github.com/bjaus/dispatch\"\n)\n\n")
+
+	b.WriteString("// Routing keys, tied to their payload types.\nvar (\n")
+	for _, h := range handlers {
+		fmt.Fprintf(&b, "\t%sKey = dispatch.NewKey[%s](%q)\n", h.name, h.payloadType, h.key)
+	}
+	b.WriteString(")\n\n")
+
+	for _, t := range g.types {
+		writeDoc(&b, "", t.doc)
+		fmt.Fprintf(&b, "type %s struct {\n", t.name)
+		for _, f := range t.fields {
+			writeDoc(&b, "\t", f.doc)
+			fmt.Fprintf(&b, "\t%s %s %s\n", f.name, f.typ, f.tag)
+		}
+		b.WriteString("}\n\n")
+	}
+
+	b.WriteString("// Handlers holds a handler for each key. Register skips nil handlers.\ntype Handlers struct {\n")
+	for _, h := range handlers {
+		fmt.Fprintf(&b, "\t%s %s\n", h.name, h.handlerType())
+	}
+	b.WriteString("}\n\n")
+
+	b.WriteString("// Register adds the non-nil handlers in h to r.\nfunc Register(r *dispatch.Router, h Handlers) {\n")
+	for _, h := range handlers {
+		fn := "RegisterProcKey"
+		if h.replyType != "" {
+			fn = "RegisterFuncKey"
+		}
+		fmt.Fprintf(&b, "\tif h.%s != nil {\n\t\tdispatch.%s(r, %sKey, h.%s)\n\t}\n", h.name, fn, h.name, h.name)
+	}
+	b.WriteString("}\n")
+
+	if code, err = format.Source(b.Bytes()); err != nil {
+		return nil, nil, fmt.Errorf("format generated code: %w", err)
+	}
+	if stubs, err = format.Source(g.stubs(handlers)); err != nil {
+		return nil, nil, fmt.Errorf("format stubs: %w", err)
+	}
+	return code, stubs, nil
+}
+
+// stubs returns handler implementations to fill in. Unlike the generated
+// file, the stubs are meant to be edited.
+func (g *generator) stubs(handlers []handler) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "package %s\n\nimport \"context\"\n\n", g.pkg)
+	for _, h := range handlers {
+		if h.replyType == "" {
+			fmt.Fprintf(&b, "// %sProc handles %sKey messages.\ntype %sProc struct{}\n\n", h.name, h.name, h.name)
+			fmt.Fprintf(&b, "// Run implements dispatch.Proc.\nfunc (p *%sProc) Run(ctx context.Context, payload %s) error {\n\treturn nil\n}\n\n", h.name, h.payloadType)
+			continue
+		}
+		fmt.Fprintf(&b, "// %sFunc handles %sKey messages.\ntype %sFunc struct{}\n\n", h.name, h.name, h.name)
+		fmt.Fprintf(&b, "// Call implements dispatch.Func.\nfunc (f *%sFunc) Call(ctx context.Context, payload %s) (%s, error) {\n\tvar result %s\n\treturn result, nil\n}\n\n", h.name, h.payloadType, h.replyType, h.replyType)
+	}
+	b.WriteString("// NewHandlers returns the handlers, for Register.\nfunc NewHandlers() Handlers {\n\treturn Handlers{\n")
+	for _, h := range handlers {
+		suffix := "Proc"
+		if h.replyType != "" {
+			suffix = "Func"
+		}
+		fmt.Fprintf(&b, "\t\t%s: &%s%s{},\n", h.name, h.name, suffix)
+	}
+	b.WriteString("\t}\n}\n")
+	return b.Bytes()
+}
+
+func (h handler) handlerType() string {
+	if h.replyType == "" {
+		return fmt.Sprintf("dispatch.Proc[%s]", h.payloadType)
+	}
+	return fmt.Sprintf("dispatch.Func[%s, %s]", h.payloadType, h.replyType)
+}
+
+func writeDoc(b *bytes.Buffer, indent, doc string) {
+	for _, line := range strings.Split(strings.TrimSpace(doc), "\n") {
+		if line != "" {
+			fmt.Fprintf(b, "%s// %s\n", indent, strings.TrimSpace(line))
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+type GenSuite struct {
+	suite.Suite
+}
+
+func TestGenSuite(t *testing.T) {
+	suite.Run(t, new(GenSuite))
+}
+
+// golden compares got with testdata/name, or rewrites it with -update.
+func (s *GenSuite) golden(name string, got []byte) {
+	path := filepath.Join("testdata", name)
+	if *update {
+		s.Require().NoError(os.WriteFile(path, got, 0o644))
+	}
+	want, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	s.Assert().Equal(string(want), string(got))
+}
+
+func (s *GenSuite) generate(file, key string) (code, stubs []byte) {
+	data, err := os.ReadFile(filepath.Join("testdata", file))
+	s.Require().NoError(err)
+	spec, err := parseSpec(data, key)
+	s.Require().NoError(err)
+	code, stubs, err = newGenerator(spec, "events", file).generate()
+	s.Require().NoError(err)
+	return code, stubs
+}
+
+func (s *GenSuite) TestAsyncAPI() {
+	code, stubs := s.generate("orders.yaml", "")
+
+	s.golden("orders.golden", code)
+	s.golden("orders_stubs.golden", stubs)
+}
+
+func (s *GenSuite) TestJSONSchema() {
+	code, _ := s.generate("user-created.schema.json", "user/created")
+
+	s.golden("user-created.golden", code)
+}
+
+type lookup struct {
+	ID string `json:"id"`
+}
+
+type user struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+}
+
+func (s *GenSuite) TestRoundTripsGeneratedAsyncAPI() {
+	r := dispatch.New()
+	dispatch.RegisterFuncFunc(r, "lookup-user", func(ctx context.Context, in lookup) (user, error) { return user{}, nil })
+	doc, err := dispatch.AsyncAPI(r, dispatch.AsyncAPIInfo{Title: "users", Version: "1"})
+	s.Require().NoError(err)
+
+	spec, err := parseSpec(doc, "")
+	s.Require().NoError(err)
+	code, _, err := newGenerator(spec, "users", "asyncapi.json").generate()
+	s.Require().NoError(err)
+
+	s.Assert().Contains(string(code), `LookupUserKey = dispatch.NewKey[Lookup]("lookup-user")`)
+	s.Assert().Contains(string(code), "LookupUser dispatch.Func[Lookup, User]")
+	s.Assert().Contains(string(code), "Email string `json:\"email,omitempty\"`")
+}
+
+func (s *GenSuite) TestSpecErrors() {
+	tests := []struct {
+		name string
+		doc  string
+		key  string
+		want string
+	}{
+		{"not an object", `[]`, "", "document must be an object"},
+		{"old asyncapi", `{"asyncapi": "2.6.0"}`, "", "unsupported AsyncAPI version 2.6.0, want 3.x"},
+		{"key with asyncapi", `{"asyncapi": "3.0.0"}`, "k", "-key only applies to JSON Schema input"},
+		{"schema without key", `{"type": "object"}`, "", "-key is required for JSON Schema input"},
+		{"remote ref", `{"asyncapi": "3.0.0", "channels": {"a": {"$ref": "other.yaml#/a"}}}`, "", `channel a: unsupported $ref "other.yaml#/a": only local references are supported`},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			_, err := parseSpec([]byte(tt.doc), tt.key)
+			s.Assert().EqualError(err, tt.want)
+		})
+	}
+}
+
+func (s *GenSuite) TestGoName() {
+	for in, want := range map[string]string{
+		"user/created": "UserCreated",
+		"user_id":      "UserID",
+		"lookup-user":  "LookupUser",
+		"api.v2.url":   "APIV2URL",
+		"123":          "X123",
+		"":             "X",
+	} {
+		s.Assert().Equal(want, goName(in), in)
+	}
+}
+
+func (s *GenSuite) TestRunWritesFilesWithoutOverwritingStubs() {
+	dir := s.T().TempDir()
+	out := filepath.Join(dir, "gen.go")
+	stubs := filepath.Join(dir, "handlers.go")
+	s.Require().NoError(os.WriteFile(stubs, []byte("package events\n"), 0o644))
+	var stderr bytes.Buffer
+
+	err := run([]string{"-in", "testdata/orders.yaml", "-out", out, "-stubs", stubs}, &stderr)
+
+	s.Require().NoError(err)
+	s.Assert().FileExists(out)
+	data, err := os.ReadFile(stubs)
+	s.Require().NoError(err)
+	s.Assert().Equal("package events\n", string(data))
+	s.Assert().Contains(stderr.String(), "exists, not writing stubs")
+}
+
+func (s *GenSuite) TestRunRequiresInput() {
+	s.Assert().EqualError(run(nil, &bytes.Buffer{}), "-in is required")
+}
+
+func (s *GenSuite) TestRunConsts() {
+	out := filepath.Join(s.T().TempDir(), "keys.go")
+
+	s.Require().NoError(run([]string{"-in", "testdata/orders.yaml", "-out", out, "-consts"}, &bytes.Buffer{}))
+
+	code, err := os.ReadFile(out)
+	s.Require().NoError(err)
+	s.golden("orders_keys.golden", code)
+}
@@ -0,0 +1,110 @@
+// Command dispatchgen generates payload types, typed routing keys, and
+// handler registration code from an AsyncAPI 3 document or a JSON Schema.
+//
+// Usage:
+//
+//	dispatchgen -in asyncapi.yaml -pkg events -out dispatch_gen.go -stubs handlers.go
+//	dispatchgen -in user-created.schema.json -key user/created -pkg events
+//
+// The generated file declares a dispatch.Key for each routing key, a struct
+// for each payload and reply schema, a Handlers struct with a field per key,
+// and a Register function that registers the non-nil handlers. With -stubs,
+// dispatchgen also writes Proc and Func implementations to fill in, and a
+// NewHandlers function returning them; an existing stubs file is never
+// overwritten.
+//
+// With -consts, dispatchgen writes only a string constant for each routing
+// key, as dispatch.Keys.GenerateGo does for the keys a router handles:
+//
+//	dispatchgen -in asyncapi.yaml -pkg events -out keys_gen.go -consts
+//
+// Use it with go generate:
+//
+//	//go:generate go run github.com/bjaus/dispatch/cmd/dispatchgen -in asyncapi.yaml -pkg events
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/bjaus/dispatch"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "dispatchgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stderr io.Writer) error {
+	flags := flag.NewFlagSet("dispatchgen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	in := flags.String("in", "", "AsyncAPI 3 document or JSON Schema, as JSON or YAML (required)")
+	pkg := flags.String("pkg", "events", "package name of the generated code")
+	out := flags.String("out", "dispatch_gen.go", "generated file")
+	stubsPath := flags.String("stubs", "", "file to write handler stubs to, if it does not exist")
+	key := flags.String("key", "", "routing key of the payload, for JSON Schema input")
+	consts := flags.Bool("consts", false, "generate only routing key constants")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" {
+		flags.Usage()
+		return errors.New("-in is required")
+	}
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+	s, err := parseSpec(data, *key)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *in, err)
+	}
+	if len(s.events) == 0 {
+		return fmt.Errorf("%s: no messages found", *in)
+	}
+
+	if *consts {
+		keys := dispatch.NewKeys()
+		for _, e := range s.events {
+			keys.Add(e.key)
+		}
+		code, err := keys.GenerateGo(*pkg)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(*out, code, 0o644)
+	}
+
+	code, stubs, err := newGenerator(s, *pkg, filepath.Base(*in)).generate()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out, code, 0o644); err != nil {
+		return err
+	}
+
+	if *stubsPath == "" {
+		return nil
+	}
+	f, err := os.OpenFile(*stubsPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		fmt.Fprintf(stderr, "dispatchgen: %s exists, not writing stubs\n", *stubsPath)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(stubs); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// object is a decoded JSON or YAML mapping that remembers its key order, so
+// generated struct fields follow the order of the schema's properties.
+type object struct {
+	keys []string
+	vals map[string]any
+}
+
+func (o *object) get(key string) any {
+	if o == nil {
+		return nil
+	}
+	return o.vals[key]
+}
+
+// names returns the object's keys in document order.
+func (o *object) names() []string {
+	if o == nil {
+		return nil
+	}
+	return o.keys
+}
+
+func (o *object) obj(key string) *object {
+	v, _ := o.get(key).(*object)
+	return v
+}
+
+func (o *object) str(key string) string {
+	v, _ := o.get(key).(string)
+	return v
+}
+
+// decode parses a JSON or YAML document into *object, []any, string,
+// float64, bool, and nil values.
+func decode(data []byte) (any, error) {
+	var n yaml.Node
+	if err := yaml.Unmarshal(data, &n); err != nil {
+		return nil, err
+	}
+	if n.Kind == 0 {
+		return nil, errors.New("empty document")
+	}
+	return convert(&n)
+}
+
+func convert(n *yaml.Node) (any, error) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		return convert(n.Content[0])
+	case yaml.AliasNode:
+		return convert(n.Alias)
+	case yaml.MappingNode:
+		o := &object{vals: make(map[string]any, len(n.Content)/2)}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i].Value
+			v, err := convert(n.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := o.vals[key]; !dup {
+				o.keys = append(o.keys, key)
+			}
+			o.vals[key] = v
+		}
+		return o, nil
+	case yaml.SequenceNode:
+		list := make([]any, 0, len(n.Content))
+		for _, c := range n.Content {
+			v, err := convert(c)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	default:
+		var v any
+		if err := n.Decode(&v); err != nil {
+			return nil, err
+		}
+		if i, ok := v.(int); ok {
+			return float64(i), nil
+		}
+		return v, nil
+	}
+}
+
+// event is one routing key to generate a payload type and handler for.
+type event struct {
+	key     string
+	name    string // Go name, such as UserCreated
+	payload any    // JSON Schema
+	reply   any    // JSON Schema of a Func's result; nil for Procs
+}
+
+// spec is a parsed input document.
+type spec struct {
+	root   any
+	events []event
+}
+
+// parseSpec reads an AsyncAPI 3 document, or a JSON Schema describing the
+// payload of key.
+func parseSpec(data []byte, key string) (*spec, error) {
+	root, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	doc, ok := root.(*object)
+	if !ok {
+		return nil, errors.New("document must be an object")
+	}
+	s := &spec{root: root}
+
+	if v := doc.str("asyncapi"); v != "" {
+		if !strings.HasPrefix(v, "3.") {
+			return nil, fmt.Errorf("unsupported AsyncAPI version %s, want 3.x", v)
+		}
+		if key != "" {
+			return nil, errors.New("-key only applies to JSON Schema input")
+		}
+		if err := s.parseAsyncAPI(doc); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+
+	if key == "" {
+		return nil, errors.New("-key is required for JSON Schema input")
+	}
+	name := doc.str("title")
+	if name == "" {
+		name = key
+	}
+	s.events = []event{{key: key, name: goName(name), payload: root}}
+	return s, nil
+}
+
+// parseAsyncAPI collects the messages of every operation, or of every
+// channel if there are no operations. Reply messages become Func results.
+func (s *spec) parseAsyncAPI(doc *object) error {
+	seen := make(map[string]bool)
+	add := func(ch *object, msgRef any, reply *object) error {
+		msg, id, err := s.message(msgRef)
+		if err != nil {
+			return err
+		}
+		key := msg.str("name")
+		if key == "" && len(ch.obj("messages").names()) == 1 {
+			key = ch.str("address")
+		}
+		if key == "" {
+			key = id
+		}
+		if seen[key] {
+			return nil
+		}
+		seen[key] = true
+		var replyPayload any
+		if reply != nil {
+			replyPayload = reply.get("payload")
+		}
+		s.events = append(s.events, event{key: key, name: goName(key), payload: msg.get("payload"), reply: replyPayload})
+		return nil
+	}
+
+	ops := doc.obj("operations")
+	for _, opName := range ops.names() {
+		op := ops.obj(opName)
+		ch, err := s.deref(op.get("channel"))
+		if err != nil {
+			return fmt.Errorf("operation %s: %w", opName, err)
+		}
+		var reply *object
+		if r := op.obj("reply"); r != nil {
+			if list, _ := r.get("messages").([]any); len(list) > 0 {
+				if reply, _, err = s.message(list[0]); err != nil {
+					return fmt.Errorf("operation %s reply: %w", opName, err)
+				}
+			}
+		}
+		msgs, _ := op.get("messages").([]any)
+		if len(msgs) == 0 {
+			for _, id := range ch.obj("messages").names() {
+				msgs = append(msgs, ch.obj("messages").get(id))
+			}
+		}
+		for _, m := range msgs {
+			if err := add(ch, m, reply); err != nil {
+				return fmt.Errorf("operation %s: %w", opName, err)
+			}
+		}
+	}
+	if ops != nil {
+		return nil
+	}
+
+	channels := doc.obj("channels")
+	for _, chName := range channels.names() {
+		ch, err := s.deref(channels.get(chName))
+		if err != nil {
+			return fmt.Errorf("channel %s: %w", chName, err)
+		}
+		for _, id := range ch.obj("messages").names() {
+			if err := add(ch, ch.obj("messages").get(id), nil); err != nil {
+				return fmt.Errorf("channel %s: %w", chName, err)
+			}
+		}
+	}
+	return nil
+}
+
+// message resolves a message or message reference, returning the message and
+// its id, the last segment of its reference.
+func (s *spec) message(v any) (*object, string, error) {
+	id := ""
+	if o, ok := v.(*object); ok {
+		if ref := o.str("$ref"); ref != "" {
+			id = ref[strings.LastIndex(ref, "/")+1:]
+		}
+	}
+	msg, err := s.deref(v)
+	if err != nil {
+		return nil, "", err
+	}
+	return msg, id, nil
+}
+
+// deref follows $ref until it reaches an object without one.
+func (s *spec) deref(v any) (*object, error) {
+	for range 32 {
+		o, ok := v.(*object)
+		if !ok {
+			return nil, fmt.Errorf("expected an object, got %T", v)
+		}
+		ref := o.str("$ref")
+		if ref == "" {
+			return o, nil
+		}
+		var err error
+		if v, err = s.resolve(ref); err != nil {
+			return nil, err
+		}
+	}
+	return nil, errors.New("too many nested $refs")
+}
+
+// resolve returns the value a local JSON Pointer reference points to.
+func (s *spec) resolve(ref string) (any, error) {
+	if ref == "#" {
+		return s.root, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q: only local references are supported", ref)
+	}
+	v := s.root
+	for _, tok := range strings.Split(ref[2:], "/") {
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		o, ok := v.(*object)
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+		if v, ok = o.vals[tok]; !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+	}
+	return v, nil
+}
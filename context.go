@@ -0,0 +1,65 @@
+package dispatch
+
+import "context"
+
+type messageKey struct{}
+
+// MessageFromContext returns the parsed Message for the message being
+// processed. It is available to hooks from OnParse onward and to handlers,
+// which lets integrations read fields such as Version without changing hook
+// signatures.
+//
+// Example:
+//
+//	dispatch.WithOnParse(func(ctx context.Context, source, key string) context.Context {
+//	    if msg, ok := dispatch.MessageFromContext(ctx); ok {
+//	        span.SetAttributes(attribute.String("version", msg.Version))
+//	    }
+//	    return ctx
+//	})
+func MessageFromContext(ctx context.Context) (Message, bool) {
+	msg, ok := ctx.Value(messageKey{}).(Message)
+	return msg, ok
+}
+
+// withMessage returns a context carrying msg for MessageFromContext.
+func withMessage(ctx context.Context, msg Message) context.Context {
+	return context.WithValue(ctx, messageKey{}, msg)
+}
+
+// Attribute returns the named attribute of the message being processed. It
+// reports false if ctx carries no message or the attribute is not set.
+//
+// Example:
+//
+//	func (p *OrderProc) Run(ctx context.Context, in OrderPayload) error {
+//	    tenant, _ := dispatch.Attribute(ctx, "tenant")
+//	    return p.orders.Create(ctx, tenant, in)
+//	}
+func Attribute(ctx context.Context, name string) (string, bool) {
+	msg, ok := MessageFromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	v, ok := msg.Attributes[name]
+	return v, ok
+}
+
+// MessageID returns the MessageID of the message being processed, or an
+// empty string if ctx carries no message.
+func MessageID(ctx context.Context) string {
+	msg, _ := MessageFromContext(ctx)
+	return msg.MessageID
+}
+
+// CorrelationID returns the CorrelationID of the message being processed, or
+// an empty string if ctx carries no message. Pass it on outgoing calls and
+// messages to keep a request traceable across services.
+//
+// Example:
+//
+//	req.Header.Set("X-Correlation-ID", dispatch.CorrelationID(ctx))
+func CorrelationID(ctx context.Context) string {
+	msg, _ := MessageFromContext(ctx)
+	return msg.CorrelationID
+}
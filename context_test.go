@@ -0,0 +1,121 @@
+package dispatch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MessageFromContextSuite struct {
+	suite.Suite
+}
+
+func TestMessageFromContextSuite(t *testing.T) {
+	suite.Run(t, new(MessageFromContextSuite))
+}
+
+func (s *MessageFromContextSuite) TestAvailableToHooksAndHandlers() {
+	var fromHook, fromHandler Message
+
+	r := New(WithOnParse(func(ctx context.Context, source, key string) context.Context {
+		fromHook, _ = MessageFromContext(ctx)
+		return ctx
+	}))
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Version: "v2", Payload: []byte(`{}`)}, nil
+	}))
+	RegisterProcFunc(r, "test", func(ctx context.Context, p struct{}) error {
+		fromHandler, _ = MessageFromContext(ctx)
+		return nil
+	})
+
+	err := r.Process(context.Background(), []byte(`{"type": "test"}`))
+
+	s.Require().NoError(err)
+	s.Assert().Equal("v2", fromHook.Version)
+	s.Assert().Equal("test", fromHandler.Key)
+}
+
+func (s *MessageFromContextSuite) TestReturnsFalseOutsideProcess() {
+	_, ok := MessageFromContext(context.Background())
+	s.Assert().False(ok)
+}
+
+func (s *MessageFromContextSuite) TestAttribute() {
+	var tenant string
+	var found, missing bool
+
+	r := New()
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{
+			Key:        "test",
+			Payload:    []byte(`{}`),
+			Attributes: map[string]string{"tenant": "acme"},
+		}, nil
+	}))
+	RegisterProcFunc(r, "test", func(ctx context.Context, p struct{}) error {
+		tenant, found = Attribute(ctx, "tenant")
+		_, missing = Attribute(ctx, "region")
+		return nil
+	})
+
+	err := r.Process(context.Background(), []byte(`{"type": "test"}`))
+
+	s.Require().NoError(err)
+	s.Assert().True(found)
+	s.Assert().Equal("acme", tenant)
+	s.Assert().False(missing)
+
+	_, ok := Attribute(context.Background(), "tenant")
+	s.Assert().False(ok)
+}
+
+func (s *MessageFromContextSuite) TestMessageAndCorrelationIDs() {
+	tests := map[string]struct {
+		msg             Message
+		wantMessage     string
+		wantCorrelation string
+	}{
+		"both set": {
+			msg:             Message{MessageID: "m-1", CorrelationID: "c-1"},
+			wantMessage:     "m-1",
+			wantCorrelation: "c-1",
+		},
+		"correlation defaults to message ID": {
+			msg:             Message{MessageID: "m-1"},
+			wantMessage:     "m-1",
+			wantCorrelation: "m-1",
+		},
+		"neither set": {},
+	}
+
+	for name, tt := range tests {
+		s.Run(name, func() {
+			var gotMessage, gotCorrelation, replyCorrelation string
+
+			msg := tt.msg
+			msg.Key = "test"
+			msg.Payload = []byte(`{}`)
+			msg.Replier = completeReplier(func(ctx context.Context, err error) error {
+				replyCorrelation = CorrelationID(ctx)
+				return err
+			})
+
+			r := New()
+			r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+				return msg, nil
+			}))
+			RegisterProcFunc(r, "test", func(ctx context.Context, p struct{}) error {
+				gotMessage = MessageID(ctx)
+				gotCorrelation = CorrelationID(ctx)
+				return nil
+			})
+
+			s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test"}`)))
+			s.Assert().Equal(tt.wantMessage, gotMessage)
+			s.Assert().Equal(tt.wantCorrelation, gotCorrelation)
+			s.Assert().Equal(tt.wantCorrelation, replyCorrelation)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package dispatch
+
+import (
+	"context"
+	"fmt"
+)
+
+// DeadLetter describes a message that a policy hook decided to skip.
+type DeadLetter struct {
+	// Raw is the message exactly as passed to Process.
+	Raw []byte
+
+	// Stage is where the message failed before it was skipped.
+	Stage Stage
+
+	// Source and Key identify the message when known; Source is empty for
+	// StageMatch and Key is empty before the message is parsed.
+	Source string
+	Key    string
+
+	// MessageID and CorrelationID are copied from the parsed message, if any.
+	MessageID     string
+	CorrelationID string
+
+	// Err is why the message failed.
+	Err error
+}
+
+// DeadLetterer stores messages that hooks skip, such as by publishing them to
+// a dead-letter queue, so skipped messages are kept without relying on
+// queue-level redrive. See WithDeadLetterer.
+type DeadLetterer interface {
+	DeadLetter(ctx context.Context, dl DeadLetter) error
+}
+
+// DeadLettererFunc is a function adapter for DeadLetterer.
+type DeadLettererFunc func(ctx context.Context, dl DeadLetter) error
+
+// DeadLetter implements DeadLetterer.
+func (f DeadLettererFunc) DeadLetter(ctx context.Context, dl DeadLetter) error {
+	return f(ctx, dl)
+}
+
+// WithDeadLetterer sends every message that a policy hook skips to dl, with
+// the raw bytes and failure details. That covers skips by WithOnNoSource,
+// WithOnParseError, WithOnNoHandler, WithOnUnmarshalError,
+// WithOnValidationError, and WithOnError (including WithErrorPolicy's
+// ActionSkip).
+//
+// If dl returns an error, the message fails with it instead of being skipped,
+// so it is redelivered rather than lost.
+//
+// Example:
+//
+//	r := dispatch.New(
+//	    dispatch.WithDeadLetterer(sqs.NewDeadLetterer(client, dlqURL)),
+//	    dispatch.WithOnUnmarshalError(func(ctx context.Context, source, key string, err error) error {
+//	        return nil // skip to the DLQ
+//	    }),
+//	)
+func WithDeadLetterer(dl DeadLetterer) Option {
+	return func(r *Router) {
+		r.deadLetterer = dl
+	}
+}
+
+// rawKey is the context key for the raw message, set only when a
+// DeadLetterer or QuarantineStore is configured.
+type rawKey struct{}
+
+// withRaw returns ctx carrying raw if the router dead-letters or quarantines
+// messages.
+func (r *Router) withRaw(ctx context.Context, raw []byte) context.Context {
+	if r.deadLetterer == nil && r.quarantine == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, rawKey{}, raw)
+}
+
+// deadLetter sends a skipped message to the DeadLetterer, if any.
+func (r *Router) deadLetter(ctx context.Context, stage Stage, sourceName, key string, cause error) error {
+	if r.deadLetterer == nil {
+		return nil
+	}
+	raw, _ := ctx.Value(rawKey{}).([]byte)
+	dl := DeadLetter{Raw: raw, Stage: stage, Source: sourceName, Key: key, Err: cause}
+	if msg, ok := MessageFromContext(ctx); ok {
+		dl.MessageID = msg.MessageID
+		dl.CorrelationID = msg.CorrelationID
+	}
+	if err := r.deadLetterer.DeadLetter(ctx, dl); err != nil {
+		return fmt.Errorf("dead letter: %w", err)
+	}
+	return nil
+}
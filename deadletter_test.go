@@ -0,0 +1,103 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DeadLettererSuite struct {
+	suite.Suite
+	letters []DeadLetter
+	err     error
+}
+
+func TestDeadLettererSuite(t *testing.T) {
+	suite.Run(t, new(DeadLettererSuite))
+}
+
+func (s *DeadLettererSuite) SetupTest() {
+	s.letters = nil
+	s.err = nil
+}
+
+func (s *DeadLettererSuite) router(opts ...Option) *Router {
+	opts = append(opts, WithDeadLetterer(DeadLettererFunc(func(ctx context.Context, dl DeadLetter) error {
+		s.letters = append(s.letters, dl)
+		return s.err
+	})))
+	r := New(opts...)
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		v, _ := JSONInspector().Inspect(raw)
+		key, _ := v.GetString("type")
+		if key == "" {
+			return Message{}, errors.New("missing type")
+		}
+		payload, _ := v.GetBytes("payload")
+		return Message{Key: key, MessageID: "m-1", Payload: payload}, nil
+	}))
+	return r
+}
+
+func (s *DeadLettererSuite) TestSkipsAreDeadLettered() {
+	skip := func(ctx context.Context, stage Stage, source, key string, err error) error { return nil }
+	r := s.router(WithOnError(skip))
+	RegisterProc(r, "fails", &testHandler{err: errors.New("handler failed")})
+	RegisterProcFunc(r, "validated", func(ctx context.Context, p validatablePayload) error { return nil })
+
+	tests := map[string]struct {
+		raw   string
+		stage Stage
+		key   string
+	}{
+		"no source":  {raw: `{"other": true}`, stage: StageMatch},
+		"parse":      {raw: `{"type": 1}`, stage: StageParse},
+		"no handler": {raw: `{"type": "missing", "payload": {}}`, stage: StageRoute, key: "missing"},
+		"unmarshal":  {raw: `{"type": "fails", "payload": "bad"}`, stage: StageUnmarshal, key: "fails"},
+		"validation": {raw: `{"type": "validated", "payload": {}}`, stage: StageValidate, key: "validated"},
+		"handler":    {raw: `{"type": "fails", "payload": {}}`, stage: StageHandle, key: "fails"},
+	}
+
+	for name, tt := range tests {
+		s.Run(name, func() {
+			s.letters = nil
+
+			s.Require().NoError(r.Process(context.Background(), []byte(tt.raw)))
+
+			s.Require().Len(s.letters, 1)
+			dl := s.letters[0]
+			s.Assert().Equal(tt.raw, string(dl.Raw))
+			s.Assert().Equal(tt.stage, dl.Stage)
+			s.Assert().Equal(tt.key, dl.Key)
+			s.Assert().Error(dl.Err)
+			if tt.stage != StageMatch {
+				s.Assert().Equal("test", dl.Source)
+			}
+			if tt.key != "" {
+				s.Assert().Equal("m-1", dl.MessageID)
+				s.Assert().Equal("m-1", dl.CorrelationID)
+			}
+		})
+	}
+}
+
+func (s *DeadLettererSuite) TestFailuresAreNotDeadLettered() {
+	r := s.router()
+	RegisterProc(r, "fails", &testHandler{err: errors.New("handler failed")})
+
+	s.Assert().Error(r.Process(context.Background(), []byte(`{"type": "fails", "payload": {}}`)))
+	s.Assert().Error(r.Process(context.Background(), []byte(`{"type": "missing", "payload": {}}`)))
+	s.Assert().Empty(s.letters)
+}
+
+func (s *DeadLettererSuite) TestDeadLetterErrorFailsMessage() {
+	s.err = errors.New("queue unavailable")
+	r := s.router(WithOnNoHandler(func(ctx context.Context, source, key string) error { return nil }))
+
+	err := r.Process(context.Background(), []byte(`{"type": "missing", "payload": {}}`))
+
+	s.Assert().ErrorIs(err, s.err)
+	s.Assert().EqualError(err, "dead letter: queue unavailable")
+}
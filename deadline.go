@@ -0,0 +1,12 @@
+package dispatch
+
+import "context"
+
+// withDeadline returns a context for the message's handler that is canceled
+// at msg.Deadline, if the source set one.
+func withDeadline(ctx context.Context, msg Message) (context.Context, context.CancelFunc) {
+	if msg.Deadline.IsZero() {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, msg.Deadline)
+}
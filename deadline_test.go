@@ -0,0 +1,90 @@
+package dispatch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DeadlineSuite struct {
+	suite.Suite
+}
+
+func TestDeadlineSuite(t *testing.T) {
+	suite.Run(t, new(DeadlineSuite))
+}
+
+// deadlineRouter returns a router whose source sets deadline on every
+// message, and whose handler runs fn.
+func (s *DeadlineSuite) deadlineRouter(deadline time.Time, fn func(ctx context.Context) error, opts ...Option) *Router {
+	r := New(opts...)
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Deadline: deadline, Payload: []byte(`{}`)}, nil
+	}))
+	RegisterProcFunc(r, "test", func(ctx context.Context, p struct{}) error {
+		return fn(ctx)
+	})
+	return r
+}
+
+func (s *DeadlineSuite) TestHandlerContextHasDeadline() {
+	deadline := time.Now().Add(time.Minute)
+	var got time.Time
+	var ok bool
+	r := s.deadlineRouter(deadline, func(ctx context.Context) error {
+		got, ok = ctx.Deadline()
+		return nil
+	})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test"}`)))
+	s.Require().True(ok)
+	s.Assert().True(got.Equal(deadline))
+}
+
+func (s *DeadlineSuite) TestEarlierContextDeadlineWins() {
+	var got time.Time
+	r := s.deadlineRouter(time.Now().Add(time.Hour), func(ctx context.Context) error {
+		got, _ = ctx.Deadline()
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	s.Require().NoError(r.Process(ctx, []byte(`{"type": "test"}`)))
+	s.Assert().WithinDuration(time.Now().Add(time.Minute), got, 5*time.Second)
+}
+
+func (s *DeadlineSuite) TestHandlerStopsAtDeadline() {
+	r := s.deadlineRouter(time.Now().Add(10*time.Millisecond), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	s.Assert().ErrorIs(r.Process(context.Background(), []byte(`{"type": "test"}`)), context.DeadlineExceeded)
+}
+
+func (s *DeadlineSuite) TestNoDeadline() {
+	var ok bool
+	r := s.deadlineRouter(time.Time{}, func(ctx context.Context) error {
+		_, ok = ctx.Deadline()
+		return nil
+	})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test"}`)))
+	s.Assert().False(ok)
+}
+
+func (s *DeadlineSuite) TestHooksAfterHandlerKeepParentContext() {
+	var hookErr error
+	r := s.deadlineRouter(time.Now().Add(10*time.Millisecond), func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}, WithOnSuccess(func(ctx context.Context, source, key string, d time.Duration) {
+		hookErr = ctx.Err()
+	}))
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test"}`)))
+	s.Assert().NoError(hookErr, "hooks and replies can still finish after the deadline")
+}
@@ -0,0 +1,147 @@
+package debug
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bjaus/dispatch"
+)
+
+// Outcome is how a recorded message ended.
+type Outcome string
+
+// Outcomes.
+const (
+	// OutcomeSuccess and OutcomeFailure mean the handler ran and succeeded
+	// or failed.
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+
+	// OutcomeError means processing failed outside the handler, such as
+	// with no source or handler, or sending the reply. Entry.Stage says
+	// where. Error hooks may still have skipped the message.
+	OutcomeError Outcome = "error"
+
+	// OutcomeExpired, OutcomeDuplicate, and OutcomeDisabled mean the
+	// message was skipped by WithMaxMessageAge, WithDuplicateSuppression,
+	// or WithEnabled.
+	OutcomeExpired   Outcome = "expired"
+	OutcomeDuplicate Outcome = "duplicate"
+	OutcomeDisabled  Outcome = "disabled"
+)
+
+// Entry is a processed message recorded by a Buffer.
+type Entry struct {
+	Time          time.Time     `json:"time"`
+	Source        string        `json:"source"`
+	Key           string        `json:"key"`
+	MessageID     string        `json:"messageId,omitempty"`
+	CorrelationID string        `json:"correlationId,omitempty"`
+	Outcome       Outcome       `json:"outcome"`
+	Stage         string        `json:"stage,omitempty"`
+	Error         string        `json:"error,omitempty"`
+	Duration      time.Duration `json:"duration"`
+
+	// Payload is the message payload, recorded only with WithPayloads.
+	Payload string `json:"payload,omitempty"`
+}
+
+// BufferOption configures a Buffer.
+type BufferOption func(*Buffer)
+
+// WithPayloads records message payloads. Payloads may hold personal data,
+// so they are left out by default.
+func WithPayloads() BufferOption {
+	return func(b *Buffer) {
+		b.payloads = true
+	}
+}
+
+// Buffer keeps the last processed messages in memory, for the /messages
+// endpoint. Register its Hooks with the router.
+type Buffer struct {
+	payloads bool
+
+	mu      sync.Mutex
+	entries []Entry // ring of up to cap(entries) entries
+	next    int     // index of the next write once full
+}
+
+// NewBuffer returns a Buffer that keeps the last n messages. Values of n
+// below 1 keep one.
+//
+// Example:
+//
+//	buf := debug.NewBuffer(100)
+//	r := dispatch.New(buf.Hooks()...)
+func NewBuffer(n int, opts ...BufferOption) *Buffer {
+	b := &Buffer{entries: make([]Entry, 0, max(n, 1))}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Hooks returns router options that record every message whose handler
+// ran, failed before or after it, or was skipped.
+func (b *Buffer) Hooks() []dispatch.Option {
+	return []dispatch.Option{
+		dispatch.WithOnSuccess(func(ctx context.Context, source, key string, d time.Duration) {
+			b.add(ctx, Entry{Source: source, Key: key, Outcome: OutcomeSuccess, Duration: d}, nil)
+		}),
+		dispatch.WithOnFailure(func(ctx context.Context, source, key string, err error, d time.Duration) {
+			b.add(ctx, Entry{Source: source, Key: key, Outcome: OutcomeFailure, Duration: d}, err)
+		}),
+		dispatch.WithOnError(func(ctx context.Context, stage dispatch.Stage, source, key string, err error) error {
+			if stage != dispatch.StageHandle {
+				b.add(ctx, Entry{Source: source, Key: key, Outcome: OutcomeError, Stage: stage.String()}, err)
+			}
+			return dispatch.ErrHookAbstain
+		}),
+		dispatch.WithOnExpired(func(ctx context.Context, source, key string, age time.Duration) {
+			b.add(ctx, Entry{Source: source, Key: key, Outcome: OutcomeExpired}, nil)
+		}),
+		dispatch.WithOnDuplicate(func(ctx context.Context, source, key string) {
+			b.add(ctx, Entry{Source: source, Key: key, Outcome: OutcomeDuplicate}, nil)
+		}),
+		dispatch.WithOnDisabled(func(ctx context.Context, source, key string) {
+			b.add(ctx, Entry{Source: source, Key: key, Outcome: OutcomeDisabled}, nil)
+		}),
+	}
+}
+
+// Entries returns the recorded messages, newest first.
+func (b *Buffer) Entries() []Entry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]Entry, 0, len(b.entries))
+	out = append(out, b.entries[b.next:]...)
+	out = append(out, b.entries[:b.next]...)
+	slices.Reverse(out)
+	return out
+}
+
+// add records e, filling in its time, error, and message fields.
+func (b *Buffer) add(ctx context.Context, e Entry, err error) {
+	e.Time = time.Now()
+	if err != nil {
+		e.Error = err.Error()
+	}
+	if msg, ok := dispatch.MessageFromContext(ctx); ok {
+		e.MessageID, e.CorrelationID = msg.MessageID, msg.CorrelationID
+		if b.payloads {
+			e.Payload = string(msg.Payload)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.entries) < cap(b.entries) {
+		b.entries = append(b.entries, e)
+		return
+	}
+	b.entries[b.next] = e
+	b.next = (b.next + 1) % len(b.entries)
+}
@@ -0,0 +1,91 @@
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+)
+
+// newRouter returns a router whose source reads {"type", "id", "payload"},
+// recording to buf.
+func newRouter(buf *Buffer) *dispatch.Router {
+	r := dispatch.New(buf.Hooks()...)
+	r.AddSource(dispatch.SourceFunc("test", dispatch.HasFields("type"), func(raw []byte) (dispatch.Message, error) {
+		var env struct {
+			Type    string          `json:"type"`
+			ID      string          `json:"id"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := json.Unmarshal(raw, &env); err != nil {
+			return dispatch.Message{}, err
+		}
+		return dispatch.Message{Key: env.Type, MessageID: env.ID, Payload: env.Payload}, nil
+	}))
+	dispatch.RegisterFuncFunc(r, "echo", func(ctx context.Context, p map[string]any) (map[string]any, error) {
+		return p, nil
+	})
+	dispatch.RegisterProcFunc(r, "fail", func(ctx context.Context, p struct{}) error {
+		return errors.New("boom")
+	})
+	return r
+}
+
+type BufferSuite struct {
+	suite.Suite
+}
+
+func TestBufferSuite(t *testing.T) {
+	suite.Run(t, new(BufferSuite))
+}
+
+func (s *BufferSuite) process(r *dispatch.Router, raw string) {
+	_ = r.Process(context.Background(), []byte(raw))
+}
+
+func (s *BufferSuite) TestRecordsOutcomes() {
+	buf := NewBuffer(10)
+	r := newRouter(buf)
+
+	s.process(r, `{"type": "echo", "id": "m1", "payload": {"a": 1}}`)
+	s.process(r, `{"type": "fail", "id": "m2", "payload": {}}`)
+	s.process(r, `{"type": "missing", "id": "m3", "payload": {}}`)
+
+	entries := buf.Entries()
+	s.Require().Len(entries, 3)
+	s.Assert().Equal("m3", entries[0].MessageID, "newest first")
+	s.Assert().Equal(OutcomeError, entries[0].Outcome)
+	s.Assert().Equal("route", entries[0].Stage)
+	s.Assert().Equal(OutcomeFailure, entries[1].Outcome)
+	s.Assert().Equal("boom", entries[1].Error)
+	s.Assert().Equal(OutcomeSuccess, entries[2].Outcome)
+	s.Assert().Equal("test", entries[2].Source)
+	s.Assert().Equal("echo", entries[2].Key)
+	s.Assert().Empty(entries[2].Payload, "payloads are opt-in")
+}
+
+func (s *BufferSuite) TestKeepsLastN() {
+	buf := NewBuffer(2)
+	r := newRouter(buf)
+
+	for _, id := range []string{"m1", "m2", "m3"} {
+		s.process(r, `{"type": "echo", "id": "`+id+`", "payload": {}}`)
+	}
+
+	entries := buf.Entries()
+	s.Require().Len(entries, 2)
+	s.Assert().Equal("m3", entries[0].MessageID)
+	s.Assert().Equal("m2", entries[1].MessageID)
+}
+
+func (s *BufferSuite) TestPayloads() {
+	buf := NewBuffer(1, WithPayloads())
+	r := newRouter(buf)
+
+	s.process(r, `{"type": "echo", "payload": {"a": 1}}`)
+
+	s.Assert().JSONEq(`{"a": 1}`, buf.Entries()[0].Payload)
+}
@@ -0,0 +1,188 @@
+// Package debug serves HTTP endpoints for inspecting a dispatch.Router
+// during incident triage:
+//
+//   - /routes: the routing table, as JSON or with ?format=text as text
+//   - /stats: per-source and per-key counters from Router.Stats
+//   - /messages: the last processed messages, when WithBuffer is set
+//   - /inject: a form that processes a message, when WithInjectToken is set
+//
+// Mount the handler under a prefix on an internal listener:
+//
+//	buf := debug.NewBuffer(200)
+//	r := dispatch.New(buf.Hooks()...)
+//	// ...
+//	mux.Handle("/debug/dispatch/", http.StripPrefix("/debug/dispatch",
+//	    debug.Handler(r, debug.WithBuffer(buf), debug.WithInjectToken(os.Getenv("DISPATCH_DEBUG_TOKEN"))),
+//	))
+package debug
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/bjaus/dispatch"
+)
+
+// Option configures Handler.
+type Option func(*config)
+
+type config struct {
+	buffer  *Buffer
+	token   string
+	maxBody int64
+}
+
+// WithBuffer serves buf's entries at /messages.
+func WithBuffer(buf *Buffer) Option {
+	return func(c *config) {
+		c.buffer = buf
+	}
+}
+
+// WithInjectToken enables /inject, which processes messages posted to it
+// with Router.ProcessResult. Requests must carry token as a bearer token or
+// a "token" form field. Without a token, or with an empty one, /inject is
+// not served.
+func WithInjectToken(token string) Option {
+	return func(c *config) {
+		c.token = token
+	}
+}
+
+// WithMaxInjectSize limits the size of injected messages. Defaults to 1 MiB.
+func WithMaxInjectSize(n int64) Option {
+	return func(c *config) {
+		c.maxBody = n
+	}
+}
+
+// Handler returns an http.Handler serving r's debug endpoints. r may be a
+// *dispatch.Router or a *dispatch.CompiledRouter. Paths are relative to
+// where it is mounted; use http.StripPrefix to mount it under a prefix.
+func Handler(r dispatch.Dispatcher, opts ...Option) http.Handler {
+	cfg := config{maxBody: 1 << 20}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", cfg.index)
+	mux.Handle("GET /routes", dispatch.RoutingTableHandler(r))
+	mux.HandleFunc("GET /stats", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(w, http.StatusOK, r.Stats())
+	})
+	if cfg.buffer != nil {
+		mux.HandleFunc("GET /messages", func(w http.ResponseWriter, req *http.Request) {
+			writeJSON(w, http.StatusOK, cfg.buffer.Entries())
+		})
+	}
+	if cfg.token != "" {
+		mux.HandleFunc("GET /inject", func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_ = injectForm.Execute(w, nil)
+		})
+		mux.HandleFunc("POST /inject", func(w http.ResponseWriter, req *http.Request) {
+			cfg.inject(w, req, r)
+		})
+	}
+	return mux
+}
+
+// index lists the endpoints being served.
+func (c *config) index(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "routes    routing table (?format=text)")
+	fmt.Fprintln(w, "stats     per-source and per-key counters")
+	if c.buffer != nil {
+		fmt.Fprintln(w, "messages  last processed messages")
+	}
+	if c.token != "" {
+		fmt.Fprintln(w, "inject    process a message")
+	}
+}
+
+// injectResult is the response of /inject.
+type injectResult struct {
+	Source   string          `json:"source,omitempty"`
+	Key      string          `json:"key,omitempty"`
+	Version  string          `json:"version,omitempty"`
+	Duration string          `json:"duration"`
+	Replied  bool            `json:"replied"`
+	Result   json.RawMessage `json:"result,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// inject processes the posted message: the "message" field of a form, or
+// the request body otherwise.
+func (c *config) inject(w http.ResponseWriter, req *http.Request, r dispatch.Dispatcher) {
+	req.Body = http.MaxBytesReader(w, req.Body, c.maxBody)
+
+	var raw []byte
+	var token string
+	if isForm(req) {
+		if err := req.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		raw, token = []byte(req.PostForm.Get("message")), req.PostForm.Get("token")
+	} else {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		raw = body
+	}
+	if bearer, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+		token = bearer
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(c.token)) != 1 {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	res, err := r.ProcessResult(req.Context(), raw)
+	out := injectResult{
+		Source:   res.Source,
+		Key:      res.Key,
+		Version:  res.Version,
+		Duration: res.Duration.String(),
+		Replied:  res.Replied,
+		Result:   res.Result,
+	}
+	status := http.StatusOK
+	if err != nil {
+		out.Error = err.Error()
+		status = http.StatusUnprocessableEntity
+	}
+	writeJSON(w, status, out)
+}
+
+func isForm(req *http.Request) bool {
+	ct := req.Header.Get("Content-Type")
+	return strings.HasPrefix(ct, "application/x-www-form-urlencoded") || strings.HasPrefix(ct, "multipart/form-data")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	body, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(append(body, '\n'))
+}
+
+var injectForm = template.Must(template.New("inject").Parse(`<!doctype html>
+<title>dispatch: inject message</title>
+<form method="post" action="inject">
+<p><textarea name="message" rows="20" cols="100" placeholder="raw message"></textarea></p>
+<p><input type="password" name="token" placeholder="token"> <button type="submit">Process</button></p>
+</form>
+`))
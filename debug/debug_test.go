@@ -0,0 +1,134 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerSuite struct {
+	suite.Suite
+	buf     *Buffer
+	handler http.Handler
+}
+
+func TestHandlerSuite(t *testing.T) {
+	suite.Run(t, new(HandlerSuite))
+}
+
+func (s *HandlerSuite) SetupTest() {
+	s.buf = NewBuffer(10)
+	s.handler = Handler(newRouter(s.buf), WithBuffer(s.buf), WithInjectToken("secret"))
+}
+
+func (s *HandlerSuite) serve(req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func (s *HandlerSuite) inject(body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/inject", strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return s.serve(req)
+}
+
+func (s *HandlerSuite) TestIndex() {
+	rec := s.serve(httptest.NewRequest(http.MethodGet, "/", nil))
+	s.Assert().Equal(http.StatusOK, rec.Code)
+	s.Assert().Contains(rec.Body.String(), "messages")
+	s.Assert().Contains(rec.Body.String(), "inject")
+}
+
+func (s *HandlerSuite) TestRoutes() {
+	rec := s.serve(httptest.NewRequest(http.MethodGet, "/routes?format=text", nil))
+	s.Assert().Equal(http.StatusOK, rec.Code)
+	s.Assert().Contains(rec.Body.String(), "echo")
+}
+
+func (s *HandlerSuite) TestCompiledRouter() {
+	s.handler = Handler(newRouter(s.buf).Build(), WithInjectToken("secret"))
+
+	s.Require().Equal(http.StatusOK, s.inject(`{"type": "echo", "id": "m1", "payload": {}}`, "secret").Code)
+
+	rec := s.serve(httptest.NewRequest(http.MethodGet, "/stats", nil))
+	s.Require().Equal(http.StatusOK, rec.Code)
+	s.Assert().Contains(rec.Body.String(), `"Processed": 1`)
+}
+
+func (s *HandlerSuite) TestStatsAndMessages() {
+	s.Require().Equal(http.StatusOK, s.inject(`{"type": "echo", "id": "m1", "payload": {}}`, "secret").Code)
+
+	rec := s.serve(httptest.NewRequest(http.MethodGet, "/stats", nil))
+	s.Require().Equal(http.StatusOK, rec.Code)
+	s.Assert().Contains(rec.Body.String(), `"Processed": 1`)
+
+	rec = s.serve(httptest.NewRequest(http.MethodGet, "/messages", nil))
+	s.Require().Equal(http.StatusOK, rec.Code)
+	var entries []Entry
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &entries))
+	s.Require().Len(entries, 1)
+	s.Assert().Equal("m1", entries[0].MessageID)
+}
+
+func (s *HandlerSuite) TestInject() {
+	rec := s.inject(`{"type": "echo", "payload": {"a": 1}}`, "secret")
+	s.Require().Equal(http.StatusOK, rec.Code)
+
+	var out injectResult
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &out))
+	s.Assert().Equal("test", out.Source)
+	s.Assert().Equal("echo", out.Key)
+	s.Assert().JSONEq(`{"a": 1}`, string(out.Result))
+	s.Assert().Empty(out.Error)
+}
+
+func (s *HandlerSuite) TestInjectFailure() {
+	rec := s.inject(`{"type": "fail", "payload": {}}`, "secret")
+	s.Assert().Equal(http.StatusUnprocessableEntity, rec.Code)
+	s.Assert().Contains(rec.Body.String(), "boom")
+}
+
+func (s *HandlerSuite) TestInjectForm() {
+	form := url.Values{"message": {`{"type": "echo", "payload": {}}`}, "token": {"secret"}}
+	req := httptest.NewRequest(http.MethodPost, "/inject", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rec := s.serve(req)
+	s.Assert().Equal(http.StatusOK, rec.Code)
+
+	rec = s.serve(httptest.NewRequest(http.MethodGet, "/inject", nil))
+	s.Assert().Equal(http.StatusOK, rec.Code)
+	s.Assert().Contains(rec.Body.String(), "<form")
+}
+
+func (s *HandlerSuite) TestInjectRequiresToken() {
+	s.Assert().Equal(http.StatusUnauthorized, s.inject(`{"type": "echo", "payload": {}}`, "").Code)
+	s.Assert().Equal(http.StatusUnauthorized, s.inject(`{"type": "echo", "payload": {}}`, "wrong").Code)
+	s.Assert().Empty(s.buf.Entries(), "nothing processed")
+}
+
+func (s *HandlerSuite) TestInjectDisabledWithoutToken() {
+	h := Handler(newRouter(s.buf))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/inject", strings.NewReader(`{}`)))
+	s.Assert().NotEqual(http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/messages", nil))
+	s.Assert().Equal(http.StatusNotFound, rec.Code)
+}
+
+func (s *HandlerSuite) TestInjectTooLarge() {
+	s.handler = Handler(newRouter(s.buf), WithInjectToken("secret"), WithMaxInjectSize(8))
+	rec := s.inject(`{"type": "echo", "payload": {}}`, "secret")
+	s.Assert().Equal(http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,122 @@
+package dispatch
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// OnDuplicateFunc is called when WithDuplicateSuppression skips a message
+// whose MessageID was already handled.
+type OnDuplicateFunc func(ctx context.Context, source, key string)
+
+// WithDuplicateSuppression skips messages whose MessageID was handled
+// successfully within window, for consumers of at-least-once transports
+// that can tolerate best-effort deduplication without an external
+// idempotency store. Skipped messages are not handled and Process returns
+// nil.
+//
+// IDs are remembered in memory, per router, for up to size messages; once
+// full, the oldest are forgotten first. A message is remembered only once
+// Process returns nil for it, so redeliveries of failed messages are handled
+// again, and messages without a MessageID are never suppressed. Use an
+// idempotency store instead when duplicates must never run, for example
+// across instances.
+//
+// Example:
+//
+//	r := dispatch.New(
+//	    dispatch.WithDuplicateSuppression(5*time.Minute, 10_000),
+//	    dispatch.WithOnDuplicate(func(ctx context.Context, source, key string) {
+//	        metrics.Incr("dispatch.duplicate", "key:"+key)
+//	    }),
+//	)
+func WithDuplicateSuppression(window time.Duration, size int) Option {
+	return func(r *Router) {
+		r.dedup = newDedupCache(window, size)
+	}
+}
+
+// WithOnDuplicate adds a hook called when a message is skipped by
+// WithDuplicateSuppression. Multiple hooks are called in order.
+func WithOnDuplicate(fn OnDuplicateFunc) Option {
+	return func(r *Router) {
+		r.hooks.onDuplicate = append(r.hooks.onDuplicate, fn)
+	}
+}
+
+// callOnDuplicate calls the duplicate hooks.
+func (r *Router) callOnDuplicate(ctx context.Context, sourceName, key string) {
+	for _, fn := range r.hooks.onDuplicate {
+		fn(ctx, sourceName, key)
+	}
+}
+
+// dedupCache remembers message IDs for a window, evicting the oldest once
+// it holds size IDs. Entries are kept in insertion order, which is also
+// expiry order.
+type dedupCache struct {
+	window time.Duration
+	size   int
+
+	mu      sync.Mutex
+	order   *list.List // of dedupEntry, oldest first
+	entries map[string]*list.Element
+}
+
+type dedupEntry struct {
+	id   string
+	seen time.Time
+}
+
+func newDedupCache(window time.Duration, size int) *dedupCache {
+	return &dedupCache{
+		window:  window,
+		size:    max(size, 1),
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// clone returns an empty cache with the same window and size.
+func (c *dedupCache) clone() *dedupCache {
+	if c == nil {
+		return nil
+	}
+	return newDedupCache(c.window, c.size)
+}
+
+// seen reports whether id was added within the window.
+func (c *dedupCache) seen(id string) bool {
+	if c == nil || id == "" {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[id]
+	return ok && time.Since(e.Value.(dedupEntry).seen) <= c.window
+}
+
+// add remembers id, forgetting expired IDs and, if the cache is full, the
+// oldest one.
+func (c *dedupCache) add(id string) {
+	if c == nil || id == "" {
+		return
+	}
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[id]; ok {
+		c.order.Remove(e)
+	}
+	c.entries[id] = c.order.PushBack(dedupEntry{id: id, seen: now})
+	for front := c.order.Front(); front != nil; front = c.order.Front() {
+		entry := front.Value.(dedupEntry)
+		if c.order.Len() <= c.size && now.Sub(entry.seen) <= c.window {
+			break
+		}
+		c.order.Remove(front)
+		delete(c.entries, entry.id)
+	}
+}
@@ -0,0 +1,114 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DedupSuite struct {
+	suite.Suite
+	calls      int
+	err        error
+	duplicates []string
+}
+
+func TestDedupSuite(t *testing.T) {
+	suite.Run(t, new(DedupSuite))
+}
+
+func (s *DedupSuite) SetupTest() {
+	s.calls, s.err, s.duplicates = 0, nil, nil
+}
+
+// router returns a router whose source uses the "id" field as MessageID.
+func (s *DedupSuite) router(opts ...Option) *Router {
+	opts = append(opts, WithOnDuplicate(func(ctx context.Context, source, key string) {
+		s.duplicates = append(s.duplicates, key)
+	}))
+	r := New(opts...)
+	r.AddSource(SourceFunc("test", HasFields("id"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", MessageID: string(raw[7:9]), Payload: []byte(`{}`)}, nil
+	}))
+	RegisterProcFunc(r, "test", func(ctx context.Context, p struct{}) error {
+		s.calls++
+		return s.err
+	})
+	return r
+}
+
+func (s *DedupSuite) process(r *Router, id string) error {
+	return r.Process(context.Background(), []byte(`{"id":"`+id+`"}`))
+}
+
+func (s *DedupSuite) TestSkipsDuplicates() {
+	r := s.router(WithDuplicateSuppression(time.Minute, 10))
+
+	s.Require().NoError(s.process(r, "m1"))
+	s.Require().NoError(s.process(r, "m1"))
+	s.Require().NoError(s.process(r, "m2"))
+
+	s.Assert().Equal(2, s.calls)
+	s.Assert().Equal([]string{"test"}, s.duplicates)
+	s.Assert().Equal(uint64(1), r.Stats().Keys["test"].Skipped)
+}
+
+func (s *DedupSuite) TestFailedMessagesAreRetried() {
+	r := s.router(WithDuplicateSuppression(time.Minute, 10))
+
+	s.err = errors.New("boom")
+	s.Require().Error(s.process(r, "m1"))
+	s.err = nil
+	s.Require().NoError(s.process(r, "m1"))
+
+	s.Assert().Equal(2, s.calls)
+	s.Assert().Empty(s.duplicates)
+}
+
+func (s *DedupSuite) TestWindowExpires() {
+	r := s.router(WithDuplicateSuppression(10*time.Millisecond, 10))
+
+	s.Require().NoError(s.process(r, "m1"))
+	time.Sleep(20 * time.Millisecond)
+	s.Require().NoError(s.process(r, "m1"))
+
+	s.Assert().Equal(2, s.calls)
+}
+
+func (s *DedupSuite) TestEvictsOldest() {
+	r := s.router(WithDuplicateSuppression(time.Minute, 2))
+
+	for _, id := range []string{"m1", "m2", "m3", "m1"} {
+		s.Require().NoError(s.process(r, id))
+	}
+	s.Assert().Equal(4, s.calls, "m1 was evicted by m3")
+
+	s.Require().NoError(s.process(r, "m3"))
+	s.Assert().Equal(4, s.calls)
+}
+
+func (s *DedupSuite) TestNoMessageID() {
+	r := New(WithDuplicateSuppression(time.Minute, 10))
+	r.AddSource(SourceFunc("test", HasFields("id"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(`{}`)}, nil
+	}))
+	RegisterProcFunc(r, "test", func(ctx context.Context, p struct{}) error {
+		s.calls++
+		return nil
+	})
+
+	s.Require().NoError(s.process(r, "m1"))
+	s.Require().NoError(s.process(r, "m1"))
+	s.Assert().Equal(2, s.calls)
+}
+
+func (s *DedupSuite) TestDisabledByDefault() {
+	r := s.router()
+
+	s.Require().NoError(s.process(r, "m1"))
+	s.Require().NoError(s.process(r, "m1"))
+	s.Assert().Equal(2, s.calls)
+}
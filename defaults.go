@@ -0,0 +1,49 @@
+package dispatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// errPayloadNotObject is returned when a message has Defaults but its
+// payload is not a JSON object.
+var errPayloadNotObject = errors.New("payload is not a JSON object")
+
+// applyDefaults returns msg's payload with each of msg.Defaults added that
+// the payload lacks. A missing or null payload is treated as an empty
+// object. The payload is returned unchanged when nothing is added.
+func applyDefaults(msg Message) (json.RawMessage, error) {
+	if len(msg.Defaults) == 0 {
+		return msg.Payload, nil
+	}
+	fields := make(map[string]json.RawMessage)
+	if trimmed := bytes.TrimSpace(msg.Payload); len(trimmed) > 0 && !bytes.Equal(trimmed, []byte("null")) {
+		if trimmed[0] != '{' {
+			return nil, fmt.Errorf("payload defaults: %w", errPayloadNotObject)
+		}
+		if err := json.Unmarshal(trimmed, &fields); err != nil {
+			return nil, fmt.Errorf("payload defaults: %w", err)
+		}
+	}
+
+	added := false
+	for _, name := range slices.Sorted(maps.Keys(msg.Defaults)) {
+		if _, ok := fields[name]; ok {
+			continue
+		}
+		value, err := json.Marshal(msg.Defaults[name])
+		if err != nil {
+			return nil, fmt.Errorf("payload default %s: %w", name, err)
+		}
+		fields[name] = value
+		added = true
+	}
+	if !added {
+		return msg.Payload, nil
+	}
+	return json.Marshal(fields)
+}
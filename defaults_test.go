@@ -0,0 +1,121 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type enrichedPayload struct {
+	Value      string `json:"value"`
+	Region     string `json:"region"`
+	ReceivedAt int64  `json:"received_at"`
+}
+
+type DefaultsSuite struct {
+	suite.Suite
+	router *Router
+	got    enrichedPayload
+}
+
+func TestDefaultsSuite(t *testing.T) {
+	suite.Run(t, new(DefaultsSuite))
+}
+
+func (s *DefaultsSuite) SetupTest() {
+	s.router = New()
+	s.router.AddSource(SourceFunc("enriching", HasFields("type"), func(raw []byte) (Message, error) {
+		var env struct {
+			Type    string          `json:"type"`
+			Region  string          `json:"region"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := json.Unmarshal(raw, &env); err != nil {
+			return Message{}, err
+		}
+		return Message{
+			Key:      env.Type,
+			Payload:  env.Payload,
+			Defaults: map[string]any{"region": env.Region, "received_at": 42},
+		}, nil
+	}))
+	RegisterProcFunc(s.router, "test", func(ctx context.Context, p enrichedPayload) error {
+		s.got = p
+		return nil
+	})
+}
+
+func (s *DefaultsSuite) process(raw string) error {
+	return s.router.Process(context.Background(), []byte(raw))
+}
+
+func (s *DefaultsSuite) TestAddsMissingFields() {
+	s.Require().NoError(s.process(`{"type": "test", "region": "us-east-1", "payload": {"value": "hi"}}`))
+
+	s.Assert().Equal(enrichedPayload{Value: "hi", Region: "us-east-1", ReceivedAt: 42}, s.got)
+}
+
+func (s *DefaultsSuite) TestPayloadWins() {
+	s.Require().NoError(s.process(`{"type": "test", "region": "us-east-1", "payload": {"region": "eu-west-1"}}`))
+
+	s.Assert().Equal("eu-west-1", s.got.Region)
+	s.Assert().Equal(int64(42), s.got.ReceivedAt)
+}
+
+func (s *DefaultsSuite) TestEmptyPayload() {
+	s.Require().NoError(s.process(`{"type": "test", "region": "us-east-1"}`))
+
+	s.Assert().Equal("us-east-1", s.got.Region)
+}
+
+func (s *DefaultsSuite) TestNotAnObject() {
+	err := s.process(`{"type": "test", "region": "us-east-1", "payload": [1, 2]}`)
+
+	var derr *DispatchError
+	s.Require().ErrorAs(err, &derr)
+	s.Assert().Equal(StageParse, derr.Stage)
+	s.Assert().ErrorIs(err, errPayloadNotObject)
+}
+
+func (s *DefaultsSuite) TestContextPayload() {
+	var payload json.RawMessage
+	s.router = New(WithOnDispatch(func(ctx context.Context, source, key string) {
+		msg, _ := MessageFromContext(ctx)
+		payload = msg.Payload
+	}))
+	s.router.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(`{"value": "hi"}`), Defaults: map[string]any{"region": "us-east-1"}}, nil
+	}))
+	RegisterProcFunc(s.router, "test", func(ctx context.Context, p enrichedPayload) error { return nil })
+
+	s.Require().NoError(s.process(`{"type": "test"}`))
+	s.Assert().JSONEq(`{"value": "hi", "region": "us-east-1"}`, string(payload))
+}
+
+func (s *DefaultsSuite) TestUnchangedWithoutNewFields() {
+	payload := json.RawMessage(`{"region": "eu-west-1"}`)
+	out, err := applyDefaults(Message{Payload: payload, Defaults: map[string]any{"region": "us-east-1"}})
+	s.Require().NoError(err)
+	s.Assert().Equal(payload, out)
+}
+
+func (s *DefaultsSuite) TestFromEnvelopeLayers() {
+	regionLayer := UnwrapperFunc(HasFields("region", "body"), func(v View, raw []byte) (Layer, error) {
+		body, _ := v.GetString("body")
+		region, _ := v.GetString("region")
+		return Layer{Body: []byte(body), Meta: Message{Defaults: map[string]any{"region": region, "received_at": 7}}}, nil
+	})
+	s.router = New()
+	s.router.AddSource(Unwrap(SourceFunc("inner", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(`{"value": "hi"}`), Defaults: map[string]any{"received_at": 42}}, nil
+	}), regionLayer))
+	RegisterProcFunc(s.router, "test", func(ctx context.Context, p enrichedPayload) error {
+		s.got = p
+		return nil
+	})
+
+	s.Require().NoError(s.process(`{"region": "us-east-1", "body": ` + wrap(`{"type": "test"}`) + `}`))
+	s.Assert().Equal(enrichedPayload{Value: "hi", Region: "us-east-1", ReceivedAt: 42}, s.got, "the source's defaults win over its envelopes'")
+}
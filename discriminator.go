@@ -1,8 +1,17 @@
 package dispatch
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // Discriminator determines if a source should handle a message based on
 // the message content. Discriminators are cheap to evaluate compared to
 // full parsing.
+//
+// Discriminators that implement fmt.Stringer are shown in that form by
+// Router.RoutingTable; the built-in discriminators all do.
 type Discriminator interface {
 	Match(v View) bool
 }
@@ -25,6 +34,10 @@ func (d hasFields) Match(v View) bool {
 	return true
 }
 
+func (d hasFields) String() string {
+	return "has(" + strings.Join(d.paths, ", ") + ")"
+}
+
 // FieldEquals returns a Discriminator that matches when the path exists
 // and equals the given string value.
 func FieldEquals(path, value string) Discriminator {
@@ -41,6 +54,245 @@ func (d fieldEquals) Match(v View) bool {
 	return ok && s == d.value
 }
 
+func (d fieldEquals) String() string {
+	return fmt.Sprintf("%s == %q", d.path, d.value)
+}
+
+// FieldHasPrefix returns a Discriminator that matches when the path exists
+// and is a string beginning with prefix.
+//
+// Example:
+//
+//	// Matches AWS service events such as "aws.s3" or "aws.ec2"
+//	dispatch.FieldHasPrefix("source", "aws.")
+func FieldHasPrefix(path, prefix string) Discriminator {
+	return fieldHasPrefix{path: path, prefix: prefix}
+}
+
+type fieldHasPrefix struct {
+	path   string
+	prefix string
+}
+
+func (d fieldHasPrefix) Match(v View) bool {
+	s, ok := v.GetString(d.path)
+	return ok && strings.HasPrefix(s, d.prefix)
+}
+
+func (d fieldHasPrefix) String() string {
+	return fmt.Sprintf("hasPrefix(%s, %q)", d.path, d.prefix)
+}
+
+// FieldHasSuffix returns a Discriminator that matches when the path exists
+// and is a string ending with suffix.
+func FieldHasSuffix(path, suffix string) Discriminator {
+	return fieldHasSuffix{path: path, suffix: suffix}
+}
+
+type fieldHasSuffix struct {
+	path   string
+	suffix string
+}
+
+func (d fieldHasSuffix) Match(v View) bool {
+	s, ok := v.GetString(d.path)
+	return ok && strings.HasSuffix(s, d.suffix)
+}
+
+func (d fieldHasSuffix) String() string {
+	return fmt.Sprintf("hasSuffix(%s, %q)", d.path, d.suffix)
+}
+
+// FieldContains returns a Discriminator that matches when the path exists
+// and is a string containing substr.
+func FieldContains(path, substr string) Discriminator {
+	return fieldContains{path: path, substr: substr}
+}
+
+type fieldContains struct {
+	path   string
+	substr string
+}
+
+func (d fieldContains) Match(v View) bool {
+	s, ok := v.GetString(d.path)
+	return ok && strings.Contains(s, d.substr)
+}
+
+func (d fieldContains) String() string {
+	return fmt.Sprintf("contains(%s, %q)", d.path, d.substr)
+}
+
+// FieldTrue returns a Discriminator that matches when the path exists and
+// is the boolean true. Views that do not implement BoolView never match.
+func FieldTrue(path string) Discriminator {
+	return fieldTrue{path: path}
+}
+
+type fieldTrue struct {
+	path string
+}
+
+func (d fieldTrue) Match(v View) bool {
+	bv, ok := v.(BoolView)
+	if !ok {
+		return false
+	}
+	b, ok := bv.GetBool(d.path)
+	return ok && b
+}
+
+func (d fieldTrue) String() string {
+	return d.path + " == true"
+}
+
+// FieldGreaterThan returns a Discriminator that matches when the path exists
+// and is a number strictly greater than n. Views that do not implement
+// NumberView never match.
+func FieldGreaterThan(path string, n float64) Discriminator {
+	return fieldGreaterThan{path: path, n: n}
+}
+
+type fieldGreaterThan struct {
+	path string
+	n    float64
+}
+
+func (d fieldGreaterThan) Match(v View) bool {
+	nv, ok := v.(NumberView)
+	if !ok {
+		return false
+	}
+	f, ok := nv.GetFloat(d.path)
+	return ok && f > d.n
+}
+
+func (d fieldGreaterThan) String() string {
+	return d.path + " > " + strconv.FormatFloat(d.n, 'g', -1, 64)
+}
+
+// FieldIsString returns a Discriminator that matches when the path exists and
+// is a string.
+func FieldIsString(path string) Discriminator {
+	return fieldIsString{path: path}
+}
+
+type fieldIsString struct {
+	path string
+}
+
+func (d fieldIsString) Match(v View) bool {
+	_, ok := v.GetString(d.path)
+	return ok
+}
+
+func (d fieldIsString) String() string {
+	return "isString(" + d.path + ")"
+}
+
+// FieldIsObject returns a Discriminator that matches when the path exists and
+// is an object. Views from JSONInspector and MapView are checked in place;
+// other views are checked with GetView.
+//
+// Example:
+//
+//	// Distinguishes {"Message": {...}} from {"Message": "..."}
+//	dispatch.FieldIsObject("Message")
+func FieldIsObject(path string) Discriminator {
+	return fieldIsObject{path: path}
+}
+
+type fieldIsObject struct {
+	path string
+}
+
+func (d fieldIsObject) Match(v View) bool {
+	switch v := v.(type) {
+	case jsonView:
+		return v.get(d.path).IsObject()
+	case mapView:
+		val, _ := v.lookup(d.path)
+		_, ok := val.(map[string]any)
+		return ok
+	}
+	_, ok := GetView(v, d.path)
+	return ok
+}
+
+func (d fieldIsObject) String() string {
+	return "isObject(" + d.path + ")"
+}
+
+// FieldIsArray returns a Discriminator that matches when the path exists and
+// is an array. Views from JSONInspector and MapView are checked in place;
+// other views that do not implement ArrayView never match.
+func FieldIsArray(path string) Discriminator {
+	return fieldIsArray{path: path}
+}
+
+type fieldIsArray struct {
+	path string
+}
+
+func (d fieldIsArray) Match(v View) bool {
+	switch v := v.(type) {
+	case jsonView:
+		return v.get(d.path).IsArray()
+	case mapView:
+		val, _ := v.lookup(d.path)
+		_, ok := val.([]any)
+		return ok
+	}
+	av, ok := v.(ArrayView)
+	if !ok {
+		return false
+	}
+	_, ok = av.Elements(d.path)
+	return ok
+}
+
+func (d fieldIsArray) String() string {
+	return "isArray(" + d.path + ")"
+}
+
+// AnyElementMatches returns a Discriminator that matches when the path is an
+// array and at least one element matches d. Paths passed to d are relative to
+// the element. Views that do not implement ArrayView never match.
+//
+// Example:
+//
+//	// Matches S3 notifications delivered as a Records[] envelope
+//	dispatch.AnyElementMatches("Records", dispatch.FieldEquals("eventSource", "aws:s3"))
+func AnyElementMatches(path string, d Discriminator) Discriminator {
+	return anyElementMatches{path: path, d: d}
+}
+
+type anyElementMatches struct {
+	path string
+	d    Discriminator
+}
+
+func (d anyElementMatches) Match(v View) bool {
+	av, ok := v.(ArrayView)
+	if !ok {
+		return false
+	}
+	elems, ok := av.Elements(d.path)
+	if !ok {
+		return false
+	}
+	for _, elem := range elems {
+		if d.d.Match(elem) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d anyElementMatches) String() string {
+	return "any(" + d.path + ", " + describe(d.d) + ")"
+}
+
 // And returns a Discriminator that matches when all discriminators match.
 func And(ds ...Discriminator) Discriminator {
 	return and{ds: ds}
@@ -59,6 +311,10 @@ func (d and) Match(v View) bool {
 	return true
 }
 
+func (d and) String() string {
+	return "and(" + describeAll(d.ds) + ")"
+}
+
 // Or returns a Discriminator that matches when any discriminator matches.
 func Or(ds ...Discriminator) Discriminator {
 	return or{ds: ds}
@@ -76,3 +332,54 @@ func (d or) Match(v View) bool {
 	}
 	return false
 }
+
+func (d or) String() string {
+	return "or(" + describeAll(d.ds) + ")"
+}
+
+// Not returns a Discriminator that matches when d does not match.
+//
+// Example:
+//
+//	// Has a message field but is not an SNS notification
+//	dispatch.And(
+//	    dispatch.HasFields("Message"),
+//	    dispatch.Not(dispatch.FieldEquals("Type", "Notification")),
+//	)
+func Not(d Discriminator) Discriminator {
+	return not{d: d}
+}
+
+type not struct {
+	d Discriminator
+}
+
+func (d not) Match(v View) bool {
+	return !d.d.Match(v)
+}
+
+func (d not) String() string {
+	return "not(" + describe(d.d) + ")"
+}
+
+// describe returns a readable form of d, such as `type == "order"` for
+// FieldEquals("type", "order"). Discriminators that do not implement
+// fmt.Stringer are described by their type.
+func describe(d Discriminator) string {
+	switch d := d.(type) {
+	case nil:
+		return "<nil>"
+	case fmt.Stringer:
+		return d.String()
+	default:
+		return fmt.Sprintf("%T", d)
+	}
+}
+
+func describeAll(ds []Discriminator) string {
+	parts := make([]string, len(ds))
+	for i, d := range ds {
+		parts[i] = describe(d)
+	}
+	return strings.Join(parts, ", ")
+}
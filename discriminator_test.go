@@ -1,6 +1,8 @@
 package dispatch
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -92,6 +94,42 @@ func (s *FieldEqualsSuite) TestFailsOnNonStringField() {
 	s.Assert().False(d.Match(s.view))
 }
 
+type FieldStringMatchSuite struct {
+	suite.Suite
+	view View
+}
+
+func (s *FieldStringMatchSuite) SetupTest() {
+	s.view = MapView(map[string]any{
+		"source":      "aws.s3",
+		"detail-type": "billing.InvoiceCreated",
+		"count":       42,
+	})
+}
+
+func TestFieldStringMatchSuite(t *testing.T) {
+	suite.Run(t, new(FieldStringMatchSuite))
+}
+
+func (s *FieldStringMatchSuite) TestFieldHasPrefix() {
+	s.Assert().True(FieldHasPrefix("source", "aws.").Match(s.view))
+	s.Assert().False(FieldHasPrefix("source", "my.").Match(s.view))
+	s.Assert().False(FieldHasPrefix("count", "4").Match(s.view))
+	s.Assert().False(FieldHasPrefix("missing", "").Match(s.view))
+}
+
+func (s *FieldStringMatchSuite) TestFieldHasSuffix() {
+	s.Assert().True(FieldHasSuffix("detail-type", "Created").Match(s.view))
+	s.Assert().False(FieldHasSuffix("detail-type", "Deleted").Match(s.view))
+	s.Assert().False(FieldHasSuffix("missing", "").Match(s.view))
+}
+
+func (s *FieldStringMatchSuite) TestFieldContains() {
+	s.Assert().True(FieldContains("detail-type", "billing.").Match(s.view))
+	s.Assert().False(FieldContains("detail-type", "shipping.").Match(s.view))
+	s.Assert().False(FieldContains("missing", "").Match(s.view))
+}
+
 type AndSuite struct {
 	suite.Suite
 	inspector Inspector
@@ -239,3 +277,249 @@ func (s *ComposedDiscriminatorsSuite) TestComplexComposedDiscriminator() {
 	s.Assert().True(d.Match(snsView))
 	s.Assert().False(d.Match(otherView))
 }
+
+type NotSuite struct {
+	suite.Suite
+	view View
+}
+
+func (s *NotSuite) SetupTest() {
+	s.view = MapView(map[string]any{
+		"Type":    "Notification",
+		"Message": "{}",
+	})
+}
+
+func TestNotSuite(t *testing.T) {
+	suite.Run(t, new(NotSuite))
+}
+
+func (s *NotSuite) TestInvertsMatch() {
+	s.Assert().False(Not(HasFields("Type")).Match(s.view))
+	s.Assert().True(Not(HasFields("missing")).Match(s.view))
+}
+
+func (s *NotSuite) TestComposesWithAnd() {
+	d := And(
+		HasFields("Message"),
+		Not(FieldEquals("Type", "Notification")),
+	)
+	s.Assert().False(d.Match(s.view))
+
+	other := MapView(map[string]any{"Type": "Other", "Message": "{}"})
+	s.Assert().True(d.Match(other))
+}
+
+type TypedDiscriminatorSuite struct {
+	suite.Suite
+	inspector Inspector
+	view      View
+}
+
+func (s *TypedDiscriminatorSuite) SetupTest() {
+	s.inspector = JSONInspector()
+	raw := []byte(`{
+		"enabled": true,
+		"disabled": false,
+		"count": 42,
+		"name": "my.app"
+	}`)
+
+	var err error
+	s.view, err = s.inspector.Inspect(raw)
+	s.Require().NoError(err)
+}
+
+func TestTypedDiscriminatorSuite(t *testing.T) {
+	suite.Run(t, new(TypedDiscriminatorSuite))
+}
+
+func (s *TypedDiscriminatorSuite) TestFieldTrue() {
+	s.Assert().True(FieldTrue("enabled").Match(s.view))
+	s.Assert().False(FieldTrue("disabled").Match(s.view))
+	s.Assert().False(FieldTrue("name").Match(s.view))
+	s.Assert().False(FieldTrue("missing").Match(s.view))
+}
+
+func (s *TypedDiscriminatorSuite) TestFieldGreaterThan() {
+	s.Assert().True(FieldGreaterThan("count", 41).Match(s.view))
+	s.Assert().False(FieldGreaterThan("count", 42).Match(s.view))
+	s.Assert().False(FieldGreaterThan("name", 0).Match(s.view))
+	s.Assert().False(FieldGreaterThan("missing", 0).Match(s.view))
+}
+
+func (s *TypedDiscriminatorSuite) TestFailsWhenViewLacksOptionalInterface() {
+	v := stringOnlyView{}
+	s.Assert().False(FieldTrue("enabled").Match(v))
+	s.Assert().False(FieldGreaterThan("count", 0).Match(v))
+}
+
+type FieldTypeSuite struct {
+	suite.Suite
+	view View
+}
+
+func (s *FieldTypeSuite) SetupTest() {
+	var err error
+	s.view, err = JSONInspector().Inspect([]byte(`{
+		"str": "value",
+		"obj": {"a": 1},
+		"arr": [1, 2],
+		"num": 42
+	}`))
+	s.Require().NoError(err)
+}
+
+func TestFieldTypeSuite(t *testing.T) {
+	suite.Run(t, new(FieldTypeSuite))
+}
+
+func (s *FieldTypeSuite) TestFieldTypes() {
+	tests := map[string]struct {
+		d    Discriminator
+		want bool
+	}{
+		"string is string":   {FieldIsString("str"), true},
+		"object not string":  {FieldIsString("obj"), false},
+		"object is object":   {FieldIsObject("obj"), true},
+		"string not object":  {FieldIsObject("str"), false},
+		"array not object":   {FieldIsObject("arr"), false},
+		"array is array":     {FieldIsArray("arr"), true},
+		"object not array":   {FieldIsArray("obj"), false},
+		"number not string":  {FieldIsString("num"), false},
+		"missing not string": {FieldIsString("missing"), false},
+		"missing not object": {FieldIsObject("missing"), false},
+		"missing not array":  {FieldIsArray("missing"), false},
+	}
+
+	for name, tt := range tests {
+		s.Run(name, func() {
+			s.Assert().Equal(tt.want, tt.d.Match(s.view))
+		})
+	}
+}
+
+func (s *FieldTypeSuite) TestOtherViews() {
+	var m map[string]any
+	s.Require().NoError(json.Unmarshal([]byte(`{"obj": {"a": 1}, "arr": [1, 2], "str": "x"}`), &m))
+
+	for name, view := range map[string]View{"map": MapView(m), "bytes only": bytesOnlyView{s.view}} {
+		s.Run(name, func() {
+			s.Assert().True(FieldIsObject("obj").Match(view))
+			s.Assert().False(FieldIsObject("arr").Match(view))
+			s.Assert().False(FieldIsObject("str").Match(view))
+			s.Assert().Equal(name == "map", FieldIsArray("arr").Match(view))
+			s.Assert().False(FieldIsArray("obj").Match(view))
+		})
+	}
+}
+
+func (s *FieldTypeSuite) TestChecksJSONInPlace() {
+	view, err := JSONInspector().Inspect([]byte(`{"arr": [` + strings.Repeat(`{"a": 1}, `, 99) + `{"a": 1}]}`))
+	s.Require().NoError(err)
+	isArray := FieldIsArray("arr")
+
+	allocs := testing.AllocsPerRun(100, func() { isArray.Match(view) })
+	s.Assert().Less(allocs, 5.0, "no view per element")
+}
+
+func (s *FieldTypeSuite) TestFailsWhenViewLacksOptionalInterfaces() {
+	s.Assert().False(FieldIsObject("obj").Match(stringOnlyView{}))
+	s.Assert().False(FieldIsArray("arr").Match(stringOnlyView{}))
+}
+
+type AnyElementMatchesSuite struct {
+	suite.Suite
+	inspector Inspector
+	view      View
+}
+
+func (s *AnyElementMatchesSuite) SetupTest() {
+	s.inspector = JSONInspector()
+	raw := []byte(`{
+		"Records": [
+			{"eventSource": "aws:sqs"},
+			{"eventSource": "aws:s3"}
+		],
+		"source": "my.app"
+	}`)
+
+	var err error
+	s.view, err = s.inspector.Inspect(raw)
+	s.Require().NoError(err)
+}
+
+func TestAnyElementMatchesSuite(t *testing.T) {
+	suite.Run(t, new(AnyElementMatchesSuite))
+}
+
+func (s *AnyElementMatchesSuite) TestMatchesWhenAnyElementMatches() {
+	d := AnyElementMatches("Records", FieldEquals("eventSource", "aws:s3"))
+	s.Assert().True(d.Match(s.view))
+}
+
+func (s *AnyElementMatchesSuite) TestFailsWhenNoElementMatches() {
+	d := AnyElementMatches("Records", FieldEquals("eventSource", "aws:sns"))
+	s.Assert().False(d.Match(s.view))
+}
+
+func (s *AnyElementMatchesSuite) TestFailsForNonArray() {
+	d := AnyElementMatches("source", HasFields())
+	s.Assert().False(d.Match(s.view))
+}
+
+func (s *AnyElementMatchesSuite) TestFailsWhenViewLacksArrayView() {
+	d := AnyElementMatches("Records", HasFields())
+	s.Assert().False(d.Match(stringOnlyView{}))
+}
+
+// stringOnlyView implements only the required View methods.
+type stringOnlyView struct{}
+
+func (stringOnlyView) HasField(string) bool            { return true }
+func (stringOnlyView) GetString(string) (string, bool) { return "", false }
+func (stringOnlyView) GetBytes(string) ([]byte, bool)  { return nil, false }
+
+// bytesOnlyView hides the optional interfaces of the View it wraps.
+type bytesOnlyView struct{ v View }
+
+func (b bytesOnlyView) HasField(path string) bool            { return b.v.HasField(path) }
+func (b bytesOnlyView) GetString(path string) (string, bool) { return b.v.GetString(path) }
+func (b bytesOnlyView) GetBytes(path string) ([]byte, bool)  { return b.v.GetBytes(path) }
+
+type DescribeSuite struct {
+	suite.Suite
+}
+
+func TestDescribeSuite(t *testing.T) {
+	suite.Run(t, new(DescribeSuite))
+}
+
+func (s *DescribeSuite) TestBuiltins() {
+	tests := []struct {
+		d    Discriminator
+		want string
+	}{
+		{HasFields("a", "b.c"), "has(a, b.c)"},
+		{FieldEquals("type", "order"), `type == "order"`},
+		{FieldHasPrefix("source", "aws."), `hasPrefix(source, "aws.")`},
+		{FieldHasSuffix("source", ".s3"), `hasSuffix(source, ".s3")`},
+		{FieldContains("source", "s3"), `contains(source, "s3")`},
+		{FieldTrue("retry"), "retry == true"},
+		{FieldGreaterThan("version", 1.5), "version > 1.5"},
+		{FieldIsString("Message"), "isString(Message)"},
+		{FieldIsObject("Message"), "isObject(Message)"},
+		{FieldIsArray("Records"), "isArray(Records)"},
+		{AnyElementMatches("Records", FieldEquals("eventSource", "aws:s3")), `any(Records, eventSource == "aws:s3")`},
+		{And(HasFields("a"), Or(FieldTrue("b"), Not(FieldIsString("c")))), "and(has(a), or(b == true, not(isString(c))))"},
+	}
+	for _, tt := range tests {
+		s.Assert().Equal(tt.want, describe(tt.d))
+	}
+}
+
+func (s *DescribeSuite) TestCustomAndNil() {
+	s.Assert().Equal("dispatch.routesDiscriminator", describe(routesDiscriminator{}))
+	s.Assert().Equal("not(dispatch.routesDiscriminator)", describe(Not(routesDiscriminator{})))
+	s.Assert().Equal("<nil>", describe(nil))
+}
@@ -3,6 +3,7 @@ package dispatch
 import (
 	"context"
 	"encoding/json"
+	"time"
 )
 
 // Proc (procedure) processes a message without returning a result.
@@ -123,6 +124,40 @@ type Source interface {
 	Parse(raw []byte) (Message, error)
 }
 
+// ViewParser is an optional interface for sources that can build a Message
+// from the View their discriminator matched, instead of re-parsing raw.
+// When a source implements it, the router calls ParseView instead of Parse.
+//
+// Example:
+//
+//	func (s *mySource) ParseView(v dispatch.View, raw []byte) (dispatch.Message, error) {
+//	    key, _ := v.GetString("type")
+//	    payload, _ := v.GetBytes("payload")
+//	    return dispatch.Message{Key: key, Payload: payload}, nil
+//	}
+type ViewParser interface {
+	ParseView(view View, raw []byte) (Message, error)
+}
+
+// InspectorProvider is an optional interface for sources whose messages need
+// a different Inspector than the rest of their group, such as a single
+// protobuf source among JSON ones. The source keeps its place in matching
+// order, but its discriminator is evaluated against a View from its own
+// inspector. Returning nil uses the group's inspector.
+//
+// Router.AddSourceWithInspector provides one for an existing source.
+type InspectorProvider interface {
+	Inspector() Inspector
+}
+
+// sourceInspector returns the inspector src provides, or nil.
+func sourceInspector(src Source) Inspector {
+	if p, ok := src.(InspectorProvider); ok {
+		return p.Inspector()
+	}
+	return nil
+}
+
 // SourceFunc creates a Source from a name, discriminator, and parse function.
 // Use for simple sources that don't need a struct:
 //
@@ -153,13 +188,67 @@ type Message struct {
 	// This is matched against keys passed to RegisterProc/RegisterFunc.
 	Key string
 
+	// Keys routes a composite message to several handlers, such as an event
+	// that should trigger both order/updated and inventory/recheck. The
+	// message is dispatched to Key, if set, and then to each of Keys; see
+	// Router.Process. It is optional.
+	Keys []string
+
 	// Version is the schema version of the payload, if available.
 	// Sources should populate this for version-aware routing.
 	Version string
 
+	// MessageID uniquely identifies the message, such as an SQS message ID or
+	// CloudEvents id. It is optional.
+	MessageID string
+
+	// CorrelationID ties the message to a wider request or workflow across
+	// services. If a source leaves it empty, the router sets it to MessageID.
+	CorrelationID string
+
+	// Timestamp is when the message was originally produced, such as the
+	// EventBridge time or SQS SentTimestamp. It is optional and is used by
+	// WithMaxMessageAge to drop stale messages.
+	Timestamp time.Time
+
+	// Deadline is when work on the message must stop, such as when an SQS
+	// message becomes visible again or a deadline carried in the envelope.
+	// If it is set, the handler's context is canceled at Deadline. It is
+	// optional.
+	Deadline time.Time
+
+	// Priority orders messages processed together by ProcessBatch or waiting
+	// for the workers started with StartWorkers; higher values are handled
+	// first. It is optional and defaults to zero.
+	Priority int
+
+	// Lease extends the time before the transport redelivers the message,
+	// for handlers that call ExtendLease and for WithLeaseHeartbeat. It is
+	// optional.
+	Lease Lease
+
 	// Payload is the raw JSON to unmarshal into the handler's type.
 	Payload json.RawMessage
 
+	// Defaults are fields from the envelope, such as the region or the time
+	// the message was received, that the router adds to Payload before it
+	// is unmarshaled, so handlers read them from their payload type. Fields
+	// the payload already has are kept. Payload must be a JSON object, or
+	// empty. It is optional.
+	Defaults map[string]any
+
+	// Attributes holds transport metadata that is not part of the payload,
+	// such as SNS message attributes, Kafka headers, or CloudEvents
+	// extensions. It is optional. Hooks and handlers read it through
+	// MessageFromContext or Attribute.
+	Attributes map[string]string
+
+	// ReplyTo is the address the caller asked responses to be sent to, such
+	// as an SQS queue URL, SNS topic ARN, or NATS subject. When it is set and
+	// Replier is nil, the router builds a Replier with the ReplierFactory set
+	// by WithReplierFactory.
+	ReplyTo string
+
 	// Replier handles sending responses back to the caller.
 	// For fire-and-forget sources (EventBridge, SNS), this is nil.
 	// For request-response sources (Step Functions), this sends results back.
@@ -176,6 +265,9 @@ type Message struct {
 
 // Replier sends responses back to the message originator.
 // Implement this for request-response transport patterns.
+//
+// The context passed to Reply and Fail carries the message, so replies can
+// echo its MessageID and CorrelationID (see MessageID and CorrelationID).
 type Replier interface {
 	// Reply sends a successful response with the given JSON payload.
 	Reply(ctx context.Context, result json.RawMessage) error
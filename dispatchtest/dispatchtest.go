@@ -0,0 +1,118 @@
+// Package dispatchtest provides test doubles and assertions for code built on
+// dispatch, so services don't each maintain their own.
+//
+//   - FakeSource builds and parses messages with any key and payload.
+//   - EventBridgeEnvelope, SNSEnvelope, and SQSEnvelope build realistic AWS
+//     messages for testing real sources.
+//   - FakeReplier captures Reply and Fail calls.
+//   - HookRecorder captures every hook invocation in order.
+//   - Harness records which key each handler ran for, with its payload.
+//   - RunGolden checks a corpus of captured messages against expected routes,
+//     and GoldenWriter adds messages captured with dispatch.Record to one.
+//   - FuzzSource fuzzes a custom source's discriminator and parser.
+//
+// Example:
+//
+//	src := dispatchtest.NewFakeSource("test")
+//	replier := &dispatchtest.FakeReplier{}
+//	src.Replier = replier
+//	rec := &dispatchtest.HookRecorder{}
+//
+//	r := dispatch.New(rec.Options()...)
+//	r.AddSource(src)
+//	dispatch.RegisterFuncFunc(r, "lookup", lookup)
+//
+//	err := r.Process(ctx, src.Message("lookup", LookupInput{ID: "42"}))
+//	require.NoError(t, err)
+//	dispatchtest.AssertReplied(t, replier, `{"name": "Ada"}`)
+//	dispatchtest.AssertHooks(t, rec, "OnParse", "OnDispatch", "OnSuccess", "OnReply", "OnTimings")
+package dispatchtest
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/bjaus/dispatch"
+)
+
+// AssertHooks reports an error on t unless rec recorded exactly the hooks in
+// want, in order. It returns whether the assertion passed.
+func AssertHooks(t testing.TB, rec *HookRecorder, want ...string) bool {
+	t.Helper()
+	got := rec.Hooks()
+	if !slices.Equal(got, want) {
+		t.Errorf("hooks:\n\tgot:  %v\n\twant: %v", got, want)
+		return false
+	}
+	return true
+}
+
+// AssertReplied reports an error on t unless rep received exactly one Reply,
+// with a result equal to the JSON want, and no Fail. It returns whether the
+// assertion passed.
+func AssertReplied(t testing.TB, rep *FakeReplier, want string) bool {
+	t.Helper()
+	if failures := rep.Failures(); len(failures) > 0 {
+		t.Errorf("replier failed: %v", errors.Join(failures...))
+		return false
+	}
+	replies := rep.Replies()
+	if len(replies) != 1 {
+		t.Errorf("replier got %d replies, want 1", len(replies))
+		return false
+	}
+	var got, exp any
+	if err := json.Unmarshal(replies[0], &got); err != nil {
+		t.Errorf("reply is not valid JSON: %v", err)
+		return false
+	}
+	if err := json.Unmarshal([]byte(want), &exp); err != nil {
+		t.Errorf("want is not valid JSON: %v", err)
+		return false
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("reply:\n\tgot:  %s\n\twant: %s", replies[0], want)
+		return false
+	}
+	return true
+}
+
+// AssertFailed reports an error on t unless rep received exactly one Fail,
+// with an error matching target by errors.Is, and no Reply. It returns
+// whether the assertion passed.
+func AssertFailed(t testing.TB, rep *FakeReplier, target error) bool {
+	t.Helper()
+	if replies := rep.Replies(); len(replies) > 0 {
+		t.Errorf("replier got %d replies, want none", len(replies))
+		return false
+	}
+	failures := rep.Failures()
+	if len(failures) != 1 {
+		t.Errorf("replier got %d failures, want 1", len(failures))
+		return false
+	}
+	if !errors.Is(failures[0], target) {
+		t.Errorf("failure %q does not match %q", failures[0], target)
+		return false
+	}
+	return true
+}
+
+// AssertStage reports an error on t unless err is a *dispatch.DispatchError
+// for stage. It returns whether the assertion passed.
+func AssertStage(t testing.TB, err error, stage dispatch.Stage) bool {
+	t.Helper()
+	var de *dispatch.DispatchError
+	if !errors.As(err, &de) {
+		t.Errorf("error %v is not a *dispatch.DispatchError", err)
+		return false
+	}
+	if de.Stage != stage {
+		t.Errorf("stage: got %s, want %s", de.Stage, stage)
+		return false
+	}
+	return true
+}
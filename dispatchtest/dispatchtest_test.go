@@ -0,0 +1,172 @@
+package dispatchtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeT records assertion failures instead of failing the test.
+type fakeT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+var errNoName = errors.New("no name")
+
+type input struct {
+	Name string `json:"name"`
+}
+
+type output struct {
+	Greeting string `json:"greeting"`
+}
+
+type DispatchTestSuite struct {
+	suite.Suite
+	source  *FakeSource
+	replier *FakeReplier
+	rec     *HookRecorder
+	router  *dispatch.Router
+}
+
+func TestDispatchTestSuite(t *testing.T) {
+	suite.Run(t, new(DispatchTestSuite))
+}
+
+func (s *DispatchTestSuite) SetupTest() {
+	s.source = NewFakeSource("test")
+	s.replier = &FakeReplier{}
+	s.source.Replier = s.replier
+	s.rec = &HookRecorder{}
+	s.router = dispatch.New(s.rec.Options()...)
+	s.router.AddSource(s.source)
+	dispatch.RegisterFuncFunc(s.router, "greet", func(ctx context.Context, in input) (output, error) {
+		if in.Name == "" {
+			return output{}, errNoName
+		}
+		return output{Greeting: "hello " + in.Name}, nil
+	})
+}
+
+func (s *DispatchTestSuite) TestSuccess() {
+	s.Require().NoError(s.router.Process(context.Background(), s.source.Message("greet", input{Name: "ada"})))
+
+	s.Assert().True(AssertReplied(s.T(), s.replier, `{"greeting": "hello ada"}`))
+	s.Assert().True(AssertHooks(s.T(), s.rec, "OnParse", "OnDispatch", "OnSuccess", "OnReply", "OnTimings"))
+	s.Require().Len(s.source.Parsed(), 1)
+	s.Assert().Equal("greet", s.source.Parsed()[0].Key)
+}
+
+func (s *DispatchTestSuite) TestHandlerFailureIsSentToReplier() {
+	s.Require().NoError(s.router.Process(context.Background(), s.source.Message("greet", input{})))
+
+	s.Assert().True(AssertFailed(s.T(), s.replier, errNoName))
+	s.Assert().Contains(s.rec.Calls(), Call{Hook: "OnFailure", Source: "test", Key: "greet", Err: errNoName})
+}
+
+func (s *DispatchTestSuite) TestHandlerFailure() {
+	s.source.Replier = nil
+
+	err := s.router.Process(context.Background(), s.source.Message("greet", input{}))
+
+	s.Assert().ErrorIs(err, errNoName)
+	s.Assert().True(AssertStage(s.T(), err, dispatch.StageHandle))
+	s.Assert().Empty(s.replier.Failures())
+}
+
+func (s *DispatchTestSuite) TestRecorderDoesNotChangeOutcome() {
+	s.source.Replier = nil
+
+	err := s.router.Process(context.Background(), s.source.Message("missing", input{}))
+
+	s.Assert().ErrorIs(err, dispatch.ErrNoHandler)
+	s.Assert().True(AssertHooks(s.T(), s.rec, "OnParse", "OnNoHandler", "OnTimings"))
+	s.Assert().Equal(Call{Hook: "OnNoHandler", Source: "test", Key: "missing"}, s.rec.Calls()[1])
+
+	s.rec.Reset()
+	s.Assert().ErrorIs(s.router.Process(context.Background(), []byte(`{}`)), dispatch.ErrNoSource)
+	s.Assert().True(AssertHooks(s.T(), s.rec, "OnNoSource"))
+}
+
+func (s *DispatchTestSuite) TestParseError() {
+	parseErr := errors.New("bad envelope")
+	s.source.ParseErr = parseErr
+
+	err := s.router.Process(context.Background(), s.source.Message("greet", input{}))
+
+	s.Assert().ErrorIs(err, parseErr)
+	s.Assert().True(AssertStage(s.T(), err, dispatch.StageParse))
+	s.Assert().Equal([]Call{{Hook: "OnParseError", Source: "test", Err: parseErr}}, s.rec.Calls())
+}
+
+func (s *DispatchTestSuite) TestEnvelopeRoundTrip() {
+	msg := dispatch.Message{
+		Key:           "greet",
+		Version:       "v2",
+		MessageID:     "m-1",
+		CorrelationID: "c-1",
+		Priority:      3,
+		Deadline:      time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Attributes:    map[string]string{"tenant": "acme"},
+		ReplyTo:       "queue",
+		Payload:       []byte(`{"name":"ada"}`),
+	}
+
+	got, err := s.source.Parse(s.source.Envelope(msg))
+
+	s.Require().NoError(err)
+	msg.Replier = s.replier
+	s.Assert().Equal(msg, got)
+}
+
+func (s *DispatchTestSuite) TestSourcesDontMatchEachOther() {
+	other := NewFakeSource("other")
+	err := s.router.Process(context.Background(), other.Message("greet", input{Name: "ada"}))
+
+	s.Assert().ErrorIs(err, dispatch.ErrNoSource)
+}
+
+func (s *DispatchTestSuite) TestFakeReplierErrors() {
+	s.replier.ReplyErr = errors.New("reply failed")
+
+	err := s.router.Process(context.Background(), s.source.Message("greet", input{Name: "ada"}))
+
+	s.Assert().ErrorIs(err, s.replier.ReplyErr)
+	s.Assert().Len(s.replier.Replies(), 1)
+}
+
+func (s *DispatchTestSuite) TestAssertionsReportFailures() {
+	t := &fakeT{}
+
+	s.Assert().False(AssertHooks(t, s.rec, "OnParse"))
+	s.Assert().False(AssertReplied(t, s.replier, `{}`))
+	s.Assert().False(AssertFailed(t, s.replier, dispatch.ErrNoHandler))
+	s.Assert().False(AssertStage(t, errors.New("plain"), dispatch.StageHandle))
+	s.Assert().Len(t.errors, 4)
+
+	s.Require().NoError(s.replier.Reply(context.Background(), []byte(`{"a": 1}`)))
+	s.Assert().False(AssertReplied(t, s.replier, `{"a": 2}`))
+	s.Assert().True(AssertReplied(t, s.replier, `{ "a": 1 }`))
+}
+
+// FuzzFakeSource found this: a keyless envelope used to parse to an empty
+// key, which FuzzSource reports as a failure.
+func (s *DispatchTestSuite) TestMessagesWithoutKeyFailToParse() {
+	raw := []byte(`{"dispatchtest": "test"}`)
+	_, err := s.source.Parse(raw)
+
+	s.Assert().ErrorIs(err, errNoKey)
+	s.Assert().NoError(checkSource(s.source, raw))
+}
@@ -0,0 +1,268 @@
+package dispatchtest
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// EnvelopeOption configures the envelopes built by EventBridgeEnvelope,
+// SNSEnvelope, and SQSEnvelope.
+type EnvelopeOption func(*envelopeConfig)
+
+type envelopeConfig struct {
+	id           string
+	time         time.Time
+	source       string
+	arn          string
+	keyAttribute string
+	attributes   map[string]string
+}
+
+// defaultEnvelopeTime is the timestamp of envelopes built without WithTime,
+// fixed so fixtures are reproducible.
+var defaultEnvelopeTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// WithID sets the message ID: the EventBridge id, SNS MessageId, or SQS
+// messageId. Defaults to a UUID derived from the key and payload.
+func WithID(id string) EnvelopeOption {
+	return func(c *envelopeConfig) {
+		c.id = id
+	}
+}
+
+// WithTime sets when the message was sent. Defaults to 2025-01-01T00:00:00Z
+// so fixtures are reproducible; pass time.Now() when testing
+// dispatch.WithMaxMessageAge.
+func WithTime(t time.Time) EnvelopeOption {
+	return func(c *envelopeConfig) {
+		c.time = t
+	}
+}
+
+// WithEventSource sets the EventBridge source field. Defaults to
+// "dispatchtest".
+func WithEventSource(source string) EnvelopeOption {
+	return func(c *envelopeConfig) {
+		c.source = source
+	}
+}
+
+// WithARN sets the SNS TopicArn or SQS eventSourceARN.
+func WithARN(arn string) EnvelopeOption {
+	return func(c *envelopeConfig) {
+		c.arn = arn
+	}
+}
+
+// WithKeyAttribute sets the SNS or SQS message attribute that carries the
+// key. Defaults to "type".
+func WithKeyAttribute(name string) EnvelopeOption {
+	return func(c *envelopeConfig) {
+		c.keyAttribute = name
+	}
+}
+
+// WithAttribute adds a string SNS or SQS message attribute. EventBridge
+// events have no attributes and ignore it.
+func WithAttribute(name, value string) EnvelopeOption {
+	return func(c *envelopeConfig) {
+		c.attributes[name] = value
+	}
+}
+
+func newEnvelopeConfig(key string, payload []byte, opts []EnvelopeOption) *envelopeConfig {
+	c := &envelopeConfig{
+		time:         defaultEnvelopeTime,
+		source:       "dispatchtest",
+		keyAttribute: "type",
+		attributes:   map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.id == "" {
+		sum := sha256.Sum256(append([]byte(key+"\x00"), payload...))
+		c.id = fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+	}
+	return c
+}
+
+// EventBridgeEnvelope returns an EventBridge event with key as its
+// detail-type and payload as its detail. payload is marshaled to JSON unless
+// it is already a json.RawMessage; EventBridgeEnvelope panics if it can't be.
+//
+// Example:
+//
+//	raw := dispatchtest.EventBridgeEnvelope("UserCreated", UserCreated{ID: "42"},
+//	    dispatchtest.WithEventSource("users.service"))
+func EventBridgeEnvelope(key string, payload any, opts ...EnvelopeOption) []byte {
+	detail := marshalPayload(payload)
+	c := newEnvelopeConfig(key, detail, opts)
+	return mustMarshal(struct {
+		Version    string          `json:"version"`
+		ID         string          `json:"id"`
+		DetailType string          `json:"detail-type"`
+		Source     string          `json:"source"`
+		Account    string          `json:"account"`
+		Time       string          `json:"time"`
+		Region     string          `json:"region"`
+		Resources  []string        `json:"resources"`
+		Detail     json.RawMessage `json:"detail"`
+	}{
+		Version:    "0",
+		ID:         c.id,
+		DetailType: key,
+		Source:     c.source,
+		Account:    "123456789012",
+		Time:       c.time.UTC().Format(time.RFC3339),
+		Region:     "us-east-1",
+		Resources:  []string{},
+		Detail:     detail,
+	})
+}
+
+// snsAttribute is an SNS message attribute in a notification.
+type snsAttribute struct {
+	Type  string `json:"Type"`
+	Value string `json:"Value"`
+}
+
+// SNSEnvelope returns an SNS notification, as delivered to HTTP endpoints or
+// SQS queues without raw message delivery, with payload as its Message and
+// key in the message attribute set by WithKeyAttribute. payload is marshaled
+// to JSON unless it is already a json.RawMessage; SNSEnvelope panics if it
+// can't be.
+//
+// Example:
+//
+//	raw := dispatchtest.SNSEnvelope("order/placed", Order{ID: "7"},
+//	    dispatchtest.WithAttribute("tenant", "acme"))
+func SNSEnvelope(key string, payload any, opts ...EnvelopeOption) []byte {
+	body := marshalPayload(payload)
+	c := newEnvelopeConfig(key, body, opts)
+	if c.arn == "" {
+		c.arn = "arn:aws:sns:us-east-1:123456789012:dispatchtest"
+	}
+	attrs := make(map[string]snsAttribute, len(c.attributes)+1)
+	for name, value := range c.attributes {
+		attrs[name] = snsAttribute{Type: "String", Value: value}
+	}
+	attrs[c.keyAttribute] = snsAttribute{Type: "String", Value: key}
+
+	return mustMarshal(struct {
+		Type              string                  `json:"Type"`
+		MessageID         string                  `json:"MessageId"`
+		TopicArn          string                  `json:"TopicArn"`
+		Message           string                  `json:"Message"`
+		Timestamp         string                  `json:"Timestamp"`
+		SignatureVersion  string                  `json:"SignatureVersion"`
+		Signature         string                  `json:"Signature"`
+		SigningCertURL    string                  `json:"SigningCertURL"`
+		UnsubscribeURL    string                  `json:"UnsubscribeURL"`
+		MessageAttributes map[string]snsAttribute `json:"MessageAttributes"`
+	}{
+		Type:              "Notification",
+		MessageID:         c.id,
+		TopicArn:          c.arn,
+		Message:           string(body),
+		Timestamp:         c.time.UTC().Format("2006-01-02T15:04:05.000Z"),
+		SignatureVersion:  "1",
+		Signature:         "EXAMPLE",
+		SigningCertURL:    "https://sns.us-east-1.amazonaws.com/SimpleNotificationService-EXAMPLE.pem",
+		UnsubscribeURL:    "https://sns.us-east-1.amazonaws.com/?Action=Unsubscribe&SubscriptionArn=" + c.arn + ":EXAMPLE",
+		MessageAttributes: attrs,
+	})
+}
+
+// sqsAttribute is an SQS message attribute in a Lambda event record.
+type sqsAttribute struct {
+	StringValue string `json:"stringValue"`
+	DataType    string `json:"dataType"`
+}
+
+// SQSEnvelope returns an SQS message as it appears in the Records of a Lambda
+// SQS event, with payload as its body and key in the message attribute set
+// by WithKeyAttribute. payload is marshaled to JSON unless it is already a
+// json.RawMessage; SQSEnvelope panics if it can't be. Use SQSEvent to wrap
+// records in a full Lambda event.
+//
+// Example:
+//
+//	raw := dispatchtest.SQSEnvelope("invoice/paid", Invoice{ID: "9"})
+func SQSEnvelope(key string, payload any, opts ...EnvelopeOption) []byte {
+	body := marshalPayload(payload)
+	c := newEnvelopeConfig(key, body, opts)
+	if c.arn == "" {
+		c.arn = "arn:aws:sqs:us-east-1:123456789012:dispatchtest"
+	}
+	attrs := make(map[string]sqsAttribute, len(c.attributes)+1)
+	for name, value := range c.attributes {
+		attrs[name] = sqsAttribute{StringValue: value, DataType: "String"}
+	}
+	attrs[c.keyAttribute] = sqsAttribute{StringValue: key, DataType: "String"}
+	sum := md5.Sum(body)
+	sent := fmt.Sprint(c.time.UnixMilli())
+
+	return mustMarshal(struct {
+		MessageID         string                  `json:"messageId"`
+		ReceiptHandle     string                  `json:"receiptHandle"`
+		Body              string                  `json:"body"`
+		Attributes        map[string]string       `json:"attributes"`
+		MessageAttributes map[string]sqsAttribute `json:"messageAttributes"`
+		MD5OfBody         string                  `json:"md5OfBody"`
+		EventSource       string                  `json:"eventSource"`
+		EventSourceARN    string                  `json:"eventSourceARN"`
+		AWSRegion         string                  `json:"awsRegion"`
+	}{
+		MessageID:     c.id,
+		ReceiptHandle: "EXAMPLE-" + c.id,
+		Body:          string(body),
+		Attributes: map[string]string{
+			"ApproximateReceiveCount":          "1",
+			"SentTimestamp":                    sent,
+			"SenderId":                         "123456789012",
+			"ApproximateFirstReceiveTimestamp": sent,
+		},
+		MessageAttributes: attrs,
+		MD5OfBody:         hex.EncodeToString(sum[:]),
+		EventSource:       "aws:sqs",
+		EventSourceARN:    c.arn,
+		AWSRegion:         "us-east-1",
+	})
+}
+
+// SQSEvent returns a Lambda SQS event with the given records, as built by
+// SQSEnvelope.
+func SQSEvent(records ...[]byte) []byte {
+	raws := make([]json.RawMessage, len(records))
+	for i, r := range records {
+		raws[i] = r
+	}
+	return mustMarshal(struct {
+		Records []json.RawMessage `json:"Records"`
+	}{Records: raws})
+}
+
+// marshalPayload returns payload as JSON, panicking if it can't be marshaled.
+func marshalPayload(payload any) json.RawMessage {
+	if raw, ok := payload.(json.RawMessage); ok {
+		return raw
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		panic("dispatchtest: marshal payload: " + err.Error())
+	}
+	return raw
+}
+
+func mustMarshal(v any) []byte {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		panic("dispatchtest: marshal envelope: " + err.Error())
+	}
+	return raw
+}
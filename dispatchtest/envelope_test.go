@@ -0,0 +1,106 @@
+package dispatchtest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+)
+
+type EnvelopeSuite struct {
+	suite.Suite
+}
+
+func TestEnvelopeSuite(t *testing.T) {
+	suite.Run(t, new(EnvelopeSuite))
+}
+
+func (s *EnvelopeSuite) view(raw []byte) dispatch.View {
+	s.Require().True(json.Valid(raw))
+	v, err := dispatch.JSONInspector().Inspect(raw)
+	s.Require().NoError(err)
+	return v
+}
+
+func (s *EnvelopeSuite) get(v dispatch.View, path string) string {
+	got, ok := v.GetString(path)
+	s.Require().True(ok, path)
+	return got
+}
+
+func (s *EnvelopeSuite) TestEventBridge() {
+	at := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	raw := EventBridgeEnvelope("UserCreated", map[string]string{"id": "42"},
+		WithEventSource("users.service"), WithID("evt-1"), WithTime(at))
+
+	v := s.view(raw)
+	s.Assert().Equal("UserCreated", s.get(v, "detail-type"))
+	s.Assert().Equal("users.service", s.get(v, "source"))
+	s.Assert().Equal("evt-1", s.get(v, "id"))
+	s.Assert().Equal("2024-05-06T07:08:09Z", s.get(v, "time"))
+	s.Assert().Equal("42", s.get(v, "detail.id"))
+	s.Assert().True(dispatch.HasFields("version", "account", "region", "resources").Match(v))
+}
+
+func (s *EnvelopeSuite) TestSNS() {
+	raw := SNSEnvelope("order/placed", json.RawMessage(`{"id":"7"}`),
+		WithAttribute("tenant", "acme"), WithARN("arn:aws:sns:us-east-1:1:orders"))
+
+	v := s.view(raw)
+	s.Assert().Equal("Notification", s.get(v, "Type"))
+	s.Assert().Equal(`{"id":"7"}`, s.get(v, "Message"))
+	s.Assert().Equal("arn:aws:sns:us-east-1:1:orders", s.get(v, "TopicArn"))
+	s.Assert().Equal("order/placed", s.get(v, "MessageAttributes.type.Value"))
+	s.Assert().Equal("String", s.get(v, "MessageAttributes.type.Type"))
+	s.Assert().Equal("acme", s.get(v, "MessageAttributes.tenant.Value"))
+	s.Assert().Equal("2025-01-01T00:00:00.000Z", s.get(v, "Timestamp"))
+}
+
+func (s *EnvelopeSuite) TestSQS() {
+	raw := SQSEnvelope("invoice/paid", map[string]int{"n": 1}, WithKeyAttribute("event"))
+
+	v := s.view(raw)
+	s.Assert().Equal(`{"n":1}`, s.get(v, "body"))
+	s.Assert().Equal("invoice/paid", s.get(v, "messageAttributes.event.stringValue"))
+	s.Assert().Equal("aws:sqs", s.get(v, "eventSource"))
+	s.Assert().Equal("082c26c8a6bc75226a31da5495cc9292", s.get(v, "md5OfBody"))
+	s.Assert().Equal("1735689600000", s.get(v, "attributes.SentTimestamp"))
+
+	event := s.view(SQSEvent(raw, raw))
+	s.Assert().Equal("invoice/paid", s.get(event, "Records.1.messageAttributes.event.stringValue"))
+}
+
+func (s *EnvelopeSuite) TestDefaultIDsAreStableAndDistinct() {
+	a := s.get(s.view(SQSEnvelope("a", 1)), "messageId")
+
+	s.Assert().Equal(a, s.get(s.view(SQSEnvelope("a", 1)), "messageId"))
+	s.Assert().NotEqual(a, s.get(s.view(SQSEnvelope("a", 2)), "messageId"))
+	s.Assert().Regexp(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`, a)
+}
+
+func (s *EnvelopeSuite) TestRoutesThroughSource() {
+	r := dispatch.New()
+	r.AddSource(dispatch.SourceFunc("eventbridge", dispatch.HasFields("source", "detail-type"), func(raw []byte) (dispatch.Message, error) {
+		var env struct {
+			DetailType string          `json:"detail-type"`
+			Detail     json.RawMessage `json:"detail"`
+		}
+		err := json.Unmarshal(raw, &env)
+		return dispatch.Message{Key: env.DetailType, Payload: env.Detail}, err
+	}))
+	h := NewHarness(r)
+	dispatch.RegisterProcFunc(r, "UserCreated", func(ctx context.Context, p created) error { return nil })
+
+	s.Require().NoError(r.Process(context.Background(), EventBridgeEnvelope("UserCreated", created{ID: "42"})))
+
+	s.Assert().True(h.AssertHandled(s.T(), "UserCreated", created{ID: "42"}))
+}
+
+func (s *EnvelopeSuite) TestPanicsOnBadPayload() {
+	s.Assert().PanicsWithValue("dispatchtest: marshal payload: json: unsupported type: chan int", func() {
+		SNSEnvelope("x", make(chan int))
+	})
+}
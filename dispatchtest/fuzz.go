@@ -0,0 +1,165 @@
+package dispatchtest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/bjaus/dispatch"
+)
+
+// FuzzSource fuzzes src with envelopes built by this package, common edge
+// cases, and seeds, to harden custom sources. For every input it checks
+// that:
+//
+//   - the discriminator never panics and gives the same answer twice;
+//   - when the discriminator matches, Parse (and ParseView, if implemented)
+//     never panics;
+//   - a successful Parse returns a routing key: a non-empty Key or, when Key
+//     is empty, a non-empty Keys with no empty entries;
+//   - Parse and ParseView agree on the message, ignoring Replier.
+//
+// Inputs are inspected with dispatch.JSONInspector; inputs it rejects are
+// skipped, since the router never passes them to the source.
+//
+// Example:
+//
+//	func FuzzOrderSource(f *testing.F) {
+//	    dispatchtest.FuzzSource(f, orders.NewSource(), capturedOrder)
+//	}
+func FuzzSource(f *testing.F, src dispatch.Source, seeds ...[]byte) {
+	f.Helper()
+	for _, seed := range fuzzSeeds(src) {
+		f.Add(seed)
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		if err := checkSource(src, raw); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// fuzzSeeds returns the built-in corpus: an envelope of each kind this
+// package builds, and malformed or minimal JSON.
+func fuzzSeeds(src dispatch.Source) [][]byte {
+	payload := map[string]any{"id": "1", "n": 1, "nested": map[string]any{"ok": true}}
+	return [][]byte{
+		NewFakeSource(src.Name()).Message("key", payload),
+		EventBridgeEnvelope("key", payload),
+		SNSEnvelope("key", payload),
+		SQSEnvelope("key", payload),
+		SQSEvent(SQSEnvelope("key", payload)),
+		[]byte(`{}`),
+		[]byte(`[]`),
+		[]byte(`null`),
+		[]byte(`""`),
+		[]byte(`{"type": null, "payload": null}`),
+		[]byte(`{"type": 1, "payload": []}`),
+		[]byte(`{"Records": [{}]}`),
+		[]byte(`{"detail-type": "", "detail": "x"}`),
+		[]byte(`{`),
+		{},
+	}
+}
+
+// checkSource runs the FuzzSource checks against one input.
+func checkSource(src dispatch.Source, raw []byte) error {
+	view, err := dispatch.JSONInspector().Inspect(raw)
+	if err != nil {
+		return nil
+	}
+
+	var matched, again bool
+	if err := guard("Discriminator().Match", func() error {
+		matched = src.Discriminator().Match(view)
+		again = src.Discriminator().Match(view)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if matched != again {
+		return fmt.Errorf("discriminator is not deterministic for %q", raw)
+	}
+	if !matched {
+		return nil
+	}
+
+	var msg dispatch.Message
+	parseErr := guard("Parse", func() error {
+		var err error
+		msg, err = src.Parse(raw)
+		return err
+	})
+	var panicked *panicError
+	if errors.As(parseErr, &panicked) {
+		return parseErr
+	}
+	if parseErr == nil {
+		if err := checkKeys(msg); err != nil {
+			return fmt.Errorf("Parse returned %w for %q", err, raw)
+		}
+	}
+
+	vp, ok := src.(dispatch.ViewParser)
+	if !ok {
+		return nil
+	}
+	var viewMsg dispatch.Message
+	viewErr := guard("ParseView", func() error {
+		var err error
+		viewMsg, err = vp.ParseView(view, raw)
+		return err
+	})
+	if errors.As(viewErr, &panicked) {
+		return viewErr
+	}
+	if (parseErr == nil) != (viewErr == nil) {
+		return fmt.Errorf("Parse error %v but ParseView error %v for %q", parseErr, viewErr, raw)
+	}
+	msg.Replier, viewMsg.Replier = nil, nil
+	if parseErr == nil && !reflect.DeepEqual(msg, viewMsg) {
+		return fmt.Errorf("Parse returned %+v but ParseView returned %+v for %q", msg, viewMsg, raw)
+	}
+	return nil
+}
+
+// checkKeys reports whether msg has a routing key: Key or, when Key is
+// empty, Keys.
+func checkKeys(msg dispatch.Message) error {
+	if msg.Key != "" {
+		return nil
+	}
+	if len(msg.Keys) == 0 {
+		return errors.New("an empty key")
+	}
+	for i, key := range msg.Keys {
+		if key == "" {
+			return fmt.Errorf("an empty key at Keys[%d]", i)
+		}
+	}
+	return nil
+}
+
+// panicError reports a panic recovered by guard.
+type panicError struct {
+	call  string
+	value any
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("%s panicked: %v", e.call, e.value)
+}
+
+// guard calls fn, turning a panic into a *panicError.
+func guard(call string, fn func() error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &panicError{call: call, value: v}
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,150 @@
+package dispatchtest
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+)
+
+func FuzzFakeSource(f *testing.F) {
+	FuzzSource(f, NewFakeSource("test"), []byte(`{"dispatchtest": "test", "key": "k", "payload": 1}`))
+}
+
+func FuzzMultiKeySource(f *testing.F) {
+	FuzzSource(f, dispatch.SourceFunc("multi", dispatch.HasFields("types"), func(raw []byte) (dispatch.Message, error) {
+		msg, err := parseTypes(raw)
+		if err == nil && (len(msg.Keys) == 0 || slices.Contains(msg.Keys, "")) {
+			err = errors.New("missing types")
+		}
+		return msg, err
+	}), []byte(`{"types": ["order/updated", "inventory/recheck"]}`))
+}
+
+// matchFunc adapts a function to dispatch.Discriminator.
+type matchFunc func(v dispatch.View) bool
+
+func (f matchFunc) Match(v dispatch.View) bool { return f(v) }
+
+// viewSource is a source whose ParseView can be made to disagree with Parse.
+type viewSource struct {
+	parse     func(raw []byte) (dispatch.Message, error)
+	parseView func(v dispatch.View, raw []byte) (dispatch.Message, error)
+}
+
+func (s *viewSource) Name() string                          { return "view" }
+func (s *viewSource) Discriminator() dispatch.Discriminator { return dispatch.HasFields("type") }
+func (s *viewSource) Parse(raw []byte) (dispatch.Message, error) {
+	return s.parse(raw)
+}
+func (s *viewSource) ParseView(v dispatch.View, raw []byte) (dispatch.Message, error) {
+	return s.parseView(v, raw)
+}
+
+func parseType(raw []byte) (dispatch.Message, error) {
+	var env struct {
+		Type string `json:"type"`
+	}
+	err := json.Unmarshal(raw, &env)
+	return dispatch.Message{Key: env.Type}, err
+}
+
+// parseTypes parses a message routed only by Keys.
+func parseTypes(raw []byte) (dispatch.Message, error) {
+	var env struct {
+		Types []string `json:"types"`
+	}
+	err := json.Unmarshal(raw, &env)
+	return dispatch.Message{Keys: env.Types}, err
+}
+
+type FuzzSourceSuite struct {
+	suite.Suite
+}
+
+func TestFuzzSourceSuite(t *testing.T) {
+	suite.Run(t, new(FuzzSourceSuite))
+}
+
+func (s *FuzzSourceSuite) TestSkipsInvalidAndUnmatched() {
+	src := dispatch.SourceFunc("panics", dispatch.HasFields("type"), func([]byte) (dispatch.Message, error) {
+		panic("boom")
+	})
+
+	s.Assert().NoError(checkSource(src, []byte(`{`)))
+	s.Assert().NoError(checkSource(src, []byte(`{"other": 1}`)))
+}
+
+func (s *FuzzSourceSuite) TestReportsParsePanic() {
+	src := dispatch.SourceFunc("panics", dispatch.HasFields("type"), func([]byte) (dispatch.Message, error) {
+		panic("boom")
+	})
+
+	s.Assert().EqualError(checkSource(src, []byte(`{"type": "x"}`)), "Parse panicked: boom")
+}
+
+func (s *FuzzSourceSuite) TestReportsDiscriminatorPanic() {
+	src := dispatch.SourceFunc("panics", matchFunc(func(v dispatch.View) bool {
+		panic("bad view")
+	}), parseType)
+
+	s.Assert().EqualError(checkSource(src, []byte(`{}`)), "Discriminator().Match panicked: bad view")
+}
+
+func (s *FuzzSourceSuite) TestReportsNondeterministicDiscriminator() {
+	var calls atomic.Int32
+	src := dispatch.SourceFunc("flaky", matchFunc(func(v dispatch.View) bool {
+		return calls.Add(1)%2 == 1
+	}), parseType)
+
+	s.Assert().ErrorContains(checkSource(src, []byte(`{}`)), "not deterministic")
+}
+
+func (s *FuzzSourceSuite) TestReportsEmptyKey() {
+	src := dispatch.SourceFunc("empty", dispatch.HasFields("type"), parseType)
+
+	s.Assert().NoError(checkSource(src, []byte(`{"type": "x"}`)))
+	s.Assert().ErrorContains(checkSource(src, []byte(`{"type": ""}`)), "empty key")
+}
+
+func (s *FuzzSourceSuite) TestAcceptsMultiKeyMessages() {
+	src := dispatch.SourceFunc("multi", dispatch.HasFields("types"), parseTypes)
+
+	s.Assert().NoError(checkSource(src, []byte(`{"types": ["order/updated", "inventory/recheck"]}`)))
+	s.Assert().ErrorContains(checkSource(src, []byte(`{"types": []}`)), "empty key")
+	s.Assert().ErrorContains(checkSource(src, []byte(`{"types": ["order/updated", ""]}`)), "empty key at Keys[1]")
+}
+
+func (s *FuzzSourceSuite) TestParseErrorsAreAllowed() {
+	src := dispatch.SourceFunc("fails", dispatch.HasFields("type"), func([]byte) (dispatch.Message, error) {
+		return dispatch.Message{}, errors.New("bad envelope")
+	})
+
+	s.Assert().NoError(checkSource(src, []byte(`{"type": "x"}`)))
+}
+
+func (s *FuzzSourceSuite) TestComparesParseView() {
+	src := &viewSource{parse: parseType, parseView: func(v dispatch.View, raw []byte) (dispatch.Message, error) {
+		return parseType(raw)
+	}}
+	s.Assert().NoError(checkSource(src, []byte(`{"type": "x"}`)))
+
+	src.parseView = func(v dispatch.View, raw []byte) (dispatch.Message, error) {
+		return dispatch.Message{Key: "other"}, nil
+	}
+	s.Assert().ErrorContains(checkSource(src, []byte(`{"type": "x"}`)), "ParseView returned")
+
+	src.parseView = func(v dispatch.View, raw []byte) (dispatch.Message, error) {
+		return dispatch.Message{}, errors.New("nope")
+	}
+	s.Assert().ErrorContains(checkSource(src, []byte(`{"type": "x"}`)), "ParseView error nope")
+
+	src.parseView = func(v dispatch.View, raw []byte) (dispatch.Message, error) {
+		panic("view boom")
+	}
+	s.Assert().EqualError(checkSource(src, []byte(`{"type": "x"}`)), "ParseView panicked: view boom")
+}
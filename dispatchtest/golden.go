@@ -0,0 +1,227 @@
+package dispatchtest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bjaus/dispatch"
+)
+
+// GoldenManifest is the name of the manifest file in a golden corpus
+// directory.
+const GoldenManifest = "manifest.json"
+
+// GoldenRoute is the expected route of one message in a golden corpus.
+type GoldenRoute struct {
+	Source string `json:"source"`
+	Key    string `json:"key"`
+}
+
+// RunGolden checks a golden corpus: a directory of captured raw messages and
+// a manifest.json mapping each file name to the source and key it must route
+// to, so envelope regressions are caught when sources or discriminators
+// change:
+//
+//	{
+//	    "eventbridge-user-created.json": {"source": "eventbridge", "key": "user/created"},
+//	    "sns-order-placed.json": {"source": "sns", "key": "order/placed"}
+//	}
+//
+// Each message runs as a subtest named after its file and is routed with
+// dispatch.Resolve, so no hooks or handlers run. Files missing from the
+// manifest, and manifest entries without a file, are errors.
+//
+// Example:
+//
+//	func TestGoldenMessages(t *testing.T) {
+//	    dispatchtest.RunGolden(t, newRouter(), "testdata/golden")
+//	}
+func RunGolden(t *testing.T, r dispatch.Dispatcher, dir string) {
+	t.Helper()
+	manifest, unlisted, err := loadGolden(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range unlisted {
+		t.Errorf("golden %s: not in %s", name, GoldenManifest)
+	}
+	for _, name := range sortedKeys(manifest) {
+		t.Run(name, func(t *testing.T) {
+			if err := checkGolden(r, dir, name, manifest[name]); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// CheckGolden checks a golden corpus like RunGolden, outside of a test. It
+// returns one error per failing message, combined with errors.Join.
+func CheckGolden(r dispatch.Dispatcher, dir string) error {
+	manifest, unlisted, err := loadGolden(dir)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, name := range unlisted {
+		errs = append(errs, fmt.Errorf("golden %s: not in %s", name, GoldenManifest))
+	}
+	for _, name := range sortedKeys(manifest) {
+		if err := checkGolden(r, dir, name, manifest[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// loadGolden reads the manifest in dir and returns it with the names of
+// message files it doesn't list.
+func loadGolden(dir string) (map[string]GoldenRoute, []string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, GoldenManifest))
+	if err != nil {
+		return nil, nil, fmt.Errorf("golden manifest: %w", err)
+	}
+	var manifest map[string]GoldenRoute
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("golden manifest: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("golden corpus: %w", err)
+	}
+	var unlisted []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || name == GoldenManifest {
+			continue
+		}
+		if _, ok := manifest[name]; !ok {
+			unlisted = append(unlisted, name)
+		}
+	}
+	return manifest, unlisted, nil
+}
+
+// checkGolden routes one message file and compares it with want.
+func checkGolden(r dispatch.Dispatcher, dir, name string, want GoldenRoute) error {
+	raw, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return fmt.Errorf("golden %s: %w", name, err)
+	}
+	route, err := dispatch.Resolve(r, raw)
+	if err != nil {
+		return fmt.Errorf("golden %s: %w", name, err)
+	}
+	if route.Source != want.Source || route.Key != want.Key {
+		return fmt.Errorf("golden %s: routed to %s %q, want %s %q", name, route.Source, route.Key, want.Source, want.Key)
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]GoldenRoute) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// GoldenWriter is a dispatch.RecordSink that adds recorded messages to a
+// golden corpus directory, so production traffic captured with
+// dispatch.Record can be replayed by RunGolden. Each message is written to a
+// file named after its source, key, and content hash, and added to the
+// manifest with the source and key it was routed to. Messages that failed to
+// parse are skipped.
+//
+// Example:
+//
+//	w := dispatchtest.NewGoldenWriter("testdata/golden")
+//	r.AddSource(dispatch.Record(eventbridge.NewSource(), w))
+//	// ... process traffic ...
+//	if err := w.Err(); err != nil {
+//	    log.Fatal(err)
+//	}
+type GoldenWriter struct {
+	dir string
+
+	mu  sync.Mutex
+	err error
+}
+
+// NewGoldenWriter returns a GoldenWriter that writes to dir, which must
+// exist. An existing manifest in dir is extended.
+func NewGoldenWriter(dir string) *GoldenWriter {
+	return &GoldenWriter{dir: dir}
+}
+
+// Write implements dispatch.RecordSink.
+func (w *GoldenWriter) Write(rec dispatch.Recording) {
+	if rec.Err != nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.write(rec); err != nil && w.err == nil {
+		w.err = err
+	}
+}
+
+// Err returns the first error writing a message or the manifest, if any.
+func (w *GoldenWriter) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+func (w *GoldenWriter) write(rec dispatch.Recording) error {
+	manifest := map[string]GoldenRoute{}
+	path := filepath.Join(w.dir, GoldenManifest)
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return fmt.Errorf("golden manifest: %w", err)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("golden manifest: %w", err)
+	}
+
+	sum := sha256.Sum256(rec.Raw)
+	name := goldenName(rec.Source) + "-" + goldenName(rec.Key) + "-" + hex.EncodeToString(sum[:4]) + ".json"
+	if err := os.WriteFile(filepath.Join(w.dir, name), rec.Raw, 0o644); err != nil {
+		return fmt.Errorf("golden %s: %w", name, err)
+	}
+
+	manifest[name] = GoldenRoute{Source: rec.Source, Key: rec.Key}
+	data, err = json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return fmt.Errorf("golden manifest: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("golden manifest: %w", err)
+	}
+	return nil
+}
+
+// goldenName replaces characters that aren't safe in file names with '-'.
+func goldenName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, s)
+}
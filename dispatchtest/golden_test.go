@@ -0,0 +1,117 @@
+package dispatchtest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+)
+
+type GoldenSuite struct {
+	suite.Suite
+	router *dispatch.Router
+}
+
+func TestGoldenSuite(t *testing.T) {
+	suite.Run(t, new(GoldenSuite))
+}
+
+func (s *GoldenSuite) SetupTest() {
+	s.router = dispatch.New()
+	s.router.AddSource(NewFakeSource("test"))
+}
+
+// corpus writes files to a temporary golden directory.
+func (s *GoldenSuite) corpus(files map[string]string) string {
+	dir := s.T().TempDir()
+	for name, data := range files {
+		s.Require().NoError(os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600))
+	}
+	return dir
+}
+
+func (s *GoldenSuite) TestRunGolden() {
+	RunGolden(s.T(), s.router, "testdata/golden")
+}
+
+func (s *GoldenSuite) TestCheckGoldenPasses() {
+	s.Assert().NoError(CheckGolden(s.router, "testdata/golden"))
+}
+
+func (s *GoldenSuite) TestReportsWrongRoute() {
+	dir := s.corpus(map[string]string{
+		GoldenManifest: `{"a.json": {"source": "test", "key": "user/deleted"}}`,
+		"a.json":       `{"dispatchtest": "test", "key": "user/created"}`,
+	})
+
+	err := CheckGolden(s.router, dir)
+
+	s.Assert().EqualError(err, `golden a.json: routed to test "user/created", want test "user/deleted"`)
+}
+
+func (s *GoldenSuite) TestReportsUnroutable() {
+	dir := s.corpus(map[string]string{
+		GoldenManifest: `{"a.json": {"source": "test", "key": "user/created"}}`,
+		"a.json":       `{"other": true}`,
+	})
+
+	s.Assert().ErrorIs(CheckGolden(s.router, dir), dispatch.ErrNoSource)
+}
+
+func (s *GoldenSuite) TestReportsUnlistedAndMissingFiles() {
+	dir := s.corpus(map[string]string{
+		GoldenManifest: `{"missing.json": {"source": "test", "key": "user/created"}}`,
+		"extra.json":   `{}`,
+	})
+
+	err := CheckGolden(s.router, dir)
+
+	s.Require().Error(err)
+	s.Assert().ErrorContains(err, "golden extra.json: not in manifest.json")
+	s.Assert().ErrorContains(err, "golden missing.json:")
+}
+
+func (s *GoldenSuite) TestReportsBadManifest() {
+	s.Assert().ErrorContains(CheckGolden(s.router, s.corpus(nil)), "golden manifest:")
+	s.Assert().ErrorContains(CheckGolden(s.router, s.corpus(map[string]string{GoldenManifest: `[`})), "golden manifest:")
+}
+
+func (s *GoldenSuite) TestGoldenWriterCapturesReplayableCorpus() {
+	dir := s.corpus(map[string]string{
+		GoldenManifest: `{"old.json": {"source": "test", "key": "user/created"}}`,
+		"old.json":     `{"dispatchtest": "test", "key": "user/created"}`,
+	})
+	w := NewGoldenWriter(dir)
+	src := NewFakeSource("test")
+	r := dispatch.New()
+	r.AddSource(dispatch.Record(src, w))
+	dispatch.RegisterProcFunc(r, "user/deleted", func(ctx context.Context, p created) error { return nil })
+
+	s.Require().NoError(r.Process(context.Background(), src.Message("user/deleted", created{ID: "1"})))
+	s.Require().NoError(r.Process(context.Background(), src.Message("user/deleted", created{ID: "1"})))
+	s.Require().Error(r.Process(context.Background(), []byte(`{"dispatchtest": "test"}`)))
+	s.Require().NoError(w.Err())
+
+	manifest, unlisted, err := loadGolden(dir)
+	s.Require().NoError(err)
+	s.Assert().Empty(unlisted)
+	s.Require().Len(manifest, 2)
+	for name, route := range manifest {
+		if name != "old.json" {
+			s.Assert().Regexp(`^test-user-deleted-[0-9a-f]{8}\.json$`, name)
+			s.Assert().Equal(GoldenRoute{Source: "test", Key: "user/deleted"}, route)
+		}
+	}
+	s.Assert().NoError(CheckGolden(s.router, dir))
+}
+
+func (s *GoldenSuite) TestGoldenWriterReportsErrors() {
+	w := NewGoldenWriter(filepath.Join(s.T().TempDir(), "missing"))
+
+	w.Write(dispatch.Recording{Raw: []byte(`{}`), Source: "test", Key: "k"})
+
+	s.Assert().ErrorContains(w.Err(), "golden test-k-")
+}
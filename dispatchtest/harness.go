@@ -0,0 +1,133 @@
+package dispatchtest
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bjaus/dispatch"
+)
+
+// Handled is one handler run captured by a Harness.
+type Handled struct {
+	// Source is the name of the source that parsed the message.
+	Source string
+
+	// Key is the routing key the message was dispatched with.
+	Key string
+
+	// Payload is the payload the handler's input was decoded from.
+	Payload json.RawMessage
+
+	// Err is the error the handler returned, if any.
+	Err error
+}
+
+// Harness records every message a router hands to a handler, so tests can
+// assert on routing without instrumenting real handlers. Messages that fail
+// before a handler runs, such as on unmarshal or validation errors, are not
+// recorded.
+type Harness struct {
+	mu      sync.Mutex
+	handled []Handled
+}
+
+// NewHarness attaches a Harness to r. Call it while configuring r, before
+// Process or Build.
+//
+// Example:
+//
+//	h := dispatchtest.NewHarness(r)
+//	require.NoError(t, r.Process(ctx, raw))
+//	h.AssertHandled(t, "user/created", UserCreated{ID: "42"})
+func NewHarness(r *dispatch.Router) *Harness {
+	h := &Harness{}
+	dispatch.WithOnSuccess(func(ctx context.Context, source, key string, d time.Duration) {
+		h.record(ctx, source, key, nil)
+	})(r)
+	dispatch.WithOnFailure(func(ctx context.Context, source, key string, err error, d time.Duration) {
+		h.record(ctx, source, key, err)
+	})(r)
+	return h
+}
+
+func (h *Harness) record(ctx context.Context, source, key string, err error) {
+	msg, _ := dispatch.MessageFromContext(ctx)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handled = append(h.handled, Handled{
+		Source:  source,
+		Key:     key,
+		Payload: slices.Clone(msg.Payload),
+		Err:     err,
+	})
+}
+
+// Handled returns the recorded handler runs, in order.
+func (h *Harness) Handled() []Handled {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return slices.Clone(h.handled)
+}
+
+// Keys returns the keys of the recorded handler runs, in order.
+func (h *Harness) Keys() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	keys := make([]string, len(h.handled))
+	for i, d := range h.handled {
+		keys[i] = d.Key
+	}
+	return keys
+}
+
+// Reset discards the recorded handler runs.
+func (h *Harness) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handled = nil
+}
+
+// AssertHandled reports an error on t unless a handler ran for key with a
+// payload that decodes to want. The payload is decoded into a new value of
+// want's type, the same way the router decodes it for the handler, and
+// compared with reflect.DeepEqual. It returns whether the assertion passed.
+func (h *Harness) AssertHandled(t testing.TB, key string, want any) bool {
+	t.Helper()
+	var payloads []json.RawMessage
+	for _, d := range h.Handled() {
+		if d.Key != key {
+			continue
+		}
+		got := reflect.New(reflect.TypeOf(want))
+		if err := json.Unmarshal(d.Payload, got.Interface()); err != nil {
+			t.Errorf("decode payload for %q: %v", key, err)
+			return false
+		}
+		if reflect.DeepEqual(got.Elem().Interface(), want) {
+			return true
+		}
+		payloads = append(payloads, d.Payload)
+	}
+	if len(payloads) == 0 {
+		t.Errorf("no handler ran for %q; handled: %v", key, h.Keys())
+		return false
+	}
+	t.Errorf("no handler ran for %q with payload %+v; got payloads: %s", key, want, payloads)
+	return false
+}
+
+// AssertNotHandled reports an error on t if a handler ran for key. It
+// returns whether the assertion passed.
+func (h *Harness) AssertNotHandled(t testing.TB, key string) bool {
+	t.Helper()
+	if slices.Contains(h.Keys(), key) {
+		t.Errorf("handler ran for %q", key)
+		return false
+	}
+	return true
+}
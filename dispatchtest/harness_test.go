@@ -0,0 +1,83 @@
+package dispatchtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+)
+
+type created struct {
+	ID   string `json:"id"`
+	Tags []string
+}
+
+type HarnessSuite struct {
+	suite.Suite
+	source  *FakeSource
+	router  *dispatch.Router
+	harness *Harness
+}
+
+func TestHarnessSuite(t *testing.T) {
+	suite.Run(t, new(HarnessSuite))
+}
+
+func (s *HarnessSuite) SetupTest() {
+	s.source = NewFakeSource("test")
+	s.router = dispatch.New()
+	s.router.AddSource(s.source)
+	s.harness = NewHarness(s.router)
+	dispatch.RegisterProcFunc(s.router, "user/created", func(ctx context.Context, p created) error {
+		return nil
+	})
+	dispatch.RegisterProcFunc(s.router, "user/deleted", func(ctx context.Context, p created) error {
+		return errors.New("boom")
+	})
+}
+
+func (s *HarnessSuite) process(key string, payload any) error {
+	return s.router.Process(context.Background(), s.source.Message(key, payload))
+}
+
+func (s *HarnessSuite) TestAssertHandled() {
+	s.Require().NoError(s.process("user/created", created{ID: "1"}))
+	s.Require().NoError(s.process("user/created", created{ID: "2", Tags: []string{"a"}}))
+
+	s.Assert().True(s.harness.AssertHandled(s.T(), "user/created", created{ID: "2", Tags: []string{"a"}}))
+	s.Assert().True(s.harness.AssertHandled(s.T(), "user/created", &created{ID: "1"}))
+	s.Assert().True(s.harness.AssertNotHandled(s.T(), "user/deleted"))
+	s.Assert().Equal([]string{"user/created", "user/created"}, s.harness.Keys())
+}
+
+func (s *HarnessSuite) TestRecordsFailures() {
+	s.Require().Error(s.process("user/deleted", created{ID: "1"}))
+
+	handled := s.harness.Handled()
+	s.Require().Len(handled, 1)
+	s.Assert().Equal("test", handled[0].Source)
+	s.Assert().EqualError(handled[0].Err, "boom")
+	s.Assert().JSONEq(`{"id": "1", "Tags": null}`, string(handled[0].Payload))
+}
+
+func (s *HarnessSuite) TestIgnoresMessagesNotHandled() {
+	s.Require().Error(s.process("user/created", "not an object"))
+	s.Require().Error(s.process("missing", created{}))
+
+	s.Assert().Empty(s.harness.Handled())
+}
+
+func (s *HarnessSuite) TestAssertionsReportFailures() {
+	t := &fakeT{}
+	s.Require().NoError(s.process("user/created", created{ID: "1"}))
+
+	s.Assert().False(s.harness.AssertHandled(t, "user/created", created{ID: "2"}))
+	s.Assert().False(s.harness.AssertHandled(t, "user/deleted", created{ID: "1"}))
+	s.Assert().False(s.harness.AssertNotHandled(t, "user/created"))
+	s.Assert().Len(t.errors, 3)
+
+	s.harness.Reset()
+	s.Assert().True(s.harness.AssertNotHandled(t, "user/created"))
+}
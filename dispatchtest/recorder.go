@@ -0,0 +1,134 @@
+package dispatchtest
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/bjaus/dispatch"
+)
+
+// Call is one hook invocation captured by a HookRecorder.
+type Call struct {
+	// Hook is the hook's option name without the With prefix, such as
+	// "OnParse" or "OnUnmarshalError".
+	Hook string
+
+	// Source and Key are empty when the hook doesn't receive them.
+	Source string
+	Key    string
+
+	// Err is the error passed to the hook, if any.
+	Err error
+}
+
+// HookRecorder captures every hook invocation in order. Register it with
+// dispatch.New(rec.Options()...). The zero value is ready to use.
+//
+// Policy hooks such as OnNoSource return dispatch.ErrHookAbstain, so
+// recording never changes whether a message is skipped or failed, and
+// OnParse and OnReply pass their context and result through unchanged.
+type HookRecorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Options returns router options that register the recorder on every hook.
+func (h *HookRecorder) Options() []dispatch.Option {
+	return []dispatch.Option{
+		dispatch.WithOnParse(func(ctx context.Context, source, key string) context.Context {
+			h.record(Call{Hook: "OnParse", Source: source, Key: key})
+			return ctx
+		}),
+		dispatch.WithOnDispatch(func(ctx context.Context, source, key string) {
+			h.record(Call{Hook: "OnDispatch", Source: source, Key: key})
+		}),
+		dispatch.WithOnSuccess(func(ctx context.Context, source, key string, d time.Duration) {
+			h.record(Call{Hook: "OnSuccess", Source: source, Key: key})
+		}),
+		dispatch.WithOnFailure(func(ctx context.Context, source, key string, err error, d time.Duration) {
+			h.record(Call{Hook: "OnFailure", Source: source, Key: key, Err: err})
+		}),
+		dispatch.WithOnTimings(func(ctx context.Context, source, key string, t dispatch.Timings) {
+			h.record(Call{Hook: "OnTimings", Source: source, Key: key})
+		}),
+		dispatch.WithOnExpired(func(ctx context.Context, source, key string, age time.Duration) {
+			h.record(Call{Hook: "OnExpired", Source: source, Key: key})
+		}),
+		dispatch.WithOnDuplicate(func(ctx context.Context, source, key string) {
+			h.record(Call{Hook: "OnDuplicate", Source: source, Key: key})
+		}),
+		dispatch.WithOnPause(func(ctx context.Context, source, key string, d time.Duration) {
+			h.record(Call{Hook: "OnPause", Source: source, Key: key})
+		}),
+		dispatch.WithOnResume(func(ctx context.Context) {
+			h.record(Call{Hook: "OnResume"})
+		}),
+		dispatch.WithOnDisabled(func(ctx context.Context, source, key string) {
+			h.record(Call{Hook: "OnDisabled", Source: source, Key: key})
+		}),
+		dispatch.WithOnShadow(func(ctx context.Context, source, key string, primary, shadow dispatch.ShadowResult) {
+			h.record(Call{Hook: "OnShadow", Source: source, Key: key, Err: shadow.Err})
+		}),
+		dispatch.WithOnReply(func(ctx context.Context, source, key string, result json.RawMessage) (json.RawMessage, error) {
+			h.record(Call{Hook: "OnReply", Source: source, Key: key})
+			return result, nil
+		}),
+		dispatch.WithOnNoSource(func(ctx context.Context, raw []byte) error {
+			h.record(Call{Hook: "OnNoSource"})
+			return dispatch.ErrHookAbstain
+		}),
+		dispatch.WithOnParseError(func(ctx context.Context, source string, err error) error {
+			h.record(Call{Hook: "OnParseError", Source: source, Err: err})
+			return dispatch.ErrHookAbstain
+		}),
+		dispatch.WithOnNoHandler(func(ctx context.Context, source, key string) error {
+			h.record(Call{Hook: "OnNoHandler", Source: source, Key: key})
+			return dispatch.ErrHookAbstain
+		}),
+		dispatch.WithOnUnmarshalError(func(ctx context.Context, source, key string, err error) error {
+			h.record(Call{Hook: "OnUnmarshalError", Source: source, Key: key, Err: err})
+			return dispatch.ErrHookAbstain
+		}),
+		dispatch.WithOnValidationError(func(ctx context.Context, source, key string, err error) error {
+			h.record(Call{Hook: "OnValidationError", Source: source, Key: key, Err: err})
+			return dispatch.ErrHookAbstain
+		}),
+		dispatch.WithOnOversize(func(ctx context.Context, source, key string, size int) error {
+			h.record(Call{Hook: "OnOversize", Source: source, Key: key})
+			return dispatch.ErrHookAbstain
+		}),
+	}
+}
+
+func (h *HookRecorder) record(c Call) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls = append(h.calls, c)
+}
+
+// Calls returns the recorded invocations, in order.
+func (h *HookRecorder) Calls() []Call {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]Call(nil), h.calls...)
+}
+
+// Hooks returns the names of the recorded hooks, in order.
+func (h *HookRecorder) Hooks() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	names := make([]string, len(h.calls))
+	for i, c := range h.calls {
+		names[i] = c.Hook
+	}
+	return names
+}
+
+// Reset discards the recorded invocations.
+func (h *HookRecorder) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls = nil
+}
@@ -0,0 +1,54 @@
+package dispatchtest
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+)
+
+// FakeReplier is a dispatch.Replier that captures every Reply and Fail call.
+// Unlike dispatch.ChannelReplier it accepts any number of calls, so tests can
+// assert that a message was replied to exactly once. The zero value is ready
+// to use.
+type FakeReplier struct {
+	// ReplyErr, if non-nil, is returned by Reply after recording the result.
+	ReplyErr error
+
+	// FailErr, if non-nil, is returned by Fail after recording the error.
+	FailErr error
+
+	mu       sync.Mutex
+	replies  []json.RawMessage
+	failures []error
+}
+
+// Reply implements dispatch.Replier.
+func (f *FakeReplier) Reply(ctx context.Context, result json.RawMessage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.replies = append(f.replies, slices.Clone(result))
+	return f.ReplyErr
+}
+
+// Fail implements dispatch.Replier.
+func (f *FakeReplier) Fail(ctx context.Context, err error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = append(f.failures, err)
+	return f.FailErr
+}
+
+// Replies returns the results passed to Reply, in order.
+func (f *FakeReplier) Replies() []json.RawMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.replies)
+}
+
+// Failures returns the errors passed to Fail, in order.
+func (f *FakeReplier) Failures() []error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.failures)
+}
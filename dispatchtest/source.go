@@ -0,0 +1,130 @@
+package dispatchtest
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bjaus/dispatch"
+)
+
+// envelopeField is the field FakeSource messages are discriminated by.
+const envelopeField = "dispatchtest"
+
+// errNoKey is returned by FakeSource.Parse for messages without a key.
+var errNoKey = errors.New("dispatchtest: message has no key")
+
+// envelope is the wire format of FakeSource messages.
+type envelope struct {
+	Source        string            `json:"dispatchtest"`
+	Key           string            `json:"key"`
+	Version       string            `json:"version,omitempty"`
+	MessageID     string            `json:"id,omitempty"`
+	CorrelationID string            `json:"correlation_id,omitempty"`
+	Timestamp     time.Time         `json:"timestamp,omitzero"`
+	Deadline      time.Time         `json:"deadline,omitzero"`
+	Priority      int               `json:"priority,omitempty"`
+	Attributes    map[string]string `json:"attributes,omitempty"`
+	ReplyTo       string            `json:"reply_to,omitempty"`
+	Payload       json.RawMessage   `json:"payload,omitempty"`
+}
+
+// FakeSource is a dispatch.Source for tests. Message and Envelope build raw
+// messages that only this source matches, with any key and payload, and
+// Parse turns them back into the dispatch.Message they describe.
+//
+// Set Replier and ParseErr before processing messages.
+type FakeSource struct {
+	name string
+
+	// Replier is set on every parsed message, if non-nil.
+	Replier dispatch.Replier
+
+	// ParseErr, if non-nil, is returned by Parse instead of a message.
+	ParseErr error
+
+	mu     sync.Mutex
+	parsed []dispatch.Message
+}
+
+// NewFakeSource returns a FakeSource with the given name. Sources with
+// different names don't match each other's messages.
+func NewFakeSource(name string) *FakeSource {
+	return &FakeSource{name: name}
+}
+
+// Name implements dispatch.Source.
+func (s *FakeSource) Name() string {
+	return s.name
+}
+
+// Discriminator implements dispatch.Source.
+func (s *FakeSource) Discriminator() dispatch.Discriminator {
+	return dispatch.FieldEquals(envelopeField, s.name)
+}
+
+// Parse implements dispatch.Source. Messages without a key fail to parse.
+func (s *FakeSource) Parse(raw []byte) (dispatch.Message, error) {
+	if s.ParseErr != nil {
+		return dispatch.Message{}, s.ParseErr
+	}
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return dispatch.Message{}, err
+	}
+	if env.Key == "" {
+		return dispatch.Message{}, errNoKey
+	}
+	msg := dispatch.Message{
+		Key:           env.Key,
+		Version:       env.Version,
+		MessageID:     env.MessageID,
+		CorrelationID: env.CorrelationID,
+		Timestamp:     env.Timestamp,
+		Deadline:      env.Deadline,
+		Priority:      env.Priority,
+		Payload:       env.Payload,
+		Attributes:    env.Attributes,
+		ReplyTo:       env.ReplyTo,
+		Replier:       s.Replier,
+	}
+
+	s.mu.Lock()
+	s.parsed = append(s.parsed, msg)
+	s.mu.Unlock()
+
+	return msg, nil
+}
+
+// Message returns a raw message for this source with the given key and
+// payload, which is marshaled to JSON unless it is already a
+// json.RawMessage. It panics if payload can't be marshaled.
+func (s *FakeSource) Message(key string, payload any) []byte {
+	return s.Envelope(dispatch.Message{Key: key, Payload: marshalPayload(payload)})
+}
+
+// Envelope returns a raw message for this source that parses to msg. The
+// Replier field is ignored; set FakeSource.Replier instead.
+func (s *FakeSource) Envelope(msg dispatch.Message) []byte {
+	return mustMarshal(envelope{
+		Source:        s.name,
+		Key:           msg.Key,
+		Version:       msg.Version,
+		MessageID:     msg.MessageID,
+		CorrelationID: msg.CorrelationID,
+		Timestamp:     msg.Timestamp,
+		Deadline:      msg.Deadline,
+		Priority:      msg.Priority,
+		Attributes:    msg.Attributes,
+		ReplyTo:       msg.ReplyTo,
+		Payload:       msg.Payload,
+	})
+}
+
+// Parsed returns the messages Parse has returned, in order.
+func (s *FakeSource) Parsed() []dispatch.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]dispatch.Message(nil), s.parsed...)
+}
@@ -80,8 +80,8 @@
 //  2. Parse: Full envelope parsing only after discriminator matches
 //
 // This avoids expensive JSON parsing when messages don't match a source,
-// and enables O(1) hot-path matching via adaptive ordering (the last successful
-// source is tried first on subsequent messages).
+// and enables O(1) hot-path matching via adaptive ordering (the sources that
+// matched most often recently are tried first on subsequent messages).
 //
 //	func (s *mySource) Discriminator() dispatch.Discriminator {
 //	    return dispatch.And(
@@ -90,11 +90,22 @@
 //	    )
 //	}
 //
+// WithSourceAffinity goes further, caching the source for each message
+// fingerprint (see JSONFingerprint) so repeat message types skip
+// discriminators entirely. For routers with many sources, WithParallelMatch
+// evaluates discriminators on several goroutines.
+//
 // Composable discriminators are provided:
 //   - HasFields: Check for field presence
 //   - FieldEquals: Check field value
+//   - FieldHasPrefix, FieldHasSuffix, FieldContains: Check string field contents
+//   - FieldIsString, FieldIsObject, FieldIsArray: Check field type
+//   - FieldTrue: Check boolean flag (views implementing BoolView)
+//   - FieldGreaterThan: Check numeric threshold (views implementing NumberView)
+//   - AnyElementMatches: Check elements of an array (views implementing ArrayView)
 //   - And: All discriminators must match
 //   - Or: Any discriminator must match
+//   - Not: Discriminator must not match
 //
 // # Inspector and View
 //
@@ -110,6 +121,15 @@
 //	    GetBytes(path string) ([]byte, bool)
 //	}
 //
+// Paths use dot notation ("detail.userId") with numeric indexes for array
+// elements ("Records.0.eventSource"). Use Path to address keys containing dots:
+//
+//	dispatch.FieldEquals(dispatch.Path("attributes", "app.name"), "billing")
+//
+// Use GetView to query inside a nested object without re-inspecting:
+//
+//	detail, ok := dispatch.GetView(v, "detail")
+//
 // By default, the router uses JSONInspector for all sources. For mixed formats
 // (e.g., JSON and protobuf), use AddGroup with a custom inspector:
 //
@@ -117,6 +137,18 @@
 //	r.AddSource(jsonSource)                          // Uses default JSON inspector
 //	r.AddGroup(protoInspector, grpcSource, kafkaSource) // Custom inspector
 //
+// A single source with its own format can use AddSourceWithInspector, or
+// implement InspectorProvider, instead of a group of its own. A source
+// wrapped with Fallback accepts any message its inspector can read, once no
+// other source in any group has matched.
+//
+// Sources can be controlled at runtime by name: EnableSource turns one off
+// or back on, and SetSourcePriority makes sources with a higher priority be
+// tried first within their group:
+//
+//	r.EnableSource("legacy-sns", false)
+//	r.SetSourcePriority("orders-sns", 10)
+//
 // # Sources
 //
 // A Source parses raw message bytes and returns routing information:
@@ -133,8 +165,22 @@
 //
 // The Message struct contains:
 //   - Key: routing key to match against registered handlers
+//   - Keys: optional additional routing keys; the message is dispatched to
+//     each key's handler in turn, and replies are combined into one
 //   - Version: optional schema version for version-aware routing
+//   - MessageID, CorrelationID: optional identifiers for tracing; CorrelationID
+//     defaults to MessageID and is available to handlers via CorrelationID(ctx),
+//     and WithDuplicateSuppression skips recently handled MessageIDs
+//   - Timestamp: optional production time, used by WithMaxMessageAge
+//   - Deadline: optional time at which the handler's context is canceled
+//   - Lease: optional way to delay redelivery, used by ExtendLease and
+//     WithLeaseHeartbeat
+//   - Priority: optional ordering hint; ProcessBatch and the workers started
+//     with StartWorkers handle higher values first
 //   - Payload: raw JSON to unmarshal into the handler's type
+//   - Defaults: optional envelope fields added to the payload when it lacks them
+//   - Attributes: optional transport metadata (SNS attributes, Kafka headers)
+//   - ReplyTo: optional reply address, turned into a Replier by WithReplierFactory
 //   - Replier: optional interface for request-response patterns
 //
 // Example source implementation:
@@ -164,10 +210,26 @@
 //	    }, nil
 //	}
 //
+// Sources can implement ViewParser to build the Message from the View their
+// discriminator already matched, avoiding a second parse of the raw bytes.
+//
 // Use SourceFunc for simple sources without a struct:
 //
 //	r.AddSource(dispatch.SourceFunc("custom", dispatch.HasFields("event"), parseFunc))
 //
+// Nested envelopes, such as an EventBridge event delivered through SNS to
+// SQS, are peeled with Unwrap instead of one source that parses every layer.
+// Each Unwrapper removes one layer, outermost first, and contributes its
+// metadata (MessageID, Timestamp, Attributes, Defaults, and so on) to fields
+// the inner source leaves empty:
+//
+//	r.AddSource(dispatch.Unwrap(eventbridge.NewSource(), sqsLayer, snsLayer))
+//
+// TransformRaw wraps a source with byte-level preprocessing, such as base64
+// decoding or decompression, applied before matching and parsing:
+//
+//	r.AddSource(dispatch.TransformRaw(kinesisSource, gunzip))
+//
 // # Handlers
 //
 // Procedures implement the Proc interface (fire-and-forget):
@@ -198,6 +260,54 @@
 //	    return &Result{...}, nil
 //	})
 //
+// A Key[T] ties a key name to its payload type, so RegisterProcKey and
+// RegisterFuncKey only compile with handlers for that type:
+//
+//	var UserCreatedKey = dispatch.NewKey[UserCreated]("user/created")
+//	dispatch.RegisterProcKey(r, UserCreatedKey, &UserCreatedProc{})
+//
+// Handlers that implement Starter or Closer are started by Router.Start and
+// closed by Router.Shutdown once in-flight messages have finished.
+//
+// RegisterService registers each exported method of a type shaped like a
+// Proc or Func method, under a prefix plus the method name:
+//
+//	dispatch.RegisterService(r, "order/", &OrderService{}) // "order/Created", ...
+//
+// RegisterProcLazy and RegisterFuncLazy register a constructor instead of a
+// handler. The router builds the handler on the first message for its key
+// and caches it, so expensive dependencies initialize after a cold start
+// only when needed.
+//
+// Invoke calls the handler for a key directly with a typed payload, without
+// a source or hooks, for internal calls, admin tooling, and tests.
+//
+// A Saga chains Func steps for a multi-step workflow, saving progress to a
+// SagaStore and running compensations in reverse if a step fails.
+// RegisterSaga registers one for a key.
+//
+// RegisterProcIf and RegisterFuncIf register handlers for one key that each
+// accept some payload shapes, chosen by a guard evaluated against a View of
+// the payload. The first accepting guard wins; a handler registered with
+// RegisterProc or RegisterFunc handles the rest:
+//
+//	dispatch.RegisterProcIf(r, "payment/received", dispatch.HasFields("card").Match, &CardPaymentProc{})
+//	dispatch.RegisterProcIf(r, "payment/received", dispatch.HasFields("iban").Match, &BankPaymentProc{})
+//
+// WithEnabled turns a handler off at runtime, such as behind a feature flag.
+// Messages for a disabled handler are skipped and reported to the OnDisabled
+// hooks rather than failing as unhandled.
+//
+// RegisterShadowProc and RegisterShadowFunc run a second handler for a key
+// in the background, with the same payload as the primary. Its outcome is
+// reported to the OnShadow hooks alongside the primary's and never changes
+// how the message is handled.
+//
+// RegisterCanaryProc and RegisterCanaryFunc send a percentage of a key's
+// messages to a second handler instead of the primary, bucketed by a hash of
+// the correlation ID so the choice is stable per message and workflow.
+// IsCanary reports which one handled the message.
+//
 // # Replier
 //
 // Sources can provide a Replier in Message for transport-specific response handling.
@@ -225,6 +335,13 @@
 //	    }, nil
 //	}
 //
+// ChannelReplier delivers the result in process; call Wait to receive it.
+// Use WithReplyRetry to retry transient Replier errors with exponential backoff.
+//
+// Synchronous callers that need the result without a Replier can use
+// Router.ProcessResult, which also reports the matched source, key,
+// version, and duration.
+//
 // # Hooks
 //
 // Hooks provide observability without coupling to specific logging or metrics systems.
@@ -247,17 +364,52 @@
 //   - WithOnDispatch: Called just before handler executes
 //   - WithOnSuccess: Called after handler succeeds
 //   - WithOnFailure: Called after handler fails
+//   - WithOnTimings: Called with per-stage durations after handling
+//   - WithOnReply: Transforms a successful result before Replier.Reply
+//   - WithOnExpired: Called when WithMaxMessageAge drops a stale message
+//   - WithOnDuplicate: Called when WithDuplicateSuppression skips a message
+//   - WithOnPause, WithOnResume: Called when workers pause for a Backpressure
+//     error and when they resume
+//   - WithOnDisabled: Called when WithEnabled skips a disabled handler
+//   - WithOnShadow: Called with primary and shadow outcomes after a shadow run
 //   - WithOnNoSource: Called when no source matches
+//   - WithOnParseError: Called when a source fails to parse the message
 //   - WithOnNoHandler: Called when no handler is registered
 //   - WithOnUnmarshalError: Called on JSON unmarshal errors
 //   - WithOnValidationError: Called on validation errors
+//   - WithOnOversize: Called when WithMaxPayloadSize rejects a message
+//   - WithOnError: Called on any failure, with the Stage it happened at
 //
 // Multiple hooks of the same type are called in order.
 //
+// Error hooks that only observe return ErrHookAbstain, so they don't change
+// whether a message is skipped or failed.
+//
+// Use WithHookFilter to limit a set of hooks to selected keys or sources:
+//
+//	dispatch.WithHookFilter(dispatch.ExcludeKeys("heartbeat"), dispatch.WithSlog(logger))
+//
+// Use WithSampledHooks to run expensive hooks for only a fraction of messages:
+//
+//	dispatch.WithSampledHooks(0.01, dispatchotel.Hooks()...)
+//
+// Use WithBoundedKeys to replace keys with no registered handler with a
+// bounded label, so they don't explode metric cardinality:
+//
+//	dispatch.WithBoundedKeys(dispatch.CollapseKeys("unknown"), statsd.Hooks(client)...)
+//
+// For basic structured logging, WithSlog registers a default set of hooks:
+//
+//	r := dispatch.New(dispatch.WithSlog(slog.Default()))
+//
+// WithAudit writes a canonical AuditRecord for every message, including its
+// outcome and a hash of the payload, to an AuditSink.
+//
 // # Source-Specific Hooks
 //
 // Sources can implement optional hook interfaces to add source-specific behavior.
-// These hooks run after global hooks, and both are always called:
+// These hooks run after global hooks by default (see WithHookOrder), and both are
+// always called:
 //
 //	type OnParseHook interface {
 //	    OnParse(ctx context.Context, key string) context.Context
@@ -273,6 +425,14 @@
 //
 // For error-returning hooks, if either global or source returns an error, that
 // error is returned. This allows sources to override global skip/fail policies.
+// When both return an error the first wins; use WithHookErrorPolicy(JoinHookErrors)
+// to combine them with errors.Join instead.
+//
+// To attach hooks to a source you don't own, wrap it with WithSourceHooks:
+//
+//	r.AddSource(dispatch.WithSourceHooks(vendorSource, dispatch.SourceHooks{
+//	    OnNoHandler: func(ctx context.Context, key string) error { return nil },
+//	}))
 //
 // # Validation
 //
@@ -291,6 +451,14 @@
 //	    )
 //	}
 //
+// WithJSONSchema checks the raw payload against a JSON Schema before it is
+// unmarshaled, for schemas governed separately from Go types:
+//
+//	dispatch.RegisterProc(r, "user/created", proc, dispatch.WithJSONSchema(schema))
+//
+// WithSchemaProvider validates payloads against versioned schemas from a
+// SchemaProvider, such as the schemaregistry package's Confluent provider.
+//
 // Validation errors trigger the OnValidationError hook.
 //
 // # Error Handling
@@ -310,8 +478,114 @@
 //	    }),
 //	)
 //
+// Errors returned by Process are *DispatchError values carrying the Stage,
+// source, and key where the message failed. Routing failures wrap ErrNoSource,
+// ErrNoHandler, ErrUnmarshal, ErrValidation, or ErrOversize, so callers can
+// branch with errors.Is and errors.As instead of matching error strings.
+//
+// ExtendLease and WithLeaseHeartbeat extend Message.Lease, such as an SQS
+// visibility timeout, so slow handlers aren't redelivered mid-work.
+//
+// WithMaxPayloadSize rejects messages, and payloads extracted by sources,
+// larger than a limit before they are inspected or unmarshaled.
+//
+// WithErrorPolicy and WithErrorRules classify known errors centrally as
+// ActionSkip, ActionRetry, or ActionFail; ActionFail errors wrap ErrPermanent.
+//
+// WithDeadLetterer sends every message a hook skips, with its raw bytes and
+// failure details, to a DeadLetterer such as a dead-letter queue.
+//
+// WithQuarantine stores messages with no matching source or handler in a
+// QuarantineStore instead of failing them; ReplayQuarantine processes them
+// again once the router can handle them.
+//
+// WithChaos injects latency and ErrChaos failures at the parse, handle, and
+// reply stages, for resilience testing in staging.
+//
+// # Publishing
+//
+// A Registry maps payload types to routing keys. Publish sends a typed
+// payload through a Transport under its registered key, and WithRegistry
+// makes a router check its handlers against the same registry.
+// RegisterType registers into DefaultRegistry, and RegisterProcTyped and
+// RegisterFuncTyped derive a handler's key from its payload type. The sqs,
+// sns, and eventbridge modules provide transports.
+//
+// EventBridgeKey builds keys such as "users.service:user/created" from an
+// EventBridge source and detail-type, so producers and consumers derive them
+// the same way; the eventbridge module's WithSourceKeys uses it on both
+// sides.
+//
+// Client is the caller's side of Func and Replier: Call sends a request with
+// a reply address and waits for Client.Deliver to pass it the result.
+// Loopback is a Transport that processes events with a Router in the same
+// process, for local development and integration tests without a broker.
+//
+// The fx module provides a Router built from sources, options, and handler
+// registrations contributed to an Uber fx application.
+//
 // # Thread Safety
 //
 // Router is safe for concurrent use after configuration is complete. Do not call
 // AddSource, AddGroup, or RegisterProc/RegisterFunc after calling Process.
+//
+// ProcessOptions configure a single Process, ProcessResult, or Submit call,
+// so runners can attach transport context such as a receipt handle or
+// partition: WithAttributes, WithReplier, WithSource to skip matching, and
+// WithProcessHooks.
+//
+// StartWorkers and Submit process messages on a bounded pool of goroutines,
+// with backpressure when the queue is full. StopWorkers drains the queue.
+// Handlers return Backpressure to pause the workers, or any runner that reads
+// BackpressureDelay, while a throttled downstream recovers.
+// Shutdown rejects new messages and waits for in-flight ones to finish.
+// Healthy pings sources that implement Pinger, for readiness probes.
+// Sources that implement Initializer are initialized on their first
+// matching message, or up front by Router.Start.
+//
+// AsyncAPI generates an AsyncAPI document describing the keys a router
+// handles and their payload and reply schemas. The cmd/dispatchgen tool
+// generates payload types, keys, and handler stubs from such a document.
+// Keys is a catalog of routing keys: HandlerKeys collects a router's keys,
+// Keys.GenerateGo writes them as Go constants, as dispatchgen -consts does
+// from a document, and WithKeys refuses handlers for keys outside it.
+// Router.RoutingTable describes the sources, discriminators, and handlers in
+// a form that marshals to JSON, and RoutingTableHandler serves it over HTTP.
+// Router.Handlers returns a HandlerInfo for each handler, with its key,
+// payload, result, and handler types and its registration options, and
+// HandlerInfoFromContext returns the one for the message being handled.
+// The debug package serves the routing table, stats, recent messages, and a
+// token-guarded form to inject a message, for incident triage.
+//
+// Router.Clone copies a configured router so it can be specialized without
+// changing the original.
+//
+// Router.Build returns a CompiledRouter: a frozen copy of the router whose
+// match index and source order are fixed at Build, with no runtime source
+// controls or adaptive ordering. Configure a Router, call Build, and hand the
+// CompiledRouter to consumers; later changes to the Router don't affect it.
+// Both implement Dispatcher, the runtime API that Invoke, Resolve,
+// RoutingTableHandler, and the debug package accept.
+//
+// The routeconfig package builds routers from YAML or JSON configuration
+// that binds routing keys to handlers registered by name, and rebuilds them
+// when the file changes.
+//
+// # Testing
+//
+// Record wraps a source so every message it parses is written to a
+// RecordSink, for capturing production traffic to replay in tests.
+//
+// The dispatchtest package provides FakeSource, FakeReplier, and HookRecorder
+// test doubles, with assertion helpers for replies, hooks, and error stages,
+// and builds EventBridge, SNS, and SQS envelopes for testing real sources.
+// Its Harness records which handlers ran, with their payloads, RunGolden
+// checks a corpus of captured messages against their expected routes,
+// GoldenWriter adds recorded messages to such a corpus, and FuzzSource fuzzes
+// custom sources.
+//
+// Resolve reports the source and key a message routes to without running
+// hooks or handlers; CheckSources reports messages matched by more than one
+// source, and CheckCoverage reports registered keys without a sample message
+// and samples without a handler.
 package dispatch
@@ -0,0 +1,54 @@
+package dispatch
+
+import "context"
+
+// OnDisabledFunc is called when a message is skipped because its handler was
+// turned off by WithEnabled.
+type OnDisabledFunc func(ctx context.Context, source, key string)
+
+// WithEnabled gates a handler behind a runtime check, such as a feature
+// flag. enabled is called with the message's context before each message is
+// handled; when it returns false the handler is skipped, the OnDisabled
+// hooks are called, and Process returns nil. No reply is sent.
+//
+// Example:
+//
+//	dispatch.RegisterProc(r, "order/created", proc, dispatch.WithEnabled(func(ctx context.Context) bool {
+//	    return flags.Bool(ctx, "orders-consumer", true)
+//	}))
+func WithEnabled(enabled func(ctx context.Context) bool) HandlerOption {
+	return func(c *handlerConfig) {
+		c.enabled = enabled
+	}
+}
+
+// WithOnDisabled adds a hook called when a message is skipped because its
+// handler is disabled by WithEnabled. Multiple hooks are called in order.
+func WithOnDisabled(fn OnDisabledFunc) Option {
+	return func(r *Router) {
+		r.hooks.onDisabled = append(r.hooks.onDisabled, fn)
+	}
+}
+
+// setEnabled records the WithEnabled check for key, replacing any from an
+// earlier registration.
+func (r *Router) setEnabled(key string, enabled func(context.Context) bool) {
+	if enabled == nil {
+		delete(r.enabled, key)
+		return
+	}
+	r.enabled[key] = enabled
+}
+
+// disabled reports whether the handler for key is turned off for ctx.
+func (r *Router) disabled(ctx context.Context, key string) bool {
+	enabled, ok := r.enabled[key]
+	return ok && !enabled(ctx)
+}
+
+// callOnDisabled calls the disabled hooks.
+func (r *Router) callOnDisabled(ctx context.Context, sourceName, key string) {
+	for _, fn := range r.hooks.onDisabled {
+		fn(ctx, sourceName, key)
+	}
+}
@@ -0,0 +1,139 @@
+package dispatch
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type flagKey struct{}
+
+type EnabledSuite struct {
+	suite.Suite
+	on      atomic.Bool
+	handled int
+}
+
+func TestEnabledSuite(t *testing.T) {
+	suite.Run(t, new(EnabledSuite))
+}
+
+func (s *EnabledSuite) SetupTest() {
+	s.on.Store(true)
+	s.handled = 0
+}
+
+func (s *EnabledSuite) router(opts ...Option) *Router {
+	r := New(opts...)
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(`{}`)}, nil
+	}))
+	RegisterProcFunc(r, "test", func(ctx context.Context, p struct{}) error {
+		s.handled++
+		return nil
+	}, WithEnabled(func(ctx context.Context) bool { return s.on.Load() }))
+	return r
+}
+
+func (s *EnabledSuite) process(r *Router) error {
+	return r.Process(context.Background(), []byte(`{"type": "test"}`))
+}
+
+func (s *EnabledSuite) TestRunsEnabledHandler() {
+	r := s.router()
+
+	s.Require().NoError(s.process(r))
+	s.Assert().Equal(1, s.handled)
+}
+
+func (s *EnabledSuite) TestSkipsDisabledHandler() {
+	var disabled, noHandler, dispatched []string
+	r := s.router(
+		WithOnDisabled(func(ctx context.Context, source, key string) {
+			disabled = append(disabled, source+"/"+key)
+		}),
+		WithOnNoHandler(func(ctx context.Context, source, key string) error {
+			noHandler = append(noHandler, key)
+			return nil
+		}),
+		WithOnDispatch(func(ctx context.Context, source, key string) {
+			dispatched = append(dispatched, key)
+		}),
+	)
+	s.on.Store(false)
+
+	s.Require().NoError(s.process(r))
+
+	s.Assert().Zero(s.handled)
+	s.Assert().Equal([]string{"test/test"}, disabled)
+	s.Assert().Empty(noHandler)
+	s.Assert().Empty(dispatched)
+	s.Assert().Equal(uint64(1), r.Stats().Keys["test"].Skipped)
+}
+
+func (s *EnabledSuite) TestChecksOnEveryMessage() {
+	r := s.router()
+
+	s.Require().NoError(s.process(r))
+	s.on.Store(false)
+	s.Require().NoError(s.process(r))
+	s.on.Store(true)
+	s.Require().NoError(s.process(r))
+
+	s.Assert().Equal(2, s.handled)
+}
+
+func (s *EnabledSuite) TestReceivesMessageContext() {
+	r := New(WithOnParse(func(ctx context.Context, source, key string) context.Context {
+		return context.WithValue(ctx, flagKey{}, "beta")
+	}))
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(`{}`)}, nil
+	}))
+	var got any
+	RegisterProcFunc(r, "test", func(ctx context.Context, p struct{}) error { return nil },
+		WithEnabled(func(ctx context.Context) bool {
+			got = ctx.Value(flagKey{})
+			return true
+		}))
+
+	s.Require().NoError(s.process(r))
+
+	s.Assert().Equal("beta", got)
+}
+
+func (s *EnabledSuite) TestReregistrationClearsGate() {
+	r := s.router()
+	s.on.Store(false)
+	RegisterProcFunc(r, "test", func(ctx context.Context, p struct{}) error {
+		s.handled++
+		return nil
+	})
+
+	s.Require().NoError(s.process(r))
+	s.Assert().Equal(1, s.handled)
+}
+
+func (s *EnabledSuite) TestFuncDisabledSendsNoReply() {
+	replier := &countingReplier{}
+	r := New()
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(`{}`), Replier: replier}, nil
+	}))
+	RegisterFuncFunc(r, "test", func(ctx context.Context, p struct{}) (string, error) { return "ok", nil },
+		WithEnabled(func(context.Context) bool { return false }))
+
+	s.Require().NoError(s.process(r))
+
+	s.Assert().Zero(replier.replies)
+}
+
+func (s *EnabledSuite) TestCloneKeepsGate() {
+	c := s.router().Clone()
+	s.on.Store(false)
+
+	s.Require().NoError(s.process(c))
+	s.Assert().Zero(s.handled)
+}
@@ -0,0 +1,129 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrPermanent marks failures that retrying won't fix. Errors that
+// WithErrorPolicy or WithErrorRules classify as ActionFail wrap it, so
+// consumers can dead-letter the message instead of redelivering it:
+//
+//	if errors.Is(err, dispatch.ErrPermanent) {
+//	    dlq.Send(ctx, body)
+//	    msg.Ack()
+//	}
+var ErrPermanent = errors.New("permanent failure")
+
+// Action is what to do with a message that failed with a known error.
+type Action uint8
+
+const (
+	// ActionSkip acknowledges the message: Process returns nil.
+	ActionSkip Action = iota + 1
+	// ActionRetry fails the message so the transport redelivers it: Process
+	// returns the error.
+	ActionRetry
+	// ActionFail fails the message permanently: Process returns the error
+	// wrapped so errors.Is(err, ErrPermanent) reports true.
+	ActionFail
+)
+
+// String returns the action name, such as "skip".
+func (a Action) String() string {
+	switch a {
+	case ActionSkip:
+		return "skip"
+	case ActionRetry:
+		return "retry"
+	case ActionFail:
+		return "fail"
+	}
+	return fmt.Sprintf("Action(%d)", uint8(a))
+}
+
+// ErrorRule maps errors matching Match to Action.
+type ErrorRule struct {
+	Match  func(err error) bool
+	Action Action
+}
+
+// ErrorIs returns a rule that applies action to errors matching target with
+// errors.Is.
+func ErrorIs(target error, action Action) ErrorRule {
+	return ErrorRule{
+		Match:  func(err error) bool { return errors.Is(err, target) },
+		Action: action,
+	}
+}
+
+// WithErrorRules classifies failures centrally, replacing errors.Is chains
+// spread across hooks. Rules are checked in order and the first match
+// decides the action, at every stage (see WithOnError). Errors that match no
+// rule are left to other hooks and the default behavior.
+//
+// Example:
+//
+//	dispatch.WithErrorRules(
+//	    dispatch.ErrorIs(dispatch.ErrNoHandler, dispatch.ActionSkip),
+//	    dispatch.ErrorIs(ErrAccountClosed, dispatch.ActionSkip),
+//	    dispatch.ErrorIs(ErrInvalidState, dispatch.ActionFail),
+//	    dispatch.ErrorRule{Match: isThrottled, Action: dispatch.ActionRetry},
+//	)
+func WithErrorRules(rules ...ErrorRule) Option {
+	return WithOnError(func(ctx context.Context, stage Stage, source, key string, err error) error {
+		for _, rule := range rules {
+			if rule.Match(err) {
+				return applyAction(rule.Action, err)
+			}
+		}
+		return ErrHookAbstain
+	})
+}
+
+// WithErrorPolicy is WithErrorRules for a table of errors matched with
+// errors.Is. If an error matches several entries, the most severe action
+// wins: ActionFail, then ActionRetry, then ActionSkip.
+//
+// Example:
+//
+//	dispatch.WithErrorPolicy(map[error]dispatch.Action{
+//	    ErrDuplicate:    dispatch.ActionSkip,
+//	    ErrUpstreamDown: dispatch.ActionRetry,
+//	    ErrInvalidState: dispatch.ActionFail,
+//	})
+func WithErrorPolicy(policy map[error]Action) Option {
+	return WithOnError(func(ctx context.Context, stage Stage, source, key string, err error) error {
+		var action Action
+		for target, a := range policy {
+			if a > action && errors.Is(err, target) {
+				action = a
+			}
+		}
+		if action == 0 {
+			return ErrHookAbstain
+		}
+		return applyAction(action, err)
+	})
+}
+
+// applyAction returns the OnError result for action.
+func applyAction(action Action, err error) error {
+	switch action {
+	case ActionSkip:
+		return nil
+	case ActionFail:
+		return &permanentError{err: err}
+	}
+	return err
+}
+
+// permanentError marks err as permanent without changing its message.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string        { return e.err.Error() }
+func (e *permanentError) Unwrap() error        { return e.err }
+func (e *permanentError) Is(target error) bool { return target == ErrPermanent }
@@ -0,0 +1,100 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	errDuplicate = errors.New("duplicate")
+	errUpstream  = errors.New("upstream down")
+	errInvalid   = errors.New("invalid state")
+)
+
+type ErrorPolicySuite struct {
+	suite.Suite
+}
+
+func TestErrorPolicySuite(t *testing.T) {
+	suite.Run(t, new(ErrorPolicySuite))
+}
+
+// process runs a message whose handler fails with handlerErr.
+func (s *ErrorPolicySuite) process(opt Option, handlerErr error) error {
+	r := New(opt)
+	r.AddSource(&testSource{name: "test"})
+	RegisterProc(r, "test", &testHandler{err: handlerErr})
+	return r.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`))
+}
+
+func (s *ErrorPolicySuite) TestPolicyActions() {
+	policy := WithErrorPolicy(map[error]Action{
+		errDuplicate: ActionSkip,
+		errUpstream:  ActionRetry,
+		errInvalid:   ActionFail,
+	})
+
+	s.Assert().NoError(s.process(policy, errDuplicate))
+
+	err := s.process(policy, errUpstream)
+	s.Assert().ErrorIs(err, errUpstream)
+	s.Assert().NotErrorIs(err, ErrPermanent)
+
+	err = s.process(policy, errInvalid)
+	s.Assert().ErrorIs(err, errInvalid)
+	s.Assert().ErrorIs(err, ErrPermanent)
+	s.Assert().EqualError(err, "invalid state")
+
+	other := errors.New("other")
+	err = s.process(policy, other)
+	s.Assert().ErrorIs(err, other)
+	s.Assert().NotErrorIs(err, ErrPermanent)
+}
+
+func (s *ErrorPolicySuite) TestPolicyMostSevereActionWins() {
+	policy := WithErrorPolicy(map[error]Action{
+		errDuplicate: ActionSkip,
+		errInvalid:   ActionFail,
+	})
+
+	err := s.process(policy, errors.Join(errDuplicate, errInvalid))
+
+	s.Assert().ErrorIs(err, ErrPermanent)
+}
+
+func (s *ErrorPolicySuite) TestRulesFirstMatchWins() {
+	rules := WithErrorRules(
+		ErrorIs(errDuplicate, ActionSkip),
+		ErrorRule{Match: func(err error) bool { return true }, Action: ActionFail},
+	)
+
+	s.Assert().NoError(s.process(rules, errDuplicate))
+	s.Assert().ErrorIs(s.process(rules, errUpstream), ErrPermanent)
+}
+
+func (s *ErrorPolicySuite) TestAppliesToRoutingErrors() {
+	r := New(WithErrorPolicy(map[error]Action{ErrNoHandler: ActionSkip}))
+	r.AddSource(&testSource{name: "test"})
+
+	s.Assert().NoError(r.Process(context.Background(), []byte(`{"type": "missing", "payload": {}}`)))
+}
+
+func (s *ErrorPolicySuite) TestUnmatchedErrorsLeaveOtherHooksInCharge() {
+	r := New(
+		WithErrorPolicy(map[error]Action{errInvalid: ActionFail}),
+		WithOnNoHandler(func(ctx context.Context, source, key string) error { return nil }),
+	)
+	r.AddSource(&testSource{name: "test"})
+
+	s.Assert().NoError(r.Process(context.Background(), []byte(`{"type": "missing", "payload": {}}`)))
+}
+
+func (s *ErrorPolicySuite) TestActionString() {
+	s.Assert().Equal("skip", ActionSkip.String())
+	s.Assert().Equal("retry", ActionRetry.String())
+	s.Assert().Equal("fail", ActionFail.String())
+	s.Assert().Equal("Action(0)", Action(0).String())
+}
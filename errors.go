@@ -0,0 +1,108 @@
+package dispatch
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors for the ways routing can fail before a handler runs. Errors
+// returned by Process wrap them, so callers can branch with errors.Is:
+//
+//	if errors.Is(err, dispatch.ErrNoHandler) {
+//	    // ack and move on
+//	}
+var (
+	// ErrNoSource means no source's discriminator matched the message.
+	ErrNoSource = errors.New("no source matched message")
+
+	// ErrNoHandler means no handler is registered for the message's key.
+	ErrNoHandler = errors.New("no handler for key")
+
+	// ErrUnmarshal means the payload could not be unmarshaled into the
+	// handler's type.
+	ErrUnmarshal = errors.New("unmarshal payload")
+
+	// ErrValidation means the payload failed its Validate method.
+	ErrValidation = errors.New("validate payload")
+
+	// ErrOversize means the message or its payload is larger than the limit
+	// set with WithMaxPayloadSize.
+	ErrOversize = errors.New("payload too large")
+)
+
+// Stage identifies the step of processing where a message failed.
+type Stage uint8
+
+const (
+	// StageMatch is finding a source whose discriminator matches.
+	StageMatch Stage = iota + 1
+	// StageParse is parsing the message with the matched source.
+	StageParse
+	// StageRoute is looking up the handler for the message's key.
+	StageRoute
+	// StageUnmarshal is unmarshaling the payload into the handler's type.
+	StageUnmarshal
+	// StageValidate is validating the payload.
+	StageValidate
+	// StageHandle is running the handler.
+	StageHandle
+	// StageReply is sending the result through the Replier, including
+	// building the Replier and running OnReply hooks.
+	StageReply
+)
+
+// String returns the stage name, such as "parse".
+func (s Stage) String() string {
+	switch s {
+	case StageMatch:
+		return "match"
+	case StageParse:
+		return "parse"
+	case StageRoute:
+		return "route"
+	case StageUnmarshal:
+		return "unmarshal"
+	case StageValidate:
+		return "validate"
+	case StageHandle:
+		return "handle"
+	case StageReply:
+		return "reply"
+	}
+	return fmt.Sprintf("Stage(%d)", uint8(s))
+}
+
+// DispatchError is the error returned by Process and ProcessBatch when a
+// message fails. It records where the failure happened; Err is the
+// underlying error, such as the handler's error or one wrapping ErrNoHandler.
+// Its message is the message of Err.
+//
+// Example:
+//
+//	var derr *dispatch.DispatchError
+//	if errors.As(err, &derr) && derr.Stage == dispatch.StageHandle {
+//	    metrics.Incr("handler.failed", "key:"+derr.Key)
+//	}
+type DispatchError struct {
+	Stage  Stage
+	Source string // empty for StageMatch
+	Key    string // empty before the message is parsed
+	Err    error
+}
+
+func (e *DispatchError) Error() string { return e.Err.Error() }
+func (e *DispatchError) Unwrap() error { return e.Err }
+
+// dispatchError wraps a non-nil err in a *DispatchError. Errors that already
+// are one are returned unchanged. Only err itself is checked: a
+// *DispatchError wrapped inside err, such as one a handler returned from
+// another router, belongs to a different call.
+func dispatchError(stage Stage, source, key string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*DispatchError); ok {
+		return err
+	}
+	return &DispatchError{Stage: stage, Source: source, Key: key, Err: err}
+}
@@ -0,0 +1,147 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DispatchErrorSuite struct {
+	suite.Suite
+	router *Router
+}
+
+func TestDispatchErrorSuite(t *testing.T) {
+	suite.Run(t, new(DispatchErrorSuite))
+}
+
+func (s *DispatchErrorSuite) SetupTest() {
+	s.router = New()
+	s.router.AddSource(&testSource{name: "test"})
+}
+
+func (s *DispatchErrorSuite) TestStages() {
+	errHandler := errors.New("handler failed")
+	RegisterProc(s.router, "fails", &testHandler{err: errHandler})
+	RegisterProcFunc(s.router, "validated", func(ctx context.Context, p validatablePayload) error {
+		return nil
+	})
+
+	tests := map[string]struct {
+		raw      string
+		stage    Stage
+		source   string
+		key      string
+		sentinel error
+		msg      string
+	}{
+		"no source": {
+			raw: `{"other": true}`, stage: StageMatch,
+			sentinel: ErrNoSource, msg: "no source matched message",
+		},
+		"parse": {
+			raw: `{"type": 1, "payload": {}}`, stage: StageParse, source: "test",
+		},
+		"no handler": {
+			raw: `{"type": "missing", "payload": {}}`, stage: StageRoute, source: "test", key: "missing",
+			sentinel: ErrNoHandler, msg: "no handler for key: missing",
+		},
+		"unmarshal": {
+			raw: `{"type": "fails", "payload": "bad"}`, stage: StageUnmarshal, source: "test", key: "fails",
+			sentinel: ErrUnmarshal,
+		},
+		"validation": {
+			raw: `{"type": "validated", "payload": {}}`, stage: StageValidate, source: "test", key: "validated",
+			sentinel: ErrValidation, msg: "validate payload: value is required",
+		},
+		"handler": {
+			raw: `{"type": "fails", "payload": {}}`, stage: StageHandle, source: "test", key: "fails",
+			sentinel: errHandler, msg: "handler failed",
+		},
+	}
+
+	for name, tt := range tests {
+		s.Run(name, func() {
+			err := s.router.Process(context.Background(), []byte(tt.raw))
+
+			var derr *DispatchError
+			s.Require().ErrorAs(err, &derr)
+			s.Assert().Equal(tt.stage, derr.Stage)
+			s.Assert().Equal(tt.source, derr.Source)
+			s.Assert().Equal(tt.key, derr.Key)
+			if tt.sentinel != nil {
+				s.Assert().ErrorIs(err, tt.sentinel)
+			}
+			if tt.msg != "" {
+				s.Assert().EqualError(err, tt.msg)
+			}
+		})
+	}
+}
+
+func (s *DispatchErrorSuite) TestReplyStage() {
+	errReply := errors.New("send failed")
+	r := New()
+	r.AddSource(SourceFunc("rr", HasFields("type"), func([]byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(`{}`), Replier: completeReplier(func(ctx context.Context, err error) error {
+			return errReply
+		})}, nil
+	}))
+	RegisterProc(r, "test", &testHandler{})
+
+	err := r.Process(context.Background(), []byte(`{"type": "test"}`))
+
+	var derr *DispatchError
+	s.Require().ErrorAs(err, &derr)
+	s.Assert().Equal(StageReply, derr.Stage)
+	s.Assert().ErrorIs(err, errReply)
+}
+
+func (s *DispatchErrorSuite) TestHookErrorsAreWrapped() {
+	errPolicy := errors.New("retry later")
+	r := New(WithOnNoHandler(func(ctx context.Context, source, key string) error {
+		return errPolicy
+	}))
+	r.AddSource(&testSource{name: "test"})
+
+	err := r.Process(context.Background(), []byte(`{"type": "missing", "payload": {}}`))
+
+	var derr *DispatchError
+	s.Require().ErrorAs(err, &derr)
+	s.Assert().Equal(StageRoute, derr.Stage)
+	s.Assert().ErrorIs(err, errPolicy)
+	s.Assert().NotErrorIs(err, ErrNoHandler)
+}
+
+func (s *DispatchErrorSuite) TestWrapsErrorsFromNestedRouters() {
+	inner := New()
+	inner.AddSource(&testSource{name: "inner"})
+
+	RegisterProcFunc(s.router, "forward", func(ctx context.Context, p struct{}) error {
+		return fmt.Errorf("forward: %w", inner.Process(ctx, []byte(`{"type": "missing", "payload": {}}`)))
+	})
+
+	err := s.router.Process(context.Background(), []byte(`{"type": "forward", "payload": {}}`))
+
+	var derr *DispatchError
+	s.Require().ErrorAs(err, &derr)
+	s.Assert().Equal(StageHandle, derr.Stage)
+	s.Assert().Equal("test", derr.Source)
+	s.Assert().Equal("forward", derr.Key)
+	s.Assert().ErrorIs(err, ErrNoHandler)
+}
+
+func (s *DispatchErrorSuite) TestSuccessIsNil() {
+	RegisterProc(s.router, "ok", &testHandler{})
+
+	s.Assert().NoError(s.router.Process(context.Background(), []byte(`{"type": "ok", "payload": {}}`)))
+}
+
+func (s *DispatchErrorSuite) TestStageString() {
+	s.Assert().Equal("match", StageMatch.String())
+	s.Assert().Equal("reply", StageReply.String())
+	s.Assert().Equal("Stage(0)", Stage(0).String())
+}
@@ -0,0 +1,128 @@
+// Package eventbridge provides a dispatch.Transport that puts events on an
+// EventBridge bus, for use with dispatch.Publisher, and a dispatch.Source
+// that routes the events it delivers:
+//
+//	pub := dispatch.NewPublisher(events, eventbridge.NewPublisher(client, "users.service"))
+//	err := dispatch.Publish(ctx, pub, UserCreated{ID: "42"})
+//
+// Each event's key becomes the detail-type and its payload the detail, so
+// rules can match on detail-type and consumers unmarshal detail directly.
+//
+// Detail-types alone can collide between services. With WithSourceKeys on
+// both sides, keys are built with dispatch.EventBridgeKey instead, such as
+// "users.service:user/created": the Publisher splits them into source and
+// detail-type, and the Source joins them back together:
+//
+//	var UserCreatedKey = dispatch.EventBridgeKey("users.service", "user/created")
+//
+//	pub := dispatch.NewPublisher(events, eventbridge.NewPublisher(client, "users.service", eventbridge.WithSourceKeys()))
+//	r.AddSource(eventbridge.NewSource(eventbridge.WithSourceKeys()))
+package eventbridge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awseventbridge "github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/bjaus/dispatch"
+)
+
+// API is the subset of the EventBridge client used by Publisher.
+// *eventbridge.Client satisfies it.
+type API interface {
+	PutEvents(ctx context.Context, in *awseventbridge.PutEventsInput, optFns ...func(*awseventbridge.Options)) (*awseventbridge.PutEventsOutput, error)
+}
+
+// Option configures a Publisher or Source.
+type Option func(*config)
+
+type config struct {
+	bus        string
+	resources  []string
+	sourceKeys bool
+}
+
+// WithEventBus sets the name or ARN of the event bus. Defaults to the
+// account's default bus.
+func WithEventBus(bus string) Option {
+	return func(c *config) {
+		c.bus = bus
+	}
+}
+
+// WithResources adds resource ARNs to every event.
+func WithResources(arns ...string) Option {
+	return func(c *config) {
+		c.resources = append(c.resources, arns...)
+	}
+}
+
+// WithSourceKeys uses keys built by dispatch.EventBridgeKey, which carry the
+// event's source as well as its detail-type. A Publisher puts each event
+// with the source and detail-type split from its key, falling back to its
+// own source for keys without one; a Source routes on both.
+func WithSourceKeys() Option {
+	return func(c *config) {
+		c.sourceKeys = true
+	}
+}
+
+// Publisher is a dispatch.Transport that puts events on an EventBridge bus
+// with the given source. EventBridge events have no message attributes, so
+// the event's MessageID, CorrelationID, and Attributes are not sent;
+// EventBridge assigns its own event ID.
+type Publisher struct {
+	client API
+	source string
+	cfg    config
+}
+
+// NewPublisher returns a Publisher that puts events with the given source,
+// such as "users.service".
+func NewPublisher(client API, source string, opts ...Option) *Publisher {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Publisher{client: client, source: source, cfg: cfg}
+}
+
+// Send implements dispatch.Transport. It returns an error if EventBridge
+// rejects the entry, even when the PutEvents call itself succeeds.
+func (p *Publisher) Send(ctx context.Context, e dispatch.Event) error {
+	source, detailType := p.source, e.Key
+	if p.cfg.sourceKeys {
+		if s, dt, ok := dispatch.SplitEventBridgeKey(e.Key); ok {
+			source, detailType = s, dt
+		}
+	}
+
+	entry := types.PutEventsRequestEntry{
+		Source:     aws.String(source),
+		DetailType: aws.String(detailType),
+		Detail:     aws.String(string(e.Payload)),
+		Resources:  p.cfg.resources,
+	}
+	if p.cfg.bus != "" {
+		entry.EventBusName = aws.String(p.cfg.bus)
+	}
+	if !e.Time.IsZero() {
+		entry.Time = aws.Time(e.Time)
+	}
+
+	out, err := p.client.PutEvents(ctx, &awseventbridge.PutEventsInput{
+		Entries: []types.PutEventsRequestEntry{entry},
+	})
+	if err != nil {
+		return err
+	}
+	if out.FailedEntryCount > 0 && len(out.Entries) > 0 {
+		failed := out.Entries[0]
+		return fmt.Errorf("put event %s: %s: %s", e.Key, aws.ToString(failed.ErrorCode), aws.ToString(failed.ErrorMessage))
+	}
+	return nil
+}
+
+var _ dispatch.Transport = (*Publisher)(nil)
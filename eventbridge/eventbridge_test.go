@@ -0,0 +1,110 @@
+package eventbridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awseventbridge "github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeAPI struct {
+	inputs []*awseventbridge.PutEventsInput
+	out    *awseventbridge.PutEventsOutput
+	err    error
+}
+
+func (f *fakeAPI) PutEvents(ctx context.Context, in *awseventbridge.PutEventsInput, _ ...func(*awseventbridge.Options)) (*awseventbridge.PutEventsOutput, error) {
+	f.inputs = append(f.inputs, in)
+	if f.out != nil {
+		return f.out, f.err
+	}
+	return &awseventbridge.PutEventsOutput{}, f.err
+}
+
+type userCreated struct {
+	ID string `json:"id"`
+}
+
+type PublisherSuite struct {
+	suite.Suite
+	api *fakeAPI
+	reg *dispatch.Registry
+}
+
+func (s *PublisherSuite) SetupTest() {
+	s.api = &fakeAPI{}
+	s.reg = dispatch.NewRegistry()
+	dispatch.Register[userCreated](s.reg, "UserCreated")
+}
+
+func TestPublisherSuite(t *testing.T) {
+	suite.Run(t, new(PublisherSuite))
+}
+
+func (s *PublisherSuite) publisher(opts ...Option) *dispatch.Publisher {
+	return dispatch.NewPublisher(s.reg, NewPublisher(s.api, "users.service", opts...))
+}
+
+func (s *PublisherSuite) TestPutsEvent() {
+	pub := s.publisher(WithEventBus("users"), WithResources("arn:aws:iam::123:user/ada"))
+	before := time.Now()
+
+	s.Require().NoError(dispatch.Publish(context.Background(), pub, userCreated{ID: "42"}))
+
+	s.Require().Len(s.api.inputs, 1)
+	s.Require().Len(s.api.inputs[0].Entries, 1)
+	entry := s.api.inputs[0].Entries[0]
+	s.Assert().Equal("users.service", aws.ToString(entry.Source))
+	s.Assert().Equal("UserCreated", aws.ToString(entry.DetailType))
+	s.Assert().JSONEq(`{"id": "42"}`, aws.ToString(entry.Detail))
+	s.Assert().Equal("users", aws.ToString(entry.EventBusName))
+	s.Assert().Equal([]string{"arn:aws:iam::123:user/ada"}, entry.Resources)
+	s.Assert().False(aws.ToTime(entry.Time).Before(before))
+}
+
+func (s *PublisherSuite) TestDefaultBus() {
+	s.Require().NoError(dispatch.Publish(context.Background(), s.publisher(), userCreated{}))
+
+	s.Assert().Nil(s.api.inputs[0].Entries[0].EventBusName)
+}
+
+func (s *PublisherSuite) TestReturnsFailedEntries() {
+	s.api.out = &awseventbridge.PutEventsOutput{
+		FailedEntryCount: 1,
+		Entries: []types.PutEventsResultEntry{{
+			ErrorCode:    aws.String("ThrottlingException"),
+			ErrorMessage: aws.String("Rate exceeded"),
+		}},
+	}
+
+	err := dispatch.Publish(context.Background(), s.publisher(), userCreated{})
+
+	s.Assert().EqualError(err, "put event UserCreated: ThrottlingException: Rate exceeded")
+}
+
+func (s *PublisherSuite) TestReturnsPutErrors() {
+	s.api.err = errors.New("network")
+
+	s.Assert().ErrorIs(dispatch.Publish(context.Background(), s.publisher(), userCreated{}), s.api.err)
+}
+
+func (s *PublisherSuite) TestSourceKeys() {
+	pub := NewPublisher(s.api, "users.service", WithSourceKeys())
+	key := dispatch.EventBridgeKey("orders.service", "order/placed")
+
+	s.Require().NoError(pub.Send(context.Background(), dispatch.Event{Key: key, Payload: []byte(`{}`)}))
+	s.Require().NoError(pub.Send(context.Background(), dispatch.Event{Key: "UserCreated", Payload: []byte(`{}`)}))
+
+	first := s.api.inputs[0].Entries[0]
+	s.Assert().Equal("orders.service", aws.ToString(first.Source))
+	s.Assert().Equal("order/placed", aws.ToString(first.DetailType))
+	second := s.api.inputs[1].Entries[0]
+	s.Assert().Equal("users.service", aws.ToString(second.Source), "keys without a source use the publisher's")
+	s.Assert().Equal("UserCreated", aws.ToString(second.DetailType))
+}
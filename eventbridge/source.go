@@ -0,0 +1,76 @@
+package eventbridge
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/bjaus/dispatch"
+)
+
+// Source is a dispatch.Source for EventBridge events, as delivered to a
+// Lambda target or through an SQS queue or SNS topic once unwrapped. It
+// routes on the event's detail-type, or with WithSourceKeys on its source
+// and detail-type, and uses its detail as the payload.
+type Source struct {
+	cfg config
+}
+
+// NewSource returns a Source named "eventbridge".
+//
+// Example:
+//
+//	r.AddSource(eventbridge.NewSource(eventbridge.WithSourceKeys()))
+func NewSource(opts ...Option) *Source {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Source{cfg: cfg}
+}
+
+// Name implements dispatch.Source.
+func (s *Source) Name() string {
+	return "eventbridge"
+}
+
+// Discriminator implements dispatch.Source. It matches messages with source,
+// detail-type, and detail fields.
+func (s *Source) Discriminator() dispatch.Discriminator {
+	return dispatch.HasFields("source", "detail-type", "detail")
+}
+
+// event is the part of an EventBridge event Source reads.
+type event struct {
+	ID         string          `json:"id"`
+	Source     string          `json:"source"`
+	DetailType string          `json:"detail-type"`
+	Time       string          `json:"time"`
+	Detail     json.RawMessage `json:"detail"`
+}
+
+// Parse implements dispatch.Source.
+func (s *Source) Parse(raw []byte) (dispatch.Message, error) {
+	var e event
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return dispatch.Message{}, err
+	}
+	if e.DetailType == "" {
+		return dispatch.Message{}, errors.New("eventbridge: missing detail-type")
+	}
+
+	msg := dispatch.Message{
+		Key:       e.DetailType,
+		MessageID: e.ID,
+		Payload:   e.Detail,
+	}
+	if s.cfg.sourceKeys {
+		msg.Key = dispatch.EventBridgeKey(e.Source, e.DetailType)
+	}
+	if t, err := time.Parse(time.RFC3339, e.Time); err == nil {
+		msg.Timestamp = t
+	}
+	return msg, nil
+}
+
+var _ dispatch.Source = (*Source)(nil)
@@ -0,0 +1,63 @@
+package eventbridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bjaus/dispatch"
+	"github.com/bjaus/dispatch/dispatchtest"
+	"github.com/stretchr/testify/suite"
+)
+
+type SourceSuite struct {
+	suite.Suite
+}
+
+func TestSourceSuite(t *testing.T) {
+	suite.Run(t, new(SourceSuite))
+}
+
+func (s *SourceSuite) TestParse() {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	raw := dispatchtest.EventBridgeEnvelope("user/created", userCreated{ID: "42"},
+		dispatchtest.WithEventSource("users.service"), dispatchtest.WithID("evt-1"), dispatchtest.WithTime(at))
+
+	msg, err := NewSource().Parse(raw)
+	s.Require().NoError(err)
+
+	s.Assert().Equal("user/created", msg.Key)
+	s.Assert().Equal("evt-1", msg.MessageID)
+	s.Assert().True(at.Equal(msg.Timestamp))
+	s.Assert().JSONEq(`{"id": "42"}`, string(msg.Payload))
+}
+
+func (s *SourceSuite) TestSourceKeys() {
+	raw := dispatchtest.EventBridgeEnvelope("user/created", userCreated{ID: "42"},
+		dispatchtest.WithEventSource("users.service"))
+
+	msg, err := NewSource(WithSourceKeys()).Parse(raw)
+	s.Require().NoError(err)
+
+	s.Assert().Equal(dispatch.EventBridgeKey("users.service", "user/created"), msg.Key)
+}
+
+func (s *SourceSuite) TestRoutes() {
+	r := dispatch.New()
+	r.AddSource(NewSource(WithSourceKeys()))
+	var got userCreated
+	dispatch.RegisterProcFunc(r, dispatch.EventBridgeKey("users.service", "user/created"), func(ctx context.Context, p userCreated) error {
+		got = p
+		return nil
+	})
+
+	raw := dispatchtest.EventBridgeEnvelope("user/created", userCreated{ID: "42"},
+		dispatchtest.WithEventSource("users.service"))
+	s.Require().NoError(r.Process(context.Background(), raw))
+	s.Assert().Equal("42", got.ID)
+}
+
+func (s *SourceSuite) TestMissingDetailType() {
+	_, err := NewSource().Parse([]byte(`{"source": "users.service", "detail": {}}`))
+	s.Assert().Error(err)
+}
@@ -19,7 +19,7 @@ type UserCreatedPayload struct {
 // UserCreatedHandler handles user/created events.
 type UserCreatedHandler struct{}
 
-func (h *UserCreatedHandler) Handle(ctx context.Context, p UserCreatedPayload) error {
+func (h *UserCreatedHandler) Run(ctx context.Context, p UserCreatedPayload) error {
 	fmt.Printf("User created: %s (%s)\n", p.UserID, p.Email)
 	return nil
 }
@@ -33,18 +33,18 @@ func (s *simpleSource) Discriminator() dispatch.Discriminator {
 	return dispatch.HasFields("type", "payload")
 }
 
-func (s *simpleSource) Parse(raw []byte) (dispatch.Parsed, error) {
+func (s *simpleSource) Parse(raw []byte) (dispatch.Message, error) {
 	var env struct {
 		Type    string          `json:"type"`
 		Payload json.RawMessage `json:"payload"`
 	}
 	if err := json.Unmarshal(raw, &env); err != nil {
-		return dispatch.Parsed{}, err
+		return dispatch.Message{}, err
 	}
 	if env.Type == "" {
-		return dispatch.Parsed{}, fmt.Errorf("missing type field")
+		return dispatch.Message{}, fmt.Errorf("missing type field")
 	}
-	return dispatch.Parsed{
+	return dispatch.Message{
 		Key:     env.Type,
 		Payload: env.Payload,
 	}, nil
@@ -65,7 +65,7 @@ func Example() {
 	r.AddSource(&simpleSource{})
 
 	// Register handler
-	dispatch.Register(r, "user/created", &UserCreatedHandler{})
+	dispatch.RegisterProc(r, "user/created", &UserCreatedHandler{})
 
 	// Process a message
 	msg := []byte(`{"type": "user/created", "payload": {"user_id": "123", "email": "test@example.com"}}`)
@@ -82,7 +82,7 @@ func Example_handlerFunc() {
 	r.AddSource(&simpleSource{})
 
 	// Register with a function instead of a struct
-	dispatch.RegisterFunc(r, "ping", func(ctx context.Context, p struct{ Message string }) error {
+	dispatch.RegisterProcFunc(r, "ping", func(ctx context.Context, p struct{ Message string }) error {
 		fmt.Println("Ping:", p.Message)
 		return nil
 	})
@@ -98,21 +98,21 @@ func Example_sourceFunc() {
 	r := dispatch.New()
 
 	// Use SourceFunc for simple sources
-	r.AddSource(dispatch.SourceFunc("custom", dispatch.HasFields("event", "data"), func(raw []byte) (dispatch.Parsed, error) {
+	r.AddSource(dispatch.SourceFunc("custom", dispatch.HasFields("event", "data"), func(raw []byte) (dispatch.Message, error) {
 		var env struct {
 			Event string          `json:"event"`
 			Data  json.RawMessage `json:"data"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return dispatch.Parsed{}, err
+			return dispatch.Message{}, err
 		}
 		if env.Event == "" {
-			return dispatch.Parsed{}, fmt.Errorf("missing event field")
+			return dispatch.Message{}, fmt.Errorf("missing event field")
 		}
-		return dispatch.Parsed{Key: env.Event, Payload: env.Data}, nil
+		return dispatch.Message{Key: env.Event, Payload: env.Data}, nil
 	}))
 
-	dispatch.RegisterFunc(r, "hello", func(ctx context.Context, p struct{ Name string }) error {
+	dispatch.RegisterProcFunc(r, "hello", func(ctx context.Context, p struct{ Name string }) error {
 		fmt.Println("Hello,", p.Name)
 		return nil
 	})
@@ -136,7 +136,7 @@ func Example_multipleHooks() {
 	)
 	r.AddSource(&simpleSource{})
 
-	dispatch.RegisterFunc(r, "test", func(ctx context.Context, p struct{}) error {
+	dispatch.RegisterProcFunc(r, "test", func(ctx context.Context, p struct{}) error {
 		return nil
 	})
 
@@ -172,7 +172,7 @@ func Example_skipBadMessages() {
 	// Error: <nil>
 }
 
-// completionSource demonstrates a source with completion callback.
+// completionSource demonstrates a source with a Replier.
 type completionSource struct{}
 
 func (s *completionSource) Name() string { return "completion" }
@@ -181,37 +181,45 @@ func (s *completionSource) Discriminator() dispatch.Discriminator {
 	return dispatch.HasFields("task", "token", "payload")
 }
 
-func (s *completionSource) Parse(raw []byte) (dispatch.Parsed, error) {
+func (s *completionSource) Parse(raw []byte) (dispatch.Message, error) {
 	var env struct {
 		Task    string          `json:"task"`
 		Token   string          `json:"token"`
 		Payload json.RawMessage `json:"payload"`
 	}
 	if err := json.Unmarshal(raw, &env); err != nil {
-		return dispatch.Parsed{}, err
+		return dispatch.Message{}, err
 	}
 	if env.Token == "" {
-		return dispatch.Parsed{}, fmt.Errorf("missing token field")
+		return dispatch.Message{}, fmt.Errorf("missing token field")
 	}
-	return dispatch.Parsed{
+	return dispatch.Message{
 		Key:     env.Task,
 		Payload: env.Payload,
-		Complete: func(ctx context.Context, err error) error {
-			if err != nil {
-				fmt.Printf("Task %s failed: %v\n", env.Token, err)
-			} else {
-				fmt.Printf("Task %s succeeded\n", env.Token)
-			}
-			return nil
-		},
+		Replier: &taskReplier{token: env.Token},
 	}, nil
 }
 
+// taskReplier reports task completion for a token.
+type taskReplier struct {
+	token string
+}
+
+func (r *taskReplier) Reply(ctx context.Context, result json.RawMessage) error {
+	fmt.Printf("Task %s succeeded\n", r.token)
+	return nil
+}
+
+func (r *taskReplier) Fail(ctx context.Context, err error) error {
+	fmt.Printf("Task %s failed: %v\n", r.token, err)
+	return nil
+}
+
 func Example_completion() {
 	r := dispatch.New()
 	r.AddSource(&completionSource{})
 
-	dispatch.RegisterFunc(r, "process", func(ctx context.Context, p struct{ Value int }) error {
+	dispatch.RegisterProcFunc(r, "process", func(ctx context.Context, p struct{ Value int }) error {
 		fmt.Println("Processing value:", p.Value)
 		return nil
 	})
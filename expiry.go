@@ -0,0 +1,54 @@
+package dispatch
+
+import (
+	"context"
+	"time"
+)
+
+// OnExpiredFunc is called when a message is dropped because it is older than
+// the limit set with WithMaxMessageAge. age is how old the message was.
+type OnExpiredFunc func(ctx context.Context, source, key string, age time.Duration)
+
+// WithMaxMessageAge skips messages whose Timestamp is more than d in the
+// past, so replayed or badly delayed events don't produce stale side effects.
+// Skipped messages are not handled and Process returns nil. Messages without
+// a Timestamp are never considered expired.
+//
+// Example:
+//
+//	r := dispatch.New(
+//	    dispatch.WithMaxMessageAge(15*time.Minute),
+//	    dispatch.WithOnExpired(func(ctx context.Context, source, key string, age time.Duration) {
+//	        logger.Warn("dropping stale message", "key", key, "age", age)
+//	    }),
+//	)
+func WithMaxMessageAge(d time.Duration) Option {
+	return func(r *Router) {
+		r.maxAge = d
+	}
+}
+
+// WithOnExpired adds a hook called when a message is skipped by
+// WithMaxMessageAge. Multiple hooks are called in order.
+func WithOnExpired(fn OnExpiredFunc) Option {
+	return func(r *Router) {
+		r.hooks.onExpired = append(r.hooks.onExpired, fn)
+	}
+}
+
+// expired reports whether msg is older than the router's maximum age, and
+// how old it is.
+func (r *Router) expired(msg Message) (time.Duration, bool) {
+	if r.maxAge <= 0 || msg.Timestamp.IsZero() {
+		return 0, false
+	}
+	age := time.Since(msg.Timestamp)
+	return age, age > r.maxAge
+}
+
+// callOnExpired calls the expired hooks.
+func (r *Router) callOnExpired(ctx context.Context, sourceName, key string, age time.Duration) {
+	for _, fn := range r.hooks.onExpired {
+		fn(ctx, sourceName, key, age)
+	}
+}
@@ -0,0 +1,77 @@
+package dispatch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MaxMessageAgeSuite struct {
+	suite.Suite
+}
+
+func TestMaxMessageAgeSuite(t *testing.T) {
+	suite.Run(t, new(MaxMessageAgeSuite))
+}
+
+// timestampRouter returns a router whose source stamps messages with ts and
+// reports whether the handler ran.
+func (s *MaxMessageAgeSuite) timestampRouter(ts time.Time, handled *bool, opts ...Option) *Router {
+	r := New(opts...)
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Timestamp: ts, Payload: []byte(`{}`)}, nil
+	}))
+	RegisterProcFunc(r, "test", func(ctx context.Context, p struct{}) error {
+		*handled = true
+		return nil
+	})
+	return r
+}
+
+func (s *MaxMessageAgeSuite) TestSkipsExpiredMessage() {
+	var handled bool
+	var gotKey string
+	var gotAge time.Duration
+
+	r := s.timestampRouter(time.Now().Add(-time.Hour), &handled,
+		WithMaxMessageAge(time.Minute),
+		WithOnExpired(func(ctx context.Context, source, key string, age time.Duration) {
+			gotKey = key
+			gotAge = age
+		}),
+	)
+
+	err := r.Process(context.Background(), []byte(`{"type": "test"}`))
+
+	s.Require().NoError(err)
+	s.Assert().False(handled)
+	s.Assert().Equal("test", gotKey)
+	s.Assert().GreaterOrEqual(gotAge, time.Hour)
+	s.Assert().Equal(uint64(1), r.Stats().Keys["test"].Skipped)
+}
+
+func (s *MaxMessageAgeSuite) TestHandlesFreshMessage() {
+	var handled bool
+	r := s.timestampRouter(time.Now(), &handled, WithMaxMessageAge(time.Minute))
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test"}`)))
+	s.Assert().True(handled)
+}
+
+func (s *MaxMessageAgeSuite) TestIgnoresMissingTimestamp() {
+	var handled bool
+	r := s.timestampRouter(time.Time{}, &handled, WithMaxMessageAge(time.Minute))
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test"}`)))
+	s.Assert().True(handled)
+}
+
+func (s *MaxMessageAgeSuite) TestDisabledByDefault() {
+	var handled bool
+	r := s.timestampRouter(time.Now().Add(-24*time.Hour), &handled)
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test"}`)))
+	s.Assert().True(handled)
+}
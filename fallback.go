@@ -0,0 +1,60 @@
+package dispatch
+
+// Fallback marks s as a catch-all for its format: it accepts any message its
+// group's inspector can read, or its own inspector if it provides one,
+// without consulting its discriminator. Fallbacks are tried only after no
+// other source in any group matched, in group order, so a catch-all never
+// takes messages from a more specific source registered after it.
+//
+// Use it for a group that should take anything in its format, such as every
+// message that decodes as protobuf, instead of a discriminator that always
+// matches. CheckSources doesn't report fallbacks as ambiguous.
+//
+// Example:
+//
+//	r.AddGroup(protoInspector, ordersSource, dispatch.Fallback(protoSource))
+func Fallback(s Source) Source {
+	return &hookedSource{Source: s, fallback: true}
+}
+
+// Discriminator returns a match-all discriminator for fallbacks, or forwards
+// to the wrapped source.
+func (s *hookedSource) Discriminator() Discriminator {
+	if s.fallback {
+		return fallbackDiscriminator{}
+	}
+	return s.Source.Discriminator()
+}
+
+// isFallback reports whether s, or a source it wraps, was marked by
+// Fallback.
+func (s *hookedSource) isFallback() bool {
+	return s.fallback || isFallback(s.Source)
+}
+
+// isFallback reports whether src was marked by Fallback.
+func isFallback(src Source) bool {
+	f, ok := src.(interface{ isFallback() bool })
+	return ok && f.isFallback()
+}
+
+// fallbackDiscriminator matches every message; see Fallback.
+type fallbackDiscriminator struct{}
+
+func (fallbackDiscriminator) Match(View) bool { return true }
+
+func (fallbackDiscriminator) String() string { return "fallback" }
+
+// matchFallback returns the first fallback whose inspector can read the
+// message, with the View it read.
+func (r *Router) matchFallback(cache *viewCache, idx *matchIndex) (*compiledSource, View) {
+	for _, g := range idx.allGroups() {
+		for i := range g.fallbacks {
+			cs := &g.fallbacks[i]
+			if view, ok := cache.get(r.inspectorFor(cs)); ok {
+				return cs, view
+			}
+		}
+	}
+	return nil, nil
+}
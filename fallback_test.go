@@ -0,0 +1,86 @@
+package dispatch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FallbackSuite struct {
+	suite.Suite
+	router *Router
+}
+
+func TestFallbackSuite(t *testing.T) {
+	suite.Run(t, new(FallbackSuite))
+}
+
+func (s *FallbackSuite) SetupTest() {
+	s.router = New()
+	s.router.AddSource(&testSource{name: "test"})
+	s.router.AddGroup(kvInspector{}, Fallback(&kvSource{name: "any-kv"}))
+	s.router.AddGroup(kvInspector{}, &kvSource{name: "kv"})
+}
+
+func (s *FallbackSuite) resolve(raw string) string {
+	route, err := Resolve(s.router, []byte(raw))
+	if err != nil {
+		return ""
+	}
+	return route.Source
+}
+
+func (s *FallbackSuite) TestMatchesAnythingInFormat() {
+	s.Assert().Equal("any-kv", s.resolve("key=test;format=other"))
+	s.Assert().Equal("any-kv", s.resolve("key=test"))
+}
+
+func (s *FallbackSuite) TestSpecificSourcesFirst() {
+	s.Assert().Equal("kv", s.resolve("key=test;format=kv"), "a later group's source wins over the fallback")
+	s.Assert().Equal("test", s.resolve(`{"type": "test", "payload": {}}`))
+}
+
+func (s *FallbackSuite) TestOtherFormats() {
+	route, err := Resolve(s.router, []byte(`{"other": true}`))
+	s.Assert().ErrorIs(err, ErrNoSource)
+	s.Assert().Empty(route.Source)
+}
+
+func (s *FallbackSuite) TestNotRemembered() {
+	r := New(WithSourceAffinity(func(raw []byte) (string, bool) { return "same", true }))
+	r.AddGroup(kvInspector{}, Fallback(&kvSource{name: "any-kv"}), &kvSource{name: "kv"})
+	s.router = r
+
+	s.Require().Equal("any-kv", s.resolve("key=test"))
+	s.Assert().Equal("kv", s.resolve("key=test;format=kv"))
+}
+
+func (s *FallbackSuite) TestProcess() {
+	h := &testHandler{}
+	RegisterProc(s.router, "test", h)
+
+	s.Require().NoError(s.router.Process(context.Background(), []byte("key=test")))
+	s.Assert().True(h.called)
+	s.Assert().Equal("kv", h.payload.Value)
+}
+
+func (s *FallbackSuite) TestWithHooks() {
+	r := New()
+	r.AddGroupWithHooks(kvInspector{}, []Option{WithOnDispatch(func(ctx context.Context, source, key string) {})},
+		&kvSource{name: "kv"}, Fallback(&kvSource{name: "any-kv"}))
+	s.router = r
+
+	s.Assert().Equal("any-kv", s.resolve("key=test"))
+}
+
+func (s *FallbackSuite) TestNotAmbiguous() {
+	err := CheckSources(s.router, map[string][]byte{"kv": []byte("key=test;format=kv")})
+	s.Assert().NoError(err)
+}
+
+func (s *FallbackSuite) TestRoutingTable() {
+	table := s.router.RoutingTable()
+	s.Require().Len(table.Sources, 3)
+	s.Assert().Equal("fallback", table.Sources[1].Discriminator)
+}
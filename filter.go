@@ -0,0 +1,55 @@
+package dispatch
+
+import (
+	"context"
+	"slices"
+)
+
+// HookMatcher reports whether filtered hooks should fire for a message.
+// Source or key is empty when not yet known, such as for WithOnNoSource.
+type HookMatcher func(source, key string) bool
+
+// MatchKeys returns a HookMatcher that matches the given routing keys.
+func MatchKeys(keys ...string) HookMatcher {
+	return func(_, key string) bool {
+		return slices.Contains(keys, key)
+	}
+}
+
+// ExcludeKeys returns a HookMatcher that matches every routing key except
+// the given ones. Use it to silence noisy, high-volume keys.
+func ExcludeKeys(keys ...string) HookMatcher {
+	return func(_, key string) bool {
+		return !slices.Contains(keys, key)
+	}
+}
+
+// MatchSources returns a HookMatcher that matches the given source names.
+func MatchSources(sources ...string) HookMatcher {
+	return func(source, _ string) bool {
+		return slices.Contains(sources, source)
+	}
+}
+
+// WithHookFilter registers hook options that only fire for messages accepted
+// by m. Options other than hooks have no effect when passed here.
+//
+// A filtered error hook that does not fire is treated as if it were not
+// registered for that message, so the router's default fail behavior still
+// applies unless another hook handles the message.
+//
+// Example:
+//
+//	dispatch.New(
+//	    dispatch.WithHookFilter(dispatch.ExcludeKeys("heartbeat"),
+//	        dispatch.WithSlog(logger),
+//	    ),
+//	)
+func WithHookFilter(m HookMatcher, opts ...Option) Option {
+	return func(r *Router) {
+		gate := func(_ context.Context, source, key string) (string, bool) {
+			return key, m(source, key)
+		}
+		r.hooks.add(mapHooks(collectHooks(opts), gate, gate))
+	}
+}
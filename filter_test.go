@@ -0,0 +1,77 @@
+package dispatch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type HookFilterSuite struct {
+	suite.Suite
+}
+
+func TestHookFilterSuite(t *testing.T) {
+	suite.Run(t, new(HookFilterSuite))
+}
+
+func (s *HookFilterSuite) TestMatchers() {
+	s.Assert().True(MatchKeys("a", "b")("src", "b"))
+	s.Assert().False(MatchKeys("a")("src", "b"))
+	s.Assert().True(ExcludeKeys("a")("src", "b"))
+	s.Assert().False(ExcludeKeys("a")("src", "a"))
+	s.Assert().True(MatchSources("src")("src", "a"))
+	s.Assert().False(MatchSources("src")("other", "a"))
+}
+
+func (s *HookFilterSuite) TestHooksOnlyFireForMatchingMessages() {
+	var keys []string
+
+	r := New(WithHookFilter(ExcludeKeys("heartbeat"),
+		WithOnSuccess(func(ctx context.Context, source, key string, d time.Duration) {
+			keys = append(keys, key)
+		}),
+	))
+	r.AddSource(&testSource{name: "test"})
+	RegisterProc(r, "heartbeat", &testHandler{})
+	RegisterProc(r, "user/created", &testHandler{})
+
+	ctx := context.Background()
+	s.Require().NoError(r.Process(ctx, []byte(`{"type": "heartbeat", "payload": {}}`)))
+	s.Require().NoError(r.Process(ctx, []byte(`{"type": "user/created", "payload": {}}`)))
+
+	s.Assert().Equal([]string{"user/created"}, keys)
+}
+
+func (s *HookFilterSuite) TestFilteredPolicyHookKeepsDefaultFailure() {
+	r := New(WithHookFilter(MatchKeys("ignored"),
+		WithOnNoHandler(func(ctx context.Context, source, key string) error {
+			return nil
+		}),
+	))
+	r.AddSource(&testSource{name: "test"})
+
+	ctx := context.Background()
+	s.Assert().NoError(r.Process(ctx, []byte(`{"type": "ignored", "payload": {}}`)))
+	s.Assert().EqualError(
+		r.Process(ctx, []byte(`{"type": "other", "payload": {}}`)),
+		"no handler for key: other",
+	)
+}
+
+func (s *HookFilterSuite) TestFilteredHookDoesNotOverrideUnfilteredHook() {
+	r := New(
+		WithOnNoSource(func(ctx context.Context, raw []byte) error {
+			return nil
+		}),
+		WithHookFilter(MatchKeys("x"),
+			WithOnNoSource(func(ctx context.Context, raw []byte) error {
+				s.Fail("filtered hook should not run")
+				return nil
+			}),
+		),
+	)
+
+	s.Assert().NoError(r.Process(context.Background(), []byte(`{"unknown": true}`)))
+}
@@ -0,0 +1,151 @@
+// Package fx builds a dispatch.Router from an Uber fx dependency graph.
+//
+// Sources, router options, and handler registrations are contributed to
+// value groups from anywhere in the application, and Module collects them
+// into one configured *dispatch.Router, started and shut down with the fx
+// application:
+//
+//	app := gofx.New(
+//	    fx.Module,
+//	    fx.Source(eventbridge.NewSource),
+//	    fx.RouterOption(func(logger *slog.Logger) dispatch.Option {
+//	        return dispatch.WithSlog(logger)
+//	    }),
+//	    gofx.Provide(NewOrderProc),
+//	    fx.Proc[Order, *OrderProc]("order/created"),
+//	)
+//
+// fx value groups are unordered, so sources are added in no particular
+// order. Give overlapping sources exclusive discriminators or explicit
+// priorities with Router.SetSourcePriority.
+//
+// Applications using another injector, such as google/wire, can call
+// NewRouter from a provider with the slices they assemble themselves.
+package fx
+
+import (
+	"github.com/bjaus/dispatch"
+	gofx "go.uber.org/fx"
+)
+
+// Value group names. Annotate constructors with these groups directly, or
+// use Source, RouterOption, Proc, Func, and Register.
+const (
+	SourcesGroup  = "dispatch.sources"
+	OptionsGroup  = "dispatch.options"
+	HandlersGroup = "dispatch.handlers"
+)
+
+// Registration registers handlers on a router, typically with
+// dispatch.RegisterProc or dispatch.RegisterFunc.
+type Registration func(r *dispatch.Router)
+
+// Module provides a *dispatch.Router built from the sources, options, and
+// registrations in the graph. The router's handlers are started when the
+// application starts, and the router is shut down when it stops.
+var Module = gofx.Module("dispatch",
+	gofx.Provide(provideRouter),
+)
+
+// params are the values Module collects from the graph.
+type params struct {
+	gofx.In
+
+	Sources       []dispatch.Source `group:"dispatch.sources"`
+	Options       []dispatch.Option `group:"dispatch.options"`
+	Registrations []Registration    `group:"dispatch.handlers"`
+}
+
+// NewRouter creates a router with opts, adds sources in order, and applies
+// each registration. It has no fx dependencies, so providers for other
+// injectors can call it.
+func NewRouter(sources []dispatch.Source, opts []dispatch.Option, registrations []Registration) *dispatch.Router {
+	r := dispatch.New(opts...)
+	for _, src := range sources {
+		r.AddSource(src)
+	}
+	for _, register := range registrations {
+		register(r)
+	}
+	return r
+}
+
+func provideRouter(p params, lc gofx.Lifecycle) *dispatch.Router {
+	r := NewRouter(p.Sources, p.Options, p.Registrations)
+	lc.Append(gofx.Hook{
+		OnStart: r.Start,
+		OnStop:  r.Shutdown,
+	})
+	return r
+}
+
+// Source provides a dispatch.Source to Module. constructor is an fx
+// constructor whose first result implements dispatch.Source; its parameters
+// are injected.
+//
+// Example:
+//
+//	fx.Source(func(cfg Config) *sns.Source { return sns.NewSource(cfg.TopicARN) })
+func Source(constructor any) gofx.Option {
+	return gofx.Provide(gofx.Annotate(constructor,
+		gofx.As(new(dispatch.Source)),
+		gofx.ResultTags(`group:"`+SourcesGroup+`"`),
+	))
+}
+
+// RouterOption provides a dispatch.Option to Module. constructor is an fx
+// constructor that returns a dispatch.Option; its parameters are injected.
+//
+// Example:
+//
+//	fx.RouterOption(func(m *statsd.Client) dispatch.Option {
+//	    return dispatch.WithOnSuccess(metrics.Success(m))
+//	})
+func RouterOption(constructor any) gofx.Option {
+	return gofx.Provide(gofx.Annotate(constructor,
+		gofx.ResultTags(`group:"`+OptionsGroup+`"`),
+	))
+}
+
+// Register provides a Registration to Module. constructor is an fx
+// constructor that returns a Registration; its parameters are injected. Use
+// it for registrations that Proc and Func don't cover, such as handler
+// options or several keys at once.
+//
+// Example:
+//
+//	fx.Register(func(p *OrderProc) fx.Registration {
+//	    return func(r *dispatch.Router) {
+//	        dispatch.RegisterProc(r, "order/created", p, dispatch.WithJSONSchema(orderSchema))
+//	    }
+//	})
+func Register(constructor any) gofx.Option {
+	return gofx.Provide(gofx.Annotate(constructor,
+		gofx.ResultTags(`group:"`+HandlersGroup+`"`),
+	))
+}
+
+// Proc registers the P in the graph as the procedure for key, with opts. P
+// must be provided separately, for example with gofx.Provide.
+//
+// Example:
+//
+//	gofx.Provide(NewOrderProc), // returns *OrderProc
+//	fx.Proc[Order, *OrderProc]("order/created"),
+func Proc[T any, P dispatch.Proc[T]](key string, opts ...dispatch.HandlerOption) gofx.Option {
+	return Register(func(p P) Registration {
+		return func(r *dispatch.Router) {
+			dispatch.RegisterProc[T](r, key, p, opts...)
+		}
+	})
+}
+
+// Func registers the F in the graph as the function for key, with opts, as
+// Proc does.
+func Func[T, R any, F dispatch.Func[T, R]](key string, opts ...dispatch.HandlerOption) gofx.Option {
+	return Register(func(f F) Registration {
+		return func(r *dispatch.Router) {
+			dispatch.RegisterFunc[T, R](r, key, f, opts...)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package fx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+	gofx "go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+type payload struct {
+	Value string `json:"value"`
+}
+
+type greeting string
+
+// recordingProc records the payloads it handles and whether it was closed.
+type recordingProc struct {
+	prefix greeting
+	got    []string
+	closed bool
+}
+
+func (p *recordingProc) Run(ctx context.Context, in payload) error {
+	p.got = append(p.got, string(p.prefix)+in.Value)
+	return nil
+}
+
+func (p *recordingProc) Close(ctx context.Context) error {
+	p.closed = true
+	return nil
+}
+
+func newSource() dispatch.Source {
+	return dispatch.SourceFunc("test", dispatch.HasFields("key"), func(raw []byte) (dispatch.Message, error) {
+		return dispatch.Message{Key: "greet", Payload: []byte(`{"value": "world"}`)}, nil
+	})
+}
+
+type ModuleSuite struct {
+	suite.Suite
+}
+
+func TestModuleSuite(t *testing.T) {
+	suite.Run(t, new(ModuleSuite))
+}
+
+func (s *ModuleSuite) TestBuildsRouterFromGraph() {
+	var r *dispatch.Router
+	var proc *recordingProc
+	var parsed bool
+	app := fxtest.New(s.T(),
+		Module,
+		gofx.Supply(greeting("hello, ")),
+		gofx.Provide(func(g greeting) *recordingProc { return &recordingProc{prefix: g} }),
+		Source(newSource),
+		RouterOption(func() dispatch.Option {
+			return dispatch.WithOnParse(func(ctx context.Context, source, key string) context.Context {
+				parsed = true
+				return ctx
+			})
+		}),
+		Proc[payload, *recordingProc]("greet"),
+		gofx.Populate(&r, &proc),
+	)
+	app.RequireStart()
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"key": "greet"}`)))
+	s.Assert().Equal([]string{"hello, world"}, proc.got)
+	s.Assert().True(parsed)
+
+	app.RequireStop()
+	s.Assert().True(proc.closed, "stopping the app shuts down the router")
+}
+
+func (s *ModuleSuite) TestFunc() {
+	var r *dispatch.Router
+	app := fxtest.New(s.T(),
+		Module,
+		Source(newSource),
+		gofx.Supply(dispatch.FuncFunc[payload, string](func(ctx context.Context, in payload) (string, error) {
+			return in.Value, nil
+		})),
+		Func[payload, string, dispatch.FuncFunc[payload, string]]("greet"),
+		gofx.Populate(&r),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	s.Assert().Equal([]string{"greet"}, keys(r))
+}
+
+func (s *ModuleSuite) TestRegister() {
+	var r *dispatch.Router
+	app := fxtest.New(s.T(),
+		Module,
+		Register(func() Registration {
+			return func(r *dispatch.Router) {
+				dispatch.RegisterProcFunc(r, "a", func(ctx context.Context, in payload) error { return nil })
+				dispatch.RegisterProcFunc(r, "b", func(ctx context.Context, in payload) error { return nil })
+			}
+		}),
+		gofx.Populate(&r),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	s.Assert().Equal([]string{"a", "b"}, keys(r))
+}
+
+func (s *ModuleSuite) TestNewRouter() {
+	r := NewRouter([]dispatch.Source{newSource()}, nil, []Registration{func(r *dispatch.Router) {
+		dispatch.RegisterProcFunc(r, "greet", func(ctx context.Context, in payload) error { return nil })
+	}})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"key": "greet"}`)))
+}
+
+func keys(r *dispatch.Router) []string {
+	var out []string
+	for _, h := range r.RoutingTable().Handlers {
+		out = append(out, h.Key)
+	}
+	return out
+}
@@ -0,0 +1,102 @@
+package dispatch
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// guardedHandler is a handler that only runs for payloads its guard accepts.
+type guardedHandler struct {
+	guard   func(View) bool
+	enabled func(context.Context) bool
+	handler invoker
+}
+
+// RegisterProcIf adds a procedure for key that only handles messages whose
+// payload guard accepts, so handlers for different payload shapes under one
+// key can be registered separately instead of branching inside one handler.
+// guard is called with a View of the payload from the inspector the message
+// was matched with, which is built once per message and shared by every
+// guard for the key. When the source passes the raw message through as the
+// payload, the view it was matched against is reused.
+//
+// Guarded handlers are tried in registration order, and the first whose
+// guard returns true handles the message. A handler turned off with
+// WithEnabled is passed over. If none accepts the payload, the handler
+// registered for key with RegisterProc or RegisterFunc handles it, or, if
+// there is none, the message is treated as having no handler. Canaries and
+// shadows apply to the key as a whole.
+//
+// Example:
+//
+//	dispatch.RegisterProcIf(r, "payment/received", dispatch.HasFields("card").Match, &CardPaymentProc{})
+//	dispatch.RegisterProcIf(r, "payment/received", dispatch.HasFields("iban").Match, &BankPaymentProc{})
+func RegisterProcIf[T any](r *Router, key string, guard func(View) bool, p Proc[T], opts ...HandlerOption) {
+	cfg := newHandlerConfig(opts)
+	r.checkKey(key)
+	r.checkRegistry(key, reflect.TypeFor[T]())
+	r.manage(r.guardName(key), p)
+	if _, ok := r.handlerTypes[key]; !ok {
+		r.handlerTypes[key] = handlerType{payload: reflect.TypeFor[T](), handler: reflect.TypeOf(p), schema: cfg.schemaJSON}
+	}
+	r.addGuard(key, guardedHandler{guard: guard, enabled: cfg.enabled, handler: procInvoker(p, cfg)})
+}
+
+// RegisterFuncIf adds a function for key that only handles messages whose
+// payload guard accepts, as RegisterProcIf does.
+func RegisterFuncIf[T, R any](r *Router, key string, guard func(View) bool, f Func[T, R], opts ...HandlerOption) {
+	cfg := newHandlerConfig(opts)
+	r.checkKey(key)
+	r.checkRegistry(key, reflect.TypeFor[T]())
+	r.manage(r.guardName(key), f)
+	if _, ok := r.handlerTypes[key]; !ok {
+		r.handlerTypes[key] = handlerType{payload: reflect.TypeFor[T](), result: reflect.TypeFor[R](), handler: reflect.TypeOf(f), schema: cfg.schemaJSON}
+	}
+	r.addGuard(key, guardedHandler{guard: guard, enabled: cfg.enabled, handler: funcInvoker(f, cfg)})
+}
+
+// guardName names the next guarded handler for key in lifecycle errors.
+func (r *Router) guardName(key string) string {
+	return fmt.Sprintf("%s (guard %d)", key, len(r.guards[key])+1)
+}
+
+func (r *Router) addGuard(key string, g guardedHandler) {
+	// Clip so routers cloned before this call keep their own guards.
+	r.guards[key] = append(slices.Clip(r.guards[key]), g)
+}
+
+// handlerFor returns the handler for p's message: the first enabled guarded
+// handler for its key that accepts the payload, or the key's unguarded
+// handler. guarded reports which; an unguarded handler's WithEnabled check
+// is left to the caller.
+func (r *Router) handlerFor(ctx context.Context, p *parsed) (h invoker, guarded, found bool) {
+	msg := p.msg
+	if guards, ok := r.guards[msg.Key]; ok {
+		if view, ok := p.payloadView(); ok {
+			for _, g := range guards {
+				if g.enabled != nil && !g.enabled(ctx) {
+					continue
+				}
+				if g.guard(view) {
+					return g.handler, true, true
+				}
+			}
+		}
+	}
+	h, found = r.handlers[msg.Key]
+	return h, false, found
+}
+
+// payloadView returns a View of the message's payload from the inspector it
+// was matched with, reusing the matched view when the payload is the raw
+// message itself.
+func (p *parsed) payloadView() (View, bool) {
+	payload := p.msg.Payload
+	if p.view != nil && len(payload) > 0 && len(payload) == len(p.raw) && &payload[0] == &p.raw[0] {
+		return p.view, true
+	}
+	view, err := p.insp.Inspect(payload)
+	return view, err == nil
+}
@@ -0,0 +1,180 @@
+package dispatch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type GuardSuite struct {
+	suite.Suite
+	router *Router
+	called string
+}
+
+func TestGuardSuite(t *testing.T) {
+	suite.Run(t, new(GuardSuite))
+}
+
+func (s *GuardSuite) SetupTest() {
+	s.called = ""
+	s.router = New()
+	s.router.AddSource(&testSource{name: "test"})
+}
+
+func (s *GuardSuite) handler(name string) ProcFunc[testPayload] {
+	return func(ctx context.Context, p testPayload) error {
+		s.called = name
+		return nil
+	}
+}
+
+func (s *GuardSuite) process(payload string) error {
+	return s.router.Process(context.Background(), []byte(`{"type": "payment", "payload": `+payload+`}`))
+}
+
+func (s *GuardSuite) TestSelectsByPayload() {
+	RegisterProcIf(s.router, "payment", HasFields("card").Match, s.handler("card"))
+	RegisterProcIf(s.router, "payment", HasFields("iban").Match, s.handler("bank"))
+
+	s.Require().NoError(s.process(`{"card": "4242"}`))
+	s.Assert().Equal("card", s.called)
+
+	s.Require().NoError(s.process(`{"iban": "DE89"}`))
+	s.Assert().Equal("bank", s.called)
+}
+
+func (s *GuardSuite) TestFirstMatchWins() {
+	RegisterProcIf(s.router, "payment", HasFields("card").Match, s.handler("first"))
+	RegisterProcIf(s.router, "payment", HasFields("card").Match, s.handler("second"))
+
+	s.Require().NoError(s.process(`{"card": "4242"}`))
+	s.Assert().Equal("first", s.called)
+}
+
+func (s *GuardSuite) TestFallsBackToUnguardedHandler() {
+	RegisterProcIf(s.router, "payment", HasFields("card").Match, s.handler("card"))
+	RegisterProc(s.router, "payment", s.handler("fallback"))
+
+	s.Require().NoError(s.process(`{"cash": 10}`))
+	s.Assert().Equal("fallback", s.called)
+
+	s.Require().NoError(s.process(`{"card": "4242"}`))
+	s.Assert().Equal("card", s.called, "guarded handlers are tried first")
+}
+
+func (s *GuardSuite) TestNoMatchingGuard() {
+	RegisterProcIf(s.router, "payment", HasFields("card").Match, s.handler("card"))
+
+	s.Assert().ErrorIs(s.process(`{"cash": 10}`), ErrNoHandler)
+	s.Assert().Empty(s.called)
+}
+
+func (s *GuardSuite) TestDisabledGuardIsPassedOver() {
+	off := WithEnabled(func(ctx context.Context) bool { return false })
+	RegisterProcIf(s.router, "payment", HasFields("card").Match, s.handler("off"), off)
+	RegisterProcIf(s.router, "payment", HasFields("card").Match, s.handler("on"))
+
+	s.Require().NoError(s.process(`{"card": "4242"}`))
+	s.Assert().Equal("on", s.called)
+}
+
+func (s *GuardSuite) TestDisabledFallbackWithEnabledGuard() {
+	off := WithEnabled(func(ctx context.Context) bool { return false })
+	RegisterProc(s.router, "payment", s.handler("fallback"), off)
+	RegisterProcIf(s.router, "payment", HasFields("card").Match, s.handler("card"))
+
+	s.Require().NoError(s.process(`{"card": "4242"}`))
+	s.Assert().Equal("card", s.called)
+
+	s.called = ""
+	s.Require().NoError(s.process(`{"cash": 10}`))
+	s.Assert().Empty(s.called, "the disabled fallback is skipped")
+}
+
+func (s *GuardSuite) TestUsesMatchedInspector() {
+	inspector := &countingInspector{}
+	r := New(WithInspector(inspector))
+	r.AddSource(&testSource{name: "envelope"})
+	r.AddSource(SourceFunc("raw", HasFields("card"), func(raw []byte) (Message, error) {
+		return Message{Key: "payment", Payload: raw}, nil
+	}))
+	RegisterProcIf(r, "payment", HasFields("card").Match, s.handler("card"))
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"card": "4242"}`)))
+	s.Assert().Equal("card", s.called)
+	s.Assert().Equal(1, inspector.count, "the matched view is reused for a raw payload")
+
+	s.called = ""
+	inspector.reset()
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "payment", "payload": {"card": "4242"}}`)))
+	s.Assert().Equal("card", s.called)
+	s.Assert().Equal(2, inspector.count, "the payload is inspected with the router's inspector")
+}
+
+func (s *GuardSuite) TestFuncIf() {
+	replier := &keysReplier{}
+	r := New()
+	r.AddSource(SourceFunc("reply", HasFields("value"), func(raw []byte) (Message, error) {
+		return Message{Key: "double", Payload: raw, Replier: replier}, nil
+	}))
+	RegisterFuncIf(r, "double", FieldEquals("value", "two").Match, FuncFunc[testPayload, int](
+		func(ctx context.Context, p testPayload) (int, error) { return 4, nil },
+	))
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"value": "two"}`)))
+	s.Assert().JSONEq(`4`, string(replier.result))
+}
+
+func (s *GuardSuite) TestCheckCoverage() {
+	RegisterProcIf(s.router, "payment", HasFields("card").Match, s.handler("card"))
+
+	s.Assert().NoError(CheckCoverage(s.router, map[string][]byte{
+		"card": []byte(`{"type": "payment", "payload": {"card": "4242"}}`),
+	}))
+	s.Assert().Error(CheckCoverage(s.router, map[string][]byte{
+		"cash": []byte(`{"type": "payment", "payload": {"cash": 10}}`),
+	}), "no guard accepts the sample")
+	s.Assert().Error(CheckCoverage(s.router, nil), "the guarded key has no sample")
+}
+
+func (s *GuardSuite) TestRoutingTable() {
+	RegisterProcIf(s.router, "payment", HasFields("card").Match, s.handler("card"))
+
+	handlers := s.router.RoutingTable().Handlers
+	s.Require().Len(handlers, 1)
+	s.Assert().Equal("payment", handlers[0].Key)
+}
+
+func (s *GuardSuite) TestCloneKeepsGuardsSeparate() {
+	RegisterProcIf(s.router, "payment", HasFields("card").Match, s.handler("card"))
+	c := s.router.Clone()
+	RegisterProcIf(s.router, "payment", HasFields("iban").Match, s.handler("bank"))
+
+	err := c.Process(context.Background(), []byte(`{"type": "payment", "payload": {"iban": "DE89"}}`))
+	s.Assert().ErrorIs(err, ErrNoHandler)
+}
+
+// closingProc is a Proc that records being closed.
+type closingProc struct {
+	closed *[]string
+	name   string
+}
+
+func (p closingProc) Run(ctx context.Context, payload testPayload) error { return nil }
+
+func (p closingProc) Close(ctx context.Context) error {
+	*p.closed = append(*p.closed, p.name)
+	return nil
+}
+
+func (s *GuardSuite) TestShutdownClosesEveryGuardedHandler() {
+	var closed []string
+	RegisterProcIf(s.router, "payment", HasFields("card").Match, closingProc{closed: &closed, name: "card"})
+	RegisterProcIf(s.router, "payment", HasFields("iban").Match, closingProc{closed: &closed, name: "bank"})
+	RegisterProc(s.router, "payment", closingProc{closed: &closed, name: "fallback"})
+
+	s.Require().NoError(s.router.Shutdown(context.Background()))
+	s.Assert().ElementsMatch([]string{"card", "bank", "fallback"}, closed)
+}
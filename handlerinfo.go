@@ -0,0 +1,105 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// HandlerInfo describes the handler registered for a routing key, so
+// generic tooling such as schema exporters or authorization checks can
+// reason about the handler it observes or wraps.
+type HandlerInfo struct {
+	Key string
+	// Payload is the type the payload is unmarshaled into.
+	Payload reflect.Type
+	// Result is the result type of a func, and nil for procs.
+	Result reflect.Type
+	// Handler is the type of the registered handler.
+	Handler reflect.Type
+	// Schema is the schema given to WithJSONSchema, if any.
+	Schema json.RawMessage
+	// Gated reports whether the handler was registered WithEnabled.
+	Gated bool
+}
+
+// IsFunc reports whether the handler is a Func, which returns a result,
+// rather than a Proc.
+func (i HandlerInfo) IsFunc() bool {
+	return i.Result != nil
+}
+
+type handlerInfoKey struct{}
+
+// HandlerInfoFromContext returns the HandlerInfo for the handler the message
+// being processed was dispatched to. It is available to hooks from
+// OnDispatch onward and to handlers, including wrappers around them.
+//
+// Example:
+//
+//	dispatch.WithOnDispatch(func(ctx context.Context, source, key string) {
+//	    if info, ok := dispatch.HandlerInfoFromContext(ctx); ok && info.IsFunc() {
+//	        metrics.Incr("requests", "key:"+key)
+//	    }
+//	})
+func HandlerInfoFromContext(ctx context.Context) (HandlerInfo, bool) {
+	info, ok := ctx.Value(handlerInfoKey{}).(HandlerInfo)
+	return info, ok
+}
+
+// withHandlerInfo returns a context carrying the HandlerInfo for key, if a
+// handler is registered for it.
+func (r *Router) withHandlerInfo(ctx context.Context, key string) context.Context {
+	info, ok := r.HandlerInfo(key)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, handlerInfoKey{}, info)
+}
+
+// HandlerInfo returns the HandlerInfo for the handler registered for key.
+// It reports false if no handler is registered for key.
+func (r *Router) HandlerInfo(key string) (HandlerInfo, bool) {
+	ht, ok := r.handlerTypes[key]
+	if !ok {
+		return HandlerInfo{}, false
+	}
+	_, gated := r.enabled[key]
+	return HandlerInfo{
+		Key:     key,
+		Payload: ht.payload,
+		Result:  ht.result,
+		Handler: ht.handler,
+		Schema:  ht.schema,
+		Gated:   gated,
+	}, true
+}
+
+// Handlers returns the HandlerInfo for every registered handler, sorted by
+// key.
+//
+// Example:
+//
+//	for _, h := range r.Handlers() {
+//	    schemas[h.Key] = jsonschema.Reflect(reflect.New(h.Payload).Interface())
+//	}
+func (r *Router) Handlers() []HandlerInfo {
+	out := make([]HandlerInfo, 0, len(r.handlerTypes))
+	for _, key := range slices.Sorted(maps.Keys(r.handlerTypes)) {
+		info, _ := r.HandlerInfo(key)
+		out = append(out, info)
+	}
+	return out
+}
+
+// HandlerInfo returns the HandlerInfo for key. See Router.HandlerInfo.
+func (c *CompiledRouter) HandlerInfo(key string) (HandlerInfo, bool) {
+	return c.r.HandlerInfo(key)
+}
+
+// Handlers returns the compiled router's handlers. See Router.Handlers.
+func (c *CompiledRouter) Handlers() []HandlerInfo {
+	return c.r.Handlers()
+}
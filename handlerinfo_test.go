@@ -0,0 +1,93 @@
+package dispatch
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerInfoSuite struct {
+	suite.Suite
+	router *Router
+}
+
+func TestHandlerInfoSuite(t *testing.T) {
+	suite.Run(t, new(HandlerInfoSuite))
+}
+
+func (s *HandlerInfoSuite) SetupTest() {
+	s.router = New()
+	s.router.AddSource(&testSource{name: "test"})
+}
+
+func (s *HandlerInfoSuite) TestProc() {
+	RegisterProc(s.router, "test", &testHandler{}, WithJSONSchema([]byte(`{"type": "object"}`)))
+
+	info, ok := s.router.HandlerInfo("test")
+	s.Require().True(ok)
+	s.Assert().Equal("test", info.Key)
+	s.Assert().Equal(reflect.TypeFor[testPayload](), info.Payload)
+	s.Assert().Nil(info.Result)
+	s.Assert().False(info.IsFunc())
+	s.Assert().Equal(reflect.TypeFor[*testHandler](), info.Handler)
+	s.Assert().JSONEq(`{"type": "object"}`, string(info.Schema))
+	s.Assert().False(info.Gated)
+}
+
+func (s *HandlerInfoSuite) TestFunc() {
+	RegisterFuncFunc(s.router, "test", func(ctx context.Context, p testPayload) (int, error) { return 1, nil },
+		WithEnabled(func(ctx context.Context) bool { return true }))
+
+	info, ok := s.router.HandlerInfo("test")
+	s.Require().True(ok)
+	s.Assert().Equal(reflect.TypeFor[int](), info.Result)
+	s.Assert().True(info.IsFunc())
+	s.Assert().True(info.Gated)
+}
+
+func (s *HandlerInfoSuite) TestUnknownKey() {
+	_, ok := s.router.HandlerInfo("missing")
+	s.Assert().False(ok)
+}
+
+func (s *HandlerInfoSuite) TestHandlersSortedByKey() {
+	RegisterProc(s.router, "b", &testHandler{})
+	RegisterProc(s.router, "a", &testHandler{})
+
+	var keys []string
+	for _, h := range s.router.Build().Handlers() {
+		keys = append(keys, h.Key)
+	}
+	s.Assert().Equal([]string{"a", "b"}, keys)
+}
+
+func (s *HandlerInfoSuite) TestFromContext() {
+	var fromHook, fromHandler HandlerInfo
+	r := New(WithOnDispatch(func(ctx context.Context, source, key string) {
+		fromHook, _ = HandlerInfoFromContext(ctx)
+	}))
+	r.AddSource(&testSource{name: "test"})
+	RegisterProcFunc(r, "test", func(ctx context.Context, p testPayload) error {
+		fromHandler, _ = HandlerInfoFromContext(ctx)
+		return nil
+	})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`)))
+	s.Assert().Equal("test", fromHook.Key)
+	s.Assert().Equal(reflect.TypeFor[testPayload](), fromHandler.Payload)
+}
+
+func (s *HandlerInfoSuite) TestNotInContextBeforeDispatch() {
+	var found bool
+	r := New(WithOnParse(func(ctx context.Context, source, key string) context.Context {
+		_, found = HandlerInfoFromContext(ctx)
+		return ctx
+	}))
+	r.AddSource(&testSource{name: "test"})
+	RegisterProc(r, "test", &testHandler{})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`)))
+	s.Assert().False(found)
+}
@@ -0,0 +1,79 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Pinger is an optional interface for sources and replier factories that can
+// check their own health, such as a consumer's connection or credentials.
+// Router.Healthy calls Ping on every registered Pinger.
+//
+// Example:
+//
+//	func (s *kafkaSource) Ping(ctx context.Context) error {
+//	    return s.client.Ping(ctx)
+//	}
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Healthy reports whether the router can process messages. It returns
+// ErrShutdown after Shutdown, and otherwise pings every source and the
+// replier factory that implement Pinger, concurrently. Failures are combined
+// with errors.Join, each prefixed with the source name.
+//
+// Use it for readiness and liveness probes:
+//
+//	http.HandleFunc("/healthz", func(w http.ResponseWriter, req *http.Request) {
+//	    if err := r.Healthy(req.Context()); err != nil {
+//	        http.Error(w, err.Error(), http.StatusServiceUnavailable)
+//	    }
+//	})
+func (r *Router) Healthy(ctx context.Context) error {
+	r.life.mu.RLock()
+	closed := r.life.closed
+	r.life.mu.RUnlock()
+	if closed {
+		return ErrShutdown
+	}
+
+	type check struct {
+		name string
+		ping Pinger
+	}
+	var checks []check
+	for _, src := range r.allSources() {
+		if p, ok := src.(Pinger); ok {
+			checks = append(checks, check{name: "source " + src.Name(), ping: p})
+		}
+	}
+	if p, ok := r.replierFactory.(Pinger); ok {
+		checks = append(checks, check{name: "replier factory", ping: p})
+	}
+
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.ping.Ping(ctx); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", c.name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Ping forwards to the wrapped source so decorating a Pinger keeps its
+// health check.
+func (s *hookedSource) Ping(ctx context.Context) error {
+	if p, ok := s.Source.(Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type pingSource struct {
+	testSource
+	err error
+}
+
+func (s *pingSource) Ping(ctx context.Context) error { return s.err }
+
+type pingFactory struct {
+	err error
+}
+
+func (f *pingFactory) NewReplier(ctx context.Context, msg Message) (Replier, error) {
+	return nil, nil
+}
+
+func (f *pingFactory) Ping(ctx context.Context) error { return f.err }
+
+type HealthySuite struct {
+	suite.Suite
+}
+
+func TestHealthySuite(t *testing.T) {
+	suite.Run(t, new(HealthySuite))
+}
+
+func (s *HealthySuite) TestHealthyWithoutPingers() {
+	r := New()
+	r.AddSource(&testSource{name: "test"})
+
+	s.Assert().NoError(r.Healthy(context.Background()))
+}
+
+func (s *HealthySuite) TestHealthyWhenAllPingersPass() {
+	r := New(WithReplierFactory(&pingFactory{}))
+	r.AddSource(&pingSource{testSource: testSource{name: "a"}})
+	r.AddGroup(JSONInspector(), &pingSource{testSource: testSource{name: "b"}})
+
+	s.Assert().NoError(r.Healthy(context.Background()))
+}
+
+func (s *HealthySuite) TestJoinsFailures() {
+	errA := errors.New("connection refused")
+	errFactory := errors.New("expired credentials")
+	r := New(WithReplierFactory(&pingFactory{err: errFactory}))
+	r.AddSource(&pingSource{testSource: testSource{name: "a"}, err: errA})
+	r.AddSource(&pingSource{testSource: testSource{name: "b"}})
+
+	err := r.Healthy(context.Background())
+
+	s.Assert().ErrorIs(err, errA)
+	s.Assert().ErrorIs(err, errFactory)
+	s.Assert().EqualError(err, "source a: connection refused\nreplier factory: expired credentials")
+}
+
+func (s *HealthySuite) TestPingsThroughSourceHooks() {
+	errA := errors.New("down")
+	r := New()
+	r.AddSource(WithSourceHooks(&pingSource{testSource: testSource{name: "a"}, err: errA}, SourceHooks{}))
+	r.AddSource(WithSourceHooks(&testSource{name: "b"}, SourceHooks{}))
+
+	s.Assert().EqualError(r.Healthy(context.Background()), "source a: down")
+}
+
+func (s *HealthySuite) TestUnhealthyAfterShutdown() {
+	r := New()
+	s.Require().NoError(r.Shutdown(context.Background()))
+
+	s.Assert().ErrorIs(r.Healthy(context.Background()), ErrShutdown)
+}
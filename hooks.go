@@ -2,6 +2,8 @@ package dispatch
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"time"
 )
 
@@ -19,6 +21,54 @@ type OnSuccessFunc func(ctx context.Context, source, key string, duration time.D
 // OnFailureFunc is called after the handler fails.
 type OnFailureFunc func(ctx context.Context, source, key string, err error, duration time.Duration)
 
+// OnTimingsFunc is called once a parsed message has been fully handled, with
+// a breakdown of where the time was spent.
+type OnTimingsFunc func(ctx context.Context, source, key string, t Timings)
+
+// Timings breaks down the time spent processing a message by stage. Stages
+// that did not run (for example, Reply when the message has no Replier) are
+// zero.
+type Timings struct {
+	// Match is the time spent evaluating source discriminators.
+	Match time.Duration
+
+	// Parse is the time spent in the matched source's Parse method.
+	Parse time.Duration
+
+	// Unmarshal is the time spent unmarshaling the payload.
+	Unmarshal time.Duration
+
+	// Validate is the time spent validating the payload.
+	Validate time.Duration
+
+	// Handle is the time spent in the handler's Run or Call method,
+	// including marshaling a Func result.
+	Handle time.Duration
+
+	// Reply is the time spent in Replier.Reply or Replier.Fail.
+	Reply time.Duration
+}
+
+// Total returns the sum of all stage durations.
+func (t Timings) Total() time.Duration {
+	return t.Match + t.Parse + t.Unmarshal + t.Validate + t.Handle + t.Reply
+}
+
+// OnReplyFunc is called with a successful result before it is sent with
+// Replier.Reply, and returns the result to send instead. Use it to wrap or
+// reshape results, for example in an envelope with status and request ID.
+// Returning an error sends that error with Replier.Fail instead.
+type OnReplyFunc func(ctx context.Context, source, key string, result json.RawMessage) (json.RawMessage, error)
+
+// ErrHookAbstain is returned by a policy hook (WithOnNoSource,
+// WithOnParseError, WithOnNoHandler, WithOnUnmarshalError,
+// WithOnValidationError, WithOnOversize, or WithOnError) that observes a
+// message without deciding its outcome. The router treats the hook as if it
+// had not run, so other hooks or the default behavior decide whether the
+// message is skipped or failed. Hooks registered with WithHookFilter abstain
+// for messages they don't match.
+var ErrHookAbstain = errors.New("hook abstained")
+
 // OnNoSourceFunc is called when no source can parse the message.
 // Return nil to skip the message, return an error to fail.
 type OnNoSourceFunc func(ctx context.Context, raw []byte) error
@@ -45,11 +95,227 @@ type hooks struct {
 	onDispatch        []OnDispatchFunc
 	onSuccess         []OnSuccessFunc
 	onFailure         []OnFailureFunc
+	onTimings         []OnTimingsFunc
+	onExpired         []OnExpiredFunc
+	onDuplicate       []OnDuplicateFunc
+	onPause           []OnPauseFunc
+	onResume          []OnResumeFunc
+	onDisabled        []OnDisabledFunc
+	onShadow          []OnShadowFunc
+	onReply           []OnReplyFunc
 	onNoSource        []OnNoSourceFunc
 	onParseError      []OnParseErrorFunc
 	onNoHandler       []OnNoHandlerFunc
 	onUnmarshalError  []OnUnmarshalErrorFunc
 	onValidationError []OnValidationErrorFunc
+	onOversize        []OnOversizeFunc
+
+	// onError holds WithOnError hooks for handler and reply errors; earlier
+	// stages are registered on the stage-specific hooks.
+	onError []OnErrorFunc
+
+	// onSkip observes messages skipped by policy hooks. It is internal
+	// because registering it must not change skip/fail behavior.
+	onSkip []func(ctx context.Context, source, key string, cause error)
+
+	// onReject observes messages failed before a handler ran, such as a
+	// parse error or missing handler that no policy hook skipped.
+	onReject []func(ctx context.Context, source, key string, err error)
+}
+
+// hookGate decides whether a wrapped hook runs for a message, and returns the
+// key to call it with. Source or key is empty when not known.
+type hookGate func(ctx context.Context, source, key string) (string, bool)
+
+// mapHooks returns h with every hook wrapped by a gate: observe for hooks
+// that only observe a message, and decide for the rest, which change its
+// outcome (error hooks and OnReply) or report worker state (OnPause and
+// OnResume). A hook whose gate refuses it does nothing: error hooks return
+// ErrHookAbstain, OnParse returns its context, and OnReply its result.
+func mapHooks(h hooks, observe, decide hookGate) hooks {
+	var m hooks
+	for _, fn := range h.onParse {
+		m.onParse = append(m.onParse, func(ctx context.Context, source, key string) context.Context {
+			if key, ok := observe(ctx, source, key); ok {
+				return fn(ctx, source, key)
+			}
+			return ctx
+		})
+	}
+	for _, fn := range h.onDispatch {
+		m.onDispatch = append(m.onDispatch, func(ctx context.Context, source, key string) {
+			if key, ok := observe(ctx, source, key); ok {
+				fn(ctx, source, key)
+			}
+		})
+	}
+	for _, fn := range h.onSuccess {
+		m.onSuccess = append(m.onSuccess, func(ctx context.Context, source, key string, d time.Duration) {
+			if key, ok := observe(ctx, source, key); ok {
+				fn(ctx, source, key, d)
+			}
+		})
+	}
+	for _, fn := range h.onFailure {
+		m.onFailure = append(m.onFailure, func(ctx context.Context, source, key string, err error, d time.Duration) {
+			if key, ok := observe(ctx, source, key); ok {
+				fn(ctx, source, key, err, d)
+			}
+		})
+	}
+	for _, fn := range h.onTimings {
+		m.onTimings = append(m.onTimings, func(ctx context.Context, source, key string, t Timings) {
+			if key, ok := observe(ctx, source, key); ok {
+				fn(ctx, source, key, t)
+			}
+		})
+	}
+	for _, fn := range h.onExpired {
+		m.onExpired = append(m.onExpired, func(ctx context.Context, source, key string, age time.Duration) {
+			if key, ok := observe(ctx, source, key); ok {
+				fn(ctx, source, key, age)
+			}
+		})
+	}
+	for _, fn := range h.onDuplicate {
+		m.onDuplicate = append(m.onDuplicate, func(ctx context.Context, source, key string) {
+			if key, ok := observe(ctx, source, key); ok {
+				fn(ctx, source, key)
+			}
+		})
+	}
+	for _, fn := range h.onPause {
+		m.onPause = append(m.onPause, func(ctx context.Context, source, key string, d time.Duration) {
+			if key, ok := decide(ctx, source, key); ok {
+				fn(ctx, source, key, d)
+			}
+		})
+	}
+	for _, fn := range h.onResume {
+		m.onResume = append(m.onResume, func(ctx context.Context) {
+			if _, ok := decide(ctx, "", ""); ok {
+				fn(ctx)
+			}
+		})
+	}
+	for _, fn := range h.onDisabled {
+		m.onDisabled = append(m.onDisabled, func(ctx context.Context, source, key string) {
+			if key, ok := observe(ctx, source, key); ok {
+				fn(ctx, source, key)
+			}
+		})
+	}
+	for _, fn := range h.onShadow {
+		m.onShadow = append(m.onShadow, func(ctx context.Context, source, key string, primary, shadow ShadowResult) {
+			if key, ok := observe(ctx, source, key); ok {
+				fn(ctx, source, key, primary, shadow)
+			}
+		})
+	}
+	for _, fn := range h.onReply {
+		m.onReply = append(m.onReply, func(ctx context.Context, source, key string, result json.RawMessage) (json.RawMessage, error) {
+			if key, ok := decide(ctx, source, key); ok {
+				return fn(ctx, source, key, result)
+			}
+			return result, nil
+		})
+	}
+	for _, fn := range h.onNoSource {
+		m.onNoSource = append(m.onNoSource, func(ctx context.Context, raw []byte) error {
+			if _, ok := decide(ctx, "", ""); ok {
+				return fn(ctx, raw)
+			}
+			return ErrHookAbstain
+		})
+	}
+	for _, fn := range h.onParseError {
+		m.onParseError = append(m.onParseError, func(ctx context.Context, source string, err error) error {
+			if _, ok := decide(ctx, source, ""); ok {
+				return fn(ctx, source, err)
+			}
+			return ErrHookAbstain
+		})
+	}
+	for _, fn := range h.onNoHandler {
+		m.onNoHandler = append(m.onNoHandler, func(ctx context.Context, source, key string) error {
+			if key, ok := decide(ctx, source, key); ok {
+				return fn(ctx, source, key)
+			}
+			return ErrHookAbstain
+		})
+	}
+	for _, fn := range h.onUnmarshalError {
+		m.onUnmarshalError = append(m.onUnmarshalError, func(ctx context.Context, source, key string, err error) error {
+			if key, ok := decide(ctx, source, key); ok {
+				return fn(ctx, source, key, err)
+			}
+			return ErrHookAbstain
+		})
+	}
+	for _, fn := range h.onValidationError {
+		m.onValidationError = append(m.onValidationError, func(ctx context.Context, source, key string, err error) error {
+			if key, ok := decide(ctx, source, key); ok {
+				return fn(ctx, source, key, err)
+			}
+			return ErrHookAbstain
+		})
+	}
+	for _, fn := range h.onOversize {
+		m.onOversize = append(m.onOversize, func(ctx context.Context, source, key string, size int) error {
+			if key, ok := decide(ctx, source, key); ok {
+				return fn(ctx, source, key, size)
+			}
+			return ErrHookAbstain
+		})
+	}
+	for _, fn := range h.onError {
+		m.onError = append(m.onError, func(ctx context.Context, stage Stage, source, key string, err error) error {
+			if key, ok := decide(ctx, source, key); ok {
+				return fn(ctx, stage, source, key, err)
+			}
+			return ErrHookAbstain
+		})
+	}
+	for _, fn := range h.onSkip {
+		m.onSkip = append(m.onSkip, func(ctx context.Context, source, key string, cause error) {
+			if key, ok := observe(ctx, source, key); ok {
+				fn(ctx, source, key, cause)
+			}
+		})
+	}
+	for _, fn := range h.onReject {
+		m.onReject = append(m.onReject, func(ctx context.Context, source, key string, err error) {
+			if key, ok := observe(ctx, source, key); ok {
+				fn(ctx, source, key, err)
+			}
+		})
+	}
+	return m
+}
+
+// add appends the hooks in o to h.
+func (h *hooks) add(o hooks) {
+	h.onParse = append(h.onParse, o.onParse...)
+	h.onDispatch = append(h.onDispatch, o.onDispatch...)
+	h.onSuccess = append(h.onSuccess, o.onSuccess...)
+	h.onFailure = append(h.onFailure, o.onFailure...)
+	h.onTimings = append(h.onTimings, o.onTimings...)
+	h.onExpired = append(h.onExpired, o.onExpired...)
+	h.onDuplicate = append(h.onDuplicate, o.onDuplicate...)
+	h.onPause = append(h.onPause, o.onPause...)
+	h.onResume = append(h.onResume, o.onResume...)
+	h.onDisabled = append(h.onDisabled, o.onDisabled...)
+	h.onShadow = append(h.onShadow, o.onShadow...)
+	h.onReply = append(h.onReply, o.onReply...)
+	h.onNoSource = append(h.onNoSource, o.onNoSource...)
+	h.onParseError = append(h.onParseError, o.onParseError...)
+	h.onNoHandler = append(h.onNoHandler, o.onNoHandler...)
+	h.onUnmarshalError = append(h.onUnmarshalError, o.onUnmarshalError...)
+	h.onValidationError = append(h.onValidationError, o.onValidationError...)
+	h.onOversize = append(h.onOversize, o.onOversize...)
+	h.onError = append(h.onError, o.onError...)
+	h.onSkip = append(h.onSkip, o.onSkip...)
+	h.onReject = append(h.onReject, o.onReject...)
 }
 
 // Option configures Router behavior.
@@ -112,6 +378,44 @@ func WithOnFailure(fn OnFailureFunc) Option {
 	}
 }
 
+// WithOnTimings adds a hook that reports per-stage durations once a parsed
+// message has been fully handled, including when no handler is found or the
+// payload fails to unmarshal or validate. Use it to see whether latency comes
+// from envelope and JSON work or from business logic.
+// Multiple hooks are called in order.
+//
+// Example:
+//
+//	dispatch.WithOnTimings(func(ctx context.Context, source, key string, t dispatch.Timings) {
+//	    metrics.Timing("dispatch.parse", t.Parse, "source:"+source)
+//	    metrics.Timing("dispatch.handle", t.Handle, "source:"+source)
+//	})
+func WithOnTimings(fn OnTimingsFunc) Option {
+	return func(r *Router) {
+		r.hooks.onTimings = append(r.hooks.onTimings, fn)
+	}
+}
+
+// WithOnReply adds a hook that transforms successful results before they
+// are sent with Replier.Reply. Multiple hooks are chained in order, each
+// receiving the previous hook's result. It has no effect on messages without
+// a Replier.
+//
+// Example:
+//
+//	dispatch.WithOnReply(func(ctx context.Context, source, key string, result json.RawMessage) (json.RawMessage, error) {
+//	    return json.Marshal(map[string]any{
+//	        "status":     "ok",
+//	        "request_id": dispatch.CorrelationID(ctx),
+//	        "data":       result,
+//	    })
+//	})
+func WithOnReply(fn OnReplyFunc) Option {
+	return func(r *Router) {
+		r.hooks.onReply = append(r.hooks.onReply, fn)
+	}
+}
+
 // WithOnNoSource adds a hook called when no source can parse the message.
 // Return nil to skip, return an error to fail.
 // Multiple hooks are called in order; first error wins.
@@ -192,6 +496,91 @@ func WithOnValidationError(fn OnValidationErrorFunc) Option {
 	}
 }
 
+// HookErrorPolicy controls how errors from multiple error hooks are combined
+// when global and source hooks both return an error for the same message.
+type HookErrorPolicy int
+
+const (
+	// FirstHookError returns the first error, in hook call order. This is
+	// the default.
+	FirstHookError HookErrorPolicy = iota
+
+	// JoinHookErrors returns all errors combined with errors.Join, so
+	// errors.Is and errors.As see every hook's error.
+	JoinHookErrors
+)
+
+// WithHookErrorPolicy sets how errors from the OnParseError, OnNoHandler,
+// OnUnmarshalError, OnValidationError, OnOversize, and OnError hooks are
+// combined. OnNoSource hooks always stop at the first error.
+//
+// Example:
+//
+//	dispatch.New(dispatch.WithHookErrorPolicy(dispatch.JoinHookErrors))
+func WithHookErrorPolicy(p HookErrorPolicy) Option {
+	return func(r *Router) {
+		r.hookErrors = p
+	}
+}
+
+// HookKind identifies a hook type that has both global and source variants.
+// Kinds are bit flags and can be combined with |.
+type HookKind uint16
+
+const (
+	HookParse HookKind = 1 << iota
+	HookDispatch
+	HookSuccess
+	HookFailure
+	HookParseError
+	HookNoHandler
+	HookUnmarshalError
+	HookValidationError
+	HookReply
+
+	// AllHooks selects every hook kind.
+	AllHooks = HookParse | HookDispatch | HookSuccess | HookFailure |
+		HookParseError | HookNoHandler | HookUnmarshalError | HookValidationError |
+		HookReply
+)
+
+// HookOrder controls whether global or source hooks run first.
+type HookOrder int
+
+const (
+	// GlobalHooksFirst runs global hooks before source hooks. This is the
+	// default.
+	GlobalHooksFirst HookOrder = iota
+
+	// SourceHooksFirst runs source hooks before global hooks.
+	SourceHooksFirst
+)
+
+// WithHookOrder sets whether source hooks run before or after global hooks
+// for the given hook kinds. Use it when a source hook establishes context
+// that global hooks need to see. For error hooks, the order also decides
+// which error wins under FirstHookError.
+//
+// Example:
+//
+//	// Source OnParse enriches ctx with trace fields before global logging runs
+//	dispatch.WithHookOrder(dispatch.SourceHooksFirst, dispatch.HookParse)
+func WithHookOrder(order HookOrder, kinds HookKind) Option {
+	return func(r *Router) {
+		if order == SourceHooksFirst {
+			r.sourceFirst |= kinds
+		} else {
+			r.sourceFirst &^= kinds
+		}
+	}
+}
+
+// sourceHooksFirst reports whether source hooks of the given kind run
+// before global hooks.
+func (r *Router) sourceHooksFirst(kind HookKind) bool {
+	return r.sourceFirst&kind != 0
+}
+
 // OnParseHook is an optional interface that sources can implement to add
 // source-specific context enrichment. Called after global OnParse hooks.
 type OnParseHook interface {
@@ -216,6 +605,13 @@ type OnFailureHook interface {
 	OnFailure(ctx context.Context, key string, err error, duration time.Duration)
 }
 
+// OnParseErrorHook is an optional interface that sources can implement to add
+// source-specific behavior when Parse fails. Called after global hooks;
+// if either returns an error, that error is used.
+type OnParseErrorHook interface {
+	OnParseError(ctx context.Context, err error) error
+}
+
 // OnNoHandlerHook is an optional interface that sources can implement to add
 // source-specific behavior when no handler is found. Called after global hooks;
 // if either returns an error, that error is used.
@@ -236,3 +632,10 @@ type OnUnmarshalErrorHook interface {
 type OnValidationErrorHook interface {
 	OnValidationError(ctx context.Context, key string, err error) error
 }
+
+// OnReplyHook is an optional interface that sources can implement to reshape
+// results for their transport. Called after global OnReply hooks, with their
+// result; an error sends Replier.Fail instead of Replier.Reply.
+type OnReplyHook interface {
+	OnReply(ctx context.Context, key string, result json.RawMessage) (json.RawMessage, error)
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"reflect"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -20,10 +22,12 @@ type sourceWithHooks struct {
 	onDispatchCalled        bool
 	onSuccessCalled         bool
 	onFailureCalled         bool
+	onParseErrorCalled      bool
 	onNoHandlerCalled       bool
 	onUnmarshalErrorCalled  bool
 	onValidationErrorCalled bool
 
+	onParseErrorErr      error
 	onNoHandlerErr       error
 	onUnmarshalErrorErr  error
 	onValidationErrorErr error
@@ -35,18 +39,18 @@ func (s *sourceWithHooks) Discriminator() Discriminator {
 	return HasFields("type", "payload")
 }
 
-func (s *sourceWithHooks) Parse(raw []byte) (Parsed, error) {
+func (s *sourceWithHooks) Parse(raw []byte) (Message, error) {
 	var env struct {
 		Type    string          `json:"type"`
 		Payload json.RawMessage `json:"payload"`
 	}
 	if err := json.Unmarshal(raw, &env); err != nil {
-		return Parsed{}, err
+		return Message{}, err
 	}
 	if env.Type == "" {
-		return Parsed{}, errors.New("missing type")
+		return Message{}, errors.New("missing type")
 	}
-	return Parsed{Key: env.Type, Payload: env.Payload}, nil
+	return Message{Key: env.Type, Payload: env.Payload}, nil
 }
 
 func (s *sourceWithHooks) OnParse(ctx context.Context, key string) context.Context {
@@ -66,6 +70,11 @@ func (s *sourceWithHooks) OnFailure(ctx context.Context, key string, err error,
 	s.onFailureCalled = true
 }
 
+func (s *sourceWithHooks) OnParseError(ctx context.Context, err error) error {
+	s.onParseErrorCalled = true
+	return s.onParseErrorErr
+}
+
 func (s *sourceWithHooks) OnNoHandler(ctx context.Context, key string) error {
 	s.onNoHandlerCalled = true
 	return s.onNoHandlerErr
@@ -88,6 +97,7 @@ var (
 	_ OnDispatchHook        = (*sourceWithHooks)(nil)
 	_ OnSuccessHook         = (*sourceWithHooks)(nil)
 	_ OnFailureHook         = (*sourceWithHooks)(nil)
+	_ OnParseErrorHook      = (*sourceWithHooks)(nil)
 	_ OnNoHandlerHook       = (*sourceWithHooks)(nil)
 	_ OnUnmarshalErrorHook  = (*sourceWithHooks)(nil)
 	_ OnValidationErrorHook = (*sourceWithHooks)(nil)
@@ -111,7 +121,7 @@ func (s *SourceHooksSuite) TestOnParseCalledAfterGlobal() {
 		return ctx
 	}))
 	r.AddSource(source)
-	Register(r, "test", &testHandler{})
+	RegisterProc(r, "test", &testHandler{})
 
 	msg := []byte(`{"type": "test", "payload": {}}`)
 	err := r.Process(context.Background(), msg)
@@ -131,7 +141,7 @@ func (s *SourceHooksSuite) TestOnDispatchCalledAfterGlobal() {
 		order = append(order, "global")
 	}))
 	r.AddSource(source)
-	Register(r, "test", &testHandler{})
+	RegisterProc(r, "test", &testHandler{})
 
 	msg := []byte(`{"type": "test", "payload": {}}`)
 	err := r.Process(context.Background(), msg)
@@ -151,7 +161,7 @@ func (s *SourceHooksSuite) TestOnSuccessCalledAfterGlobal() {
 		order = append(order, "global")
 	}))
 	r.AddSource(source)
-	Register(r, "test", &testHandler{})
+	RegisterProc(r, "test", &testHandler{})
 
 	msg := []byte(`{"type": "test", "payload": {}}`)
 	err := r.Process(context.Background(), msg)
@@ -171,7 +181,7 @@ func (s *SourceHooksSuite) TestOnFailureCalledAfterGlobal() {
 		order = append(order, "global")
 	}))
 	r.AddSource(source)
-	Register(r, "test", &testHandler{err: errors.New("fail")})
+	RegisterProc(r, "test", &testHandler{err: errors.New("fail")})
 
 	msg := []byte(`{"type": "test", "payload": {}}`)
 	err := r.Process(context.Background(), msg)
@@ -228,7 +238,7 @@ func (s *SourceHooksSuite) TestSourceOnUnmarshalErrorCanOverrideGlobal() {
 		return nil
 	}))
 	r.AddSource(source)
-	Register(r, "test", &testHandler{})
+	RegisterProc(r, "test", &testHandler{})
 
 	msg := []byte(`{"type": "test", "payload": "invalid"}`)
 	err := r.Process(context.Background(), msg)
@@ -237,6 +247,55 @@ func (s *SourceHooksSuite) TestSourceOnUnmarshalErrorCanOverrideGlobal() {
 	s.Assert().Error(err)
 }
 
+func (s *SourceHooksSuite) TestSourceOnParseErrorCanOverrideGlobalSkip() {
+	source := &sourceWithHooks{
+		name:            "test",
+		onParseErrorErr: errors.New("source says fail"),
+	}
+
+	var globalErr error
+	r := New(WithOnParseError(func(ctx context.Context, src string, err error) error {
+		globalErr = err
+		return nil
+	}))
+	r.AddSource(source)
+
+	msg := []byte(`{"type": "", "payload": {}}`)
+	err := r.Process(context.Background(), msg)
+
+	s.Assert().True(source.onParseErrorCalled)
+	s.Assert().EqualError(globalErr, "missing type")
+	s.Assert().EqualError(err, "source says fail")
+}
+
+func (s *SourceHooksSuite) TestSourceOnParseErrorCanSkip() {
+	source := &sourceWithHooks{name: "test"}
+
+	r := New(WithOnParseError(func(ctx context.Context, src string, err error) error {
+		return nil
+	}))
+	r.AddSource(source)
+
+	msg := []byte(`{"type": "", "payload": {}}`)
+	err := r.Process(context.Background(), msg)
+
+	s.Assert().True(source.onParseErrorCalled)
+	s.Assert().NoError(err)
+}
+
+func (s *SourceHooksSuite) TestParseErrorReturnedWithoutGlobalHook() {
+	source := &sourceWithHooks{name: "test"}
+
+	r := New()
+	r.AddSource(source)
+
+	msg := []byte(`{"type": "", "payload": {}}`)
+	err := r.Process(context.Background(), msg)
+
+	s.Assert().True(source.onParseErrorCalled)
+	s.Assert().EqualError(err, "parse failed for source test: missing type")
+}
+
 type SourceHooksContextPropagationSuite struct {
 	suite.Suite
 }
@@ -252,7 +311,7 @@ func (s *SourceHooksContextPropagationSuite) TestSourceOnParseContextAvailableTo
 	r := New()
 	r.AddSource(source)
 
-	RegisterFunc(r, "test", func(ctx context.Context, p testPayload) error {
+	RegisterProcFunc(r, "test", func(ctx context.Context, p testPayload) error {
 		handlerCtx = ctx
 		return nil
 	})
@@ -263,3 +322,423 @@ func (s *SourceHooksContextPropagationSuite) TestSourceOnParseContextAvailableTo
 	s.NoError(err)
 	s.Assert().Equal("called", handlerCtx.Value(contextKey("source-hook")))
 }
+
+type TimingsHookSuite struct {
+	suite.Suite
+}
+
+func TestTimingsHookSuite(t *testing.T) {
+	suite.Run(t, new(TimingsHookSuite))
+}
+
+// slowReplier sleeps before acknowledging a reply.
+type slowReplier struct {
+	delay time.Duration
+}
+
+func (r *slowReplier) Reply(ctx context.Context, result json.RawMessage) error {
+	time.Sleep(r.delay)
+	return nil
+}
+
+func (r *slowReplier) Fail(ctx context.Context, err error) error {
+	time.Sleep(r.delay)
+	return nil
+}
+
+func (s *TimingsHookSuite) TestReportsStageDurations() {
+	var got Timings
+	var calls int
+
+	r := New(WithOnTimings(func(ctx context.Context, source, key string, t Timings) {
+		calls++
+		got = t
+	}))
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{
+			Key:     "test",
+			Payload: []byte(`{"value": "x"}`),
+			Replier: &slowReplier{delay: 5 * time.Millisecond},
+		}, nil
+	}))
+	RegisterProcFunc(r, "test", func(ctx context.Context, p testPayload) error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+
+	err := r.Process(context.Background(), []byte(`{"type": "test"}`))
+
+	s.Require().NoError(err)
+	s.Assert().Equal(1, calls)
+	s.Assert().GreaterOrEqual(got.Handle, 10*time.Millisecond)
+	s.Assert().GreaterOrEqual(got.Reply, 5*time.Millisecond)
+	s.Assert().Less(got.Parse, got.Handle)
+	s.Assert().Equal(got.Match+got.Parse+got.Unmarshal+got.Validate+got.Handle+got.Reply, got.Total())
+}
+
+func (s *TimingsHookSuite) TestReportsOnUnmarshalError() {
+	var got *Timings
+
+	r := New(
+		WithOnTimings(func(ctx context.Context, source, key string, t Timings) {
+			got = &t
+		}),
+		WithOnUnmarshalError(func(ctx context.Context, source, key string, err error) error {
+			return nil
+		}),
+	)
+	r.AddSource(&testSource{name: "test"})
+	RegisterProc(r, "test", &testHandler{})
+
+	err := r.Process(context.Background(), []byte(`{"type": "test", "payload": "invalid"}`))
+
+	s.Require().NoError(err)
+	s.Require().NotNil(got)
+	s.Assert().Zero(got.Handle)
+	s.Assert().Zero(got.Reply)
+}
+
+func (s *TimingsHookSuite) TestNotCalledWhenNoSourceMatches() {
+	var called bool
+
+	r := New(WithOnTimings(func(ctx context.Context, source, key string, t Timings) {
+		called = true
+	}))
+	r.AddSource(&testSource{name: "test"})
+
+	_ = r.Process(context.Background(), []byte(`{"other": true}`))
+
+	s.Assert().False(called)
+}
+
+type HookErrorPolicySuite struct {
+	suite.Suite
+}
+
+func TestHookErrorPolicySuite(t *testing.T) {
+	suite.Run(t, new(HookErrorPolicySuite))
+}
+
+func (s *HookErrorPolicySuite) process(opts ...Option) (err, globalErr, sourceErr error) {
+	globalErr = errors.New("global error")
+	sourceErr = errors.New("source error")
+	source := &sourceWithHooks{name: "test", onNoHandlerErr: sourceErr}
+
+	opts = append(opts, WithOnNoHandler(func(ctx context.Context, src, key string) error {
+		return globalErr
+	}))
+	r := New(opts...)
+	r.AddSource(source)
+
+	err = r.Process(context.Background(), []byte(`{"type": "unknown", "payload": {}}`))
+	return err, globalErr, sourceErr
+}
+
+func (s *HookErrorPolicySuite) TestFirstErrorByDefault() {
+	err, globalErr, sourceErr := s.process()
+
+	s.Assert().ErrorIs(err, globalErr)
+	s.Assert().NotErrorIs(err, sourceErr)
+}
+
+func (s *HookErrorPolicySuite) TestJoinsAllErrors() {
+	err, globalErr, sourceErr := s.process(WithHookErrorPolicy(JoinHookErrors))
+
+	s.Assert().ErrorIs(err, globalErr)
+	s.Assert().ErrorIs(err, sourceErr)
+
+	var joined interface{ Unwrap() []error }
+	s.Require().ErrorAs(err, &joined)
+	s.Assert().Len(joined.Unwrap(), 2)
+}
+
+func (s *HookErrorPolicySuite) TestJoinReturnsSingleErrorUnwrapped() {
+	wantErr := errors.New("only")
+	r := New(
+		WithHookErrorPolicy(JoinHookErrors),
+		WithOnUnmarshalError(func(ctx context.Context, source, key string, err error) error {
+			return wantErr
+		}),
+	)
+	r.AddSource(&testSource{name: "test"})
+	RegisterProc(r, "test", &testHandler{})
+
+	err := r.Process(context.Background(), []byte(`{"type": "test", "payload": "bad"}`))
+
+	var derr *DispatchError
+	s.Require().ErrorAs(err, &derr)
+	s.Assert().Equal(wantErr, derr.Err)
+}
+
+type HookOrderSuite struct {
+	suite.Suite
+}
+
+func TestHookOrderSuite(t *testing.T) {
+	suite.Run(t, new(HookOrderSuite))
+}
+
+func (s *HookOrderSuite) globalSeesSourceContext(opts ...Option) bool {
+	var seen bool
+	opts = append(opts, WithOnParse(func(ctx context.Context, src, key string) context.Context {
+		seen = ctx.Value(contextKey("source-hook")) != nil
+		return ctx
+	}))
+
+	r := New(opts...)
+	r.AddSource(&sourceWithHooks{name: "test"})
+	RegisterProc(r, "test", &testHandler{})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`)))
+	return seen
+}
+
+func (s *HookOrderSuite) TestGlobalHooksFirstByDefault() {
+	s.Assert().False(s.globalSeesSourceContext())
+}
+
+func (s *HookOrderSuite) TestSourceHooksFirst() {
+	s.Assert().True(s.globalSeesSourceContext(WithHookOrder(SourceHooksFirst, HookParse)))
+}
+
+func (s *HookOrderSuite) TestOrderAppliesOnlyToSelectedKinds() {
+	s.Assert().False(s.globalSeesSourceContext(WithHookOrder(SourceHooksFirst, HookDispatch|HookSuccess)))
+}
+
+func (s *HookOrderSuite) TestLaterOptionRestoresGlobalFirst() {
+	s.Assert().False(s.globalSeesSourceContext(
+		WithHookOrder(SourceHooksFirst, AllHooks),
+		WithHookOrder(GlobalHooksFirst, HookParse),
+	))
+}
+
+func (s *HookOrderSuite) TestSourceErrorWinsWhenSourceFirst() {
+	sourceErr := errors.New("source error")
+	r := New(
+		WithHookOrder(SourceHooksFirst, HookNoHandler),
+		WithOnNoHandler(func(ctx context.Context, src, key string) error {
+			return errors.New("global error")
+		}),
+	)
+	r.AddSource(&sourceWithHooks{name: "test", onNoHandlerErr: sourceErr})
+
+	err := r.Process(context.Background(), []byte(`{"type": "unknown", "payload": {}}`))
+
+	s.Assert().ErrorIs(err, sourceErr)
+}
+
+type OnReplyHookSuite struct {
+	suite.Suite
+}
+
+func TestOnReplyHookSuite(t *testing.T) {
+	suite.Run(t, new(OnReplyHookSuite))
+}
+
+// replySource attaches replier to every message and wraps replies in
+// {"source": ...}.
+type replySource struct {
+	testSource
+	replier Replier
+}
+
+func (s *replySource) Parse(raw []byte) (Message, error) {
+	msg, err := s.testSource.Parse(raw)
+	msg.Replier = s.replier
+	return msg, err
+}
+
+func (s *replySource) OnReply(ctx context.Context, key string, result json.RawMessage) (json.RawMessage, error) {
+	return json.RawMessage(`{"source":` + string(result) + `}`), nil
+}
+
+func (s *OnReplyHookSuite) TestChainsGlobalThenSource() {
+	var got string
+	replier := completeReplier(func(ctx context.Context, err error) error { return err })
+	rec := &recordingReplier{Replier: replier, reply: &got}
+
+	r := New(WithOnReply(func(ctx context.Context, source, key string, result json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(`{"global":` + string(result) + `}`), nil
+	}))
+	r.AddSource(&replySource{testSource: testSource{name: "test"}, replier: rec})
+	RegisterFuncFunc(r, "test", func(ctx context.Context, p struct{}) (int, error) {
+		return 1, nil
+	})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`)))
+	s.Assert().JSONEq(`{"source": {"global": 1}}`, got)
+}
+
+func (s *OnReplyHookSuite) TestErrorSendsFail() {
+	var failed error
+	replier := completeReplier(func(ctx context.Context, err error) error {
+		failed = err
+		return nil
+	})
+
+	r := New(WithOnReply(func(ctx context.Context, source, key string, result json.RawMessage) (json.RawMessage, error) {
+		return nil, errors.New("cannot encode")
+	}))
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(`{}`), Replier: replier}, nil
+	}))
+	RegisterProc(r, "test", &testHandler{})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test"}`)))
+	s.Assert().EqualError(failed, "cannot encode")
+}
+
+func (s *OnReplyHookSuite) TestNotCalledOnHandlerError() {
+	var called bool
+	replier := completeReplier(func(ctx context.Context, err error) error { return err })
+
+	r := New(WithOnReply(func(ctx context.Context, source, key string, result json.RawMessage) (json.RawMessage, error) {
+		called = true
+		return result, nil
+	}))
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(`{}`), Replier: replier}, nil
+	}))
+	RegisterProc(r, "test", &testHandler{err: errors.New("boom")})
+
+	s.Assert().EqualError(r.Process(context.Background(), []byte(`{"type": "test"}`)), "boom")
+	s.Assert().False(called)
+}
+
+// recordingReplier records the result passed to Reply.
+type recordingReplier struct {
+	Replier
+	reply *string
+}
+
+func (r *recordingReplier) Reply(ctx context.Context, result json.RawMessage) error {
+	*r.reply = string(result)
+	return nil
+}
+
+type HookAbstainSuite struct {
+	suite.Suite
+}
+
+func TestHookAbstainSuite(t *testing.T) {
+	suite.Run(t, new(HookAbstainSuite))
+}
+
+func (s *HookAbstainSuite) TestAbstainingHookKeepsDefault() {
+	called := false
+	r := New(WithOnNoHandler(func(ctx context.Context, source, key string) error {
+		called = true
+		return ErrHookAbstain
+	}))
+	r.AddSource(&testSource{name: "test"})
+
+	err := r.Process(context.Background(), []byte(`{"type": "missing", "payload": {}}`))
+
+	s.Assert().True(called)
+	s.Assert().ErrorIs(err, ErrNoHandler)
+	s.Assert().NotErrorIs(err, ErrHookAbstain)
+}
+
+func (s *HookAbstainSuite) TestOtherHooksDecide() {
+	r := New(
+		WithOnNoSource(func(ctx context.Context, raw []byte) error { return ErrHookAbstain }),
+		WithOnNoSource(func(ctx context.Context, raw []byte) error { return nil }),
+	)
+
+	s.Assert().NoError(r.Process(context.Background(), []byte(`{}`)))
+}
+
+type HookWrappersSuite struct {
+	suite.Suite
+}
+
+func TestHookWrappersSuite(t *testing.T) {
+	suite.Run(t, new(HookWrappersSuite))
+}
+
+// hookFields returns the settable fields of h. The fields are unexported, so
+// they are reached through their addresses.
+func hookFields(h *hooks) []reflect.Value {
+	v := reflect.ValueOf(h).Elem()
+	fields := make([]reflect.Value, v.NumField())
+	for i := range fields {
+		f := v.Field(i)
+		fields[i] = reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+	}
+	return fields
+}
+
+// recordingHooks returns hooks with one function per hook kind, each of which
+// records the name of its field when called.
+func recordingHooks(called map[string]bool) hooks {
+	var h hooks
+	for i, field := range hookFields(&h) {
+		name := reflect.TypeFor[hooks]().Field(i).Name
+		fn := reflect.MakeFunc(field.Type().Elem(), func(args []reflect.Value) []reflect.Value {
+			called[name] = true
+			return hookResults(field.Type().Elem(), args)
+		})
+		field.Set(reflect.Append(field, fn))
+	}
+	return h
+}
+
+// hookResults returns results for a hook of type t: its context argument for
+// a returned context, and zero values otherwise.
+func hookResults(t reflect.Type, args []reflect.Value) []reflect.Value {
+	results := make([]reflect.Value, t.NumOut())
+	for i := range results {
+		results[i] = reflect.Zero(t.Out(i))
+		if t.Out(i) == reflect.TypeFor[context.Context]() {
+			results[i] = args[0]
+		}
+	}
+	return results
+}
+
+// callHooks calls every hook in h with zero arguments and a background
+// context.
+func callHooks(h hooks) {
+	for _, field := range hookFields(&h) {
+		for j := range field.Len() {
+			fn := field.Index(j)
+			args := make([]reflect.Value, fn.Type().NumIn())
+			for k := range args {
+				args[k] = reflect.Zero(fn.Type().In(k))
+				if fn.Type().In(k) == reflect.TypeFor[context.Context]() {
+					args[k] = reflect.ValueOf(context.Background())
+				}
+			}
+			fn.Call(args)
+		}
+	}
+}
+
+func (s *HookWrappersSuite) TestForwardEveryHookKind() {
+	wrappers := map[string]func(...Option) Option{
+		"WithHookFilter": func(opts ...Option) Option {
+			return WithHookFilter(func(source, key string) bool { return true }, opts...)
+		},
+		"WithSampledHooks": func(opts ...Option) Option {
+			return WithSampledHooks(1, opts...)
+		},
+		"WithBoundedKeys": func(opts ...Option) Option {
+			return WithBoundedKeys(CollapseKeys("other"), opts...)
+		},
+	}
+	for name, wrap := range wrappers {
+		s.Run(name, func() {
+			called := make(map[string]bool)
+			inner := recordingHooks(called)
+
+			var r Router
+			wrap(func(r *Router) { r.hooks = inner })(&r)
+			callHooks(r.hooks)
+
+			for i := range reflect.TypeFor[hooks]().NumField() {
+				name := reflect.TypeFor[hooks]().Field(i).Name
+				s.Assert().True(called[name], "%s hooks are not forwarded", name)
+			}
+		})
+	}
+}
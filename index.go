@@ -0,0 +1,376 @@
+package dispatch
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// requirements describes conditions a discriminator needs in order to match.
+// They are necessary but not always sufficient: exact is true only when the
+// requirements fully describe the discriminator.
+type requirements struct {
+	fields []string          // paths that must exist
+	equals map[string]string // paths that must equal a string value
+	never  bool              // conflicting requirements; can never match
+	exact  bool              // requirements are equivalent to Match
+}
+
+// requirementsOf extracts the field requirements of a discriminator. Unknown
+// discriminators have no requirements and must always be evaluated.
+func requirementsOf(d Discriminator) requirements {
+	switch d := d.(type) {
+	case hasFields:
+		return requirements{fields: d.paths, exact: true}
+	case fieldEquals:
+		return requirements{equals: map[string]string{d.path: d.value}, exact: true}
+	case fieldHasPrefix:
+		return requirements{fields: []string{d.path}}
+	case fieldHasSuffix:
+		return requirements{fields: []string{d.path}}
+	case fieldContains:
+		return requirements{fields: []string{d.path}}
+	case fieldIsString:
+		return requirements{fields: []string{d.path}}
+	case fieldIsObject:
+		return requirements{fields: []string{d.path}}
+	case fieldIsArray:
+		return requirements{fields: []string{d.path}}
+	case fieldTrue:
+		return requirements{fields: []string{d.path}}
+	case fieldGreaterThan:
+		return requirements{fields: []string{d.path}}
+	case anyElementMatches:
+		return requirements{fields: []string{d.path}}
+	case and:
+		req := requirements{exact: true}
+		for _, sub := range d.ds {
+			req.merge(requirementsOf(sub))
+		}
+		return req
+	}
+	return requirements{}
+}
+
+// merge adds the requirements of a conjunct.
+func (r *requirements) merge(o requirements) {
+	r.fields = append(r.fields, o.fields...)
+	for path, value := range o.equals {
+		if r.equals == nil {
+			r.equals = make(map[string]string)
+		}
+		if existing, ok := r.equals[path]; ok && existing != value {
+			r.never = true
+		}
+		r.equals[path] = value
+	}
+	r.never = r.never || o.never
+	r.exact = r.exact && o.exact
+}
+
+// matchIndex holds the precompiled requirements of every source so that
+// each distinct path is looked up at most once per message, regardless of
+// how many sources reference it.
+type matchIndex struct {
+	defaults groupIndex
+	groups   []groupIndex
+
+	// matches counts successful matches; every reorderInterval matches the
+	// hot list is rebuilt from per-source hit counts.
+	matches atomic.Uint64
+	hot     atomic.Pointer[[]*compiledSource]
+
+	// prioritized is set when some source has a non-zero priority. The hot
+	// list is not used then, since trying a hot source first could let it
+	// win over a higher-priority source whose discriminator overlaps.
+	prioritized bool
+
+	// frozen is set for the index of a CompiledRouter. Its order is fixed
+	// at Build, so matches are not counted and the hot list stays empty.
+	frozen bool
+
+	// affinity maps message fingerprints to the source that matched them;
+	// see WithSourceAffinity.
+	affinity     sync.Map // string -> *compiledSource
+	affinitySize atomic.Int64
+}
+
+// groupIndex is the compiled form of the sources sharing one inspector.
+type groupIndex struct {
+	fields  []string // distinct paths checked for existence
+	strings []string // distinct paths checked for string equality
+	sources []compiledSource
+	ownView bool // some sources have their own inspector
+
+	// fallbacks are tried after every group's sources; see Fallback.
+	fallbacks []compiledSource
+}
+
+// viewFunc returns the View of the message for insp.
+type viewFunc func(insp Inspector) (View, bool)
+
+// compiledSource is a source with its requirements resolved to indexes into
+// the owning groupIndex.
+type compiledSource struct {
+	source   Source
+	ref      sourceRef
+	hits     *atomic.Uint64
+	prio     int // see SetSourcePriority
+	disc     Discriminator
+	insp     Inspector // the source's own inspector, or nil for the group's
+	fields   []int
+	equals   []compiledEquals
+	never    bool
+	exact    bool
+	fallback bool
+}
+
+type compiledEquals struct {
+	path  int
+	value string
+}
+
+// compileIndex builds a matchIndex from the router's sources.
+func (r *Router) compileIndex() *matchIndex {
+	settings := r.settings.Load()
+	idx := &matchIndex{
+		defaults: compileGroup(-1, r.defaultSources, settings),
+		groups:   make([]groupIndex, len(r.groups)),
+	}
+	for i, g := range r.groups {
+		idx.groups[i] = compileGroup(i, g.sources, settings)
+	}
+	for _, g := range idx.allGroups() {
+		idx.prioritized = idx.prioritized || slices.ContainsFunc(g.sources, func(cs compiledSource) bool {
+			return cs.prio != 0
+		})
+	}
+	return idx
+}
+
+// compileGroup compiles a group's enabled sources in the order they are
+// tried.
+func compileGroup(groupIdx int, sources []Source, settings *sourceSettings) groupIndex {
+	var g groupIndex
+	fieldIdx := make(map[string]int)
+	stringIdx := make(map[string]int)
+
+	intern := func(list *[]string, seen map[string]int, path string) int {
+		if i, ok := seen[path]; ok {
+			return i
+		}
+		seen[path] = len(*list)
+		*list = append(*list, path)
+		return seen[path]
+	}
+
+	for i, src := range settings.order(sources) {
+		if !settings.enabled(src.Name()) {
+			continue
+		}
+		disc := src.Discriminator()
+		req := requirementsOf(disc)
+		cs := compiledSource{
+			source: src,
+			ref:    sourceRef{groupIdx: groupIdx, sourceIdx: i},
+			hits:   new(atomic.Uint64),
+			prio:   settings.priorityOf(src.Name()),
+			disc:   disc,
+			insp:   sourceInspector(src),
+			never:  req.never,
+			exact:  req.exact,
+		}
+		if isFallback(src) {
+			cs.fallback = true
+			g.fallbacks = append(g.fallbacks, cs)
+			continue
+		}
+		if cs.insp != nil {
+			// The group's memo is keyed to the group's View, so sources
+			// with their own are matched by their discriminator alone.
+			cs.exact = false
+			g.ownView = true
+			g.sources = append(g.sources, cs)
+			continue
+		}
+		for _, path := range req.fields {
+			cs.fields = append(cs.fields, intern(&g.fields, fieldIdx, path))
+		}
+		for path, value := range req.equals {
+			cs.equals = append(cs.equals, compiledEquals{
+				path:  intern(&g.strings, stringIdx, path),
+				value: value,
+			})
+		}
+		g.sources = append(g.sources, cs)
+	}
+	return g
+}
+
+// match returns the index of the first source in the group whose
+// discriminator matches, or -1. v is the group's View, or nil if the
+// group's inspector rejected the message; own supplies Views for sources
+// with their own inspector.
+func (g *groupIndex) match(v View, own viewFunc) int {
+	return g.matchRange(v, own, 0, len(g.sources), nil)
+}
+
+// matchRange is like match but only considers sources[lo:hi]. If stop is
+// non-nil, it is checked before each source and ends the search early when
+// it returns true.
+func (g *groupIndex) matchRange(v View, own viewFunc, lo, hi int, stop func() bool) int {
+	// Typical groups check a handful of paths; keep the memo on the stack.
+	var fieldBuf [16]lookup
+	var strBuf [8]stringLookup
+	fields := fieldBuf[:]
+	if len(g.fields) > len(fieldBuf) {
+		fields = make([]lookup, len(g.fields))
+	}
+	strs := strBuf[:]
+	if len(g.strings) > len(strBuf) {
+		strs = make([]stringLookup, len(g.strings))
+	}
+
+	for i := lo; i < hi; i++ {
+		if stop != nil && stop() {
+			return -1
+		}
+		cs := &g.sources[i]
+		if cs.insp != nil {
+			if view, ok := own(cs.insp); ok && !cs.never && cs.disc.Match(view) {
+				return i
+			}
+			continue
+		}
+		if v == nil || cs.never || !g.satisfies(v, cs, fields, strs) {
+			continue
+		}
+		if cs.exact || cs.disc.Match(v) {
+			return i
+		}
+	}
+	return -1
+}
+
+// viewOf returns the View the matched source cs was evaluated against.
+func (cs *compiledSource) viewOf(groupView View, own viewFunc) View {
+	if cs.insp == nil {
+		return groupView
+	}
+	v, _ := own(cs.insp)
+	return v
+}
+
+// satisfies reports whether the view meets the source's requirements,
+// memoizing each lookup for the remaining sources.
+func (g *groupIndex) satisfies(v View, cs *compiledSource, fields []lookup, strs []stringLookup) bool {
+	for _, fi := range cs.fields {
+		if fields[fi] == lookupUnknown {
+			fields[fi] = lookupMissing
+			if v.HasField(g.fields[fi]) {
+				fields[fi] = lookupFound
+			}
+		}
+		if fields[fi] == lookupMissing {
+			return false
+		}
+	}
+	for _, eq := range cs.equals {
+		sl := &strs[eq.path]
+		if sl.state == lookupUnknown {
+			sl.state = lookupMissing
+			if s, ok := v.GetString(g.strings[eq.path]); ok {
+				sl.state = lookupFound
+				sl.value = s
+			}
+		}
+		if sl.state == lookupMissing || sl.value != eq.value {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup is the memoized result of a path lookup.
+type lookup uint8
+
+const (
+	lookupUnknown lookup = iota
+	lookupFound
+	lookupMissing
+)
+
+type stringLookup struct {
+	state lookup
+	value string
+}
+
+// Adaptive ordering: the sources that matched most often recently are tried
+// first, before the full indexed scan. Unlike a single last-match slot, this
+// stays effective when traffic from several sources is interleaved.
+const (
+	hotSources      = 4   // sources tried before the full scan
+	reorderInterval = 256 // matches between hot list rebuilds
+)
+
+// record counts a match for cs and periodically rebuilds the hot list. It
+// does nothing when sources are prioritized or the index is frozen.
+func (idx *matchIndex) record(cs *compiledSource) {
+	if idx.prioritized || idx.frozen {
+		return
+	}
+	cs.hits.Add(1)
+	if idx.matches.Add(1)%reorderInterval == 0 {
+		idx.reorder()
+	}
+}
+
+// reorder rebuilds the hot list from hit counts, then halves the counts so
+// the ordering follows shifts in traffic. Ties keep registration order.
+func (idx *matchIndex) reorder() {
+	type counted struct {
+		cs   *compiledSource
+		hits uint64
+	}
+	var all []counted
+	for _, g := range idx.allGroups() {
+		for i := range g.sources {
+			cs := &g.sources[i]
+			if n := cs.halveHits(); n > 0 {
+				all = append(all, counted{cs: cs, hits: n})
+			}
+		}
+	}
+
+	slices.SortStableFunc(all, func(a, b counted) int {
+		return cmp.Compare(b.hits, a.hits)
+	})
+	hot := make([]*compiledSource, 0, min(len(all), hotSources))
+	for _, c := range all[:min(len(all), hotSources)] {
+		hot = append(hot, c.cs)
+	}
+	idx.hot.Store(&hot)
+}
+
+// halveHits halves the source's hit count and returns the count before
+// halving. It retries rather than overwrite hits recorded concurrently.
+func (cs *compiledSource) halveHits() uint64 {
+	for {
+		n := cs.hits.Load()
+		if cs.hits.CompareAndSwap(n, n/2) {
+			return n
+		}
+	}
+}
+
+// allGroups returns the default group followed by the custom groups, in
+// match order.
+func (idx *matchIndex) allGroups() []*groupIndex {
+	groups := make([]*groupIndex, 0, len(idx.groups)+1)
+	groups = append(groups, &idx.defaults)
+	for i := range idx.groups {
+		groups = append(groups, &idx.groups[i])
+	}
+	return groups
+}
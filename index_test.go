@@ -0,0 +1,209 @@
+package dispatch
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// countingView records how many times each path is looked up.
+type countingView struct {
+	View
+	lookups map[string]int
+}
+
+func (v *countingView) HasField(path string) bool {
+	v.lookups[path]++
+	return v.View.HasField(path)
+}
+
+func (v *countingView) GetString(path string) (string, bool) {
+	v.lookups[path]++
+	return v.View.GetString(path)
+}
+
+type lookupCountingInspector struct {
+	last *countingView
+}
+
+func (i *lookupCountingInspector) Inspect(raw []byte) (View, error) {
+	v, err := JSONInspector().Inspect(raw)
+	if err != nil {
+		return nil, err
+	}
+	i.last = &countingView{View: v, lookups: make(map[string]int)}
+	return i.last, nil
+}
+
+type MatchIndexSuite struct {
+	suite.Suite
+}
+
+func TestMatchIndexSuite(t *testing.T) {
+	suite.Run(t, new(MatchIndexSuite))
+}
+
+func (s *MatchIndexSuite) TestRequirementsOf() {
+	tests := map[string]struct {
+		d      Discriminator
+		fields []string
+		equals map[string]string
+		never  bool
+		exact  bool
+	}{
+		"has fields":   {d: HasFields("a", "b"), fields: []string{"a", "b"}, exact: true},
+		"field equals": {d: FieldEquals("a", "x"), equals: map[string]string{"a": "x"}, exact: true},
+		"prefix":       {d: FieldHasPrefix("a", "x"), fields: []string{"a"}},
+		"and": {
+			d:      And(HasFields("a"), FieldEquals("b", "x")),
+			fields: []string{"a"},
+			equals: map[string]string{"b": "x"},
+			exact:  true,
+		},
+		"and inexact": {d: And(HasFields("a"), FieldTrue("b")), fields: []string{"a", "b"}},
+		"conflict": {
+			d:      And(FieldEquals("a", "x"), FieldEquals("a", "y")),
+			equals: map[string]string{"a": "y"},
+			never:  true,
+			exact:  true,
+		},
+		"or":  {d: Or(HasFields("a"), HasFields("b"))},
+		"not": {d: Not(HasFields("a"))},
+	}
+
+	for name, tt := range tests {
+		s.Run(name, func() {
+			req := requirementsOf(tt.d)
+			s.Assert().Equal(tt.fields, req.fields)
+			s.Assert().Equal(tt.equals, req.equals)
+			s.Assert().Equal(tt.never, req.never)
+			s.Assert().Equal(tt.exact, req.exact)
+		})
+	}
+}
+
+func (s *MatchIndexSuite) TestLooksUpEachPathOnce() {
+	var matched string
+	insp := &lookupCountingInspector{}
+	r := New(
+		WithInspector(insp),
+		WithOnNoHandler(func(ctx context.Context, source, key string) error {
+			matched = source
+			return nil
+		}),
+	)
+	for i := range 30 {
+		name := fmt.Sprintf("source-%d", i)
+		r.AddSource(SourceFunc(name, And(
+			HasFields("type", "payload"),
+			FieldEquals("type", name),
+		), func([]byte) (Message, error) {
+			return Message{Key: name}, nil
+		}))
+	}
+
+	err := r.Process(context.Background(), []byte(`{"type": "source-29", "payload": {}}`))
+
+	s.Require().NoError(err)
+	s.Assert().Equal("source-29", matched)
+	s.Assert().Equal(map[string]int{"type": 2, "payload": 1}, insp.last.lookups)
+}
+
+func (s *MatchIndexSuite) TestEvaluatesInexactDiscriminators() {
+	process := func(raw string) string {
+		var key string
+		r := New(WithOnNoHandler(func(ctx context.Context, source, k string) error {
+			key = k
+			return nil
+		}))
+		r.AddSource(SourceFunc("flagged", And(HasFields("type"), FieldTrue("flag")), func([]byte) (Message, error) {
+			return Message{Key: "flagged"}, nil
+		}))
+		r.AddSource(SourceFunc("fallback", HasFields("type"), func([]byte) (Message, error) {
+			return Message{Key: "fallback"}, nil
+		}))
+
+		s.Require().NoError(r.Process(context.Background(), []byte(raw)))
+		return key
+	}
+
+	s.Assert().Equal("fallback", process(`{"type": "a", "flag": false}`))
+	s.Assert().Equal("flagged", process(`{"type": "a", "flag": true}`))
+}
+
+func (s *MatchIndexSuite) TestRecompilesAfterAddSource() {
+	r := New()
+	r.AddSource(SourceFunc("a", HasFields("a"), func([]byte) (Message, error) {
+		return Message{Key: "a"}, nil
+	}))
+
+	err := r.Process(context.Background(), []byte(`{"b": 1}`))
+	s.Require().Error(err)
+
+	r.AddSource(SourceFunc("b", HasFields("b"), func([]byte) (Message, error) {
+		return Message{Key: "b", Payload: []byte(`{}`)}, nil
+	}))
+	RegisterProcFunc(r, "b", func(ctx context.Context, p struct{}) error { return nil })
+
+	s.Assert().NoError(r.Process(context.Background(), []byte(`{"b": 1}`)))
+}
+
+func (s *MatchIndexSuite) TestHotListFollowsInterleavedTraffic() {
+	r := New()
+	r.AddSource(SourceFunc("rare", FieldEquals("kind", "rare"), nil))
+	r.AddSource(SourceFunc("a", FieldEquals("kind", "a"), nil))
+	r.AddSource(SourceFunc("b", FieldEquals("kind", "b"), nil))
+
+	msgA := []byte(`{"kind": "a"}`)
+	msgB := []byte(`{"kind": "b"}`)
+	for range reorderInterval / 2 {
+		src, _ := r.match(msgA)
+		s.Require().Equal("a", src.Name())
+		src, _ = r.match(msgB)
+		s.Require().Equal("b", src.Name())
+	}
+
+	hot := r.matchIndex().hot.Load()
+	s.Require().NotNil(hot)
+	var names []string
+	for _, cs := range *hot {
+		names = append(names, cs.source.Name())
+	}
+	s.Assert().Equal([]string{"a", "b"}, names)
+}
+
+func (s *MatchIndexSuite) TestHotListFavorsFrequentSource() {
+	r := New()
+	r.AddSource(SourceFunc("a", FieldEquals("kind", "a"), nil))
+	r.AddSource(SourceFunc("b", FieldEquals("kind", "b"), nil))
+
+	for i := range reorderInterval {
+		msg := []byte(`{"kind": "a"}`)
+		if i%4 != 0 {
+			msg = []byte(`{"kind": "b"}`)
+		}
+		_, _ = r.match(msg)
+	}
+
+	hot := r.matchIndex().hot.Load()
+	s.Require().NotNil(hot)
+	s.Require().Len(*hot, 2)
+	s.Assert().Equal("b", (*hot)[0].source.Name())
+}
+
+func (s *MatchIndexSuite) TestHotListResetWhenSourcesChange() {
+	r := New()
+	r.AddSource(SourceFunc("a", FieldEquals("kind", "a"), nil))
+	for range reorderInterval {
+		_, _ = r.match([]byte(`{"kind": "a"}`))
+	}
+	s.Require().NotNil(r.matchIndex().hot.Load())
+
+	r.AddSource(SourceFunc("b", FieldEquals("kind", "b"), nil))
+
+	s.Assert().Nil(r.matchIndex().hot.Load())
+	src, _ := r.match([]byte(`{"kind": "b"}`))
+	s.Assert().Equal("b", src.Name())
+}
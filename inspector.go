@@ -2,6 +2,9 @@ package dispatch
 
 import (
 	"errors"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/tidwall/gjson"
 )
@@ -16,6 +19,11 @@ type Inspector interface {
 }
 
 // View provides format-agnostic field access for discriminator matching.
+//
+// Paths use dot notation for nested fields. Array elements are addressed by
+// index, so "Records.0.eventSource" reads the eventSource field of the first
+// element of the Records array. Use Path to build paths for keys that contain
+// dots or other special characters.
 type View interface {
 	// HasField returns true if the path exists in the message.
 	HasField(path string) bool
@@ -29,7 +37,136 @@ type View interface {
 	GetBytes(path string) ([]byte, bool)
 }
 
+// NumberView is an optional interface for views that support numeric access.
+// Discriminators such as FieldGreaterThan use it when the view implements it.
+type NumberView interface {
+	// GetInt returns the integer value at path, or false if not found or
+	// not an integer.
+	GetInt(path string) (int64, bool)
+
+	// GetFloat returns the numeric value at path, or false if not found or
+	// not a number.
+	GetFloat(path string) (float64, bool)
+}
+
+// BoolView is an optional interface for views that support boolean access.
+// Discriminators such as FieldTrue use it when the view implements it.
+type BoolView interface {
+	// GetBool returns the boolean value at path, or false if not found or
+	// not a boolean.
+	GetBool(path string) (bool, bool)
+}
+
+// ArrayView is an optional interface for views that support iterating over
+// array elements. AnyElementMatches uses it when the view implements it.
+type ArrayView interface {
+	// Elements returns a View for each element of the array at path, or
+	// false if not found or not an array.
+	Elements(path string) ([]View, bool)
+}
+
+// NestedView is an optional interface for views that can return a scoped
+// view over a nested object. Use GetView to access it with a fallback for
+// views that do not implement it.
+type NestedView interface {
+	// GetView returns a View rooted at path, or false if not found or not
+	// an object.
+	GetView(path string) (View, bool)
+}
+
+// GetView returns a View scoped to the object at path, so discriminators and
+// sources can query inside an envelope without re-inspecting the raw bytes.
+// Paths passed to the returned View are relative to path.
+//
+// If v implements NestedView, its GetView method is used. Otherwise path
+// must hold a JSON object, as reported by v.GetBytes, and the returned View
+// prefixes each path with path before delegating to v.
+//
+// Example:
+//
+//	if detail, ok := dispatch.GetView(v, "detail"); ok {
+//	    userID, _ := detail.GetString("userId")
+//	}
+func GetView(v View, path string) (View, bool) {
+	if nv, ok := v.(NestedView); ok {
+		return nv.GetView(path)
+	}
+	raw, ok := v.GetBytes(path)
+	if !ok || !isJSONObject(raw) {
+		return nil, false
+	}
+	return prefixView{view: v, prefix: path + "."}, true
+}
+
+// prefixView scopes a View to a nested path by prefixing every lookup. It
+// forwards the optional View interfaces the wrapped View implements.
+type prefixView struct {
+	view   View
+	prefix string
+}
+
+func (v prefixView) HasField(path string) bool {
+	return v.view.HasField(v.prefix + path)
+}
+
+func (v prefixView) GetString(path string) (string, bool) {
+	return v.view.GetString(v.prefix + path)
+}
+
+func (v prefixView) GetBytes(path string) ([]byte, bool) {
+	return v.view.GetBytes(v.prefix + path)
+}
+
+func (v prefixView) GetInt(path string) (int64, bool) {
+	if nv, ok := v.view.(NumberView); ok {
+		return nv.GetInt(v.prefix + path)
+	}
+	return 0, false
+}
+
+func (v prefixView) GetFloat(path string) (float64, bool) {
+	if nv, ok := v.view.(NumberView); ok {
+		return nv.GetFloat(v.prefix + path)
+	}
+	return 0, false
+}
+
+func (v prefixView) GetBool(path string) (bool, bool) {
+	if bv, ok := v.view.(BoolView); ok {
+		return bv.GetBool(v.prefix + path)
+	}
+	return false, false
+}
+
+func (v prefixView) Elements(path string) ([]View, bool) {
+	if av, ok := v.view.(ArrayView); ok {
+		return av.Elements(v.prefix + path)
+	}
+	return nil, false
+}
+
+func (v prefixView) GetTime(path string) (time.Time, bool) {
+	if tv, ok := v.view.(TimeView); ok {
+		return tv.GetTime(v.prefix + path)
+	}
+	return time.Time{}, false
+}
+
+func (v prefixView) GetView(path string) (View, bool) {
+	return GetView(v.view, v.prefix+path)
+}
+
+// TimeView is an optional interface for views that support timestamp access.
+type TimeView interface {
+	// GetTime returns the time value at path, or false if not found or not
+	// an RFC 3339 timestamp.
+	GetTime(path string) (time.Time, bool)
+}
+
 // JSONInspector returns an Inspector that uses gjson for field access.
+// Large documents are parsed once per message, so discriminators that check
+// many fields don't rescan the whole payload for each one. Its views are safe
+// for concurrent use.
 func JSONInspector() Inspector {
 	return jsonInspector{}
 }
@@ -40,19 +177,130 @@ func (jsonInspector) Inspect(raw []byte) (View, error) {
 	if !gjson.ValidBytes(raw) {
 		return nil, ErrInvalidJSON
 	}
-	return jsonView{raw: raw}, nil
+	return newJSONView(raw), nil
 }
 
+// jsonView answers lookups with gjson. Documents of at least minIndexedJSON
+// bytes get a jsonIndex, so repeated lookups under the same top-level member
+// only scan that member instead of the whole document.
 type jsonView struct {
-	raw []byte
+	raw   []byte
+	index *jsonIndex
+}
+
+// minIndexedJSON is the smallest document that jsonInspector indexes. Smaller
+// documents are cheaper to scan directly than to index.
+const minIndexedJSON = 256
+
+func newJSONView(raw []byte) jsonView {
+	v := jsonView{raw: raw}
+	if len(raw) >= minIndexedJSON {
+		v.index = &jsonIndex{raw: raw, object: isJSONObject(raw)}
+	}
+	return v
+}
+
+func (v jsonView) get(path string) gjson.Result {
+	if v.index != nil {
+		return v.index.get(path)
+	}
+	return gjson.GetBytes(v.raw, path)
+}
+
+// isJSONObject reports whether the first non-whitespace byte of raw opens an
+// object.
+func isJSONObject(raw []byte) bool {
+	for _, c := range raw {
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			continue
+		}
+		return c == '{'
+	}
+	return false
+}
+
+// jsonIndex caches the top-level members of a JSON object as they are looked
+// up. Members are subslices of raw, so the document is never copied, and each
+// one is located at most once. It is safe for concurrent use.
+type jsonIndex struct {
+	raw    []byte
+	object bool // whether raw is an object
+
+	mu      sync.RWMutex
+	members map[string][]byte // member values by key; nil for missing keys
+}
+
+// member returns the value of the top-level member key. Duplicate keys
+// resolve to the first occurrence, as gjson does.
+func (x *jsonIndex) member(key string) ([]byte, bool) {
+	x.mu.RLock()
+	m, ok := x.members[key]
+	x.mu.RUnlock()
+	if ok {
+		return m, m != nil
+	}
+
+	r := gjson.GetBytes(x.raw, key)
+	switch {
+	case !r.Exists():
+		m = nil
+	case r.Index > 0:
+		m = x.raw[r.Index : r.Index+len(r.Raw)]
+	default:
+		m = []byte(r.Raw)
+	}
+
+	x.mu.Lock()
+	if x.members == nil {
+		x.members = make(map[string][]byte)
+	}
+	x.members[key] = m
+	x.mu.Unlock()
+	return m, m != nil
+}
+
+// get returns the result at path. Paths whose first component is a plain key
+// are resolved within the cached member; anything else, such as wildcards,
+// queries, or modifiers, falls back to a full gjson lookup.
+func (x *jsonIndex) get(path string) gjson.Result {
+	key, rest, ok := splitPlainKey(path)
+	if !ok || !x.object {
+		return gjson.GetBytes(x.raw, path)
+	}
+	m, ok := x.member(key)
+	if !ok {
+		return gjson.Result{}
+	}
+	if rest == "" {
+		return gjson.ParseBytes(m)
+	}
+	return gjson.GetBytes(m, rest)
+}
+
+// splitPlainKey splits path into its first component and the remainder. It
+// reports false if the first component uses gjson syntax beyond a plain key.
+func splitPlainKey(path string) (key, rest string, ok bool) {
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '.':
+			if i == 0 || i == len(path)-1 {
+				return "", "", false
+			}
+			return path[:i], path[i+1:], true
+		case '\\', '*', '?', '#', '|', '@', '!', '=', '<', '>', '%', '[', ']', '{', '}', '(', ')', ',', '"':
+			return "", "", false
+		}
+	}
+	return path, "", path != ""
 }
 
 func (v jsonView) HasField(path string) bool {
-	return gjson.GetBytes(v.raw, path).Exists()
+	return v.get(path).Exists()
 }
 
 func (v jsonView) GetString(path string) (string, bool) {
-	r := gjson.GetBytes(v.raw, path)
+	r := v.get(path)
 	if !r.Exists() {
 		return "", false
 	}
@@ -63,9 +311,70 @@ func (v jsonView) GetString(path string) (string, bool) {
 }
 
 func (v jsonView) GetBytes(path string) ([]byte, bool) {
-	r := gjson.GetBytes(v.raw, path)
+	r := v.get(path)
 	if !r.Exists() {
 		return nil, false
 	}
 	return []byte(r.Raw), true
 }
+
+func (v jsonView) GetInt(path string) (int64, bool) {
+	r := v.get(path)
+	if r.Type != gjson.Number {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(r.Raw, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+func (v jsonView) GetFloat(path string) (float64, bool) {
+	r := v.get(path)
+	if r.Type != gjson.Number {
+		return 0, false
+	}
+	return r.Num, true
+}
+
+func (v jsonView) GetBool(path string) (bool, bool) {
+	r := v.get(path)
+	if r.Type != gjson.True && r.Type != gjson.False {
+		return false, false
+	}
+	return r.Bool(), true
+}
+
+func (v jsonView) GetTime(path string) (time.Time, bool) {
+	r := v.get(path)
+	if r.Type != gjson.String {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, r.Str)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func (v jsonView) Elements(path string) ([]View, bool) {
+	r := v.get(path)
+	if !r.IsArray() {
+		return nil, false
+	}
+	var views []View
+	r.ForEach(func(_, elem gjson.Result) bool {
+		views = append(views, newJSONView([]byte(elem.Raw)))
+		return true
+	})
+	return views, true
+}
+
+func (v jsonView) GetView(path string) (View, bool) {
+	r := v.get(path)
+	if !r.IsObject() {
+		return nil, false
+	}
+	return newJSONView([]byte(r.Raw)), true
+}
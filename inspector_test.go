@@ -1,9 +1,14 @@
 package dispatch
 
 import (
+	"bytes"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
+	"github.com/tidwall/gjson"
 )
 
 type JSONInspectorSuite struct {
@@ -195,3 +200,373 @@ func (s *JSONViewGetBytesSuite) TestReturnsFalseForMissingField() {
 
 	s.Assert().False(ok)
 }
+
+type JSONViewTypedGettersSuite struct {
+	suite.Suite
+	view View
+}
+
+func (s *JSONViewTypedGettersSuite) SetupTest() {
+	inspector := JSONInspector()
+	raw := []byte(`{
+		"count": 42,
+		"ratio": 1.5,
+		"active": true,
+		"disabled": false,
+		"time": "2024-01-15T10:30:00Z",
+		"name": "my.app"
+	}`)
+
+	var err error
+	s.view, err = inspector.Inspect(raw)
+	s.Require().NoError(err)
+}
+
+func TestJSONViewTypedGettersSuite(t *testing.T) {
+	suite.Run(t, new(JSONViewTypedGettersSuite))
+}
+
+func (s *JSONViewTypedGettersSuite) TestGetInt() {
+	nv, ok := s.view.(NumberView)
+	s.Require().True(ok)
+
+	n, ok := nv.GetInt("count")
+	s.Require().True(ok)
+	s.Assert().Equal(int64(42), n)
+
+	_, ok = nv.GetInt("ratio")
+	s.Assert().False(ok)
+
+	_, ok = nv.GetInt("name")
+	s.Assert().False(ok)
+
+	_, ok = nv.GetInt("missing")
+	s.Assert().False(ok)
+}
+
+func (s *JSONViewTypedGettersSuite) TestGetFloat() {
+	nv, ok := s.view.(NumberView)
+	s.Require().True(ok)
+
+	f, ok := nv.GetFloat("ratio")
+	s.Require().True(ok)
+	s.Assert().InDelta(1.5, f, 0)
+
+	f, ok = nv.GetFloat("count")
+	s.Require().True(ok)
+	s.Assert().InDelta(42.0, f, 0)
+
+	_, ok = nv.GetFloat("name")
+	s.Assert().False(ok)
+}
+
+func (s *JSONViewTypedGettersSuite) TestGetBool() {
+	bv, ok := s.view.(BoolView)
+	s.Require().True(ok)
+
+	b, ok := bv.GetBool("active")
+	s.Require().True(ok)
+	s.Assert().True(b)
+
+	b, ok = bv.GetBool("disabled")
+	s.Require().True(ok)
+	s.Assert().False(b)
+
+	_, ok = bv.GetBool("count")
+	s.Assert().False(ok)
+}
+
+func (s *JSONViewTypedGettersSuite) TestGetTime() {
+	tv, ok := s.view.(TimeView)
+	s.Require().True(ok)
+
+	tm, ok := tv.GetTime("time")
+	s.Require().True(ok)
+	s.Assert().Equal(time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), tm)
+
+	_, ok = tv.GetTime("name")
+	s.Assert().False(ok)
+
+	_, ok = tv.GetTime("count")
+	s.Assert().False(ok)
+}
+
+type JSONViewArraySuite struct {
+	suite.Suite
+	view View
+}
+
+func (s *JSONViewArraySuite) SetupTest() {
+	inspector := JSONInspector()
+	raw := []byte(`{
+		"Records": [
+			{"eventSource": "aws:sqs", "body": "a"},
+			{"eventSource": "aws:s3", "body": "b"}
+		],
+		"empty": [],
+		"name": "my.app"
+	}`)
+
+	var err error
+	s.view, err = inspector.Inspect(raw)
+	s.Require().NoError(err)
+}
+
+func TestJSONViewArraySuite(t *testing.T) {
+	suite.Run(t, new(JSONViewArraySuite))
+}
+
+func (s *JSONViewArraySuite) TestIndexedPaths() {
+	val, ok := s.view.GetString("Records.1.eventSource")
+
+	s.Require().True(ok)
+	s.Assert().Equal("aws:s3", val)
+	s.Assert().True(s.view.HasField("Records.0.body"))
+	s.Assert().False(s.view.HasField("Records.2"))
+}
+
+func (s *JSONViewArraySuite) TestElements() {
+	av, ok := s.view.(ArrayView)
+	s.Require().True(ok)
+
+	elems, ok := av.Elements("Records")
+	s.Require().True(ok)
+	s.Require().Len(elems, 2)
+
+	val, ok := elems[1].GetString("eventSource")
+	s.Require().True(ok)
+	s.Assert().Equal("aws:s3", val)
+}
+
+func (s *JSONViewArraySuite) TestElementsEmptyArray() {
+	av, ok := s.view.(ArrayView)
+	s.Require().True(ok)
+
+	elems, ok := av.Elements("empty")
+	s.Require().True(ok)
+	s.Assert().Empty(elems)
+}
+
+func (s *JSONViewArraySuite) TestElementsReturnsFalseForNonArray() {
+	av, ok := s.view.(ArrayView)
+	s.Require().True(ok)
+
+	_, ok = av.Elements("name")
+	s.Assert().False(ok)
+
+	_, ok = av.Elements("missing")
+	s.Assert().False(ok)
+}
+
+type GetViewSuite struct {
+	suite.Suite
+	view View
+}
+
+func (s *GetViewSuite) SetupTest() {
+	inspector := JSONInspector()
+	raw := []byte(`{
+		"source": "my.app",
+		"detail": {
+			"userId": "123",
+			"nested": {"deep": "value"}
+		}
+	}`)
+
+	var err error
+	s.view, err = inspector.Inspect(raw)
+	s.Require().NoError(err)
+}
+
+func TestGetViewSuite(t *testing.T) {
+	suite.Run(t, new(GetViewSuite))
+}
+
+func (s *GetViewSuite) TestReturnsScopedView() {
+	detail, ok := GetView(s.view, "detail")
+	s.Require().True(ok)
+
+	val, ok := detail.GetString("userId")
+	s.Require().True(ok)
+	s.Assert().Equal("123", val)
+	s.Assert().True(detail.HasField("nested.deep"))
+	s.Assert().False(detail.HasField("source"))
+}
+
+func (s *GetViewSuite) TestReturnsFalseForNonObject() {
+	_, ok := GetView(s.view, "source")
+	s.Assert().False(ok)
+
+	_, ok = GetView(s.view, "missing")
+	s.Assert().False(ok)
+}
+
+func (s *GetViewSuite) TestFallsBackToPrefixedLookups() {
+	base := viewOnly{View: s.view}
+
+	detail, ok := GetView(base, "detail")
+	s.Require().True(ok)
+
+	val, ok := detail.GetString("userId")
+	s.Require().True(ok)
+	s.Assert().Equal("123", val)
+
+	raw, ok := detail.GetBytes("nested")
+	s.Require().True(ok)
+	s.Assert().JSONEq(`{"deep": "value"}`, string(raw))
+	s.Assert().True(detail.HasField("nested.deep"))
+
+	_, ok = GetView(base, "missing")
+	s.Assert().False(ok)
+}
+
+func (s *GetViewSuite) TestFallbackRequiresObject() {
+	base := viewOnly{View: s.view}
+
+	_, ok := GetView(base, "source")
+	s.Assert().False(ok)
+
+	detail, ok := GetView(base, "detail")
+	s.Require().True(ok)
+	_, ok = GetView(detail, "userId")
+	s.Assert().False(ok)
+}
+
+func (s *GetViewSuite) TestPrefixedViewsAreNested() {
+	detail, ok := GetView(viewOnly{View: s.view}, "detail")
+	s.Require().True(ok)
+
+	s.Assert().True(FieldIsObject("nested").Match(detail))
+	s.Assert().False(FieldIsObject("userId").Match(detail))
+
+	nested, ok := GetView(detail, "nested")
+	s.Require().True(ok)
+	val, ok := nested.GetString("deep")
+	s.Require().True(ok)
+	s.Assert().Equal("value", val)
+}
+
+// viewOnly hides any optional interfaces implemented by the wrapped View.
+type viewOnly struct {
+	View
+}
+
+// typedView hides NestedView but keeps the typed View interfaces, so GetView
+// falls back to prefixed lookups.
+type typedView struct {
+	View
+	NumberView
+	BoolView
+	ArrayView
+	TimeView
+}
+
+func (s *GetViewSuite) TestPrefixedLookupsForwardTypedViews() {
+	v, err := JSONInspector().Inspect([]byte(`{
+		"detail": {"count": 3, "ratio": 0.5, "active": true, "items": [{"id": "a"}], "at": "2024-01-02T03:04:05Z"}
+	}`))
+	s.Require().NoError(err)
+	base := typedView{View: v, NumberView: v.(NumberView), BoolView: v.(BoolView), ArrayView: v.(ArrayView), TimeView: v.(TimeView)}
+
+	detail, ok := GetView(base, "detail")
+	s.Require().True(ok)
+	s.Assert().True(FieldGreaterThan("count", 2).Match(detail))
+	s.Assert().True(FieldTrue("active").Match(detail))
+	s.Assert().True(AnyElementMatches("items", FieldEquals("id", "a")).Match(detail))
+
+	ratio, ok := detail.(NumberView).GetFloat("ratio")
+	s.Require().True(ok)
+	s.Assert().Equal(0.5, ratio)
+	at, ok := detail.(TimeView).GetTime("at")
+	s.Require().True(ok)
+	s.Assert().Equal(2024, at.Year())
+
+	plain, ok := GetView(viewOnly{View: v}, "detail")
+	s.Require().True(ok)
+	s.Assert().False(FieldGreaterThan("count", 2).Match(plain), "views without NumberView never match")
+}
+
+type JSONViewIndexSuite struct {
+	suite.Suite
+	raw []byte
+}
+
+func TestJSONViewIndexSuite(t *testing.T) {
+	suite.Run(t, new(JSONViewIndexSuite))
+}
+
+func (s *JSONViewIndexSuite) SetupTest() {
+	s.raw = []byte(`{
+		"padding": "` + strings.Repeat("x", minIndexedJSON) + `",
+		"source": "my.app",
+		"source": "duplicate",
+		"a.b": "dotted",
+		"count": 42,
+		"ok": true,
+		"detail": {"userId": "123", "tags": ["x", "y"]},
+		"items": [{"id": 1}, {"id": 2}]
+	}`)
+}
+
+func (s *JSONViewIndexSuite) TestMatchesGJSON() {
+	view, err := JSONInspector().Inspect(s.raw)
+	s.Require().NoError(err)
+	s.Require().NotNil(view.(jsonView).index)
+
+	paths := []string{
+		"source", "count", "ok", "detail", "detail.userId", "detail.tags.1",
+		"detail.tags.#", "items.#.id", "items.1.id", `a\.b`, "d*il.userId",
+		"missing", "detail.missing", "", ".", "source.", "@this.count",
+	}
+	for _, path := range paths {
+		s.Run(path, func() {
+			want := gjson.GetBytes(s.raw, path)
+
+			got, ok := view.GetBytes(path)
+			s.Assert().Equal(want.Exists(), ok)
+			s.Assert().Equal(want.Raw, string(got))
+
+			str, ok := view.GetString(path)
+			s.Assert().Equal(want.Type == gjson.String, ok)
+			s.Assert().Equal(want.Type == gjson.String, ok && str == want.Str)
+		})
+	}
+}
+
+func (s *JSONViewIndexSuite) TestMembersReferenceDocument() {
+	view, err := JSONInspector().Inspect(s.raw)
+	s.Require().NoError(err)
+	index := view.(jsonView).index
+
+	s.Require().True(view.HasField("detail.userId"))
+	m, ok := index.member("detail")
+	s.Require().True(ok)
+
+	start := bytes.Index(s.raw, []byte(`{"userId"`))
+	s.Assert().Same(&s.raw[start], &m[0], "member values are not copied")
+}
+
+func (s *JSONViewIndexSuite) TestSmallDocumentsAreNotIndexed() {
+	view, err := JSONInspector().Inspect([]byte(`{"source": "my.app"}`))
+	s.Require().NoError(err)
+
+	s.Assert().Nil(view.(jsonView).index)
+	s.Assert().True(view.HasField("source"))
+}
+
+func (s *JSONViewIndexSuite) TestConcurrentLookups() {
+	view, err := JSONInspector().Inspect(s.raw)
+	s.Require().NoError(err)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, ok := view.GetString("detail.userId")
+			s.Assert().True(ok)
+			s.Assert().Equal("123", got)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,160 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// kvInspector inspects "key=value;key=value" messages.
+type kvInspector struct {
+	calls *int
+}
+
+func (i kvInspector) Inspect(raw []byte) (View, error) {
+	if i.calls != nil {
+		*i.calls++
+	}
+	m := make(map[string]any)
+	for _, pair := range strings.Split(string(raw), ";") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, errors.New("not key=value")
+		}
+		m[k] = v
+	}
+	return MapView(m), nil
+}
+
+// kvSource parses kv messages, from the View when it can.
+type kvSource struct {
+	name     string
+	viewUsed bool
+}
+
+func (s *kvSource) Name() string                 { return s.name }
+func (s *kvSource) Discriminator() Discriminator { return FieldEquals("format", "kv") }
+
+func (s *kvSource) Parse(raw []byte) (Message, error) {
+	return Message{}, errors.New("use ParseView")
+}
+
+func (s *kvSource) ParseView(v View, raw []byte) (Message, error) {
+	s.viewUsed = true
+	key, _ := v.GetString("key")
+	return Message{Key: key, Payload: []byte(`{"value": "kv"}`)}, nil
+}
+
+func (s *kvSource) OnDispatch(ctx context.Context, key string) {}
+
+type InspectorProviderSuite struct {
+	suite.Suite
+	router  *Router
+	kv      *kvSource
+	handler *testHandler
+	calls   int
+}
+
+func TestInspectorProviderSuite(t *testing.T) {
+	suite.Run(t, new(InspectorProviderSuite))
+}
+
+func (s *InspectorProviderSuite) SetupTest() {
+	s.calls = 0
+	s.kv = &kvSource{name: "kv"}
+	s.handler = &testHandler{}
+	s.router = New()
+	s.router.AddSource(&testSource{name: "json"})
+	s.router.AddSourceWithInspector(s.kv, kvInspector{calls: &s.calls})
+	RegisterProc(s.router, "test", s.handler)
+}
+
+func (s *InspectorProviderSuite) TestMatchesWithOwnInspector() {
+	s.Require().NoError(s.router.Process(context.Background(), []byte("format=kv;key=test")))
+
+	s.Assert().True(s.kv.viewUsed, "ParseView gets the source's own View")
+	s.Assert().Equal("kv", s.handler.payload.Value)
+}
+
+func (s *InspectorProviderSuite) TestGroupSourcesStillMatch() {
+	s.Require().NoError(s.router.Process(context.Background(), []byte(`{"type": "test", "payload": {"value": "json"}}`)))
+
+	s.Assert().Equal("json", s.handler.payload.Value)
+	s.Assert().False(s.kv.viewUsed)
+}
+
+func (s *InspectorProviderSuite) TestKeepsRegistrationOrder() {
+	first := &kvSource{name: "first"}
+	r := New()
+	r.AddSourceWithInspector(first, kvInspector{})
+	r.AddSource(SourceFunc("json", HasFields("format"), func(raw []byte) (Message, error) {
+		return Message{}, errors.New("should not be reached")
+	}))
+	RegisterProc(r, "test", &testHandler{})
+
+	s.Require().NoError(r.Process(context.Background(), []byte("format=kv;key=test")))
+	s.Assert().True(first.viewUsed)
+}
+
+func (s *InspectorProviderSuite) TestInspectsOncePerMessage() {
+	r := New()
+	a := &kvSource{name: "a"}
+	r.AddSourceWithInspector(SourceFunc("never", FieldEquals("format", "other"), nil), kvInspector{calls: &s.calls})
+	r.AddSourceWithInspector(a, kvInspector{calls: &s.calls})
+	RegisterProc(r, "test", &testHandler{})
+
+	s.Require().NoError(r.Process(context.Background(), []byte("format=kv;key=test")))
+
+	s.Assert().True(a.viewUsed)
+	s.Assert().Equal(1, s.calls, "equal inspectors share one View")
+}
+
+func (s *InspectorProviderSuite) TestParallelMatch() {
+	r := New(WithParallelMatch(4))
+	for range minParallelSources {
+		r.AddSource(SourceFunc("json", FieldEquals("kind", "other"), nil))
+	}
+	kv := &kvSource{name: "kv"}
+	r.AddSourceWithInspector(kv, kvInspector{})
+	RegisterProc(r, "test", &testHandler{})
+
+	s.Require().NoError(r.Process(context.Background(), []byte("format=kv;key=test")))
+	s.Assert().True(kv.viewUsed)
+}
+
+func (s *InspectorProviderSuite) TestForwardsSourceHooks() {
+	r := New()
+	r.AddSourceWithInspector(s.kv, kvInspector{})
+	wrapped := r.defaultSources[0]
+
+	_, isHook := wrapped.(OnDispatchHook)
+	s.Assert().True(isHook)
+	s.Assert().Equal("kv", wrapped.Name())
+}
+
+func (s *InspectorProviderSuite) TestSourceImplementsInspectorProvider() {
+	r := New()
+	r.AddGroupWithHooks(JSONInspector(), nil, &providingSource{kvSource: kvSource{name: "provided"}})
+	RegisterProc(r, "test", &testHandler{})
+
+	s.Require().NoError(r.Process(context.Background(), []byte("format=kv;key=test")))
+
+	s.Assert().Equal("dispatch.kvInspector", r.RoutingTable().Sources[0].Inspector)
+}
+
+func (s *InspectorProviderSuite) TestCheckAmbiguity() {
+	r := New()
+	r.AddSourceWithInspector(&kvSource{name: "a"}, kvInspector{})
+	r.AddSourceWithInspector(&kvSource{name: "b"}, kvInspector{})
+
+	s.Assert().Equal([]string{"a", "b"}, r.matchingSources([]byte("format=kv;key=test")))
+}
+
+type providingSource struct {
+	kvSource
+}
+
+func (s *providingSource) Inspector() Inspector { return kvInspector{} }
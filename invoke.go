@@ -0,0 +1,73 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Invoke calls the handler registered for key with payload and returns its
+// result, without a source or raw message. Use it for internal calls, admin
+// tooling, and tests that exercise a handler through the router.
+//
+// The payload goes through the same JSON round trip, schema check, and
+// validation as a dispatched message, and the handler's context carries a
+// Message with Key and Payload and its HandlerInfo. Hooks, WithEnabled
+// gates, canaries, and shadows are not applied. A Proc's result is {}, so R
+// is typically struct{} for one.
+//
+// Invoke fails with ErrNoHandler if no handler is registered for key,
+// ErrUnmarshal or ErrValidation if the payload doesn't fit the handler, and
+// ErrShutdown after Shutdown.
+//
+// Example:
+//
+//	user, err := dispatch.Invoke[GetUser, User](ctx, r, "user/get", GetUser{ID: "42"})
+func Invoke[T, R any](ctx context.Context, d Dispatcher, key string, payload T) (R, error) {
+	var result R
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return result, fmt.Errorf("marshal payload: %w", err)
+	}
+	out, err := d.router().invokeKey(ctx, key, raw)
+	if err != nil {
+		return result, err
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return result, fmt.Errorf("unmarshal result: %w", err)
+	}
+	return result, nil
+}
+
+// invokeKey runs the handler for key with payload.
+func (r *Router) invokeKey(ctx context.Context, key string, payload json.RawMessage) (json.RawMessage, error) {
+	if !r.begin() {
+		return nil, ErrShutdown
+	}
+	defer r.end()
+
+	msg := Message{Key: key, CorrelationID: CorrelationID(ctx), Payload: payload}
+	handler, _, found := r.handlerFor(ctx, &parsed{insp: r.defaultInspector, msg: msg})
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrNoHandler, key)
+	}
+	ctx = withMessage(r.withHandlerInfo(ctx, key), msg)
+
+	var t Timings
+	err := r.schemas.check(ctx, msg, &t)
+	var result json.RawMessage
+	if err == nil {
+		result, err = handler(ctx, payload, &t)
+	}
+
+	var uerr *unmarshalError
+	if errors.As(err, &uerr) {
+		return nil, fmt.Errorf("%w: %w", ErrUnmarshal, uerr.err)
+	}
+	var verr *validationError
+	if errors.As(err, &verr) {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, verr.err)
+	}
+	return result, err
+}
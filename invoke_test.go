@@ -0,0 +1,102 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type InvokeSuite struct {
+	suite.Suite
+	router *Router
+}
+
+func TestInvokeSuite(t *testing.T) {
+	suite.Run(t, new(InvokeSuite))
+}
+
+func (s *InvokeSuite) SetupTest() {
+	s.router = New()
+	RegisterFuncFunc(s.router, "echo", func(ctx context.Context, p testPayload) (testPayload, error) {
+		return testPayload{Value: p.Value + "!"}, nil
+	})
+}
+
+func (s *InvokeSuite) TestFunc() {
+	out, err := Invoke[testPayload, testPayload](context.Background(), s.router, "echo", testPayload{Value: "hi"})
+	s.Require().NoError(err)
+	s.Assert().Equal("hi!", out.Value)
+}
+
+func (s *InvokeSuite) TestProc() {
+	h := &testHandler{}
+	RegisterProc(s.router, "proc", h)
+
+	_, err := Invoke[testPayload, struct{}](context.Background(), s.router, "proc", testPayload{Value: "hi"})
+	s.Require().NoError(err)
+	s.Assert().True(h.called)
+	s.Assert().Equal("hi", h.payload.Value)
+}
+
+func (s *InvokeSuite) TestNoHandler() {
+	_, err := Invoke[testPayload, testPayload](context.Background(), s.router, "missing", testPayload{})
+	s.Assert().ErrorIs(err, ErrNoHandler)
+}
+
+func (s *InvokeSuite) TestHandlerError() {
+	boom := errors.New("boom")
+	RegisterProcFunc(s.router, "fail", func(ctx context.Context, p testPayload) error {
+		return boom
+	})
+
+	_, err := Invoke[testPayload, struct{}](context.Background(), s.router, "fail", testPayload{})
+	s.Assert().ErrorIs(err, boom)
+}
+
+func (s *InvokeSuite) TestValidation() {
+	RegisterProcFunc(s.router, "validated", func(ctx context.Context, p *validatablePayload) error {
+		return nil
+	})
+
+	_, err := Invoke[validatablePayload, struct{}](context.Background(), s.router, "validated", validatablePayload{})
+	s.Assert().ErrorIs(err, ErrValidation)
+}
+
+func (s *InvokeSuite) TestSkipsHooks() {
+	var hooked bool
+	r := New(WithOnSuccess(func(ctx context.Context, source, key string, d time.Duration) {
+		hooked = true
+	}))
+	RegisterFuncFunc(r, "echo", func(ctx context.Context, p testPayload) (testPayload, error) {
+		return p, nil
+	})
+
+	_, err := Invoke[testPayload, testPayload](context.Background(), r, "echo", testPayload{})
+	s.Require().NoError(err)
+	s.Assert().False(hooked)
+}
+
+func (s *InvokeSuite) TestContext() {
+	var msg Message
+	var info HandlerInfo
+	RegisterProcFunc(s.router, "ctx", func(ctx context.Context, p testPayload) error {
+		msg, _ = MessageFromContext(ctx)
+		info, _ = HandlerInfoFromContext(ctx)
+		return nil
+	})
+
+	_, err := Invoke[testPayload, struct{}](context.Background(), s.router, "ctx", testPayload{Value: "hi"})
+	s.Require().NoError(err)
+	s.Assert().Equal("ctx", msg.Key)
+	s.Assert().JSONEq(`{"value": "hi"}`, string(msg.Payload))
+	s.Assert().Equal("ctx", info.Key)
+}
+
+func (s *InvokeSuite) TestShutdown() {
+	s.Require().NoError(s.router.Shutdown(context.Background()))
+	_, err := Invoke[testPayload, testPayload](context.Background(), s.router, "echo", testPayload{})
+	s.Assert().ErrorIs(err, ErrShutdown)
+}
@@ -0,0 +1,63 @@
+package dispatch
+
+import "strings"
+
+// Key is a routing key tied to its payload type, so registering a handler
+// for the wrong payload type fails to compile instead of failing to
+// unmarshal at runtime. Declare keys once, next to their payload types:
+//
+//	var UserCreatedKey = dispatch.NewKey[UserCreated]("user/created")
+//
+//	dispatch.RegisterProcKey(r, UserCreatedKey, &UserCreatedProc{}) // Proc[UserCreated]
+//	dispatch.RegisterProcKey(r, UserCreatedKey, &UserDeletedProc{}) // does not compile
+type Key[T any] struct {
+	name string
+}
+
+// NewKey returns the key name for payloads of type T.
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{name: name}
+}
+
+// String returns the key name, as returned by a source's Parse method.
+func (k Key[T]) String() string {
+	return k.name
+}
+
+// RegisterKey records k in reg, so its name and payload type are checked
+// against the rest of the registry.
+func RegisterKey[T any](reg *Registry, k Key[T]) {
+	Register[T](reg, k.name)
+}
+
+// RegisterProcKey adds a procedure for k. The procedure's payload type must
+// be k's type parameter.
+func RegisterProcKey[T any](r *Router, k Key[T], p Proc[T], opts ...HandlerOption) {
+	RegisterProc(r, k.name, p, opts...)
+}
+
+// RegisterFuncKey adds a function for k. The function's payload type must be
+// k's type parameter.
+func RegisterFuncKey[T, R any](r *Router, k Key[T], f Func[T, R], opts ...HandlerOption) {
+	RegisterFunc(r, k.name, f, opts...)
+}
+
+// EventBridgeKey returns the routing key for EventBridge events with the
+// given source and detail-type, joined by a colon, such as
+// "users.service:user/created". Deriving keys on both the producer and
+// consumer side from it keeps them from drifting apart; the eventbridge
+// module's WithSourceKeys option uses it for both.
+//
+// Example:
+//
+//	var UserCreatedKey = dispatch.NewKey[UserCreated](dispatch.EventBridgeKey("users.service", "user/created"))
+func EventBridgeKey(source, detailType string) string {
+	return source + ":" + detailType
+}
+
+// SplitEventBridgeKey splits a key built by EventBridgeKey into its source
+// and detail-type. EventBridge sources can't contain a colon, so the key is
+// split at the first one. ok is false if key has none.
+func SplitEventBridgeKey(key string) (source, detailType string, ok bool) {
+	return strings.Cut(key, ":")
+}
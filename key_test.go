@@ -0,0 +1,74 @@
+package dispatch
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type KeySuite struct {
+	suite.Suite
+	key Key[testPayload]
+}
+
+func TestKeySuite(t *testing.T) {
+	suite.Run(t, new(KeySuite))
+}
+
+func (s *KeySuite) SetupTest() {
+	s.key = NewKey[testPayload]("test")
+}
+
+func (s *KeySuite) TestString() {
+	s.Assert().Equal("test", s.key.String())
+}
+
+func (s *KeySuite) TestRegisterProcKey() {
+	r := New()
+	r.AddSource(&testSource{name: "test"})
+	handler := &testHandler{}
+
+	RegisterProcKey(r, s.key, handler)
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test", "payload": {"value": "x"}}`)))
+	s.Assert().True(handler.called)
+}
+
+func (s *KeySuite) TestRegisterFuncKey() {
+	r := New()
+	r.AddSource(&testSource{name: "test"})
+	var got string
+
+	RegisterFuncKey(r, s.key, FuncFunc[testPayload, string](func(ctx context.Context, p testPayload) (string, error) {
+		got = p.Value
+		return "ok", nil
+	}))
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test", "payload": {"value": "x"}}`)))
+	s.Assert().Equal("x", got)
+}
+
+func (s *KeySuite) TestRegisterKey() {
+	reg := NewRegistry()
+
+	RegisterKey(reg, s.key)
+
+	t, ok := reg.TypeOf("test")
+	s.Assert().True(ok)
+	s.Assert().Equal(reflect.TypeFor[testPayload](), t)
+}
+
+func (s *KeySuite) TestEventBridgeKey() {
+	key := EventBridgeKey("users.service", "user/created")
+	s.Assert().Equal("users.service:user/created", key)
+
+	source, detailType, ok := SplitEventBridgeKey(key)
+	s.Assert().True(ok)
+	s.Assert().Equal("users.service", source)
+	s.Assert().Equal("user/created", detailType)
+
+	_, _, ok = SplitEventBridgeKey("UserCreated")
+	s.Assert().False(ok)
+}
@@ -0,0 +1,152 @@
+package dispatch
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Keys is a catalog of routing keys, so a codebase refers to generated
+// constants instead of repeating raw strings. Build one from the keys a
+// router handles with HandlerKeys, write it out as Go constants with
+// GenerateGo, and make routers refuse handlers for keys outside it with
+// WithKeys. dispatchgen -consts writes the same constants from an AsyncAPI
+// document.
+//
+// Example:
+//
+//	var Catalog = dispatch.NewKeys(events.UserCreated, events.UserDeleted)
+//
+//	r := dispatch.New(dispatch.WithKeys(Catalog))
+type Keys struct {
+	mu   sync.RWMutex
+	keys map[string]struct{}
+}
+
+// NewKeys returns a catalog of the given keys.
+func NewKeys(keys ...string) *Keys {
+	k := &Keys{keys: make(map[string]struct{}, len(keys))}
+	for _, key := range keys {
+		k.Add(key)
+	}
+	return k
+}
+
+// HandlerKeys returns a catalog of the keys d has handlers for, including
+// keys with only guarded handlers.
+func HandlerKeys(d Dispatcher) *Keys {
+	return NewKeys(slices.Collect(maps.Keys(d.router().handlerTypes))...)
+}
+
+// Add adds key to the catalog and returns it, so a catalog can be built
+// alongside the variables that name its keys.
+//
+// Example:
+//
+//	var (
+//	    Catalog     = dispatch.NewKeys()
+//	    UserCreated = Catalog.Add("user/created")
+//	)
+func (k *Keys) Add(key string) string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[key] = struct{}{}
+	return key
+}
+
+// Contains reports whether key is in the catalog.
+func (k *Keys) Contains(key string) bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	_, ok := k.keys[key]
+	return ok
+}
+
+// List returns the keys in sorted order.
+func (k *Keys) List() []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return slices.Sorted(maps.Keys(k.keys))
+}
+
+// GenerateGo returns the formatted source of a Go file in package pkg that
+// declares a string constant for each key, named after it: "user/created"
+// becomes UserCreated. Names that would collide get a numeric suffix.
+//
+// Example:
+//
+//	code, err := dispatch.HandlerKeys(r).GenerateGo("events")
+//	err = os.WriteFile("events/keys_gen.go", code, 0o644)
+func (k *Keys) GenerateGo(pkg string) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by dispatch. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("// Routing keys.\nconst (\n")
+	taken := make(map[string]bool)
+	for _, key := range k.List() {
+		name := keyConstName(key)
+		for i := 2; taken[name]; i++ {
+			name = fmt.Sprintf("%s%d", keyConstName(key), i)
+		}
+		taken[name] = true
+		fmt.Fprintf(&b, "\t%s = %q\n", name, key)
+	}
+	b.WriteString(")\n")
+	return format.Source(b.Bytes())
+}
+
+// keyInitialisms are written in upper case in constant names, as golint
+// expects.
+var keyInitialisms = map[string]bool{
+	"API": true, "ARN": true, "HTTP": true, "ID": true, "JSON": true, "SKU": true,
+	"SNS": true, "SQS": true, "URL": true, "UUID": true,
+}
+
+// keyConstName converts a key such as "user/created" or "order.item_id" to an
+// exported Go identifier such as UserCreated or OrderItemID.
+func keyConstName(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, w := range words {
+		if upper := strings.ToUpper(w); keyInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	name := b.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "Key" + name
+	}
+	return name
+}
+
+// WithKeys makes the router refuse handlers for keys outside k: every
+// registration function, including the guarded, lazy, service, shadow, and
+// canary variants, panics, so a typo in a raw key fails at startup instead
+// of leaving messages unrouted.
+//
+// Example:
+//
+//	r := dispatch.New(dispatch.WithKeys(events.Catalog))
+func WithKeys(k *Keys) Option {
+	return func(r *Router) {
+		r.keys = k
+	}
+}
+
+// checkKey panics if the router has a key catalog without key.
+func (r *Router) checkKey(key string) {
+	if r.keys != nil && !r.keys.Contains(key) {
+		panic(fmt.Sprintf("dispatch: key %q is not in the key catalog", key))
+	}
+}
@@ -0,0 +1,89 @@
+package dispatch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type KeysSuite struct {
+	suite.Suite
+}
+
+func TestKeysSuite(t *testing.T) {
+	suite.Run(t, new(KeysSuite))
+}
+
+func (s *KeysSuite) TestCatalog() {
+	k := NewKeys("user/deleted")
+	s.Assert().Equal("user/created", k.Add("user/created"))
+
+	s.Assert().True(k.Contains("user/created"))
+	s.Assert().False(k.Contains("user/updated"))
+	s.Assert().Equal([]string{"user/created", "user/deleted"}, k.List())
+}
+
+func (s *KeysSuite) TestHandlerKeys() {
+	r := New()
+	RegisterProc(r, "user/created", &testHandler{})
+	RegisterFuncFunc(r, "user/lookup", func(ctx context.Context, p testPayload) (testPayload, error) {
+		return p, nil
+	})
+	RegisterProcIf(r, "user/guarded", func(View) bool { return true }, &testHandler{})
+
+	s.Assert().Equal([]string{"user/created", "user/guarded", "user/lookup"}, HandlerKeys(r).List())
+}
+
+func (s *KeysSuite) TestGenerateGo() {
+	code, err := NewKeys("user/created", "user_created", "order.item_id", "42").GenerateGo("events")
+	s.Require().NoError(err)
+
+	s.Assert().Equal(`// Code generated by dispatch. DO NOT EDIT.
+
+package events
+
+// Routing keys.
+const (
+	Key42        = "42"
+	OrderItemID  = "order.item_id"
+	UserCreated  = "user/created"
+	UserCreated2 = "user_created"
+)
+`, string(code))
+}
+
+func (s *KeysSuite) TestWithKeys() {
+	r := New(WithKeys(NewKeys("user/created")))
+
+	s.Assert().NotPanics(func() { RegisterProc(r, "user/created", &testHandler{}) })
+	s.Assert().PanicsWithValue(`dispatch: key "user/creatd" is not in the key catalog`, func() {
+		RegisterProc(r, "user/creatd", &testHandler{})
+	})
+}
+
+func (s *KeysSuite) TestWithKeysAllRegistrations() {
+	newProc := func(context.Context) (Proc[testPayload], error) { return &testHandler{}, nil }
+	always := func(View) bool { return true }
+
+	cases := map[string]struct {
+		key      string
+		register func(r *Router)
+	}{
+		"RegisterProcIf":     {"user/creatd", func(r *Router) { RegisterProcIf(r, "user/creatd", always, &testHandler{}) }},
+		"RegisterProcLazy":   {"user/creatd", func(r *Router) { RegisterProcLazy(r, "user/creatd", newProc) }},
+		"RegisterService":    {"user/Creatd", func(r *Router) { RegisterService(r, "user/", &keysService{}) }},
+		"RegisterShadowProc": {"user/creatd", func(r *Router) { RegisterShadowProc(r, "user/creatd", &testHandler{}) }},
+		"RegisterCanaryProc": {"user/creatd", func(r *Router) { RegisterCanaryProc(r, "user/creatd", &testHandler{}, 5) }},
+	}
+	for name, tc := range cases {
+		s.Run(name, func() {
+			r := New(WithKeys(NewKeys("user/created")))
+			s.Assert().PanicsWithValue(`dispatch: key "`+tc.key+`" is not in the key catalog`, func() { tc.register(r) })
+		})
+	}
+}
+
+type keysService struct{}
+
+func (keysService) Creatd(context.Context, testPayload) error { return nil }
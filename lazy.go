@@ -0,0 +1,113 @@
+package dispatch
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// RegisterProcLazy adds a procedure for key that is built by newProc when
+// the first message for key is dispatched, rather than at startup. Use it
+// for handlers with expensive dependencies, such as database pools or SDK
+// clients, so a Lambda cold start doesn't pay for handlers it never uses.
+//
+// newProc is called with the context of the message being dispatched, and
+// at most once at a time. Its result is cached; if it returns an error, the
+// message fails with that error and the next message tries again. A built
+// handler that implements Starter is started before its first message, and
+// one that implements Closer is closed by Router.Shutdown.
+//
+// Example:
+//
+//	dispatch.RegisterProcLazy(r, "order/created", func(ctx context.Context) (dispatch.Proc[Order], error) {
+//	    db, err := sql.Open("postgres", dsn)
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return &OrderProc{db: db}, nil
+//	})
+func RegisterProcLazy[T any](r *Router, key string, newProc func(ctx context.Context) (Proc[T], error), opts ...HandlerOption) {
+	cfg := newHandlerConfig(opts)
+	r.checkKey(key)
+	r.checkRegistry(key, reflect.TypeFor[T]())
+	l := &lazyHandler[Proc[T]]{build: newProc}
+	r.manage(key, l)
+	r.handlerTypes[key] = handlerType{payload: reflect.TypeFor[T](), handler: reflect.TypeOf(newProc), schema: cfg.schemaJSON}
+	r.setEnabled(key, cfg.enabled)
+	r.handlers[key] = procInvoker(ProcFunc[T](func(ctx context.Context, payload T) error {
+		p, err := l.get(ctx)
+		if err != nil {
+			return err
+		}
+		return p.Run(ctx, payload)
+	}), cfg)
+}
+
+// RegisterFuncLazy adds a function for key that is built by newFunc when
+// the first message for key is dispatched, as RegisterProcLazy does.
+func RegisterFuncLazy[T, R any](r *Router, key string, newFunc func(ctx context.Context) (Func[T, R], error), opts ...HandlerOption) {
+	cfg := newHandlerConfig(opts)
+	r.checkKey(key)
+	r.checkRegistry(key, reflect.TypeFor[T]())
+	l := &lazyHandler[Func[T, R]]{build: newFunc}
+	r.manage(key, l)
+	r.handlerTypes[key] = handlerType{payload: reflect.TypeFor[T](), result: reflect.TypeFor[R](), handler: reflect.TypeOf(newFunc), schema: cfg.schemaJSON}
+	r.setEnabled(key, cfg.enabled)
+	r.handlers[key] = funcInvoker(FuncFunc[T, R](func(ctx context.Context, payload T) (R, error) {
+		f, err := l.get(ctx)
+		if err != nil {
+			var zero R
+			return zero, err
+		}
+		return f.Call(ctx, payload)
+	}), cfg)
+}
+
+// lazyHandler builds a handler on first use and caches it.
+type lazyHandler[H any] struct {
+	build func(context.Context) (H, error)
+
+	mu      sync.Mutex // serializes building and closing
+	built   atomic.Bool
+	handler H
+}
+
+// get returns the handler, building and starting it if this is the first
+// call to succeed.
+func (l *lazyHandler[H]) get(ctx context.Context) (H, error) {
+	if l.built.Load() {
+		return l.handler, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.built.Load() {
+		return l.handler, nil
+	}
+	h, err := l.build(ctx)
+	if err != nil {
+		return h, fmt.Errorf("build handler: %w", err)
+	}
+	if s, ok := any(h).(Starter); ok {
+		if err := s.Start(ctx); err != nil {
+			return h, fmt.Errorf("start handler: %w", err)
+		}
+	}
+	l.handler = h
+	l.built.Store(true)
+	return h, nil
+}
+
+// Close closes the handler if it has been built and implements Closer.
+func (l *lazyHandler[H]) Close(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.built.Load() {
+		return nil
+	}
+	if c, ok := any(l.handler).(Closer); ok {
+		return c.Close(ctx)
+	}
+	return nil
+}
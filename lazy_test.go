@@ -0,0 +1,162 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// lifecycleProc is a Proc that records Start and Close calls.
+type lifecycleProc struct {
+	started, closed int
+	startErr        error
+}
+
+func (p *lifecycleProc) Run(ctx context.Context, payload testPayload) error { return nil }
+
+func (p *lifecycleProc) Start(ctx context.Context) error {
+	p.started++
+	return p.startErr
+}
+
+func (p *lifecycleProc) Close(ctx context.Context) error {
+	p.closed++
+	return nil
+}
+
+type LazySuite struct {
+	suite.Suite
+	router *Router
+	builds atomic.Int32
+}
+
+func TestLazySuite(t *testing.T) {
+	suite.Run(t, new(LazySuite))
+}
+
+func (s *LazySuite) SetupTest() {
+	s.builds.Store(0)
+	s.router = New()
+	s.router.AddSource(&testSource{name: "test"})
+}
+
+func (s *LazySuite) process() error {
+	return s.router.Process(context.Background(), []byte(`{"type": "test", "payload": {"value": "v"}}`))
+}
+
+func (s *LazySuite) TestBuildsOnFirstDispatch() {
+	handler := &testHandler{}
+	RegisterProcLazy(s.router, "test", func(ctx context.Context) (Proc[testPayload], error) {
+		s.builds.Add(1)
+		return handler, nil
+	})
+	s.Assert().Zero(s.builds.Load(), "not built at registration")
+
+	s.Require().NoError(s.process())
+	s.Require().NoError(s.process())
+
+	s.Assert().Equal(int32(1), s.builds.Load())
+	s.Assert().Equal("v", handler.payload.Value)
+}
+
+func (s *LazySuite) TestBuildErrorIsRetried() {
+	boom := errors.New("no database")
+	RegisterProcLazy(s.router, "test", func(ctx context.Context) (Proc[testPayload], error) {
+		if s.builds.Add(1) == 1 {
+			return nil, boom
+		}
+		return &testHandler{}, nil
+	})
+
+	s.Assert().ErrorIs(s.process(), boom)
+	s.Require().NoError(s.process())
+	s.Assert().Equal(int32(2), s.builds.Load())
+}
+
+func (s *LazySuite) TestFuncLazy() {
+	replier := &keysReplier{}
+	r := New()
+	r.AddSource(SourceFunc("reply", HasFields("value"), func(raw []byte) (Message, error) {
+		return Message{Key: "echo", Payload: raw, Replier: replier}, nil
+	}))
+	RegisterFuncLazy(r, "echo", func(ctx context.Context) (Func[testPayload, string], error) {
+		return FuncFunc[testPayload, string](func(ctx context.Context, p testPayload) (string, error) {
+			return p.Value, nil
+		}), nil
+	})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"value": "hi"}`)))
+	s.Assert().JSONEq(`"hi"`, string(replier.result))
+}
+
+func (s *LazySuite) TestStartsAndClosesBuiltHandler() {
+	proc := &lifecycleProc{}
+	RegisterProcLazy(s.router, "test", func(ctx context.Context) (Proc[testPayload], error) {
+		return proc, nil
+	})
+
+	s.Require().NoError(s.router.Start(context.Background()))
+	s.Assert().Zero(proc.started, "Router.Start doesn't build lazy handlers")
+
+	s.Require().NoError(s.process())
+	s.Assert().Equal(1, proc.started)
+
+	s.Require().NoError(s.router.Shutdown(context.Background()))
+	s.Assert().Equal(1, proc.closed)
+}
+
+func (s *LazySuite) TestStartErrorIsRetried() {
+	boom := errors.New("not ready")
+	proc := &lifecycleProc{startErr: boom}
+	RegisterProcLazy(s.router, "test", func(ctx context.Context) (Proc[testPayload], error) {
+		return proc, nil
+	})
+
+	s.Assert().ErrorIs(s.process(), boom)
+	proc.startErr = nil
+	s.Require().NoError(s.process())
+	s.Assert().Equal(2, proc.started)
+}
+
+func (s *LazySuite) TestShutdownWithoutBuild() {
+	RegisterProcLazy(s.router, "test", func(ctx context.Context) (Proc[testPayload], error) {
+		s.builds.Add(1)
+		return &lifecycleProc{}, nil
+	})
+
+	s.Require().NoError(s.router.Shutdown(context.Background()))
+	s.Assert().Zero(s.builds.Load())
+}
+
+func (s *LazySuite) TestConcurrentFirstDispatch() {
+	RegisterProcLazy(s.router, "test", func(ctx context.Context) (Proc[testPayload], error) {
+		s.builds.Add(1)
+		return ProcFunc[testPayload](func(ctx context.Context, p testPayload) error { return nil }), nil
+	})
+
+	var wg sync.WaitGroup
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.NoError(s.process())
+		}()
+	}
+	wg.Wait()
+	s.Assert().Equal(int32(1), s.builds.Load())
+}
+
+func (s *LazySuite) TestRoutingTable() {
+	RegisterProcLazy(s.router, "test", func(ctx context.Context) (Proc[testPayload], error) {
+		return &testHandler{}, nil
+	})
+
+	handlers := s.router.RoutingTable().Handlers
+	s.Require().Len(handlers, 1)
+	s.Assert().Equal("dispatch.testPayload", handlers[0].Payload)
+	s.Assert().Equal("proc", handlers[0].Kind)
+}
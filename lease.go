@@ -0,0 +1,127 @@
+package dispatch
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Lease extends how long a transport waits before redelivering a message
+// that is still being handled, such as an SQS message's visibility timeout
+// or a Kafka consumer's session. Sources set it on Message.Lease.
+type Lease interface {
+	// ExtendLease asks the transport not to redeliver the message for at
+	// least d from now.
+	ExtendLease(ctx context.Context, d time.Duration) error
+}
+
+// LeaseFunc is a function adapter for Lease.
+type LeaseFunc func(ctx context.Context, d time.Duration) error
+
+// ExtendLease implements the Lease interface.
+func (f LeaseFunc) ExtendLease(ctx context.Context, d time.Duration) error {
+	return f(ctx, d)
+}
+
+// ExtendLease extends the lease of the message being processed by d, so a
+// long-running handler can keep working without the message being
+// redelivered. It does nothing and returns nil if ctx carries no message or
+// the message has no Lease.
+//
+// Example:
+//
+//	func (p *ExportProc) Run(ctx context.Context, in ExportRequest) error {
+//	    for _, page := range in.Pages {
+//	        if err := dispatch.ExtendLease(ctx, time.Minute); err != nil {
+//	            return err
+//	        }
+//	        p.export(ctx, page)
+//	    }
+//	    return nil
+//	}
+func ExtendLease(ctx context.Context, d time.Duration) error {
+	msg, ok := MessageFromContext(ctx)
+	if !ok || msg.Lease == nil {
+		return nil
+	}
+	return msg.Lease.ExtendLease(ctx, d)
+}
+
+// HeartbeatOption configures WithLeaseHeartbeat.
+type HeartbeatOption func(*heartbeatConfig)
+
+type heartbeatConfig struct {
+	interval  time.Duration
+	extension time.Duration
+	onError   func(ctx context.Context, source, key string, err error)
+}
+
+// WithHeartbeatErrors sets a function called when extending a lease fails.
+// The heartbeat for that message stops after the first failure.
+func WithHeartbeatErrors(fn func(ctx context.Context, source, key string, err error)) HeartbeatOption {
+	return func(c *heartbeatConfig) {
+		c.onError = fn
+	}
+}
+
+// WithLeaseHeartbeat extends the lease of every message that has one by
+// extension, every interval while its handler runs, so slow work isn't
+// redelivered to another consumer. interval should be comfortably shorter
+// than extension. Messages without a Lease are unaffected.
+//
+// A heartbeat can't extend Message.Deadline, so sources that support leases
+// should leave it unset or set it to the longest time a handler may run.
+//
+// Example:
+//
+//	r := dispatch.New(dispatch.WithLeaseHeartbeat(20*time.Second, time.Minute,
+//	    dispatch.WithHeartbeatErrors(func(ctx context.Context, source, key string, err error) {
+//	        logger.Warn("extend visibility", "key", key, "error", err)
+//	    }),
+//	))
+func WithLeaseHeartbeat(interval, extension time.Duration, opts ...HeartbeatOption) Option {
+	cfg := &heartbeatConfig{interval: interval, extension: extension}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return func(r *Router) {
+		r.heartbeat = cfg
+	}
+}
+
+// startHeartbeat extends msg's lease periodically until the returned
+// function is called, if the router has a heartbeat and msg has a lease.
+func (r *Router) startHeartbeat(ctx context.Context, sourceName string, msg Message) (stop func()) {
+	cfg := r.heartbeat
+	if cfg == nil || cfg.interval <= 0 || msg.Lease == nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(cfg.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := msg.Lease.ExtendLease(ctx, cfg.extension); err != nil {
+				if cfg.onError != nil {
+					cfg.onError(ctx, sourceName, msg.Key, err)
+				}
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
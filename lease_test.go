@@ -0,0 +1,134 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// recordingLease records the extensions it is asked for.
+type recordingLease struct {
+	mu   sync.Mutex
+	ext  []time.Duration
+	fail error
+}
+
+func (l *recordingLease) ExtendLease(ctx context.Context, d time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ext = append(l.ext, d)
+	return l.fail
+}
+
+func (l *recordingLease) extensions() []time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]time.Duration(nil), l.ext...)
+}
+
+type LeaseSuite struct {
+	suite.Suite
+	lease *recordingLease
+}
+
+func TestLeaseSuite(t *testing.T) {
+	suite.Run(t, new(LeaseSuite))
+}
+
+func (s *LeaseSuite) SetupTest() {
+	s.lease = &recordingLease{}
+}
+
+// leaseRouter returns a router whose source sets the suite's lease on every
+// message, and whose handler runs fn.
+func (s *LeaseSuite) leaseRouter(fn func(ctx context.Context) error, opts ...Option) *Router {
+	r := New(opts...)
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Lease: s.lease, Payload: []byte(`{}`)}, nil
+	}))
+	RegisterProcFunc(r, "test", func(ctx context.Context, p struct{}) error {
+		return fn(ctx)
+	})
+	return r
+}
+
+func (s *LeaseSuite) process(r *Router) error {
+	return r.Process(context.Background(), []byte(`{"type": "test"}`))
+}
+
+func (s *LeaseSuite) TestHandlerExtendsLease() {
+	r := s.leaseRouter(func(ctx context.Context) error {
+		return ExtendLease(ctx, time.Minute)
+	})
+
+	s.Require().NoError(s.process(r))
+	s.Assert().Equal([]time.Duration{time.Minute}, s.lease.extensions())
+}
+
+func (s *LeaseSuite) TestExtendLeaseWithoutLease() {
+	s.Assert().NoError(ExtendLease(context.Background(), time.Minute))
+
+	r := New()
+	r.AddSource(&testSource{name: "test"})
+	RegisterProcFunc(r, "test", func(ctx context.Context, p testPayload) error {
+		return ExtendLease(ctx, time.Minute)
+	})
+	s.Assert().NoError(r.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`)))
+}
+
+func (s *LeaseSuite) TestExtendLeaseError() {
+	s.lease.fail = errors.New("receipt handle expired")
+	r := s.leaseRouter(func(ctx context.Context) error {
+		return ExtendLease(ctx, time.Minute)
+	})
+
+	s.Assert().ErrorIs(s.process(r), s.lease.fail)
+}
+
+func (s *LeaseSuite) TestHeartbeatWhileHandlerRuns() {
+	r := s.leaseRouter(func(ctx context.Context) error {
+		time.Sleep(55 * time.Millisecond)
+		return nil
+	}, WithLeaseHeartbeat(10*time.Millisecond, time.Minute))
+
+	s.Require().NoError(s.process(r))
+	got := s.lease.extensions()
+	s.Assert().GreaterOrEqual(len(got), 3)
+	s.Assert().Equal(time.Minute, got[0])
+
+	n := len(got)
+	time.Sleep(30 * time.Millisecond)
+	s.Assert().Len(s.lease.extensions(), n, "the heartbeat stops when the handler returns")
+}
+
+func (s *LeaseSuite) TestHeartbeatStopsOnError() {
+	s.lease.fail = errors.New("receipt handle expired")
+	var mu sync.Mutex
+	var errs []error
+	r := s.leaseRouter(func(ctx context.Context) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}, WithLeaseHeartbeat(10*time.Millisecond, time.Minute, WithHeartbeatErrors(func(ctx context.Context, source, key string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	})))
+
+	s.Require().NoError(s.process(r), "heartbeat failures don't fail the message")
+	s.Assert().Len(s.lease.extensions(), 1)
+	mu.Lock()
+	defer mu.Unlock()
+	s.Require().Len(errs, 1)
+	s.Assert().ErrorIs(errs[0], s.lease.fail)
+}
+
+func (s *LeaseSuite) TestNoHeartbeatForFastHandlers() {
+	r := s.leaseRouter(func(ctx context.Context) error { return nil }, WithLeaseHeartbeat(time.Hour, time.Hour))
+
+	s.Require().NoError(s.process(r))
+	s.Assert().Empty(s.lease.extensions())
+}
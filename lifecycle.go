@@ -0,0 +1,110 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Starter is an optional interface for registered handlers that need to
+// acquire resources, such as connections or warm caches, before processing.
+// Router.Start calls Start on every registered Starter.
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Closer is an optional interface for registered handlers that own resources
+// to release. Router.Shutdown calls Close on every registered Closer once
+// in-flight messages have finished.
+type Closer interface {
+	Close(ctx context.Context) error
+}
+
+// managedHandler is a registered handler value, kept so its lifecycle
+// methods can be called.
+type managedHandler struct {
+	key     string
+	handler any
+}
+
+// manage records h as the handler for key, replacing any earlier handler
+// registered for the same key.
+func (r *Router) manage(key string, h any) {
+	_, isStarter := h.(Starter)
+	_, isCloser := h.(Closer)
+	r.managed = slices.DeleteFunc(r.managed, func(m managedHandler) bool {
+		return m.key == key
+	})
+	if isStarter || isCloser {
+		r.managed = append(r.managed, managedHandler{key: key, handler: h})
+	}
+}
+
+// lifecycleHandlers returns the managed handlers in registration order,
+// with handlers registered under several keys listed once.
+func (r *Router) lifecycleHandlers() []managedHandler {
+	var out []managedHandler
+	for _, m := range r.managed {
+		// Interface comparison panics for values of the same non-comparable
+		// type, such as a struct holding a slice.
+		if reflect.TypeOf(m.handler).Comparable() && slices.ContainsFunc(out, func(o managedHandler) bool {
+			return o.handler == m.handler
+		}) {
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// Start initializes every source that implements Initializer, then calls
+// Start on every registered handler that implements Starter, in
+// registration order. A handler registered under several keys is started
+// once. If a source fails to initialize, Start returns its error without
+// starting handlers. If a handler fails to start, handlers already started
+// that implement Closer are closed in reverse order and the error is
+// returned.
+//
+// Start is optional: call it after registering handlers and before
+// processing messages when handlers implement Starter or sources should be
+// initialized up front.
+//
+// Example:
+//
+//	if err := r.Start(ctx); err != nil {
+//	    return err
+//	}
+//	defer r.Shutdown(context.Background())
+func (r *Router) Start(ctx context.Context) error {
+	if err := r.initSources(ctx); err != nil {
+		return err
+	}
+	handlers := r.lifecycleHandlers()
+	for i, m := range handlers {
+		s, ok := m.handler.(Starter)
+		if !ok {
+			continue
+		}
+		if err := s.Start(ctx); err != nil {
+			err = fmt.Errorf("start handler %s: %w", m.key, err)
+			return errors.Join(err, closeHandlers(ctx, handlers[:i]))
+		}
+	}
+	return nil
+}
+
+// closeHandlers calls Close on every handler that implements Closer, in
+// reverse order, and joins the errors.
+func closeHandlers(ctx context.Context, handlers []managedHandler) error {
+	var errs []error
+	for _, m := range slices.Backward(handlers) {
+		if c, ok := m.handler.(Closer); ok {
+			if err := c.Close(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("close handler %s: %w", m.key, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
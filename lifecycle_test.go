@@ -0,0 +1,124 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// lifecycleHandler is a Proc that records Start and Close calls.
+type lifecycleHandler struct {
+	name     string
+	events   *[]string
+	startErr error
+	closeErr error
+}
+
+func (h *lifecycleHandler) Run(ctx context.Context, payload struct{}) error { return nil }
+
+func (h *lifecycleHandler) Start(ctx context.Context) error {
+	*h.events = append(*h.events, "start "+h.name)
+	return h.startErr
+}
+
+func (h *lifecycleHandler) Close(ctx context.Context) error {
+	*h.events = append(*h.events, "close "+h.name)
+	return h.closeErr
+}
+
+// startOnlyHandler is a Func with a Start method but no Close method.
+type startOnlyHandler struct {
+	events *[]string
+}
+
+func (h startOnlyHandler) Call(ctx context.Context, payload struct{}) (struct{}, error) {
+	return struct{}{}, nil
+}
+
+func (h startOnlyHandler) Start(ctx context.Context) error {
+	*h.events = append(*h.events, "start func")
+	return nil
+}
+
+type LifecycleSuite struct {
+	suite.Suite
+	router *Router
+	events []string
+}
+
+func TestLifecycleSuite(t *testing.T) {
+	suite.Run(t, new(LifecycleSuite))
+}
+
+func (s *LifecycleSuite) SetupTest() {
+	s.router = New()
+	s.events = nil
+}
+
+func (s *LifecycleSuite) handler(name string) *lifecycleHandler {
+	return &lifecycleHandler{name: name, events: &s.events}
+}
+
+func (s *LifecycleSuite) TestStartAndShutdownInOrder() {
+	a, b := s.handler("a"), s.handler("b")
+	RegisterProc[struct{}](s.router, "a", a)
+	RegisterFunc[struct{}, struct{}](s.router, "f", startOnlyHandler{events: &s.events})
+	RegisterProc[struct{}](s.router, "b", b)
+	RegisterProcFunc(s.router, "plain", func(ctx context.Context, p struct{}) error { return nil })
+
+	s.Require().NoError(s.router.Start(context.Background()))
+	s.Require().NoError(s.router.Shutdown(context.Background()))
+
+	s.Assert().Equal([]string{"start a", "start func", "start b", "close b", "close a"}, s.events)
+}
+
+func (s *LifecycleSuite) TestSharedHandlerStartsOnce() {
+	a := s.handler("a")
+	RegisterProc[struct{}](s.router, "one", a)
+	RegisterProc[struct{}](s.router, "two", a)
+
+	s.Require().NoError(s.router.Start(context.Background()))
+	s.Require().NoError(s.router.Shutdown(context.Background()))
+
+	s.Assert().Equal([]string{"start a", "close a"}, s.events)
+}
+
+func (s *LifecycleSuite) TestReplacedHandlerIsNotManaged() {
+	RegisterProc[struct{}](s.router, "key", s.handler("old"))
+	RegisterProc[struct{}](s.router, "key", s.handler("new"))
+
+	s.Require().NoError(s.router.Start(context.Background()))
+
+	s.Assert().Equal([]string{"start new"}, s.events)
+}
+
+func (s *LifecycleSuite) TestStartFailureClosesStartedHandlers() {
+	errStart := errors.New("no connection")
+	failing := s.handler("b")
+	failing.startErr = errStart
+	RegisterProc[struct{}](s.router, "a", s.handler("a"))
+	RegisterProc[struct{}](s.router, "b", failing)
+	RegisterProc[struct{}](s.router, "c", s.handler("c"))
+
+	err := s.router.Start(context.Background())
+
+	s.Assert().ErrorIs(err, errStart)
+	s.Assert().EqualError(err, "start handler b: no connection")
+	s.Assert().Equal([]string{"start a", "start b", "close a"}, s.events)
+}
+
+func (s *LifecycleSuite) TestShutdownJoinsCloseErrorsAndClosesOnce() {
+	errClose := errors.New("flush failed")
+	a := s.handler("a")
+	a.closeErr = errClose
+	RegisterProc[struct{}](s.router, "a", a)
+	RegisterProc[struct{}](s.router, "b", s.handler("b"))
+
+	err := s.router.Shutdown(context.Background())
+	s.Assert().EqualError(err, "close handler a: flush failed")
+	s.Require().NoError(s.router.Shutdown(context.Background()))
+
+	s.Assert().Equal([]string{"close b", "close a"}, s.events)
+}
@@ -0,0 +1,169 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// loopbackField marks messages sent by a Loopback.
+const loopbackField = "dispatch_loopback"
+
+// loopbackReplyTo is the reply address of Calls made through a Loopback.
+const loopbackReplyTo = "loopback"
+
+// loopbackEnvelope is the wire format of Loopback messages.
+type loopbackEnvelope struct {
+	Loopback      bool              `json:"dispatch_loopback"`
+	Key           string            `json:"key"`
+	MessageID     string            `json:"id,omitempty"`
+	CorrelationID string            `json:"correlation_id,omitempty"`
+	ReplyTo       string            `json:"reply_to,omitempty"`
+	Attributes    map[string]string `json:"attributes,omitempty"`
+	Time          time.Time         `json:"time,omitzero"`
+	Payload       json.RawMessage   `json:"payload"`
+}
+
+// LoopbackOption configures a Loopback.
+type LoopbackOption func(*Loopback)
+
+// LoopbackAsync makes Send queue events on the router's workers with Submit
+// instead of processing them before returning, like a broker would. Start
+// the workers with StartWorkers. Send then only reports events that could
+// not be queued; processing failures go to the router's hooks.
+func LoopbackAsync() LoopbackOption {
+	return func(l *Loopback) {
+		l.async = true
+	}
+}
+
+// Loopback is a Transport that delivers events straight into a Router, so
+// publishers, clients, and handlers run in one process without a broker,
+// for local development and integration tests. NewLoopback adds a source
+// for its events to the router.
+//
+// Example:
+//
+//	r := dispatch.New()
+//	dispatch.RegisterProcTyped(r, &UserCreatedProc{})
+//	pub := dispatch.NewPublisher(dispatch.DefaultRegistry, dispatch.NewLoopback(r))
+//	err := dispatch.Publish(ctx, pub, UserCreated{ID: "42"}) // runs UserCreatedProc
+type Loopback struct {
+	r     *Router
+	async bool
+
+	mu      sync.Mutex
+	clients []*Client
+}
+
+// NewLoopback returns a Loopback that delivers to r, and adds its source to
+// r. Call it while configuring r, before processing messages.
+func NewLoopback(r *Router, opts ...LoopbackOption) *Loopback {
+	l := &Loopback{r: r}
+	for _, opt := range opts {
+		opt(l)
+	}
+	r.AddSource(&loopbackSource{l: l})
+	return l
+}
+
+// Send implements the Transport interface. Unless LoopbackAsync is set, it
+// processes the event before returning and returns the result of Process.
+func (l *Loopback) Send(ctx context.Context, e Event) error {
+	raw, err := json.Marshal(loopbackEnvelope{
+		Loopback:      true,
+		Key:           e.Key,
+		MessageID:     e.MessageID,
+		CorrelationID: e.CorrelationID,
+		ReplyTo:       e.ReplyTo,
+		Attributes:    e.Attributes,
+		Time:          e.Time,
+		Payload:       e.Payload,
+	})
+	if err != nil {
+		return err
+	}
+	if !l.async {
+		return l.r.Process(ctx, raw)
+	}
+
+	// The event outlives Send, as it would on a broker.
+	result := l.r.Submit(context.WithoutCancel(ctx), raw)
+	select {
+	case err := <-result:
+		if errors.Is(err, ErrWorkersStopped) || errors.Is(err, ErrShutdown) {
+			return err
+		}
+	default:
+	}
+	return nil
+}
+
+// NewClient returns a Client that sends requests through the loopback and
+// receives the results of their handlers directly.
+func (l *Loopback) NewClient(reg *Registry) *Client {
+	c := NewClient(reg, l, loopbackReplyTo)
+	l.mu.Lock()
+	l.clients = append(l.clients, c)
+	l.mu.Unlock()
+	return c
+}
+
+// deliver passes a reply to the client waiting for correlationID.
+func (l *Loopback) deliver(correlationID string, result json.RawMessage, err error) {
+	l.mu.Lock()
+	clients := l.clients
+	l.mu.Unlock()
+	for _, c := range clients {
+		if c.Deliver(correlationID, result, err) {
+			return
+		}
+	}
+}
+
+// loopbackSource parses messages sent by a Loopback.
+type loopbackSource struct {
+	l *Loopback
+}
+
+func (s *loopbackSource) Name() string { return "loopback" }
+
+func (s *loopbackSource) Discriminator() Discriminator { return FieldTrue(loopbackField) }
+
+func (s *loopbackSource) Parse(raw []byte) (Message, error) {
+	var env loopbackEnvelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return Message{}, err
+	}
+	msg := Message{
+		Key:           env.Key,
+		MessageID:     env.MessageID,
+		CorrelationID: env.CorrelationID,
+		Timestamp:     env.Time,
+		Payload:       env.Payload,
+		Attributes:    env.Attributes,
+		ReplyTo:       env.ReplyTo,
+	}
+	if env.ReplyTo == loopbackReplyTo {
+		msg.Replier = &loopbackReplier{l: s.l, correlationID: env.CorrelationID}
+	}
+	return msg, nil
+}
+
+// loopbackReplier delivers a handler's result to the Loopback's clients.
+type loopbackReplier struct {
+	l             *Loopback
+	correlationID string
+}
+
+func (r *loopbackReplier) Reply(_ context.Context, result json.RawMessage) error {
+	r.l.deliver(r.correlationID, result, nil)
+	return nil
+}
+
+func (r *loopbackReplier) Fail(_ context.Context, err error) error {
+	r.l.deliver(r.correlationID, nil, &RemoteError{Message: err.Error()})
+	return nil
+}
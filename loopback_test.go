@@ -0,0 +1,99 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LoopbackSuite struct {
+	suite.Suite
+	reg *Registry
+	r   *Router
+}
+
+func TestLoopbackSuite(t *testing.T) {
+	suite.Run(t, new(LoopbackSuite))
+}
+
+func (s *LoopbackSuite) SetupTest() {
+	s.reg = NewRegistry()
+	Register[testPayload](s.reg, "test")
+	s.r = New()
+}
+
+func (s *LoopbackSuite) TestPublishRunsHandler() {
+	var msg Message
+	s.r = New(WithOnDispatch(func(ctx context.Context, source, key string) {
+		msg, _ = MessageFromContext(ctx)
+	}))
+	handler := &testHandler{}
+	RegisterProc(s.r, "test", handler)
+	pub := NewPublisher(s.reg, NewLoopback(s.r))
+
+	err := Publish(context.Background(), pub, testPayload{Value: "x"}, WithEventID("m-1"), WithEventAttributes(map[string]string{"a": "b"}))
+
+	s.Require().NoError(err)
+	s.Assert().True(handler.called)
+	s.Assert().Equal("x", handler.payload.Value)
+	s.Assert().Equal("m-1", msg.MessageID)
+	s.Assert().Equal(map[string]string{"a": "b"}, msg.Attributes)
+	s.Assert().False(msg.Timestamp.IsZero())
+}
+
+func (s *LoopbackSuite) TestSyncReturnsHandlerError() {
+	RegisterProc(s.r, "test", &testHandler{err: errors.New("boom")})
+	pub := NewPublisher(s.reg, NewLoopback(s.r))
+
+	s.Assert().ErrorContains(Publish(context.Background(), pub, testPayload{}), "boom")
+}
+
+func (s *LoopbackSuite) TestAsyncQueuesOnWorkers() {
+	done := make(chan testPayload, 1)
+	RegisterProcFunc(s.r, "test", func(ctx context.Context, p testPayload) error {
+		done <- p
+		return errors.New("not reported")
+	})
+	pub := NewPublisher(s.reg, NewLoopback(s.r, LoopbackAsync()))
+	s.r.StartWorkers(1)
+	defer s.r.StopWorkers(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.Require().NoError(Publish(ctx, pub, testPayload{Value: "x"}))
+	cancel()
+
+	select {
+	case p := <-done:
+		s.Assert().Equal("x", p.Value)
+	case <-time.After(time.Second):
+		s.Fail("handler not called")
+	}
+}
+
+func (s *LoopbackSuite) TestAsyncReportsStoppedWorkers() {
+	pub := NewPublisher(s.reg, NewLoopback(s.r, LoopbackAsync()))
+
+	s.Assert().ErrorIs(Publish(context.Background(), pub, testPayload{}), ErrWorkersStopped)
+}
+
+func (s *LoopbackSuite) TestClientCall() {
+	RegisterFuncFunc(s.r, "test", func(ctx context.Context, p testPayload) (testPayload, error) {
+		if p.Value == "" {
+			return testPayload{}, errors.New("empty")
+		}
+		return testPayload{Value: p.Value + "!"}, nil
+	})
+	client := NewLoopback(s.r).NewClient(s.reg)
+
+	got, err := Call[testPayload, testPayload](context.Background(), client, testPayload{Value: "hi"})
+	s.Require().NoError(err)
+	s.Assert().Equal("hi!", got.Value)
+
+	_, err = Call[testPayload, testPayload](context.Background(), client, testPayload{})
+	var remote *RemoteError
+	s.Require().ErrorAs(err, &remote)
+	s.Assert().Contains(remote.Message, "empty")
+}
@@ -0,0 +1,222 @@
+package dispatch
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+)
+
+// MapView returns a View over a decoded map, such as the result of
+// unmarshaling JSON into map[string]any. Use it to unit test discriminators
+// and sources without constructing JSON strings, or to synthesize views for
+// transports that do not deliver raw bytes.
+//
+// Nested objects are map[string]any and arrays are []any. Paths follow the
+// same rules as JSONInspector, including array indexes and Path escaping.
+//
+// GetInt follows JSONInspector's integer-only rules: it accepts Go integer
+// types within the int64 range and json.Number integer literals, but not
+// floats, since a float64 such as 1.0 does not record whether the JSON it
+// came from was an integer. Decode with json.Decoder.UseNumber to read
+// integers from unmarshaled JSON.
+//
+// Example:
+//
+//	v := dispatch.MapView(map[string]any{
+//	    "source": "my.app",
+//	    "detail": map[string]any{"userId": "123"},
+//	})
+//	dispatch.HasFields("detail.userId").Match(v) // true
+func MapView(m map[string]any) View {
+	return mapView{val: m}
+}
+
+// mapView is a View over a decoded value: a map at the top level, or any
+// element of an array returned by Elements.
+type mapView struct {
+	val any
+}
+
+// lookup walks the path through nested maps and slices.
+func (v mapView) lookup(path string) (any, bool) {
+	cur := v.val
+	for _, seg := range SplitPath(path) {
+		switch node := cur.(type) {
+		case map[string]any:
+			next, ok := node[seg]
+			if !ok {
+				return nil, false
+			}
+			cur = next
+		case []any:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			cur = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func (v mapView) HasField(path string) bool {
+	_, ok := v.lookup(path)
+	return ok
+}
+
+func (v mapView) GetString(path string) (string, bool) {
+	val, ok := v.lookup(path)
+	if !ok {
+		return "", false
+	}
+	s, ok := val.(string)
+	return s, ok
+}
+
+func (v mapView) GetBytes(path string) ([]byte, bool) {
+	val, ok := v.lookup(path)
+	if !ok {
+		return nil, false
+	}
+	b, err := json.Marshal(val)
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+func (v mapView) GetInt(path string) (int64, bool) {
+	val, ok := v.lookup(path)
+	if !ok {
+		return 0, false
+	}
+	switch n := val.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint:
+		return uintToInt64(uint64(n))
+	case uint8:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	case uint64:
+		return uintToInt64(n)
+	case json.Number:
+		i, err := strconv.ParseInt(string(n), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return i, true
+	}
+	return 0, false
+}
+
+// uintToInt64 converts n to an int64 if it fits.
+func uintToInt64(n uint64) (int64, bool) {
+	if n > math.MaxInt64 {
+		return 0, false
+	}
+	return int64(n), true
+}
+
+func (v mapView) GetFloat(path string) (float64, bool) {
+	val, ok := v.lookup(path)
+	if !ok {
+		return 0, false
+	}
+	switch n := val.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func (v mapView) GetBool(path string) (bool, bool) {
+	val, ok := v.lookup(path)
+	if !ok {
+		return false, false
+	}
+	b, ok := val.(bool)
+	return b, ok
+}
+
+func (v mapView) GetTime(path string) (time.Time, bool) {
+	val, ok := v.lookup(path)
+	if !ok {
+		return time.Time{}, false
+	}
+	switch t := val.(type) {
+	case time.Time:
+		return t, true
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, t)
+		return parsed, err == nil
+	}
+	return time.Time{}, false
+}
+
+func (v mapView) Elements(path string) ([]View, bool) {
+	val, ok := v.lookup(path)
+	if !ok {
+		return nil, false
+	}
+	arr, ok := val.([]any)
+	if !ok {
+		return nil, false
+	}
+	views := make([]View, 0, len(arr))
+	for _, elem := range arr {
+		views = append(views, mapView{val: elem})
+	}
+	return views, true
+}
+
+func (v mapView) GetView(path string) (View, bool) {
+	val, ok := v.lookup(path)
+	if !ok {
+		return nil, false
+	}
+	m, ok := val.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	return mapView{val: m}, true
+}
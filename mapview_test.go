@@ -0,0 +1,177 @@
+package dispatch
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MapViewSuite struct {
+	suite.Suite
+	view View
+}
+
+func (s *MapViewSuite) SetupTest() {
+	s.view = MapView(map[string]any{
+		"source":   "my.app",
+		"count":    42,
+		"ratio":    1.5,
+		"active":   true,
+		"time":     "2024-01-15T10:30:00Z",
+		"app.name": "billing",
+		"detail": map[string]any{
+			"userId": "123",
+		},
+		"Records": []any{
+			map[string]any{"eventSource": "aws:sqs"},
+			map[string]any{"eventSource": "aws:s3"},
+		},
+	})
+}
+
+func TestMapViewSuite(t *testing.T) {
+	suite.Run(t, new(MapViewSuite))
+}
+
+func (s *MapViewSuite) TestHasField() {
+	tests := map[string]struct {
+		path   string
+		exists bool
+	}{
+		"top level":     {"source", true},
+		"nested":        {"detail.userId", true},
+		"array index":   {"Records.1.eventSource", true},
+		"escaped":       {Path("app.name"), true},
+		"missing":       {"missing", false},
+		"nested miss":   {"detail.missing", false},
+		"out of range":  {"Records.2", false},
+		"through value": {"source.x", false},
+	}
+
+	for name, tt := range tests {
+		s.Run(name, func() {
+			s.Assert().Equal(tt.exists, s.view.HasField(tt.path))
+		})
+	}
+}
+
+func (s *MapViewSuite) TestGetString() {
+	val, ok := s.view.GetString("detail.userId")
+	s.Require().True(ok)
+	s.Assert().Equal("123", val)
+
+	_, ok = s.view.GetString("count")
+	s.Assert().False(ok)
+}
+
+func (s *MapViewSuite) TestGetBytes() {
+	val, ok := s.view.GetBytes("detail")
+	s.Require().True(ok)
+	s.Assert().JSONEq(`{"userId": "123"}`, string(val))
+
+	val, ok = s.view.GetBytes("source")
+	s.Require().True(ok)
+	s.Assert().Equal(`"my.app"`, string(val))
+}
+
+func (s *MapViewSuite) TestTypedGetters() {
+	n, ok := s.view.(NumberView).GetInt("count")
+	s.Require().True(ok)
+	s.Assert().Equal(int64(42), n)
+
+	_, ok = s.view.(NumberView).GetInt("ratio")
+	s.Assert().False(ok)
+
+	f, ok := s.view.(NumberView).GetFloat("ratio")
+	s.Require().True(ok)
+	s.Assert().InDelta(1.5, f, 0)
+
+	b, ok := s.view.(BoolView).GetBool("active")
+	s.Require().True(ok)
+	s.Assert().True(b)
+
+	tm, ok := s.view.(TimeView).GetTime("time")
+	s.Require().True(ok)
+	s.Assert().Equal(time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), tm)
+}
+
+func (s *MapViewSuite) TestGetIntIsIntegerOnly() {
+	view := MapView(map[string]any{
+		"int8":        int8(-3),
+		"uint64":      uint64(7),
+		"uint64 big":  uint64(math.MaxUint64),
+		"number":      json.Number("12"),
+		"number frac": json.Number("1.0"),
+		"number big":  json.Number("9223372036854775808"),
+		"float":       1.0,
+		"float big":   1e300,
+	}).(NumberView)
+
+	tests := map[string]struct {
+		want int64
+		ok   bool
+	}{
+		"int8":        {-3, true},
+		"uint64":      {7, true},
+		"uint64 big":  {0, false},
+		"number":      {12, true},
+		"number frac": {0, false},
+		"number big":  {0, false},
+		"float":       {0, false},
+		"float big":   {0, false},
+	}
+
+	for path, tt := range tests {
+		s.Run(path, func() {
+			n, ok := view.GetInt(Path(path))
+			s.Assert().Equal(tt.ok, ok)
+			s.Assert().Equal(tt.want, n)
+		})
+	}
+}
+
+func (s *MapViewSuite) TestNestedAndArrayViews() {
+	detail, ok := GetView(s.view, "detail")
+	s.Require().True(ok)
+	s.Assert().True(FieldEquals("userId", "123").Match(detail))
+
+	d := AnyElementMatches("Records", FieldEquals("eventSource", "aws:s3"))
+	s.Assert().True(d.Match(s.view))
+}
+
+func (s *MapViewSuite) TestGetFloatAcceptsIntegerTypes() {
+	values := map[string]any{
+		"int": 1, "int8": int8(1), "int16": int16(1), "int32": int32(1), "int64": int64(1),
+		"uint": uint(1), "uint8": uint8(1), "uint16": uint16(1), "uint32": uint32(1), "uint64": uint64(1),
+	}
+	view := MapView(values)
+
+	for path := range values {
+		s.Run(path, func() {
+			f, ok := view.(NumberView).GetFloat(path)
+			s.Require().True(ok)
+			s.Assert().InDelta(1.0, f, 0)
+			s.Assert().True(FieldGreaterThan(path, 0.5).Match(view))
+		})
+	}
+}
+
+func (s *MapViewSuite) TestElementsWrapEveryElement() {
+	raw := `{"matrix": [["a", "b"]], "tags": ["x", 1]}`
+	var m map[string]any
+	s.Require().NoError(json.Unmarshal([]byte(raw), &m))
+	jsonView, err := JSONInspector().Inspect([]byte(raw))
+	s.Require().NoError(err)
+
+	for name, view := range map[string]View{"map": MapView(m), "json": jsonView} {
+		s.Run(name, func() {
+			s.Assert().True(AnyElementMatches("matrix", FieldEquals("1", "b")).Match(view))
+			elems, ok := view.(ArrayView).Elements("tags")
+			s.Require().True(ok)
+			s.Assert().Len(elems, 2)
+		})
+	}
+}
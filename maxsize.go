@@ -0,0 +1,77 @@
+package dispatch
+
+import (
+	"context"
+	"fmt"
+)
+
+// OnOversizeFunc is called when a message is larger than the limit set with
+// WithMaxPayloadSize. For a raw message rejected before matching, source and
+// key are empty. size is the length in bytes that exceeded the limit.
+// Return nil to skip the message, return an error to fail.
+type OnOversizeFunc func(ctx context.Context, source, key string, size int) error
+
+// WithMaxPayloadSize rejects messages larger than n bytes before they are
+// inspected or unmarshaled, so a pathological input can't make the router
+// allocate without bound. The raw message is checked before matching, at
+// StageMatch, and the payload extracted by the source is checked before the
+// handler is looked up, at StageUnmarshal, since sources may decode or
+// decompress it.
+//
+// Oversize messages fail with an error wrapping ErrOversize, unless a
+// WithOnOversize hook skips them. n <= 0 means no limit.
+//
+// Example:
+//
+//	r := dispatch.New(
+//	    dispatch.WithMaxPayloadSize(256<<10), // SQS's maximum message size
+//	    dispatch.WithOnOversize(func(ctx context.Context, source, key string, size int) error {
+//	        logger.Warn("dropping oversize message", "key", key, "size", size)
+//	        return nil // skip
+//	    }),
+//	)
+func WithMaxPayloadSize(n int) Option {
+	return func(r *Router) {
+		r.maxPayloadSize = n
+	}
+}
+
+// WithOnOversize adds a hook called when a message is rejected by
+// WithMaxPayloadSize. Return nil to skip, return an error to fail.
+// Multiple hooks are all called in order, and their errors are combined as
+// set by WithHookErrorPolicy: by default, the first error is returned.
+func WithOnOversize(fn OnOversizeFunc) Option {
+	return func(r *Router) {
+		r.hooks.onOversize = append(r.hooks.onOversize, fn)
+	}
+}
+
+// oversize reports whether size exceeds the router's maximum payload size.
+func (r *Router) oversize(size int) bool {
+	return r.maxPayloadSize > 0 && size > r.maxPayloadSize
+}
+
+// handleOversize handles a message or payload of size bytes that exceeds
+// the maximum payload size.
+func (r *Router) handleOversize(ctx context.Context, stage Stage, sourceName, key string, size int, replier Replier) error {
+	cause := fmt.Errorf("%w: %d bytes exceeds %d", ErrOversize, size, r.maxPayloadSize)
+
+	ran, errs := runPolicyHooks(r.hooks.onOversize, func(fn OnOversizeFunc) error {
+		return fn(ctx, sourceName, key, size)
+	})
+
+	var resultErr error
+	switch {
+	case len(errs) > 0:
+		resultErr = r.combineHookErrors(errs)
+	case ran == 0:
+		resultErr = cause
+	default:
+		resultErr = r.callOnSkip(ctx, stage, sourceName, key, cause)
+	}
+
+	if resultErr != nil && replier != nil {
+		return r.fail(ctx, replier, resultErr)
+	}
+	return resultErr
+}
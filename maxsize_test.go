@@ -0,0 +1,170 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MaxPayloadSizeSuite struct {
+	suite.Suite
+	handled bool
+}
+
+func TestMaxPayloadSizeSuite(t *testing.T) {
+	suite.Run(t, new(MaxPayloadSizeSuite))
+}
+
+func (s *MaxPayloadSizeSuite) SetupTest() {
+	s.handled = false
+}
+
+// router returns a router whose source extracts payload, regardless of the
+// raw message.
+func (s *MaxPayloadSizeSuite) router(payload string, opts ...Option) *Router {
+	r := New(opts...)
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(payload)}, nil
+	}))
+	RegisterProcFunc(r, "test", func(ctx context.Context, p struct{}) error {
+		s.handled = true
+		return nil
+	})
+	return r
+}
+
+func (s *MaxPayloadSizeSuite) TestRejectsOversizeRawMessage() {
+	r := s.router(`{}`, WithMaxPayloadSize(16))
+
+	err := r.Process(context.Background(), []byte(`{"type": "test", "padding": "xxxxxxxx"}`))
+
+	s.Assert().ErrorIs(err, ErrOversize)
+	var derr *DispatchError
+	s.Require().ErrorAs(err, &derr)
+	s.Assert().Equal(StageMatch, derr.Stage)
+	s.Assert().False(s.handled)
+}
+
+func (s *MaxPayloadSizeSuite) TestRejectsOversizePayload() {
+	r := s.router(`{"padding": "`+strings.Repeat("x", 64)+`"}`, WithMaxPayloadSize(32))
+
+	err := r.Process(context.Background(), []byte(`{"type": "test"}`))
+
+	s.Assert().ErrorIs(err, ErrOversize)
+	var derr *DispatchError
+	s.Require().ErrorAs(err, &derr)
+	s.Assert().Equal(StageUnmarshal, derr.Stage)
+	s.Assert().Equal("test", derr.Key)
+	s.Assert().False(s.handled)
+	s.Assert().Equal(uint64(1), r.Stats().Keys["test"].Failed)
+}
+
+func (s *MaxPayloadSizeSuite) TestWithinLimit() {
+	r := s.router(`{}`, WithMaxPayloadSize(64))
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test"}`)))
+	s.Assert().True(s.handled)
+}
+
+func (s *MaxPayloadSizeSuite) TestNoLimitByDefault() {
+	r := s.router(`{"padding": "` + strings.Repeat("x", 1<<20) + `"}`)
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test"}`)))
+	s.Assert().True(s.handled)
+}
+
+func (s *MaxPayloadSizeSuite) TestHookSkips() {
+	var gotKey string
+	var gotSize int
+	var dead []DeadLetter
+	r := s.router(`{"padding": "xxxxxxxxxxxxxxxx"}`,
+		WithMaxPayloadSize(16),
+		WithOnOversize(func(ctx context.Context, source, key string, size int) error {
+			gotKey, gotSize = key, size
+			return nil
+		}),
+		WithDeadLetterer(DeadLettererFunc(func(ctx context.Context, dl DeadLetter) error {
+			dead = append(dead, dl)
+			return nil
+		})),
+	)
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test"}`)))
+	s.Assert().Equal("test", gotKey)
+	s.Assert().Equal(31, gotSize)
+	s.Require().Len(dead, 1)
+	s.Assert().ErrorIs(dead[0].Err, ErrOversize)
+	s.Assert().False(s.handled)
+}
+
+func (s *MaxPayloadSizeSuite) TestHookFails() {
+	boom := errors.New("too big")
+	r := s.router(`{}`,
+		WithMaxPayloadSize(8),
+		WithOnOversize(func(ctx context.Context, source, key string, size int) error {
+			s.Assert().Empty(source, "raw messages are checked before matching")
+			return boom
+		}),
+	)
+
+	s.Assert().ErrorIs(r.Process(context.Background(), []byte(`{"type": "test"}`)), boom)
+}
+
+func (s *MaxPayloadSizeSuite) TestHookErrorPolicy() {
+	first, second := errors.New("first"), errors.New("second")
+	hooks := []Option{
+		WithMaxPayloadSize(8),
+		WithOnOversize(func(ctx context.Context, source, key string, size int) error { return first }),
+		WithOnOversize(func(ctx context.Context, source, key string, size int) error { return second }),
+	}
+
+	err := s.router(`{}`, hooks...).Process(context.Background(), []byte(`{"type": "test"}`))
+	s.Assert().ErrorIs(err, first)
+	s.Assert().NotErrorIs(err, second)
+
+	err = s.router(`{}`, append(hooks, WithHookErrorPolicy(JoinHookErrors))...).Process(context.Background(), []byte(`{"type": "test"}`))
+	s.Assert().ErrorIs(err, first)
+	s.Assert().ErrorIs(err, second)
+}
+
+func (s *MaxPayloadSizeSuite) TestAbstainingHook() {
+	r := s.router(`{}`,
+		WithMaxPayloadSize(8),
+		WithOnOversize(func(ctx context.Context, source, key string, size int) error {
+			return ErrHookAbstain
+		}),
+	)
+
+	s.Assert().ErrorIs(r.Process(context.Background(), []byte(`{"type": "test"}`)), ErrOversize)
+}
+
+func (s *MaxPayloadSizeSuite) TestOnError() {
+	var stage Stage
+	var gotErr error
+	r := s.router(`{"padding": "xxxxxxxxxxxxxxxx"}`,
+		WithMaxPayloadSize(16),
+		WithOnError(func(ctx context.Context, st Stage, source, key string, err error) error {
+			stage, gotErr = st, err
+			return nil
+		}),
+	)
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test"}`)))
+	s.Assert().Equal(StageUnmarshal, stage)
+	s.Assert().ErrorIs(gotErr, ErrOversize)
+}
+
+func (s *MaxPayloadSizeSuite) TestFailsReplier() {
+	replier := &keysReplier{}
+	r := New(WithMaxPayloadSize(12))
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(`{"value": "too long"}`), Replier: replier}, nil
+	}))
+	RegisterProcFunc(r, "test", func(ctx context.Context, p testPayload) error { return nil })
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type":1}`)))
+	s.Assert().ErrorIs(replier.err, ErrOversize)
+}
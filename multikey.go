@@ -0,0 +1,91 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+)
+
+// routingKeys returns Key, if set, followed by Keys, without duplicates.
+func (m Message) routingKeys() []string {
+	keys := make([]string, 0, len(m.Keys)+1)
+	if m.Key != "" {
+		keys = append(keys, m.Key)
+	}
+	for _, key := range m.Keys {
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// dispatchKeys dispatches a message with several routing keys to each key's
+// handler in turn. Replies are collected and sent as one; keys skipped
+// without a reply, such as disabled ones, are left out of it.
+func (r *Router) dispatchKeys(ctx context.Context, p *parsed) error {
+	replier := p.msg.Replier
+	replies := make([]collectedReply, len(p.msg.Keys))
+
+	var errs []error
+	for i, key := range p.msg.Keys {
+		one := *p
+		one.msg.Key = key
+		if replier != nil {
+			one.msg.Replier = &replies[i]
+		}
+		if err := r.dispatchKey(ctx, &one); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if replier == nil {
+		return errors.Join(errs...)
+	}
+
+	key := p.msg.Key
+	ctx = withMessage(r.withRaw(ctx, p.raw), p.msg)
+	var failed []error
+	results := make(map[string]json.RawMessage, len(replies))
+	for i, rep := range replies {
+		switch {
+		case rep.err != nil:
+			failed = append(failed, rep.err)
+		case rep.result != nil:
+			results[p.msg.Keys[i]] = rep.result
+		}
+	}
+	if len(failed) == 0 && len(results) == 0 {
+		return errors.Join(errs...) // every key was skipped without a reply
+	}
+	var err error
+	if len(failed) > 0 {
+		err = r.fail(ctx, replier, errors.Join(failed...))
+	} else {
+		var result json.RawMessage
+		if result, err = json.Marshal(results); err == nil {
+			err = r.reply(ctx, replier, result)
+		}
+	}
+	if err = r.handleError(ctx, StageReply, p.sourceName, key, err); err != nil {
+		errs = append(errs, dispatchError(StageReply, p.sourceName, key, err))
+	}
+	return errors.Join(errs...)
+}
+
+// collectedReply is the Replier given to each key's handler when a message
+// with several keys has a Replier, so the replies can be combined.
+type collectedReply struct {
+	result json.RawMessage
+	err    error
+}
+
+func (c *collectedReply) Reply(_ context.Context, result json.RawMessage) error {
+	c.result = result
+	return nil
+}
+
+func (c *collectedReply) Fail(_ context.Context, err error) error {
+	c.err = err
+	return nil
+}
@@ -0,0 +1,157 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// keysReplier records the reply to a multi-key message.
+type keysReplier struct {
+	result json.RawMessage
+	err    error
+	calls  int
+}
+
+func (r *keysReplier) Reply(ctx context.Context, result json.RawMessage) error {
+	r.calls++
+	r.result = result
+	return nil
+}
+
+func (r *keysReplier) Fail(ctx context.Context, err error) error {
+	r.calls++
+	r.err = err
+	return nil
+}
+
+type MultiKeySuite struct {
+	suite.Suite
+	router  *Router
+	msg     Message
+	replier *keysReplier
+	calls   []string
+	keys    [][]string
+}
+
+func TestMultiKeySuite(t *testing.T) {
+	suite.Run(t, new(MultiKeySuite))
+}
+
+func (s *MultiKeySuite) SetupTest() {
+	s.msg = Message{Key: "order/updated", Keys: []string{"inventory/recheck"}, Payload: []byte(`{"value": "v"}`)}
+	s.replier = nil
+	s.calls, s.keys = nil, nil
+	s.router = New()
+	s.router.AddSource(SourceFunc("composite", HasFields("composite"), func(raw []byte) (Message, error) {
+		msg := s.msg
+		if s.replier != nil {
+			msg.Replier = s.replier
+		}
+		return msg, nil
+	}))
+	handler := func(ctx context.Context, p testPayload) error {
+		msg, _ := MessageFromContext(ctx)
+		s.calls = append(s.calls, msg.Key)
+		s.keys = append(s.keys, msg.Keys)
+		return nil
+	}
+	RegisterProcFunc(s.router, "order/updated", handler)
+	RegisterProcFunc(s.router, "inventory/recheck", handler)
+}
+
+func (s *MultiKeySuite) process() error {
+	return s.router.Process(context.Background(), []byte(`{"composite": true}`))
+}
+
+func (s *MultiKeySuite) TestDispatchesEachKey() {
+	s.Require().NoError(s.process())
+
+	s.Assert().Equal([]string{"order/updated", "inventory/recheck"}, s.calls)
+	s.Assert().Equal([]string{"order/updated", "inventory/recheck"}, s.keys[0], "handlers see every key")
+}
+
+func (s *MultiKeySuite) TestKeysOnly() {
+	s.msg.Key = ""
+	s.msg.Keys = []string{"inventory/recheck", "order/updated"}
+	s.Require().NoError(s.process())
+
+	s.Assert().Equal([]string{"inventory/recheck", "order/updated"}, s.calls)
+}
+
+func (s *MultiKeySuite) TestDuplicateKeysRunOnce() {
+	s.msg.Keys = []string{"order/updated", "inventory/recheck", "inventory/recheck"}
+	s.Require().NoError(s.process())
+
+	s.Assert().Equal([]string{"order/updated", "inventory/recheck"}, s.calls)
+}
+
+func (s *MultiKeySuite) TestSingleKeyInKeys() {
+	s.msg.Key = ""
+	s.msg.Keys = []string{"order/updated"}
+	s.Require().NoError(s.process())
+
+	s.Assert().Equal([]string{"order/updated"}, s.calls)
+}
+
+func (s *MultiKeySuite) TestFailureDoesNotStopOtherKeys() {
+	boom := errors.New("boom")
+	RegisterProcFunc(s.router, "order/updated", func(ctx context.Context, p testPayload) error { return boom })
+
+	err := s.process()
+	s.Require().ErrorIs(err, boom)
+	var derr *DispatchError
+	s.Require().ErrorAs(err, &derr)
+	s.Assert().Equal("order/updated", derr.Key)
+	s.Assert().Equal([]string{"inventory/recheck"}, s.calls)
+}
+
+func (s *MultiKeySuite) TestMissingHandler() {
+	s.msg.Keys = []string{"unknown", "inventory/recheck"}
+
+	s.Assert().ErrorIs(s.process(), ErrNoHandler)
+	s.Assert().Equal([]string{"order/updated", "inventory/recheck"}, s.calls)
+}
+
+func (s *MultiKeySuite) TestAggregatesReplies() {
+	s.replier = &keysReplier{}
+	RegisterFuncFunc(s.router, "order/updated", func(ctx context.Context, p testPayload) (int, error) {
+		return 42, nil
+	})
+
+	s.Require().NoError(s.process())
+	s.Assert().Equal(1, s.replier.calls)
+	s.Assert().JSONEq(`{"order/updated": 42, "inventory/recheck": {}}`, string(s.replier.result))
+}
+
+func (s *MultiKeySuite) TestAggregatesFailures() {
+	s.replier = &keysReplier{}
+	boom := errors.New("boom")
+	RegisterProcFunc(s.router, "inventory/recheck", func(ctx context.Context, p testPayload) error { return boom })
+
+	s.Require().NoError(s.process(), "the failure was delivered to the replier")
+	s.Assert().Equal(1, s.replier.calls)
+	s.Assert().ErrorIs(s.replier.err, boom)
+}
+
+func (s *MultiKeySuite) TestSkippedKeysLeftOutOfReply() {
+	s.replier = &keysReplier{}
+	RegisterProcFunc(s.router, "inventory/recheck", func(ctx context.Context, p testPayload) error { return nil },
+		WithEnabled(func(ctx context.Context) bool { return false }))
+
+	s.Require().NoError(s.process())
+	s.Assert().JSONEq(`{"order/updated": {}}`, string(s.replier.result))
+}
+
+func (s *MultiKeySuite) TestStats() {
+	s.Require().NoError(s.process())
+
+	stats := s.router.Stats()
+	s.Assert().Equal(uint64(1), stats.Sources["composite"].Matched)
+	s.Assert().Equal(uint64(1), stats.Keys["order/updated"].Processed)
+	s.Assert().Equal(uint64(1), stats.Keys["inventory/recheck"].Processed)
+	s.Assert().Equal(uint64(1), stats.Keys["inventory/recheck"].Matched)
+}
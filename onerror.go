@@ -0,0 +1,101 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// OnErrorFunc is called for a message that failed at stage. Return nil to
+// skip the message, return an error to fail with it.
+type OnErrorFunc func(ctx context.Context, stage Stage, source, key string, err error) error
+
+// WithOnError adds one policy hook that sees every kind of failure, for teams
+// that want a single function instead of a hook per error class. The stage
+// says where the message failed:
+//
+//   - StageMatch: no source matched; err is ErrNoSource, or the raw message
+//     is oversize; err wraps ErrOversize
+//   - StageParse: the source's Parse method failed
+//   - StageRoute: no handler is registered; err wraps ErrNoHandler
+//   - StageUnmarshal: the payload didn't unmarshal; err wraps ErrUnmarshal,
+//     or it is oversize; err wraps ErrOversize
+//   - StageValidate: the payload failed validation; err wraps ErrValidation
+//   - StageHandle: the handler returned err
+//   - StageReply: sending the result through the Replier failed
+//
+// For the stages before the handler runs, the hook behaves exactly like the
+// matching hook (WithOnNoSource, WithOnParseError, and so on) and combines
+// with them. For StageHandle and StageReply, it decides what Process returns:
+// OnFailure hooks still run and replies are still sent, but returning nil
+// acknowledges the message instead of failing it. Handler errors on messages
+// with a Replier reach the hook as StageHandle before Replier.Fail is called,
+// and a failing Fail is reported again as StageReply. Group hooks registered
+// with AddGroupWithHooks only see the stages before the handler.
+//
+// Example:
+//
+//	dispatch.WithOnError(func(ctx context.Context, stage dispatch.Stage, source, key string, err error) error {
+//	    if stage == dispatch.StageHandle && errors.Is(err, ErrTransient) {
+//	        return err // retry
+//	    }
+//	    if stage == dispatch.StageHandle {
+//	        return nil // ack business failures
+//	    }
+//	    return err
+//	})
+func WithOnError(fn OnErrorFunc) Option {
+	return func(r *Router) {
+		r.hooks.onNoSource = append(r.hooks.onNoSource, func(ctx context.Context, raw []byte) error {
+			return fn(ctx, StageMatch, "", "", ErrNoSource)
+		})
+		r.hooks.onParseError = append(r.hooks.onParseError, func(ctx context.Context, source string, err error) error {
+			return fn(ctx, StageParse, source, "", err)
+		})
+		r.hooks.onNoHandler = append(r.hooks.onNoHandler, func(ctx context.Context, source, key string) error {
+			return fn(ctx, StageRoute, source, key, fmt.Errorf("%w: %s", ErrNoHandler, key))
+		})
+		r.hooks.onUnmarshalError = append(r.hooks.onUnmarshalError, func(ctx context.Context, source, key string, err error) error {
+			return fn(ctx, StageUnmarshal, source, key, fmt.Errorf("%w: %w", ErrUnmarshal, err))
+		})
+		r.hooks.onValidationError = append(r.hooks.onValidationError, func(ctx context.Context, source, key string, err error) error {
+			return fn(ctx, StageValidate, source, key, fmt.Errorf("%w: %w", ErrValidation, err))
+		})
+		r.hooks.onOversize = append(r.hooks.onOversize, func(ctx context.Context, source, key string, size int) error {
+			stage := StageUnmarshal
+			if source == "" {
+				stage = StageMatch
+			}
+			return fn(ctx, stage, source, key, fmt.Errorf("%w: %d bytes", ErrOversize, size))
+		})
+		r.hooks.onError = append(r.hooks.onError, fn)
+	}
+}
+
+// handleError applies OnError hooks to a handler or reply error. It returns
+// err unchanged when no hook ran, and nil when the hooks skipped it (unless
+// dead-lettering the message fails).
+func (r *Router) handleError(ctx context.Context, stage Stage, sourceName, key string, err error) error {
+	if err == nil || len(r.hooks.onError) == 0 {
+		return err
+	}
+	var errs []error
+	ran := 0
+	for _, fn := range r.hooks.onError {
+		herr := fn(ctx, stage, sourceName, key, err)
+		if errors.Is(herr, ErrHookAbstain) {
+			continue
+		}
+		ran++
+		if herr != nil {
+			errs = append(errs, herr)
+		}
+	}
+	switch {
+	case len(errs) > 0:
+		return r.combineHookErrors(errs)
+	case ran == 0:
+		return err
+	}
+	return r.callOnSkip(ctx, stage, sourceName, key, err)
+}
@@ -0,0 +1,186 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type stageCall struct {
+	stage  Stage
+	source string
+	key    string
+	err    error
+}
+
+type OnErrorSuite struct {
+	suite.Suite
+	calls  []stageCall
+	result error
+	router *Router
+}
+
+func TestOnErrorSuite(t *testing.T) {
+	suite.Run(t, new(OnErrorSuite))
+}
+
+func (s *OnErrorSuite) SetupTest() {
+	s.calls = nil
+	s.result = nil
+	s.router = New(WithOnError(func(ctx context.Context, stage Stage, source, key string, err error) error {
+		s.calls = append(s.calls, stageCall{stage: stage, source: source, key: key, err: err})
+		return s.result
+	}))
+	s.router.AddSource(&testSource{name: "test"})
+}
+
+func (s *OnErrorSuite) TestSeesEveryStage() {
+	errHandler := errors.New("handler failed")
+	RegisterProc(s.router, "fails", &testHandler{err: errHandler})
+	RegisterProcFunc(s.router, "validated", func(ctx context.Context, p validatablePayload) error {
+		return nil
+	})
+
+	tests := map[string]struct {
+		raw      string
+		stage    Stage
+		key      string
+		sentinel error
+	}{
+		"no source":  {raw: `{"other": true}`, stage: StageMatch, sentinel: ErrNoSource},
+		"parse":      {raw: `{"type": 1, "payload": {}}`, stage: StageParse},
+		"no handler": {raw: `{"type": "missing", "payload": {}}`, stage: StageRoute, key: "missing", sentinel: ErrNoHandler},
+		"unmarshal":  {raw: `{"type": "fails", "payload": "bad"}`, stage: StageUnmarshal, key: "fails", sentinel: ErrUnmarshal},
+		"validation": {raw: `{"type": "validated", "payload": {}}`, stage: StageValidate, key: "validated", sentinel: ErrValidation},
+		"handler":    {raw: `{"type": "fails", "payload": {}}`, stage: StageHandle, key: "fails", sentinel: errHandler},
+	}
+
+	for name, tt := range tests {
+		s.Run(name, func() {
+			s.calls = nil
+
+			err := s.router.Process(context.Background(), []byte(tt.raw))
+
+			s.Require().NoError(err, "nil from OnError skips the message")
+			s.Require().Len(s.calls, 1)
+			s.Assert().Equal(tt.stage, s.calls[0].stage)
+			s.Assert().Equal(tt.key, s.calls[0].key)
+			if tt.stage != StageMatch {
+				s.Assert().Equal("test", s.calls[0].source)
+			}
+			if tt.sentinel != nil {
+				s.Assert().ErrorIs(s.calls[0].err, tt.sentinel)
+			}
+		})
+	}
+}
+
+func (s *OnErrorSuite) TestReturnedErrorFails() {
+	errPolicy := errors.New("retry")
+	s.result = errPolicy
+	RegisterProc(s.router, "fails", &testHandler{err: errors.New("handler failed")})
+
+	err := s.router.Process(context.Background(), []byte(`{"type": "fails", "payload": {}}`))
+
+	s.Assert().ErrorIs(err, errPolicy)
+	var derr *DispatchError
+	s.Require().ErrorAs(err, &derr)
+	s.Assert().Equal(StageHandle, derr.Stage)
+}
+
+func (s *OnErrorSuite) TestOnFailureStillRuns() {
+	var failures int
+	WithOnFailure(func(ctx context.Context, source, key string, err error, d time.Duration) {
+		failures++
+	})(s.router)
+	RegisterProc(s.router, "fails", &testHandler{err: errors.New("handler failed")})
+
+	s.Require().NoError(s.router.Process(context.Background(), []byte(`{"type": "fails", "payload": {}}`)))
+	s.Assert().Equal(1, failures)
+}
+
+func (s *OnErrorSuite) TestReplyStage() {
+	errSend := errors.New("send failed")
+	r := New(WithOnError(func(ctx context.Context, stage Stage, source, key string, err error) error {
+		s.calls = append(s.calls, stageCall{stage: stage, source: source, key: key, err: err})
+		return nil
+	}))
+	r.AddSource(SourceFunc("rr", HasFields("type"), func([]byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(`{}`), Replier: completeReplier(func(ctx context.Context, err error) error {
+			return errSend
+		})}, nil
+	}))
+	RegisterProc(r, "test", &testHandler{})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test"}`)))
+	s.Require().Len(s.calls, 1)
+	s.Assert().Equal(StageReply, s.calls[0].stage)
+	s.Assert().ErrorIs(s.calls[0].err, errSend)
+}
+
+func (s *OnErrorSuite) TestHandlerErrorWithReplier() {
+	errHandler := errors.New("handler failed")
+	errPolicy := errors.New("retry")
+	s.result = errPolicy
+	RegisterProc(s.router, "fails", &testHandler{err: errHandler})
+	var failed error
+	replier := completeReplier(func(ctx context.Context, err error) error {
+		failed = err
+		return nil
+	})
+
+	err := s.router.Process(context.Background(), []byte(`{"type": "fails", "payload": {}}`), WithReplier(replier))
+
+	s.Assert().ErrorIs(err, errPolicy)
+	var derr *DispatchError
+	s.Require().ErrorAs(err, &derr)
+	s.Assert().Equal(StageHandle, derr.Stage)
+	s.Require().Len(s.calls, 1)
+	s.Assert().Equal(StageHandle, s.calls[0].stage)
+	s.Assert().ErrorIs(s.calls[0].err, errHandler)
+	s.Assert().ErrorIs(failed, errHandler, "the failure is still sent back")
+}
+
+func (s *OnErrorSuite) TestSkipObserversSeeHandlerAndReplyStages() {
+	var records []AuditRecord
+	WithAudit(AuditSinkFunc(func(ctx context.Context, rec AuditRecord) {
+		records = append(records, rec)
+	}))(s.router)
+	errHandler := errors.New("handler failed")
+	errSend := errors.New("send failed")
+	RegisterProc(s.router, "fails", &testHandler{err: errHandler})
+	RegisterProc(s.router, "ok", &testHandler{})
+	failingReplier := completeReplier(func(ctx context.Context, err error) error { return errSend })
+
+	s.Require().NoError(s.router.Process(context.Background(), []byte(`{"type": "fails", "payload": {}}`)))
+	s.Require().NoError(s.router.Process(context.Background(), []byte(`{"type": "ok", "payload": {}}`), WithReplier(failingReplier)))
+
+	s.Require().Len(records, 4)
+	s.Assert().Equal(AuditFailure, records[0].Outcome)
+	s.Assert().Equal(AuditSkipped, records[1].Outcome)
+	s.Assert().Equal("handler failed", records[1].Error)
+	s.Assert().Equal(AuditSuccess, records[2].Outcome)
+	s.Assert().Equal(AuditSkipped, records[3].Outcome)
+	s.Assert().Equal("send failed", records[3].Error)
+}
+
+func (s *OnErrorSuite) TestNotCalledOnSuccess() {
+	RegisterProc(s.router, "ok", &testHandler{})
+
+	s.Require().NoError(s.router.Process(context.Background(), []byte(`{"type": "ok", "payload": {}}`)))
+	s.Assert().Empty(s.calls)
+}
+
+func (s *OnErrorSuite) TestFilteredHookDoesNotSkip() {
+	r := New(WithHookFilter(MatchKeys("other"), WithOnError(func(ctx context.Context, stage Stage, source, key string, err error) error {
+		return nil
+	})))
+	r.AddSource(&testSource{name: "test"})
+	RegisterProc(r, "fails", &testHandler{err: errors.New("handler failed")})
+
+	s.Assert().EqualError(r.Process(context.Background(), []byte(`{"type": "fails", "payload": {}}`)), "handler failed")
+	s.Assert().ErrorIs(r.Process(context.Background(), []byte(`{"type": "missing", "payload": {}}`)), ErrNoHandler)
+}
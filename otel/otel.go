@@ -0,0 +1,160 @@
+// Package otel traces dispatched messages with OpenTelemetry.
+//
+// Hooks returns router options that start a consumer span per message once a
+// source has parsed it, record handler errors on the span, and end the span
+// after the message has been fully handled:
+//
+//	r := dispatch.New(otel.Hooks()...)
+//
+// Spans carry the source, routing key, and payload version as attributes.
+// If the context passed to Router.Process already holds a remote span
+// context (for example, extracted from SQS message attributes by the
+// consumer), the span becomes its child. Use WithCarrier to extract trace
+// context from the parsed Message instead.
+//
+// Unmarshal, validation, and no-handler outcomes are reported through policy
+// hooks whose presence changes router behavior, so this package does not
+// register them. Call RecordError from your own policy hooks to mark those
+// spans as failed.
+package otel
+
+import (
+	"context"
+	"time"
+
+	"github.com/bjaus/dispatch"
+	gootel "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope name used for the tracer.
+const ScopeName = "github.com/bjaus/dispatch/otel"
+
+// Attribute keys set on spans. Version, message ID, and correlation ID are
+// only set when the message has them.
+const (
+	SourceKey        = attribute.Key("dispatch.source")
+	KeyKey           = attribute.Key("dispatch.key")
+	VersionKey       = attribute.Key("dispatch.version")
+	MessageIDKey     = attribute.Key("messaging.message.id")
+	CorrelationIDKey = attribute.Key("dispatch.correlation_id")
+)
+
+// Option configures the tracing hooks.
+type Option func(*config)
+
+type config struct {
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator
+	carrier    func(dispatch.Message) propagation.TextMapCarrier
+	spanName   func(source, key string) string
+}
+
+// WithTracerProvider sets the tracer provider. Defaults to the global
+// provider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = tp
+	}
+}
+
+// WithPropagator sets the propagator used with WithCarrier. Defaults to the
+// global text map propagator.
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagator = p
+	}
+}
+
+// WithCarrier extracts remote trace context from the parsed message before
+// the span starts. Return nil when the message carries no trace context.
+//
+// Example:
+//
+//	otel.WithCarrier(func(msg dispatch.Message) propagation.TextMapCarrier {
+//	    var env struct {
+//	        Trace map[string]string `json:"trace"`
+//	    }
+//	    if err := json.Unmarshal(msg.Payload, &env); err != nil {
+//	        return nil
+//	    }
+//	    return propagation.MapCarrier(env.Trace)
+//	})
+func WithCarrier(fn func(dispatch.Message) propagation.TextMapCarrier) Option {
+	return func(c *config) {
+		c.carrier = fn
+	}
+}
+
+// WithSpanName sets the span name. Defaults to the routing key.
+func WithSpanName(fn func(source, key string) string) Option {
+	return func(c *config) {
+		c.spanName = fn
+	}
+}
+
+// Hooks returns router options that trace each parsed message with a span.
+//
+// Example:
+//
+//	r := dispatch.New(append(otel.Hooks(otel.WithTracerProvider(tp)),
+//	    dispatch.WithOnNoHandler(func(ctx context.Context, source, key string) error {
+//	        otel.RecordError(ctx, fmt.Errorf("no handler for %s", key))
+//	        return nil
+//	    }),
+//	)...)
+func Hooks(opts ...Option) []dispatch.Option {
+	cfg := config{
+		provider:   gootel.GetTracerProvider(),
+		propagator: gootel.GetTextMapPropagator(),
+		spanName:   func(source, key string) string { return key },
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	tracer := cfg.provider.Tracer(ScopeName)
+
+	return []dispatch.Option{
+		dispatch.WithOnParse(func(ctx context.Context, source, key string) context.Context {
+			attrs := []attribute.KeyValue{SourceKey.String(source), KeyKey.String(key)}
+			if msg, ok := dispatch.MessageFromContext(ctx); ok {
+				if msg.Version != "" {
+					attrs = append(attrs, VersionKey.String(msg.Version))
+				}
+				if msg.MessageID != "" {
+					attrs = append(attrs, MessageIDKey.String(msg.MessageID))
+				}
+				if msg.CorrelationID != "" {
+					attrs = append(attrs, CorrelationIDKey.String(msg.CorrelationID))
+				}
+				if cfg.carrier != nil {
+					if carrier := cfg.carrier(msg); carrier != nil {
+						ctx = cfg.propagator.Extract(ctx, carrier)
+					}
+				}
+			}
+			ctx, _ = tracer.Start(ctx, cfg.spanName(source, key),
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(attrs...),
+			)
+			return ctx
+		}),
+		dispatch.WithOnFailure(func(ctx context.Context, source, key string, err error, d time.Duration) {
+			RecordError(ctx, err)
+		}),
+		dispatch.WithOnTimings(func(ctx context.Context, source, key string, t dispatch.Timings) {
+			trace.SpanFromContext(ctx).End()
+		}),
+	}
+}
+
+// RecordError records err on the span in ctx and marks it as failed.
+func RecordError(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
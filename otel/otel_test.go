@@ -0,0 +1,111 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type HooksSuite struct {
+	suite.Suite
+	recorder *tracetest.SpanRecorder
+	provider *sdktrace.TracerProvider
+}
+
+func (s *HooksSuite) SetupTest() {
+	s.recorder = tracetest.NewSpanRecorder()
+	s.provider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.recorder))
+}
+
+func TestHooksSuite(t *testing.T) {
+	suite.Run(t, new(HooksSuite))
+}
+
+func (s *HooksSuite) newRouter(opts ...Option) *dispatch.Router {
+	r := dispatch.New(Hooks(append([]Option{WithTracerProvider(s.provider)}, opts...)...)...)
+	r.AddSource(dispatch.SourceFunc("test", dispatch.HasFields("type"), func(raw []byte) (dispatch.Message, error) {
+		return dispatch.Message{
+			Key:     "user/created",
+			Version: "v2",
+			Payload: []byte(`{"trace": {"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}}`),
+		}, nil
+	}))
+	return r
+}
+
+func (s *HooksSuite) TestRecordsSpanWithAttributes() {
+	r := s.newRouter()
+	dispatch.RegisterProcFunc(r, "user/created", func(ctx context.Context, p struct{}) error {
+		s.Assert().True(trace.SpanFromContext(ctx).SpanContext().IsValid())
+		return nil
+	})
+
+	err := r.Process(context.Background(), []byte(`{"type": "x"}`))
+
+	s.Require().NoError(err)
+	spans := s.recorder.Ended()
+	s.Require().Len(spans, 1)
+	s.Assert().Equal("user/created", spans[0].Name())
+	s.Assert().Equal(trace.SpanKindConsumer, spans[0].SpanKind())
+	s.Assert().ElementsMatch([]any{
+		SourceKey.String("test"),
+		KeyKey.String("user/created"),
+		VersionKey.String("v2"),
+	}, toAny(spans[0].Attributes()))
+}
+
+func (s *HooksSuite) TestRecordsHandlerError() {
+	r := s.newRouter()
+	dispatch.RegisterProcFunc(r, "user/created", func(ctx context.Context, p struct{}) error {
+		return errors.New("boom")
+	})
+
+	_ = r.Process(context.Background(), []byte(`{"type": "x"}`))
+
+	spans := s.recorder.Ended()
+	s.Require().Len(spans, 1)
+	s.Assert().Equal(codes.Error, spans[0].Status().Code)
+	s.Assert().Equal("boom", spans[0].Status().Description)
+}
+
+func (s *HooksSuite) TestExtractsRemoteContextFromCarrier() {
+	r := s.newRouter(
+		WithPropagator(propagation.TraceContext{}),
+		WithCarrier(func(msg dispatch.Message) propagation.TextMapCarrier {
+			return propagation.MapCarrier{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+		}),
+	)
+	dispatch.RegisterProcFunc(r, "user/created", func(ctx context.Context, p struct{}) error { return nil })
+
+	err := r.Process(context.Background(), []byte(`{"type": "x"}`))
+
+	s.Require().NoError(err)
+	spans := s.recorder.Ended()
+	s.Require().Len(spans, 1)
+	s.Assert().Equal("4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	s.Assert().True(spans[0].Parent().IsRemote())
+}
+
+func (s *HooksSuite) TestEndsSpanWhenNoHandler() {
+	r := s.newRouter()
+
+	_ = r.Process(context.Background(), []byte(`{"type": "x"}`))
+
+	s.Assert().Len(s.recorder.Ended(), 1)
+}
+
+func toAny[T any](in []T) []any {
+	out := make([]any, len(in))
+	for i, v := range in {
+		out[i] = v
+	}
+	return out
+}
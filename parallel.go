@@ -0,0 +1,146 @@
+package dispatch
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// minParallelSources is the smallest source count for which WithParallelMatch
+// evaluates discriminators in parallel. Below it, goroutine overhead outweighs
+// the saving.
+const minParallelSources = 16
+
+// WithParallelMatch evaluates discriminators on up to workers goroutines
+// when a message isn't matched by the hot sources or the affinity cache.
+// Use it for routers with many groups or sources to cut worst-case match
+// latency. Values below 2 disable it, which is the default.
+//
+// Sources are split into contiguous chunks that are checked concurrently, but
+// the result is the same as a sequential scan: the first matching source in
+// registration order wins. Each group's inspector still parses the message at
+// most once.
+//
+// Discriminators and Views must be safe for concurrent use. The built-in
+// discriminators and JSONInspector views are.
+//
+// Example:
+//
+//	r := dispatch.New(dispatch.WithParallelMatch(runtime.GOMAXPROCS(0)))
+func WithParallelMatch(workers int) Option {
+	return func(r *Router) {
+		r.parallel = workers
+	}
+}
+
+// matchTask is a contiguous chunk of one group's sources.
+type matchTask struct {
+	group  *groupIndex
+	view   View
+	lo, hi int
+}
+
+// matchParallel searches all groups like matchAll, evaluating chunks of
+// sources concurrently. It returns false if the router has too few sources
+// for parallel matching to help, in which case the caller scans sequentially.
+func (r *Router) matchParallel(cache *viewCache, idx *matchIndex) (*compiledSource, View, bool) {
+	groups := idx.allGroups()
+	total := 0
+	for _, g := range groups {
+		total += len(g.sources)
+	}
+	if total < minParallelSources {
+		return nil, nil, false
+	}
+
+	// Views are resolved up front so inspectors run once per message and the
+	// cache is only touched from this goroutine.
+	chunk := (total + r.parallel - 1) / r.parallel
+	tasks := make([]matchTask, 0, r.parallel+len(groups))
+	for gi, g := range groups {
+		if len(g.sources) == 0 {
+			continue
+		}
+		insp := r.defaultInspector
+		if gi > 0 {
+			insp = r.groups[gi-1].inspector
+		}
+		view, ok := cache.get(insp)
+		if !ok {
+			if !g.ownView {
+				continue
+			}
+			view = nil
+		}
+		for lo := 0; lo < len(g.sources); lo += chunk {
+			tasks = append(tasks, matchTask{group: g, view: view, lo: lo, hi: min(lo+chunk, len(g.sources))})
+		}
+	}
+
+	// Likewise for sources with their own inspector.
+	var own viewFunc
+	var ownViews map[Inspector]View
+	for _, g := range groups {
+		if !g.ownView {
+			continue
+		}
+		for i := range g.sources {
+			if insp := g.sources[i].insp; insp != nil {
+				if _, done := ownViews[insp]; !done {
+					if ownViews == nil {
+						ownViews = make(map[Inspector]View)
+					}
+					view, _ := cache.get(insp)
+					ownViews[insp] = view
+				}
+			}
+		}
+	}
+	if len(ownViews) > 0 {
+		own = func(insp Inspector) (View, bool) {
+			view := ownViews[insp]
+			return view, view != nil
+		}
+	}
+
+	// best holds the lowest task index with a match so far; tasks after it
+	// can stop early since their result can't win.
+	var best atomic.Int64
+	best.Store(int64(len(tasks)))
+	found := make([]int, len(tasks))
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range min(r.parallel, len(tasks)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				t := int(next.Add(1) - 1)
+				if t >= len(tasks) || int64(t) > best.Load() {
+					return
+				}
+				task := &tasks[t]
+				found[t] = task.group.matchRange(task.view, own, task.lo, task.hi, func() bool {
+					return int64(t) > best.Load()
+				})
+				if found[t] < 0 {
+					continue
+				}
+				for {
+					cur := best.Load()
+					if int64(t) >= cur || best.CompareAndSwap(cur, int64(t)) {
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if t := int(best.Load()); t < len(tasks) {
+		task := &tasks[t]
+		cs := &task.group.sources[found[t]]
+		return cs, cs.viewOf(task.view, own), true
+	}
+	return nil, nil, true
+}
@@ -0,0 +1,96 @@
+package dispatch
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ParallelMatchSuite struct {
+	suite.Suite
+}
+
+func TestParallelMatchSuite(t *testing.T) {
+	suite.Run(t, new(ParallelMatchSuite))
+}
+
+// kindSource matches messages whose kind field equals its name.
+func kindSource(name string) Source {
+	return SourceFunc(name, FieldEquals("kind", name), func([]byte) (Message, error) {
+		return Message{Key: name}, nil
+	})
+}
+
+func (s *ParallelMatchSuite) TestMatchesFirstInRegistrationOrder() {
+	r := New(WithParallelMatch(4))
+	for i := range 40 {
+		r.AddSource(kindSource(fmt.Sprintf("s%d", i)))
+	}
+	r.AddSource(SourceFunc("catch-all", HasFields("kind"), nil))
+	r.AddSource(SourceFunc("late", FieldEquals("kind", "s35"), nil))
+
+	for _, kind := range []string{"s0", "s17", "s35", "s39"} {
+		src, _ := r.match([]byte(`{"kind": "` + kind + `"}`))
+		s.Require().NotNil(src, kind)
+		s.Assert().Equal(kind, src.Name())
+	}
+
+	src, _ := r.match([]byte(`{"kind": "other"}`))
+	s.Require().NotNil(src)
+	s.Assert().Equal("catch-all", src.Name())
+
+	src, _ = r.match([]byte(`{"other": true}`))
+	s.Assert().Nil(src)
+}
+
+func (s *ParallelMatchSuite) TestSearchesGroupsInOrder() {
+	r := New(WithParallelMatch(3))
+	for i := range 10 {
+		r.AddSource(kindSource(fmt.Sprintf("d%d", i)))
+	}
+	var first, second []Source
+	for i := range 10 {
+		first = append(first, kindSource(fmt.Sprintf("g%d", i)))
+		second = append(second, kindSource(fmt.Sprintf("g%d", i)))
+	}
+	r.AddGroup(JSONInspector(), first...)
+	r.AddGroup(JSONInspector(), second...)
+
+	src, view := r.match([]byte(`{"kind": "g7"}`))
+	s.Require().NotNil(src)
+	s.Assert().Same(first[7], src)
+	s.Assert().NotNil(view)
+}
+
+func (s *ParallelMatchSuite) TestSmallRoutersScanSequentially() {
+	r := New(WithParallelMatch(4))
+	r.AddSource(kindSource("a"))
+
+	_, _, ok := r.matchParallel(getViewCache([]byte(`{"kind": "a"}`)), r.matchIndex())
+	s.Assert().False(ok)
+
+	src, _ := r.match([]byte(`{"kind": "a"}`))
+	s.Require().NotNil(src)
+	s.Assert().Equal("a", src.Name())
+}
+
+func (s *ParallelMatchSuite) TestConcurrentProcess() {
+	r := New(WithParallelMatch(4))
+	for i := range 32 {
+		r.AddSource(kindSource(fmt.Sprintf("s%d", i)))
+	}
+
+	var wg sync.WaitGroup
+	for i := range 32 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			kind := fmt.Sprintf("s%d", i)
+			src, _ := r.match([]byte(`{"kind": "` + kind + `"}`))
+			s.Assert().Equal(kind, src.Name())
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,59 @@
+package dispatch
+
+import "strings"
+
+// Path joins path segments into a View path, escaping characters that would
+// otherwise be interpreted as path syntax. Use it to address keys that
+// contain dots or other special characters:
+//
+//	// {"detail-type.v2": "UserCreated"}
+//	dispatch.FieldEquals(dispatch.Path("detail-type.v2"), "UserCreated")
+//
+//	// {"attributes": {"app.name": "billing"}}
+//	dispatch.HasFields(dispatch.Path("attributes", "app.name"))
+//
+// Escaped characters are prefixed with a backslash. Custom View
+// implementations can use SplitPath to recover the original segments.
+func Path(segments ...string) string {
+	var b strings.Builder
+	for i, seg := range segments {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		for j := 0; j < len(seg); j++ {
+			if !isPlainPathChar(seg[j]) {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(seg[j])
+		}
+	}
+	return b.String()
+}
+
+// SplitPath splits a View path into its segments, removing escape
+// characters. It is the inverse of Path and is intended for custom View
+// implementations that do not use gjson.
+func SplitPath(path string) []string {
+	var segments []string
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '\\' && i+1 < len(path):
+			i++
+			b.WriteByte(path[i])
+		case c == '.':
+			segments = append(segments, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return append(segments, b.String())
+}
+
+// isPlainPathChar reports whether c can appear unescaped in a path segment.
+func isPlainPathChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+		(c >= '0' && c <= '9') || c <= ' ' || c > '~' ||
+		c == '_' || c == '-' || c == ':'
+}
@@ -0,0 +1,62 @@
+package dispatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PathSuite struct {
+	suite.Suite
+	view View
+}
+
+func (s *PathSuite) SetupTest() {
+	raw := []byte(`{
+		"detail-type.v2": "UserCreated",
+		"attributes": {"app.name": "billing", "a*b": "star"},
+		"detail": {"userId": "123"}
+	}`)
+
+	var err error
+	s.view, err = JSONInspector().Inspect(raw)
+	s.Require().NoError(err)
+}
+
+func TestPathSuite(t *testing.T) {
+	suite.Run(t, new(PathSuite))
+}
+
+func (s *PathSuite) TestJoinsPlainSegments() {
+	s.Assert().Equal("detail.userId", Path("detail", "userId"))
+}
+
+func (s *PathSuite) TestEscapesSpecialCharacters() {
+	s.Assert().Equal(`detail-type\.v2`, Path("detail-type.v2"))
+	s.Assert().Equal(`attributes.a\*b`, Path("attributes", "a*b"))
+}
+
+func (s *PathSuite) TestAddressesKeysContainingDots() {
+	val, ok := s.view.GetString(Path("detail-type.v2"))
+	s.Require().True(ok)
+	s.Assert().Equal("UserCreated", val)
+
+	s.Assert().True(FieldEquals(Path("attributes", "app.name"), "billing").Match(s.view))
+	s.Assert().True(HasFields(Path("attributes", "a*b")).Match(s.view))
+	s.Assert().False(HasFields("detail-type.v2").Match(s.view))
+}
+
+func (s *PathSuite) TestSplitPathInvertsPath() {
+	tests := map[string][]string{
+		"single":  {"source"},
+		"nested":  {"detail", "userId"},
+		"dotted":  {"attributes", "app.name"},
+		"escapes": {`a\b`, "c*d", "e.f.g"},
+	}
+
+	for name, segments := range tests {
+		s.Run(name, func() {
+			s.Assert().Equal(segments, SplitPath(Path(segments...)))
+		})
+	}
+}
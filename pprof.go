@@ -0,0 +1,42 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"runtime/pprof"
+)
+
+// WithPprofLabels runs each handler under pprof labels identifying the
+// source and routing key, so CPU profiles of busy consumers can be broken
+// down by message type. Labels are "dispatch.source" and "dispatch.key", and
+// are also visible to the handler through pprof.Label on its context.
+//
+// Example:
+//
+//	r := dispatch.New(dispatch.WithPprofLabels())
+//
+//	// go tool pprof -tagfocus=dispatch.key=user/created cpu.pprof
+func WithPprofLabels() Option {
+	return func(r *Router) {
+		r.pprofLabels = true
+	}
+}
+
+// invoke calls the handler, under pprof labels when enabled, after injecting
+// any WithChaos faults for StageHandle.
+func (r *Router) invoke(ctx context.Context, h invoker, sourceName string, msg Message, t *Timings) (json.RawMessage, error) {
+	if err := r.chaos.inject(ctx, StageHandle); err != nil {
+		return nil, err
+	}
+	if !r.pprofLabels {
+		return h(ctx, msg.Payload, t)
+	}
+
+	var result json.RawMessage
+	var err error
+	labels := pprof.Labels("dispatch.source", sourceName, "dispatch.key", msg.Key)
+	pprof.Do(ctx, labels, func(ctx context.Context) {
+		result, err = h(ctx, msg.Payload, t)
+	})
+	return result, err
+}
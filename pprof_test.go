@@ -0,0 +1,48 @@
+package dispatch
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PprofLabelsSuite struct {
+	suite.Suite
+}
+
+func TestPprofLabelsSuite(t *testing.T) {
+	suite.Run(t, new(PprofLabelsSuite))
+}
+
+func (s *PprofLabelsSuite) labelsSeenByHandler(opts ...Option) map[string]string {
+	labels := make(map[string]string)
+
+	r := New(opts...)
+	r.AddSource(&testSource{name: "test"})
+	RegisterProcFunc(r, "test/event", func(ctx context.Context, p testPayload) error {
+		pprof.ForLabels(ctx, func(k, v string) bool {
+			labels[k] = v
+			return true
+		})
+		return nil
+	})
+
+	err := r.Process(context.Background(), []byte(`{"type": "test/event", "payload": {}}`))
+	s.Require().NoError(err)
+	return labels
+}
+
+func (s *PprofLabelsSuite) TestLabelsHandlerExecution() {
+	labels := s.labelsSeenByHandler(WithPprofLabels())
+
+	s.Assert().Equal(map[string]string{
+		"dispatch.source": "test",
+		"dispatch.key":    "test/event",
+	}, labels)
+}
+
+func (s *PprofLabelsSuite) TestNoLabelsByDefault() {
+	s.Assert().Empty(s.labelsSeenByHandler())
+}
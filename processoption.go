@@ -0,0 +1,136 @@
+package dispatch
+
+import (
+	"fmt"
+	"maps"
+)
+
+// ProcessOption configures a single call to Router.Process, ProcessResult,
+// or Submit, so runners can attach transport context, such as an SQS
+// receipt handle or a Kafka partition, without configuring the router for
+// it.
+type ProcessOption func(*processConfig)
+
+type processConfig struct {
+	attributes map[string]string
+	replier    Replier
+	source     string
+	hookOpts   []Option
+	hooks      *hooks
+}
+
+func newProcessConfig(opts []ProcessOption) processConfig {
+	var cfg processConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if len(cfg.hookOpts) > 0 {
+		h := collectHooks(cfg.hookOpts)
+		cfg.hooks = &h
+	}
+	return cfg
+}
+
+// WithAttributes adds attrs to the message's Attributes, replacing any the
+// source set with the same names.
+//
+// Example:
+//
+//	err := r.Process(ctx, []byte(*m.Body), dispatch.WithAttributes(map[string]string{
+//	    "receipt-handle": *m.ReceiptHandle,
+//	}))
+func WithAttributes(attrs map[string]string) ProcessOption {
+	return func(c *processConfig) {
+		if c.attributes == nil {
+			c.attributes = make(map[string]string, len(attrs))
+		}
+		maps.Copy(c.attributes, attrs)
+	}
+}
+
+// WithReplier sends the message's result to rep instead of the Replier its
+// source set or WithReplierFactory would build.
+func WithReplier(rep Replier) ProcessOption {
+	return func(c *processConfig) {
+		c.replier = rep
+	}
+}
+
+// WithSource parses the message with the source registered under name,
+// without consulting discriminators, for runners that already know where a
+// message came from. Processing fails with ErrNoSource if no enabled source
+// has that name.
+func WithSource(name string) ProcessOption {
+	return func(c *processConfig) {
+		c.source = name
+	}
+}
+
+// WithProcessHooks runs the hooks configured by opts for this message only.
+// They run as source hooks, as with AddGroupWithHooks: after global hooks
+// and before hooks the source implements itself. Only hooks with a source
+// variant (see HookKind) run here; others, such as WithOnNoSource,
+// WithOnTimings, or WithOnPause, and non-hook options have no effect.
+//
+// Example:
+//
+//	err := r.Process(ctx, rec.Value, dispatch.WithProcessHooks(
+//	    dispatch.WithOnFailure(func(ctx context.Context, source, key string, err error, d time.Duration) {
+//	        log.Printf("partition %d offset %d: %v", rec.Partition, rec.Offset, err)
+//	    }),
+//	))
+func WithProcessHooks(opts ...Option) ProcessOption {
+	return func(c *processConfig) {
+		c.hookOpts = append(c.hookOpts, opts...)
+	}
+}
+
+// apply sets the message fields overridden by the call.
+func (c *processConfig) apply(msg *Message) {
+	if len(c.attributes) > 0 {
+		attrs := maps.Clone(msg.Attributes)
+		if attrs == nil {
+			attrs = make(map[string]string, len(c.attributes))
+		}
+		maps.Copy(attrs, c.attributes)
+		msg.Attributes = attrs
+	}
+	if c.replier != nil {
+		msg.Replier = c.replier
+	}
+}
+
+// wrap returns source with the call's hooks attached, if it has any.
+func (c *processConfig) wrap(source Source) Source {
+	if c.hooks == nil {
+		return source
+	}
+	return &hookedSource{Source: source, hooks: *c.hooks}
+}
+
+// matchNamed is like matchSource, but returns the source registered under
+// name.
+func (r *Router) matchNamed(raw []byte, name string) (*compiledSource, View, error) {
+	cache := getViewCache(raw)
+	defer putViewCache(cache)
+	return r.sourceNamed(cache, name)
+}
+
+// sourceNamed returns the enabled source registered under name, with the
+// View of the message from its inspector, or a nil View if the inspector
+// rejects it.
+func (r *Router) sourceNamed(cache *viewCache, name string) (*compiledSource, View, error) {
+	for _, g := range r.matchIndex().allGroups() {
+		for _, list := range [][]compiledSource{g.sources, g.fallbacks} {
+			for i := range list {
+				cs := &list[i]
+				if cs.source.Name() != name {
+					continue
+				}
+				view, _ := cache.get(r.inspectorFor(cs))
+				return cs, view, nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: %s", ErrNoSource, name)
+}
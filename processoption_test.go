@@ -0,0 +1,145 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ProcessOptionSuite struct {
+	suite.Suite
+	router *Router
+	msg    Message
+}
+
+func TestProcessOptionSuite(t *testing.T) {
+	suite.Run(t, new(ProcessOptionSuite))
+}
+
+func (s *ProcessOptionSuite) SetupTest() {
+	s.msg = Message{}
+	s.router = New()
+	s.router.AddSource(&testSource{name: "test"})
+	s.router.AddGroup(kvInspector{}, &kvSource{name: "kv"})
+	RegisterProcFunc(s.router, "test", func(ctx context.Context, p testPayload) error {
+		s.msg, _ = MessageFromContext(ctx)
+		return nil
+	})
+}
+
+func (s *ProcessOptionSuite) process(raw string, opts ...ProcessOption) error {
+	return s.router.Process(context.Background(), []byte(raw), opts...)
+}
+
+func (s *ProcessOptionSuite) TestAttributes() {
+	s.router.AddSource(SourceFunc("attrs", HasFields("attrs"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(`{}`), Attributes: map[string]string{"tenant": "acme", "partition": "0"}}, nil
+	}))
+
+	err := s.process(`{"attrs": true}`, WithAttributes(map[string]string{"partition": "3"}), WithAttributes(map[string]string{"offset": "42"}))
+
+	s.Require().NoError(err)
+	s.Assert().Equal(map[string]string{"tenant": "acme", "partition": "3", "offset": "42"}, s.msg.Attributes)
+}
+
+func (s *ProcessOptionSuite) TestReplier() {
+	rep := &keysReplier{}
+	RegisterFuncFunc(s.router, "echo", func(ctx context.Context, p testPayload) (testPayload, error) {
+		return p, nil
+	})
+
+	s.Require().NoError(s.process(`{"type": "echo", "payload": {"value": "hi"}}`, WithReplier(rep)))
+
+	s.Assert().JSONEq(`{"value": "hi"}`, string(rep.result))
+}
+
+func (s *ProcessOptionSuite) TestSource() {
+	err := s.process(`{"type": "test", "payload": {}}`, WithSource("kv"))
+
+	var derr *DispatchError
+	s.Require().ErrorAs(err, &derr, "kv's inspector can't read JSON, so kv parses raw")
+	s.Assert().Equal(StageParse, derr.Stage)
+	s.Assert().Equal("kv", derr.Source)
+
+	s.Require().NoError(s.process("key=test;format=other", WithSource("kv")), "the discriminator is not consulted")
+	s.Assert().Equal("test", s.msg.Key)
+}
+
+func (s *ProcessOptionSuite) TestUnknownSource() {
+	err := s.process(`{"type": "test", "payload": {}}`, WithSource("missing"))
+
+	s.Assert().ErrorIs(err, ErrNoSource)
+	s.Assert().ErrorContains(err, "missing")
+}
+
+func (s *ProcessOptionSuite) TestDisabledSource() {
+	s.router.EnableSource("test", false)
+
+	s.Assert().ErrorIs(s.process(`{"type": "test", "payload": {}}`, WithSource("test")), ErrNoSource)
+}
+
+func (s *ProcessOptionSuite) TestProcessHooks() {
+	boom := errors.New("boom")
+	RegisterProcFunc(s.router, "fail", func(ctx context.Context, p testPayload) error { return boom })
+	var dispatched []string
+	var failed error
+
+	hooks := WithProcessHooks(
+		WithOnDispatch(func(ctx context.Context, source, key string) { dispatched = append(dispatched, key) }),
+		WithOnFailure(func(ctx context.Context, source, key string, err error, d time.Duration) { failed = err }),
+	)
+	s.Require().NoError(s.process(`{"type": "test", "payload": {}}`, hooks))
+	s.Require().ErrorIs(s.process(`{"type": "fail", "payload": {}}`, hooks), boom)
+	s.Require().NoError(s.process(`{"type": "test", "payload": {}}`))
+
+	s.Assert().Equal([]string{"test", "fail"}, dispatched, "hooks run only for calls given them")
+	s.Assert().ErrorIs(failed, boom)
+}
+
+func (s *ProcessOptionSuite) TestCompiledRouter() {
+	var got Message
+	RegisterProcFunc(s.router, "compiled", func(ctx context.Context, p testPayload) error {
+		got, _ = MessageFromContext(ctx)
+		return nil
+	})
+	c := s.router.Build()
+
+	err := c.Process(context.Background(), []byte(`{"type": "compiled", "payload": {}}`), WithAttributes(map[string]string{"a": "b"}))
+
+	s.Require().NoError(err)
+	s.Assert().Equal("b", got.Attributes["a"])
+}
+
+func (s *ProcessOptionSuite) TestProcessResult() {
+	rep := &keysReplier{}
+	RegisterFuncFunc(s.router, "echo", func(ctx context.Context, p testPayload) (testPayload, error) {
+		return p, nil
+	})
+
+	res, err := s.router.ProcessResult(context.Background(), []byte("key=echo;format=other"), WithSource("kv"), WithReplier(rep))
+
+	s.Require().NoError(err)
+	s.Assert().Equal("kv", res.Source)
+	s.Assert().True(res.Replied)
+	s.Assert().JSONEq(`{"value": "kv"}`, string(res.Result))
+	s.Assert().JSONEq(`{"value": "kv"}`, string(rep.result))
+}
+
+func (s *ProcessOptionSuite) TestSubmit() {
+	var dispatched []string
+	hooks := WithProcessHooks(
+		WithOnDispatch(func(ctx context.Context, source, key string) { dispatched = append(dispatched, key) }),
+	)
+	s.router.StartWorkers(1)
+	defer func() { s.Require().NoError(s.router.StopWorkers(context.Background())) }()
+
+	err := <-s.router.Submit(context.Background(), []byte(`{"type": "test", "payload": {}}`),
+		WithAttributes(map[string]string{"a": "b"}), hooks)
+
+	s.Require().NoError(err)
+	s.Assert().Equal("b", s.msg.Attributes["a"])
+	s.Assert().Equal([]string{"test"}, dispatched)
+}
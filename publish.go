@@ -0,0 +1,142 @@
+package dispatch
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"time"
+)
+
+// ErrNotRegistered means a payload type has no key in the Publisher's
+// Registry.
+var ErrNotRegistered = errors.New("payload type not registered")
+
+// Event is an outbound message built by a Publisher and sent by a Transport.
+type Event struct {
+	// Key is the routing key registered for the payload type.
+	Key string
+
+	// Payload is the JSON-encoded payload.
+	Payload json.RawMessage
+
+	// MessageID uniquely identifies the event. Publish generates one unless
+	// WithEventID is given.
+	MessageID string
+
+	// CorrelationID ties the event to the message being handled, if Publish
+	// is called from a handler.
+	CorrelationID string
+
+	// ReplyTo is the address the consumer should send its result to, set by
+	// Call.
+	ReplyTo string
+
+	// Attributes holds transport metadata, such as SNS message attributes.
+	Attributes map[string]string
+
+	// Time is when the event was published.
+	Time time.Time
+}
+
+// Transport sends events built by a Publisher, for example to EventBridge,
+// SNS, or SQS. The sns, sqs, and eventbridge modules provide
+// implementations.
+type Transport interface {
+	Send(ctx context.Context, e Event) error
+}
+
+// TransportFunc is a function adapter for Transport.
+type TransportFunc func(ctx context.Context, e Event) error
+
+// Send implements the Transport interface.
+func (f TransportFunc) Send(ctx context.Context, e Event) error {
+	return f(ctx, e)
+}
+
+// Publisher sends typed events, deriving each event's key from the payload
+// type's registration, so producers can't drift from the keys and schemas
+// their consumers handle.
+type Publisher struct {
+	registry  *Registry
+	transport Transport
+}
+
+// NewPublisher returns a Publisher that looks up keys in reg and sends events
+// with t.
+//
+// Example:
+//
+//	pub := dispatch.NewPublisher(Events, sns.NewPublisher(client, topicARN))
+//	err := dispatch.Publish(ctx, pub, UserCreated{ID: "42"})
+func NewPublisher(reg *Registry, t Transport) *Publisher {
+	return &Publisher{registry: reg, transport: t}
+}
+
+// PublishOption configures an Event sent by Publish.
+type PublishOption func(*Event)
+
+// WithEventID sets the event's MessageID instead of generating one, for
+// example to make retried publishes idempotent.
+func WithEventID(id string) PublishOption {
+	return func(e *Event) {
+		e.MessageID = id
+	}
+}
+
+// WithEventAttributes adds attributes to the event.
+func WithEventAttributes(attrs map[string]string) PublishOption {
+	return func(e *Event) {
+		if e.Attributes == nil {
+			e.Attributes = make(map[string]string, len(attrs))
+		}
+		maps.Copy(e.Attributes, attrs)
+	}
+}
+
+// Publish validates payload, if its type implements Validate, and sends it
+// with the key registered for T. It returns an error wrapping
+// ErrNotRegistered if T isn't registered, or ErrValidation if validation
+// fails. When called from a handler, the event carries the handled
+// message's correlation ID.
+//
+// Publish is a package-level function because methods cannot have type
+// parameters.
+func Publish[T any](ctx context.Context, p *Publisher, payload T, opts ...PublishOption) error {
+	e, err := newEvent(ctx, p.registry, payload, opts)
+	if err != nil {
+		return err
+	}
+	return p.transport.Send(ctx, e)
+}
+
+// newEvent builds the event for payload, keyed by T's registration in reg.
+func newEvent[T any](ctx context.Context, reg *Registry, payload T, opts []PublishOption) (Event, error) {
+	t := reflect.TypeFor[T]()
+	key, ok := reg.KeyOf(t)
+	if !ok {
+		return Event{}, fmt.Errorf("%w: %v", ErrNotRegistered, t)
+	}
+	if err := validate(&payload); err != nil {
+		return Event{}, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, fmt.Errorf("marshal %s: %w", key, err)
+	}
+
+	e := Event{
+		Key:           key,
+		Payload:       data,
+		MessageID:     rand.Text(),
+		CorrelationID: CorrelationID(ctx),
+		Time:          time.Now(),
+	}
+	for _, opt := range opts {
+		opt(&e)
+	}
+	return e, nil
+}
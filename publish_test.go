@@ -0,0 +1,94 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PublisherSuite struct {
+	suite.Suite
+	events []Event
+	err    error
+	pub    *Publisher
+}
+
+func TestPublisherSuite(t *testing.T) {
+	suite.Run(t, new(PublisherSuite))
+}
+
+func (s *PublisherSuite) SetupTest() {
+	s.events = nil
+	s.err = nil
+	reg := NewRegistry()
+	Register[testPayload](reg, "test")
+	Register[validatablePayload](reg, "validated")
+	s.pub = NewPublisher(reg, TransportFunc(func(ctx context.Context, e Event) error {
+		s.events = append(s.events, e)
+		return s.err
+	}))
+}
+
+func (s *PublisherSuite) TestPublishDerivesKey() {
+	before := time.Now()
+	s.Require().NoError(Publish(context.Background(), s.pub, testPayload{Value: "x"}))
+
+	s.Require().Len(s.events, 1)
+	e := s.events[0]
+	s.Assert().Equal("test", e.Key)
+	s.Assert().JSONEq(`{"value": "x"}`, string(e.Payload))
+	s.Assert().NotEmpty(e.MessageID)
+	s.Assert().Empty(e.CorrelationID)
+	s.Assert().False(e.Time.Before(before))
+}
+
+func (s *PublisherSuite) TestGeneratesUniqueIDs() {
+	s.Require().NoError(Publish(context.Background(), s.pub, testPayload{}))
+	s.Require().NoError(Publish(context.Background(), s.pub, testPayload{}))
+
+	s.Assert().NotEqual(s.events[0].MessageID, s.events[1].MessageID)
+}
+
+func (s *PublisherSuite) TestOptions() {
+	err := Publish(context.Background(), s.pub, testPayload{},
+		WithEventID("evt-1"),
+		WithEventAttributes(map[string]string{"tenant": "acme"}),
+		WithEventAttributes(map[string]string{"region": "eu"}),
+	)
+
+	s.Require().NoError(err)
+	s.Assert().Equal("evt-1", s.events[0].MessageID)
+	s.Assert().Equal(map[string]string{"tenant": "acme", "region": "eu"}, s.events[0].Attributes)
+}
+
+func (s *PublisherSuite) TestPropagatesCorrelationID() {
+	ctx := withMessage(context.Background(), Message{CorrelationID: "req-1"})
+
+	s.Require().NoError(Publish(ctx, s.pub, testPayload{}))
+
+	s.Assert().Equal("req-1", s.events[0].CorrelationID)
+}
+
+func (s *PublisherSuite) TestRejectsUnregisteredTypes() {
+	err := Publish(context.Background(), s.pub, struct{}{})
+
+	s.Assert().ErrorIs(err, ErrNotRegistered)
+	s.Assert().Empty(s.events)
+}
+
+func (s *PublisherSuite) TestValidates() {
+	err := Publish(context.Background(), s.pub, validatablePayload{})
+
+	s.Assert().ErrorIs(err, ErrValidation)
+	s.Assert().ErrorContains(err, "value is required")
+	s.Assert().Empty(s.events)
+}
+
+func (s *PublisherSuite) TestReturnsTransportErrors() {
+	s.err = errors.New("throttled")
+
+	s.Assert().ErrorIs(Publish(context.Background(), s.pub, testPayload{}), s.err)
+}
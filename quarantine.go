@@ -0,0 +1,180 @@
+package dispatch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// QuarantinedMessage is an unroutable message set aside by WithQuarantine,
+// with diagnostics for inspection.
+type QuarantinedMessage struct {
+	// ID identifies the message in the store: its MessageID if the source
+	// set one, otherwise the hex SHA-256 of Raw.
+	ID string
+
+	// Raw is the message exactly as passed to Process.
+	Raw []byte
+
+	// Stage is StageMatch when no source matched and StageRoute when no
+	// handler is registered for the key.
+	Stage Stage
+
+	// Source and Key are empty for StageMatch.
+	Source string
+	Key    string
+
+	// CorrelationID is copied from the parsed message, if any.
+	CorrelationID string
+
+	// Reason is the routing error message, such as
+	// "no handler for key: order/created".
+	Reason string
+
+	// Time is when the message was quarantined.
+	Time time.Time
+}
+
+// QuarantineStore persists unroutable messages for later inspection and
+// replay, for example in S3 or DynamoDB. Implementations must be safe for
+// concurrent use.
+type QuarantineStore interface {
+	// Put stores msg, replacing any message with the same ID.
+	Put(ctx context.Context, msg QuarantinedMessage) error
+
+	// List returns the stored messages.
+	List(ctx context.Context) ([]QuarantinedMessage, error)
+
+	// Delete removes the message with the given ID.
+	Delete(ctx context.Context, id string) error
+}
+
+// WithQuarantine stores messages that no source matches, or that have no
+// registered handler, in store instead of failing them. Quarantined messages
+// are acknowledged: Process returns nil. Use ReplayQuarantine to process
+// them again once the missing source or handler is deployed.
+//
+// Quarantine only applies when no hook decided the outcome; OnNoSource and
+// OnNoHandler hooks (and WithOnError) still take precedence. If store fails,
+// the message fails with the store's error so it is redelivered.
+//
+// Example:
+//
+//	r := dispatch.New(dispatch.WithQuarantine(s3Quarantine))
+func WithQuarantine(store QuarantineStore) Option {
+	return func(r *Router) {
+		r.quarantine = store
+	}
+}
+
+// replayKey marks a context used by ReplayQuarantine so messages that are
+// still unroutable aren't quarantined again.
+type replayKey struct{}
+
+// quarantineMessage stores an unroutable message and reports it as skipped.
+// It returns cause unchanged when quarantine is off or the message is being
+// replayed.
+func (r *Router) quarantineMessage(ctx context.Context, stage Stage, sourceName, key string, cause error) error {
+	if r.quarantine == nil || ctx.Value(replayKey{}) != nil {
+		return cause
+	}
+	raw, _ := ctx.Value(rawKey{}).([]byte)
+	q := QuarantinedMessage{
+		Raw:    slices.Clone(raw),
+		Stage:  stage,
+		Source: sourceName,
+		Key:    key,
+		Reason: cause.Error(),
+		Time:   time.Now(),
+	}
+	if msg, ok := MessageFromContext(ctx); ok {
+		q.ID = msg.MessageID
+		q.CorrelationID = msg.CorrelationID
+	}
+	if q.ID == "" {
+		sum := sha256.Sum256(raw)
+		q.ID = hex.EncodeToString(sum[:])
+	}
+	if err := r.quarantine.Put(ctx, q); err != nil {
+		return fmt.Errorf("quarantine: %w", err)
+	}
+	r.notifySkip(ctx, sourceName, key, cause)
+	return nil
+}
+
+// ReplayQuarantine processes every message in store with r, deleting each one
+// that now succeeds. Messages that are still unroutable stay in the store and
+// aren't quarantined again. It returns the number of messages replayed and
+// the processing errors, combined with errors.Join.
+//
+// Example:
+//
+//	n, err := r.ReplayQuarantine(ctx, store)
+//	log.Printf("replayed %d messages: %v", n, err)
+func (r *Router) ReplayQuarantine(ctx context.Context, store QuarantineStore) (int, error) {
+	msgs, err := store.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	ctx = context.WithValue(ctx, replayKey{}, true)
+
+	replayed := 0
+	var errs []error
+	for _, msg := range msgs {
+		if err := r.Process(ctx, msg.Raw); err != nil {
+			errs = append(errs, fmt.Errorf("replay %s: %w", msg.ID, err))
+			continue
+		}
+		if err := store.Delete(ctx, msg.ID); err != nil {
+			errs = append(errs, fmt.Errorf("delete %s: %w", msg.ID, err))
+		}
+		replayed++
+	}
+	return replayed, errors.Join(errs...)
+}
+
+// ReplayQuarantine processes every message in store with the compiled
+// router. See Router.ReplayQuarantine.
+func (c *CompiledRouter) ReplayQuarantine(ctx context.Context, store QuarantineStore) (int, error) {
+	return c.r.ReplayQuarantine(ctx, store)
+}
+
+// MemoryQuarantine is an in-memory QuarantineStore for tests and local
+// development. The zero value is ready to use.
+type MemoryQuarantine struct {
+	mu   sync.Mutex
+	msgs []QuarantinedMessage
+}
+
+// Put implements QuarantineStore.
+func (m *MemoryQuarantine) Put(ctx context.Context, msg QuarantinedMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i := slices.IndexFunc(m.msgs, func(q QuarantinedMessage) bool { return q.ID == msg.ID }); i >= 0 {
+		m.msgs[i] = msg
+		return nil
+	}
+	m.msgs = append(m.msgs, msg)
+	return nil
+}
+
+// List implements QuarantineStore. Messages are returned in the order they
+// were first stored.
+func (m *MemoryQuarantine) List(ctx context.Context) ([]QuarantinedMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.msgs), nil
+}
+
+// Delete implements QuarantineStore.
+func (m *MemoryQuarantine) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.msgs = slices.DeleteFunc(m.msgs, func(q QuarantinedMessage) bool { return q.ID == id })
+	return nil
+}
@@ -0,0 +1,146 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QuarantineSuite struct {
+	suite.Suite
+	store  *MemoryQuarantine
+	router *Router
+}
+
+func TestQuarantineSuite(t *testing.T) {
+	suite.Run(t, new(QuarantineSuite))
+}
+
+func (s *QuarantineSuite) SetupTest() {
+	s.store = &MemoryQuarantine{}
+	s.router = New(WithQuarantine(s.store))
+	s.router.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		v, _ := JSONInspector().Inspect(raw)
+		key, _ := v.GetString("type")
+		id, _ := v.GetString("id")
+		payload, _ := v.GetBytes("payload")
+		return Message{Key: key, MessageID: id, CorrelationID: id, Payload: payload}, nil
+	}))
+}
+
+func (s *QuarantineSuite) list() []QuarantinedMessage {
+	msgs, err := s.store.List(context.Background())
+	s.Require().NoError(err)
+	return msgs
+}
+
+func (s *QuarantineSuite) TestNoHandler() {
+	raw := `{"type": "missing", "id": "m-1", "payload": {}}`
+
+	s.Require().NoError(s.router.Process(context.Background(), []byte(raw)))
+
+	msgs := s.list()
+	s.Require().Len(msgs, 1)
+	q := msgs[0]
+	s.Assert().Equal("m-1", q.ID)
+	s.Assert().Equal(raw, string(q.Raw))
+	s.Assert().Equal(StageRoute, q.Stage)
+	s.Assert().Equal("test", q.Source)
+	s.Assert().Equal("missing", q.Key)
+	s.Assert().Equal("m-1", q.CorrelationID)
+	s.Assert().Equal("no handler for key: missing", q.Reason)
+	s.Assert().False(q.Time.IsZero())
+}
+
+func (s *QuarantineSuite) TestNoSource() {
+	raw := `{"other": true}`
+
+	s.Require().NoError(s.router.Process(context.Background(), []byte(raw)))
+
+	msgs := s.list()
+	s.Require().Len(msgs, 1)
+	q := msgs[0]
+	s.Assert().Len(q.ID, 64)
+	s.Assert().Equal(StageMatch, q.Stage)
+	s.Assert().Empty(q.Source)
+	s.Assert().Equal(ErrNoSource.Error(), q.Reason)
+}
+
+func (s *QuarantineSuite) TestOtherFailuresAreNotQuarantined() {
+	RegisterProc(s.router, "fails", &testHandler{err: errors.New("handler failed")})
+
+	s.Assert().Error(s.router.Process(context.Background(), []byte(`{"type": "fails", "payload": {}}`)))
+	s.Assert().Empty(s.list())
+}
+
+func (s *QuarantineSuite) TestHooksTakePrecedence() {
+	hookErr := errors.New("from hook")
+	r := New(WithQuarantine(s.store), WithOnNoSource(func(ctx context.Context, raw []byte) error {
+		return hookErr
+	}))
+
+	s.Assert().ErrorIs(r.Process(context.Background(), []byte(`{}`)), hookErr)
+	s.Assert().Empty(s.list())
+}
+
+func (s *QuarantineSuite) TestStoreFailureFailsMessage() {
+	storeErr := errors.New("store down")
+	r := New(WithQuarantine(failingQuarantine{err: storeErr}))
+
+	err := r.Process(context.Background(), []byte(`{}`))
+
+	s.Assert().ErrorIs(err, storeErr)
+	s.Assert().ErrorContains(err, "quarantine: store down")
+}
+
+func (s *QuarantineSuite) TestReplay() {
+	ctx := context.Background()
+	s.Require().NoError(s.router.Process(ctx, []byte(`{"type": "late", "id": "m-1", "payload": {}}`)))
+	s.Require().NoError(s.router.Process(ctx, []byte(`{"type": "never", "id": "m-2", "payload": {}}`)))
+	s.Require().Len(s.list(), 2)
+
+	handler := &testHandler{}
+	RegisterProc(s.router, "late", handler)
+
+	n, err := s.router.ReplayQuarantine(ctx, s.store)
+
+	s.Assert().Equal(1, n)
+	s.Assert().ErrorIs(err, ErrNoHandler)
+	s.Assert().ErrorContains(err, "replay m-2")
+	s.Assert().True(handler.called)
+	msgs := s.list()
+	s.Require().Len(msgs, 1)
+	s.Assert().Equal("m-2", msgs[0].ID)
+}
+
+func (s *QuarantineSuite) TestMemoryQuarantinePutReplaces() {
+	ctx := context.Background()
+	s.Require().NoError(s.store.Put(ctx, QuarantinedMessage{ID: "a", Reason: "first"}))
+	s.Require().NoError(s.store.Put(ctx, QuarantinedMessage{ID: "b"}))
+	s.Require().NoError(s.store.Put(ctx, QuarantinedMessage{ID: "a", Reason: "second"}))
+
+	msgs := s.list()
+	s.Require().Len(msgs, 2)
+	s.Assert().Equal("second", msgs[0].Reason)
+
+	s.Require().NoError(s.store.Delete(ctx, "a"))
+	s.Assert().Len(s.list(), 1)
+}
+
+type failingQuarantine struct {
+	err error
+}
+
+func (f failingQuarantine) Put(ctx context.Context, msg QuarantinedMessage) error {
+	return f.err
+}
+
+func (f failingQuarantine) List(ctx context.Context) ([]QuarantinedMessage, error) {
+	return nil, f.err
+}
+
+func (f failingQuarantine) Delete(ctx context.Context, id string) error {
+	return f.err
+}
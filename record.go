@@ -0,0 +1,74 @@
+package dispatch
+
+import (
+	"slices"
+	"time"
+)
+
+// Recording is a raw message captured by a source wrapped with Record, with
+// where it was routed.
+type Recording struct {
+	// Raw is a copy of the message exactly as the source received it.
+	Raw []byte
+
+	// Source is the name of the recording source.
+	Source string
+
+	// Key and MessageID are copied from the parsed message. Both are empty
+	// when Err is set.
+	Key       string
+	MessageID string
+
+	// Err is the error the source's parser returned, if any.
+	Err error
+
+	// Time is when the message was parsed.
+	Time time.Time
+}
+
+// RecordSink receives the messages captured by Record. Write is called on
+// the processing path, so slow sinks should buffer. Implementations must be
+// safe for concurrent use.
+type RecordSink interface {
+	Write(rec Recording)
+}
+
+// RecordSinkFunc is a function adapter for RecordSink.
+type RecordSinkFunc func(rec Recording)
+
+// Write implements the RecordSink interface.
+func (f RecordSinkFunc) Write(rec Recording) {
+	f(rec)
+}
+
+// Record returns a Source that behaves like s and writes every message it
+// parses, with its key or parse error, to sink. Use it to capture production
+// traffic for replay tests, such as a golden corpus. Hooks and optional
+// interfaces that s implements keep working.
+//
+// Only messages whose discriminator matched s reach it, so unmatched
+// messages are not recorded.
+//
+// Example:
+//
+//	r.AddSource(dispatch.Record(eventbridge.NewSource(), sink))
+func Record(s Source, sink RecordSink) Source {
+	return &hookedSource{Source: s, sink: sink}
+}
+
+// record writes a parsed message to the source's sink, if any.
+func (s *hookedSource) record(raw []byte, msg Message, err error) {
+	if s.sink == nil {
+		return
+	}
+	rec := Recording{
+		Raw:    slices.Clone(raw),
+		Source: s.Name(),
+		Err:    err,
+		Time:   time.Now(),
+	}
+	if err == nil {
+		rec.Key, rec.MessageID = msg.Key, msg.MessageID
+	}
+	s.sink.Write(rec)
+}
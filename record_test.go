@@ -0,0 +1,86 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RecordSuite struct {
+	suite.Suite
+	mu   sync.Mutex
+	recs []Recording
+	sink RecordSink
+}
+
+func TestRecordSuite(t *testing.T) {
+	suite.Run(t, new(RecordSuite))
+}
+
+func (s *RecordSuite) SetupTest() {
+	s.recs = nil
+	s.sink = RecordSinkFunc(func(rec Recording) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.recs = append(s.recs, rec)
+	})
+}
+
+func (s *RecordSuite) TestRecordsParsedMessages() {
+	r := New()
+	r.AddSource(Record(&testSource{name: "test"}, s.sink))
+	RegisterProc(r, "known", &testHandler{})
+	raw := []byte(`{"type": "known", "payload": {}}`)
+
+	s.Require().NoError(r.Process(context.Background(), raw))
+	raw[2] = 'X'
+
+	s.Require().Len(s.recs, 1)
+	rec := s.recs[0]
+	s.Assert().Equal(`{"type": "known", "payload": {}}`, string(rec.Raw))
+	s.Assert().Equal("test", rec.Source)
+	s.Assert().Equal("known", rec.Key)
+	s.Assert().NoError(rec.Err)
+	s.Assert().False(rec.Time.IsZero())
+}
+
+func (s *RecordSuite) TestRecordsParseErrors() {
+	parseErr := errors.New("bad envelope")
+	r := New()
+	r.AddSource(Record(SourceFunc("broken", HasFields("type"), func([]byte) (Message, error) {
+		return Message{Key: "ignored"}, parseErr
+	}), s.sink))
+
+	s.Assert().ErrorIs(r.Process(context.Background(), []byte(`{"type": "x"}`)), parseErr)
+
+	s.Require().Len(s.recs, 1)
+	s.Assert().ErrorIs(s.recs[0].Err, parseErr)
+	s.Assert().Empty(s.recs[0].Key)
+}
+
+func (s *RecordSuite) TestIgnoresUnmatchedMessages() {
+	r := New()
+	r.AddSource(Record(&testSource{name: "test"}, s.sink))
+
+	s.Assert().ErrorIs(r.Process(context.Background(), []byte(`{"other": 1}`)), ErrNoSource)
+	s.Assert().Empty(s.recs)
+}
+
+func (s *RecordSuite) TestKeepsSourceHooksAndViewParser() {
+	src := &sourceWithHooks{name: "hooked"}
+	r := New()
+	r.AddSource(Record(src, s.sink))
+	RegisterProc(r, "known", &testHandler{})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "known", "payload": {}}`)))
+
+	s.Assert().True(src.onParseCalled)
+	s.Assert().True(src.onSuccessCalled)
+	s.Require().Len(s.recs, 1)
+
+	_, ok := Record(src, s.sink).(ViewParser)
+	s.Assert().True(ok)
+}
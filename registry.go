@@ -0,0 +1,165 @@
+package dispatch
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Registry records the payload type of each routing key, so producers and
+// consumers in the same codebase share one definition. A Publisher derives
+// the key of each event from its payload type, and a Router configured with
+// WithRegistry refuses handlers whose key or payload type disagrees.
+//
+// Example:
+//
+//	var Events = dispatch.NewRegistry()
+//
+//	func init() {
+//	    dispatch.Register[UserCreated](Events, "user/created")
+//	    dispatch.Register[UserDeleted](Events, "user/deleted")
+//	}
+type Registry struct {
+	mu    sync.RWMutex
+	types map[string]reflect.Type
+	keys  map[reflect.Type]string
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		types: make(map[string]reflect.Type),
+		keys:  make(map[reflect.Type]string),
+	}
+}
+
+// Register records that key carries payloads of type T. Each key has one
+// payload type and each type one key. Registering the same pair again is a
+// no-op; Register panics if key or T is already registered with a different
+// partner, since that is a programming error.
+func Register[T any](reg *Registry, key string) {
+	reg.register(key, reflect.TypeFor[T]())
+}
+
+func (reg *Registry) register(key string, t reflect.Type) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if got, ok := reg.types[key]; ok && got != t {
+		panic(fmt.Sprintf("dispatch: key %q is registered with %v, not %v", key, got, t))
+	}
+	if got, ok := reg.keys[t]; ok && got != key {
+		panic(fmt.Sprintf("dispatch: %v is registered with key %q, not %q", t, got, key))
+	}
+	reg.types[key] = t
+	reg.keys[t] = key
+}
+
+// DefaultRegistry is the registry used by RegisterType, and by
+// RegisterProcTyped and RegisterFuncTyped on routers without WithRegistry.
+var DefaultRegistry = NewRegistry()
+
+// RegisterType records in DefaultRegistry that key carries payloads of type
+// T. Call it next to the payload type's definition so producers and
+// consumers import the key instead of repeating it.
+//
+// Example:
+//
+//	type UserCreated struct{ ID string }
+//
+//	func init() { dispatch.RegisterType[UserCreated]("user/created") }
+func RegisterType[T any](key string) {
+	Register[T](DefaultRegistry, key)
+}
+
+// TypeOf returns the payload type registered for key.
+func (reg *Registry) TypeOf(key string) (reflect.Type, bool) {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	t, ok := reg.types[key]
+	return t, ok
+}
+
+// KeyOf returns the key registered for payload type t.
+func (reg *Registry) KeyOf(t reflect.Type) (string, bool) {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	key, ok := reg.keys[t]
+	return key, ok
+}
+
+// Keys returns the registered keys in sorted order.
+func (reg *Registry) Keys() []string {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	keys := make([]string, 0, len(reg.types))
+	for key := range reg.types {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WithRegistry makes the router check handlers against reg. RegisterProc and
+// RegisterFunc panic if the key isn't registered, or is registered with a
+// different payload type than the handler takes, so consumers can't drift
+// from the keys and schemas producers publish.
+//
+// Example:
+//
+//	r := dispatch.New(dispatch.WithRegistry(Events))
+//	dispatch.RegisterProc(r, "user/created", &UserCreatedProc{}) // Proc[UserCreated]
+func WithRegistry(reg *Registry) Option {
+	return func(r *Router) {
+		r.registry = reg
+	}
+}
+
+// checkRegistry panics if a handler taking payloads of type t disagrees with
+// the router's registry for key.
+func (r *Router) checkRegistry(key string, t reflect.Type) {
+	if r.registry == nil {
+		return
+	}
+	got, ok := r.registry.TypeOf(key)
+	if !ok {
+		panic(fmt.Sprintf("dispatch: key %q is not in the registry", key))
+	}
+	if got != t {
+		panic(fmt.Sprintf("dispatch: handler for %q takes %v, registry has %v", key, t, got))
+	}
+}
+
+// keyFor returns the key registered for payload type t in the router's
+// registry, or DefaultRegistry if it has none. It panics if t isn't
+// registered.
+func (r *Router) keyFor(t reflect.Type) string {
+	reg := r.registry
+	if reg == nil {
+		reg = DefaultRegistry
+	}
+	key, ok := reg.KeyOf(t)
+	if !ok {
+		panic(fmt.Sprintf("dispatch: %v is not in the registry", t))
+	}
+	return key
+}
+
+// RegisterProcTyped adds a procedure under the key registered for its
+// payload type, in the router's registry or DefaultRegistry. It panics if
+// the type isn't registered.
+//
+// Example:
+//
+//	dispatch.RegisterType[UserCreated]("user/created")
+//	dispatch.RegisterProcTyped(r, &UserCreatedProc{db: db}) // "user/created"
+func RegisterProcTyped[T any](r *Router, p Proc[T], opts ...HandlerOption) {
+	RegisterProc(r, r.keyFor(reflect.TypeFor[T]()), p, opts...)
+}
+
+// RegisterFuncTyped adds a function under the key registered for its
+// payload type, in the router's registry or DefaultRegistry. It panics if
+// the type isn't registered.
+func RegisterFuncTyped[T, R any](r *Router, f Func[T, R], opts ...HandlerOption) {
+	RegisterFunc(r, r.keyFor(reflect.TypeFor[T]()), f, opts...)
+}
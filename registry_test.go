@@ -0,0 +1,119 @@
+package dispatch
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RegistrySuite struct {
+	suite.Suite
+	reg *Registry
+}
+
+func TestRegistrySuite(t *testing.T) {
+	suite.Run(t, new(RegistrySuite))
+}
+
+func (s *RegistrySuite) SetupTest() {
+	s.reg = NewRegistry()
+	Register[testPayload](s.reg, "test")
+}
+
+func (s *RegistrySuite) TestLookups() {
+	Register[validatablePayload](s.reg, "validated")
+
+	t, ok := s.reg.TypeOf("test")
+	s.Assert().True(ok)
+	s.Assert().Equal(reflect.TypeFor[testPayload](), t)
+	key, ok := s.reg.KeyOf(reflect.TypeFor[validatablePayload]())
+	s.Assert().True(ok)
+	s.Assert().Equal("validated", key)
+	s.Assert().Equal([]string{"test", "validated"}, s.reg.Keys())
+
+	_, ok = s.reg.TypeOf("missing")
+	s.Assert().False(ok)
+}
+
+func (s *RegistrySuite) TestReregisteringSamePairIsNoop() {
+	s.Assert().NotPanics(func() { Register[testPayload](s.reg, "test") })
+}
+
+func (s *RegistrySuite) TestConflictsPanic() {
+	s.Assert().PanicsWithValue(`dispatch: key "test" is registered with dispatch.testPayload, not dispatch.validatablePayload`, func() {
+		Register[validatablePayload](s.reg, "test")
+	})
+	s.Assert().PanicsWithValue(`dispatch: dispatch.testPayload is registered with key "test", not "other"`, func() {
+		Register[testPayload](s.reg, "other")
+	})
+}
+
+func (s *RegistrySuite) TestRouterAcceptsMatchingHandlers() {
+	r := New(WithRegistry(s.reg))
+	r.AddSource(&testSource{name: "test"})
+	handler := &testHandler{}
+
+	s.Require().NotPanics(func() { RegisterProc(r, "test", handler) })
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test", "payload": {"value": "x"}}`)))
+	s.Assert().True(handler.called)
+}
+
+func (s *RegistrySuite) TestRouterRejectsDrift() {
+	r := New(WithRegistry(s.reg))
+
+	s.Assert().PanicsWithValue(`dispatch: key "missing" is not in the registry`, func() {
+		RegisterProc(r, "missing", &testHandler{})
+	})
+	s.Assert().PanicsWithValue(`dispatch: handler for "test" takes dispatch.validatablePayload, registry has dispatch.testPayload`, func() {
+		RegisterFuncFunc(r, "test", func(ctx context.Context, p validatablePayload) (string, error) { return "", nil })
+	})
+}
+
+func (s *RegistrySuite) TestCloneKeepsRegistry() {
+	r := New(WithRegistry(s.reg)).Clone()
+
+	s.Assert().Panics(func() { RegisterProc(r, "missing", &testHandler{}) })
+}
+
+type typedPayload struct {
+	Value string `json:"value"`
+}
+
+func (s *RegistrySuite) TestRegisterProcTypedUsesRouterRegistry() {
+	r := New(WithRegistry(s.reg))
+	r.AddSource(&testSource{name: "test"})
+	handler := &testHandler{}
+
+	RegisterProcTyped(r, handler)
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test", "payload": {"value": "x"}}`)))
+	s.Assert().True(handler.called)
+}
+
+func (s *RegistrySuite) TestRegisterTypedUsesDefaultRegistry() {
+	RegisterType[typedPayload]("typed")
+	r := New()
+	r.AddSource(&testSource{name: "test"})
+	var got typedPayload
+
+	RegisterFuncTyped(r, FuncFunc[typedPayload, string](func(ctx context.Context, p typedPayload) (string, error) {
+		got = p
+		return "ok", nil
+	}))
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "typed", "payload": {"value": "x"}}`)))
+	s.Assert().Equal("x", got.Value)
+	key, ok := DefaultRegistry.KeyOf(reflect.TypeFor[typedPayload]())
+	s.Assert().True(ok)
+	s.Assert().Equal("typed", key)
+}
+
+func (s *RegistrySuite) TestRegisterTypedPanicsForUnregisteredType() {
+	r := New(WithRegistry(s.reg))
+
+	s.Assert().PanicsWithValue(`dispatch: dispatch.validatablePayload is not in the registry`, func() {
+		RegisterProcTyped(r, ProcFunc[validatablePayload](func(ctx context.Context, p validatablePayload) error { return nil }))
+	})
+}
@@ -0,0 +1,62 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// replyRetry configures retries of Replier calls.
+type replyRetry struct {
+	attempts int
+	backoff  time.Duration
+}
+
+// WithReplyRetry retries failed Replier.Reply and Replier.Fail calls up to
+// attempts times in total, waiting backoff before the first retry and
+// doubling the wait after each one. Use it so transient errors such as
+// SendTaskSuccess throttling don't fail work that already succeeded.
+//
+// Retries stop early if ctx is done; the last Replier error is returned.
+//
+// Example:
+//
+//	r := dispatch.New(dispatch.WithReplyRetry(5, 100*time.Millisecond))
+func WithReplyRetry(attempts int, backoff time.Duration) Option {
+	return func(r *Router) {
+		r.replyRetry = replyRetry{attempts: attempts, backoff: backoff}
+	}
+}
+
+// reply sends result through replier, retrying per the router's policy.
+func (r *Router) reply(ctx context.Context, replier Replier, result json.RawMessage) error {
+	return r.retryReply(ctx, func() error { return replier.Reply(ctx, result) })
+}
+
+// fail sends err through replier, retrying per the router's policy.
+func (r *Router) fail(ctx context.Context, replier Replier, err error) error {
+	return r.retryReply(ctx, func() error { return replier.Fail(ctx, err) })
+}
+
+func (r *Router) retryReply(ctx context.Context, send func() error) error {
+	call := func() error {
+		if err := r.chaos.inject(ctx, StageReply); err != nil {
+			return err
+		}
+		return send()
+	}
+	err := call()
+	wait := r.replyRetry.backoff
+	for attempt := 1; err != nil && attempt < r.replyRetry.attempts; attempt++ {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		wait *= 2
+		err = call()
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ReplyRetrySuite struct {
+	suite.Suite
+}
+
+func TestReplyRetrySuite(t *testing.T) {
+	suite.Run(t, new(ReplyRetrySuite))
+}
+
+// flakyReplier fails the first failures calls to Reply or Fail.
+type flakyReplier struct {
+	failures int
+	calls    int
+}
+
+func (r *flakyReplier) Reply(ctx context.Context, result json.RawMessage) error {
+	return r.call()
+}
+
+func (r *flakyReplier) Fail(ctx context.Context, err error) error {
+	return r.call()
+}
+
+func (r *flakyReplier) call() error {
+	r.calls++
+	if r.calls <= r.failures {
+		return errors.New("throttled")
+	}
+	return nil
+}
+
+func (s *ReplyRetrySuite) router(replier Replier, handlerErr error, opts ...Option) *Router {
+	r := New(opts...)
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(`{}`), Replier: replier}, nil
+	}))
+	RegisterProcFunc(r, "test", func(ctx context.Context, p struct{}) error {
+		return handlerErr
+	})
+	return r
+}
+
+func (s *ReplyRetrySuite) TestRetriesReplyUntilSuccess() {
+	replier := &flakyReplier{failures: 2}
+	r := s.router(replier, nil, WithReplyRetry(3, time.Millisecond))
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test"}`)))
+	s.Assert().Equal(3, replier.calls)
+}
+
+func (s *ReplyRetrySuite) TestRetriesFail() {
+	replier := &flakyReplier{failures: 1}
+	r := s.router(replier, errors.New("boom"), WithReplyRetry(2, time.Millisecond))
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test"}`)))
+	s.Assert().Equal(2, replier.calls)
+}
+
+func (s *ReplyRetrySuite) TestReturnsLastErrorWhenExhausted() {
+	replier := &flakyReplier{failures: 5}
+	r := s.router(replier, nil, WithReplyRetry(3, time.Millisecond))
+
+	s.Assert().EqualError(r.Process(context.Background(), []byte(`{"type": "test"}`)), "throttled")
+	s.Assert().Equal(3, replier.calls)
+}
+
+func (s *ReplyRetrySuite) TestNoRetryByDefault() {
+	replier := &flakyReplier{failures: 1}
+	r := s.router(replier, nil)
+
+	s.Assert().Error(r.Process(context.Background(), []byte(`{"type": "test"}`)))
+	s.Assert().Equal(1, replier.calls)
+}
+
+func (s *ReplyRetrySuite) TestStopsWhenContextDone() {
+	replier := &flakyReplier{failures: 5}
+	r := s.router(replier, nil, WithReplyRetry(5, time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s.Assert().Error(r.Process(ctx, []byte(`{"type": "test"}`)))
+	s.Assert().Equal(1, replier.calls)
+}
@@ -0,0 +1,39 @@
+package dispatch
+
+import "context"
+
+// ReplierFactory builds a Replier for a message that names a reply-to
+// address, so request-response works over any transport without each source
+// constructing repliers itself.
+type ReplierFactory interface {
+	// NewReplier returns a Replier that sends responses to msg.ReplyTo.
+	// msg.CorrelationID is set so replies can be matched to their request.
+	NewReplier(ctx context.Context, msg Message) (Replier, error)
+}
+
+// ReplierFactoryFunc is a function adapter for ReplierFactory.
+type ReplierFactoryFunc func(ctx context.Context, msg Message) (Replier, error)
+
+// NewReplier implements the ReplierFactory interface.
+func (f ReplierFactoryFunc) NewReplier(ctx context.Context, msg Message) (Replier, error) {
+	return f(ctx, msg)
+}
+
+// WithReplierFactory sets the factory used to build a Replier for messages
+// that have a ReplyTo address but no Replier. If the factory returns an
+// error, the message fails without running its handler.
+//
+// Example:
+//
+//	r := dispatch.New(
+//	    dispatch.WithReplierFactory(dispatch.ReplierFactoryFunc(
+//	        func(ctx context.Context, msg dispatch.Message) (dispatch.Replier, error) {
+//	            return &sqsReplier{client: sqsClient, queueURL: msg.ReplyTo}, nil
+//	        },
+//	    )),
+//	)
+func WithReplierFactory(f ReplierFactory) Option {
+	return func(r *Router) {
+		r.replierFactory = f
+	}
+}
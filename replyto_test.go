@@ -0,0 +1,105 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ReplierFactorySuite struct {
+	suite.Suite
+}
+
+func TestReplierFactorySuite(t *testing.T) {
+	suite.Run(t, new(ReplierFactorySuite))
+}
+
+// addressReplier records replies sent to an address.
+type addressReplier struct {
+	address string
+	replies *[]string
+}
+
+func (r *addressReplier) Reply(ctx context.Context, result json.RawMessage) error {
+	*r.replies = append(*r.replies, r.address+":"+string(result))
+	return nil
+}
+
+func (r *addressReplier) Fail(ctx context.Context, err error) error {
+	*r.replies = append(*r.replies, r.address+":"+err.Error())
+	return nil
+}
+
+func (s *ReplierFactorySuite) router(msg Message, opts ...Option) *Router {
+	r := New(opts...)
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		return msg, nil
+	}))
+	RegisterFuncFunc(r, "echo", func(ctx context.Context, p struct{}) (string, error) {
+		return "ok", nil
+	})
+	return r
+}
+
+func (s *ReplierFactorySuite) TestBuildsReplierFromReplyTo() {
+	var replies []string
+	var gotCorrelation string
+
+	factory := ReplierFactoryFunc(func(ctx context.Context, msg Message) (Replier, error) {
+		gotCorrelation = msg.CorrelationID
+		return &addressReplier{address: msg.ReplyTo, replies: &replies}, nil
+	})
+	r := s.router(Message{Key: "echo", MessageID: "m-1", ReplyTo: "queue-a", Payload: []byte(`{}`)},
+		WithReplierFactory(factory))
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "echo"}`)))
+	s.Assert().Equal([]string{`queue-a:"ok"`}, replies)
+	s.Assert().Equal("m-1", gotCorrelation)
+}
+
+func (s *ReplierFactorySuite) TestKeepsSourceReplier() {
+	var replies []string
+	var called bool
+
+	factory := ReplierFactoryFunc(func(ctx context.Context, msg Message) (Replier, error) {
+		called = true
+		return nil, nil
+	})
+	r := s.router(Message{
+		Key:     "echo",
+		ReplyTo: "queue-a",
+		Replier: &addressReplier{address: "source", replies: &replies},
+		Payload: []byte(`{}`),
+	}, WithReplierFactory(factory))
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "echo"}`)))
+	s.Assert().False(called)
+	s.Assert().Equal([]string{`source:"ok"`}, replies)
+}
+
+func (s *ReplierFactorySuite) TestFactoryErrorFailsMessage() {
+	var handled bool
+	factory := ReplierFactoryFunc(func(ctx context.Context, msg Message) (Replier, error) {
+		return nil, errors.New("unknown address")
+	})
+	r := s.router(Message{Key: "echo", ReplyTo: "bogus", Payload: []byte(`{}`)}, WithReplierFactory(factory))
+	RegisterProcFunc(r, "echo", func(ctx context.Context, p struct{}) error {
+		handled = true
+		return nil
+	})
+
+	err := r.Process(context.Background(), []byte(`{"type": "echo"}`))
+
+	s.Assert().EqualError(err, "replier for bogus: unknown address")
+	s.Assert().False(handled)
+	s.Assert().Equal(uint64(1), r.Stats().Keys["echo"].Failed)
+}
+
+func (s *ReplierFactorySuite) TestIgnoredWithoutFactory() {
+	r := s.router(Message{Key: "echo", ReplyTo: "queue-a", Payload: []byte(`{}`)})
+
+	s.Assert().NoError(r.Process(context.Background(), []byte(`{"type": "echo"}`)))
+}
@@ -0,0 +1,67 @@
+// Package report sends handler failures to an error reporting service.
+//
+// ErrorReporter is a small interface so any backend can be plugged in; the
+// report/sentry module provides a Sentry implementation:
+//
+//	r := dispatch.New(report.Hooks(sentryreport.New())...)
+package report
+
+import (
+	"context"
+	"time"
+
+	"github.com/bjaus/dispatch"
+)
+
+// ErrorReporter sends an error and its dispatch context to a reporting
+// backend.
+type ErrorReporter interface {
+	Report(ctx context.Context, err error, info Info)
+}
+
+// ErrorReporterFunc is a function adapter for ErrorReporter.
+type ErrorReporterFunc func(ctx context.Context, err error, info Info)
+
+// Report implements the ErrorReporter interface.
+func (f ErrorReporterFunc) Report(ctx context.Context, err error, info Info) {
+	f(ctx, err, info)
+}
+
+// Info describes the message whose handler failed.
+type Info struct {
+	// Source is the name of the source that parsed the message.
+	Source string
+
+	// Key is the routing key of the message.
+	Key string
+
+	// Version is the payload schema version, if the source provided one.
+	Version string
+
+	// MessageID and CorrelationID identify the message, if the source
+	// provided them.
+	MessageID     string
+	CorrelationID string
+
+	// PayloadSize is the size of the payload in bytes.
+	PayloadSize int
+
+	// Duration is how long the handler ran before failing.
+	Duration time.Duration
+}
+
+// Hooks returns router options that report handler failures to reporter.
+func Hooks(reporter ErrorReporter) []dispatch.Option {
+	return []dispatch.Option{
+		dispatch.WithOnFailure(func(ctx context.Context, source, key string, err error, d time.Duration) {
+			info := Info{Source: source, Key: key, Duration: d}
+			if msg, ok := dispatch.MessageFromContext(ctx); ok {
+				info.Version = msg.Version
+				info.MessageID = msg.MessageID
+				info.CorrelationID = msg.CorrelationID
+				info.PayloadSize = len(msg.Payload)
+			}
+			reporter.Report(ctx, err, info)
+		}),
+	}
+}
@@ -0,0 +1,69 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+)
+
+type HooksSuite struct {
+	suite.Suite
+}
+
+func TestHooksSuite(t *testing.T) {
+	suite.Run(t, new(HooksSuite))
+}
+
+func (s *HooksSuite) TestReportsHandlerFailure() {
+	var gotErr error
+	var gotInfo Info
+	var calls int
+
+	reporter := ErrorReporterFunc(func(ctx context.Context, err error, info Info) {
+		calls++
+		gotErr = err
+		gotInfo = info
+	})
+
+	r := dispatch.New(Hooks(reporter)...)
+	r.AddSource(dispatch.SourceFunc("test", dispatch.HasFields("type"), func(raw []byte) (dispatch.Message, error) {
+		return dispatch.Message{Key: "user/created", Version: "v1", Payload: []byte(`{"id": 1}`)}, nil
+	}))
+
+	wantErr := errors.New("boom")
+	dispatch.RegisterProcFunc(r, "user/created", func(ctx context.Context, p struct{}) error {
+		return wantErr
+	})
+
+	_ = r.Process(context.Background(), []byte(`{"type": "x"}`))
+
+	s.Require().Equal(1, calls)
+	s.Assert().ErrorIs(gotErr, wantErr)
+	s.Assert().Equal("test", gotInfo.Source)
+	s.Assert().Equal("user/created", gotInfo.Key)
+	s.Assert().Equal("v1", gotInfo.Version)
+	s.Assert().Equal(9, gotInfo.PayloadSize)
+}
+
+func (s *HooksSuite) TestDoesNotReportSuccess() {
+	var calls int
+	reporter := ErrorReporterFunc(func(ctx context.Context, err error, info Info) {
+		calls++
+	})
+
+	r := dispatch.New(Hooks(reporter)...)
+	r.AddSource(dispatch.SourceFunc("test", dispatch.HasFields("type"), func(raw []byte) (dispatch.Message, error) {
+		return dispatch.Message{Key: "user/created", Payload: []byte(`{}`)}, nil
+	}))
+	dispatch.RegisterProcFunc(r, "user/created", func(ctx context.Context, p struct{}) error {
+		return nil
+	})
+
+	err := r.Process(context.Background(), []byte(`{"type": "x"}`))
+
+	s.Require().NoError(err)
+	s.Assert().Zero(calls)
+}
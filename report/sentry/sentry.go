@@ -0,0 +1,59 @@
+// Package sentry implements report.ErrorReporter with Sentry.
+//
+//	err := sentrygo.Init(sentrygo.ClientOptions{Dsn: dsn})
+//	r := dispatch.New(report.Hooks(sentry.New())...)
+//
+// Events are captured on the hub attached to the context when present
+// (for example, by sentry's HTTP or Lambda integrations), falling back to a
+// clone of the current hub.
+package sentry
+
+import (
+	"context"
+
+	"github.com/bjaus/dispatch/report"
+	sentrygo "github.com/getsentry/sentry-go"
+)
+
+// Reporter captures errors as Sentry exceptions tagged with the source and
+// key, with the remaining Info fields in a "dispatch" context.
+type Reporter struct {
+	hub *sentrygo.Hub
+}
+
+// New returns a Reporter that uses the hub from the context, or the current
+// hub when the context has none.
+func New() *Reporter {
+	return &Reporter{}
+}
+
+// NewWithHub returns a Reporter that always captures on hub.
+func NewWithHub(hub *sentrygo.Hub) *Reporter {
+	return &Reporter{hub: hub}
+}
+
+// Report implements report.ErrorReporter.
+func (r *Reporter) Report(ctx context.Context, err error, info report.Info) {
+	hub := r.hub
+	if hub == nil {
+		hub = sentrygo.GetHubFromContext(ctx)
+	}
+	if hub == nil {
+		hub = sentrygo.CurrentHub().Clone()
+	}
+
+	hub.WithScope(func(scope *sentrygo.Scope) {
+		scope.SetTag("dispatch.source", info.Source)
+		scope.SetTag("dispatch.key", info.Key)
+		scope.SetContext("dispatch", sentrygo.Context{
+			"version":        info.Version,
+			"message_id":     info.MessageID,
+			"correlation_id": info.CorrelationID,
+			"payload_size":   info.PayloadSize,
+			"duration_ms":    info.Duration.Milliseconds(),
+		})
+		hub.CaptureException(err)
+	})
+}
+
+var _ report.ErrorReporter = (*Reporter)(nil)
@@ -0,0 +1,64 @@
+package sentry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bjaus/dispatch/report"
+	sentrygo "github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/suite"
+)
+
+// recordingTransport captures events instead of sending them.
+type recordingTransport struct {
+	events []*sentrygo.Event
+}
+
+func (t *recordingTransport) Flush(time.Duration) bool              { return true }
+func (t *recordingTransport) FlushWithContext(context.Context) bool { return true }
+func (t *recordingTransport) Configure(sentrygo.ClientOptions)      {}
+func (t *recordingTransport) SendEvent(e *sentrygo.Event)           { t.events = append(t.events, e) }
+func (t *recordingTransport) Close()                                {}
+
+type ReporterSuite struct {
+	suite.Suite
+	transport *recordingTransport
+	hub       *sentrygo.Hub
+}
+
+func (s *ReporterSuite) SetupTest() {
+	s.transport = &recordingTransport{}
+	client, err := sentrygo.NewClient(sentrygo.ClientOptions{Transport: s.transport})
+	s.Require().NoError(err)
+	s.hub = sentrygo.NewHub(client, sentrygo.NewScope())
+}
+
+func TestReporterSuite(t *testing.T) {
+	suite.Run(t, new(ReporterSuite))
+}
+
+func (s *ReporterSuite) TestCapturesExceptionWithDispatchContext() {
+	r := NewWithHub(s.hub)
+
+	r.Report(context.Background(), errors.New("boom"), report.Info{
+		Source:      "eventbridge",
+		Key:         "user/created",
+		PayloadSize: 42,
+	})
+
+	s.Require().Len(s.transport.events, 1)
+	event := s.transport.events[0]
+	s.Assert().Equal("eventbridge", event.Tags["dispatch.source"])
+	s.Assert().Equal("user/created", event.Tags["dispatch.key"])
+	s.Assert().Equal(42, event.Contexts["dispatch"]["payload_size"])
+}
+
+func (s *ReporterSuite) TestUsesHubFromContext() {
+	ctx := sentrygo.SetHubOnContext(context.Background(), s.hub)
+
+	New().Report(ctx, errors.New("boom"), report.Info{Source: "sns", Key: "k"})
+
+	s.Assert().Len(s.transport.events, 1)
+}
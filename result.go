@@ -0,0 +1,129 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Result describes how ProcessResult handled a message.
+type Result struct {
+	// Source is the name of the matched source, or empty if none matched.
+	Source string
+
+	// Key is the message's routing key, and Keys all of them if it has
+	// several. Both are empty if the message wasn't parsed.
+	Key  string
+	Keys []string
+
+	// Version is the message's schema version, if any.
+	Version string
+
+	// Duration is the time taken to process the message, from matching
+	// through the reply.
+	Duration time.Duration
+
+	// Replied reports whether the message had a Replier, so its result or
+	// failure was also sent through it.
+	Replied bool
+
+	// Result is the marshaled result of a handler that succeeded, before
+	// WithOnReply hooks, and nil otherwise. A Proc's result is {}. For a
+	// message with several keys, it is a JSON object of each key's result,
+	// as sent to a Replier.
+	Result json.RawMessage
+}
+
+// ProcessResult processes a raw message like Process, and also returns
+// what happened to it. Use it when the router is embedded in a synchronous
+// path, such as an HTTP handler, that needs the handler's result or
+// wants to report the matched source and key.
+//
+// The Result is filled in as far as processing got, so it is useful even
+// when the error is non-nil. opts apply to this call as they do for
+// Process.
+//
+// Example:
+//
+//	res, err := router.ProcessResult(req.Context(), body)
+//	if err != nil {
+//	    http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+//	    return
+//	}
+//	w.Header().Set("X-Dispatch-Key", res.Key)
+//	w.Write(res.Result)
+func (r *Router) ProcessResult(ctx context.Context, raw []byte, opts ...ProcessOption) (Result, error) {
+	if !r.begin() {
+		return Result{}, ErrShutdown
+	}
+	defer r.end()
+
+	start := time.Now()
+	c := &resultCollector{}
+	ctx = context.WithValue(ctx, resultCollectorKey{}, c)
+
+	var res Result
+	var derr *DispatchError
+	p, err := r.parse(ctx, raw, newProcessConfig(opts))
+	switch {
+	case p != nil:
+		res.Source = p.sourceName
+		res.Key = p.msg.Key
+		res.Keys = p.msg.Keys
+		res.Version = p.msg.Version
+		res.Replied = p.msg.Replier != nil
+		err = r.dispatch(ctx, p)
+	case errors.As(err, &derr):
+		res.Source = derr.Source
+	}
+	res.Duration = time.Since(start)
+	res.Result = c.result(res.Keys)
+	return res, err
+}
+
+// ProcessResult processes a raw message and describes what happened. See
+// Router.ProcessResult.
+func (c *CompiledRouter) ProcessResult(ctx context.Context, raw []byte, opts ...ProcessOption) (Result, error) {
+	return c.r.ProcessResult(ctx, raw, opts...)
+}
+
+// resultCollectorKey carries the resultCollector of a ProcessResult call.
+type resultCollectorKey struct{}
+
+// resultCollector records each key's handler result for ProcessResult.
+type resultCollector struct {
+	results map[string]json.RawMessage
+}
+
+// collectResult records the handler result for key, if ctx comes from
+// ProcessResult.
+func collectResult(ctx context.Context, key string, result json.RawMessage) {
+	c, ok := ctx.Value(resultCollectorKey{}).(*resultCollector)
+	if !ok || result == nil {
+		return
+	}
+	if c.results == nil {
+		c.results = make(map[string]json.RawMessage)
+	}
+	c.results[key] = result
+}
+
+// result returns the collected result: the only one for a message with a
+// single key, or an object of every key's result for a message with keys.
+func (c *resultCollector) result(keys []string) json.RawMessage {
+	if len(keys) <= 1 {
+		for _, result := range c.results {
+			return result
+		}
+		return nil
+	}
+	if len(c.results) == 0 {
+		return nil
+	}
+	out, err := json.Marshal(c.results)
+	if err != nil {
+		return nil
+	}
+	return out
+}
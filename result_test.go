@@ -0,0 +1,114 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ResultSuite struct {
+	suite.Suite
+	router *Router
+}
+
+func TestResultSuite(t *testing.T) {
+	suite.Run(t, new(ResultSuite))
+}
+
+func (s *ResultSuite) SetupTest() {
+	s.router = New()
+	s.router.AddSource(&testSource{name: "test"})
+	RegisterFuncFunc(s.router, "echo", func(ctx context.Context, p testPayload) (testPayload, error) {
+		return p, nil
+	})
+	RegisterProcFunc(s.router, "proc", func(ctx context.Context, p testPayload) error {
+		return nil
+	})
+	RegisterFuncFunc(s.router, "fail", func(ctx context.Context, p testPayload) (testPayload, error) {
+		return p, errors.New("boom")
+	})
+}
+
+func (s *ResultSuite) TestFuncResult() {
+	res, err := s.router.ProcessResult(context.Background(), []byte(`{"type": "echo", "payload": {"value": "hi"}}`))
+	s.Require().NoError(err)
+
+	s.Assert().Equal("test", res.Source)
+	s.Assert().Equal("echo", res.Key)
+	s.Assert().False(res.Replied)
+	s.Assert().Positive(res.Duration)
+	s.Assert().JSONEq(`{"value": "hi"}`, string(res.Result))
+}
+
+func (s *ResultSuite) TestProcResult() {
+	res, err := s.router.ProcessResult(context.Background(), []byte(`{"type": "proc", "payload": {}}`))
+	s.Require().NoError(err)
+	s.Assert().Equal("proc", res.Key)
+	s.Assert().JSONEq(`{}`, string(res.Result))
+}
+
+func (s *ResultSuite) TestFailure() {
+	res, err := s.router.ProcessResult(context.Background(), []byte(`{"type": "fail", "payload": {}}`))
+	s.Require().EqualError(err, "boom")
+	s.Assert().Equal("fail", res.Key)
+	s.Assert().Nil(res.Result)
+}
+
+func (s *ResultSuite) TestNoSource() {
+	res, err := s.router.ProcessResult(context.Background(), []byte(`{"other": true}`))
+	s.Require().ErrorIs(err, ErrNoSource)
+	s.Assert().Empty(res.Source)
+	s.Assert().Empty(res.Key)
+}
+
+func (s *ResultSuite) TestReplierAndVersion() {
+	replier := &keysReplier{}
+	r := New()
+	r.AddSource(SourceFunc("versioned", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "echo", Version: "v2", Payload: []byte(`{"value": "hi"}`), Replier: replier}, nil
+	}))
+	RegisterFuncFunc(r, "echo", func(ctx context.Context, p testPayload) (testPayload, error) {
+		return p, nil
+	})
+
+	res, err := r.ProcessResult(context.Background(), []byte(`{"type": "echo"}`))
+	s.Require().NoError(err)
+	s.Assert().Equal("versioned", res.Source)
+	s.Assert().Equal("v2", res.Version)
+	s.Assert().True(res.Replied)
+	s.Assert().JSONEq(`{"value": "hi"}`, string(res.Result))
+	s.Assert().JSONEq(`{"value": "hi"}`, string(replier.result))
+}
+
+func (s *ResultSuite) TestMultipleKeys() {
+	r := New()
+	r.AddSource(SourceFunc("multi", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Keys: []string{"a", "b"}, Payload: []byte(`{"value": "hi"}`)}, nil
+	}))
+	RegisterFuncFunc(r, "a", func(ctx context.Context, p testPayload) (string, error) {
+		return "from a", nil
+	})
+	RegisterFuncFunc(r, "b", func(ctx context.Context, p testPayload) (string, error) {
+		return "from b", nil
+	})
+
+	res, err := r.ProcessResult(context.Background(), []byte(`{"type": "x"}`))
+	s.Require().NoError(err)
+	s.Assert().Equal("a", res.Key)
+	s.Assert().Equal([]string{"a", "b"}, res.Keys)
+	s.Assert().JSONEq(`{"a": "from a", "b": "from b"}`, string(res.Result))
+}
+
+func (s *ResultSuite) TestCompiled() {
+	res, err := s.router.Build().ProcessResult(context.Background(), []byte(`{"type": "echo", "payload": {"value": "hi"}}`))
+	s.Require().NoError(err)
+	s.Assert().JSONEq(`{"value": "hi"}`, string(res.Result))
+}
+
+func (s *ResultSuite) TestShutdown() {
+	s.Require().NoError(s.router.Shutdown(context.Background()))
+	_, err := s.router.ProcessResult(context.Background(), []byte(`{"type": "echo", "payload": {}}`))
+	s.Assert().ErrorIs(err, ErrShutdown)
+}
@@ -0,0 +1,248 @@
+// Package routeconfig builds dispatch routers from declarative YAML or JSON
+// configuration, and reloads them when the configuration file changes, so
+// platforms can change routing without redeploying.
+//
+// Handlers stay in code and are registered by name; the configuration
+// defines sources and binds routing keys to handler names:
+//
+//	sources:
+//	  - name: orders
+//	    match:
+//	      equals: {source: orders}
+//	      has: [detail-type]
+//	    key: detail-type
+//	    payload: detail
+//	    id: id
+//	routes:
+//	  order.created: createOrder
+//	  order.cancelled: cancelOrder
+//
+// Paths use gjson syntax, as dispatch.JSONInspector does.
+package routeconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/bjaus/dispatch"
+	"github.com/tidwall/gjson"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is a declarative routing configuration.
+type Config struct {
+	// Sources are added to the router in order.
+	Sources []SourceConfig `yaml:"sources" json:"sources"`
+	// Routes maps routing keys to handler names registered in Handlers.
+	Routes map[string]string `yaml:"routes" json:"routes"`
+}
+
+// SourceConfig defines a JSON source. Each field other than Name and Match
+// is the path of a message field; Key is required, and an empty Payload
+// uses the whole message as the payload.
+type SourceConfig struct {
+	Name          string `yaml:"name" json:"name"`
+	Match         Match  `yaml:"match" json:"match"`
+	Key           string `yaml:"key" json:"key"`
+	Payload       string `yaml:"payload,omitempty" json:"payload,omitempty"`
+	ID            string `yaml:"id,omitempty" json:"id,omitempty"`
+	CorrelationID string `yaml:"correlationId,omitempty" json:"correlationId,omitempty"`
+	Version       string `yaml:"version,omitempty" json:"version,omitempty"`
+	// Timestamp is the path of an RFC 3339 timestamp.
+	Timestamp string `yaml:"timestamp,omitempty" json:"timestamp,omitempty"`
+	// Deadline is the path of an RFC 3339 time after which handling stops.
+	Deadline string `yaml:"deadline,omitempty" json:"deadline,omitempty"`
+}
+
+// Match describes a source's discriminator. All of its conditions must
+// hold; map conditions are keyed by path.
+type Match struct {
+	Has      []string          `yaml:"has,omitempty" json:"has,omitempty"`
+	Equals   map[string]string `yaml:"equals,omitempty" json:"equals,omitempty"`
+	Prefix   map[string]string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+	Suffix   map[string]string `yaml:"suffix,omitempty" json:"suffix,omitempty"`
+	Contains map[string]string `yaml:"contains,omitempty" json:"contains,omitempty"`
+	True     []string          `yaml:"true,omitempty" json:"true,omitempty"`
+	All      []Match           `yaml:"all,omitempty" json:"all,omitempty"`
+	Any      []Match           `yaml:"any,omitempty" json:"any,omitempty"`
+	Not      *Match            `yaml:"not,omitempty" json:"not,omitempty"`
+}
+
+// Parse decodes a YAML or JSON configuration. Unknown fields are errors, so
+// typos are caught before a configuration is applied.
+func Parse(data []byte) (Config, error) {
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		return Config{}, fmt.Errorf("routeconfig: %w", err)
+	}
+	return cfg, nil
+}
+
+// Apply adds the configured sources to r and registers the handler named by
+// each route. It fails without registering anything if a source is invalid
+// or a route names an unknown handler.
+func (c Config) Apply(r *dispatch.Router, h *Handlers) error {
+	sources := make([]dispatch.Source, 0, len(c.Sources))
+	var errs []error
+	for i, sc := range c.Sources {
+		src, err := newSource(sc)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("source %d (%s): %w", i, sc.Name, err))
+			continue
+		}
+		sources = append(sources, src)
+	}
+	keys := slices.Sorted(maps.Keys(c.Routes))
+	for _, key := range keys {
+		if _, ok := h.binders[c.Routes[key]]; !ok {
+			errs = append(errs, fmt.Errorf("route %s: unknown handler %q", key, c.Routes[key]))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("routeconfig: %w", errors.Join(errs...))
+	}
+
+	for _, src := range sources {
+		r.AddSource(src)
+	}
+	for _, key := range keys {
+		if err := h.bind(r, key, c.Routes[key]); err != nil {
+			return fmt.Errorf("routeconfig: route %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// discriminator converts m to a dispatch.Discriminator.
+func (m Match) discriminator() (dispatch.Discriminator, error) {
+	var ds []dispatch.Discriminator
+	if len(m.Has) > 0 {
+		ds = append(ds, dispatch.HasFields(m.Has...))
+	}
+	for _, cond := range []struct {
+		fields map[string]string
+		fn     func(path, value string) dispatch.Discriminator
+	}{
+		{m.Equals, dispatch.FieldEquals},
+		{m.Prefix, dispatch.FieldHasPrefix},
+		{m.Suffix, dispatch.FieldHasSuffix},
+		{m.Contains, dispatch.FieldContains},
+	} {
+		for _, path := range slices.Sorted(maps.Keys(cond.fields)) {
+			ds = append(ds, cond.fn(path, cond.fields[path]))
+		}
+	}
+	for _, path := range m.True {
+		ds = append(ds, dispatch.FieldTrue(path))
+	}
+	for _, sub := range m.All {
+		d, err := sub.discriminator()
+		if err != nil {
+			return nil, err
+		}
+		ds = append(ds, d)
+	}
+	if len(m.Any) > 0 {
+		anyOf := make([]dispatch.Discriminator, len(m.Any))
+		for i, sub := range m.Any {
+			d, err := sub.discriminator()
+			if err != nil {
+				return nil, err
+			}
+			anyOf[i] = d
+		}
+		ds = append(ds, dispatch.Or(anyOf...))
+	}
+	if m.Not != nil {
+		d, err := m.Not.discriminator()
+		if err != nil {
+			return nil, err
+		}
+		ds = append(ds, dispatch.Not(d))
+	}
+
+	switch len(ds) {
+	case 0:
+		return nil, errors.New("match has no conditions")
+	case 1:
+		return ds[0], nil
+	default:
+		return dispatch.And(ds...), nil
+	}
+}
+
+// source is a dispatch.Source built from a SourceConfig.
+type source struct {
+	cfg           SourceConfig
+	discriminator dispatch.Discriminator
+}
+
+func newSource(cfg SourceConfig) (*source, error) {
+	if cfg.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	if cfg.Key == "" {
+		return nil, errors.New("key is required")
+	}
+	d, err := cfg.Match.discriminator()
+	if err != nil {
+		return nil, err
+	}
+	return &source{cfg: cfg, discriminator: d}, nil
+}
+
+func (s *source) Name() string { return s.cfg.Name }
+
+func (s *source) Discriminator() dispatch.Discriminator { return s.discriminator }
+
+// Parse reads the configured paths from raw.
+func (s *source) Parse(raw []byte) (dispatch.Message, error) {
+	key := gjson.GetBytes(raw, s.cfg.Key)
+	if key.Type != gjson.String || key.Str == "" {
+		return dispatch.Message{}, fmt.Errorf("missing routing key at %s", s.cfg.Key)
+	}
+	msg := dispatch.Message{
+		Key:           key.Str,
+		Payload:       raw,
+		MessageID:     s.str(raw, s.cfg.ID),
+		CorrelationID: s.str(raw, s.cfg.CorrelationID),
+		Version:       s.str(raw, s.cfg.Version),
+	}
+	if s.cfg.Payload != "" {
+		payload := gjson.GetBytes(raw, s.cfg.Payload)
+		if !payload.Exists() {
+			return dispatch.Message{}, fmt.Errorf("missing payload at %s", s.cfg.Payload)
+		}
+		msg.Payload = []byte(payload.Raw)
+	}
+	if ts := s.str(raw, s.cfg.Timestamp); ts != "" {
+		t, err := time.Parse(time.RFC3339, ts)
+		if err != nil {
+			return dispatch.Message{}, fmt.Errorf("timestamp at %s: %w", s.cfg.Timestamp, err)
+		}
+		msg.Timestamp = t
+	}
+	if ts := s.str(raw, s.cfg.Deadline); ts != "" {
+		t, err := time.Parse(time.RFC3339, ts)
+		if err != nil {
+			return dispatch.Message{}, fmt.Errorf("deadline at %s: %w", s.cfg.Deadline, err)
+		}
+		msg.Deadline = t
+	}
+	return msg, nil
+}
+
+// str returns the value at path as a string, or "" if path is empty or
+// missing.
+func (s *source) str(raw []byte, path string) string {
+	if path == "" {
+		return ""
+	}
+	return gjson.GetBytes(raw, path).String()
+}
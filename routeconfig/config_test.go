@@ -0,0 +1,214 @@
+package routeconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+)
+
+type order struct {
+	ID string `json:"id"`
+}
+
+type recorder struct {
+	got []order
+}
+
+func (r *recorder) Run(ctx context.Context, o order) error {
+	r.got = append(r.got, o)
+	return nil
+}
+
+const ordersConfig = `
+sources:
+  - name: orders
+    match:
+      equals: {source: orders}
+      has: [detail-type]
+    key: detail-type
+    payload: detail
+    id: id
+    timestamp: time
+    deadline: expires
+routes:
+  order.created: record
+`
+
+type ConfigSuite struct {
+	suite.Suite
+	handlers *Handlers
+	rec      *recorder
+}
+
+func TestConfigSuite(t *testing.T) {
+	suite.Run(t, new(ConfigSuite))
+}
+
+func (s *ConfigSuite) SetupTest() {
+	s.rec = &recorder{}
+	s.handlers = NewHandlers()
+	RegisterProc(s.handlers, "record", s.rec)
+}
+
+func (s *ConfigSuite) apply(data string) (*dispatch.Router, error) {
+	cfg, err := Parse([]byte(data))
+	s.Require().NoError(err)
+	r := dispatch.New()
+	return r, cfg.Apply(r, s.handlers)
+}
+
+func (s *ConfigSuite) TestApply_RoutesMessages() {
+	r, err := s.apply(ordersConfig)
+	s.Require().NoError(err)
+
+	err = r.Process(context.Background(), []byte(`{
+		"id": "m-1",
+		"source": "orders",
+		"time": "2026-01-02T03:04:05Z",
+		"detail-type": "order.created",
+		"detail": {"id": "o-1"}
+	}`))
+	s.Require().NoError(err)
+	s.Assert().Equal([]order{{ID: "o-1"}}, s.rec.got)
+}
+
+func (s *ConfigSuite) TestApply_ParsesMessageFields() {
+	cfg, err := Parse([]byte(ordersConfig))
+	s.Require().NoError(err)
+	src, err := newSource(cfg.Sources[0])
+	s.Require().NoError(err)
+
+	msg, err := src.Parse([]byte(`{"id": "m-1", "time": "2026-01-02T03:04:05Z", "expires": "2026-01-02T03:09:05Z", "detail-type": "order.created", "detail": {"id": "o-1"}}`))
+	s.Require().NoError(err)
+
+	s.Assert().Equal("order.created", msg.Key)
+	s.Assert().Equal("m-1", msg.MessageID)
+	s.Assert().Equal(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), msg.Timestamp)
+	s.Assert().Equal(time.Date(2026, 1, 2, 3, 9, 5, 0, time.UTC), msg.Deadline)
+	s.Assert().JSONEq(`{"id": "o-1"}`, string(msg.Payload))
+}
+
+func (s *ConfigSuite) TestParse_WholeMessagePayload() {
+	src, err := newSource(SourceConfig{Name: "flat", Match: Match{Has: []string{"type"}}, Key: "type"})
+	s.Require().NoError(err)
+
+	raw := []byte(`{"type": "order.created", "id": "o-1"}`)
+	msg, err := src.Parse(raw)
+	s.Require().NoError(err)
+
+	s.Assert().Equal(raw, []byte(msg.Payload))
+}
+
+func (s *ConfigSuite) TestParse_MissingKeyOrPayload() {
+	src, err := newSource(SourceConfig{Name: "orders", Match: Match{Has: []string{"type"}}, Key: "type", Payload: "detail"})
+	s.Require().NoError(err)
+
+	_, err = src.Parse([]byte(`{"detail": {}}`))
+	s.Assert().ErrorContains(err, "missing routing key at type")
+
+	_, err = src.Parse([]byte(`{"type": "x"}`))
+	s.Assert().ErrorContains(err, "missing payload at detail")
+}
+
+func (s *ConfigSuite) TestParse_AcceptsJSON() {
+	cfg, err := Parse([]byte(`{"sources": [{"name": "a", "match": {"has": ["t"]}, "key": "t"}], "routes": {"k": "record"}}`))
+	s.Require().NoError(err)
+
+	s.Assert().Equal("a", cfg.Sources[0].Name)
+	s.Assert().Equal(map[string]string{"k": "record"}, cfg.Routes)
+}
+
+func (s *ConfigSuite) TestParse_RejectsUnknownFields() {
+	_, err := Parse([]byte("sources:\n  - name: a\n    mtach: {has: [t]}\n"))
+	s.Assert().ErrorContains(err, "mtach")
+}
+
+func (s *ConfigSuite) TestMatch_Discriminator() {
+	m := Match{
+		Has:      []string{"a"},
+		Equals:   map[string]string{"b": "x"},
+		Prefix:   map[string]string{"c": "p"},
+		Suffix:   map[string]string{"c": "s"},
+		Contains: map[string]string{"c": "m"},
+		True:     []string{"d"},
+		All:      []Match{{Has: []string{"e"}}},
+		Any:      []Match{{Has: []string{"f"}}, {Has: []string{"g"}}},
+		Not:      &Match{Has: []string{"h"}},
+	}
+	d, err := m.discriminator()
+	s.Require().NoError(err)
+
+	table := dispatch.New()
+	table.AddSource(&source{cfg: SourceConfig{Name: "s"}, discriminator: d})
+	s.Assert().Equal(
+		`and(has(a), b == "x", hasPrefix(c, "p"), hasSuffix(c, "s"), contains(c, "m"), d == true, has(e), or(has(f), has(g)), not(has(h)))`,
+		table.RoutingTable().Sources[0].Discriminator,
+	)
+}
+
+func (s *ConfigSuite) TestApply_RejectsInvalidConfig() {
+	r, err := s.apply(`
+sources:
+  - name: nomatch
+    key: type
+  - match: {has: [type]}
+    key: type
+  - name: nokey
+    match: {has: [type]}
+routes:
+  a: record
+  b: missing
+`)
+	s.Require().Error(err)
+	s.Assert().ErrorContains(err, "source 0 (nomatch): match has no conditions")
+	s.Assert().ErrorContains(err, "source 1 (): name is required")
+	s.Assert().ErrorContains(err, "source 2 (nokey): key is required")
+	s.Assert().ErrorContains(err, `route b: unknown handler "missing"`)
+	s.Assert().Empty(r.RoutingTable().Sources)
+	s.Assert().Empty(r.RoutingTable().Handlers)
+}
+
+func (s *ConfigSuite) TestApply_RegistrationPanicBecomesError() {
+	reg := dispatch.NewRegistry()
+	dispatch.Register[string](reg, "order.created")
+	r := dispatch.New(dispatch.WithRegistry(reg))
+	cfg, err := Parse([]byte(ordersConfig))
+	s.Require().NoError(err)
+
+	err = cfg.Apply(r, s.handlers)
+
+	s.Assert().ErrorContains(err, "route order.created:")
+}
+
+func (s *ConfigSuite) TestHandlers_BindsOneHandlerToManyKeys() {
+	RegisterFunc(s.handlers, "lookup", dispatch.FuncFunc[order, string](func(ctx context.Context, o order) (string, error) {
+		return o.ID, nil
+	}))
+	r, err := s.apply(`
+sources:
+  - name: flat
+    match: {has: [type]}
+    key: type
+routes:
+  a: lookup
+  b: lookup
+  c: record
+`)
+	s.Require().NoError(err)
+
+	var keys []string
+	for _, h := range r.RoutingTable().Handlers {
+		keys = append(keys, h.Key+":"+h.Kind)
+	}
+	s.Assert().Equal([]string{"a:func", "b:func", "c:proc"}, keys)
+	s.Assert().Equal([]string{"lookup", "record"}, s.handlers.Names())
+}
+
+func (s *ConfigSuite) TestHandlers_PanicsOnDuplicateName() {
+	s.Assert().PanicsWithValue(`routeconfig: handler "record" is already registered`, func() {
+		RegisterProc(s.handlers, "record", s.rec)
+	})
+}
@@ -0,0 +1,69 @@
+package routeconfig
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/bjaus/dispatch"
+)
+
+// Handlers maps handler names to handlers, so configuration can bind routing
+// keys to handlers without code changes. A handler may be bound to several
+// keys.
+type Handlers struct {
+	binders map[string]binder
+}
+
+// binder registers a handler for key on r.
+type binder func(r *dispatch.Router, key string)
+
+// NewHandlers returns an empty handler registry.
+func NewHandlers() *Handlers {
+	return &Handlers{binders: make(map[string]binder)}
+}
+
+// RegisterProc adds p to h under name. Options apply to every key p is bound
+// to. It panics if name is already registered.
+//
+// Example:
+//
+//	routeconfig.RegisterProc(h, "createOrder", &CreateOrderProc{db: db})
+func RegisterProc[T any](h *Handlers, name string, p dispatch.Proc[T], opts ...dispatch.HandlerOption) {
+	h.add(name, func(r *dispatch.Router, key string) {
+		dispatch.RegisterProc(r, key, p, opts...)
+	})
+}
+
+// RegisterFunc adds f to h under name. Options apply to every key f is bound
+// to. It panics if name is already registered.
+func RegisterFunc[T, R any](h *Handlers, name string, f dispatch.Func[T, R], opts ...dispatch.HandlerOption) {
+	h.add(name, func(r *dispatch.Router, key string) {
+		dispatch.RegisterFunc(r, key, f, opts...)
+	})
+}
+
+func (h *Handlers) add(name string, b binder) {
+	if _, ok := h.binders[name]; ok {
+		panic(fmt.Sprintf("routeconfig: handler %q is already registered", name))
+	}
+	h.binders[name] = b
+}
+
+// Names returns the registered handler names in sorted order.
+func (h *Handlers) Names() []string {
+	return slices.Sorted(maps.Keys(h.binders))
+}
+
+// bind registers the handler named name for key on r, turning a
+// registration panic, such as a payload type that disagrees with the
+// router's Registry, into an error.
+func (h *Handlers) bind(r *dispatch.Router, key, name string) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%v", p)
+		}
+	}()
+	h.binders[name](r, key)
+	return nil
+}
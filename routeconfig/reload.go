@@ -0,0 +1,185 @@
+package routeconfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bjaus/dispatch"
+)
+
+// Option configures a Reloader.
+type Option func(*Reloader)
+
+// WithBase sets the function that creates the router each configuration is
+// applied to. Use it to set router options, hooks, and sources defined in
+// code. Defaults to dispatch.New with no options.
+//
+// Example:
+//
+//	routeconfig.WithBase(func() *dispatch.Router {
+//	    r := dispatch.New(dispatch.WithSlog(logger))
+//	    r.AddSource(snsSource)
+//	    return r
+//	})
+func WithBase(base func() *dispatch.Router) Option {
+	return func(l *Reloader) {
+		l.base = base
+	}
+}
+
+// WithPollInterval sets how often Watch checks the file for changes.
+// Defaults to 5 seconds.
+func WithPollInterval(d time.Duration) Option {
+	return func(l *Reloader) {
+		l.interval = d
+	}
+}
+
+// WithOnReload sets a function called after every reload attempt made by
+// Watch, with nil on success or the reason the configuration was rejected.
+// Use it to log or alert on bad configuration.
+func WithOnReload(fn func(err error)) Option {
+	return func(l *Reloader) {
+		l.onReload = fn
+	}
+}
+
+// Reloader serves messages with a router built from a configuration file,
+// and rebuilds it when the file changes. A configuration that fails to
+// parse or apply is rejected and the previous router keeps serving.
+//
+// Each configuration is compiled with Router.Build and swapped in
+// atomically: messages already being processed finish on the router they
+// started on, and Stats start from zero after each reload.
+//
+// Example:
+//
+//	h := routeconfig.NewHandlers()
+//	routeconfig.RegisterProc(h, "createOrder", &CreateOrderProc{db: db})
+//
+//	l, err := routeconfig.NewReloader("routes.yaml", h)
+//	if err != nil {
+//	    return err
+//	}
+//	go l.Watch(ctx)
+//
+//	err = l.Process(ctx, raw)
+type Reloader struct {
+	path     string
+	handlers *Handlers
+	base     func() *dispatch.Router
+	interval time.Duration
+	onReload func(error)
+
+	current atomic.Pointer[dispatch.CompiledRouter]
+
+	mu      sync.Mutex // serializes reloads
+	modTime time.Time
+	size    int64
+}
+
+// NewReloader loads the configuration at path and builds the first router.
+// It returns an error if the configuration cannot be loaded.
+func NewReloader(path string, h *Handlers, opts ...Option) (*Reloader, error) {
+	l := &Reloader{
+		path:     path,
+		handlers: h,
+		base:     func() *dispatch.Router { return dispatch.New() },
+		interval: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if err := l.Reload(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Router returns the router built from the current configuration.
+func (l *Reloader) Router() *dispatch.CompiledRouter {
+	return l.current.Load()
+}
+
+// Process processes raw with the current router.
+func (l *Reloader) Process(ctx context.Context, raw []byte, opts ...dispatch.ProcessOption) error {
+	return l.current.Load().Process(ctx, raw, opts...)
+}
+
+// Reload reads the configuration file and, if it is valid, replaces the
+// current router. On error the current router is kept.
+func (l *Reloader) Reload() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return fmt.Errorf("routeconfig: %w", err)
+	}
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return fmt.Errorf("routeconfig: %w", err)
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	r := l.base()
+	if err := cfg.Apply(r, l.handlers); err != nil {
+		return err
+	}
+	l.current.Store(r.Build())
+	l.modTime, l.size = info.ModTime(), info.Size()
+	return nil
+}
+
+// Watch polls the configuration file and reloads it when its modification
+// time or size changes, until ctx is canceled. It returns ctx.Err().
+func (l *Reloader) Watch(ctx context.Context) error {
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if !l.changed() {
+				continue
+			}
+			err := l.Reload()
+			if l.onReload != nil {
+				l.onReload(err)
+			}
+			if err != nil {
+				l.markSeen()
+			}
+		}
+	}
+}
+
+// changed reports whether the file differs from the last one seen.
+func (l *Reloader) changed() bool {
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return !info.ModTime().Equal(l.modTime) || info.Size() != l.size
+}
+
+// markSeen records the file's current state after a rejected reload, so a
+// bad configuration is reported once rather than on every poll.
+func (l *Reloader) markSeen() {
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.modTime, l.size = info.ModTime(), info.Size()
+}
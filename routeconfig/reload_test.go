@@ -0,0 +1,145 @@
+package routeconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReloaderSuite struct {
+	suite.Suite
+	path     string
+	handlers *Handlers
+	created  *recorder
+	updated  *recorder
+}
+
+func TestReloaderSuite(t *testing.T) {
+	suite.Run(t, new(ReloaderSuite))
+}
+
+func (s *ReloaderSuite) SetupTest() {
+	s.path = filepath.Join(s.T().TempDir(), "routes.yaml")
+	s.created = &recorder{}
+	s.updated = &recorder{}
+	s.handlers = NewHandlers()
+	RegisterProc(s.handlers, "created", s.created)
+	RegisterProc(s.handlers, "updated", s.updated)
+	s.write("order.created: created")
+}
+
+// write saves a configuration with a single flat source and the given route.
+func (s *ReloaderSuite) write(route string) {
+	data := "sources:\n  - name: flat\n    match: {has: [type]}\n    key: type\nroutes:\n  " + route + "\n"
+	s.Require().NoError(os.WriteFile(s.path, []byte(data), 0o600))
+}
+
+func (s *ReloaderSuite) process(l *Reloader, key string) error {
+	return l.Process(context.Background(), []byte(`{"type": "`+key+`", "id": "o-1"}`))
+}
+
+func (s *ReloaderSuite) TestNewReloader_LoadsConfig() {
+	l, err := NewReloader(s.path, s.handlers)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.process(l, "order.created"))
+	s.Assert().Len(s.created.got, 1)
+	s.Assert().ErrorIs(s.process(l, "order.updated"), dispatch.ErrNoHandler)
+}
+
+func (s *ReloaderSuite) TestNewReloader_FailsOnInvalidConfig() {
+	s.write("order.created: missing")
+
+	_, err := NewReloader(s.path, s.handlers)
+
+	s.Assert().ErrorContains(err, `unknown handler "missing"`)
+}
+
+func (s *ReloaderSuite) TestNewReloader_FailsOnMissingFile() {
+	_, err := NewReloader(filepath.Join(s.T().TempDir(), "none.yaml"), s.handlers)
+
+	s.Assert().ErrorIs(err, os.ErrNotExist)
+}
+
+func (s *ReloaderSuite) TestReload_SwapsRouter() {
+	l, err := NewReloader(s.path, s.handlers)
+	s.Require().NoError(err)
+	before := l.Router()
+
+	s.write("order.updated: updated")
+	s.Require().NoError(l.Reload())
+
+	s.Assert().NotSame(before, l.Router())
+	s.Require().NoError(s.process(l, "order.updated"))
+	s.Assert().Len(s.updated.got, 1)
+	s.Assert().ErrorIs(s.process(l, "order.created"), dispatch.ErrNoHandler)
+}
+
+func (s *ReloaderSuite) TestReload_KeepsRouterOnError() {
+	l, err := NewReloader(s.path, s.handlers)
+	s.Require().NoError(err)
+	before := l.Router()
+
+	s.write("order.updated: missing")
+
+	s.Assert().Error(l.Reload())
+	s.Assert().Same(before, l.Router())
+	s.Assert().NoError(s.process(l, "order.created"))
+}
+
+func (s *ReloaderSuite) TestWithBase() {
+	var seen []string
+	l, err := NewReloader(s.path, s.handlers, WithBase(func() *dispatch.Router {
+		return dispatch.New(dispatch.WithOnSuccess(func(ctx context.Context, source, key string, d time.Duration) {
+			seen = append(seen, source+"/"+key)
+		}))
+	}))
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.process(l, "order.created"))
+
+	s.Assert().Equal([]string{"flat/order.created"}, seen)
+}
+
+func (s *ReloaderSuite) TestWatch_ReloadsOnChange() {
+	var (
+		mu      sync.Mutex
+		results []error
+	)
+	l, err := NewReloader(s.path, s.handlers,
+		WithPollInterval(time.Millisecond),
+		WithOnReload(func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			results = append(results, err)
+		}),
+	)
+	s.Require().NoError(err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- l.Watch(ctx) }()
+
+	s.write("order.updated.v2: updated")
+	s.Eventually(func() bool { return s.process(l, "order.updated.v2") == nil }, time.Second, time.Millisecond)
+
+	s.write("order.deleted: nonexistent")
+	s.Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(results) == 2
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	s.Assert().ErrorIs(<-done, context.Canceled)
+	mu.Lock()
+	defer mu.Unlock()
+	s.Assert().NoError(results[0])
+	s.Assert().ErrorContains(results[1], `unknown handler "nonexistent"`)
+	s.Assert().Len(results, 2, "a rejected configuration is reported once")
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -16,8 +18,9 @@ type validatable interface {
 }
 
 // invoker wraps a typed handler so we can store handlers of different types
-// in a single map. Returns the result (nil for Procs) and any error.
-type invoker func(ctx context.Context, payload json.RawMessage) (json.RawMessage, error)
+// in a single map. Returns the result (nil for Procs) and any error. Stage
+// durations are recorded into t.
+type invoker func(ctx context.Context, payload json.RawMessage, t *Timings) (json.RawMessage, error)
 
 // Router dispatches messages to registered handlers based on routing keys.
 //
@@ -34,9 +37,42 @@ type Router struct {
 	defaultSources   []Source
 	groups           []group
 	handlers         map[string]invoker
+	handlerTypes     map[string]handlerType
+	enabled          map[string]func(context.Context) bool
+	shadows          map[string]invoker
+	guards           map[string][]guardedHandler
+	canaries         map[string]canary
 	hooks            hooks
-
-	lastMatch atomic.Value // stores sourceRef
+	stats            routerStats
+	pprofLabels      bool
+	hookErrors       HookErrorPolicy
+	sourceFirst      HookKind // bit set of hook kinds whose source hooks run first
+	maxAge           time.Duration
+	maxPayloadSize   int
+	heartbeat        *heartbeatConfig
+	dedup            *dedupCache
+	boundedKeys      bool
+	replyRetry       replyRetry
+	replierFactory   ReplierFactory
+	fingerprint      func(raw []byte) (string, bool)
+	parallel         int
+	deadLetterer     DeadLetterer
+	quarantine       QuarantineStore
+	chaos            *chaos
+	registry         *Registry
+	keys             *Keys
+	schemas          *schemaChecker
+	inits            *sync.Map // source name to *sourceInit
+
+	index atomic.Pointer[matchIndex]
+
+	sourcesMu sync.Mutex // serializes EnableSource and SetSourcePriority
+	settings  atomic.Pointer[sourceSettings]
+
+	workersMu sync.Mutex
+	workers   *workerPool
+	life      lifecycle
+	managed   []managedHandler // handlers with Start or Close methods
 }
 
 // sourceRef identifies a source by its position in the router.
@@ -70,6 +106,12 @@ func New(opts ...Option) *Router {
 	r := &Router{
 		defaultInspector: JSONInspector(),
 		handlers:         make(map[string]invoker),
+		handlerTypes:     make(map[string]handlerType),
+		enabled:          make(map[string]func(context.Context) bool),
+		shadows:          make(map[string]invoker),
+		guards:           make(map[string][]guardedHandler),
+		canaries:         make(map[string]canary),
+		inits:            new(sync.Map),
 	}
 	for _, opt := range opts {
 		opt(r)
@@ -94,6 +136,21 @@ func WithInspector(i Inspector) Option {
 //	r.AddSource(sfnSource)
 func (r *Router) AddSource(s Source) {
 	r.defaultSources = append(r.defaultSources, s)
+	r.index.Store(nil)
+}
+
+// AddSourceWithInspector registers a source to the default group, matched
+// against Views from insp instead of the default inspector. Use it for a
+// single source with an odd format instead of creating a group for it; the
+// source is tried in registration order along with the other default
+// sources.
+//
+// Example:
+//
+//	r.AddSource(eventBridgeSource)
+//	r.AddSourceWithInspector(legacyXMLSource, xmlInspector)
+func (r *Router) AddSourceWithInspector(s Source, insp Inspector) {
+	r.AddSource(&hookedSource{Source: s, insp: insp})
 }
 
 // AddGroup registers sources with a custom inspector. Use this when you have
@@ -106,10 +163,36 @@ func (r *Router) AddSource(s Source) {
 //	r.AddGroup(protoInspector, grpcSource, kafkaSource)
 func (r *Router) AddGroup(inspector Inspector, sources ...Source) {
 	r.groups = append(r.groups, group{inspector: inspector, sources: sources})
+	r.index.Store(nil)
+}
+
+// AddGroupWithHooks registers sources with a custom inspector and attaches
+// hooks shared by every source in the group. Use it to give all sources on
+// one transport common observability, such as Kafka partition tags, without
+// implementing hook interfaces on each source.
+//
+// Group hooks are configured with the same options as global hooks and run
+// as source hooks: after global hooks (see WithHookOrder) and before hooks
+// the source implements itself. Group error hooks follow the same rules as
+// global ones, including ErrHookAbstain and WithHookErrorPolicy. Only hooks
+// with a source variant (see HookKind) run here; others, such as
+// WithOnNoSource, WithOnTimings, or WithOnPause, and non-hook options have no
+// effect.
+//
+// Example:
+//
+//	r.AddGroupWithHooks(kafkaInspector, []dispatch.Option{
+//	    dispatch.WithOnParse(func(ctx context.Context, source, key string) context.Context {
+//	        return logx.WithCtx(ctx, slog.String("transport", "kafka"))
+//	    }),
+//	}, ordersSource, paymentsSource)
+func (r *Router) AddGroupWithHooks(inspector Inspector, hooks []Option, sources ...Source) {
+	r.AddGroup(inspector, withHooks(collectHooks(hooks), sources)...)
 }
 
 // RegisterProc adds a procedure (no result) for a routing key. The key must
-// match the Key field returned by a source's Parse method.
+// match the Key field returned by a source's Parse method. Options such as
+// WithJSONSchema apply to this registration only.
 //
 // This is a package-level function (not a method) due to Go generics limitations:
 // methods cannot have type parameters independent of the receiver.
@@ -118,13 +201,28 @@ func (r *Router) AddGroup(inspector Inspector, sources ...Source) {
 //
 //	dispatch.RegisterProc(r, "user/created", &UserCreatedProc{db: db})
 //	dispatch.RegisterProc(r, "user/deleted", &UserDeletedProc{db: db})
-func RegisterProc[T any](r *Router, key string, p Proc[T]) {
-	r.handlers[key] = func(ctx context.Context, payload json.RawMessage) (json.RawMessage, error) {
-		data, err := unmarshalAndValidate[T](payload)
+func RegisterProc[T any](r *Router, key string, p Proc[T], opts ...HandlerOption) {
+	cfg := newHandlerConfig(opts)
+	r.checkKey(key)
+	r.checkRegistry(key, reflect.TypeFor[T]())
+	r.manage(key, p)
+	r.handlerTypes[key] = handlerType{payload: reflect.TypeFor[T](), handler: reflect.TypeOf(p), schema: cfg.schemaJSON}
+	r.setEnabled(key, cfg.enabled)
+	r.handlers[key] = procInvoker(p, cfg)
+}
+
+// procInvoker returns an invoker that unmarshals and validates the payload
+// and runs p.
+func procInvoker[T any](p Proc[T], cfg handlerConfig) invoker {
+	return func(ctx context.Context, payload json.RawMessage, t *Timings) (json.RawMessage, error) {
+		data, err := unmarshalAndValidate[T](payload, t, cfg.schema)
 		if err != nil {
 			return nil, err
 		}
-		if err := p.Run(ctx, data); err != nil {
+		start := time.Now()
+		err = p.Run(ctx, data)
+		t.Handle = time.Since(start)
+		if err != nil {
 			return nil, err
 		}
 		// Procs return empty JSON object for Replier.Reply
@@ -138,13 +236,27 @@ func RegisterProc[T any](r *Router, key string, p Proc[T]) {
 // Example:
 //
 //	dispatch.RegisterFunc(r, "lookup-user", &LookupUserFunc{client: client})
-func RegisterFunc[T, R any](r *Router, key string, f Func[T, R]) {
-	r.handlers[key] = func(ctx context.Context, payload json.RawMessage) (json.RawMessage, error) {
-		data, err := unmarshalAndValidate[T](payload)
+func RegisterFunc[T, R any](r *Router, key string, f Func[T, R], opts ...HandlerOption) {
+	cfg := newHandlerConfig(opts)
+	r.checkKey(key)
+	r.checkRegistry(key, reflect.TypeFor[T]())
+	r.manage(key, f)
+	r.handlerTypes[key] = handlerType{payload: reflect.TypeFor[T](), result: reflect.TypeFor[R](), handler: reflect.TypeOf(f), schema: cfg.schemaJSON}
+	r.setEnabled(key, cfg.enabled)
+	r.handlers[key] = funcInvoker(f, cfg)
+}
+
+// funcInvoker returns an invoker that unmarshals and validates the payload,
+// calls f, and marshals its result.
+func funcInvoker[T, R any](f Func[T, R], cfg handlerConfig) invoker {
+	return func(ctx context.Context, payload json.RawMessage, t *Timings) (json.RawMessage, error) {
+		data, err := unmarshalAndValidate[T](payload, t, cfg.schema)
 		if err != nil {
 			return nil, err
 		}
+		start := time.Now()
 		result, err := f.Call(ctx, data)
+		t.Handle = time.Since(start)
 		if err != nil {
 			return nil, err
 		}
@@ -156,26 +268,61 @@ func RegisterFunc[T, R any](r *Router, key string, f Func[T, R]) {
 	}
 }
 
-// unmarshalAndValidate unmarshals JSON and validates if the type implements validatable.
-func unmarshalAndValidate[T any](payload json.RawMessage) (T, error) {
+// unmarshalAndValidate checks the payload against schema, if any, then
+// unmarshals it and validates if the type implements validatable.
+func unmarshalAndValidate[T any](payload json.RawMessage, t *Timings, schema *jsonSchema) (T, error) {
 	var data T
-	if err := json.Unmarshal(payload, &data); err != nil {
+	if err := checkSchema(payload, t, schema); err != nil {
+		return data, err
+	}
+
+	start := time.Now()
+	err := json.Unmarshal(payload, &data)
+	t.Unmarshal = time.Since(start)
+	if err != nil {
 		return data, &unmarshalError{err: err}
 	}
 
-	if v, ok := any(data).(validatable); ok {
-		if err := v.Validate(); err != nil {
-			return data, &validationError{err: err}
-		}
-	} else if v, ok := any(&data).(validatable); ok {
-		if err := v.Validate(); err != nil {
-			return data, &validationError{err: err}
-		}
+	start = time.Now()
+	defer func() { t.Validate += time.Since(start) }()
+
+	if err := validate(&data); err != nil {
+		return data, &validationError{err: err}
 	}
 
 	return data, nil
 }
 
+// checkSchema checks the payload against schema, if any.
+func checkSchema(payload json.RawMessage, t *Timings, schema *jsonSchema) error {
+	if schema == nil {
+		return nil
+	}
+	start := time.Now()
+	var v any
+	if err := json.Unmarshal(payload, &v); err != nil {
+		t.Unmarshal = time.Since(start)
+		return &unmarshalError{err: err}
+	}
+	err := schema.check(v)
+	t.Validate = time.Since(start)
+	if err != nil {
+		return &validationError{err: err}
+	}
+	return nil
+}
+
+// validate calls Validate on *data if T or *T implements validatable.
+func validate[T any](data *T) error {
+	if v, ok := any(*data).(validatable); ok {
+		return v.Validate()
+	}
+	if v, ok := any(data).(validatable); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
 // RegisterProcFunc is a convenience function for registering a procedure function.
 //
 // Example:
@@ -183,8 +330,8 @@ func unmarshalAndValidate[T any](payload json.RawMessage) (T, error) {
 //	dispatch.RegisterProcFunc(r, "user/created", func(ctx context.Context, p Payload) error {
 //	    return nil
 //	})
-func RegisterProcFunc[T any](r *Router, key string, fn func(ctx context.Context, payload T) error) {
-	RegisterProc(r, key, ProcFunc[T](fn))
+func RegisterProcFunc[T any](r *Router, key string, fn func(ctx context.Context, payload T) error, opts ...HandlerOption) {
+	RegisterProc(r, key, ProcFunc[T](fn), opts...)
 }
 
 // RegisterFuncFunc is a convenience function for registering a function function.
@@ -194,8 +341,8 @@ func RegisterProcFunc[T any](r *Router, key string, fn func(ctx context.Context,
 //	dispatch.RegisterFuncFunc(r, "lookup-user", func(ctx context.Context, in Input) (*Result, error) {
 //	    return &Result{...}, nil
 //	})
-func RegisterFuncFunc[T, R any](r *Router, key string, fn func(ctx context.Context, payload T) (R, error)) {
-	RegisterFunc(r, key, FuncFunc[T, R](fn))
+func RegisterFuncFunc[T, R any](r *Router, key string, fn func(ctx context.Context, payload T) (R, error), opts ...HandlerOption) {
+	RegisterFunc(r, key, FuncFunc[T, R](fn), opts...)
 }
 
 // Process parses the raw message, routes to the appropriate handler, and
@@ -212,6 +359,17 @@ func RegisterFuncFunc[T, R any](r *Router, key string, fn func(ctx context.Conte
 //
 // Hooks are called at appropriate points throughout this flow.
 //
+// Options apply to this call only: WithAttributes and WithReplier override
+// message fields, WithSource skips matching, and WithProcessHooks adds
+// hooks.
+//
+// A message whose source sets Message.Keys is handled once per routing key,
+// in order, as if it had been processed separately for each, so every hook
+// runs per key. Every key is handled even if an earlier one fails, and the
+// errors are joined. With a Replier, the results are sent as one reply: a
+// JSON object mapping each key that replied to its result, or, if any
+// handler failed, a Fail with the joined errors.
+//
 // Example:
 //
 //	// In an SQS consumer
@@ -223,48 +381,233 @@ func RegisterFuncFunc[T, R any](r *Router, key string, fn func(ctx context.Conte
 //	func handler(ctx context.Context, event json.RawMessage) error {
 //	    return router.Process(ctx, event)
 //	}
-func (r *Router) Process(ctx context.Context, raw []byte) error {
-	// Find matching source using discriminators
-	source := r.match(raw)
-	if source == nil {
-		return r.handleNoSource(ctx, raw)
+func (r *Router) Process(ctx context.Context, raw []byte, opts ...ProcessOption) error {
+	if !r.begin() {
+		return ErrShutdown
+	}
+	defer r.end()
+	return r.process(ctx, raw, opts...)
+}
+
+// process is Process without shutdown tracking.
+func (r *Router) process(ctx context.Context, raw []byte, opts ...ProcessOption) error {
+	p, err := r.parse(ctx, raw, newProcessConfig(opts))
+	if p == nil {
+		return err
+	}
+	return r.dispatch(ctx, p)
+}
+
+// parsed is a message that has been matched to a source and parsed, but not
+// yet dispatched to its handler.
+type parsed struct {
+	raw        []byte
+	view       View      // raw's view from insp, nil if insp rejected it
+	insp       Inspector // the inspector the source was matched with
+	source     Source
+	sourceName string
+	msg        Message
+	timings    Timings
+}
+
+// parse matches raw to a source and parses it, applying the per-call
+// options in cfg. If processing ends before dispatch (no source, parse
+// error), parse returns a nil *parsed and the result of processing.
+func (r *Router) parse(ctx context.Context, raw []byte, cfg processConfig) (*parsed, error) {
+	p := &parsed{raw: raw}
+	ctx = r.withHandlerTypes(r.withRaw(ctx, raw))
+
+	// Reject oversize messages before inspecting them
+	if r.oversize(len(raw)) {
+		return nil, dispatchError(StageMatch, "", "", r.handleOversize(ctx, StageMatch, "", "", len(raw), nil))
+	}
+
+	// Find matching source using discriminators, unless the caller named it
+	start := time.Now()
+	var cs *compiledSource
+	var view View
+	if cfg.source != "" {
+		var err error
+		if cs, view, err = r.matchNamed(raw, cfg.source); err != nil {
+			return nil, dispatchError(StageMatch, "", "", err)
+		}
+	} else {
+		cs, view = r.matchSource(raw)
+	}
+	p.timings.Match = time.Since(start)
+	if cs == nil {
+		return nil, dispatchError(StageMatch, "", "", r.handleNoSource(ctx, raw))
+	}
+
+	source := cs.source
+	p.source = source
+	p.view, p.insp = view, r.inspectorFor(cs)
+	p.sourceName = source.Name()
+	r.stats.sources.get(p.sourceName).matched.Add(1)
+
+	// Initialize the source on its first message
+	if err := r.initSource(ctx, source); err != nil {
+		r.outcome(ctx, p.sourceName, "", err)
+		return nil, dispatchError(StageParse, p.sourceName, "", err)
 	}
 
 	// Parse with matched source
-	msg, err := source.Parse(raw)
+	source = cfg.wrap(source)
+	p.source = source
+	start = time.Now()
+	var msg Message
+	err := r.chaos.inject(ctx, StageParse)
+	if err == nil && view == nil {
+		msg, err = source.Parse(raw)
+	} else if err == nil {
+		msg, err = parseSource(source, view, raw)
+	}
+	if err == nil {
+		msg.Payload, err = applyDefaults(msg)
+	}
+	p.timings.Parse = time.Since(start)
 	if err != nil {
-		return r.handleParseError(ctx, source, err)
+		err = r.handleParseError(ctx, source, err)
+		r.outcome(ctx, p.sourceName, "", err)
+		return nil, dispatchError(StageParse, p.sourceName, "", err)
 	}
 
-	sourceName := source.Name()
+	cfg.apply(&msg)
+	if msg.CorrelationID == "" {
+		msg.CorrelationID = msg.MessageID
+	}
+	if len(msg.Keys) > 0 {
+		msg.Keys = msg.routingKeys()
+		msg.Key = msg.Keys[0]
+		for _, key := range msg.Keys[1:] {
+			r.stats.keys.get(key).matched.Add(1)
+		}
+	}
+	r.stats.keys.get(msg.Key).matched.Add(1)
+
+	if msg.Replier == nil && msg.ReplyTo != "" && r.replierFactory != nil {
+		replier, err := r.replierFactory.NewReplier(ctx, msg)
+		if err != nil {
+			err = fmt.Errorf("replier for %s: %w", msg.ReplyTo, err)
+			r.outcome(withMessage(ctx, msg), p.sourceName, msg.Key, err)
+			return nil, dispatchError(StageReply, p.sourceName, msg.Key, err)
+		}
+		msg.Replier = replier
+	}
+	p.msg = msg
+
+	return p, nil
+}
+
+// dispatch runs the handlers for a parsed message and sends its reply.
+func (r *Router) dispatch(ctx context.Context, p *parsed) error {
+	ctx = r.withHandlerTypes(ctx)
+
+	// Skip messages already handled within the suppression window
+	id := p.msg.MessageID
+	if r.dedup.seen(id) {
+		ctx = withMessage(r.withRaw(ctx, p.raw), p.msg)
+		r.callOnDuplicate(ctx, p.sourceName, p.msg.Key)
+		r.stats.outcome(p.sourceName, p.msg.Key, nil)
+		return nil
+	}
+
+	var err error
+	if len(p.msg.Keys) > 1 {
+		err = r.dispatchKeys(ctx, p)
+	} else {
+		err = r.dispatchKey(ctx, p)
+	}
+	if err == nil {
+		r.dedup.add(id)
+	}
+	return err
+}
+
+// dispatchKey runs the handler for a parsed message's Key and sends its
+// reply.
+func (r *Router) dispatchKey(ctx context.Context, p *parsed) error {
+	source, sourceName, msg := p.source, p.sourceName, p.msg
+	timings := &p.timings
+	ctx = withMessage(r.withRaw(ctx, p.raw), msg)
+
+	// Drop stale messages before any side effects
+	if age, expired := r.expired(msg); expired {
+		r.callOnExpired(ctx, sourceName, msg.Key, age)
+		r.stats.outcome(sourceName, msg.Key, nil)
+		return nil
+	}
 
 	// OnParse: global, then source
 	ctx = r.callOnParse(ctx, source, sourceName, msg.Key)
 
+	// OnTimings: reported once the message has been fully handled
+	if len(r.hooks.onTimings) > 0 {
+		defer func() { r.callOnTimings(ctx, sourceName, msg.Key, *timings) }()
+	}
+
+	// Reject oversize payloads before unmarshaling them
+	if r.oversize(len(msg.Payload)) {
+		err := r.handleOversize(ctx, StageUnmarshal, sourceName, msg.Key, len(msg.Payload), msg.Replier)
+		r.outcome(ctx, sourceName, msg.Key, err)
+		return dispatchError(StageUnmarshal, sourceName, msg.Key, err)
+	}
+
 	// Look up handler
-	handler, found := r.handlers[msg.Key]
+	handler, guarded, found := r.handlerFor(ctx, p)
 	if !found {
-		return r.handleNoHandler(ctx, source, sourceName, msg.Key, msg.Replier)
+		err := r.handleNoHandler(ctx, source, sourceName, msg.Key, msg.Replier)
+		r.outcome(ctx, sourceName, msg.Key, err)
+		return dispatchError(StageRoute, sourceName, msg.Key, err)
 	}
 
+	// Skip handlers turned off by WithEnabled; guarded handlers were checked
+	// when they were chosen
+	if !guarded && r.disabled(ctx, msg.Key) {
+		r.callOnDisabled(ctx, sourceName, msg.Key)
+		r.stats.outcome(sourceName, msg.Key, nil)
+		return nil
+	}
+
+	// Send a share of the key's messages to its canary, if any
+	ctx, handler = r.route(r.withHandlerInfo(ctx, msg.Key), msg, handler)
+
 	// OnDispatch: global, then source
 	r.callOnDispatch(ctx, source, sourceName, msg.Key)
 
-	// Execute handler
+	// Execute handler, after checking the payload's registered schema
 	start := time.Now()
-	result, err := handler(ctx, msg.Payload)
+	var result json.RawMessage
+	err := r.schemas.check(ctx, msg, timings)
+	if err == nil {
+		hctx, cancel := withDeadline(ctx, msg)
+		stop := r.startHeartbeat(ctx, sourceName, msg)
+		result, err = r.invoke(hctx, handler, sourceName, msg, timings)
+		stop()
+		cancel()
+	}
 	duration := time.Since(start)
+	if err == nil {
+		collectResult(ctx, msg.Key, result)
+	}
 
 	// Handle unmarshal and validation errors specially
 	var uerr *unmarshalError
 	if errors.As(err, &uerr) {
-		return r.handleUnmarshalError(ctx, source, sourceName, msg.Key, uerr.err, msg.Replier)
+		err := r.handleUnmarshalError(ctx, source, sourceName, msg.Key, uerr.err, msg.Replier)
+		r.outcome(ctx, sourceName, msg.Key, err)
+		return dispatchError(StageUnmarshal, sourceName, msg.Key, err)
 	}
 	var verr *validationError
 	if errors.As(err, &verr) {
-		return r.handleValidationError(ctx, source, sourceName, msg.Key, verr.err, msg.Replier)
+		err := r.handleValidationError(ctx, source, sourceName, msg.Key, verr.err, msg.Replier)
+		r.outcome(ctx, sourceName, msg.Key, err)
+		return dispatchError(StageValidate, sourceName, msg.Key, err)
 	}
 
+	r.stats.handled(sourceName, msg.Key, err, duration)
+	r.shadow(ctx, sourceName, msg, ShadowResult{Result: result, Err: err, Duration: duration})
+
 	// OnSuccess/OnFailure: global, then source
 	if err != nil {
 		r.callOnFailure(ctx, source, sourceName, msg.Key, err, duration)
@@ -274,223 +617,488 @@ func (r *Router) Process(ctx context.Context, raw []byte) error {
 
 	// Send response via Replier if present
 	if msg.Replier != nil {
+		// The error policy sees handler errors before they are sent back;
+		// without one, delivering the failure completes the message
+		var herr error
+		if err != nil && len(r.hooks.onError) > 0 {
+			herr = r.handleError(ctx, StageHandle, sourceName, msg.Key, err)
+		}
+		start = time.Now()
+		defer func() { timings.Reply = time.Since(start) }()
+		if err == nil {
+			result, err = r.callOnReply(ctx, source, sourceName, msg.Key, result)
+		}
 		if err != nil {
-			return msg.Replier.Fail(ctx, err)
+			err = r.fail(ctx, msg.Replier, err)
+		} else {
+			err = r.reply(ctx, msg.Replier, result)
 		}
-		return msg.Replier.Reply(ctx, result)
+		if err = r.handleError(ctx, StageReply, sourceName, msg.Key, err); err != nil {
+			return dispatchError(StageReply, sourceName, msg.Key, err)
+		}
+		return dispatchError(StageHandle, sourceName, msg.Key, herr)
 	}
 
-	return err
+	err = r.handleError(ctx, StageHandle, sourceName, msg.Key, err)
+	return dispatchError(StageHandle, sourceName, msg.Key, err)
 }
 
 // viewCache caches parsed views per inspector to avoid re-parsing the same
 // raw bytes multiple times during source matching.
+//
+// Routers rarely have more than a few inspectors, so entries are kept in a
+// small inline array and searched linearly, spilling to a slice beyond that.
+// Caches are pooled; use getViewCache and putViewCache.
 type viewCache struct {
-	raw   []byte
-	views map[Inspector]viewResult
+	raw    []byte
+	inline [4]viewEntry
+	n      int
+	more   []viewEntry
 }
 
-type viewResult struct {
+type viewEntry struct {
+	insp Inspector
 	view View
 	ok   bool
 }
 
-func newViewCache(raw []byte) *viewCache {
-	return &viewCache{
-		raw:   raw,
-		views: make(map[Inspector]viewResult),
-	}
+var viewCachePool = sync.Pool{
+	New: func() any { return new(viewCache) },
+}
+
+// getViewCache returns an empty cache for raw from the pool.
+func getViewCache(raw []byte) *viewCache {
+	c := viewCachePool.Get().(*viewCache)
+	c.raw = raw
+	return c
+}
+
+// putViewCache clears c and returns it to the pool. c must not be used
+// afterwards, but views obtained from it remain valid.
+func putViewCache(c *viewCache) {
+	clear(c.inline[:c.n])
+	clear(c.more)
+	*c = viewCache{more: c.more[:0]}
+	viewCachePool.Put(c)
 }
 
 // get returns a cached view or parses and caches it.
 func (c *viewCache) get(insp Inspector) (View, bool) {
-	if result, ok := c.views[insp]; ok {
-		return result.view, result.ok
+	for i := range c.inline[:c.n] {
+		if e := &c.inline[i]; e.insp == insp {
+			return e.view, e.ok
+		}
+	}
+	for i := range c.more {
+		if e := &c.more[i]; e.insp == insp {
+			return e.view, e.ok
+		}
 	}
 
-	view, err := insp.Inspect(c.raw)
-	if err != nil {
-		c.views[insp] = viewResult{ok: false}
-		return nil, false
+	e := viewEntry{insp: insp}
+	if view, err := insp.Inspect(c.raw); err == nil {
+		e.view, e.ok = view, true
 	}
+	if c.n < len(c.inline) {
+		c.inline[c.n] = e
+		c.n++
+	} else {
+		c.more = append(c.more, e)
+	}
+	return e.view, e.ok
+}
 
-	c.views[insp] = viewResult{view: view, ok: true}
-	return view, true
+// parseSource parses raw with source, using view if the source is a
+// ViewParser.
+func parseSource(source Source, view View, raw []byte) (Message, error) {
+	if vp, ok := source.(ViewParser); ok {
+		return vp.ParseView(view, raw)
+	}
+	return source.Parse(raw)
 }
 
-// match finds a source whose discriminator matches the raw message.
-func (r *Router) match(raw []byte) Source {
-	cache := newViewCache(raw)
+// match finds a source whose discriminator matches the raw message and
+// returns it with the view it matched against.
+func (r *Router) match(raw []byte) (Source, View) {
+	cs, view := r.matchSource(raw)
+	if cs == nil {
+		return nil, nil
+	}
+	return cs.source, view
+}
+
+// matchSource is like match, but returns the compiled source.
+func (r *Router) matchSource(raw []byte) (*compiledSource, View) {
+	cache := getViewCache(raw)
+	defer putViewCache(cache)
+
+	idx := r.matchIndex()
 
-	if v := r.lastMatch.Load(); v != nil {
-		if ref, ok := v.(sourceRef); ok {
-			if src := r.trySource(cache, ref); src != nil {
-				return src
+	var fp string
+	var hasFP bool
+	if r.fingerprint != nil {
+		if fp, hasFP = r.fingerprint(raw); hasFP {
+			if cs, ok := idx.affinityFor(fp); ok {
+				if view, ok := cache.get(r.inspectorFor(cs)); ok {
+					return cs, view
+				}
 			}
 		}
 	}
 
-	return r.matchAll(cache)
+	cs, view := r.findSource(cache, idx)
+	if cs == nil {
+		return nil, nil
+	}
+	if cs.fallback {
+		// Remembering fallbacks would let them preempt specific sources.
+		return cs, view
+	}
+	idx.record(cs)
+	if hasFP {
+		idx.remember(fp, cs)
+	}
+	return cs, view
 }
 
-// trySource attempts to match the source at the given position.
-func (r *Router) trySource(cache *viewCache, ref sourceRef) Source {
-	if ref.groupIdx == -1 {
-		if ref.sourceIdx >= len(r.defaultSources) {
-			return nil
-		}
-		view, ok := cache.get(r.defaultInspector)
-		if !ok {
-			return nil
-		}
-		src := r.defaultSources[ref.sourceIdx]
-		if src.Discriminator().Match(view) {
-			return src
+// findSource tries the hot sources, then falls back to a full indexed scan
+// and finally to the fallback sources.
+func (r *Router) findSource(cache *viewCache, idx *matchIndex) (*compiledSource, View) {
+	if hot := idx.hot.Load(); hot != nil {
+		for _, cs := range *hot {
+			if view, ok := r.trySource(cache, cs); ok {
+				return cs, view
+			}
 		}
-		return nil
 	}
+	if cs, view := r.matchAll(cache); cs != nil {
+		return cs, view
+	}
+	return r.matchFallback(cache, idx)
+}
 
-	if ref.groupIdx >= len(r.groups) {
-		return nil
+// inspectorFor returns the inspector cs is matched with: its own, or that of
+// the group that owns it.
+func (r *Router) inspectorFor(cs *compiledSource) Inspector {
+	if cs.insp != nil {
+		return cs.insp
 	}
-	g := r.groups[ref.groupIdx]
-	if ref.sourceIdx >= len(g.sources) {
-		return nil
+	if cs.ref.groupIdx >= 0 {
+		return r.groups[cs.ref.groupIdx].inspector
 	}
-	view, ok := cache.get(g.inspector)
-	if !ok {
-		return nil
+	return r.defaultInspector
+}
+
+// trySource reports whether the compiled source matches the message,
+// returning the view it matched against.
+func (r *Router) trySource(cache *viewCache, cs *compiledSource) (View, bool) {
+	view, ok := cache.get(r.inspectorFor(cs))
+	if !ok || cs.never || !cs.disc.Match(view) {
+		return nil, false
 	}
-	src := g.sources[ref.sourceIdx]
-	if src.Discriminator().Match(view) {
-		return src
+	return view, true
+}
+
+// matchIndex returns the compiled match index, building it on first use.
+func (r *Router) matchIndex() *matchIndex {
+	idx := r.index.Load()
+	if idx == nil {
+		idx = r.compileIndex()
+		// Don't replace an index built by a concurrent EnableSource or
+		// SetSourcePriority, which may reflect newer settings.
+		if !r.index.CompareAndSwap(nil, idx) {
+			idx = r.index.Load()
+		}
 	}
-	return nil
+	return idx
 }
 
 // matchAll searches all groups for a matching source.
-func (r *Router) matchAll(cache *viewCache) Source {
-	if len(r.defaultSources) > 0 {
-		if view, ok := cache.get(r.defaultInspector); ok {
-			for i, src := range r.defaultSources {
-				if src.Discriminator().Match(view) {
-					r.lastMatch.Store(sourceRef{groupIdx: -1, sourceIdx: i})
-					return src
-				}
-			}
+//
+// Discriminators are precompiled into a matchIndex on first use, so each
+// distinct path is looked up at most once per message no matter how many
+// sources reference it.
+func (r *Router) matchAll(cache *viewCache) (*compiledSource, View) {
+	idx := r.matchIndex()
+
+	if r.parallel > 1 {
+		if cs, view, ok := r.matchParallel(cache, idx); ok {
+			return cs, view
 		}
 	}
 
-	for gi, g := range r.groups {
-		view, ok := cache.get(g.inspector)
-		if !ok {
-			continue
-		}
-		for si, src := range g.sources {
-			if src.Discriminator().Match(view) {
-				r.lastMatch.Store(sourceRef{groupIdx: gi, sourceIdx: si})
-				return src
-			}
+	if cs, view := matchGroup(cache, &idx.defaults, r.defaultInspector); cs != nil {
+		return cs, view
+	}
+	for gi := range idx.groups {
+		if cs, view := matchGroup(cache, &idx.groups[gi], r.groups[gi].inspector); cs != nil {
+			return cs, view
 		}
 	}
+	return nil, nil
+}
 
-	return nil
+// matchGroup returns the first source in g that matches, with the View it
+// matched against. Sources with their own inspector are still tried when
+// the group's inspector rejects the message.
+func matchGroup(cache *viewCache, g *groupIndex, insp Inspector) (*compiledSource, View) {
+	if len(g.sources) == 0 {
+		return nil, nil
+	}
+	view, ok := cache.get(insp)
+	if !ok {
+		if !g.ownView {
+			return nil, nil
+		}
+		view = nil
+	}
+	if i := g.match(view, cache.get); i >= 0 {
+		cs := &g.sources[i]
+		return cs, cs.viewOf(view, cache.get)
+	}
+	return nil, nil
 }
 
 // callOnParse calls global and source OnParse hooks.
 func (r *Router) callOnParse(ctx context.Context, source Source, sourceName, key string) context.Context {
+	h, ok := source.(OnParseHook)
+	sourceFirst := ok && r.sourceHooksFirst(HookParse)
+	if sourceFirst {
+		ctx = h.OnParse(ctx, key)
+	}
 	for _, fn := range r.hooks.onParse {
 		ctx = fn(ctx, sourceName, key)
 	}
-	if h, ok := source.(OnParseHook); ok {
+	if ok && !sourceFirst {
 		ctx = h.OnParse(ctx, key)
 	}
 	return ctx
 }
 
+// callOnTimings calls global OnTimings hooks.
+func (r *Router) callOnTimings(ctx context.Context, sourceName, key string, t Timings) {
+	for _, fn := range r.hooks.onTimings {
+		fn(ctx, sourceName, key, t)
+	}
+}
+
 // callOnDispatch calls global and source OnDispatch hooks.
 func (r *Router) callOnDispatch(ctx context.Context, source Source, sourceName, key string) {
+	h, ok := source.(OnDispatchHook)
+	sourceFirst := ok && r.sourceHooksFirst(HookDispatch)
+	if sourceFirst {
+		h.OnDispatch(ctx, key)
+	}
 	for _, fn := range r.hooks.onDispatch {
 		fn(ctx, sourceName, key)
 	}
-	if h, ok := source.(OnDispatchHook); ok {
+	if ok && !sourceFirst {
 		h.OnDispatch(ctx, key)
 	}
 }
 
 // callOnSuccess calls global and source OnSuccess hooks.
 func (r *Router) callOnSuccess(ctx context.Context, source Source, sourceName, key string, duration time.Duration) {
+	h, ok := source.(OnSuccessHook)
+	sourceFirst := ok && r.sourceHooksFirst(HookSuccess)
+	if sourceFirst {
+		h.OnSuccess(ctx, key, duration)
+	}
 	for _, fn := range r.hooks.onSuccess {
 		fn(ctx, sourceName, key, duration)
 	}
-	if h, ok := source.(OnSuccessHook); ok {
+	if ok && !sourceFirst {
 		h.OnSuccess(ctx, key, duration)
 	}
 }
 
 // callOnFailure calls global and source OnFailure hooks.
 func (r *Router) callOnFailure(ctx context.Context, source Source, sourceName, key string, err error, duration time.Duration) {
+	h, ok := source.(OnFailureHook)
+	sourceFirst := ok && r.sourceHooksFirst(HookFailure)
+	if sourceFirst {
+		h.OnFailure(ctx, key, err, duration)
+	}
 	for _, fn := range r.hooks.onFailure {
 		fn(ctx, sourceName, key, err, duration)
 	}
-	if h, ok := source.(OnFailureHook); ok {
+	if ok && !sourceFirst {
 		h.OnFailure(ctx, key, err, duration)
 	}
 }
 
+// outcome records a message that ended without running a handler and
+// notifies reject observers when a policy hook failed it.
+func (r *Router) outcome(ctx context.Context, sourceName, key string, err error) {
+	r.stats.outcome(sourceName, key, err)
+	if err == nil {
+		return
+	}
+	for _, fn := range r.hooks.onReject {
+		fn(ctx, sourceName, key, err)
+	}
+}
+
+// callOnReply runs the reply transformation hooks, global then source,
+// stopping at the first error.
+func (r *Router) callOnReply(ctx context.Context, source Source, sourceName, key string, result json.RawMessage) (json.RawMessage, error) {
+	h, ok := source.(OnReplyHook)
+	sourceFirst := ok && r.sourceHooksFirst(HookReply)
+	var err error
+	if sourceFirst {
+		if result, err = h.OnReply(ctx, key, result); err != nil {
+			return nil, err
+		}
+	}
+	for _, fn := range r.hooks.onReply {
+		if result, err = fn(ctx, sourceName, key, result); err != nil {
+			return nil, err
+		}
+	}
+	if ok && !sourceFirst {
+		return h.OnReply(ctx, key, result)
+	}
+	return result, nil
+}
+
+// callOnSkip calls skip observers when a policy hook skipped a message that
+// would otherwise have failed, then dead-letters it. cause describes why the
+// message was skipped. A non-nil result means dead-lettering failed and the
+// message must fail instead.
+func (r *Router) callOnSkip(ctx context.Context, stage Stage, sourceName, key string, cause error) error {
+	r.notifySkip(ctx, sourceName, key, cause)
+	return r.deadLetter(ctx, stage, sourceName, key, cause)
+}
+
+// notifySkip calls skip observers.
+func (r *Router) notifySkip(ctx context.Context, sourceName, key string, cause error) {
+	for _, fn := range r.hooks.onSkip {
+		fn(ctx, sourceName, key, cause)
+	}
+}
+
+// combineHookErrors reduces the errors returned by error hooks according to
+// the router's HookErrorPolicy. errs must not be empty.
+func (r *Router) combineHookErrors(errs []error) error {
+	if r.hookErrors == JoinHookErrors && len(errs) > 1 {
+		return errors.Join(errs...)
+	}
+	return errs[0]
+}
+
+// runPolicyHooks calls each policy hook in order with call, skipping hooks
+// that return ErrHookAbstain. It returns how many hooks ran and the errors
+// they returned.
+func runPolicyHooks[F any](fns []F, call func(F) error) (int, []error) {
+	ran := 0
+	var errs []error
+	for _, fn := range fns {
+		err := call(fn)
+		if errors.Is(err, ErrHookAbstain) {
+			continue
+		}
+		ran++
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return ran, errs
+}
+
+// policyErrors returns err as a slice of hook errors, or nil if err is nil or
+// ErrHookAbstain.
+func policyErrors(err error) []error {
+	if err == nil || errors.Is(err, ErrHookAbstain) {
+		return nil
+	}
+	return []error{err}
+}
+
 // handleNoSource handles the case when no source matches.
 func (r *Router) handleNoSource(ctx context.Context, raw []byte) error {
+	ran := 0
 	for _, fn := range r.hooks.onNoSource {
-		if err := fn(ctx, raw); err != nil {
+		err := fn(ctx, raw)
+		if errors.Is(err, ErrHookAbstain) {
+			continue
+		}
+		ran++
+		if err != nil {
 			return err
 		}
 	}
-	if len(r.hooks.onNoSource) > 0 {
-		return nil
+	if ran > 0 {
+		return r.callOnSkip(ctx, StageMatch, "", "", ErrNoSource)
 	}
-	return fmt.Errorf("no source matched message")
+	return r.quarantineMessage(ctx, StageMatch, "", "", ErrNoSource)
 }
 
 // handleParseError handles the case when a source's Parse method returns an error.
 func (r *Router) handleParseError(ctx context.Context, source Source, parseErr error) error {
 	sourceName := source.Name()
-	for _, fn := range r.hooks.onParseError {
-		if err := fn(ctx, sourceName, parseErr); err != nil {
-			return err
-		}
+	var ran int
+	var errs []error
+
+	sourceFirst := r.sourceHooksFirst(HookParseError)
+	if sourceFirst {
+		ran, errs = sourceParseErrorHooks(ctx, source, parseErr)
 	}
-	if len(r.hooks.onParseError) > 0 {
-		return nil
+
+	n, global := runPolicyHooks(r.hooks.onParseError, func(fn OnParseErrorFunc) error {
+		return fn(ctx, sourceName, parseErr)
+	})
+	ran += n
+	errs = append(errs, global...)
+
+	if !sourceFirst {
+		n, more := sourceParseErrorHooks(ctx, source, parseErr)
+		ran += n
+		errs = append(errs, more...)
+	}
+
+	switch {
+	case len(errs) > 0:
+		return r.combineHookErrors(errs)
+	case ran == 0:
+		return fmt.Errorf("parse failed for source %s: %w", sourceName, parseErr)
 	}
-	return fmt.Errorf("parse failed for source %s: %w", sourceName, parseErr)
+	return r.callOnSkip(ctx, StageParse, sourceName, "", parseErr)
 }
 
 // handleNoHandler handles the case when no handler is registered.
 func (r *Router) handleNoHandler(ctx context.Context, source Source, sourceName, key string, replier Replier) error {
+	var ran int
 	var errs []error
 
-	for _, fn := range r.hooks.onNoHandler {
-		if err := fn(ctx, sourceName, key); err != nil {
-			errs = append(errs, err)
-		}
+	sourceFirst := r.sourceHooksFirst(HookNoHandler)
+	if sourceFirst {
+		ran, errs = sourceNoHandlerHooks(ctx, source, key)
 	}
 
-	if h, ok := source.(OnNoHandlerHook); ok {
-		if err := h.OnNoHandler(ctx, key); err != nil {
-			errs = append(errs, err)
-		}
+	n, global := runPolicyHooks(r.hooks.onNoHandler, func(fn OnNoHandlerFunc) error {
+		return fn(ctx, sourceName, key)
+	})
+	ran += n
+	errs = append(errs, global...)
+
+	if !sourceFirst {
+		n, more := sourceNoHandlerHooks(ctx, source, key)
+		ran += n
+		errs = append(errs, more...)
 	}
 
 	var resultErr error
 	switch {
 	case len(errs) > 0:
-		resultErr = errs[0]
-	case len(r.hooks.onNoHandler) == 0:
-		resultErr = fmt.Errorf("no handler for key: %s", key)
+		resultErr = r.combineHookErrors(errs)
+	case ran == 0:
+		resultErr = r.quarantineMessage(ctx, StageRoute, sourceName, key, fmt.Errorf("%w: %s", ErrNoHandler, key))
+	default:
+		resultErr = r.callOnSkip(ctx, StageRoute, sourceName, key, fmt.Errorf("%w: %s", ErrNoHandler, key))
 	}
 
 	if resultErr != nil && replier != nil {
-		return replier.Fail(ctx, resultErr)
+		return r.fail(ctx, replier, resultErr)
 	}
 
 	return resultErr
@@ -498,30 +1106,38 @@ func (r *Router) handleNoHandler(ctx context.Context, source Source, sourceName,
 
 // handleUnmarshalError handles JSON unmarshal errors.
 func (r *Router) handleUnmarshalError(ctx context.Context, source Source, sourceName, key string, err error, replier Replier) error {
+	var ran int
 	var errs []error
 
-	for _, fn := range r.hooks.onUnmarshalError {
-		if herr := fn(ctx, sourceName, key, err); herr != nil {
-			errs = append(errs, herr)
-		}
+	sourceFirst := r.sourceHooksFirst(HookUnmarshalError)
+	if sourceFirst {
+		ran, errs = sourceUnmarshalErrorHooks(ctx, source, key, err)
 	}
 
-	if h, ok := source.(OnUnmarshalErrorHook); ok {
-		if herr := h.OnUnmarshalError(ctx, key, err); herr != nil {
-			errs = append(errs, herr)
-		}
+	n, global := runPolicyHooks(r.hooks.onUnmarshalError, func(fn OnUnmarshalErrorFunc) error {
+		return fn(ctx, sourceName, key, err)
+	})
+	ran += n
+	errs = append(errs, global...)
+
+	if !sourceFirst {
+		n, more := sourceUnmarshalErrorHooks(ctx, source, key, err)
+		ran += n
+		errs = append(errs, more...)
 	}
 
 	var resultErr error
 	switch {
 	case len(errs) > 0:
-		resultErr = errs[0]
-	case len(r.hooks.onUnmarshalError) == 0:
-		resultErr = fmt.Errorf("unmarshal payload: %w", err)
+		resultErr = r.combineHookErrors(errs)
+	case ran == 0:
+		resultErr = fmt.Errorf("%w: %w", ErrUnmarshal, err)
+	default:
+		resultErr = r.callOnSkip(ctx, StageUnmarshal, sourceName, key, err)
 	}
 
 	if resultErr != nil && replier != nil {
-		return replier.Fail(ctx, resultErr)
+		return r.fail(ctx, replier, resultErr)
 	}
 
 	return resultErr
@@ -529,30 +1145,38 @@ func (r *Router) handleUnmarshalError(ctx context.Context, source Source, source
 
 // handleValidationError handles payload validation errors.
 func (r *Router) handleValidationError(ctx context.Context, source Source, sourceName, key string, err error, replier Replier) error {
+	var ran int
 	var errs []error
 
-	for _, fn := range r.hooks.onValidationError {
-		if herr := fn(ctx, sourceName, key, err); herr != nil {
-			errs = append(errs, herr)
-		}
+	sourceFirst := r.sourceHooksFirst(HookValidationError)
+	if sourceFirst {
+		ran, errs = sourceValidationErrorHooks(ctx, source, key, err)
 	}
 
-	if h, ok := source.(OnValidationErrorHook); ok {
-		if herr := h.OnValidationError(ctx, key, err); herr != nil {
-			errs = append(errs, herr)
-		}
+	n, global := runPolicyHooks(r.hooks.onValidationError, func(fn OnValidationErrorFunc) error {
+		return fn(ctx, sourceName, key, err)
+	})
+	ran += n
+	errs = append(errs, global...)
+
+	if !sourceFirst {
+		n, more := sourceValidationErrorHooks(ctx, source, key, err)
+		ran += n
+		errs = append(errs, more...)
 	}
 
 	var resultErr error
 	switch {
 	case len(errs) > 0:
-		resultErr = errs[0]
-	case len(r.hooks.onValidationError) == 0:
-		resultErr = fmt.Errorf("validate payload: %w", err)
+		resultErr = r.combineHookErrors(errs)
+	case ran == 0:
+		resultErr = fmt.Errorf("%w: %w", ErrValidation, err)
+	default:
+		resultErr = r.callOnSkip(ctx, StageValidate, sourceName, key, err)
 	}
 
 	if resultErr != nil && replier != nil {
-		return replier.Fail(ctx, resultErr)
+		return r.fail(ctx, replier, resultErr)
 	}
 
 	return resultErr
@@ -22,7 +22,7 @@ type testHandler struct {
 	err     error
 }
 
-func (h *testHandler) Handle(ctx context.Context, p testPayload) error {
+func (h *testHandler) Run(ctx context.Context, p testPayload) error {
 	h.called = true
 	h.payload = p
 	return h.err
@@ -38,20 +38,26 @@ func (s *testSource) Discriminator() Discriminator {
 	return HasFields("type", "payload")
 }
 
-func (s *testSource) Parse(raw []byte) (Parsed, error) {
+func (s *testSource) Parse(raw []byte) (Message, error) {
 	var env struct {
 		Type    string          `json:"type"`
 		Payload json.RawMessage `json:"payload"`
 	}
 	if err := json.Unmarshal(raw, &env); err != nil {
-		return Parsed{}, err
+		return Message{}, err
 	}
 	if env.Type == "" {
-		return Parsed{}, errors.New("missing type field")
+		return Message{}, errors.New("missing type field")
 	}
-	return Parsed{Key: env.Type, Payload: env.Payload}, nil
+	return Message{Key: env.Type, Payload: env.Payload}, nil
 }
 
+// completeReplier adapts a completion callback to the Replier interface.
+type completeReplier func(ctx context.Context, err error) error
+
+func (f completeReplier) Reply(ctx context.Context, _ json.RawMessage) error { return f(ctx, nil) }
+func (f completeReplier) Fail(ctx context.Context, err error) error          { return f(ctx, err) }
+
 // mockInspector is a test inspector that can be configured to fail.
 type mockInspector struct {
 	err error
@@ -109,7 +115,7 @@ func TestRouterSuite(t *testing.T) {
 }
 
 func (s *RouterSuite) TestProcess_DispatchesToRegisteredHandler() {
-	Register(s.router, "test/event", s.handler)
+	RegisterProc(s.router, "test/event", s.handler)
 
 	msg := []byte(`{"type": "test/event", "payload": {"value": "hello"}}`)
 	err := s.router.Process(context.Background(), msg)
@@ -122,7 +128,7 @@ func (s *RouterSuite) TestProcess_DispatchesToRegisteredHandler() {
 func (s *RouterSuite) TestProcess_ReturnsHandlerError() {
 	wantErr := errors.New("handler error")
 	s.handler.err = wantErr
-	Register(s.router, "test/event", s.handler)
+	RegisterProc(s.router, "test/event", s.handler)
 
 	msg := []byte(`{"type": "test/event", "payload": {"value": "hello"}}`)
 	err := s.router.Process(context.Background(), msg)
@@ -148,15 +154,15 @@ func (s *RouterSuite) TestProcess_TriesSourcesInOrder() {
 	r := New()
 
 	// First source doesn't match JSON format
-	r.AddSource(SourceFunc("first", HasFields("nonexistent"), func(raw []byte) (Parsed, error) {
-		return Parsed{}, errors.New("no match")
+	r.AddSource(SourceFunc("first", HasFields("nonexistent"), func(raw []byte) (Message, error) {
+		return Message{}, errors.New("no match")
 	}))
 
 	// Second source matches
 	r.AddSource(&testSource{name: "second"})
 
 	h := &testHandler{}
-	Register(r, "test/event", h)
+	RegisterProc(r, "test/event", h)
 
 	var calledSource string
 	r.hooks.onParse = append(r.hooks.onParse, func(ctx context.Context, source, key string) context.Context {
@@ -188,7 +194,7 @@ func (s *GroupsSuite) TestDefaultGroupUsesDefaultInspector() {
 	s.router.AddSource(&testSource{name: "json-source"})
 
 	h := &testHandler{}
-	Register(s.router, "test/event", h)
+	RegisterProc(s.router, "test/event", h)
 
 	msg := []byte(`{"type": "test/event", "payload": {"value": "hello"}}`)
 	err := s.router.Process(context.Background(), msg)
@@ -199,24 +205,24 @@ func (s *GroupsSuite) TestDefaultGroupUsesDefaultInspector() {
 
 func (s *GroupsSuite) TestCustomGroupWithCustomInspector() {
 	customInspector := JSONInspector()
-	customSource := SourceFunc("custom", HasFields("event", "data"), func(raw []byte) (Parsed, error) {
+	customSource := SourceFunc("custom", HasFields("event", "data"), func(raw []byte) (Message, error) {
 		var env struct {
 			Event string          `json:"event"`
 			Data  json.RawMessage `json:"data"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
 		if env.Event == "" {
-			return Parsed{}, errors.New("missing event field")
+			return Message{}, errors.New("missing event field")
 		}
-		return Parsed{Key: env.Event, Payload: env.Data}, nil
+		return Message{Key: env.Event, Payload: env.Data}, nil
 	})
 
 	s.router.AddGroup(customInspector, customSource)
 
 	h := &testHandler{}
-	Register(s.router, "custom/event", h)
+	RegisterProc(s.router, "custom/event", h)
 
 	msg := []byte(`{"event": "custom/event", "data": {"value": "test"}}`)
 	err := s.router.Process(context.Background(), msg)
@@ -229,39 +235,39 @@ func (s *GroupsSuite) TestDefaultGroupCheckedBeforeCustomGroups() {
 	var matchedSource string
 
 	// Default group source
-	s.router.AddSource(SourceFunc("default", HasFields("type"), func(raw []byte) (Parsed, error) {
+	s.router.AddSource(SourceFunc("default", HasFields("type"), func(raw []byte) (Message, error) {
 		matchedSource = "default"
 		var env struct {
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
 		if env.Type == "" {
-			return Parsed{}, errors.New("missing type")
+			return Message{}, errors.New("missing type")
 		}
-		return Parsed{Key: env.Type, Payload: env.Payload}, nil
+		return Message{Key: env.Type, Payload: env.Payload}, nil
 	}))
 
 	// Custom group source (also matches "type" field)
-	customSource := SourceFunc("custom", HasFields("type"), func(raw []byte) (Parsed, error) {
+	customSource := SourceFunc("custom", HasFields("type"), func(raw []byte) (Message, error) {
 		matchedSource = "custom"
 		var env struct {
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
 		if env.Type == "" {
-			return Parsed{}, errors.New("missing type")
+			return Message{}, errors.New("missing type")
 		}
-		return Parsed{Key: env.Type, Payload: env.Payload}, nil
+		return Message{Key: env.Type, Payload: env.Payload}, nil
 	})
 	s.router.AddGroup(JSONInspector(), customSource)
 
-	Register(s.router, "test", &testHandler{})
+	RegisterProc(s.router, "test", &testHandler{})
 
 	msg := []byte(`{"type": "test", "payload": {}}`)
 	err := s.router.Process(context.Background(), msg)
@@ -296,7 +302,7 @@ func (s *AdaptiveOrderingSuite) TestLastMatchedSourceTriedFirst() {
 	r := New()
 
 	// First source - matches "first" type
-	r.AddSource(SourceFunc("first-source", HasFields("first"), func(raw []byte) (Parsed, error) {
+	r.AddSource(SourceFunc("first-source", HasFields("first"), func(raw []byte) (Message, error) {
 		matchOrder = append(matchOrder, "first-source")
 		var env struct {
 			First   bool            `json:"first"`
@@ -304,16 +310,16 @@ func (s *AdaptiveOrderingSuite) TestLastMatchedSourceTriedFirst() {
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
 		if !env.First {
-			return Parsed{}, errors.New("not first")
+			return Message{}, errors.New("not first")
 		}
-		return Parsed{Key: env.Type, Payload: env.Payload}, nil
+		return Message{Key: env.Type, Payload: env.Payload}, nil
 	}))
 
 	// Second source - matches "second" type
-	r.AddSource(SourceFunc("second-source", HasFields("second"), func(raw []byte) (Parsed, error) {
+	r.AddSource(SourceFunc("second-source", HasFields("second"), func(raw []byte) (Message, error) {
 		matchOrder = append(matchOrder, "second-source")
 		var env struct {
 			Second  bool            `json:"second"`
@@ -321,15 +327,15 @@ func (s *AdaptiveOrderingSuite) TestLastMatchedSourceTriedFirst() {
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
 		if !env.Second {
-			return Parsed{}, errors.New("not second")
+			return Message{}, errors.New("not second")
 		}
-		return Parsed{Key: env.Type, Payload: env.Payload}, nil
+		return Message{Key: env.Type, Payload: env.Payload}, nil
 	}))
 
-	Register(r, "test", &testHandler{})
+	RegisterProc(r, "test", &testHandler{})
 
 	// First message matches second source
 	msg1 := []byte(`{"second": true, "type": "test", "payload": {}}`)
@@ -351,37 +357,37 @@ func (s *AdaptiveOrderingSuite) TestLastMatchedSourceTriedFirst() {
 func (s *AdaptiveOrderingSuite) TestFallsBackToFullSearchWhenLastMatchFails() {
 	r := New()
 
-	r.AddSource(SourceFunc("first-source", HasFields("first"), func(raw []byte) (Parsed, error) {
+	r.AddSource(SourceFunc("first-source", HasFields("first"), func(raw []byte) (Message, error) {
 		var env struct {
 			First   bool            `json:"first"`
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
 		if !env.First {
-			return Parsed{}, errors.New("not first")
+			return Message{}, errors.New("not first")
 		}
-		return Parsed{Key: env.Type, Payload: env.Payload}, nil
+		return Message{Key: env.Type, Payload: env.Payload}, nil
 	}))
 
-	r.AddSource(SourceFunc("second-source", HasFields("second"), func(raw []byte) (Parsed, error) {
+	r.AddSource(SourceFunc("second-source", HasFields("second"), func(raw []byte) (Message, error) {
 		var env struct {
 			Second  bool            `json:"second"`
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
 		if !env.Second {
-			return Parsed{}, errors.New("not second")
+			return Message{}, errors.New("not second")
 		}
-		return Parsed{Key: env.Type, Payload: env.Payload}, nil
+		return Message{Key: env.Type, Payload: env.Payload}, nil
 	}))
 
-	Register(r, "test", &testHandler{})
+	RegisterProc(r, "test", &testHandler{})
 
 	// Prime with second source
 	msg1 := []byte(`{"second": true, "type": "test", "payload": {}}`)
@@ -420,7 +426,7 @@ func (s *HooksSuite) TestOnParseCalledWithSourceAndKey() {
 		return ctx
 	}))
 	s.router.AddSource(&testSource{name: "mysource"})
-	Register(s.router, "my/event", &testHandler{})
+	RegisterProc(s.router, "my/event", &testHandler{})
 
 	msg := []byte(`{"type": "my/event", "payload": {}}`)
 	err := s.router.Process(context.Background(), msg)
@@ -438,7 +444,7 @@ func (s *HooksSuite) TestOnDispatchCalledBeforeHandler() {
 	}))
 	s.router.AddSource(s.source)
 
-	Register(s.router, "test/event", HandlerFunc[testPayload](func(ctx context.Context, p testPayload) error {
+	RegisterProc(s.router, "test/event", ProcFunc[testPayload](func(ctx context.Context, p testPayload) error {
 		order = append(order, "handler")
 		return nil
 	}))
@@ -461,7 +467,7 @@ func (s *HooksSuite) TestOnSuccessCalledWithDuration() {
 		gotDuration = d
 	}))
 	s.router.AddSource(s.source)
-	Register(s.router, "test/event", s.handler)
+	RegisterProc(s.router, "test/event", s.handler)
 
 	msg := []byte(`{"type": "test/event", "payload": {}}`)
 	err := s.router.Process(context.Background(), msg)
@@ -481,7 +487,7 @@ func (s *HooksSuite) TestOnFailureCalledWithErrorAndDuration() {
 		gotDuration = d
 	}))
 	s.router.AddSource(s.source)
-	Register(s.router, "test/event", &testHandler{err: wantErr})
+	RegisterProc(s.router, "test/event", &testHandler{err: wantErr})
 
 	msg := []byte(`{"type": "test/event", "payload": {}}`)
 	err := s.router.Process(context.Background(), msg)
@@ -521,7 +527,7 @@ func (s *HooksSuite) TestOnUnmarshalErrorCanSkip() {
 	}))
 	s.router.AddSource(s.source)
 
-	Register(s.router, "test/event", s.handler)
+	RegisterProc(s.router, "test/event", s.handler)
 
 	msg := []byte(`{"type": "test/event", "payload": "not an object"}`)
 	err := s.router.Process(context.Background(), msg)
@@ -538,25 +544,25 @@ func TestCompletionSuite(t *testing.T) {
 }
 
 func (s *CompletionSuite) makeSourceWithCompletion(completeCalled *bool, completeErr *error) Source {
-	return SourceFunc("completion", HasFields("type", "payload"), func(raw []byte) (Parsed, error) {
+	return SourceFunc("completion", HasFields("type", "payload"), func(raw []byte) (Message, error) {
 		var env struct {
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
 		if env.Type == "" {
-			return Parsed{}, errors.New("missing type")
+			return Message{}, errors.New("missing type")
 		}
-		return Parsed{
+		return Message{
 			Key:     env.Type,
 			Payload: env.Payload,
-			Complete: func(ctx context.Context, err error) error {
+			Replier: completeReplier(func(ctx context.Context, err error) error {
 				*completeCalled = true
 				*completeErr = err
 				return nil
-			},
+			}),
 		}, nil
 	})
 }
@@ -567,7 +573,7 @@ func (s *CompletionSuite) TestCompleteCalledOnSuccess() {
 
 	r := New()
 	r.AddSource(s.makeSourceWithCompletion(&completeCalled, &completeErr))
-	Register(r, "test", &testHandler{})
+	RegisterProc(r, "test", &testHandler{})
 
 	msg := []byte(`{"type": "test", "payload": {"value": "x"}}`)
 	err := r.Process(context.Background(), msg)
@@ -585,12 +591,12 @@ func (s *CompletionSuite) TestCompleteCalledOnFailure() {
 	r.AddSource(s.makeSourceWithCompletion(&completeCalled, &completeErr))
 
 	wantErr := errors.New("handler error")
-	Register(r, "test", &testHandler{err: wantErr})
+	RegisterProc(r, "test", &testHandler{err: wantErr})
 
 	msg := []byte(`{"type": "test", "payload": {"value": "x"}}`)
 	err := r.Process(context.Background(), msg)
 
-	s.NoError(err) // Complete callback returns nil, swallowing the handler error
+	s.NoError(err) // Replier returns nil, swallowing the handler error
 	s.Assert().True(completeCalled)
 	s.Assert().ErrorIs(completeErr, wantErr)
 }
@@ -620,7 +626,7 @@ func (s *MultipleHooksSuite) TestChainsOnParseContexts() {
 		}),
 	)
 	r.AddSource(&testSource{name: "test"})
-	Register(r, "test", &testHandler{})
+	RegisterProc(r, "test", &testHandler{})
 
 	msg := []byte(`{"type": "test", "payload": {}}`)
 	err := r.Process(context.Background(), msg)
@@ -642,7 +648,7 @@ func (s *MultipleHooksSuite) TestCallsAllOnSuccessHooks() {
 		}),
 	)
 	r.AddSource(&testSource{name: "test"})
-	Register(r, "test", &testHandler{})
+	RegisterProc(r, "test", &testHandler{})
 
 	msg := []byte(`{"type": "test", "payload": {}}`)
 	err := r.Process(context.Background(), msg)
@@ -672,12 +678,12 @@ func (s *MultipleHooksSuite) TestFirstErrorWinsForOnNoHandler() {
 	s.Assert().ErrorIs(err, wantErr)
 }
 
-func TestRegisterFunc(t *testing.T) {
+func TestRegisterProcFunc(t *testing.T) {
 	r := New()
 	r.AddSource(&testSource{name: "test"})
 
 	var called bool
-	RegisterFunc(r, "test", func(ctx context.Context, p testPayload) error {
+	RegisterProcFunc(r, "test", func(ctx context.Context, p testPayload) error {
 		called = true
 		return nil
 	})
@@ -706,7 +712,7 @@ func TestValidationSuite(t *testing.T) {
 }
 
 func (s *ValidationSuite) TestValidatesPayloadWhenValidatable() {
-	RegisterFunc(s.router, "test", func(ctx context.Context, p validatablePayload) error {
+	RegisterProcFunc(s.router, "test", func(ctx context.Context, p validatablePayload) error {
 		return nil
 	})
 
@@ -718,7 +724,7 @@ func (s *ValidationSuite) TestValidatesPayloadWhenValidatable() {
 
 func (s *ValidationSuite) TestValidPayloadPassesValidation() {
 	var called bool
-	RegisterFunc(s.router, "test", func(ctx context.Context, p validatablePayload) error {
+	RegisterProcFunc(s.router, "test", func(ctx context.Context, p validatablePayload) error {
 		called = true
 		return nil
 	})
@@ -741,7 +747,7 @@ func (s *ValidationSuite) TestOnValidationErrorCanSkip() {
 	}))
 	r.AddSource(s.source)
 
-	RegisterFunc(r, "test", func(ctx context.Context, p validatablePayload) error {
+	RegisterProcFunc(r, "test", func(ctx context.Context, p validatablePayload) error {
 		s.Fail("handler should not be called on validation error")
 		return nil
 	})
@@ -762,7 +768,7 @@ func (s *ValidationSuite) TestOnValidationErrorCanReturnCustomError() {
 	}))
 	r.AddSource(s.source)
 
-	RegisterFunc(r, "test", func(ctx context.Context, p validatablePayload) error {
+	RegisterProcFunc(r, "test", func(ctx context.Context, p validatablePayload) error {
 		return nil
 	})
 
@@ -776,39 +782,39 @@ func (s *ValidationSuite) TestValidationErrorWithCompletionCallback() {
 	var completeErr error
 	var completeCalled bool
 
-	source := SourceFunc("completion", HasFields("type", "payload"), func(raw []byte) (Parsed, error) {
+	source := SourceFunc("completion", HasFields("type", "payload"), func(raw []byte) (Message, error) {
 		var env struct {
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
 		if env.Type == "" {
-			return Parsed{}, errors.New("missing type")
+			return Message{}, errors.New("missing type")
 		}
-		return Parsed{
+		return Message{
 			Key:     env.Type,
 			Payload: env.Payload,
-			Complete: func(ctx context.Context, err error) error {
+			Replier: completeReplier(func(ctx context.Context, err error) error {
 				completeCalled = true
 				completeErr = err
 				return nil
-			},
+			}),
 		}, nil
 	})
 
 	r := New()
 	r.AddSource(source)
 
-	RegisterFunc(r, "test", func(ctx context.Context, p validatablePayload) error {
+	RegisterProcFunc(r, "test", func(ctx context.Context, p validatablePayload) error {
 		return nil
 	})
 
 	msg := []byte(`{"type": "test", "payload": {"value": ""}}`)
 	err := r.Process(context.Background(), msg)
 
-	s.NoError(err) // Complete callback returns nil, swallowing the validation error
+	s.NoError(err) // Replier returns nil, swallowing the validation error
 	s.Assert().True(completeCalled)
 	s.Assert().Error(completeErr)
 }
@@ -819,7 +825,7 @@ func (s *ValidationSuite) TestSourceOnValidationErrorHookCalled() {
 	r := New()
 	r.AddSource(source)
 
-	RegisterFunc(r, "test", func(ctx context.Context, p validatablePayload) error {
+	RegisterProcFunc(r, "test", func(ctx context.Context, p validatablePayload) error {
 		return nil
 	})
 
@@ -841,23 +847,23 @@ func TestTrySourceInGroupsSuite(t *testing.T) {
 func (s *TrySourceInGroupsSuite) TestAdaptiveOrderingWorksWithCustomGroups() {
 	r := New()
 
-	customSource := SourceFunc("custom-group-source", HasFields("custom"), func(raw []byte) (Parsed, error) {
+	customSource := SourceFunc("custom-group-source", HasFields("custom"), func(raw []byte) (Message, error) {
 		var env struct {
 			Custom  bool            `json:"custom"`
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
 		if !env.Custom {
-			return Parsed{}, errors.New("not custom")
+			return Message{}, errors.New("not custom")
 		}
-		return Parsed{Key: env.Type, Payload: env.Payload}, nil
+		return Message{Key: env.Type, Payload: env.Payload}, nil
 	})
 
 	r.AddGroup(JSONInspector(), customSource)
-	Register(r, "test", &testHandler{})
+	RegisterProc(r, "test", &testHandler{})
 
 	msg1 := []byte(`{"custom": true, "type": "test", "payload": {}}`)
 	err := r.Process(context.Background(), msg1)
@@ -874,12 +880,12 @@ func (s *TrySourceInGroupsSuite) TestTrySourceHandlesInspectorErrorInCustomGroup
 	r.AddSource(&testSource{name: "default"})
 
 	failingInspector := &mockInspector{err: ErrInvalidJSON}
-	customSource := SourceFunc("custom", HasFields("custom"), func(raw []byte) (Parsed, error) {
-		return Parsed{Key: "test", Payload: []byte(`{}`)}, nil
+	customSource := SourceFunc("custom", HasFields("custom"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(`{}`)}, nil
 	})
 	r.AddGroup(failingInspector, customSource)
 
-	Register(r, "test/event", &testHandler{})
+	RegisterProc(r, "test/event", &testHandler{})
 
 	msg1 := []byte(`{"type": "test/event", "payload": {}}`)
 	err := r.Process(context.Background(), msg1)
@@ -903,36 +909,36 @@ func (s *UnmarshalErrorSuite) TestUnmarshalErrorCallsCompletionCallback() {
 	var completeErr error
 	var completeCalled bool
 
-	source := SourceFunc("completion", HasFields("type", "payload"), func(raw []byte) (Parsed, error) {
+	source := SourceFunc("completion", HasFields("type", "payload"), func(raw []byte) (Message, error) {
 		var env struct {
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
 		if env.Type == "" {
-			return Parsed{}, errors.New("missing type")
+			return Message{}, errors.New("missing type")
 		}
-		return Parsed{
+		return Message{
 			Key:     env.Type,
 			Payload: env.Payload,
-			Complete: func(ctx context.Context, err error) error {
+			Replier: completeReplier(func(ctx context.Context, err error) error {
 				completeCalled = true
 				completeErr = err
 				return nil
-			},
+			}),
 		}, nil
 	})
 
 	r := New()
 	r.AddSource(source)
-	Register(r, "test", &testHandler{})
+	RegisterProc(r, "test", &testHandler{})
 
 	msg := []byte(`{"type": "test", "payload": "not an object"}`)
 	err := r.Process(context.Background(), msg)
 
-	s.NoError(err) // Complete callback returns nil, swallowing the unmarshal error
+	s.NoError(err) // Replier returns nil, swallowing the unmarshal error
 	s.Assert().True(completeCalled)
 	s.Assert().Error(completeErr)
 }
@@ -982,7 +988,7 @@ func TestRouter_OnUnmarshalErrorReturnsCustomError(t *testing.T) {
 		return customErr
 	}))
 	r.AddSource(&testSource{name: "test"})
-	Register(r, "test", &testHandler{})
+	RegisterProc(r, "test", &testHandler{})
 
 	msg := []byte(`{"type": "test", "payload": "invalid"}`)
 	err := r.Process(context.Background(), msg)
@@ -991,8 +997,8 @@ func TestRouter_OnUnmarshalErrorReturnsCustomError(t *testing.T) {
 }
 
 func TestRouter_SourceParseFailsAfterDiscriminatorMatch(t *testing.T) {
-	flakySource := SourceFunc("flaky", HasFields("type"), func(raw []byte) (Parsed, error) {
-		return Parsed{}, errors.New("parse failed")
+	flakySource := SourceFunc("flaky", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{}, errors.New("parse failed")
 	})
 
 	r := New()
@@ -1016,7 +1022,7 @@ func TestRouter_SourceValidationErrorHookReturnsError(t *testing.T) {
 	}))
 	r.AddSource(source)
 
-	RegisterFunc(r, "test", func(ctx context.Context, p validatablePayload) error {
+	RegisterProcFunc(r, "test", func(ctx context.Context, p validatablePayload) error {
 		return nil
 	})
 
@@ -1029,24 +1035,24 @@ func TestRouter_SourceValidationErrorHookReturnsError(t *testing.T) {
 func TestRouter_CustomGroupMatchAll(t *testing.T) {
 	r := New()
 
-	r.AddSource(SourceFunc("default", HasFields("default_field"), func(raw []byte) (Parsed, error) {
-		return Parsed{}, errors.New("no match")
+	r.AddSource(SourceFunc("default", HasFields("default_field"), func(raw []byte) (Message, error) {
+		return Message{}, errors.New("no match")
 	}))
 
-	customSource := SourceFunc("custom", HasFields("custom_field"), func(raw []byte) (Parsed, error) {
+	customSource := SourceFunc("custom", HasFields("custom_field"), func(raw []byte) (Message, error) {
 		var env struct {
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
-		return Parsed{Key: env.Type, Payload: env.Payload}, nil
+		return Message{Key: env.Type, Payload: env.Payload}, nil
 	})
 	r.AddGroup(JSONInspector(), customSource)
 
 	var called bool
-	RegisterFunc(r, "test", func(ctx context.Context, p testPayload) error {
+	RegisterProcFunc(r, "test", func(ctx context.Context, p testPayload) error {
 		called = true
 		return nil
 	})
@@ -1061,23 +1067,23 @@ func TestRouter_CustomGroupMatchAll(t *testing.T) {
 func TestRouter_TrySourceCustomGroupMatch(t *testing.T) {
 	r := New()
 
-	customSource := SourceFunc("custom-src", HasFields("custom"), func(raw []byte) (Parsed, error) {
+	customSource := SourceFunc("custom-src", HasFields("custom"), func(raw []byte) (Message, error) {
 		var env struct {
 			Custom  bool            `json:"custom"`
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
 		if !env.Custom {
-			return Parsed{}, errors.New("not custom")
+			return Message{}, errors.New("not custom")
 		}
-		return Parsed{Key: env.Type, Payload: env.Payload}, nil
+		return Message{Key: env.Type, Payload: env.Payload}, nil
 	})
 	r.AddGroup(JSONInspector(), customSource)
 
-	Register(r, "test", &testHandler{})
+	RegisterProc(r, "test", &testHandler{})
 
 	msg1 := []byte(`{"custom": true, "type": "test", "payload": {}}`)
 	err := r.Process(context.Background(), msg1)
@@ -1093,8 +1099,8 @@ func TestRouter_MatchAllCustomGroupInspectorError(t *testing.T) {
 	r := New()
 
 	failingInspector := &mockInspector{err: ErrInvalidJSON}
-	customSource := SourceFunc("custom", HasFields("custom"), func(raw []byte) (Parsed, error) {
-		return Parsed{Key: "test", Payload: []byte(`{}`)}, nil
+	customSource := SourceFunc("custom", HasFields("custom"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(`{}`)}, nil
 	})
 	r.AddGroup(failingInspector, customSource)
 
@@ -1107,23 +1113,23 @@ func TestRouter_MatchAllCustomGroupInspectorError(t *testing.T) {
 func TestRouter_TrySourceFindsInCustomGroupDirectly(t *testing.T) {
 	r := New()
 
-	customSource := SourceFunc("only-custom", HasFields("x"), func(raw []byte) (Parsed, error) {
+	customSource := SourceFunc("only-custom", HasFields("x"), func(raw []byte) (Message, error) {
 		var env struct {
 			X       bool            `json:"x"`
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
 		if !env.X {
-			return Parsed{}, errors.New("not x")
+			return Message{}, errors.New("not x")
 		}
-		return Parsed{Key: env.Type, Payload: env.Payload}, nil
+		return Message{Key: env.Type, Payload: env.Payload}, nil
 	})
 	r.AddGroup(JSONInspector(), customSource)
 
-	Register(r, "event", &testHandler{})
+	RegisterProc(r, "event", &testHandler{})
 
 	msg1 := []byte(`{"x": true, "type": "event", "payload": {}}`)
 	err := r.Process(context.Background(), msg1)
@@ -1139,39 +1145,39 @@ func TestRouter_TrySourceInspectorFailsInCustomGroup(t *testing.T) {
 
 	conditionalInsp := &conditionalInspector{failAfter: 1}
 
-	customSource := SourceFunc("conditional-src", HasFields("c"), func(raw []byte) (Parsed, error) {
+	customSource := SourceFunc("conditional-src", HasFields("c"), func(raw []byte) (Message, error) {
 		var env struct {
 			C       bool            `json:"c"`
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
 		if !env.C {
-			return Parsed{}, errors.New("not c")
+			return Message{}, errors.New("not c")
 		}
-		return Parsed{Key: env.Type, Payload: env.Payload}, nil
+		return Message{Key: env.Type, Payload: env.Payload}, nil
 	})
 	r.AddGroup(conditionalInsp, customSource)
 
-	defaultSource := SourceFunc("default-src", HasFields("d"), func(raw []byte) (Parsed, error) {
+	defaultSource := SourceFunc("default-src", HasFields("d"), func(raw []byte) (Message, error) {
 		var env struct {
 			D       bool            `json:"d"`
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
 		if !env.D {
-			return Parsed{}, errors.New("not d")
+			return Message{}, errors.New("not d")
 		}
-		return Parsed{Key: env.Type, Payload: env.Payload}, nil
+		return Message{Key: env.Type, Payload: env.Payload}, nil
 	})
 	r.AddSource(defaultSource)
 
-	Register(r, "event", &testHandler{})
+	RegisterProc(r, "event", &testHandler{})
 
 	msg1 := []byte(`{"c": true, "type": "event", "payload": {}}`)
 	err := r.Process(context.Background(), msg1)
@@ -1210,21 +1216,21 @@ func (s *ViewCachingSuite) TestInspectorCalledOnceWhenTrySourceSucceeds() {
 
 	r := New(WithInspector(inspector))
 
-	source := SourceFunc("test-source", HasFields("type"), func(raw []byte) (Parsed, error) {
+	source := SourceFunc("test-source", HasFields("type"), func(raw []byte) (Message, error) {
 		var env struct {
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
 		if env.Type == "" {
-			return Parsed{}, errors.New("missing type")
+			return Message{}, errors.New("missing type")
 		}
-		return Parsed{Key: env.Type, Payload: env.Payload}, nil
+		return Message{Key: env.Type, Payload: env.Payload}, nil
 	})
 	r.AddSource(source)
-	Register(r, "test", &testHandler{})
+	RegisterProc(r, "test", &testHandler{})
 
 	// First message - no lastMatch, goes through matchAll
 	msg := []byte(`{"type": "test", "payload": {}}`)
@@ -1241,40 +1247,40 @@ func (s *ViewCachingSuite) TestInspectorCalledOnceWhenTrySourceFailsAndMatchAllS
 	r := New(WithInspector(inspector))
 
 	// Source A - matches "a" field
-	sourceA := SourceFunc("source-a", HasFields("a"), func(raw []byte) (Parsed, error) {
+	sourceA := SourceFunc("source-a", HasFields("a"), func(raw []byte) (Message, error) {
 		var env struct {
 			A       bool            `json:"a"`
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
 		if !env.A {
-			return Parsed{}, errors.New("not a")
+			return Message{}, errors.New("not a")
 		}
-		return Parsed{Key: env.Type, Payload: env.Payload}, nil
+		return Message{Key: env.Type, Payload: env.Payload}, nil
 	})
 
 	// Source B - matches "b" field
-	sourceB := SourceFunc("source-b", HasFields("b"), func(raw []byte) (Parsed, error) {
+	sourceB := SourceFunc("source-b", HasFields("b"), func(raw []byte) (Message, error) {
 		var env struct {
 			B       bool            `json:"b"`
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
 		if !env.B {
-			return Parsed{}, errors.New("not b")
+			return Message{}, errors.New("not b")
 		}
-		return Parsed{Key: env.Type, Payload: env.Payload}, nil
+		return Message{Key: env.Type, Payload: env.Payload}, nil
 	})
 
 	r.AddSource(sourceA)
 	r.AddSource(sourceB)
-	Register(r, "test", &testHandler{})
+	RegisterProc(r, "test", &testHandler{})
 
 	// Prime with source-a
 	msg1 := []byte(`{"a": true, "type": "test", "payload": {}}`)
@@ -1301,31 +1307,31 @@ func (s *ViewCachingSuite) TestInspectorCalledOncePerGroupWithMultipleGroups() {
 	r := New(WithInspector(defaultInspector))
 
 	// Default source - won't match
-	defaultSource := SourceFunc("default", HasFields("default_field"), func(raw []byte) (Parsed, error) {
-		return Parsed{}, errors.New("no match")
+	defaultSource := SourceFunc("default", HasFields("default_field"), func(raw []byte) (Message, error) {
+		return Message{}, errors.New("no match")
 	})
 	r.AddSource(defaultSource)
 
 	// Group 1 source - won't match
-	group1Source := SourceFunc("group1", HasFields("group1_field"), func(raw []byte) (Parsed, error) {
-		return Parsed{}, errors.New("no match")
+	group1Source := SourceFunc("group1", HasFields("group1_field"), func(raw []byte) (Message, error) {
+		return Message{}, errors.New("no match")
 	})
 	r.AddGroup(group1Inspector, group1Source)
 
 	// Group 2 source - will match
-	group2Source := SourceFunc("group2", HasFields("group2_field"), func(raw []byte) (Parsed, error) {
+	group2Source := SourceFunc("group2", HasFields("group2_field"), func(raw []byte) (Message, error) {
 		var env struct {
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
-		return Parsed{Key: env.Type, Payload: env.Payload}, nil
+		return Message{Key: env.Type, Payload: env.Payload}, nil
 	})
 	r.AddGroup(group2Inspector, group2Source)
 
-	Register(r, "test", &testHandler{})
+	RegisterProc(r, "test", &testHandler{})
 
 	msg := []byte(`{"group2_field": true, "type": "test", "payload": {}}`)
 	err := r.Process(context.Background(), msg)
@@ -1342,25 +1348,25 @@ func (s *ViewCachingSuite) TestSameInspectorSharedAcrossGroupsCalledOnce() {
 	r := New(WithInspector(sharedInspector))
 
 	// Default source - won't match
-	defaultSource := SourceFunc("default", HasFields("default_field"), func(raw []byte) (Parsed, error) {
-		return Parsed{}, errors.New("no match")
+	defaultSource := SourceFunc("default", HasFields("default_field"), func(raw []byte) (Message, error) {
+		return Message{}, errors.New("no match")
 	})
 	r.AddSource(defaultSource)
 
 	// Custom group using the SAME inspector
-	customSource := SourceFunc("custom", HasFields("custom_field"), func(raw []byte) (Parsed, error) {
+	customSource := SourceFunc("custom", HasFields("custom_field"), func(raw []byte) (Message, error) {
 		var env struct {
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
-		return Parsed{Key: env.Type, Payload: env.Payload}, nil
+		return Message{Key: env.Type, Payload: env.Payload}, nil
 	})
 	r.AddGroup(sharedInspector, customSource)
 
-	Register(r, "test", &testHandler{})
+	RegisterProc(r, "test", &testHandler{})
 
 	msg := []byte(`{"custom_field": true, "type": "test", "payload": {}}`)
 	err := r.Process(context.Background(), msg)
@@ -1377,25 +1383,25 @@ func (s *ViewCachingSuite) TestViewCacheHandlesInspectorError() {
 	r := New(WithInspector(failingInspector))
 
 	// Default source with failing inspector
-	defaultSource := SourceFunc("default", HasFields("x"), func(raw []byte) (Parsed, error) {
-		return Parsed{}, errors.New("no match")
+	defaultSource := SourceFunc("default", HasFields("x"), func(raw []byte) (Message, error) {
+		return Message{}, errors.New("no match")
 	})
 	r.AddSource(defaultSource)
 
 	// Custom group with working inspector
-	customSource := SourceFunc("custom", HasFields("type"), func(raw []byte) (Parsed, error) {
+	customSource := SourceFunc("custom", HasFields("type"), func(raw []byte) (Message, error) {
 		var env struct {
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
-		return Parsed{Key: env.Type, Payload: env.Payload}, nil
+		return Message{Key: env.Type, Payload: env.Payload}, nil
 	})
 	r.AddGroup(workingInspector, customSource)
 
-	Register(r, "test", &testHandler{})
+	RegisterProc(r, "test", &testHandler{})
 
 	msg := []byte(`{"type": "test", "payload": {}}`)
 	err := r.Process(context.Background(), msg)
@@ -1412,27 +1418,27 @@ func (s *ViewCachingSuite) TestViewCacheCachesFailureResult() {
 	r := New(WithInspector(failingInspector))
 
 	// Add multiple sources to default group to force multiple discriminator checks
-	r.AddSource(SourceFunc("src1", HasFields("a"), func(raw []byte) (Parsed, error) {
-		return Parsed{}, errors.New("no match")
+	r.AddSource(SourceFunc("src1", HasFields("a"), func(raw []byte) (Message, error) {
+		return Message{}, errors.New("no match")
 	}))
-	r.AddSource(SourceFunc("src2", HasFields("b"), func(raw []byte) (Parsed, error) {
-		return Parsed{}, errors.New("no match")
+	r.AddSource(SourceFunc("src2", HasFields("b"), func(raw []byte) (Message, error) {
+		return Message{}, errors.New("no match")
 	}))
 
 	// Custom group with working inspector
-	customSource := SourceFunc("custom", HasFields("type"), func(raw []byte) (Parsed, error) {
+	customSource := SourceFunc("custom", HasFields("type"), func(raw []byte) (Message, error) {
 		var env struct {
 			Type    string          `json:"type"`
 			Payload json.RawMessage `json:"payload"`
 		}
 		if err := json.Unmarshal(raw, &env); err != nil {
-			return Parsed{}, err
+			return Message{}, err
 		}
-		return Parsed{Key: env.Type, Payload: env.Payload}, nil
+		return Message{Key: env.Type, Payload: env.Payload}, nil
 	})
 	r.AddGroup(workingInspector, customSource)
 
-	Register(r, "test", &testHandler{})
+	RegisterProc(r, "test", &testHandler{})
 
 	msg := []byte(`{"type": "test", "payload": {}}`)
 	err := r.Process(context.Background(), msg)
@@ -1452,3 +1458,57 @@ func (f *failingCountingInspector) Inspect(raw []byte) (View, error) {
 	f.count++
 	return nil, ErrInvalidJSON
 }
+
+type ViewParserSuite struct {
+	suite.Suite
+}
+
+func TestViewParserSuite(t *testing.T) {
+	suite.Run(t, new(ViewParserSuite))
+}
+
+// viewParserSource parses from the matched view and counts Parse calls.
+type viewParserSource struct {
+	testSource
+	parseCalls int
+	views      int
+}
+
+func (s *viewParserSource) Parse(raw []byte) (Message, error) {
+	s.parseCalls++
+	return s.testSource.Parse(raw)
+}
+
+func (s *viewParserSource) ParseView(v View, raw []byte) (Message, error) {
+	s.views++
+	key, _ := v.GetString("type")
+	payload, _ := v.GetBytes("payload")
+	return Message{Key: key, Payload: payload}, nil
+}
+
+func (s *ViewParserSuite) TestParsesFromMatchedView() {
+	var got int
+	src := &viewParserSource{testSource: testSource{name: "test"}}
+	r := New()
+	r.AddSource(src)
+	RegisterProcFunc(r, "test", func(ctx context.Context, p struct{ N int }) error {
+		got = p.N
+		return nil
+	})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test", "payload": {"n": 7}}`)))
+	s.Assert().Equal(7, got)
+	s.Assert().Equal(1, src.views)
+	s.Assert().Zero(src.parseCalls)
+}
+
+func (s *ViewParserSuite) TestPreservedBySourceHooks() {
+	src := &viewParserSource{testSource: testSource{name: "test"}}
+	r := New()
+	r.AddSource(WithSourceHooks(src, SourceHooks{}))
+	RegisterProc(r, "test", &testHandler{})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`)))
+	s.Assert().Equal(1, src.views)
+	s.Assert().Zero(src.parseCalls)
+}
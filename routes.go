@@ -0,0 +1,162 @@
+package dispatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"text/tabwriter"
+)
+
+// RoutingTable describes how a router matches and handles messages, for
+// runbooks, documentation, and live debugging. It marshals to JSON.
+type RoutingTable struct {
+	Sources  []SourceRoute  `json:"sources"`
+	Handlers []HandlerRoute `json:"handlers"`
+}
+
+// SourceRoute describes a registered source.
+type SourceRoute struct {
+	// Name is the source's name.
+	Name string `json:"name"`
+	// Group is 0 for sources added with AddSource and n for sources added
+	// by the nth call to AddGroup or AddGroupWithHooks.
+	Group int `json:"group"`
+	// Inspector is the type of the inspector the source is matched with:
+	// its own, if it implements InspectorProvider, or its group's.
+	Inspector string `json:"inspector"`
+	// Discriminator is a readable form of the source's discriminator, such
+	// as and(has(detail-type), source == "orders").
+	Discriminator string `json:"discriminator"`
+	// Priority is the priority set with SetSourcePriority.
+	Priority int `json:"priority,omitempty"`
+	// Disabled reports whether the source was turned off with EnableSource.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// HandlerRoute describes the handler registered for a routing key.
+type HandlerRoute struct {
+	Key string `json:"key"`
+	// Kind is "proc" or "func".
+	Kind    string `json:"kind"`
+	Payload string `json:"payload"`
+	// Result is the result type of a func, and empty for procs.
+	Result  string `json:"result,omitempty"`
+	Handler string `json:"handler"`
+	// Schema reports whether the handler was registered WithJSONSchema.
+	Schema bool `json:"schema,omitempty"`
+}
+
+// RoutingTable returns the router's sources, in matching order, and its
+// handlers, sorted by key. Type names are Go type names such as
+// *orders.CreatedProc.
+//
+// Example:
+//
+//	for _, h := range r.RoutingTable().Handlers {
+//	    fmt.Println(h.Key, h.Payload, h.Handler)
+//	}
+func (r *Router) RoutingTable() RoutingTable {
+	t := RoutingTable{Sources: []SourceRoute{}, Handlers: []HandlerRoute{}}
+	settings := r.settings.Load()
+	for _, src := range settings.order(r.defaultSources) {
+		t.Sources = append(t.Sources, sourceRoute(src, 0, r.defaultInspector, settings))
+	}
+	for i, g := range r.groups {
+		for _, src := range settings.order(g.sources) {
+			t.Sources = append(t.Sources, sourceRoute(src, i+1, g.inspector, settings))
+		}
+	}
+	for _, info := range r.Handlers() {
+		h := HandlerRoute{
+			Key:     info.Key,
+			Kind:    "proc",
+			Payload: typeName(info.Payload),
+			Handler: typeName(info.Handler),
+			Schema:  info.Schema != nil,
+		}
+		if info.IsFunc() {
+			h.Kind = "func"
+			h.Result = typeName(info.Result)
+		}
+		t.Handlers = append(t.Handlers, h)
+	}
+	return t
+}
+
+// RoutingTable returns the compiled router's sources and handlers. See
+// Router.RoutingTable.
+func (c *CompiledRouter) RoutingTable() RoutingTable {
+	return c.r.RoutingTable()
+}
+
+func sourceRoute(src Source, group int, inspector Inspector, settings *sourceSettings) SourceRoute {
+	if own := sourceInspector(src); own != nil {
+		inspector = own
+	}
+	return SourceRoute{
+		Name:          src.Name(),
+		Group:         group,
+		Inspector:     typeName(reflect.TypeOf(inspector)),
+		Discriminator: describe(src.Discriminator()),
+		Priority:      settings.priorityOf(src.Name()),
+		Disabled:      !settings.enabled(src.Name()),
+	}
+}
+
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	return t.String()
+}
+
+// RoutingTableHandler returns an http.Handler that serves d's routing table
+// as JSON, or as aligned plain text when the request has ?format=text.
+// The table is read on every request, so it reflects sources and handlers
+// added after the handler was created.
+//
+// Example:
+//
+//	mux.Handle("/debug/dispatch/routes", dispatch.RoutingTableHandler(r))
+func RoutingTableHandler(d Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t := d.RoutingTable()
+		if req.URL.Query().Get("format") == "text" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			t.writeText(w)
+			return
+		}
+		body, err := json.MarshalIndent(t, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(append(body, '\n'))
+	})
+}
+
+// writeText writes t as two aligned tables.
+func (t RoutingTable) writeText(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SOURCE\tGROUP\tPRIORITY\tINSPECTOR\tDISCRIMINATOR")
+	for _, s := range t.Sources {
+		name := s.Name
+		if s.Disabled {
+			name += " (disabled)"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\n", name, s.Group, s.Priority, s.Inspector, s.Discriminator)
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "KEY\tKIND\tPAYLOAD\tRESULT\tHANDLER")
+	for _, h := range t.Handlers {
+		result := h.Result
+		if result == "" {
+			result = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", h.Key, h.Kind, h.Payload, result, h.Handler)
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,99 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type routesDiscriminator struct{}
+
+func (routesDiscriminator) Match(View) bool { return true }
+
+type routesSource struct {
+	testSource
+	d Discriminator
+}
+
+func (s *routesSource) Discriminator() Discriminator { return s.d }
+
+type RoutingTableSuite struct {
+	suite.Suite
+	router *Router
+}
+
+func TestRoutingTableSuite(t *testing.T) {
+	suite.Run(t, new(RoutingTableSuite))
+}
+
+func (s *RoutingTableSuite) SetupTest() {
+	s.router = New()
+	s.router.AddSource(&testSource{name: "test"})
+	s.router.AddGroupWithHooks(JSONInspector(), nil,
+		&routesSource{testSource: testSource{name: "custom"}, d: routesDiscriminator{}})
+	RegisterProc(s.router, "user/created", &testHandler{})
+	RegisterFuncFunc(s.router, "lookup", func(ctx context.Context, p testPayload) (float64, error) { return 0, nil },
+		WithJSONSchema([]byte(`{"type": "object"}`)))
+}
+
+func (s *RoutingTableSuite) TestSources() {
+	t := s.router.RoutingTable()
+
+	s.Assert().Equal([]SourceRoute{
+		{Name: "test", Group: 0, Inspector: "dispatch.jsonInspector", Discriminator: "has(type, payload)"},
+		{Name: "custom", Group: 1, Inspector: "dispatch.jsonInspector", Discriminator: "dispatch.routesDiscriminator"},
+	}, t.Sources)
+}
+
+func (s *RoutingTableSuite) TestHandlers() {
+	t := s.router.RoutingTable()
+
+	s.Assert().Equal([]HandlerRoute{
+		{Key: "lookup", Kind: "func", Payload: "dispatch.testPayload", Result: "float64", Handler: "dispatch.FuncFunc[github.com/bjaus/dispatch.testPayload,float64]", Schema: true},
+		{Key: "user/created", Kind: "proc", Payload: "dispatch.testPayload", Handler: "*dispatch.testHandler"},
+	}, t.Handlers)
+}
+
+func (s *RoutingTableSuite) TestEmptyRouter() {
+	data, err := json.Marshal(New().RoutingTable())
+	s.Require().NoError(err)
+
+	s.Assert().JSONEq(`{"sources": [], "handlers": []}`, string(data))
+}
+
+func (s *RoutingTableSuite) TestHandler_ServesJSON() {
+	rec := httptest.NewRecorder()
+	RoutingTableHandler(s.router).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/routes", nil))
+
+	s.Require().Equal(http.StatusOK, rec.Code)
+	s.Assert().Equal("application/json", rec.Header().Get("Content-Type"))
+	var t RoutingTable
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &t))
+	s.Assert().Equal(s.router.RoutingTable(), t)
+}
+
+func (s *RoutingTableSuite) TestHandler_ServesText() {
+	rec := httptest.NewRecorder()
+	RoutingTableHandler(s.router).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/routes?format=text", nil))
+
+	s.Require().Equal(http.StatusOK, rec.Code)
+	s.Assert().Equal("text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	s.Assert().Contains(body, "SOURCE")
+	s.Assert().Regexp(`test\s+0\s+0\s+dispatch.jsonInspector\s+has\(type, payload\)`, body)
+	s.Assert().Regexp(`user/created\s+proc\s+dispatch.testPayload\s+-\s+\*dispatch.testHandler`, body)
+}
+
+func (s *RoutingTableSuite) TestHandler_ReflectsLaterRegistrations() {
+	h := RoutingTableHandler(s.router)
+	RegisterProc(s.router, "user/deleted", &testHandler{})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/routes", nil))
+
+	s.Assert().Contains(rec.Body.String(), `"user/deleted"`)
+}
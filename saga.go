@@ -0,0 +1,325 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// SagaStatus is where a saga is in its lifecycle.
+type SagaStatus string
+
+// Saga statuses.
+const (
+	// SagaRunning means steps are still running; Step is the next one.
+	SagaRunning SagaStatus = "running"
+
+	// SagaCompensating means a step failed and the completed steps are being
+	// compensated in reverse; Step is the number left to compensate.
+	SagaCompensating SagaStatus = "compensating"
+
+	// SagaCompleted means every step succeeded.
+	SagaCompleted SagaStatus = "completed"
+
+	// SagaCompensated means a step failed and every completed step has been
+	// compensated.
+	SagaCompensated SagaStatus = "compensated"
+)
+
+// SagaState is the persisted progress of one run of a saga. It marshals to
+// JSON, so stores can keep it as a document.
+type SagaState struct {
+	// Saga is the saga's name.
+	Saga string `json:"saga"`
+
+	// ID identifies the run: the MessageID of the message that started it.
+	ID string `json:"id"`
+
+	Status SagaStatus `json:"status"`
+
+	// Step is the index of the next step to run while running, and the
+	// number of steps left to compensate while compensating.
+	Step int `json:"step"`
+
+	// Data is the input to the next step while running, and the saga's
+	// result once completed.
+	Data json.RawMessage `json:"data,omitempty"`
+
+	// Results holds the result of each completed step, which is passed to
+	// its compensation.
+	Results []json.RawMessage `json:"results,omitempty"`
+
+	// FailedStep and Error describe the step failure that started
+	// compensation.
+	FailedStep string `json:"failedStep,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// SagaStore persists saga state between steps, so a redelivered message
+// resumes its saga where it stopped instead of repeating completed steps.
+// Implementations must be safe for concurrent use.
+type SagaStore interface {
+	// Load returns the state of the run id of saga, and false if there is
+	// none.
+	Load(ctx context.Context, saga, id string) (SagaState, bool, error)
+
+	// Save stores state, replacing any earlier state for the same run.
+	Save(ctx context.Context, state SagaState) error
+}
+
+// SagaStep is one step of a saga, created with Step.
+type SagaStep struct {
+	name       string
+	run        func(ctx context.Context, in json.RawMessage) (json.RawMessage, error)
+	compensate func(ctx context.Context, result json.RawMessage) error
+}
+
+// Step returns a saga step that calls f with the previous step's result, or
+// the saga's input for the first step. If a later step fails, compensate,
+// if non-nil, is called with f's result to undo its effects.
+//
+// Example:
+//
+//	dispatch.Step("reserve-stock", &ReserveStock{}, dispatch.ProcFunc[Reservation](inventory.Release))
+func Step[T, R any](name string, f Func[T, R], compensate Proc[R]) SagaStep {
+	step := SagaStep{
+		name: name,
+		run: func(ctx context.Context, in json.RawMessage) (json.RawMessage, error) {
+			var input T
+			if err := json.Unmarshal(in, &input); err != nil {
+				return nil, fmt.Errorf("unmarshal input: %w", err)
+			}
+			result, err := f.Call(ctx, input)
+			if err != nil {
+				return nil, err
+			}
+			return json.Marshal(result)
+		},
+	}
+	if compensate != nil {
+		step.compensate = func(ctx context.Context, result json.RawMessage) error {
+			var r R
+			if err := json.Unmarshal(result, &r); err != nil {
+				return fmt.Errorf("unmarshal result: %w", err)
+			}
+			return compensate.Run(ctx, r)
+		}
+	}
+	return step
+}
+
+// Saga runs a chain of steps for one business flow, each a Func whose
+// result is the next step's input. Progress is saved to a SagaStore after
+// every step. If a step fails, the steps that completed are compensated in
+// reverse order and the saga fails with a *SagaError.
+//
+// Register a saga for a key with RegisterSaga.
+type Saga struct {
+	name  string
+	store SagaStore
+	steps []SagaStep
+}
+
+// NewSaga returns a saga named name that runs steps in order and saves its
+// progress to store. With a nil store, progress is kept only for the
+// duration of one message.
+//
+// Example:
+//
+//	checkout := dispatch.NewSaga("checkout", store,
+//	    dispatch.Step("reserve-stock", &ReserveStock{}, releaseStock),
+//	    dispatch.Step("charge-card", &ChargeCard{}, refundCard),
+//	    dispatch.Step("ship", &CreateShipment{}, nil),
+//	)
+func NewSaga(name string, store SagaStore, steps ...SagaStep) *Saga {
+	if len(steps) == 0 {
+		panic(fmt.Sprintf("dispatch: saga %s has no steps", name))
+	}
+	return &Saga{name: name, store: store, steps: steps}
+}
+
+// SagaError is returned by a saga whose step failed. Err is the step's
+// error. If every completed step was compensated, it also wraps
+// ErrPermanent, since redelivering the message won't change the outcome;
+// if compensation failed, CompensateErr is set and redelivery resumes the
+// compensation.
+type SagaError struct {
+	Saga          string
+	Step          string
+	Err           error
+	CompensateErr error
+}
+
+func (e *SagaError) Error() string {
+	msg := fmt.Sprintf("saga %s: step %s: %v", e.Saga, e.Step, e.Err)
+	if e.CompensateErr != nil {
+		msg += fmt.Sprintf(" (compensate: %v)", e.CompensateErr)
+	}
+	return msg
+}
+
+func (e *SagaError) Unwrap() []error {
+	if e.CompensateErr != nil {
+		return []error{e.Err, e.CompensateErr}
+	}
+	return []error{e.Err, ErrPermanent}
+}
+
+// RegisterSaga registers s as the function for key. The message payload,
+// a T, is the first step's input, and the last step's result, an R, is
+// the reply.
+//
+// A run is identified by the message's MessageID, which stays the same when
+// the transport redelivers the message; other messages in the same workflow
+// share its CorrelationID but run their own saga. Progress of messages
+// without a MessageID is not saved. When the message is redelivered, the
+// saga resumes from its saved state: completed steps don't run again, a
+// completed saga replies with its saved result, and a compensated one fails
+// with its saved error.
+//
+// Example:
+//
+//	dispatch.RegisterSaga[Order, Shipment](r, "checkout/requested", checkout)
+func RegisterSaga[T, R any](r *Router, key string, s *Saga, opts ...HandlerOption) {
+	RegisterFunc(r, key, FuncFunc[T, R](func(ctx context.Context, in T) (R, error) {
+		var result R
+		raw, err := json.Marshal(in)
+		if err != nil {
+			return result, fmt.Errorf("marshal input: %w", err)
+		}
+		out, err := s.Run(ctx, raw)
+		if err != nil {
+			return result, err
+		}
+		if err := json.Unmarshal(out, &result); err != nil {
+			return result, fmt.Errorf("unmarshal result: %w", err)
+		}
+		return result, nil
+	}), opts...)
+}
+
+// Run runs the saga with input for the message in ctx and returns the last
+// step's result. See RegisterSaga.
+func (s *Saga) Run(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	state, err := s.load(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	for state.Status == SagaRunning && state.Step < len(s.steps) {
+		step := s.steps[state.Step]
+		result, err := step.run(ctx, state.Data)
+		if err != nil {
+			state.Status = SagaCompensating
+			state.FailedStep = step.name
+			state.Error = err.Error()
+			if serr := s.save(ctx, state); serr != nil {
+				return nil, serr
+			}
+			return nil, s.compensate(ctx, &state, err)
+		}
+		state.Results = append(state.Results, result)
+		state.Data = result
+		state.Step++
+		if state.Step == len(s.steps) {
+			state.Status = SagaCompleted
+		}
+		if err := s.save(ctx, state); err != nil {
+			return nil, err
+		}
+	}
+
+	switch state.Status {
+	case SagaCompensating:
+		return nil, s.compensate(ctx, &state, errors.New(state.Error))
+	case SagaCompensated:
+		return nil, &SagaError{Saga: s.name, Step: state.FailedStep, Err: errors.New(state.Error)}
+	}
+	return state.Data, nil
+}
+
+// compensate undoes the completed steps in reverse order, saving progress
+// after each, and returns the *SagaError for cause.
+func (s *Saga) compensate(ctx context.Context, state *SagaState, cause error) error {
+	serr := &SagaError{Saga: s.name, Step: state.FailedStep, Err: cause}
+	for state.Step > 0 {
+		i := state.Step - 1
+		if c := s.steps[i].compensate; c != nil {
+			if err := c(ctx, state.Results[i]); err != nil {
+				serr.CompensateErr = fmt.Errorf("%s: %w", s.steps[i].name, err)
+				return serr
+			}
+		}
+		state.Step--
+		if state.Step == 0 {
+			break
+		}
+		if err := s.save(ctx, *state); err != nil {
+			serr.CompensateErr = err
+			return serr
+		}
+	}
+	state.Status = SagaCompensated
+	if err := s.save(ctx, *state); err != nil {
+		serr.CompensateErr = err
+	}
+	return serr
+}
+
+// load returns the saved state of the run for the message in ctx, or a new
+// state starting from input.
+func (s *Saga) load(ctx context.Context, input json.RawMessage) (SagaState, error) {
+	id := MessageID(ctx)
+	state := SagaState{Saga: s.name, ID: id, Status: SagaRunning, Data: input}
+	if s.store == nil || id == "" {
+		return state, nil
+	}
+	saved, ok, err := s.store.Load(ctx, s.name, id)
+	if err != nil {
+		return state, fmt.Errorf("load saga state: %w", err)
+	}
+	if ok {
+		return saved, nil
+	}
+	return state, nil
+}
+
+func (s *Saga) save(ctx context.Context, state SagaState) error {
+	if s.store == nil || state.ID == "" {
+		return nil
+	}
+	if err := s.store.Save(ctx, state); err != nil {
+		return fmt.Errorf("save saga state: %w", err)
+	}
+	return nil
+}
+
+// MemorySagaStore is an in-memory SagaStore for tests and local
+// development. The zero value is ready to use.
+type MemorySagaStore struct {
+	mu     sync.Mutex
+	states map[[2]string]SagaState
+}
+
+// Load implements SagaStore.
+func (m *MemorySagaStore) Load(ctx context.Context, saga, id string) (SagaState, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state, ok := m.states[[2]string{saga, id}]
+	return state, ok, nil
+}
+
+// Save implements SagaStore.
+func (m *MemorySagaStore) Save(ctx context.Context, state SagaState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.states == nil {
+		m.states = make(map[[2]string]SagaState)
+	}
+	state.Results = slices.Clone(state.Results)
+	m.states[[2]string{state.Saga, state.ID}] = state
+	return nil
+}
@@ -0,0 +1,214 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type sagaOrder struct {
+	ID     string `json:"id"`
+	Amount int    `json:"amount"`
+}
+
+type sagaReservation struct {
+	OrderID string `json:"orderId"`
+	Amount  int    `json:"amount"`
+}
+
+type sagaCharge struct {
+	ChargeID string `json:"chargeId"`
+}
+
+type SagaSuite struct {
+	suite.Suite
+	store      *MemorySagaStore
+	replier    *keysReplier
+	ran        []string
+	reserveErr error
+	chargeErr  error
+	releaseErr error
+	saga       *Saga
+	router     *Router
+}
+
+func TestSagaSuite(t *testing.T) {
+	suite.Run(t, new(SagaSuite))
+}
+
+func (s *SagaSuite) SetupTest() {
+	s.store = &MemorySagaStore{}
+	s.replier = &keysReplier{}
+	s.ran = nil
+	s.reserveErr, s.chargeErr, s.releaseErr = nil, nil, nil
+
+	s.saga = NewSaga("checkout", s.store,
+		Step("reserve", FuncFunc[sagaOrder, sagaReservation](func(ctx context.Context, o sagaOrder) (sagaReservation, error) {
+			s.ran = append(s.ran, "reserve "+o.ID)
+			if s.reserveErr != nil {
+				return sagaReservation{}, s.reserveErr
+			}
+			return sagaReservation{OrderID: o.ID, Amount: o.Amount}, nil
+		}), ProcFunc[sagaReservation](func(ctx context.Context, r sagaReservation) error {
+			s.ran = append(s.ran, "release "+r.OrderID)
+			return s.releaseErr
+		})),
+		Step("charge", FuncFunc[sagaReservation, sagaCharge](func(ctx context.Context, r sagaReservation) (sagaCharge, error) {
+			s.ran = append(s.ran, "charge "+r.OrderID)
+			if s.chargeErr != nil {
+				return sagaCharge{}, s.chargeErr
+			}
+			return sagaCharge{ChargeID: "ch-" + r.OrderID}, nil
+		}), nil),
+	)
+
+	s.router = New()
+	s.router.AddSource(SourceFunc("test", HasFields("order"), func(raw []byte) (Message, error) {
+		return Message{Key: "checkout", MessageID: "m-1", Payload: []byte(`{"id": "o-1", "amount": 5}`), Replier: s.replier}, nil
+	}))
+	RegisterSaga[sagaOrder, sagaCharge](s.router, "checkout", s.saga)
+}
+
+func (s *SagaSuite) process() error {
+	return s.router.Process(context.Background(), []byte(`{"order": true}`))
+}
+
+func (s *SagaSuite) state() SagaState {
+	state, ok, err := s.store.Load(context.Background(), "checkout", "m-1")
+	s.Require().NoError(err)
+	s.Require().True(ok)
+	return state
+}
+
+func (s *SagaSuite) TestRunsStepsInOrder() {
+	s.Require().NoError(s.process())
+
+	s.Assert().Equal([]string{"reserve o-1", "charge o-1"}, s.ran)
+	s.Assert().JSONEq(`{"chargeId": "ch-o-1"}`, string(s.replier.result))
+	state := s.state()
+	s.Assert().Equal(SagaCompleted, state.Status)
+	s.Assert().Equal(2, state.Step)
+}
+
+func (s *SagaSuite) TestCompletedSagaRepliesWithSavedResult() {
+	s.Require().NoError(s.process())
+	s.ran = nil
+
+	s.Require().NoError(s.process())
+	s.Assert().Empty(s.ran, "steps don't run again")
+	s.Assert().JSONEq(`{"chargeId": "ch-o-1"}`, string(s.replier.result))
+}
+
+func (s *SagaSuite) TestCompensatesOnFailure() {
+	s.chargeErr = errors.New("card declined")
+
+	s.Require().NoError(s.process())
+
+	s.Assert().Equal([]string{"reserve o-1", "charge o-1", "release o-1"}, s.ran)
+	var serr *SagaError
+	s.Require().ErrorAs(s.replier.err, &serr)
+	s.Assert().Equal("charge", serr.Step)
+	s.Assert().ErrorIs(serr, s.chargeErr)
+	s.Assert().ErrorIs(serr, ErrPermanent)
+	s.Assert().EqualError(serr, "saga checkout: step charge: card declined")
+	state := s.state()
+	s.Assert().Equal(SagaCompensated, state.Status)
+	s.Assert().Zero(state.Step)
+}
+
+func (s *SagaSuite) TestCompensatedSagaFailsAgain() {
+	s.chargeErr = errors.New("card declined")
+	s.Require().NoError(s.process())
+	s.ran = nil
+
+	s.Require().NoError(s.process())
+	s.Assert().Empty(s.ran)
+	s.Assert().EqualError(s.replier.err, "saga checkout: step charge: card declined")
+}
+
+func (s *SagaSuite) TestFirstStepFailure() {
+	s.reserveErr = errors.New("out of stock")
+
+	s.Require().NoError(s.process())
+	s.Assert().Equal([]string{"reserve o-1"}, s.ran)
+	state := s.state()
+	s.Assert().Equal(SagaCompensated, state.Status)
+	s.Assert().Zero(state.Step)
+
+	s.ran = nil
+	s.Require().NoError(s.process())
+	s.Assert().Empty(s.ran)
+	s.Assert().EqualError(s.replier.err, "saga checkout: step reserve: out of stock")
+}
+
+func (s *SagaSuite) TestRunsPerMessageNotPerCorrelation() {
+	r := New()
+	r.AddSource(SourceFunc("test", HasFields("id"), func(raw []byte) (Message, error) {
+		var env struct {
+			ID string `json:"id"`
+		}
+		err := json.Unmarshal(raw, &env)
+		return Message{Key: "checkout", MessageID: env.ID, CorrelationID: "flow-1", Payload: []byte(`{"id": "o-1", "amount": 5}`), Replier: s.replier}, err
+	}))
+	RegisterSaga[sagaOrder, sagaCharge](r, "checkout", s.saga)
+	s.chargeErr = errors.New("card declined")
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"id": "m-1"}`)))
+
+	s.chargeErr = nil
+	s.ran = nil
+	s.replier.err = nil
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"id": "m-2"}`)))
+
+	s.Assert().Equal([]string{"reserve o-1", "charge o-1"}, s.ran, "the second message runs its own saga")
+	s.Assert().NoError(s.replier.err)
+	s.Assert().JSONEq(`{"chargeId": "ch-o-1"}`, string(s.replier.result))
+	s.Assert().Equal(SagaCompensated, s.state().Status)
+}
+
+func (s *SagaSuite) TestFailedCompensationResumes() {
+	s.chargeErr = errors.New("card declined")
+	s.releaseErr = errors.New("inventory unavailable")
+
+	s.Require().NoError(s.process())
+	var serr *SagaError
+	s.Require().ErrorAs(s.replier.err, &serr)
+	s.Assert().ErrorIs(serr, s.releaseErr)
+	s.Assert().NotErrorIs(serr, ErrPermanent, "redelivery can finish compensating")
+	s.Assert().Equal(SagaCompensating, s.state().Status)
+
+	s.ran, s.releaseErr = nil, nil
+	s.Require().NoError(s.process())
+	s.Assert().Equal([]string{"release o-1"}, s.ran, "only compensation runs")
+	s.Assert().Equal(SagaCompensated, s.state().Status)
+}
+
+func (s *SagaSuite) TestResumesFromSavedStep() {
+	s.Require().NoError(s.store.Save(context.Background(), SagaState{
+		Saga:    "checkout",
+		ID:      "m-1",
+		Status:  SagaRunning,
+		Step:    1,
+		Data:    []byte(`{"orderId": "o-1", "amount": 5}`),
+		Results: []json.RawMessage{[]byte(`{"orderId": "o-1", "amount": 5}`)},
+	}))
+
+	s.Require().NoError(s.process())
+	s.Assert().Equal([]string{"charge o-1"}, s.ran)
+}
+
+func (s *SagaSuite) TestWithoutStore() {
+	saga := NewSaga("echo", nil, Step("echo", FuncFunc[sagaOrder, sagaOrder](func(ctx context.Context, o sagaOrder) (sagaOrder, error) {
+		return o, nil
+	}), nil))
+
+	out, err := saga.Run(context.Background(), []byte(`{"id": "o-2"}`))
+	s.Require().NoError(err)
+	s.Assert().JSONEq(`{"id": "o-2", "amount": 0}`, string(out))
+}
+
+func (s *SagaSuite) TestNoSteps() {
+	s.Assert().Panics(func() { NewSaga("empty", nil) })
+}
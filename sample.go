@@ -0,0 +1,55 @@
+package dispatch
+
+import (
+	"context"
+	"math/rand/v2"
+)
+
+// sampleKey carries one sampler's decision for a message. Each call to
+// WithSampledHooks allocates its own key so samplers decide independently.
+type sampleKey struct {
+	rate float64
+}
+
+// WithSampledHooks registers hook options that only fire for a random
+// fraction of messages, given by rate between 0 and 1. Use it for expensive
+// observability such as payload logging or tracing on high-throughput keys.
+//
+// The decision is made once per message when it is parsed, so a sampled
+// message runs all of the given hooks and an unsampled one runs none of them.
+// Messages that fail before parsing are sampled independently.
+//
+// Error hooks passed here (WithOnNoSource, WithOnParseError, WithOnNoHandler,
+// WithOnUnmarshalError, WithOnValidationError, WithOnOversize) decide skip
+// or fail, and WithOnReply changes what is sent, so they are registered
+// unsampled and always run. WithOnPause and WithOnResume hooks report the
+// workers' state rather than a message, so they always run too.
+//
+// Example:
+//
+//	dispatch.New(
+//	    dispatch.WithSampledHooks(0.01, dispatchotel.Hooks()...),
+//	)
+func WithSampledHooks(rate float64, opts ...Option) Option {
+	return func(r *Router) {
+		key := &sampleKey{rate: rate}
+
+		draw := func() bool {
+			return rate >= 1 || (rate > 0 && rand.Float64() < rate)
+		}
+		sampled := func(ctx context.Context, _, k string) (string, bool) {
+			if keep, ok := ctx.Value(key).(bool); ok {
+				return k, keep
+			}
+			return k, draw()
+		}
+		always := func(_ context.Context, _, k string) (string, bool) {
+			return k, true
+		}
+
+		r.hooks.onParse = append(r.hooks.onParse, func(ctx context.Context, _, _ string) context.Context {
+			return context.WithValue(ctx, key, draw())
+		})
+		r.hooks.add(mapHooks(collectHooks(opts), sampled, always))
+	}
+}
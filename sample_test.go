@@ -0,0 +1,88 @@
+package dispatch
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SampledHooksSuite struct {
+	suite.Suite
+}
+
+func TestSampledHooksSuite(t *testing.T) {
+	suite.Run(t, new(SampledHooksSuite))
+}
+
+// countHooks returns options that count OnParse and OnSuccess calls.
+func countHooks(parsed, succeeded *int) []Option {
+	return []Option{
+		WithOnParse(func(ctx context.Context, source, key string) context.Context {
+			*parsed++
+			return ctx
+		}),
+		WithOnSuccess(func(ctx context.Context, source, key string, d time.Duration) {
+			*succeeded++
+		}),
+	}
+}
+
+func (s *SampledHooksSuite) process(r *Router, n int) {
+	for i := range n {
+		msg := fmt.Appendf(nil, `{"type": "test", "payload": {"n": %d}}`, i)
+		s.Require().NoError(r.Process(context.Background(), msg))
+	}
+}
+
+func (s *SampledHooksSuite) TestRateOneAlwaysFires() {
+	var parsed, succeeded int
+	r := New(WithSampledHooks(1, countHooks(&parsed, &succeeded)...))
+	r.AddSource(&testSource{name: "test"})
+	RegisterProc(r, "test", &testHandler{})
+
+	s.process(r, 10)
+
+	s.Assert().Equal(10, parsed)
+	s.Assert().Equal(10, succeeded)
+}
+
+func (s *SampledHooksSuite) TestRateZeroNeverFires() {
+	var parsed, succeeded int
+	r := New(WithSampledHooks(0, countHooks(&parsed, &succeeded)...))
+	r.AddSource(&testSource{name: "test"})
+	RegisterProc(r, "test", &testHandler{})
+
+	s.process(r, 10)
+
+	s.Assert().Zero(parsed)
+	s.Assert().Zero(succeeded)
+}
+
+func (s *SampledHooksSuite) TestDecisionIsPerMessage() {
+	var parsed, succeeded int
+	r := New(WithSampledHooks(0.5, countHooks(&parsed, &succeeded)...))
+	r.AddSource(&testSource{name: "test"})
+	RegisterProc(r, "test", &testHandler{})
+
+	s.process(r, 200)
+
+	s.Assert().Equal(parsed, succeeded)
+	s.Assert().Positive(parsed)
+	s.Assert().Less(parsed, 200)
+}
+
+func (s *SampledHooksSuite) TestErrorHooksAreNotSampled() {
+	r := New(WithSampledHooks(0,
+		WithOnNoHandler(func(ctx context.Context, source, key string) error {
+			return nil
+		}),
+	))
+	r.AddSource(&testSource{name: "test"})
+
+	err := r.Process(context.Background(), []byte(`{"type": "unknown", "payload": {}}`))
+
+	s.Assert().NoError(err)
+}
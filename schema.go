@@ -0,0 +1,510 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// HandlerOption configures a single handler registration.
+type HandlerOption func(*handlerConfig)
+
+type handlerConfig struct {
+	schema     *jsonSchema
+	schemaJSON json.RawMessage
+	enabled    func(context.Context) bool
+}
+
+// handlerType records the types a handler was registered with, for
+// generating documentation from the routing table.
+type handlerType struct {
+	payload reflect.Type
+	result  reflect.Type // nil for Procs
+	handler reflect.Type
+	schema  json.RawMessage
+}
+
+func newHandlerConfig(opts []HandlerOption) handlerConfig {
+	var cfg handlerConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithJSONSchema validates each payload against a JSON Schema before it is
+// unmarshaled. Payloads that don't conform fail at StageValidate and go
+// through OnValidationError, like a failed Validate method, so schemas can be
+// governed separately from the Go types that consume them.
+//
+// The schema may use type, enum, const, properties, required,
+// additionalProperties, items, minimum, maximum, exclusiveMinimum,
+// exclusiveMaximum, multipleOf, minLength, maxLength, pattern, minItems,
+// maxItems, uniqueItems, minProperties, maxProperties, allOf, anyOf, oneOf,
+// not, and $ref to "#" or "#/$defs/...". Other keywords are ignored.
+// WithJSONSchema panics if schema is not a valid JSON Schema.
+//
+// Example:
+//
+//	//go:embed schemas/user-created.json
+//	var userCreatedSchema []byte
+//
+//	dispatch.RegisterProc(r, "user/created", proc, dispatch.WithJSONSchema(userCreatedSchema))
+func WithJSONSchema(schema []byte) HandlerOption {
+	s, err := compileJSONSchema(schema)
+	if err != nil {
+		panic(fmt.Sprintf("dispatch: invalid JSON schema: %v", err))
+	}
+	raw := json.RawMessage(slices.Clone(schema))
+	return func(c *handlerConfig) {
+		c.schema = s
+		c.schemaJSON = raw
+	}
+}
+
+// jsonSchema is a compiled JSON Schema. Boolean schemas are represented by
+// reject: true rejects everything and an empty jsonSchema accepts everything.
+type jsonSchema struct {
+	reject bool
+
+	types    []string
+	enum     []any
+	constant *any
+
+	properties           map[string]*jsonSchema
+	required             []string
+	additionalProperties *jsonSchema
+	minProperties        *int
+	maxProperties        *int
+
+	items       *jsonSchema
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+
+	allOf []*jsonSchema
+	anyOf []*jsonSchema
+	oneOf []*jsonSchema
+	not   *jsonSchema
+	ref   *jsonSchema
+}
+
+// schemaCompiler resolves $refs against the root document. Refs are cached
+// before they are compiled so recursive schemas terminate.
+type schemaCompiler struct {
+	root any
+	refs map[string]*jsonSchema
+}
+
+func compileJSONSchema(data []byte) (*jsonSchema, error) {
+	var root any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	c := &schemaCompiler{root: root, refs: make(map[string]*jsonSchema)}
+	return c.resolve("#")
+}
+
+func (c *schemaCompiler) resolve(ref string) (*jsonSchema, error) {
+	if s, ok := c.refs[ref]; ok {
+		return s, nil
+	}
+	if ref != "#" && !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q", ref)
+	}
+	node := c.root
+	if ref != "#" {
+		for _, tok := range strings.Split(ref[2:], "/") {
+			tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+			m, ok := node.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("unresolvable $ref %q", ref)
+			}
+			if node, ok = m[tok]; !ok {
+				return nil, fmt.Errorf("unresolvable $ref %q", ref)
+			}
+		}
+	}
+	s := &jsonSchema{}
+	c.refs[ref] = s
+	if err := c.compileInto(s, node); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (c *schemaCompiler) compile(node any) (*jsonSchema, error) {
+	s := &jsonSchema{}
+	if err := c.compileInto(s, node); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (c *schemaCompiler) compileInto(s *jsonSchema, node any) error {
+	if b, ok := node.(bool); ok {
+		s.reject = !b
+		return nil
+	}
+	m, ok := node.(map[string]any)
+	if !ok {
+		return fmt.Errorf("schema must be an object or boolean, got %T", node)
+	}
+
+	var err error
+	if v, ok := m["$ref"]; ok {
+		ref, ok := v.(string)
+		if !ok {
+			return errors.New("$ref must be a string")
+		}
+		if s.ref, err = c.resolve(ref); err != nil {
+			return err
+		}
+	}
+	switch v := m["type"].(type) {
+	case nil:
+	case string:
+		s.types = []string{v}
+	case []any:
+		for _, t := range v {
+			name, ok := t.(string)
+			if !ok {
+				return errors.New("type must be a string or array of strings")
+			}
+			s.types = append(s.types, name)
+		}
+	default:
+		return errors.New("type must be a string or array of strings")
+	}
+	if v, ok := m["enum"]; ok {
+		if s.enum, ok = v.([]any); !ok {
+			return errors.New("enum must be an array")
+		}
+	}
+	if v, ok := m["const"]; ok {
+		s.constant = &v
+	}
+
+	if v, ok := m["properties"]; ok {
+		props, ok := v.(map[string]any)
+		if !ok {
+			return errors.New("properties must be an object")
+		}
+		s.properties = make(map[string]*jsonSchema, len(props))
+		for name, p := range props {
+			if s.properties[name], err = c.compile(p); err != nil {
+				return fmt.Errorf("properties.%s: %w", name, err)
+			}
+		}
+	}
+	if v, ok := m["required"]; ok {
+		list, ok := v.([]any)
+		if !ok {
+			return errors.New("required must be an array of strings")
+		}
+		for _, name := range list {
+			str, ok := name.(string)
+			if !ok {
+				return errors.New("required must be an array of strings")
+			}
+			s.required = append(s.required, str)
+		}
+	}
+	if v, ok := m["additionalProperties"]; ok {
+		if s.additionalProperties, err = c.compile(v); err != nil {
+			return fmt.Errorf("additionalProperties: %w", err)
+		}
+	}
+	if v, ok := m["items"]; ok {
+		if s.items, err = c.compile(v); err != nil {
+			return fmt.Errorf("items: %w", err)
+		}
+	}
+	if v, ok := m["uniqueItems"].(bool); ok {
+		s.uniqueItems = v
+	}
+
+	for name, dst := range map[string]**float64{
+		"minimum":          &s.minimum,
+		"maximum":          &s.maximum,
+		"exclusiveMinimum": &s.exclusiveMinimum,
+		"exclusiveMaximum": &s.exclusiveMaximum,
+		"multipleOf":       &s.multipleOf,
+	} {
+		if v, ok := m[name]; ok {
+			n, ok := v.(float64)
+			if !ok {
+				return fmt.Errorf("%s must be a number", name)
+			}
+			*dst = &n
+		}
+	}
+	for name, dst := range map[string]**int{
+		"minLength":     &s.minLength,
+		"maxLength":     &s.maxLength,
+		"minItems":      &s.minItems,
+		"maxItems":      &s.maxItems,
+		"minProperties": &s.minProperties,
+		"maxProperties": &s.maxProperties,
+	} {
+		if v, ok := m[name]; ok {
+			n, ok := v.(float64)
+			if !ok || n < 0 || n != math.Trunc(n) {
+				return fmt.Errorf("%s must be a non-negative integer", name)
+			}
+			i := int(n)
+			*dst = &i
+		}
+	}
+	if v, ok := m["pattern"]; ok {
+		str, ok := v.(string)
+		if !ok {
+			return errors.New("pattern must be a string")
+		}
+		if s.pattern, err = regexp.Compile(str); err != nil {
+			return fmt.Errorf("pattern: %w", err)
+		}
+	}
+
+	for name, dst := range map[string]*[]*jsonSchema{
+		"allOf": &s.allOf,
+		"anyOf": &s.anyOf,
+		"oneOf": &s.oneOf,
+	} {
+		if v, ok := m[name]; ok {
+			list, ok := v.([]any)
+			if !ok || len(list) == 0 {
+				return fmt.Errorf("%s must be a non-empty array", name)
+			}
+			for i, sub := range list {
+				compiled, err := c.compile(sub)
+				if err != nil {
+					return fmt.Errorf("%s.%d: %w", name, i, err)
+				}
+				*dst = append(*dst, compiled)
+			}
+		}
+	}
+	if v, ok := m["not"]; ok {
+		if s.not, err = c.compile(v); err != nil {
+			return fmt.Errorf("not: %w", err)
+		}
+	}
+	return nil
+}
+
+// check returns every violation of the schema by the decoded payload v.
+func (s *jsonSchema) check(v any) error {
+	return errors.Join(s.validate(v, "")...)
+}
+
+// validate returns every violation of the schema by v, found at the JSON
+// Pointer path.
+func (s *jsonSchema) validate(v any, path string) []error {
+	if s.reject {
+		return []error{schemaErr(path, "is not allowed")}
+	}
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, schemaErr(path, fmt.Sprintf(format, args...)))
+	}
+
+	if s.ref != nil {
+		errs = append(errs, s.ref.validate(v, path)...)
+	}
+	if len(s.types) > 0 && !slices.ContainsFunc(s.types, func(t string) bool { return hasJSONType(v, t) }) {
+		fail("must be %s, got %s", strings.Join(s.types, " or "), jsonType(v))
+		return errs
+	}
+	if s.enum != nil && !slices.ContainsFunc(s.enum, func(e any) bool { return reflect.DeepEqual(e, v) }) {
+		fail("must be one of %s", mustJSON(s.enum))
+	}
+	if s.constant != nil && !reflect.DeepEqual(*s.constant, v) {
+		fail("must be %s", mustJSON(*s.constant))
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		errs = append(errs, s.validateObject(v, path)...)
+	case []any:
+		errs = append(errs, s.validateArray(v, path)...)
+	case float64:
+		errs = append(errs, s.validateNumber(v, path)...)
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.minLength != nil && n < *s.minLength {
+			fail("must be at least %d characters", *s.minLength)
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			fail("must be at most %d characters", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("must match %q", s.pattern)
+		}
+	}
+
+	for _, sub := range s.allOf {
+		errs = append(errs, sub.validate(v, path)...)
+	}
+	if s.anyOf != nil && !slices.ContainsFunc(s.anyOf, func(sub *jsonSchema) bool { return sub.matches(v) }) {
+		fail("must match a schema in anyOf")
+	}
+	if s.oneOf != nil {
+		n := 0
+		for _, sub := range s.oneOf {
+			if sub.matches(v) {
+				n++
+			}
+		}
+		if n != 1 {
+			fail("must match exactly one schema in oneOf, matched %d", n)
+		}
+	}
+	if s.not != nil && s.not.matches(v) {
+		fail("must not match the schema in not")
+	}
+	return errs
+}
+
+func (s *jsonSchema) matches(v any) bool {
+	return len(s.validate(v, "")) == 0
+}
+
+func (s *jsonSchema) validateObject(obj map[string]any, path string) []error {
+	var errs []error
+	for _, name := range s.required {
+		if _, ok := obj[name]; !ok {
+			errs = append(errs, schemaErr(path, fmt.Sprintf("missing required property %q", name)))
+		}
+	}
+	if s.minProperties != nil && len(obj) < *s.minProperties {
+		errs = append(errs, schemaErr(path, fmt.Sprintf("must have at least %d properties", *s.minProperties)))
+	}
+	if s.maxProperties != nil && len(obj) > *s.maxProperties {
+		errs = append(errs, schemaErr(path, fmt.Sprintf("must have at most %d properties", *s.maxProperties)))
+	}
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		sub, ok := s.properties[name]
+		if !ok {
+			sub = s.additionalProperties
+		}
+		if sub != nil {
+			errs = append(errs, sub.validate(obj[name], path+"/"+escapePointer(name))...)
+		}
+	}
+	return errs
+}
+
+func (s *jsonSchema) validateArray(arr []any, path string) []error {
+	var errs []error
+	if s.minItems != nil && len(arr) < *s.minItems {
+		errs = append(errs, schemaErr(path, fmt.Sprintf("must have at least %d items", *s.minItems)))
+	}
+	if s.maxItems != nil && len(arr) > *s.maxItems {
+		errs = append(errs, schemaErr(path, fmt.Sprintf("must have at most %d items", *s.maxItems)))
+	}
+	if s.uniqueItems {
+		for i := range arr {
+			if slices.ContainsFunc(arr[:i], func(e any) bool { return reflect.DeepEqual(e, arr[i]) }) {
+				errs = append(errs, schemaErr(path, "must have unique items"))
+				break
+			}
+		}
+	}
+	if s.items != nil {
+		for i, item := range arr {
+			errs = append(errs, s.items.validate(item, path+"/"+strconv.Itoa(i))...)
+		}
+	}
+	return errs
+}
+
+func (s *jsonSchema) validateNumber(n float64, path string) []error {
+	var errs []error
+	check := func(bad bool, format string, limit float64) {
+		if bad {
+			errs = append(errs, schemaErr(path, fmt.Sprintf(format, limit)))
+		}
+	}
+	if s.minimum != nil {
+		check(n < *s.minimum, "must be >= %v", *s.minimum)
+	}
+	if s.maximum != nil {
+		check(n > *s.maximum, "must be <= %v", *s.maximum)
+	}
+	if s.exclusiveMinimum != nil {
+		check(n <= *s.exclusiveMinimum, "must be > %v", *s.exclusiveMinimum)
+	}
+	if s.exclusiveMaximum != nil {
+		check(n >= *s.exclusiveMaximum, "must be < %v", *s.exclusiveMaximum)
+	}
+	if s.multipleOf != nil && *s.multipleOf != 0 {
+		q := n / *s.multipleOf
+		check(q != math.Trunc(q), "must be a multiple of %v", *s.multipleOf)
+	}
+	return errs
+}
+
+func hasJSONType(v any, t string) bool {
+	if t == "integer" {
+		n, ok := v.(float64)
+		return ok && n == math.Trunc(n)
+	}
+	return jsonType(v) == t
+}
+
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func schemaErr(path, msg string) error {
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Errorf("%s: %s", path, msg)
+}
+
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func mustJSON(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
@@ -0,0 +1,120 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SchemaSuite struct {
+	suite.Suite
+}
+
+func TestSchemaSuite(t *testing.T) {
+	suite.Run(t, new(SchemaSuite))
+}
+
+func (s *SchemaSuite) check(schema, payload string) error {
+	compiled, err := compileJSONSchema([]byte(schema))
+	s.Require().NoError(err)
+	var v any
+	s.Require().NoError(json.Unmarshal([]byte(payload), &v))
+	return compiled.check(v)
+}
+
+func (s *SchemaSuite) TestKeywords() {
+	tests := []struct {
+		name    string
+		schema  string
+		payload string
+		wantErr string
+	}{
+		{"type ok", `{"type": "string"}`, `"x"`, ""},
+		{"type mismatch", `{"type": "string"}`, `1`, "/: must be string, got number"},
+		{"type list", `{"type": ["string", "null"]}`, `null`, ""},
+		{"integer", `{"type": "integer"}`, `1.5`, "/: must be integer, got number"},
+		{"integer ok", `{"type": "integer"}`, `2`, ""},
+		{"enum", `{"enum": ["a", "b"]}`, `"c"`, `/: must be one of ["a","b"]`},
+		{"const", `{"const": 3}`, `4`, "/: must be 3"},
+		{"required", `{"required": ["id"]}`, `{}`, `/: missing required property "id"`},
+		{"property", `{"properties": {"id": {"type": "string"}}}`, `{"id": 1}`, "/id: must be string, got number"},
+		{"additional false", `{"properties": {"id": {}}, "additionalProperties": false}`, `{"id": 1, "x": 2}`, "/x: is not allowed"},
+		{"additional schema", `{"additionalProperties": {"type": "number"}}`, `{"a~b/c": "x"}`, "/a~0b~1c: must be number, got string"},
+		{"min properties", `{"minProperties": 1}`, `{}`, "/: must have at least 1 properties"},
+		{"items", `{"items": {"type": "number"}}`, `[1, "x"]`, "/1: must be number, got string"},
+		{"min items", `{"minItems": 2}`, `[1]`, "/: must have at least 2 items"},
+		{"max items", `{"maxItems": 1}`, `[1, 2]`, "/: must have at most 1 items"},
+		{"unique items", `{"uniqueItems": true}`, `[{"a": 1}, {"a": 1}]`, "/: must have unique items"},
+		{"minimum", `{"minimum": 1}`, `0`, "/: must be >= 1"},
+		{"maximum", `{"maximum": 1}`, `2`, "/: must be <= 1"},
+		{"exclusive minimum", `{"exclusiveMinimum": 1}`, `1`, "/: must be > 1"},
+		{"exclusive maximum", `{"exclusiveMaximum": 1}`, `1`, "/: must be < 1"},
+		{"multiple of", `{"multipleOf": 0.5}`, `1.25`, "/: must be a multiple of 0.5"},
+		{"min length", `{"minLength": 2}`, `"é"`, "/: must be at least 2 characters"},
+		{"max length", `{"maxLength": 1}`, `"ab"`, "/: must be at most 1 characters"},
+		{"pattern", `{"pattern": "^[a-z]+$"}`, `"A"`, `/: must match "^[a-z]+$"`},
+		{"all of", `{"allOf": [{"type": "number"}, {"minimum": 5}]}`, `4`, "/: must be >= 5"},
+		{"any of", `{"anyOf": [{"type": "number"}, {"type": "null"}]}`, `"x"`, "/: must match a schema in anyOf"},
+		{"one of", `{"oneOf": [{"type": "number"}, {"minimum": 0}]}`, `1`, "/: must match exactly one schema in oneOf, matched 2"},
+		{"not", `{"not": {"type": "null"}}`, `null`, "/: must not match the schema in not"},
+		{"false schema", `false`, `1`, "/: is not allowed"},
+		{"true schema", `true`, `1`, ""},
+		{"unknown keywords ignored", `{"format": "email", "description": "x"}`, `"x"`, ""},
+		{"defs ref", `{"$defs": {"id": {"type": "string"}}, "properties": {"id": {"$ref": "#/$defs/id"}}}`, `{"id": 1}`, "/id: must be string, got number"},
+		{"recursive ref", `{"properties": {"child": {"$ref": "#"}}, "required": ["name"]}`, `{"name": "a", "child": {}}`, `/child: missing required property "name"`},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			err := s.check(tt.schema, tt.payload)
+			if tt.wantErr == "" {
+				s.Assert().NoError(err)
+				return
+			}
+			s.Assert().EqualError(err, tt.wantErr)
+		})
+	}
+}
+
+func (s *SchemaSuite) TestReportsEveryViolation() {
+	err := s.check(`{"required": ["a", "b"], "properties": {"c": {"type": "string"}}}`, `{"c": 1}`)
+
+	s.Assert().EqualError(err, "/: missing required property \"a\"\n/: missing required property \"b\"\n/c: must be string, got number")
+}
+
+func (s *SchemaSuite) TestInvalidSchemasPanic() {
+	for _, schema := range []string{
+		`{`,
+		`1`,
+		`{"type": 1}`,
+		`{"required": "id"}`,
+		`{"minLength": -1}`,
+		`{"pattern": "("}`,
+		`{"anyOf": []}`,
+		`{"$ref": "#/$defs/missing"}`,
+		`{"$ref": "other.json"}`,
+	} {
+		s.Assert().Panics(func() { WithJSONSchema([]byte(schema)) }, schema)
+	}
+}
+
+func (s *SchemaSuite) TestRouterRejectsNonConformingPayloads() {
+	var validationErr error
+	r := New(WithOnValidationError(func(ctx context.Context, source, key string, err error) error {
+		validationErr = err
+		return err
+	}))
+	r.AddSource(&testSource{name: "test"})
+	handler := &testHandler{}
+	RegisterProc(r, "test", handler, WithJSONSchema([]byte(`{"required": ["value"]}`)))
+
+	err := r.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`))
+
+	s.Assert().Error(err)
+	s.Assert().EqualError(validationErr, `/: missing required property "value"`)
+	s.Assert().False(handler.called)
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test", "payload": {"value": "x"}}`)))
+	s.Assert().True(handler.called)
+}
@@ -0,0 +1,91 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrSchemaNotFound is returned by a SchemaProvider that has no schema for a
+// key and version.
+var ErrSchemaNotFound = errors.New("schema not found")
+
+// SchemaProvider looks up the JSON Schema of a routing key's payload at a
+// version, such as from a schema registry. An empty version means the
+// latest.
+type SchemaProvider interface {
+	Schema(ctx context.Context, key, version string) ([]byte, error)
+}
+
+// SchemaProviderFunc is a function adapter for SchemaProvider.
+type SchemaProviderFunc func(ctx context.Context, key, version string) ([]byte, error)
+
+// Schema implements the SchemaProvider interface.
+func (f SchemaProviderFunc) Schema(ctx context.Context, key, version string) ([]byte, error) {
+	return f(ctx, key, version)
+}
+
+// WithSchemaProvider validates every payload against the schema p returns
+// for the message's Key and Version, before it is unmarshaled, so schema
+// evolution is enforced centrally rather than by each consumer's types.
+//
+// Payloads that don't conform, and messages whose version p returns
+// ErrSchemaNotFound for, fail at StageValidate and go through
+// OnValidationError. Other errors from p fail the message like a handler
+// error, so it is retried. Compiled schemas are cached; p should cache
+// lookups itself if they are expensive. See the schemaregistry package for a
+// Confluent Schema Registry provider.
+//
+// Example:
+//
+//	r := dispatch.New(dispatch.WithSchemaProvider(schemaregistry.NewConfluent("http://registry:8081")))
+func WithSchemaProvider(p SchemaProvider) Option {
+	return func(r *Router) {
+		r.schemas = &schemaChecker{provider: p}
+	}
+}
+
+// schemaChecker validates payloads against a SchemaProvider's schemas.
+type schemaChecker struct {
+	provider SchemaProvider
+	compiled sync.Map // schema source -> *jsonSchema
+}
+
+// check validates msg's payload. Violations are returned as
+// *validationError and unmarshal failures as *unmarshalError.
+func (c *schemaChecker) check(ctx context.Context, msg Message, t *Timings) error {
+	if c == nil {
+		return nil
+	}
+	start := time.Now()
+	defer func() { t.Validate += time.Since(start) }()
+
+	data, err := c.provider.Schema(ctx, msg.Key, msg.Version)
+	if errors.Is(err, ErrSchemaNotFound) {
+		return &validationError{err: err}
+	}
+	if err != nil {
+		return fmt.Errorf("schema %s@%s: %w", msg.Key, msg.Version, err)
+	}
+
+	schema, ok := c.compiled.Load(string(data))
+	if !ok {
+		compiled, err := compileJSONSchema(data)
+		if err != nil {
+			return fmt.Errorf("schema %s@%s: %w", msg.Key, msg.Version, err)
+		}
+		schema, _ = c.compiled.LoadOrStore(string(data), compiled)
+	}
+
+	var v any
+	if err := json.Unmarshal(msg.Payload, &v); err != nil {
+		return &unmarshalError{err: err}
+	}
+	if err := schema.(*jsonSchema).check(v); err != nil {
+		return &validationError{err: err}
+	}
+	return nil
+}
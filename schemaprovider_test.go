@@ -0,0 +1,95 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SchemaProviderSuite struct {
+	suite.Suite
+	versions map[string]string
+	lookups  []string
+	err      error
+	handler  *testHandler
+	r        *Router
+}
+
+func TestSchemaProviderSuite(t *testing.T) {
+	suite.Run(t, new(SchemaProviderSuite))
+}
+
+func (s *SchemaProviderSuite) SetupTest() {
+	s.versions = map[string]string{"": `{"required": ["value"]}`}
+	s.lookups = nil
+	s.err = nil
+	s.handler = &testHandler{}
+	s.r = s.newRouter()
+}
+
+func (s *SchemaProviderSuite) newRouter(opts ...Option) *Router {
+	r := New(append(opts, WithSchemaProvider(SchemaProviderFunc(func(ctx context.Context, key, version string) ([]byte, error) {
+		s.lookups = append(s.lookups, key+"@"+version)
+		if s.err != nil {
+			return nil, s.err
+		}
+		schema, ok := s.versions[version]
+		if !ok {
+			return nil, ErrSchemaNotFound
+		}
+		return []byte(schema), nil
+	})))...)
+	r.AddSource(&testSource{name: "test"})
+	RegisterProc(r, "test", s.handler)
+	return r
+}
+
+func (s *SchemaProviderSuite) TestValidPayload() {
+	s.Require().NoError(s.r.Process(context.Background(), []byte(`{"type": "test", "payload": {"value": "x"}}`)))
+
+	s.Assert().True(s.handler.called)
+	s.Assert().Equal([]string{"test@"}, s.lookups)
+}
+
+func (s *SchemaProviderSuite) TestViolationIsValidationError() {
+	var got error
+	s.r = s.newRouter(WithOnValidationError(func(ctx context.Context, source, key string, err error) error {
+		got = err
+		return nil
+	}))
+
+	s.Require().NoError(s.r.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`)))
+
+	s.Assert().False(s.handler.called)
+	s.Assert().EqualError(got, `/: missing required property "value"`)
+}
+
+func (s *SchemaProviderSuite) TestUnknownVersionIsValidationError() {
+	s.versions = map[string]string{}
+
+	err := s.r.Process(context.Background(), []byte(`{"type": "test", "payload": {"value": "x"}}`))
+
+	s.Assert().ErrorIs(err, ErrValidation)
+	s.Assert().ErrorIs(err, ErrSchemaNotFound)
+	s.Assert().False(s.handler.called)
+}
+
+func (s *SchemaProviderSuite) TestProviderErrorFailsMessage() {
+	s.err = errors.New("registry down")
+
+	err := s.r.Process(context.Background(), []byte(`{"type": "test", "payload": {"value": "x"}}`))
+
+	s.Assert().ErrorIs(err, s.err)
+	s.Assert().NotErrorIs(err, ErrValidation)
+	s.Assert().False(s.handler.called)
+}
+
+func (s *SchemaProviderSuite) TestInvalidSchemaFailsMessage() {
+	s.versions[""] = `{"type": 1}`
+
+	err := s.r.Process(context.Background(), []byte(`{"type": "test", "payload": {"value": "x"}}`))
+
+	s.Assert().ErrorContains(err, "schema test@: type must be a string or array of strings")
+}
@@ -0,0 +1,174 @@
+// Package schemaregistry provides dispatch.SchemaProvider implementations
+// backed by schema registries, for validating payloads against centrally
+// governed, versioned schemas:
+//
+//	r := dispatch.New(dispatch.WithSchemaProvider(schemaregistry.NewConfluent("http://registry:8081")))
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bjaus/dispatch"
+)
+
+// Option configures a Confluent provider.
+type Option func(*Confluent)
+
+// WithHTTPClient sets the HTTP client used to reach the registry. Defaults to
+// http.DefaultClient.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Confluent) {
+		c.client = client
+	}
+}
+
+// WithBasicAuth authenticates requests with an API key and secret, as
+// Confluent Cloud requires.
+func WithBasicAuth(username, password string) Option {
+	return func(c *Confluent) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithSubject sets how routing keys map to subjects. Defaults to the key
+// itself.
+func WithSubject(fn func(key string) string) Option {
+	return func(c *Confluent) {
+		c.subject = fn
+	}
+}
+
+// WithLatestTTL sets how long the latest version of a subject is cached.
+// Specific versions are immutable and cached indefinitely. Defaults to one
+// minute.
+func WithLatestTTL(d time.Duration) Option {
+	return func(c *Confluent) {
+		c.latestTTL = d
+	}
+}
+
+// Confluent is a dispatch.SchemaProvider that fetches JSON schemas from a
+// Confluent Schema Registry, or any registry implementing its REST API. The
+// message version is the subject version; an empty version means the latest.
+type Confluent struct {
+	baseURL   string
+	client    *http.Client
+	username  string
+	password  string
+	subject   func(key string) string
+	latestTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedSchema
+}
+
+type cachedSchema struct {
+	schema  []byte
+	expires time.Time // zero for specific versions
+}
+
+// NewConfluent returns a provider for the registry at baseURL.
+func NewConfluent(baseURL string, opts ...Option) *Confluent {
+	c := &Confluent{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		client:    http.DefaultClient,
+		subject:   func(key string) string { return key },
+		latestTTL: time.Minute,
+		cache:     make(map[string]cachedSchema),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Schema implements dispatch.SchemaProvider. It returns an error wrapping
+// dispatch.ErrSchemaNotFound if the subject or version does not exist.
+func (c *Confluent) Schema(ctx context.Context, key, version string) ([]byte, error) {
+	subject := c.subject(key)
+	if version == "" {
+		version = "latest"
+	}
+	cacheKey := subject + "@" + version
+
+	c.mu.Lock()
+	cached, ok := c.cache[cacheKey]
+	c.mu.Unlock()
+	if ok && (cached.expires.IsZero() || time.Now().Before(cached.expires)) {
+		return cached.schema, nil
+	}
+
+	schema, err := c.fetch(ctx, subject, version)
+	if err != nil {
+		return nil, err
+	}
+	cached = cachedSchema{schema: schema}
+	if version == "latest" {
+		cached.expires = time.Now().Add(c.latestTTL)
+	}
+	c.mu.Lock()
+	c.cache[cacheKey] = cached
+	c.mu.Unlock()
+	return schema, nil
+}
+
+func (c *Confluent) fetch(ctx context.Context, subject, version string) ([]byte, error) {
+	u := fmt.Sprintf("%s/subjects/%s/versions/%s", c.baseURL, url.PathEscape(subject), url.PathEscape(version))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(body, &e)
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s version %s", dispatch.ErrSchemaNotFound, subject, version)
+		}
+		return nil, fmt.Errorf("schema registry: %s: %s", resp.Status, e.Message)
+	}
+
+	var out struct {
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("schema registry: %w", err)
+	}
+	if out.SchemaType != "JSON" {
+		// The registry omits schemaType for Avro, its default.
+		typ := out.SchemaType
+		if typ == "" {
+			typ = "AVRO"
+		}
+		return nil, fmt.Errorf("schema registry: %s version %s is %s, not JSON", subject, version, typ)
+	}
+	return []byte(out.Schema), nil
+}
+
+var _ dispatch.SchemaProvider = (*Confluent)(nil)
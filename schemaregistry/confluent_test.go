@@ -0,0 +1,139 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfluentSuite struct {
+	suite.Suite
+	srv      *httptest.Server
+	requests atomic.Int32
+	paths    []string
+	auth     string
+}
+
+func TestConfluentSuite(t *testing.T) {
+	suite.Run(t, new(ConfluentSuite))
+}
+
+func (s *ConfluentSuite) SetupTest() {
+	s.requests.Store(0)
+	s.paths = nil
+	s.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		s.paths = append(s.paths, r.URL.EscapedPath())
+		s.auth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+		switch r.URL.EscapedPath() {
+		case "/subjects/user%2Fcreated/versions/1", "/subjects/user%2Fcreated/versions/latest", "/subjects/users-value/versions/latest":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"subject":    "user/created",
+				"version":    1,
+				"schemaType": "JSON",
+				"schema":     `{"type": "object", "required": ["id"]}`,
+			})
+		case "/subjects/avro/versions/latest":
+			_ = json.NewEncoder(w).Encode(map[string]any{"schema": `{"type": "record"}`})
+		case "/subjects/broken/versions/latest":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"error_code": 50001, "message": "store error"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code": 40401, "message": "Subject not found."}`))
+		}
+	}))
+}
+
+func (s *ConfluentSuite) TearDownTest() {
+	s.srv.Close()
+}
+
+func (s *ConfluentSuite) TestFetchesVersion() {
+	c := NewConfluent(s.srv.URL+"/", WithBasicAuth("key", "secret"))
+
+	schema, err := c.Schema(context.Background(), "user/created", "1")
+
+	s.Require().NoError(err)
+	s.Assert().JSONEq(`{"type": "object", "required": ["id"]}`, string(schema))
+	s.Assert().Equal([]string{"/subjects/user%2Fcreated/versions/1"}, s.paths)
+	s.Assert().Equal("Basic a2V5OnNlY3JldA==", s.auth)
+}
+
+func (s *ConfluentSuite) TestCachesVersions() {
+	c := NewConfluent(s.srv.URL)
+
+	for range 3 {
+		_, err := c.Schema(context.Background(), "user/created", "1")
+		s.Require().NoError(err)
+	}
+
+	s.Assert().EqualValues(1, s.requests.Load())
+}
+
+func (s *ConfluentSuite) TestLatestExpires() {
+	c := NewConfluent(s.srv.URL, WithLatestTTL(time.Nanosecond))
+
+	_, err := c.Schema(context.Background(), "user/created", "")
+	s.Require().NoError(err)
+	time.Sleep(time.Millisecond)
+	_, err = c.Schema(context.Background(), "user/created", "")
+	s.Require().NoError(err)
+
+	s.Assert().EqualValues(2, s.requests.Load())
+	s.Assert().Equal("/subjects/user%2Fcreated/versions/latest", s.paths[0])
+}
+
+func (s *ConfluentSuite) TestSubjectMapping() {
+	c := NewConfluent(s.srv.URL, WithSubject(func(key string) string { return "users-value" }))
+
+	_, err := c.Schema(context.Background(), "user/created", "")
+
+	s.Assert().NoError(err)
+}
+
+func (s *ConfluentSuite) TestErrors() {
+	c := NewConfluent(s.srv.URL)
+
+	_, err := c.Schema(context.Background(), "missing", "")
+	s.Assert().ErrorIs(err, dispatch.ErrSchemaNotFound)
+
+	_, err = c.Schema(context.Background(), "broken", "")
+	s.Assert().EqualError(err, "schema registry: 500 Internal Server Error: store error")
+
+	_, err = c.Schema(context.Background(), "avro", "")
+	s.Assert().EqualError(err, "schema registry: avro version latest is AVRO, not JSON")
+}
+
+func (s *ConfluentSuite) TestValidatesRoutedPayloads() {
+	r := dispatch.New(dispatch.WithSchemaProvider(NewConfluent(s.srv.URL)))
+	r.AddSource(dispatch.SourceFunc("test", dispatch.HasFields("key"), func(raw []byte) (dispatch.Message, error) {
+		var env struct {
+			Key     string          `json:"key"`
+			Version string          `json:"version"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		err := json.Unmarshal(raw, &env)
+		return dispatch.Message{Key: env.Key, Version: env.Version, Payload: env.Payload}, err
+	}))
+	called := false
+	dispatch.RegisterProcFunc(r, "user/created", func(ctx context.Context, p struct{ ID string }) error {
+		called = true
+		return nil
+	})
+
+	err := r.Process(context.Background(), []byte(`{"key": "user/created", "version": "1", "payload": {}}`))
+	s.Assert().ErrorIs(err, dispatch.ErrValidation)
+	s.Assert().False(called)
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"key": "user/created", "version": "1", "payload": {"id": "42"}}`)))
+	s.Assert().True(called)
+}
@@ -0,0 +1,134 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	contextType = reflect.TypeFor[context.Context]()
+	errorType   = reflect.TypeFor[error]()
+)
+
+// RegisterService registers each exported method of svc that has the shape
+// of a procedure or function as a handler for prefix plus the method name,
+// like a gRPC service. Methods of the form
+//
+//	func(ctx context.Context, payload T) error
+//
+// are registered as procedures, and methods of the form
+//
+//	func(ctx context.Context, payload T) (R, error)
+//
+// as functions. Other methods are ignored. opts apply to every handler. If
+// svc implements Starter or Closer, it is started and closed once.
+//
+// Methods are called through reflection, which costs a little more per
+// message than RegisterProc and RegisterFunc. RegisterService panics if svc
+// has no handler methods.
+//
+// Example:
+//
+//	type OrderService struct{ db *sql.DB }
+//
+//	func (s *OrderService) Created(ctx context.Context, o Order) error { ... }
+//	func (s *OrderService) Lookup(ctx context.Context, q OrderQuery) (Order, error) { ... }
+//
+//	// registers "order/Created" and "order/Lookup"
+//	dispatch.RegisterService(r, "order/", &OrderService{db: db})
+func RegisterService(r *Router, prefix string, svc any, opts ...HandlerOption) {
+	cfg := newHandlerConfig(opts)
+	v := reflect.ValueOf(svc)
+	var names []string
+	for i := range v.NumMethod() {
+		method := v.Type().Method(i)
+		if !method.IsExported() {
+			continue
+		}
+		mt := method.Type
+		// method.Type includes the receiver.
+		if mt.NumIn() != 3 || mt.In(1) != contextType || mt.IsVariadic() {
+			continue
+		}
+		var result reflect.Type
+		switch {
+		case mt.NumOut() == 1 && mt.Out(0) == errorType:
+		case mt.NumOut() == 2 && mt.Out(1) == errorType:
+			result = mt.Out(0)
+		default:
+			continue
+		}
+
+		key := prefix + method.Name
+		payload := mt.In(2)
+		r.checkKey(key)
+		r.checkRegistry(key, payload)
+		r.handlerTypes[key] = handlerType{payload: payload, result: result, handler: v.Type(), schema: cfg.schemaJSON}
+		r.setEnabled(key, cfg.enabled)
+		r.handlers[key] = methodInvoker(v.Method(i), payload, result != nil, cfg)
+		names = append(names, method.Name)
+	}
+	if len(names) == 0 {
+		panic(fmt.Sprintf("dispatch: %T has no handler methods", svc))
+	}
+	// Manage the service under a name of its own, such as
+	// "order/{Created,Lookup}", so replacing one of its keys keeps it.
+	r.manage(prefix+"{"+strings.Join(names, ",")+"}", svc)
+}
+
+// methodInvoker returns an invoker that calls a bound method taking a
+// context and a payload of type payload. If hasResult, the method returns a
+// result before its error, which is marshaled as a Func result is.
+func methodInvoker(method reflect.Value, payload reflect.Type, hasResult bool, cfg handlerConfig) invoker {
+	return func(ctx context.Context, raw json.RawMessage, t *Timings) (json.RawMessage, error) {
+		if err := checkSchema(raw, t, cfg.schema); err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		data := reflect.New(payload)
+		err := json.Unmarshal(raw, data.Interface())
+		t.Unmarshal = time.Since(start)
+		if err != nil {
+			return nil, &unmarshalError{err: err}
+		}
+
+		start = time.Now()
+		err = validateValue(data)
+		t.Validate += time.Since(start)
+		if err != nil {
+			return nil, &validationError{err: err}
+		}
+
+		start = time.Now()
+		out := method.Call([]reflect.Value{reflect.ValueOf(&ctx).Elem(), data.Elem()})
+		t.Handle = time.Since(start)
+		if err, _ := out[len(out)-1].Interface().(error); err != nil {
+			return nil, err
+		}
+		if !hasResult {
+			return []byte("{}"), nil
+		}
+		resultJSON, err := json.Marshal(out[0].Interface())
+		if err != nil {
+			return nil, fmt.Errorf("marshal result: %w", err)
+		}
+		return resultJSON, nil
+	}
+}
+
+// validateValue calls Validate on the value ptr points to if its type or
+// pointer type implements validatable, as validate does.
+func validateValue(ptr reflect.Value) error {
+	if v, ok := ptr.Elem().Interface().(validatable); ok {
+		return v.Validate()
+	}
+	if v, ok := ptr.Interface().(validatable); ok {
+		return v.Validate()
+	}
+	return nil
+}
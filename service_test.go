@@ -0,0 +1,166 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// orderService is a service with handler methods and methods that aren't
+// handlers.
+type orderService struct {
+	created []string
+	started int
+	closed  int
+}
+
+func (s *orderService) Created(ctx context.Context, p testPayload) error {
+	s.created = append(s.created, p.Value)
+	return nil
+}
+
+func (s *orderService) Lookup(ctx context.Context, p testPayload) (string, error) {
+	return "order " + p.Value, nil
+}
+
+func (s *orderService) Fail(ctx context.Context, p testPayload) error {
+	return errors.New("failed " + p.Value)
+}
+
+func (s *orderService) Checked(ctx context.Context, p validatedPayload) error { return nil }
+
+func (s *orderService) Start(ctx context.Context) error {
+	s.started++
+	return nil
+}
+
+func (s *orderService) Close(ctx context.Context) error {
+	s.closed++
+	return nil
+}
+
+func (s *orderService) Name() string { return "orders" }
+
+func (s *orderService) lookup(ctx context.Context, p testPayload) error { return nil }
+
+// validatedPayload fails validation when Value is empty.
+type validatedPayload struct {
+	Value string `json:"value"`
+}
+
+func (p validatedPayload) Validate() error {
+	if p.Value == "" {
+		return errors.New("value is required")
+	}
+	return nil
+}
+
+type ServiceSuite struct {
+	suite.Suite
+	router  *Router
+	svc     *orderService
+	replier *keysReplier
+}
+
+func TestServiceSuite(t *testing.T) {
+	suite.Run(t, new(ServiceSuite))
+}
+
+func (s *ServiceSuite) SetupTest() {
+	s.svc = &orderService{}
+	s.replier = &keysReplier{}
+	s.router = New()
+	s.router.AddSource(SourceFunc("test", HasFields("key"), func(raw []byte) (Message, error) {
+		var env struct {
+			Key     string          `json:"key"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := json.Unmarshal(raw, &env); err != nil {
+			return Message{}, err
+		}
+		return Message{Key: env.Key, Payload: env.Payload, Replier: s.replier}, nil
+	}))
+	RegisterService(s.router, "order/", s.svc)
+}
+
+func (s *ServiceSuite) process(key, payload string) error {
+	return s.router.Process(context.Background(), []byte(`{"key": "`+key+`", "payload": `+payload+`}`))
+}
+
+func (s *ServiceSuite) TestProc() {
+	s.Require().NoError(s.process("order/Created", `{"value": "o-1"}`))
+	s.Assert().Equal([]string{"o-1"}, s.svc.created)
+	s.Assert().JSONEq(`{}`, string(s.replier.result))
+}
+
+func (s *ServiceSuite) TestFunc() {
+	s.Require().NoError(s.process("order/Lookup", `{"value": "o-1"}`))
+	s.Assert().JSONEq(`"order o-1"`, string(s.replier.result))
+}
+
+func (s *ServiceSuite) TestHandlerError() {
+	s.Require().NoError(s.process("order/Fail", `{"value": "o-1"}`), "the failure is sent to the replier")
+	s.Assert().EqualError(s.replier.err, "failed o-1")
+}
+
+func (s *ServiceSuite) TestUnmarshalError() {
+	s.Require().NoError(s.process("order/Created", `{"value": 1}`))
+	s.Assert().ErrorContains(s.replier.err, "cannot unmarshal")
+	s.Assert().Empty(s.svc.created)
+}
+
+func (s *ServiceSuite) TestValidation() {
+	var validationErr error
+	r := New(WithOnValidationError(func(ctx context.Context, source, key string, err error) error {
+		validationErr = err
+		return err
+	}))
+	r.AddSource(&testSource{name: "test"})
+	RegisterService(r, "", s.svc)
+
+	s.Assert().Error(r.Process(context.Background(), []byte(`{"type": "Checked", "payload": {}}`)))
+	s.Assert().EqualError(validationErr, "value is required")
+}
+
+func (s *ServiceSuite) TestIgnoresOtherMethods() {
+	var keys []string
+	for _, h := range s.router.RoutingTable().Handlers {
+		keys = append(keys, h.Key)
+	}
+	s.Assert().ElementsMatch([]string{"order/Checked", "order/Created", "order/Fail", "order/Lookup"}, keys)
+}
+
+func (s *ServiceSuite) TestRoutingTable() {
+	for _, h := range s.router.RoutingTable().Handlers {
+		if h.Key == "order/Lookup" {
+			s.Assert().Equal("func", h.Kind)
+			s.Assert().Equal("string", h.Result)
+			s.Assert().Equal("dispatch.testPayload", h.Payload)
+		}
+	}
+}
+
+func (s *ServiceSuite) TestLifecycleOnce() {
+	s.Require().NoError(s.router.Start(context.Background()))
+	s.Require().NoError(s.router.Shutdown(context.Background()))
+	s.Assert().Equal(1, s.svc.started)
+	s.Assert().Equal(1, s.svc.closed)
+}
+
+func (s *ServiceSuite) TestLifecycleKeptWhenKeyReplaced() {
+	RegisterProc(s.router, "order/Checked", &testHandler{})
+
+	s.Require().NoError(s.router.Start(context.Background()))
+	s.Require().NoError(s.router.Shutdown(context.Background()))
+	s.Assert().Equal(1, s.svc.started)
+	s.Assert().Equal(1, s.svc.closed)
+}
+
+func (s *ServiceSuite) TestNoHandlerMethods() {
+	s.Assert().PanicsWithValue("dispatch: *dispatch.testSource has no handler methods", func() {
+		RegisterService(New(), "x/", &testSource{})
+	})
+}
@@ -0,0 +1,111 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ShadowResult is the outcome of a handler for one message, as reported to
+// OnShadow hooks.
+type ShadowResult struct {
+	// Result is the handler's JSON-encoded result; {} for Procs and nil
+	// when Err is set.
+	Result json.RawMessage
+	// Err is the error the handler returned. For shadows it also reports
+	// unmarshal and validation errors and recovered panics.
+	Err error
+	// Duration is how long the handler took.
+	Duration time.Duration
+}
+
+// OnShadowFunc is called when a shadow handler finishes, with the primary
+// handler's outcome and the shadow's, so the two can be compared.
+type OnShadowFunc func(ctx context.Context, source, key string, primary, shadow ShadowResult)
+
+// WithOnShadow adds a hook called after each shadow handler run, from the
+// shadow's goroutine. Multiple hooks are called in order.
+//
+// Example:
+//
+//	dispatch.WithOnShadow(func(ctx context.Context, source, key string, primary, shadow dispatch.ShadowResult) {
+//	    if !bytes.Equal(primary.Result, shadow.Result) || (primary.Err == nil) != (shadow.Err == nil) {
+//	        logger.Warn("shadow mismatch", "key", key)
+//	    }
+//	})
+func WithOnShadow(fn OnShadowFunc) Option {
+	return func(r *Router) {
+		r.hooks.onShadow = append(r.hooks.onShadow, fn)
+	}
+}
+
+// RegisterShadowProc runs p in shadow mode for key: after the primary
+// handler has run, p is called in its own goroutine with the same payload,
+// and both outcomes are reported to the OnShadow hooks. The shadow never
+// affects the message's outcome, reply, hooks, or stats, and panics in it
+// are recovered. Use it to validate a rewrite of a critical handler against
+// production traffic before switching over.
+//
+// The shadow runs only for messages the primary handler ran for. Shutdown
+// waits for running shadows, and none start once it has been called.
+// Registering another shadow for key replaces the previous one.
+//
+// Example:
+//
+//	dispatch.RegisterProc(r, "order/created", &OrderProc{})
+//	dispatch.RegisterShadowProc(r, "order/created", &OrderProcV2{})
+func RegisterShadowProc[T any](r *Router, key string, p Proc[T], opts ...HandlerOption) {
+	r.checkKey(key)
+	r.checkRegistry(key, reflect.TypeFor[T]())
+	r.manage(shadowKey(key), p)
+	r.shadows[key] = procInvoker(p, newHandlerConfig(opts))
+}
+
+// RegisterShadowFunc runs f in shadow mode for key, as RegisterShadowProc
+// does. Its result type may differ from the primary's; OnShadow hooks
+// receive both results as JSON.
+func RegisterShadowFunc[T, R any](r *Router, key string, f Func[T, R], opts ...HandlerOption) {
+	r.checkKey(key)
+	r.checkRegistry(key, reflect.TypeFor[T]())
+	r.manage(shadowKey(key), f)
+	r.shadows[key] = funcInvoker(f, newHandlerConfig(opts))
+}
+
+// shadowKey names a shadow handler in lifecycle errors.
+func shadowKey(key string) string {
+	return key + " (shadow)"
+}
+
+// shadow starts the shadow handler for msg, if one is registered, and
+// reports it alongside the primary outcome. The shadow gets a context that
+// is not canceled when Process returns.
+func (r *Router) shadow(ctx context.Context, sourceName string, msg Message, primary ShadowResult) {
+	h, ok := r.shadows[msg.Key]
+	if !ok || !r.begin() {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer r.end()
+		result := runShadow(ctx, h, msg.Payload)
+		for _, fn := range r.hooks.onShadow {
+			fn(ctx, sourceName, msg.Key, primary, result)
+		}
+	}()
+}
+
+// runShadow calls h, recovering a panic as an error.
+func runShadow(ctx context.Context, h invoker, payload json.RawMessage) (res ShadowResult) {
+	start := time.Now()
+	defer func() {
+		res.Duration = time.Since(start)
+		if p := recover(); p != nil {
+			res = ShadowResult{Err: fmt.Errorf("shadow panic: %v", p), Duration: res.Duration}
+		}
+	}()
+	var t Timings
+	res.Result, res.Err = h(ctx, payload, &t)
+	return res
+}
@@ -0,0 +1,207 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type shadowCall struct {
+	source, key     string
+	primary, shadow ShadowResult
+}
+
+type ShadowSuite struct {
+	suite.Suite
+	mu    sync.Mutex
+	calls []shadowCall
+	done  chan struct{}
+}
+
+func TestShadowSuite(t *testing.T) {
+	suite.Run(t, new(ShadowSuite))
+}
+
+func (s *ShadowSuite) SetupTest() {
+	s.calls = nil
+	s.done = make(chan struct{}, 10)
+}
+
+func (s *ShadowSuite) router(opts ...Option) *Router {
+	opts = append(opts, WithOnShadow(func(ctx context.Context, source, key string, primary, shadow ShadowResult) {
+		s.mu.Lock()
+		s.calls = append(s.calls, shadowCall{source: source, key: key, primary: primary, shadow: shadow})
+		s.mu.Unlock()
+		s.done <- struct{}{}
+	}))
+	r := New(opts...)
+	r.AddSource(&testSource{name: "test"})
+	return r
+}
+
+// wait waits for n shadow reports and returns them.
+func (s *ShadowSuite) wait(n int) []shadowCall {
+	for range n {
+		select {
+		case <-s.done:
+		case <-time.After(time.Second):
+			s.FailNow("timed out waiting for shadow")
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func (s *ShadowSuite) process(r *Router, payload string) error {
+	return r.Process(context.Background(), []byte(`{"type": "test", "payload": `+payload+`}`))
+}
+
+func (s *ShadowSuite) TestReportsBothOutcomes() {
+	r := s.router()
+	RegisterFuncFunc(r, "test", func(ctx context.Context, p testPayload) (string, error) { return "v1:" + p.Value, nil })
+	RegisterShadowFunc(r, "test", FuncFunc[testPayload, string](func(ctx context.Context, p testPayload) (string, error) {
+		return "v2:" + p.Value, nil
+	}))
+
+	s.Require().NoError(s.process(r, `{"value": "x"}`))
+
+	calls := s.wait(1)
+	s.Require().Len(calls, 1)
+	s.Assert().Equal("test", calls[0].source)
+	s.Assert().Equal("test", calls[0].key)
+	s.Assert().JSONEq(`"v1:x"`, string(calls[0].primary.Result))
+	s.Assert().JSONEq(`"v2:x"`, string(calls[0].shadow.Result))
+	s.Assert().NoError(calls[0].primary.Err)
+	s.Assert().NoError(calls[0].shadow.Err)
+}
+
+func (s *ShadowSuite) TestShadowErrorDoesNotAffectOutcome() {
+	var failures int
+	r := s.router(WithOnFailure(func(ctx context.Context, source, key string, err error, d time.Duration) {
+		failures++
+	}))
+	primary := &testHandler{}
+	RegisterProc(r, "test", primary)
+	RegisterShadowProc(r, "test", ProcFunc[testPayload](func(ctx context.Context, p testPayload) error {
+		return errors.New("v2 broke")
+	}))
+
+	s.Require().NoError(s.process(r, `{"value": "x"}`))
+
+	calls := s.wait(1)
+	s.Assert().True(primary.called)
+	s.Assert().JSONEq(`{}`, string(calls[0].primary.Result))
+	s.Assert().EqualError(calls[0].shadow.Err, "v2 broke")
+	s.Assert().Zero(failures)
+	s.Assert().Equal(uint64(1), r.Stats().Keys["test"].Processed)
+	s.Assert().Zero(r.Stats().Keys["test"].Failed)
+}
+
+func (s *ShadowSuite) TestPrimaryErrorIsReported() {
+	r := s.router()
+	RegisterProc(r, "test", &testHandler{err: errors.New("v1 broke")})
+	RegisterShadowProc(r, "test", ProcFunc[testPayload](func(ctx context.Context, p testPayload) error { return nil }))
+
+	s.Require().EqualError(s.process(r, `{"value": "x"}`), "v1 broke")
+
+	calls := s.wait(1)
+	s.Assert().EqualError(calls[0].primary.Err, "v1 broke")
+	s.Assert().NoError(calls[0].shadow.Err)
+}
+
+func (s *ShadowSuite) TestRecoversShadowPanic() {
+	r := s.router()
+	RegisterProc(r, "test", &testHandler{})
+	RegisterShadowProc(r, "test", ProcFunc[testPayload](func(ctx context.Context, p testPayload) error {
+		panic("v2 exploded")
+	}))
+
+	s.Require().NoError(s.process(r, `{"value": "x"}`))
+
+	calls := s.wait(1)
+	s.Assert().EqualError(calls[0].shadow.Err, "shadow panic: v2 exploded")
+}
+
+func (s *ShadowSuite) TestShadowUnmarshalError() {
+	r := s.router()
+	RegisterProc(r, "test", &testHandler{})
+	RegisterShadowProc(r, "test", ProcFunc[struct{ Value int }](func(ctx context.Context, p struct{ Value int }) error {
+		return nil
+	}))
+
+	s.Require().NoError(s.process(r, `{"value": "x"}`))
+
+	calls := s.wait(1)
+	var typeErr *json.UnmarshalTypeError
+	s.Assert().ErrorAs(calls[0].shadow.Err, &typeErr)
+}
+
+func (s *ShadowSuite) TestSkipsWhenPrimaryDidNotRun() {
+	var shadowRan bool
+	r := s.router(WithOnUnmarshalError(func(ctx context.Context, source, key string, err error) error { return nil }))
+	RegisterProc(r, "test", &testHandler{})
+	RegisterShadowProc(r, "test", ProcFunc[json.RawMessage](func(ctx context.Context, p json.RawMessage) error {
+		shadowRan = true
+		return nil
+	}))
+
+	s.Require().NoError(s.process(r, `"not an object"`))
+	s.Require().NoError(r.Shutdown(context.Background()))
+
+	s.Assert().False(shadowRan)
+	s.Assert().Empty(s.calls)
+}
+
+func (s *ShadowSuite) TestShutdownWaitsForShadow() {
+	release := make(chan struct{})
+	var finished bool
+	r := s.router()
+	RegisterProc(r, "test", &testHandler{})
+	RegisterShadowProc(r, "test", ProcFunc[testPayload](func(ctx context.Context, p testPayload) error {
+		<-release
+		finished = true
+		return nil
+	}))
+
+	s.Require().NoError(s.process(r, `{"value": "x"}`))
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+	s.Require().NoError(r.Shutdown(context.Background()))
+
+	s.Assert().True(finished)
+}
+
+func (s *ShadowSuite) TestShadowContextOutlivesProcess() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := s.router()
+	RegisterProc(r, "test", &testHandler{})
+	var shadowErr error
+	RegisterShadowProc(r, "test", ProcFunc[testPayload](func(ctx context.Context, p testPayload) error {
+		shadowErr = ctx.Err()
+		return nil
+	}))
+
+	s.Require().NoError(r.Process(ctx, []byte(`{"type": "test", "payload": {"value": "x"}}`)))
+	cancel()
+	s.Require().NoError(r.Shutdown(context.Background()))
+
+	s.Assert().NoError(shadowErr)
+}
+
+func (s *ShadowSuite) TestCloneKeepsShadow() {
+	r := s.router()
+	RegisterProc(r, "test", &testHandler{})
+	RegisterShadowProc(r, "test", ProcFunc[testPayload](func(ctx context.Context, p testPayload) error { return nil }))
+
+	s.Require().NoError(s.process(r.Build().r, `{"value": "x"}`))
+
+	s.Assert().Len(s.wait(1), 1)
+}
@@ -0,0 +1,81 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrShutdown is returned by Process, ProcessBatch, and Submit after
+// Shutdown has been called.
+var ErrShutdown = errors.New("dispatch: router is shut down")
+
+// lifecycle tracks in-flight executions so Shutdown can wait for them.
+type lifecycle struct {
+	mu        sync.RWMutex
+	closed    bool
+	inflight  sync.WaitGroup
+	closeOnce sync.Once // closes handlers
+}
+
+// begin registers an in-flight execution. It returns false if the router is
+// shutting down; otherwise the caller must call end when it finishes.
+func (r *Router) begin() bool {
+	r.life.mu.RLock()
+	defer r.life.mu.RUnlock()
+	if r.life.closed {
+		return false
+	}
+	r.life.inflight.Add(1)
+	return true
+}
+
+// end marks an execution registered with begin as finished.
+func (r *Router) end() {
+	r.life.inflight.Done()
+}
+
+// Shutdown stops the router from accepting new messages and waits for
+// in-flight Process and ProcessBatch calls, and messages already accepted by
+// Submit, to finish. After Shutdown is called, Process, ProcessBatch, and
+// Submit fail with ErrShutdown. Once everything has finished, the workers
+// started with StartWorkers are stopped and registered handlers that
+// implement Closer are closed, in reverse registration order.
+//
+// If ctx is done first, Shutdown returns ctx.Err() and in-flight work keeps
+// running in the background; handlers are not closed. Calling Shutdown again
+// waits again. Handlers are closed at most once.
+//
+// Example:
+//
+//	<-sigterm
+//	ctx, cancel := context.WithTimeout(context.Background(), 25*time.Second)
+//	defer cancel()
+//	if err := r.Shutdown(ctx); err != nil {
+//	    slog.Warn("shutdown timed out", "error", err)
+//	}
+func (r *Router) Shutdown(ctx context.Context) error {
+	r.life.mu.Lock()
+	r.life.closed = true
+	r.life.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.life.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if err := r.StopWorkers(ctx); err != nil {
+		return err
+	}
+
+	var err error
+	r.life.closeOnce.Do(func() {
+		err = closeHandlers(ctx, r.lifecycleHandlers())
+	})
+	return err
+}
@@ -0,0 +1,103 @@
+package dispatch
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ShutdownSuite struct {
+	suite.Suite
+	router  *Router
+	release chan struct{}
+	started chan struct{}
+	done    *atomic.Int32
+}
+
+func TestShutdownSuite(t *testing.T) {
+	suite.Run(t, new(ShutdownSuite))
+}
+
+func (s *ShutdownSuite) SetupTest() {
+	s.router = New()
+	s.router.AddSource(&testSource{name: "test"})
+	// The handler captures locals so goroutines left running by one test
+	// don't race with the next test's setup.
+	release, started, done := make(chan struct{}), make(chan struct{}, 10), new(atomic.Int32)
+	s.release, s.started, s.done = release, started, done
+	RegisterProcFunc(s.router, "test", func(ctx context.Context, p struct{}) error {
+		started <- struct{}{}
+		<-release
+		done.Add(1)
+		return nil
+	})
+}
+
+var shutdownMessage = []byte(`{"type": "test", "payload": {}}`)
+
+func (s *ShutdownSuite) TestWaitsForInFlightProcess() {
+	errc := make(chan error, 1)
+	go func() { errc <- s.router.Process(context.Background(), shutdownMessage) }()
+	<-s.started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- s.router.Shutdown(context.Background()) }()
+
+	s.Require().Eventually(func() bool {
+		if s.router.begin() {
+			s.router.end()
+			return false
+		}
+		return true
+	}, time.Second, time.Millisecond)
+	s.Assert().ErrorIs(s.router.Process(context.Background(), shutdownMessage), ErrShutdown)
+	select {
+	case <-shutdown:
+		s.Fail("Shutdown returned before in-flight message finished")
+	default:
+	}
+
+	close(s.release)
+	s.Require().NoError(<-shutdown)
+	s.Require().NoError(<-errc)
+	s.Assert().Equal(int32(1), s.done.Load())
+}
+
+func (s *ShutdownSuite) TestRejectsNewMessages() {
+	s.Require().NoError(s.router.Shutdown(context.Background()))
+
+	s.Assert().ErrorIs(s.router.Process(context.Background(), shutdownMessage), ErrShutdown)
+	s.Assert().Equal([]error{ErrShutdown, ErrShutdown}, s.router.ProcessBatch(context.Background(), [][]byte{shutdownMessage, shutdownMessage}))
+	s.Assert().ErrorIs(<-s.router.Submit(context.Background(), shutdownMessage), ErrShutdown)
+}
+
+func (s *ShutdownSuite) TestDrainsSubmittedMessages() {
+	s.router.StartWorkers(1)
+	first := s.router.Submit(context.Background(), shutdownMessage)
+	second := s.router.Submit(context.Background(), shutdownMessage)
+	<-s.started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- s.router.Shutdown(context.Background()) }()
+	close(s.release)
+
+	s.Require().NoError(<-shutdown)
+	s.Assert().NoError(<-first)
+	s.Assert().NoError(<-second)
+	s.Assert().Equal(int32(2), s.done.Load())
+	s.Assert().Nil(s.router.workers)
+}
+
+func (s *ShutdownSuite) TestHonorsDeadline() {
+	defer close(s.release)
+	go s.router.Process(context.Background(), shutdownMessage)
+	<-s.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	s.Assert().ErrorIs(s.router.Shutdown(ctx), context.DeadlineExceeded)
+}
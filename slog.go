@@ -0,0 +1,109 @@
+package dispatch
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// WithSlog adds a default set of logging hooks using logger:
+//   - Debug when a message is dispatched to its handler
+//   - Info when a handler succeeds
+//   - Error when a handler fails
+//   - Error when a message is skipped by a policy hook such as WithOnNoHandler
+//     or WithOnError
+//   - Warn when a message is dropped by WithMaxMessageAge
+//   - Info when a message is skipped because WithEnabled turned its handler off
+//   - Info when WithDuplicateSuppression skips a duplicate message
+//   - Warn when workers pause for a Backpressure error, and Info when they
+//     resume
+//
+// Records carry source, key, duration, and error attributes where relevant,
+// plus message_id and correlation_id when the message has them.
+// Skip logging only observes decisions made by other hooks; it does not
+// change whether a message is skipped or failed.
+//
+// Example:
+//
+//	r := dispatch.New(dispatch.WithSlog(slog.Default()))
+func WithSlog(logger *slog.Logger) Option {
+	return func(r *Router) {
+		r.hooks.onDispatch = append(r.hooks.onDispatch, func(ctx context.Context, source, key string) {
+			messageLogger(ctx, logger).DebugContext(ctx, "dispatching message",
+				slog.String("source", source),
+				slog.String("key", key),
+			)
+		})
+		r.hooks.onSuccess = append(r.hooks.onSuccess, func(ctx context.Context, source, key string, d time.Duration) {
+			messageLogger(ctx, logger).InfoContext(ctx, "message handled",
+				slog.String("source", source),
+				slog.String("key", key),
+				slog.Duration("duration", d),
+			)
+		})
+		r.hooks.onFailure = append(r.hooks.onFailure, func(ctx context.Context, source, key string, err error, d time.Duration) {
+			messageLogger(ctx, logger).ErrorContext(ctx, "message failed",
+				slog.String("source", source),
+				slog.String("key", key),
+				slog.Duration("duration", d),
+				slog.Any("error", err),
+			)
+		})
+		r.hooks.onExpired = append(r.hooks.onExpired, func(ctx context.Context, source, key string, age time.Duration) {
+			messageLogger(ctx, logger).WarnContext(ctx, "message expired",
+				slog.String("source", source),
+				slog.String("key", key),
+				slog.Duration("age", age),
+			)
+		})
+		r.hooks.onDuplicate = append(r.hooks.onDuplicate, func(ctx context.Context, source, key string) {
+			messageLogger(ctx, logger).InfoContext(ctx, "duplicate message",
+				slog.String("source", source),
+				slog.String("key", key),
+			)
+		})
+		r.hooks.onPause = append(r.hooks.onPause, func(ctx context.Context, source, key string, d time.Duration) {
+			logger.WarnContext(ctx, "workers paused",
+				slog.String("source", source),
+				slog.String("key", key),
+				slog.Duration("delay", d),
+			)
+		})
+		r.hooks.onResume = append(r.hooks.onResume, func(ctx context.Context) {
+			logger.InfoContext(ctx, "workers resumed")
+		})
+		r.hooks.onDisabled = append(r.hooks.onDisabled, func(ctx context.Context, source, key string) {
+			messageLogger(ctx, logger).InfoContext(ctx, "handler disabled",
+				slog.String("source", source),
+				slog.String("key", key),
+			)
+		})
+		r.hooks.onSkip = append(r.hooks.onSkip, func(ctx context.Context, source, key string, cause error) {
+			messageLogger(ctx, logger).ErrorContext(ctx, "message skipped",
+				slog.String("source", source),
+				slog.String("key", key),
+				slog.Any("error", cause),
+			)
+		})
+	}
+}
+
+// messageLogger returns logger with the message and correlation IDs of the
+// message in ctx, if set.
+func messageLogger(ctx context.Context, logger *slog.Logger) *slog.Logger {
+	msg, ok := MessageFromContext(ctx)
+	if !ok {
+		return logger
+	}
+	var attrs []any
+	if msg.MessageID != "" {
+		attrs = append(attrs, slog.String("message_id", msg.MessageID))
+	}
+	if msg.CorrelationID != "" {
+		attrs = append(attrs, slog.String("correlation_id", msg.CorrelationID))
+	}
+	if len(attrs) == 0 {
+		return logger
+	}
+	return logger.With(attrs...)
+}
@@ -0,0 +1,115 @@
+package dispatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SlogSuite struct {
+	suite.Suite
+	buf    *bytes.Buffer
+	logger *slog.Logger
+}
+
+func (s *SlogSuite) SetupTest() {
+	s.buf = &bytes.Buffer{}
+	s.logger = slog.New(slog.NewJSONHandler(s.buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func TestSlogSuite(t *testing.T) {
+	suite.Run(t, new(SlogSuite))
+}
+
+// records decodes the JSON log lines written so far.
+func (s *SlogSuite) records() []map[string]any {
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(s.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		s.Require().NoError(json.Unmarshal([]byte(line), &rec))
+		out = append(out, rec)
+	}
+	return out
+}
+
+func (s *SlogSuite) TestLogsDispatchAndSuccess() {
+	r := New(WithSlog(s.logger))
+	r.AddSource(&testSource{name: "test"})
+	RegisterProc(r, "test/event", &testHandler{})
+
+	err := r.Process(context.Background(), []byte(`{"type": "test/event", "payload": {}}`))
+
+	s.Require().NoError(err)
+	recs := s.records()
+	s.Require().Len(recs, 2)
+	s.Assert().Equal("DEBUG", recs[0]["level"])
+	s.Assert().Equal("dispatching message", recs[0]["msg"])
+	s.Assert().Equal("INFO", recs[1]["level"])
+	s.Assert().Equal("message handled", recs[1]["msg"])
+	s.Assert().Equal("test", recs[1]["source"])
+	s.Assert().Equal("test/event", recs[1]["key"])
+	s.Assert().Contains(recs[1], "duration")
+}
+
+func (s *SlogSuite) TestLogsFailure() {
+	r := New(WithSlog(s.logger))
+	r.AddSource(&testSource{name: "test"})
+	RegisterProc(r, "test/event", &testHandler{err: errors.New("boom")})
+
+	_ = r.Process(context.Background(), []byte(`{"type": "test/event", "payload": {}}`))
+
+	recs := s.records()
+	s.Require().Len(recs, 2)
+	s.Assert().Equal("ERROR", recs[1]["level"])
+	s.Assert().Equal("message failed", recs[1]["msg"])
+	s.Assert().Equal("boom", recs[1]["error"])
+}
+
+func (s *SlogSuite) TestLogsSkipWithoutChangingPolicy() {
+	r := New(WithSlog(s.logger))
+	r.AddSource(&testSource{name: "test"})
+
+	err := r.Process(context.Background(), []byte(`{"type": "unknown", "payload": {}}`))
+
+	s.Require().Error(err)
+	s.Assert().Empty(s.records())
+
+	r = New(WithSlog(s.logger), WithOnNoHandler(func(ctx context.Context, source, key string) error {
+		return nil
+	}))
+	r.AddSource(&testSource{name: "test"})
+
+	err = r.Process(context.Background(), []byte(`{"type": "unknown", "payload": {}}`))
+
+	s.Require().NoError(err)
+	recs := s.records()
+	s.Require().Len(recs, 1)
+	s.Assert().Equal("ERROR", recs[0]["level"])
+	s.Assert().Equal("message skipped", recs[0]["msg"])
+	s.Assert().Equal("unknown", recs[0]["key"])
+	s.Assert().Equal("no handler for key: unknown", recs[0]["error"])
+}
+
+func (s *SlogSuite) TestIncludesMessageIDs() {
+	r := New(WithSlog(s.logger))
+	r.AddSource(SourceFunc("test", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", MessageID: "m-1", CorrelationID: "c-1", Payload: []byte(`{}`)}, nil
+	}))
+	RegisterProc(r, "test", &testHandler{})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test"}`)))
+
+	recs := s.records()
+	s.Require().Len(recs, 2)
+	s.Assert().Equal("m-1", recs[1]["message_id"])
+	s.Assert().Equal("c-1", recs[1]["correlation_id"])
+}
@@ -0,0 +1,79 @@
+package sns
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awssns "github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/bjaus/dispatch"
+)
+
+// Message attribute names set on dead-lettered and published messages.
+// Attributes whose value is unknown, such as the key of a message that failed
+// to parse, are omitted.
+const (
+	StageAttribute     = "DispatchStage"
+	SourceAttribute    = "DispatchSource"
+	KeyAttribute       = "DispatchKey"
+	ErrorAttribute     = "DispatchError"
+	MessageIDAttribute = "DispatchMessageId"
+)
+
+// DeadLetterer publishes skipped messages to an SNS topic, so several
+// subscribers (a DLQ, an alerting pipeline) can receive them. The message is
+// the raw message, unchanged; failure details are sent as message attributes.
+type DeadLetterer struct {
+	client   API
+	topicARN string
+	cfg      config
+}
+
+// NewDeadLetterer returns a DeadLetterer that publishes to topicARN.
+// Attributes added with WithAttributes and WithAttributeFunc are sent with
+// every message; WithAttributeFunc receives the parsed message, if any.
+//
+//	r := dispatch.New(dispatch.WithDeadLetterer(sns.NewDeadLetterer(client, topicARN)))
+func NewDeadLetterer(client API, topicARN string, opts ...Option) *DeadLetterer {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &DeadLetterer{client: client, topicARN: topicARN, cfg: cfg}
+}
+
+// DeadLetter implements dispatch.DeadLetterer.
+func (d *DeadLetterer) DeadLetter(ctx context.Context, dl dispatch.DeadLetter) error {
+	attrs := make(map[string]types.MessageAttributeValue, len(d.cfg.attributes)+6)
+	for k, v := range d.cfg.attributes {
+		attrs[k] = stringAttribute(v)
+	}
+	if d.cfg.attrFunc != nil {
+		msg, _ := dispatch.MessageFromContext(ctx)
+		for k, v := range d.cfg.attrFunc(ctx, msg) {
+			attrs[k] = stringAttribute(v)
+		}
+	}
+	set := func(name, value string) {
+		if value != "" {
+			attrs[name] = stringAttribute(value)
+		}
+	}
+	set(StageAttribute, dl.Stage.String())
+	set(SourceAttribute, dl.Source)
+	set(KeyAttribute, dl.Key)
+	set(MessageIDAttribute, dl.MessageID)
+	set(CorrelationIDAttribute, dl.CorrelationID)
+	if dl.Err != nil {
+		set(ErrorAttribute, dl.Err.Error())
+	}
+
+	_, err := d.client.Publish(ctx, &awssns.PublishInput{
+		TopicArn:          aws.String(d.topicARN),
+		Message:           aws.String(string(dl.Raw)),
+		MessageAttributes: attrs,
+	})
+	return err
+}
+
+var _ dispatch.DeadLetterer = (*DeadLetterer)(nil)
@@ -0,0 +1,67 @@
+package sns
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+)
+
+type DeadLettererSuite struct {
+	suite.Suite
+	api *fakeAPI
+	r   *dispatch.Router
+}
+
+func (s *DeadLettererSuite) SetupTest() {
+	s.api = &fakeAPI{}
+	dl := NewDeadLetterer(s.api, "arn:aws:sns:us-east-1:123:dead-letters",
+		WithAttributes(map[string]string{"Service": "billing"}),
+		WithAttributeFunc(func(ctx context.Context, msg dispatch.Message) map[string]string {
+			return map[string]string{"Tenant": msg.Attributes["tenant"]}
+		}),
+	)
+	s.r = dispatch.New(
+		dispatch.WithDeadLetterer(dl),
+		dispatch.WithOnNoHandler(func(ctx context.Context, source, key string) error { return nil }),
+	)
+	s.r.AddSource(dispatch.SourceFunc("test", dispatch.HasFields("type"), func(raw []byte) (dispatch.Message, error) {
+		return dispatch.Message{
+			Key:        "unknown",
+			MessageID:  "m-1",
+			Attributes: map[string]string{"tenant": "acme"},
+			Payload:    []byte(`{}`),
+		}, nil
+	}))
+}
+
+func TestDeadLettererSuite(t *testing.T) {
+	suite.Run(t, new(DeadLettererSuite))
+}
+
+func (s *DeadLettererSuite) TestPublishesRawMessageWithDetails() {
+	raw := `{"type":"unknown"}`
+	s.Require().NoError(s.r.Process(context.Background(), []byte(raw)))
+
+	s.Require().Len(s.api.inputs, 1)
+	in := s.api.inputs[0]
+	attr := func(name string) string { return aws.ToString(in.MessageAttributes[name].StringValue) }
+	s.Assert().Equal("arn:aws:sns:us-east-1:123:dead-letters", aws.ToString(in.TopicArn))
+	s.Assert().Equal(raw, aws.ToString(in.Message))
+	s.Assert().Equal("route", attr(StageAttribute))
+	s.Assert().Equal("test", attr(SourceAttribute))
+	s.Assert().Equal("unknown", attr(KeyAttribute))
+	s.Assert().Equal("no handler for key: unknown", attr(ErrorAttribute))
+	s.Assert().Equal("m-1", attr(MessageIDAttribute))
+	s.Assert().Equal("billing", attr("Service"))
+	s.Assert().Equal("acme", attr("Tenant"))
+}
+
+func (s *DeadLettererSuite) TestPublishErrorFailsMessage() {
+	s.api.err = errors.New("throttled")
+
+	s.Assert().EqualError(s.r.Process(context.Background(), []byte(`{"type":"unknown"}`)), "dead letter: throttled")
+}
@@ -0,0 +1,75 @@
+package sns
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awssns "github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/bjaus/dispatch"
+)
+
+// ReplyToAttribute holds the reply address of requests sent with
+// dispatch.Call.
+const ReplyToAttribute = "DispatchReplyTo"
+
+// Publisher is a dispatch.Transport that publishes events to an SNS topic.
+// The message is the event payload; the key, message ID, correlation ID, and
+// reply address are sent as the KeyAttribute, MessageIDAttribute,
+// CorrelationIDAttribute, and ReplyToAttribute message attributes, with the
+// event's own attributes, so subscription filter policies can select events
+// by key.
+type Publisher struct {
+	client   API
+	topicARN string
+	cfg      config
+}
+
+// NewPublisher returns a Publisher that publishes to topicARN. Attributes
+// added with WithAttributes and WithAttributeFunc are sent with every event;
+// WithAttributeFunc receives the message being handled, if Publish is called
+// from a handler.
+//
+//	pub := dispatch.NewPublisher(events, sns.NewPublisher(client, topicARN))
+func NewPublisher(client API, topicARN string, opts ...Option) *Publisher {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Publisher{client: client, topicARN: topicARN, cfg: cfg}
+}
+
+// Send implements dispatch.Transport.
+func (p *Publisher) Send(ctx context.Context, e dispatch.Event) error {
+	attrs := make(map[string]types.MessageAttributeValue, len(p.cfg.attributes)+len(e.Attributes)+4)
+	for k, v := range p.cfg.attributes {
+		attrs[k] = stringAttribute(v)
+	}
+	if p.cfg.attrFunc != nil {
+		msg, _ := dispatch.MessageFromContext(ctx)
+		for k, v := range p.cfg.attrFunc(ctx, msg) {
+			attrs[k] = stringAttribute(v)
+		}
+	}
+	for k, v := range e.Attributes {
+		attrs[k] = stringAttribute(v)
+	}
+	set := func(name, value string) {
+		if value != "" {
+			attrs[name] = stringAttribute(value)
+		}
+	}
+	set(KeyAttribute, e.Key)
+	set(MessageIDAttribute, e.MessageID)
+	set(CorrelationIDAttribute, e.CorrelationID)
+	set(ReplyToAttribute, e.ReplyTo)
+
+	_, err := p.client.Publish(ctx, &awssns.PublishInput{
+		TopicArn:          aws.String(p.topicARN),
+		Message:           aws.String(string(e.Payload)),
+		MessageAttributes: attrs,
+	})
+	return err
+}
+
+var _ dispatch.Transport = (*Publisher)(nil)
@@ -0,0 +1,82 @@
+package sns
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+)
+
+type userCreated struct {
+	ID string `json:"id"`
+}
+
+type PublisherSuite struct {
+	suite.Suite
+	api *fakeAPI
+	reg *dispatch.Registry
+}
+
+func (s *PublisherSuite) SetupTest() {
+	s.api = &fakeAPI{}
+	s.reg = dispatch.NewRegistry()
+	dispatch.Register[userCreated](s.reg, "user/created")
+}
+
+func TestPublisherSuite(t *testing.T) {
+	suite.Run(t, new(PublisherSuite))
+}
+
+func (s *PublisherSuite) publisher(opts ...Option) *dispatch.Publisher {
+	return dispatch.NewPublisher(s.reg, NewPublisher(s.api, "arn:aws:sns:us-east-1:123:users", opts...))
+}
+
+func (s *PublisherSuite) TestPublishesPayloadWithKeyAttributes() {
+	pub := s.publisher(WithAttributes(map[string]string{"Service": "users"}))
+
+	err := dispatch.Publish(context.Background(), pub, userCreated{ID: "42"}, dispatch.WithEventID("evt-1"))
+
+	s.Require().NoError(err)
+	s.Require().Len(s.api.inputs, 1)
+	in := s.api.inputs[0]
+	attr := func(name string) string { return aws.ToString(in.MessageAttributes[name].StringValue) }
+	s.Assert().Equal("arn:aws:sns:us-east-1:123:users", aws.ToString(in.TopicArn))
+	s.Assert().JSONEq(`{"id": "42"}`, aws.ToString(in.Message))
+	s.Assert().Equal("user/created", attr(KeyAttribute))
+	s.Assert().Equal("evt-1", attr(MessageIDAttribute))
+	s.Assert().Equal("users", attr("Service"))
+}
+
+func (s *PublisherSuite) TestCopiesAttributesFromHandledMessage() {
+	pub := s.publisher(WithAttributeFunc(func(ctx context.Context, msg dispatch.Message) map[string]string {
+		return map[string]string{"Tenant": msg.Attributes["tenant"]}
+	}))
+	r := dispatch.New()
+	r.AddSource(dispatch.SourceFunc("test", dispatch.HasFields("type"), func(raw []byte) (dispatch.Message, error) {
+		return dispatch.Message{
+			Key:           "in",
+			CorrelationID: "c-1",
+			Attributes:    map[string]string{"tenant": "acme"},
+			Payload:       []byte(`{}`),
+		}, nil
+	}))
+	dispatch.RegisterProcFunc(r, "in", func(ctx context.Context, p struct{}) error {
+		return dispatch.Publish(ctx, pub, userCreated{ID: "42"})
+	})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "in"}`)))
+
+	s.Require().Len(s.api.inputs, 1)
+	in := s.api.inputs[0]
+	s.Assert().Equal("acme", aws.ToString(in.MessageAttributes["Tenant"].StringValue))
+	s.Assert().Equal("c-1", aws.ToString(in.MessageAttributes[CorrelationIDAttribute].StringValue))
+}
+
+func (s *PublisherSuite) TestReturnsPublishErrors() {
+	s.api.err = errors.New("throttled")
+
+	s.Assert().ErrorIs(dispatch.Publish(context.Background(), s.publisher(), userCreated{}), s.api.err)
+}
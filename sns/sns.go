@@ -0,0 +1,138 @@
+// Package sns provides a dispatch.Replier that publishes results to an SNS
+// topic, for fanning out computed results to several subscribers, and a
+// dispatch.DeadLetterer that publishes skipped messages.
+//
+//	replier := sns.NewReplier(client, topicARN)
+//
+// Use Factory instead to publish to the topic ARN in each message's ReplyTo:
+//
+//	r := dispatch.New(dispatch.WithReplierFactory(sns.Factory(client)))
+package sns
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awssns "github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/bjaus/dispatch"
+)
+
+// Message attribute names and values set on every published reply.
+const (
+	// CorrelationIDAttribute holds the correlation ID of the request.
+	CorrelationIDAttribute = "CorrelationId"
+
+	// StatusAttribute is StatusOK for results and StatusError for failures.
+	StatusAttribute = "Status"
+
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// API is the subset of the SNS client used by Replier. *sns.Client
+// satisfies it.
+type API interface {
+	Publish(ctx context.Context, in *awssns.PublishInput, optFns ...func(*awssns.Options)) (*awssns.PublishOutput, error)
+}
+
+// Option configures a Replier or DeadLetterer.
+type Option func(*config)
+
+type config struct {
+	attributes map[string]string
+	attrFunc   func(ctx context.Context, msg dispatch.Message) map[string]string
+}
+
+// WithAttributes adds static string message attributes to every reply.
+func WithAttributes(attrs map[string]string) Option {
+	return func(c *config) {
+		if c.attributes == nil {
+			c.attributes = make(map[string]string, len(attrs))
+		}
+		maps.Copy(c.attributes, attrs)
+	}
+}
+
+// WithAttributeFunc adds message attributes computed from the message being
+// replied to, for example to copy routing attributes used by subscription
+// filter policies. They override static attributes with the same name.
+func WithAttributeFunc(fn func(ctx context.Context, msg dispatch.Message) map[string]string) Option {
+	return func(c *config) {
+		c.attrFunc = fn
+	}
+}
+
+// Replier publishes results to an SNS topic. Reply publishes the result JSON
+// as the message; Fail publishes {"error": "..."} with StatusAttribute set to
+// StatusError.
+type Replier struct {
+	client   API
+	topicARN string
+	cfg      config
+}
+
+// NewReplier returns a Replier that publishes to topicARN.
+func NewReplier(client API, topicARN string, opts ...Option) *Replier {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Replier{client: client, topicARN: topicARN, cfg: cfg}
+}
+
+// Factory returns a dispatch.ReplierFactory that publishes replies to each
+// message's ReplyTo topic ARN.
+func Factory(client API, opts ...Option) dispatch.ReplierFactory {
+	return dispatch.ReplierFactoryFunc(func(ctx context.Context, msg dispatch.Message) (dispatch.Replier, error) {
+		return NewReplier(client, msg.ReplyTo, opts...), nil
+	})
+}
+
+// Reply implements dispatch.Replier.
+func (r *Replier) Reply(ctx context.Context, result json.RawMessage) error {
+	return r.publish(ctx, string(result), StatusOK)
+}
+
+// Fail implements dispatch.Replier.
+func (r *Replier) Fail(ctx context.Context, err error) error {
+	body, merr := json.Marshal(struct {
+		Error string `json:"error"`
+	}{Error: err.Error()})
+	if merr != nil {
+		return merr
+	}
+	return r.publish(ctx, string(body), StatusError)
+}
+
+func (r *Replier) publish(ctx context.Context, body, status string) error {
+	attrs := make(map[string]types.MessageAttributeValue, len(r.cfg.attributes)+2)
+	for k, v := range r.cfg.attributes {
+		attrs[k] = stringAttribute(v)
+	}
+	if r.cfg.attrFunc != nil {
+		msg, _ := dispatch.MessageFromContext(ctx)
+		for k, v := range r.cfg.attrFunc(ctx, msg) {
+			attrs[k] = stringAttribute(v)
+		}
+	}
+	attrs[StatusAttribute] = stringAttribute(status)
+	if id := dispatch.CorrelationID(ctx); id != "" {
+		attrs[CorrelationIDAttribute] = stringAttribute(id)
+	}
+
+	_, err := r.client.Publish(ctx, &awssns.PublishInput{
+		TopicArn:          aws.String(r.topicARN),
+		Message:           aws.String(body),
+		MessageAttributes: attrs,
+	})
+	return err
+}
+
+func stringAttribute(v string) types.MessageAttributeValue {
+	return types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(v)}
+}
+
+var _ dispatch.Replier = (*Replier)(nil)
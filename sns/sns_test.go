@@ -0,0 +1,94 @@
+package sns
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awssns "github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeAPI struct {
+	inputs []*awssns.PublishInput
+	err    error
+}
+
+func (f *fakeAPI) Publish(ctx context.Context, in *awssns.PublishInput, _ ...func(*awssns.Options)) (*awssns.PublishOutput, error) {
+	f.inputs = append(f.inputs, in)
+	return &awssns.PublishOutput{}, f.err
+}
+
+type ReplierSuite struct {
+	suite.Suite
+	api *fakeAPI
+}
+
+func (s *ReplierSuite) SetupTest() {
+	s.api = &fakeAPI{}
+}
+
+func TestReplierSuite(t *testing.T) {
+	suite.Run(t, new(ReplierSuite))
+}
+
+// router returns a router whose messages reply through replier.
+func (s *ReplierSuite) router(opts ...Option) *dispatch.Router {
+	r := dispatch.New()
+	r.AddSource(dispatch.SourceFunc("test", dispatch.HasFields("type"), func(raw []byte) (dispatch.Message, error) {
+		return dispatch.Message{
+			Key:           "compute",
+			CorrelationID: "c-1",
+			Attributes:    map[string]string{"tenant": "acme"},
+			Payload:       []byte(`{}`),
+			Replier:       NewReplier(s.api, "arn:aws:sns:us-east-1:123:results", opts...),
+		}, nil
+	}))
+	return r
+}
+
+func (s *ReplierSuite) TestReplyPublishesResult() {
+	r := s.router(
+		WithAttributes(map[string]string{"Service": "pricing"}),
+		WithAttributeFunc(func(ctx context.Context, msg dispatch.Message) map[string]string {
+			return map[string]string{"Tenant": msg.Attributes["tenant"]}
+		}),
+	)
+	dispatch.RegisterFuncFunc(r, "compute", func(ctx context.Context, p struct{}) (int, error) {
+		return 42, nil
+	})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "x"}`)))
+
+	s.Require().Len(s.api.inputs, 1)
+	in := s.api.inputs[0]
+	s.Assert().Equal("arn:aws:sns:us-east-1:123:results", aws.ToString(in.TopicArn))
+	s.Assert().Equal("42", aws.ToString(in.Message))
+	s.Assert().Equal("c-1", aws.ToString(in.MessageAttributes[CorrelationIDAttribute].StringValue))
+	s.Assert().Equal(StatusOK, aws.ToString(in.MessageAttributes[StatusAttribute].StringValue))
+	s.Assert().Equal("pricing", aws.ToString(in.MessageAttributes["Service"].StringValue))
+	s.Assert().Equal("acme", aws.ToString(in.MessageAttributes["Tenant"].StringValue))
+}
+
+func (s *ReplierSuite) TestFailPublishesError() {
+	r := s.router()
+	dispatch.RegisterProcFunc(r, "compute", func(ctx context.Context, p struct{}) error {
+		return errors.New("boom")
+	})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "x"}`)))
+
+	s.Require().Len(s.api.inputs, 1)
+	s.Assert().JSONEq(`{"error": "boom"}`, aws.ToString(s.api.inputs[0].Message))
+	s.Assert().Equal(StatusError, aws.ToString(s.api.inputs[0].MessageAttributes[StatusAttribute].StringValue))
+}
+
+func (s *ReplierSuite) TestFactoryUsesReplyTo() {
+	replier, err := Factory(s.api).NewReplier(context.Background(), dispatch.Message{ReplyTo: "arn:topic"})
+	s.Require().NoError(err)
+
+	s.Require().NoError(replier.Reply(context.Background(), []byte(`{}`)))
+	s.Assert().Equal("arn:topic", aws.ToString(s.api.inputs[0].TopicArn))
+}
@@ -0,0 +1,211 @@
+package dispatch
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SourceControlSuite struct {
+	suite.Suite
+	router  *Router
+	matched []string
+}
+
+func TestSourceControlSuite(t *testing.T) {
+	suite.Run(t, new(SourceControlSuite))
+}
+
+func (s *SourceControlSuite) SetupTest() {
+	s.matched = nil
+	s.router = New(WithOnParse(func(ctx context.Context, source, key string) context.Context {
+		s.matched = append(s.matched, source)
+		return ctx
+	}))
+	s.router.AddSource(&testSource{name: "first"})
+	s.router.AddSource(&testSource{name: "second"})
+	RegisterProc(s.router, "test", &testHandler{})
+}
+
+func (s *SourceControlSuite) process() error {
+	return s.router.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`))
+}
+
+func (s *SourceControlSuite) TestRegistrationOrderByDefault() {
+	s.Require().NoError(s.process())
+
+	s.Assert().Equal([]string{"first"}, s.matched)
+}
+
+func (s *SourceControlSuite) TestDisabledSourceIsSkipped() {
+	s.Require().NoError(s.process())
+	s.router.EnableSource("first", false)
+	s.Require().NoError(s.process())
+
+	s.Assert().Equal([]string{"first", "second"}, s.matched)
+}
+
+func (s *SourceControlSuite) TestReenabledSourceMatchesAgain() {
+	s.router.EnableSource("first", false)
+	s.router.EnableSource("first", true)
+	s.Require().NoError(s.process())
+
+	s.Assert().Equal([]string{"first"}, s.matched)
+}
+
+func (s *SourceControlSuite) TestAllSourcesDisabled() {
+	s.router.EnableSource("first", false)
+	s.router.EnableSource("second", false)
+
+	s.Assert().ErrorIs(s.process(), ErrNoSource)
+	s.Assert().Empty(s.matched)
+}
+
+func (s *SourceControlSuite) TestAppliesToSourcesAddedLater() {
+	s.router.EnableSource("third", false)
+	s.router.EnableSource("first", false)
+	s.router.EnableSource("second", false)
+	s.router.AddSource(&testSource{name: "third"})
+
+	s.Assert().ErrorIs(s.process(), ErrNoSource)
+}
+
+func (s *SourceControlSuite) TestPriorityOverridesRegistrationOrder() {
+	s.router.SetSourcePriority("second", 10)
+	s.Require().NoError(s.process())
+
+	s.Assert().Equal([]string{"second"}, s.matched)
+}
+
+func (s *SourceControlSuite) TestResettingPriorityRestoresOrder() {
+	s.router.SetSourcePriority("second", 10)
+	s.router.SetSourcePriority("second", 0)
+	s.Require().NoError(s.process())
+
+	s.Assert().Equal([]string{"first"}, s.matched)
+}
+
+func (s *SourceControlSuite) TestNegativePriorityGoesLast() {
+	s.router.AddSource(&testSource{name: "third"})
+	s.router.SetSourcePriority("first", -1)
+	s.router.EnableSource("second", false)
+	s.Require().NoError(s.process())
+
+	s.Assert().Equal([]string{"third"}, s.matched)
+}
+
+func (s *SourceControlSuite) TestPriorityAppliesToHotSources() {
+	for range reorderInterval {
+		s.Require().NoError(s.process())
+	}
+	s.router.SetSourcePriority("second", 1)
+	s.matched = nil
+	for range reorderInterval + 1 {
+		s.Require().NoError(s.process())
+	}
+
+	s.Assert().NotContains(s.matched, "first")
+}
+
+func (s *SourceControlSuite) TestPriorityBeatsHotSources() {
+	parse := func(raw []byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(`{}`)}, nil
+	}
+	r := New(WithOnParse(func(ctx context.Context, source, key string) context.Context {
+		s.matched = append(s.matched, source)
+		return ctx
+	}))
+	r.AddSource(SourceFunc("generic", HasFields("type"), parse))
+	r.AddSource(SourceFunc("orders", And(HasFields("type"), FieldEquals("kind", "order")), parse))
+	RegisterProc(r, "test", &testHandler{})
+	r.SetSourcePriority("orders", 10)
+
+	ctx := context.Background()
+	for range 300 {
+		s.Require().NoError(r.Process(ctx, []byte(`{"type": "test", "kind": "refund"}`)))
+	}
+	s.matched = nil
+	s.Require().NoError(r.Process(ctx, []byte(`{"type": "test", "kind": "order"}`)))
+
+	s.Assert().Equal([]string{"orders"}, s.matched)
+}
+
+func (s *SourceControlSuite) TestPriorityIsPerGroup() {
+	r := New(WithOnParse(func(ctx context.Context, source, key string) context.Context {
+		s.matched = append(s.matched, source)
+		return ctx
+	}))
+	r.AddSource(&testSource{name: "default"})
+	r.AddGroup(JSONInspector(), &testSource{name: "grouped"})
+	RegisterProc(r, "test", &testHandler{})
+	r.SetSourcePriority("grouped", 100)
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`)))
+	s.Assert().Equal([]string{"default"}, s.matched)
+}
+
+func (s *SourceControlSuite) TestCompiledRouter() {
+	s.router.EnableSource("first", false)
+	cr := s.router.Build()
+	s.router.EnableSource("first", true)
+	s.Require().NoError(cr.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`)))
+	s.Require().NoError(s.process())
+
+	s.Assert().Equal([]string{"second", "first"}, s.matched, "the compiled router keeps the settings from Build")
+}
+
+func (s *SourceControlSuite) TestCloneCopiesSettings() {
+	s.router.SetSourcePriority("second", 1)
+	c := s.router.Clone()
+	s.router.SetSourcePriority("second", 0)
+
+	s.Require().NoError(c.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`)))
+	s.Assert().Equal([]string{"second"}, s.matched)
+}
+
+func (s *SourceControlSuite) TestCheckSourcesIgnoresDisabled() {
+	samples := map[string][]byte{"test": []byte(`{"type": "test", "payload": {}}`)}
+	s.Require().Error(CheckSources(s.router, samples))
+
+	s.router.EnableSource("first", false)
+	s.Assert().NoError(CheckSources(s.router, samples))
+}
+
+func (s *SourceControlSuite) TestRoutingTable() {
+	s.router.SetSourcePriority("second", 5)
+	s.router.EnableSource("first", false)
+
+	sources := s.router.RoutingTable().Sources
+	s.Require().Len(sources, 2)
+	s.Assert().Equal("second", sources[0].Name)
+	s.Assert().Equal(5, sources[0].Priority)
+	s.Assert().False(sources[0].Disabled)
+	s.Assert().Equal("first", sources[1].Name)
+	s.Assert().True(sources[1].Disabled)
+}
+
+func (s *SourceControlSuite) TestConcurrentUpdates() {
+	r := New()
+	r.AddSource(&testSource{name: "first"})
+	r.AddSource(&testSource{name: "second"})
+	RegisterProcFunc(r, "test", func(ctx context.Context, p testPayload) error { return nil })
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			r.EnableSource("first", i%2 == 0)
+		}()
+		go func() {
+			defer wg.Done()
+			_ = r.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`))
+		}()
+	}
+	wg.Wait()
+	r.EnableSource("first", false)
+
+	s.Assert().Equal([]string{"second"}, r.matchingSources([]byte(`{"type": "test", "payload": {}}`)))
+}
@@ -0,0 +1,284 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// SourceHooks holds source-level hooks to attach to a Source with
+// WithSourceHooks. Nil fields are skipped. Each field has the same signature
+// as the method of the matching optional hook interface, such as OnParseHook.
+// Error hooks follow the same rules as global ones: they can return
+// ErrHookAbstain, nil skips the message, and errors from several hooks are
+// combined according to WithHookErrorPolicy.
+type SourceHooks struct {
+	OnParse           func(ctx context.Context, key string) context.Context
+	OnDispatch        func(ctx context.Context, key string)
+	OnSuccess         func(ctx context.Context, key string, duration time.Duration)
+	OnFailure         func(ctx context.Context, key string, err error, duration time.Duration)
+	OnParseError      func(ctx context.Context, err error) error
+	OnNoHandler       func(ctx context.Context, key string) error
+	OnUnmarshalError  func(ctx context.Context, key string, err error) error
+	OnValidationError func(ctx context.Context, key string, err error) error
+	OnReply           func(ctx context.Context, key string, result json.RawMessage) (json.RawMessage, error)
+}
+
+// WithSourceHooks returns a Source that behaves like s and also runs h as
+// source hooks. Use it to attach hooks to sources you don't own, instead of
+// implementing the optional hook interfaces on the source type. Hooks that s
+// implements itself still run, after h.
+//
+// Example:
+//
+//	r.AddSource(dispatch.WithSourceHooks(eventbridge.NewSource(), dispatch.SourceHooks{
+//	    OnParse: func(ctx context.Context, key string) context.Context {
+//	        return logx.WithCtx(ctx, slog.String("bus", "default"))
+//	    },
+//	}))
+func WithSourceHooks(s Source, h SourceHooks) Source {
+	var hs hooks
+	if h.OnParse != nil {
+		hs.onParse = append(hs.onParse, func(ctx context.Context, _, key string) context.Context {
+			return h.OnParse(ctx, key)
+		})
+	}
+	if h.OnDispatch != nil {
+		hs.onDispatch = append(hs.onDispatch, func(ctx context.Context, _, key string) {
+			h.OnDispatch(ctx, key)
+		})
+	}
+	if h.OnSuccess != nil {
+		hs.onSuccess = append(hs.onSuccess, func(ctx context.Context, _, key string, d time.Duration) {
+			h.OnSuccess(ctx, key, d)
+		})
+	}
+	if h.OnFailure != nil {
+		hs.onFailure = append(hs.onFailure, func(ctx context.Context, _, key string, err error, d time.Duration) {
+			h.OnFailure(ctx, key, err, d)
+		})
+	}
+	if h.OnParseError != nil {
+		hs.onParseError = append(hs.onParseError, func(ctx context.Context, _ string, err error) error {
+			return h.OnParseError(ctx, err)
+		})
+	}
+	if h.OnNoHandler != nil {
+		hs.onNoHandler = append(hs.onNoHandler, func(ctx context.Context, _, key string) error {
+			return h.OnNoHandler(ctx, key)
+		})
+	}
+	if h.OnUnmarshalError != nil {
+		hs.onUnmarshalError = append(hs.onUnmarshalError, func(ctx context.Context, _, key string, err error) error {
+			return h.OnUnmarshalError(ctx, key, err)
+		})
+	}
+	if h.OnValidationError != nil {
+		hs.onValidationError = append(hs.onValidationError, func(ctx context.Context, _, key string, err error) error {
+			return h.OnValidationError(ctx, key, err)
+		})
+	}
+	if h.OnReply != nil {
+		hs.onReply = append(hs.onReply, func(ctx context.Context, _, key string, result json.RawMessage) (json.RawMessage, error) {
+			return h.OnReply(ctx, key, result)
+		})
+	}
+	return &hookedSource{Source: s, hooks: hs}
+}
+
+// hookedSource decorates a Source with additional hooks that run as source
+// hooks, before any hooks implemented by the wrapped source itself. Hooks
+// receive the wrapped source's name.
+type hookedSource struct {
+	Source
+	hooks hooks
+
+	// sink, if set, receives every message the source parses; see Record.
+	sink RecordSink
+
+	// insp, if set, overrides the group's inspector; see
+	// AddSourceWithInspector.
+	insp Inspector
+
+	// fallback marks the source as a catch-all; see Fallback.
+	fallback bool
+}
+
+// withHooks wraps each source so it also runs h.
+func withHooks(h hooks, sources []Source) []Source {
+	wrapped := make([]Source, len(sources))
+	for i, src := range sources {
+		wrapped[i] = &hookedSource{Source: src, hooks: h}
+	}
+	return wrapped
+}
+
+// collectHooks applies opts to an empty router and returns the hooks they
+// registered. Options other than hooks are ignored.
+func collectHooks(opts []Option) hooks {
+	var r Router
+	for _, opt := range opts {
+		opt(&r)
+	}
+	return r.hooks
+}
+
+// Parse forwards to the wrapped source, first peeling any envelopes added
+// with Unwrap or transforms added with TransformRaw.
+func (s *hookedSource) Parse(raw []byte) (Message, error) {
+	switch s.insp.(type) {
+	case *unwrapInspector, *transformInspector:
+		view, err := s.insp.Inspect(raw)
+		if err != nil {
+			s.record(raw, Message{}, err)
+			return Message{}, err
+		}
+		return s.ParseView(view, raw)
+	}
+	msg, err := s.Source.Parse(raw)
+	s.record(raw, msg, err)
+	return msg, err
+}
+
+// Inspector returns the inspector set by AddSourceWithInspector, or
+// forwards to the wrapped source so decorating an InspectorProvider keeps
+// its inspector.
+func (s *hookedSource) Inspector() Inspector {
+	if s.insp != nil {
+		return s.insp
+	}
+	return sourceInspector(s.Source)
+}
+
+// ParseView forwards to the wrapped source so decorating a ViewParser keeps
+// its fast path. Views from Unwrap and TransformRaw are parsed as the
+// innermost message.
+func (s *hookedSource) ParseView(view View, raw []byte) (Message, error) {
+	var msg Message
+	var err error
+	if uv, ok := view.(*unwrappedView); ok {
+		msg, err = uv.parse(s.Source)
+	} else if vp, ok := s.Source.(ViewParser); ok {
+		msg, err = vp.ParseView(view, raw)
+	} else {
+		msg, err = s.Source.Parse(raw)
+	}
+	s.record(raw, msg, err)
+	return msg, err
+}
+
+func (s *hookedSource) OnParse(ctx context.Context, key string) context.Context {
+	for _, fn := range s.hooks.onParse {
+		ctx = fn(ctx, s.Name(), key)
+	}
+	if h, ok := s.Source.(OnParseHook); ok {
+		ctx = h.OnParse(ctx, key)
+	}
+	return ctx
+}
+
+func (s *hookedSource) OnDispatch(ctx context.Context, key string) {
+	for _, fn := range s.hooks.onDispatch {
+		fn(ctx, s.Name(), key)
+	}
+	if h, ok := s.Source.(OnDispatchHook); ok {
+		h.OnDispatch(ctx, key)
+	}
+}
+
+func (s *hookedSource) OnSuccess(ctx context.Context, key string, duration time.Duration) {
+	for _, fn := range s.hooks.onSuccess {
+		fn(ctx, s.Name(), key, duration)
+	}
+	if h, ok := s.Source.(OnSuccessHook); ok {
+		h.OnSuccess(ctx, key, duration)
+	}
+}
+
+func (s *hookedSource) OnFailure(ctx context.Context, key string, err error, duration time.Duration) {
+	for _, fn := range s.hooks.onFailure {
+		fn(ctx, s.Name(), key, err, duration)
+	}
+	if h, ok := s.Source.(OnFailureHook); ok {
+		h.OnFailure(ctx, key, err, duration)
+	}
+}
+
+func (s *hookedSource) OnReply(ctx context.Context, key string, result json.RawMessage) (json.RawMessage, error) {
+	var err error
+	for _, fn := range s.hooks.onReply {
+		if result, err = fn(ctx, s.Name(), key, result); err != nil {
+			return nil, err
+		}
+	}
+	if h, ok := s.Source.(OnReplyHook); ok {
+		return h.OnReply(ctx, key, result)
+	}
+	return result, nil
+}
+
+// sourceParseErrorHooks runs the OnParseError hooks of source: hooks attached
+// with WithSourceHooks or AddGroupWithHooks, which count as having run like
+// global hooks, then the source's own OnParseErrorHook, which can only fail
+// the message.
+func sourceParseErrorHooks(ctx context.Context, source Source, err error) (int, []error) {
+	if s, ok := source.(*hookedSource); ok {
+		ran, errs := runPolicyHooks(s.hooks.onParseError, func(fn OnParseErrorFunc) error {
+			return fn(ctx, s.Name(), err)
+		})
+		n, more := sourceParseErrorHooks(ctx, s.Source, err)
+		return ran + n, append(errs, more...)
+	}
+	if h, ok := source.(OnParseErrorHook); ok {
+		return 0, policyErrors(h.OnParseError(ctx, err))
+	}
+	return 0, nil
+}
+
+// sourceNoHandlerHooks runs the OnNoHandler hooks of source; see
+// sourceParseErrorHooks.
+func sourceNoHandlerHooks(ctx context.Context, source Source, key string) (int, []error) {
+	if s, ok := source.(*hookedSource); ok {
+		ran, errs := runPolicyHooks(s.hooks.onNoHandler, func(fn OnNoHandlerFunc) error {
+			return fn(ctx, s.Name(), key)
+		})
+		n, more := sourceNoHandlerHooks(ctx, s.Source, key)
+		return ran + n, append(errs, more...)
+	}
+	if h, ok := source.(OnNoHandlerHook); ok {
+		return 0, policyErrors(h.OnNoHandler(ctx, key))
+	}
+	return 0, nil
+}
+
+// sourceUnmarshalErrorHooks runs the OnUnmarshalError hooks of source; see
+// sourceParseErrorHooks.
+func sourceUnmarshalErrorHooks(ctx context.Context, source Source, key string, err error) (int, []error) {
+	if s, ok := source.(*hookedSource); ok {
+		ran, errs := runPolicyHooks(s.hooks.onUnmarshalError, func(fn OnUnmarshalErrorFunc) error {
+			return fn(ctx, s.Name(), key, err)
+		})
+		n, more := sourceUnmarshalErrorHooks(ctx, s.Source, key, err)
+		return ran + n, append(errs, more...)
+	}
+	if h, ok := source.(OnUnmarshalErrorHook); ok {
+		return 0, policyErrors(h.OnUnmarshalError(ctx, key, err))
+	}
+	return 0, nil
+}
+
+// sourceValidationErrorHooks runs the OnValidationError hooks of source; see
+// sourceParseErrorHooks.
+func sourceValidationErrorHooks(ctx context.Context, source Source, key string, err error) (int, []error) {
+	if s, ok := source.(*hookedSource); ok {
+		ran, errs := runPolicyHooks(s.hooks.onValidationError, func(fn OnValidationErrorFunc) error {
+			return fn(ctx, s.Name(), key, err)
+		})
+		n, more := sourceValidationErrorHooks(ctx, s.Source, key, err)
+		return ran + n, append(errs, more...)
+	}
+	if h, ok := source.(OnValidationErrorHook); ok {
+		return 0, policyErrors(h.OnValidationError(ctx, key, err))
+	}
+	return 0, nil
+}
@@ -0,0 +1,247 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type GroupHooksSuite struct {
+	suite.Suite
+}
+
+func TestGroupHooksSuite(t *testing.T) {
+	suite.Run(t, new(GroupHooksSuite))
+}
+
+func (s *GroupHooksSuite) TestGroupHooksRunForGroupSourcesOnly() {
+	var order []string
+
+	r := New(WithOnSuccess(func(ctx context.Context, source, key string, d time.Duration) {
+		order = append(order, "global:"+source)
+	}))
+	r.AddSource(SourceFunc("default", HasFields("default"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(`{}`)}, nil
+	}))
+	source := &sourceWithHooks{name: "grouped"}
+	r.AddGroupWithHooks(JSONInspector(), []Option{
+		WithOnSuccess(func(ctx context.Context, source, key string, d time.Duration) {
+			order = append(order, "group:"+source)
+		}),
+	}, source)
+	RegisterProc(r, "test", &testHandler{})
+
+	ctx := context.Background()
+	s.Require().NoError(r.Process(ctx, []byte(`{"default": true}`)))
+	s.Require().NoError(r.Process(ctx, []byte(`{"type": "test", "payload": {}}`)))
+
+	s.Assert().Equal([]string{"global:default", "global:grouped", "group:grouped"}, order)
+	s.Assert().True(source.onSuccessCalled)
+}
+
+func (s *GroupHooksSuite) TestGroupOnParseEnrichesContext() {
+	var handlerCtx context.Context
+
+	r := New()
+	r.AddGroupWithHooks(JSONInspector(), []Option{
+		WithOnParse(func(ctx context.Context, source, key string) context.Context {
+			return context.WithValue(ctx, contextKey("group"), source)
+		}),
+	}, &testSource{name: "kafka"})
+	RegisterProcFunc(r, "test", func(ctx context.Context, p testPayload) error {
+		handlerCtx = ctx
+		return nil
+	})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`)))
+	s.Assert().Equal("kafka", handlerCtx.Value(contextKey("group")))
+}
+
+func (s *GroupHooksSuite) TestGroupErrorHookCanFail() {
+	groupErr := errors.New("group says fail")
+
+	r := New(WithOnNoHandler(func(ctx context.Context, source, key string) error {
+		return nil
+	}))
+	r.AddGroupWithHooks(JSONInspector(), []Option{
+		WithOnNoHandler(func(ctx context.Context, source, key string) error {
+			return groupErr
+		}),
+	}, &testSource{name: "kafka"})
+
+	err := r.Process(context.Background(), []byte(`{"type": "unknown", "payload": {}}`))
+
+	s.Assert().ErrorIs(err, groupErr)
+}
+
+func (s *GroupHooksSuite) TestFilteredGroupHookAbstains() {
+	var called []string
+
+	r := New()
+	r.AddGroupWithHooks(JSONInspector(), []Option{
+		WithHookFilter(MatchKeys("a"), WithOnNoHandler(func(ctx context.Context, source, key string) error {
+			called = append(called, key)
+			return nil
+		})),
+	}, &testSource{name: "kafka"})
+
+	ctx := context.Background()
+	s.Require().NoError(r.Process(ctx, []byte(`{"type": "a", "payload": {}}`)))
+	err := r.Process(ctx, []byte(`{"type": "b", "payload": {}}`))
+
+	s.Assert().ErrorIs(err, ErrNoHandler)
+	s.Assert().NotErrorIs(err, ErrHookAbstain)
+	s.Assert().Equal([]string{"a"}, called)
+}
+
+func (s *GroupHooksSuite) TestGroupJoinHookErrors() {
+	globalErr := errors.New("global")
+	groupErr := errors.New("group")
+
+	r := New(
+		WithHookErrorPolicy(JoinHookErrors),
+		WithOnNoHandler(func(ctx context.Context, source, key string) error {
+			return globalErr
+		}),
+	)
+	r.AddGroupWithHooks(JSONInspector(), []Option{
+		WithOnNoHandler(func(ctx context.Context, source, key string) error {
+			return groupErr
+		}),
+	}, &testSource{name: "kafka"})
+
+	err := r.Process(context.Background(), []byte(`{"type": "unknown", "payload": {}}`))
+
+	s.Assert().ErrorIs(err, globalErr)
+	s.Assert().ErrorIs(err, groupErr)
+}
+
+type WithSourceHooksSuite struct {
+	suite.Suite
+}
+
+func TestWithSourceHooksSuite(t *testing.T) {
+	suite.Run(t, new(WithSourceHooksSuite))
+}
+
+func (s *WithSourceHooksSuite) TestAttachesHooksToSource() {
+	var calls []string
+	inner := &sourceWithHooks{name: "third-party"}
+
+	src := WithSourceHooks(inner, SourceHooks{
+		OnParse: func(ctx context.Context, key string) context.Context {
+			calls = append(calls, "parse:"+key)
+			return ctx
+		},
+		OnDispatch: func(ctx context.Context, key string) {
+			calls = append(calls, "dispatch")
+		},
+		OnSuccess: func(ctx context.Context, key string, d time.Duration) {
+			calls = append(calls, "success")
+		},
+	})
+
+	r := New()
+	r.AddSource(src)
+	RegisterProc(r, "test", &testHandler{})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`)))
+	s.Assert().Equal("third-party", src.Name())
+	s.Assert().Equal([]string{"parse:test", "dispatch", "success"}, calls)
+	s.Assert().True(inner.onParseCalled)
+	s.Assert().True(inner.onSuccessCalled)
+}
+
+func (s *WithSourceHooksSuite) TestErrorHooks() {
+	hookErr := errors.New("fail")
+	var failureErr error
+
+	src := WithSourceHooks(&testSource{name: "test"}, SourceHooks{
+		OnFailure: func(ctx context.Context, key string, err error, d time.Duration) {
+			failureErr = err
+		},
+		OnParseError: func(ctx context.Context, err error) error {
+			return hookErr
+		},
+		OnNoHandler: func(ctx context.Context, key string) error {
+			return hookErr
+		},
+		OnUnmarshalError: func(ctx context.Context, key string, err error) error {
+			return hookErr
+		},
+		OnValidationError: func(ctx context.Context, key string, err error) error {
+			return hookErr
+		},
+	})
+
+	skip := func(ctx context.Context, source, key string, err error) error { return nil }
+	r := New(
+		WithOnParseError(func(ctx context.Context, source string, err error) error { return nil }),
+		WithOnNoHandler(func(ctx context.Context, source, key string) error { return nil }),
+		WithOnUnmarshalError(skip),
+		WithOnValidationError(skip),
+	)
+	r.AddSource(src)
+	RegisterProc(r, "proc", &testHandler{err: errors.New("boom")})
+	RegisterProcFunc(r, "valid", func(ctx context.Context, p validatablePayload) error { return nil })
+
+	ctx := context.Background()
+	s.Assert().ErrorIs(r.Process(ctx, []byte(`{"type": "", "payload": {}}`)), hookErr)
+	s.Assert().ErrorIs(r.Process(ctx, []byte(`{"type": "unknown", "payload": {}}`)), hookErr)
+	s.Assert().ErrorIs(r.Process(ctx, []byte(`{"type": "proc", "payload": "bad"}`)), hookErr)
+	s.Assert().ErrorIs(r.Process(ctx, []byte(`{"type": "valid", "payload": {}}`)), hookErr)
+
+	_ = r.Process(ctx, []byte(`{"type": "proc", "payload": {}}`))
+	s.Assert().EqualError(failureErr, "boom")
+}
+
+func (s *WithSourceHooksSuite) TestAbstainingErrorHooksDontDecide() {
+	src := WithSourceHooks(&testSource{name: "test"}, SourceHooks{
+		OnNoHandler: func(ctx context.Context, key string) error {
+			return ErrHookAbstain
+		},
+	})
+
+	r := New()
+	r.AddSource(src)
+
+	err := r.Process(context.Background(), []byte(`{"type": "unknown", "payload": {}}`))
+
+	s.Assert().ErrorIs(err, ErrNoHandler)
+	s.Assert().NotErrorIs(err, ErrHookAbstain)
+}
+
+func (s *WithSourceHooksSuite) TestErrorHookCanSkip() {
+	src := WithSourceHooks(&testSource{name: "test"}, SourceHooks{
+		OnNoHandler: func(ctx context.Context, key string) error {
+			return nil
+		},
+	})
+
+	r := New()
+	r.AddSource(src)
+
+	s.Assert().NoError(r.Process(context.Background(), []byte(`{"type": "unknown", "payload": {}}`)))
+}
+
+func (s *WithSourceHooksSuite) TestJoinHookErrors() {
+	attachedErr := errors.New("attached")
+	ownErr := errors.New("own")
+
+	src := WithSourceHooks(&sourceWithHooks{name: "test", onNoHandlerErr: ownErr}, SourceHooks{
+		OnNoHandler: func(ctx context.Context, key string) error {
+			return attachedErr
+		},
+	})
+
+	r := New(WithHookErrorPolicy(JoinHookErrors))
+	r.AddSource(src)
+
+	err := r.Process(context.Background(), []byte(`{"type": "unknown", "payload": {}}`))
+
+	s.Assert().ErrorIs(err, attachedErr)
+	s.Assert().ErrorIs(err, ownErr)
+}
@@ -0,0 +1,91 @@
+package dispatch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Initializer is an optional interface for sources that need setup before
+// they parse, such as constructing SDK clients. The router calls Init when
+// the source first matches a message, so a Lambda cold start doesn't pay
+// for sources that never receive traffic. Router.Start calls it eagerly
+// instead, for long-running consumers that would rather fail at startup.
+//
+// Init is called at most once at a time per source name. Once it succeeds
+// it is not called again; if it fails, the message fails with its error,
+// without calling WithOnParseError hooks, and the next message tries again.
+//
+// Example:
+//
+//	func (s *sqsSource) Init(ctx context.Context) error {
+//	    cfg, err := config.LoadDefaultConfig(ctx)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    s.client = sqs.NewFromConfig(cfg)
+//	    return nil
+//	}
+type Initializer interface {
+	Init(ctx context.Context) error
+}
+
+// sourceInit tracks whether one source has been initialized.
+type sourceInit struct {
+	mu   sync.Mutex // serializes Init calls
+	done atomic.Bool
+}
+
+// initSource calls Init on src if it implements Initializer and hasn't been
+// initialized yet.
+func (r *Router) initSource(ctx context.Context, src Source) error {
+	in, ok := src.(Initializer)
+	if !ok {
+		return nil
+	}
+	v, _ := r.inits.LoadOrStore(src.Name(), &sourceInit{})
+	s := v.(*sourceInit)
+	if s.done.Load() {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done.Load() {
+		return nil
+	}
+	if err := in.Init(ctx); err != nil {
+		return fmt.Errorf("init source %s: %w", src.Name(), err)
+	}
+	s.done.Store(true)
+	return nil
+}
+
+// initSources initializes every registered source that implements
+// Initializer, in registration order.
+func (r *Router) initSources(ctx context.Context) error {
+	for _, src := range r.allSources() {
+		if err := r.initSource(ctx, src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allSources returns the default sources followed by each group's sources.
+func (r *Router) allSources() []Source {
+	sources := r.defaultSources
+	for _, g := range r.groups {
+		sources = append(sources[:len(sources):len(sources)], g.sources...)
+	}
+	return sources
+}
+
+// Init forwards to the wrapped source so decorating an Initializer keeps
+// its setup.
+func (s *hookedSource) Init(ctx context.Context) error {
+	if in, ok := s.Source.(Initializer); ok {
+		return in.Init(ctx)
+	}
+	return nil
+}
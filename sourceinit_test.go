@@ -0,0 +1,131 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// initSource is a testSource that counts Init calls.
+type initSource struct {
+	testSource
+	mu    sync.Mutex
+	calls int
+	err   error
+}
+
+func (s *initSource) Init(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	return s.err
+}
+
+type SourceInitSuite struct {
+	suite.Suite
+	source  *initSource
+	handler *testHandler
+	router  *Router
+}
+
+func TestSourceInitSuite(t *testing.T) {
+	suite.Run(t, new(SourceInitSuite))
+}
+
+func (s *SourceInitSuite) SetupTest() {
+	s.source = &initSource{testSource: testSource{name: "lazy"}}
+	s.handler = &testHandler{}
+	s.router = New()
+	s.router.AddSource(s.source)
+	RegisterProc(s.router, "test", s.handler)
+}
+
+func (s *SourceInitSuite) process() error {
+	return s.router.Process(context.Background(), []byte(`{"type": "test", "payload": {"value": "x"}}`))
+}
+
+func (s *SourceInitSuite) TestInitOnFirstMatch() {
+	s.Assert().Zero(s.source.calls, "not initialized before traffic")
+
+	s.Require().NoError(s.process())
+	s.Require().NoError(s.process())
+
+	s.Assert().Equal(1, s.source.calls)
+	s.Assert().True(s.handler.called)
+}
+
+func (s *SourceInitSuite) TestUnmatchedSourceNotInitialized() {
+	other := &initSource{testSource: testSource{name: "other"}}
+	r := New()
+	r.AddGroup(JSONInspector(), SourceFunc("first", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(`{}`)}, nil
+	}), other)
+	RegisterProc(r, "test", &testHandler{})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test"}`)))
+	s.Assert().Zero(other.calls)
+}
+
+func (s *SourceInitSuite) TestInitFailureRetried() {
+	var parseErrors int
+	s.router = New(WithOnParseError(func(ctx context.Context, source string, err error) error {
+		parseErrors++
+		return nil
+	}))
+	s.router.AddSource(s.source)
+	RegisterProc(s.router, "test", s.handler)
+
+	s.source.err = errors.New("no credentials")
+	err := s.process()
+	s.Require().ErrorIs(err, s.source.err)
+	s.Assert().ErrorContains(err, "init source lazy")
+	s.Assert().Zero(parseErrors, "init failures always fail")
+	s.Assert().False(s.handler.called)
+
+	s.source.err = nil
+	s.Require().NoError(s.process())
+	s.Assert().Equal(2, s.source.calls)
+	s.Assert().True(s.handler.called)
+}
+
+func (s *SourceInitSuite) TestStartInitializesEagerly() {
+	s.Require().NoError(s.router.Start(context.Background()))
+	s.Assert().Equal(1, s.source.calls)
+
+	s.Require().NoError(s.process())
+	s.Assert().Equal(1, s.source.calls)
+}
+
+func (s *SourceInitSuite) TestStartFailure() {
+	s.source.err = errors.New("no credentials")
+	s.Assert().ErrorIs(s.router.Start(context.Background()), s.source.err)
+}
+
+func (s *SourceInitSuite) TestDecoratedSource() {
+	r := New()
+	r.AddSourceWithInspector(s.source, JSONInspector())
+	RegisterProc(r, "test", s.handler)
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"type": "test", "payload": {}}`)))
+	s.Assert().Equal(1, s.source.calls)
+}
+
+func (s *SourceInitSuite) TestConcurrentFirstMessages() {
+	RegisterProcFunc(s.router, "test", func(ctx context.Context, p testPayload) error {
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = s.process()
+		}()
+	}
+	wg.Wait()
+	s.Assert().Equal(1, s.source.calls)
+}
@@ -0,0 +1,121 @@
+package sqs
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awssqs "github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/bjaus/dispatch"
+)
+
+// Message attribute names set on dead-lettered and published messages.
+// Attributes whose value is unknown, such as the key of a message that failed
+// to parse, are omitted.
+const (
+	StageAttribute     = "DispatchStage"
+	SourceAttribute    = "DispatchSource"
+	KeyAttribute       = "DispatchKey"
+	ErrorAttribute     = "DispatchError"
+	MessageIDAttribute = "DispatchMessageId"
+
+	// BodyEncodingAttribute is set to BodyEncodingBase64 when the raw
+	// message contains characters SQS does not accept in a message body.
+	BodyEncodingAttribute = "DispatchBodyEncoding"
+	BodyEncodingBase64    = "base64"
+)
+
+// maxAttributes is the most message attributes SQS accepts on one message.
+const maxAttributes = 10
+
+// DeadLetterer sends skipped messages to an SQS dead-letter queue. The body
+// is the raw message, unchanged, so it can be replayed; failure details are
+// sent as message attributes.
+//
+// SQS only accepts Unicode text in a body, without most control characters.
+// A raw message it would reject, such as binary data, is sent base64-encoded
+// with BodyEncodingAttribute set to BodyEncodingBase64, so replay tooling
+// must decode such messages first. Attribute values are sent with invalid
+// characters replaced by U+FFFD.
+//
+// SQS accepts at most 10 message attributes. The DeadLetterer sets up to 7
+// of its own, so WithAttributes should add no more than 3; a message that
+// would exceed the limit fails to dead-letter with an error saying so.
+type DeadLetterer struct {
+	client   API
+	queueURL string
+	cfg      config
+}
+
+// NewDeadLetterer returns a DeadLetterer that sends to queueURL. Attributes
+// added with WithAttributes are sent with every message.
+//
+//	r := dispatch.New(dispatch.WithDeadLetterer(sqs.NewDeadLetterer(client, dlqURL)))
+func NewDeadLetterer(client API, queueURL string, opts ...Option) *DeadLetterer {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &DeadLetterer{client: client, queueURL: queueURL, cfg: cfg}
+}
+
+// DeadLetter implements dispatch.DeadLetterer.
+func (d *DeadLetterer) DeadLetter(ctx context.Context, dl dispatch.DeadLetter) error {
+	attrs := make(map[string]types.MessageAttributeValue, len(d.cfg.attributes)+7)
+	set := func(name, value string) {
+		if value != "" {
+			attrs[name] = stringAttribute(strings.Map(validRune, value))
+		}
+	}
+	for k, v := range d.cfg.attributes {
+		set(k, v)
+	}
+	set(StageAttribute, dl.Stage.String())
+	set(SourceAttribute, dl.Source)
+	set(KeyAttribute, dl.Key)
+	set(MessageIDAttribute, dl.MessageID)
+	set(CorrelationIDAttribute, dl.CorrelationID)
+	if dl.Err != nil {
+		set(ErrorAttribute, dl.Err.Error())
+	}
+	body := string(dl.Raw)
+	if !validBody(body) {
+		body = base64.StdEncoding.EncodeToString(dl.Raw)
+		set(BodyEncodingAttribute, BodyEncodingBase64)
+	}
+	if len(attrs) > maxAttributes {
+		return fmt.Errorf("sqs: dead letter has %d message attributes, more than the %d SQS accepts", len(attrs), maxAttributes)
+	}
+
+	_, err := d.client.SendMessage(ctx, &awssqs.SendMessageInput{
+		QueueUrl:          aws.String(d.queueURL),
+		MessageBody:       aws.String(body),
+		MessageAttributes: attrs,
+	})
+	return err
+}
+
+// validBody reports whether SQS accepts s as a message body.
+func validBody(s string) bool {
+	return utf8.ValidString(s) && strings.IndexFunc(s, func(r rune) bool { return validRune(r) != r }) < 0
+}
+
+// validRune returns r if SQS accepts it in message text, and U+FFFD
+// otherwise: SQS accepts tab, newline, carriage return, and Unicode from
+// U+0020 except surrogates and U+FFFE and U+FFFF.
+func validRune(r rune) rune {
+	switch {
+	case r == '\t', r == '\n', r == '\r',
+		r >= 0x20 && r <= 0xD7FF,
+		r >= 0xE000 && r <= 0xFFFD,
+		r >= 0x10000 && r <= 0x10FFFF:
+		return r
+	}
+	return '\uFFFD'
+}
+
+var _ dispatch.DeadLetterer = (*DeadLetterer)(nil)
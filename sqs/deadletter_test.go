@@ -0,0 +1,114 @@
+package sqs
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+)
+
+type DeadLettererSuite struct {
+	suite.Suite
+	api *fakeAPI
+	r   *dispatch.Router
+}
+
+func (s *DeadLettererSuite) SetupTest() {
+	s.api = &fakeAPI{}
+	s.r = dispatch.New(
+		dispatch.WithDeadLetterer(NewDeadLetterer(s.api, "https://sqs.us-east-1.amazonaws.com/123/dlq", WithAttributes(map[string]string{"Service": "billing"}))),
+		dispatch.WithOnNoHandler(func(ctx context.Context, source, key string) error { return nil }),
+	)
+	s.r.AddSource(dispatch.SourceFunc("test", dispatch.HasFields("type"), func(raw []byte) (dispatch.Message, error) {
+		return dispatch.Message{Key: "unknown", MessageID: "m-1", Payload: []byte(`{}`)}, nil
+	}))
+}
+
+func TestDeadLettererSuite(t *testing.T) {
+	suite.Run(t, new(DeadLettererSuite))
+}
+
+func (s *DeadLettererSuite) TestSendsRawMessageWithDetails() {
+	raw := `{"type":"unknown"}`
+	s.Require().NoError(s.r.Process(context.Background(), []byte(raw)))
+
+	s.Require().Len(s.api.inputs, 1)
+	in := s.api.inputs[0]
+	attr := func(name string) string { return aws.ToString(in.MessageAttributes[name].StringValue) }
+	s.Assert().Equal("https://sqs.us-east-1.amazonaws.com/123/dlq", aws.ToString(in.QueueUrl))
+	s.Assert().Equal(raw, aws.ToString(in.MessageBody))
+	s.Assert().Equal("route", attr(StageAttribute))
+	s.Assert().Equal("test", attr(SourceAttribute))
+	s.Assert().Equal("unknown", attr(KeyAttribute))
+	s.Assert().Equal("no handler for key: unknown", attr(ErrorAttribute))
+	s.Assert().Equal("m-1", attr(MessageIDAttribute))
+	s.Assert().Equal("m-1", attr(CorrelationIDAttribute))
+	s.Assert().Equal("billing", attr("Service"))
+}
+
+func (s *DeadLettererSuite) TestOmitsUnknownDetails() {
+	dl := NewDeadLetterer(s.api, "dlq")
+
+	s.Require().NoError(dl.DeadLetter(context.Background(), dispatch.DeadLetter{
+		Raw:   []byte(`{}`),
+		Stage: dispatch.StageMatch,
+		Err:   dispatch.ErrNoSource,
+	}))
+
+	in := s.api.inputs[0]
+	s.Assert().Len(in.MessageAttributes, 2)
+	s.Assert().Contains(in.MessageAttributes, StageAttribute)
+	s.Assert().Contains(in.MessageAttributes, ErrorAttribute)
+}
+
+func (s *DeadLettererSuite) TestEncodesBodiesSQSRejects() {
+	dl := NewDeadLetterer(s.api, "dlq")
+
+	for _, raw := range [][]byte{{0x00, 0x01}, {0xff, 'x'}} {
+		s.Require().NoError(dl.DeadLetter(context.Background(), dispatch.DeadLetter{
+			Raw:   raw,
+			Stage: dispatch.StageMatch,
+			Err:   errors.New("bad \x00 input"),
+		}))
+
+		in := s.api.inputs[len(s.api.inputs)-1]
+		s.Assert().Equal(base64.StdEncoding.EncodeToString(raw), aws.ToString(in.MessageBody))
+		s.Assert().Equal(BodyEncodingBase64, aws.ToString(in.MessageAttributes[BodyEncodingAttribute].StringValue))
+		s.Assert().Equal("bad \uFFFD input", aws.ToString(in.MessageAttributes[ErrorAttribute].StringValue))
+	}
+}
+
+func (s *DeadLettererSuite) TestKeepsValidBodies() {
+	s.Assert().True(validBody("{\"name\": \"caf\u00e9 \U0001F600\"}\n"))
+	s.Assert().False(validBody("\x1b[0m"))
+	s.Assert().False(validBody("\xc3"))
+	s.Assert().False(validBody("\uFFFE"))
+}
+
+func (s *DeadLettererSuite) TestTooManyAttributes() {
+	attrs := map[string]string{"A": "1", "B": "2", "C": "3", "D": "4", "E": "5"}
+	dl := NewDeadLetterer(s.api, "dlq", WithAttributes(attrs))
+
+	err := dl.DeadLetter(context.Background(), dispatch.DeadLetter{
+		Raw:           []byte(`{}`),
+		Stage:         dispatch.StageRoute,
+		Source:        "test",
+		Key:           "unknown",
+		MessageID:     "m-1",
+		CorrelationID: "c-1",
+		Err:           dispatch.ErrNoHandler,
+	})
+
+	s.Assert().EqualError(err, "sqs: dead letter has 11 message attributes, more than the 10 SQS accepts")
+	s.Assert().Empty(s.api.inputs)
+}
+
+func (s *DeadLettererSuite) TestSendErrorFailsMessage() {
+	s.api.err = errors.New("throttled")
+
+	s.Assert().EqualError(s.r.Process(context.Background(), []byte(`{"type":"unknown"}`)), "dead letter: throttled")
+}
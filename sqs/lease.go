@@ -0,0 +1,53 @@
+package sqs
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awssqs "github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/bjaus/dispatch"
+)
+
+// VisibilityAPI is the subset of the SQS client used by Lease. *sqs.Client
+// satisfies it.
+type VisibilityAPI interface {
+	ChangeMessageVisibility(ctx context.Context, in *awssqs.ChangeMessageVisibilityInput, optFns ...func(*awssqs.Options)) (*awssqs.ChangeMessageVisibilityOutput, error)
+}
+
+// Lease extends the visibility timeout of a received message, so it isn't
+// delivered to another consumer while a slow handler is still working.
+// Set it on dispatch.Message.Lease in the source that parses the message.
+type Lease struct {
+	client        VisibilityAPI
+	queueURL      string
+	receiptHandle string
+}
+
+// NewLease returns a Lease for the message with receiptHandle, received
+// from queueURL.
+//
+//	msg.Lease = sqs.NewLease(client, queueURL, *m.ReceiptHandle)
+func NewLease(client VisibilityAPI, queueURL, receiptHandle string) *Lease {
+	return &Lease{client: client, queueURL: queueURL, receiptHandle: receiptHandle}
+}
+
+// ExtendLease implements dispatch.Lease. It sets the message's visibility
+// timeout to d from now, rounded up to whole seconds and capped at SQS's
+// maximum of 12 hours.
+func (l *Lease) ExtendLease(ctx context.Context, d time.Duration) error {
+	seconds := min(math.Ceil(d.Seconds()), maxVisibilityTimeout)
+	_, err := l.client.ChangeMessageVisibility(ctx, &awssqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(l.queueURL),
+		ReceiptHandle:     aws.String(l.receiptHandle),
+		VisibilityTimeout: int32(seconds),
+	})
+	return err
+}
+
+// maxVisibilityTimeout is the longest visibility timeout SQS allows, in
+// seconds.
+const maxVisibilityTimeout = 12 * 60 * 60
+
+var _ dispatch.Lease = (*Lease)(nil)
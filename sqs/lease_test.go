@@ -0,0 +1,61 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awssqs "github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeVisibilityAPI struct {
+	inputs []*awssqs.ChangeMessageVisibilityInput
+	err    error
+}
+
+func (f *fakeVisibilityAPI) ChangeMessageVisibility(ctx context.Context, in *awssqs.ChangeMessageVisibilityInput, _ ...func(*awssqs.Options)) (*awssqs.ChangeMessageVisibilityOutput, error) {
+	f.inputs = append(f.inputs, in)
+	return &awssqs.ChangeMessageVisibilityOutput{}, f.err
+}
+
+type LeaseSuite struct {
+	suite.Suite
+	api   *fakeVisibilityAPI
+	lease *Lease
+}
+
+func TestLeaseSuite(t *testing.T) {
+	suite.Run(t, new(LeaseSuite))
+}
+
+func (s *LeaseSuite) SetupTest() {
+	s.api = &fakeVisibilityAPI{}
+	s.lease = NewLease(s.api, "https://sqs.us-east-1.amazonaws.com/123/orders", "rh-1")
+}
+
+func (s *LeaseSuite) TestExtendsVisibility() {
+	s.Require().NoError(s.lease.ExtendLease(context.Background(), 90*time.Second))
+
+	s.Require().Len(s.api.inputs, 1)
+	in := s.api.inputs[0]
+	s.Assert().Equal("https://sqs.us-east-1.amazonaws.com/123/orders", aws.ToString(in.QueueUrl))
+	s.Assert().Equal("rh-1", aws.ToString(in.ReceiptHandle))
+	s.Assert().Equal(int32(90), in.VisibilityTimeout)
+}
+
+func (s *LeaseSuite) TestRoundsUpAndCaps() {
+	s.Require().NoError(s.lease.ExtendLease(context.Background(), 1500*time.Millisecond))
+	s.Require().NoError(s.lease.ExtendLease(context.Background(), 24*time.Hour))
+
+	s.Assert().Equal(int32(2), s.api.inputs[0].VisibilityTimeout)
+	s.Assert().Equal(int32(12*60*60), s.api.inputs[1].VisibilityTimeout)
+}
+
+func (s *LeaseSuite) TestError() {
+	s.api.err = errors.New("receipt handle expired")
+
+	s.Assert().ErrorIs(s.lease.ExtendLease(context.Background(), time.Minute), s.api.err)
+}
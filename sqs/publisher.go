@@ -0,0 +1,66 @@
+package sqs
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awssqs "github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/bjaus/dispatch"
+)
+
+// ReplyToAttribute holds the reply address of requests sent with
+// dispatch.Call.
+const ReplyToAttribute = "DispatchReplyTo"
+
+// Publisher is a dispatch.Transport that sends events to an SQS queue. The
+// body is the event payload; the key, message ID, correlation ID, and reply
+// address are sent as the KeyAttribute, MessageIDAttribute,
+// CorrelationIDAttribute, and ReplyToAttribute message attributes, with the
+// event's own attributes.
+type Publisher struct {
+	client   API
+	queueURL string
+	cfg      config
+}
+
+// NewPublisher returns a Publisher that sends to queueURL. Attributes added
+// with WithAttributes are sent with every event.
+//
+//	pub := dispatch.NewPublisher(events, sqs.NewPublisher(client, queueURL))
+func NewPublisher(client API, queueURL string, opts ...Option) *Publisher {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Publisher{client: client, queueURL: queueURL, cfg: cfg}
+}
+
+// Send implements dispatch.Transport.
+func (p *Publisher) Send(ctx context.Context, e dispatch.Event) error {
+	attrs := make(map[string]types.MessageAttributeValue, len(p.cfg.attributes)+len(e.Attributes)+4)
+	for k, v := range p.cfg.attributes {
+		attrs[k] = stringAttribute(v)
+	}
+	for k, v := range e.Attributes {
+		attrs[k] = stringAttribute(v)
+	}
+	set := func(name, value string) {
+		if value != "" {
+			attrs[name] = stringAttribute(value)
+		}
+	}
+	set(KeyAttribute, e.Key)
+	set(MessageIDAttribute, e.MessageID)
+	set(CorrelationIDAttribute, e.CorrelationID)
+	set(ReplyToAttribute, e.ReplyTo)
+
+	_, err := p.client.SendMessage(ctx, &awssqs.SendMessageInput{
+		QueueUrl:          aws.String(p.queueURL),
+		MessageBody:       aws.String(string(e.Payload)),
+		MessageAttributes: attrs,
+	})
+	return err
+}
+
+var _ dispatch.Transport = (*Publisher)(nil)
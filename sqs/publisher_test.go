@@ -0,0 +1,57 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+)
+
+type userCreated struct {
+	ID string `json:"id"`
+}
+
+type PublisherSuite struct {
+	suite.Suite
+	api *fakeAPI
+	pub *dispatch.Publisher
+}
+
+func (s *PublisherSuite) SetupTest() {
+	s.api = &fakeAPI{}
+	reg := dispatch.NewRegistry()
+	dispatch.Register[userCreated](reg, "user/created")
+	s.pub = dispatch.NewPublisher(reg, NewPublisher(s.api, "https://sqs.us-east-1.amazonaws.com/123/users", WithAttributes(map[string]string{"Service": "users"})))
+}
+
+func TestPublisherSuite(t *testing.T) {
+	suite.Run(t, new(PublisherSuite))
+}
+
+func (s *PublisherSuite) TestSendsPayloadWithKeyAttributes() {
+	err := dispatch.Publish(context.Background(), s.pub, userCreated{ID: "42"},
+		dispatch.WithEventID("evt-1"),
+		dispatch.WithEventAttributes(map[string]string{"Tenant": "acme"}),
+	)
+
+	s.Require().NoError(err)
+	s.Require().Len(s.api.inputs, 1)
+	in := s.api.inputs[0]
+	attr := func(name string) string { return aws.ToString(in.MessageAttributes[name].StringValue) }
+	s.Assert().Equal("https://sqs.us-east-1.amazonaws.com/123/users", aws.ToString(in.QueueUrl))
+	s.Assert().JSONEq(`{"id": "42"}`, aws.ToString(in.MessageBody))
+	s.Assert().Equal("user/created", attr(KeyAttribute))
+	s.Assert().Equal("evt-1", attr(MessageIDAttribute))
+	s.Assert().Equal("acme", attr("Tenant"))
+	s.Assert().Equal("users", attr("Service"))
+	s.Assert().NotContains(in.MessageAttributes, CorrelationIDAttribute)
+}
+
+func (s *PublisherSuite) TestReturnsSendErrors() {
+	s.api.err = errors.New("throttled")
+
+	s.Assert().ErrorIs(dispatch.Publish(context.Background(), s.pub, userCreated{}), s.api.err)
+}
@@ -0,0 +1,157 @@
+// Package sqs provides a dispatch.Replier that sends results to an SQS reply
+// queue, for queue-based request-response, and a dispatch.DeadLetterer that
+// sends skipped messages to a dead-letter queue.
+//
+// Use Factory to build repliers from each message's ReplyTo queue URL:
+//
+//	r := dispatch.New(dispatch.WithReplierFactory(sqs.Factory(client)))
+//
+// Replies carry the message's correlation ID as a message attribute so the
+// caller can match them to its request.
+//
+// Lease extends a received message's visibility timeout, for
+// dispatch.ExtendLease and dispatch.WithLeaseHeartbeat.
+package sqs
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awssqs "github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/bjaus/dispatch"
+)
+
+// Message attribute names and values set on every reply.
+const (
+	// CorrelationIDAttribute holds the correlation ID of the request.
+	CorrelationIDAttribute = "CorrelationId"
+
+	// StatusAttribute is StatusOK for results and StatusError for failures.
+	StatusAttribute = "Status"
+
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// API is the subset of the SQS client used by Replier. *sqs.Client
+// satisfies it.
+type API interface {
+	SendMessage(ctx context.Context, in *awssqs.SendMessageInput, optFns ...func(*awssqs.Options)) (*awssqs.SendMessageOutput, error)
+}
+
+// Option configures a Replier or DeadLetterer.
+type Option func(*config)
+
+type config struct {
+	attributes map[string]string
+}
+
+// WithAttributes adds static string message attributes to every reply.
+func WithAttributes(attrs map[string]string) Option {
+	return func(c *config) {
+		if c.attributes == nil {
+			c.attributes = make(map[string]string, len(attrs))
+		}
+		maps.Copy(c.attributes, attrs)
+	}
+}
+
+// Replier sends results to an SQS queue. Reply sends the result JSON as the
+// message body; Fail sends {"error": "..."} with StatusAttribute set to
+// StatusError.
+type Replier struct {
+	client   API
+	queueURL string
+	cfg      config
+}
+
+// NewReplier returns a Replier that sends to queueURL.
+func NewReplier(client API, queueURL string, opts ...Option) *Replier {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Replier{client: client, queueURL: queueURL, cfg: cfg}
+}
+
+// Factory returns a dispatch.ReplierFactory that sends replies to each
+// message's ReplyTo queue URL.
+func Factory(client API, opts ...Option) dispatch.ReplierFactory {
+	return dispatch.ReplierFactoryFunc(func(ctx context.Context, msg dispatch.Message) (dispatch.Replier, error) {
+		return NewReplier(client, msg.ReplyTo, opts...), nil
+	})
+}
+
+// Reply implements dispatch.Replier.
+func (r *Replier) Reply(ctx context.Context, result json.RawMessage) error {
+	return r.send(ctx, string(result), StatusOK)
+}
+
+// Fail implements dispatch.Replier.
+func (r *Replier) Fail(ctx context.Context, err error) error {
+	body, merr := json.Marshal(struct {
+		Error string `json:"error"`
+	}{Error: err.Error()})
+	if merr != nil {
+		return merr
+	}
+	return r.send(ctx, string(body), StatusError)
+}
+
+func (r *Replier) send(ctx context.Context, body, status string) error {
+	attrs := make(map[string]types.MessageAttributeValue, len(r.cfg.attributes)+2)
+	for k, v := range r.cfg.attributes {
+		attrs[k] = stringAttribute(v)
+	}
+	attrs[StatusAttribute] = stringAttribute(status)
+	if id := dispatch.CorrelationID(ctx); id != "" {
+		attrs[CorrelationIDAttribute] = stringAttribute(id)
+	}
+
+	_, err := r.client.SendMessage(ctx, &awssqs.SendMessageInput{
+		QueueUrl:          aws.String(r.queueURL),
+		MessageBody:       aws.String(body),
+		MessageAttributes: attrs,
+	})
+	return err
+}
+
+// Deliver completes the dispatch.Call that m, a message from the client's
+// reply queue sent by Replier, answers. Receive messages with
+// MessageAttributeNames including CorrelationIDAttribute and
+// StatusAttribute. It reports whether a Call was waiting for the reply.
+//
+// Example:
+//
+//	out, err := client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+//	    QueueUrl:              aws.String(replyQueueURL),
+//	    MessageAttributeNames: []string{"All"},
+//	})
+//	for _, m := range out.Messages {
+//	    dispatchsqs.Deliver(rpc, m)
+//	}
+func Deliver(c *dispatch.Client, m types.Message) bool {
+	attr := func(name string) string {
+		return aws.ToString(m.MessageAttributes[name].StringValue)
+	}
+	body := json.RawMessage(aws.ToString(m.Body))
+	if attr(StatusAttribute) != StatusError {
+		return c.Deliver(attr(CorrelationIDAttribute), body, nil)
+	}
+	var failure struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &failure); err != nil {
+		failure.Error = string(body)
+	}
+	return c.Deliver(attr(CorrelationIDAttribute), nil, &dispatch.RemoteError{Message: failure.Error})
+}
+
+func stringAttribute(v string) types.MessageAttributeValue {
+	return types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(v)}
+}
+
+var _ dispatch.Replier = (*Replier)(nil)
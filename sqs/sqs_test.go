@@ -0,0 +1,149 @@
+package sqs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awssqs "github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeAPI struct {
+	inputs []*awssqs.SendMessageInput
+	err    error
+	onSend func(*awssqs.SendMessageInput)
+}
+
+func (f *fakeAPI) SendMessage(ctx context.Context, in *awssqs.SendMessageInput, _ ...func(*awssqs.Options)) (*awssqs.SendMessageOutput, error) {
+	f.inputs = append(f.inputs, in)
+	if f.onSend != nil {
+		f.onSend(in)
+	}
+	return &awssqs.SendMessageOutput{}, f.err
+}
+
+type ReplierSuite struct {
+	suite.Suite
+	api *fakeAPI
+	r   *dispatch.Router
+}
+
+func (s *ReplierSuite) SetupTest() {
+	s.api = &fakeAPI{}
+	s.r = dispatch.New(dispatch.WithReplierFactory(Factory(s.api, WithAttributes(map[string]string{"Service": "billing"}))))
+	s.r.AddSource(dispatch.SourceFunc("test", dispatch.HasFields("type"), func(raw []byte) (dispatch.Message, error) {
+		var env struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(raw, &env); err != nil {
+			return dispatch.Message{}, err
+		}
+		return dispatch.Message{
+			Key:           env.Type,
+			CorrelationID: "c-1",
+			ReplyTo:       "https://sqs.us-east-1.amazonaws.com/123/replies",
+			Payload:       []byte(`{}`),
+		}, nil
+	}))
+	dispatch.RegisterFuncFunc(s.r, "ok", func(ctx context.Context, p struct{}) (map[string]int, error) {
+		return map[string]int{"n": 1}, nil
+	})
+	dispatch.RegisterProcFunc(s.r, "fail", func(ctx context.Context, p struct{}) error {
+		return errors.New("boom")
+	})
+}
+
+func TestReplierSuite(t *testing.T) {
+	suite.Run(t, new(ReplierSuite))
+}
+
+func (s *ReplierSuite) TestReplySendsResult() {
+	s.Require().NoError(s.r.Process(context.Background(), []byte(`{"type":"ok"}`)))
+
+	s.Require().Len(s.api.inputs, 1)
+	in := s.api.inputs[0]
+	s.Assert().Equal("https://sqs.us-east-1.amazonaws.com/123/replies", aws.ToString(in.QueueUrl))
+	s.Assert().JSONEq(`{"n": 1}`, aws.ToString(in.MessageBody))
+	s.Assert().Equal("c-1", aws.ToString(in.MessageAttributes[CorrelationIDAttribute].StringValue))
+	s.Assert().Equal(StatusOK, aws.ToString(in.MessageAttributes[StatusAttribute].StringValue))
+	s.Assert().Equal("billing", aws.ToString(in.MessageAttributes["Service"].StringValue))
+}
+
+func (s *ReplierSuite) TestFailSendsError() {
+	s.Require().NoError(s.r.Process(context.Background(), []byte(`{"type":"fail"}`)))
+
+	s.Require().Len(s.api.inputs, 1)
+	in := s.api.inputs[0]
+	s.Assert().JSONEq(`{"error": "boom"}`, aws.ToString(in.MessageBody))
+	s.Assert().Equal(StatusError, aws.ToString(in.MessageAttributes[StatusAttribute].StringValue))
+}
+
+func (s *ReplierSuite) TestReturnsSendError() {
+	s.api.err = errors.New("throttled")
+
+	s.Assert().EqualError(s.r.Process(context.Background(), []byte(`{"type":"ok"}`)), "throttled")
+}
+
+type lookup struct {
+	ID string `json:"id"`
+}
+
+type DeliverSuite struct {
+	suite.Suite
+	api    *fakeAPI
+	client *dispatch.Client
+}
+
+func (s *DeliverSuite) SetupTest() {
+	s.api = &fakeAPI{}
+	reg := dispatch.NewRegistry()
+	dispatch.Register[lookup](reg, "lookup")
+	s.client = dispatch.NewClient(reg, NewPublisher(s.api, "requests"), "replies")
+}
+
+func TestDeliverSuite(t *testing.T) {
+	suite.Run(t, new(DeliverSuite))
+}
+
+// reply answers each request the way Replier would.
+func (s *DeliverSuite) reply(status, body string) {
+	s.api.onSend = func(in *awssqs.SendMessageInput) {
+		s.Assert().Equal("replies", aws.ToString(in.MessageAttributes[ReplyToAttribute].StringValue))
+		id := aws.ToString(in.MessageAttributes[CorrelationIDAttribute].StringValue)
+		s.Assert().True(Deliver(s.client, types.Message{
+			Body: aws.String(body),
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				CorrelationIDAttribute: stringAttribute(id),
+				StatusAttribute:        stringAttribute(status),
+			},
+		}))
+	}
+}
+
+func (s *DeliverSuite) TestDeliversResult() {
+	s.reply(StatusOK, `{"name": "ada"}`)
+
+	got, err := dispatch.Call[lookup, map[string]string](context.Background(), s.client, lookup{ID: "42"})
+
+	s.Require().NoError(err)
+	s.Assert().Equal(map[string]string{"name": "ada"}, got)
+}
+
+func (s *DeliverSuite) TestDeliversFailure() {
+	s.reply(StatusError, `{"error": "not found"}`)
+
+	_, err := dispatch.Call[lookup, map[string]string](context.Background(), s.client, lookup{ID: "42"})
+
+	var remote *dispatch.RemoteError
+	s.Require().ErrorAs(err, &remote)
+	s.Assert().Equal("not found", remote.Message)
+}
+
+func (s *DeliverSuite) TestUnknownCorrelationID() {
+	s.Assert().False(Deliver(s.client, types.Message{Body: aws.String(`{}`)}))
+}
@@ -0,0 +1,138 @@
+package dispatch
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a snapshot of router activity since it was created.
+type Stats struct {
+	// Sources holds counters per source name.
+	Sources map[string]Counters
+
+	// Keys holds counters per routing key.
+	Keys map[string]Counters
+}
+
+// Counters describes how messages for a source or key were handled.
+type Counters struct {
+	// Matched is the number of messages matched to the source, or parsed
+	// with the key.
+	Matched uint64
+
+	// Processed is the number of messages whose handler succeeded.
+	Processed uint64
+
+	// Failed is the number of messages that returned an error, either from
+	// the handler or from parsing, lookup, unmarshaling, or validation.
+	Failed uint64
+
+	// Skipped is the number of messages skipped by a policy hook such as
+	// WithOnNoHandler, dropped as stale by WithMaxMessageAge, or skipped as a
+	// duplicate by WithDuplicateSuppression.
+	Skipped uint64
+
+	// AvgDuration is the average handler duration across processed and
+	// failed handler runs.
+	AvgDuration time.Duration
+}
+
+// Stats returns a snapshot of per-source and per-key counters. Counters are
+// maintained with atomic operations, so Stats is safe to call concurrently
+// with Process, for example from an admin or debug endpoint.
+//
+// Example:
+//
+//	http.HandleFunc("/debug/dispatch", func(w http.ResponseWriter, _ *http.Request) {
+//	    json.NewEncoder(w).Encode(router.Stats())
+//	})
+func (r *Router) Stats() Stats {
+	return Stats{
+		Sources: r.stats.sources.snapshot(),
+		Keys:    r.stats.keys.snapshot(),
+	}
+}
+
+// routerStats holds the live counters behind Router.Stats.
+type routerStats struct {
+	sources counterSet
+	keys    counterSet
+}
+
+// outcome records a message that ended without running a handler. A nil
+// err means a policy hook skipped the message.
+func (s *routerStats) outcome(source, key string, err error) {
+	for _, c := range s.counters(source, key) {
+		if err != nil {
+			c.failed.Add(1)
+		} else {
+			c.skipped.Add(1)
+		}
+	}
+}
+
+// handled records a handler run.
+func (s *routerStats) handled(source, key string, err error, d time.Duration) {
+	for _, c := range s.counters(source, key) {
+		if err != nil {
+			c.failed.Add(1)
+		} else {
+			c.processed.Add(1)
+		}
+		c.runs.Add(1)
+		c.nanos.Add(int64(d))
+	}
+}
+
+// counters returns the counters for source and, if known, key.
+func (s *routerStats) counters(source, key string) []*liveCounters {
+	if key == "" {
+		return []*liveCounters{s.sources.get(source)}
+	}
+	return []*liveCounters{s.sources.get(source), s.keys.get(key)}
+}
+
+// counterSet maps names to live counters.
+type counterSet struct {
+	m sync.Map // string -> *liveCounters
+}
+
+func (cs *counterSet) get(name string) *liveCounters {
+	if c, ok := cs.m.Load(name); ok {
+		return c.(*liveCounters)
+	}
+	c, _ := cs.m.LoadOrStore(name, &liveCounters{})
+	return c.(*liveCounters)
+}
+
+func (cs *counterSet) snapshot() map[string]Counters {
+	out := make(map[string]Counters)
+	cs.m.Range(func(k, v any) bool {
+		out[k.(string)] = v.(*liveCounters).snapshot()
+		return true
+	})
+	return out
+}
+
+type liveCounters struct {
+	matched   atomic.Uint64
+	processed atomic.Uint64
+	failed    atomic.Uint64
+	skipped   atomic.Uint64
+	runs      atomic.Uint64
+	nanos     atomic.Int64
+}
+
+func (c *liveCounters) snapshot() Counters {
+	out := Counters{
+		Matched:   c.matched.Load(),
+		Processed: c.processed.Load(),
+		Failed:    c.failed.Load(),
+		Skipped:   c.skipped.Load(),
+	}
+	if runs := c.runs.Load(); runs > 0 {
+		out.AvgDuration = time.Duration(c.nanos.Load() / int64(runs))
+	}
+	return out
+}
@@ -0,0 +1,75 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type StatsSuite struct {
+	suite.Suite
+}
+
+func TestStatsSuite(t *testing.T) {
+	suite.Run(t, new(StatsSuite))
+}
+
+func (s *StatsSuite) TestCountsOutcomesPerSourceAndKey() {
+	r := New(WithOnUnmarshalError(func(ctx context.Context, source, key string, err error) error {
+		return nil
+	}))
+	r.AddSource(&testSource{name: "test"})
+	RegisterProc(r, "ok", &testHandler{})
+	RegisterProc(r, "fail", &testHandler{err: errors.New("boom")})
+
+	ctx := context.Background()
+	_ = r.Process(ctx, []byte(`{"type": "ok", "payload": {}}`))
+	_ = r.Process(ctx, []byte(`{"type": "ok", "payload": {}}`))
+	_ = r.Process(ctx, []byte(`{"type": "fail", "payload": {}}`))
+	_ = r.Process(ctx, []byte(`{"type": "ok", "payload": "bad"}`))
+	_ = r.Process(ctx, []byte(`{"type": "missing", "payload": {}}`))
+	_ = r.Process(ctx, []byte(`{"type": "", "payload": {}}`))
+	_ = r.Process(ctx, []byte(`{"other": true}`))
+
+	stats := r.Stats()
+
+	src := stats.Sources["test"]
+	s.Assert().Equal(uint64(6), src.Matched)
+	s.Assert().Equal(uint64(2), src.Processed)
+	s.Assert().Equal(uint64(3), src.Failed)
+	s.Assert().Equal(uint64(1), src.Skipped)
+
+	ok := stats.Keys["ok"]
+	s.Assert().Equal(uint64(3), ok.Matched)
+	s.Assert().Equal(uint64(2), ok.Processed)
+	s.Assert().Equal(uint64(1), ok.Skipped)
+	s.Assert().Positive(ok.AvgDuration)
+
+	s.Assert().Equal(uint64(1), stats.Keys["fail"].Failed)
+	s.Assert().Equal(uint64(1), stats.Keys["missing"].Failed)
+	s.Assert().NotContains(stats.Keys, "")
+}
+
+func (s *StatsSuite) TestSafeForConcurrentUse() {
+	r := New()
+	r.AddSource(&testSource{name: "test"})
+	RegisterProcFunc(r, "ok", func(ctx context.Context, p testPayload) error { return nil })
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 10 {
+				_ = r.Process(context.Background(), []byte(`{"type": "ok", "payload": {}}`))
+				_ = r.Stats()
+			}
+		}()
+	}
+	wg.Wait()
+
+	s.Assert().Equal(uint64(100), r.Stats().Keys["ok"].Processed)
+}
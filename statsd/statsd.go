@@ -0,0 +1,112 @@
+// Package statsd emits dispatch metrics to a StatsD or DogStatsD client.
+//
+// Client matches the method signatures of the Datadog Go client, so a
+// *statsd.Client from github.com/DataDog/datadog-go/v5/statsd can be passed
+// directly:
+//
+//	client, _ := ddstatsd.New("127.0.0.1:8125")
+//	r := dispatch.New(statsd.Hooks(client, statsd.WithTags("service:billing"))...)
+//
+// The following metrics are emitted, tagged with source and key:
+//
+//   - <prefix>.success: timing of successful handler runs
+//   - <prefix>.failure: count of failed handler runs
+//   - <prefix>.stage.<stage>: per-stage timings, when WithStageTimings is set
+package statsd
+
+import (
+	"context"
+	"time"
+
+	"github.com/bjaus/dispatch"
+)
+
+// Client is the subset of a StatsD client used to emit metrics.
+type Client interface {
+	Timing(name string, value time.Duration, tags []string, rate float64) error
+	Count(name string, value int64, tags []string, rate float64) error
+}
+
+// Option configures the metrics hooks.
+type Option func(*config)
+
+type config struct {
+	prefix string
+	tags   []string
+	rate   float64
+	stages bool
+}
+
+// WithPrefix sets the metric name prefix. Defaults to "dispatch".
+func WithPrefix(prefix string) Option {
+	return func(c *config) {
+		c.prefix = prefix
+	}
+}
+
+// WithTags adds static tags to every metric, such as "service:billing".
+func WithTags(tags ...string) Option {
+	return func(c *config) {
+		c.tags = append(c.tags, tags...)
+	}
+}
+
+// WithRate sets the sample rate passed to the client. Defaults to 1.
+func WithRate(rate float64) Option {
+	return func(c *config) {
+		c.rate = rate
+	}
+}
+
+// WithStageTimings emits a timing per processing stage (match, parse,
+// unmarshal, validate, handle, reply) from dispatch.WithOnTimings.
+func WithStageTimings() Option {
+	return func(c *config) {
+		c.stages = true
+	}
+}
+
+// Hooks returns router options that emit metrics to client.
+func Hooks(client Client, opts ...Option) []dispatch.Option {
+	cfg := config{prefix: "dispatch", rate: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	hooks := []dispatch.Option{
+		dispatch.WithOnSuccess(func(ctx context.Context, source, key string, d time.Duration) {
+			_ = client.Timing(cfg.prefix+".success", d, cfg.tagsFor(source, key), cfg.rate)
+		}),
+		dispatch.WithOnFailure(func(ctx context.Context, source, key string, err error, d time.Duration) {
+			_ = client.Count(cfg.prefix+".failure", 1, cfg.tagsFor(source, key), cfg.rate)
+		}),
+	}
+
+	if cfg.stages {
+		hooks = append(hooks, dispatch.WithOnTimings(func(ctx context.Context, source, key string, t dispatch.Timings) {
+			tags := cfg.tagsFor(source, key)
+			for _, stage := range []struct {
+				name string
+				d    time.Duration
+			}{
+				{"match", t.Match},
+				{"parse", t.Parse},
+				{"unmarshal", t.Unmarshal},
+				{"validate", t.Validate},
+				{"handle", t.Handle},
+				{"reply", t.Reply},
+			} {
+				_ = client.Timing(cfg.prefix+".stage."+stage.name, stage.d, tags, cfg.rate)
+			}
+		}))
+	}
+
+	return hooks
+}
+
+// tagsFor returns the static tags plus source and key tags.
+func (c *config) tagsFor(source, key string) []string {
+	tags := make([]string, 0, len(c.tags)+2)
+	tags = append(tags, c.tags...)
+	return append(tags, "source:"+source, "key:"+key)
+}
@@ -0,0 +1,107 @@
+package statsd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bjaus/dispatch"
+	"github.com/stretchr/testify/suite"
+)
+
+type metric struct {
+	name string
+	tags []string
+	rate float64
+}
+
+// fakeClient records emitted metrics.
+type fakeClient struct {
+	timings []metric
+	counts  []metric
+}
+
+func (c *fakeClient) Timing(name string, value time.Duration, tags []string, rate float64) error {
+	c.timings = append(c.timings, metric{name: name, tags: tags, rate: rate})
+	return nil
+}
+
+func (c *fakeClient) Count(name string, value int64, tags []string, rate float64) error {
+	c.counts = append(c.counts, metric{name: name, tags: tags, rate: rate})
+	return nil
+}
+
+type HooksSuite struct {
+	suite.Suite
+	client *fakeClient
+}
+
+func (s *HooksSuite) SetupTest() {
+	s.client = &fakeClient{}
+}
+
+func TestHooksSuite(t *testing.T) {
+	suite.Run(t, new(HooksSuite))
+}
+
+func (s *HooksSuite) newRouter(handlerErr error, opts ...Option) *dispatch.Router {
+	r := dispatch.New(Hooks(s.client, opts...)...)
+	r.AddSource(dispatch.SourceFunc("test", dispatch.HasFields("type"), func(raw []byte) (dispatch.Message, error) {
+		return dispatch.Message{Key: "user/created", Payload: []byte(`{}`)}, nil
+	}))
+	dispatch.RegisterProcFunc(r, "user/created", func(ctx context.Context, p struct{}) error {
+		return handlerErr
+	})
+	return r
+}
+
+func (s *HooksSuite) TestEmitsSuccessTiming() {
+	r := s.newRouter(nil, WithTags("service:billing"))
+
+	err := r.Process(context.Background(), []byte(`{"type": "x"}`))
+
+	s.Require().NoError(err)
+	s.Require().Len(s.client.timings, 1)
+	s.Assert().Equal(metric{
+		name: "dispatch.success",
+		tags: []string{"service:billing", "source:test", "key:user/created"},
+		rate: 1,
+	}, s.client.timings[0])
+	s.Assert().Empty(s.client.counts)
+}
+
+func (s *HooksSuite) TestEmitsFailureCount() {
+	r := s.newRouter(errors.New("boom"), WithPrefix("events"), WithRate(0.5))
+
+	_ = r.Process(context.Background(), []byte(`{"type": "x"}`))
+
+	s.Require().Len(s.client.counts, 1)
+	s.Assert().Equal(metric{
+		name: "events.failure",
+		tags: []string{"source:test", "key:user/created"},
+		rate: 0.5,
+	}, s.client.counts[0])
+	s.Assert().Empty(s.client.timings)
+}
+
+func (s *HooksSuite) TestEmitsStageTimings() {
+	r := s.newRouter(nil, WithStageTimings())
+
+	err := r.Process(context.Background(), []byte(`{"type": "x"}`))
+
+	s.Require().NoError(err)
+	var names []string
+	for _, m := range s.client.timings {
+		names = append(names, m.name)
+	}
+	s.Assert().Equal([]string{
+		"dispatch.success",
+		"dispatch.stage.match",
+		"dispatch.stage.parse",
+		"dispatch.stage.unmarshal",
+		"dispatch.stage.validate",
+		"dispatch.stage.handle",
+		"dispatch.stage.reply",
+	}, names)
+}
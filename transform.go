@@ -0,0 +1,51 @@
+package dispatch
+
+// TransformRaw returns a Source that applies transform to each raw message
+// before source matches and parses it, so preprocessing such as base64
+// decoding, decompression, or stripping a byte order mark can wrap any
+// existing source instead of being reimplemented inside its Parse. The
+// returned source is named after source and keeps its hooks.
+//
+// The transformed bytes are matched against source's own inspector if it
+// implements InspectorProvider, or JSONInspector. A message that transform
+// returns an error for does not match. TransformRaw composes with Unwrap in
+// either order.
+//
+// Example:
+//
+//	r.AddSource(dispatch.TransformRaw(kinesisSource, func(raw []byte) ([]byte, error) {
+//	    return base64.StdEncoding.AppendDecode(nil, raw)
+//	}))
+func TransformRaw(source Source, transform func(raw []byte) ([]byte, error)) Source {
+	inner := sourceInspector(source)
+	if inner == nil {
+		inner = JSONInspector()
+	}
+	return &hookedSource{Source: source, insp: &transformInspector{
+		transform: transform,
+		inner:     inner,
+	}}
+}
+
+// transformInspector transforms a message before inspecting it. It is a
+// pointer so it can be compared by the router's view cache.
+type transformInspector struct {
+	transform func([]byte) ([]byte, error)
+	inner     Inspector
+}
+
+// Inspect returns an *unwrappedView of the transformed message.
+func (t *transformInspector) Inspect(raw []byte) (View, error) {
+	body, err := t.transform(raw)
+	if err != nil {
+		return nil, err
+	}
+	view, err := t.inner.Inspect(body)
+	if err != nil {
+		return nil, err
+	}
+	if uv, ok := view.(*unwrappedView); ok {
+		return uv, nil // the inner inspector already tracks the body
+	}
+	return &unwrappedView{View: view, body: body}, nil
+}
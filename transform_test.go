@@ -0,0 +1,110 @@
+package dispatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func decodeBase64(raw []byte) ([]byte, error) {
+	return base64.StdEncoding.AppendDecode(nil, raw)
+}
+
+func stripBOM(raw []byte) ([]byte, error) {
+	return bytes.TrimPrefix(raw, []byte("\xef\xbb\xbf")), nil
+}
+
+func encodeBase64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+type TransformRawSuite struct {
+	suite.Suite
+	msg   Message
+	value string
+}
+
+func TestTransformRawSuite(t *testing.T) {
+	suite.Run(t, new(TransformRawSuite))
+}
+
+func (s *TransformRawSuite) SetupTest() {
+	s.msg, s.value = Message{}, ""
+}
+
+func (s *TransformRawSuite) router(sources ...Source) *Router {
+	r := New()
+	for _, src := range sources {
+		r.AddSource(src)
+	}
+	RegisterProcFunc(r, "test", func(ctx context.Context, p testPayload) error {
+		s.msg, _ = MessageFromContext(ctx)
+		s.value = p.Value
+		return nil
+	})
+	return r
+}
+
+func (s *TransformRawSuite) TestTransformsBeforeMatching() {
+	r := s.router(TransformRaw(&testSource{name: "encoded"}, decodeBase64))
+
+	s.Require().NoError(r.Process(context.Background(), []byte(encodeBase64(innerMessage))))
+	s.Assert().Equal("inner", s.value)
+}
+
+func (s *TransformRawSuite) TestTransformErrorDoesNotMatch() {
+	r := s.router(TransformRaw(&testSource{name: "encoded"}, decodeBase64))
+	s.Assert().ErrorIs(r.Process(context.Background(), []byte(innerMessage)), ErrNoSource)
+
+	r = s.router(TransformRaw(&testSource{name: "encoded"}, decodeBase64), &testSource{name: "plain"})
+	s.Require().NoError(r.Process(context.Background(), []byte(innerMessage)))
+	s.Assert().Equal("inner", s.value, "later sources still match")
+}
+
+func (s *TransformRawSuite) TestStripsBOM() {
+	r := s.router(TransformRaw(&testSource{name: "bom"}, stripBOM))
+
+	s.Require().NoError(r.Process(context.Background(), []byte("\xef\xbb\xbf"+innerMessage)))
+	s.Assert().Equal("inner", s.value)
+}
+
+func (s *TransformRawSuite) TestParse() {
+	src := TransformRaw(&testSource{name: "encoded"}, decodeBase64)
+
+	msg, err := src.Parse([]byte(encodeBase64(innerMessage)))
+	s.Require().NoError(err)
+	s.Assert().Equal("test", msg.Key)
+	s.Assert().Equal("encoded", src.Name())
+
+	_, err = src.Parse([]byte("not base64!"))
+	s.Assert().Error(err)
+}
+
+func (s *TransformRawSuite) TestInsideUnwrap() {
+	// The SQS body is base64-encoded JSON.
+	r := s.router(Unwrap(TransformRaw(&testSource{name: "orders"}, decodeBase64), sqsLayer))
+
+	s.Require().NoError(r.Process(context.Background(), []byte(sqsMessage(encodeBase64(innerMessage)))))
+	s.Assert().Equal("inner", s.value)
+	s.Assert().Equal("sqs-1", s.msg.MessageID)
+}
+
+func (s *TransformRawSuite) TestAroundUnwrap() {
+	// The whole SQS record is base64-encoded.
+	r := s.router(TransformRaw(Unwrap(&testSource{name: "orders"}, sqsLayer), decodeBase64))
+
+	s.Require().NoError(r.Process(context.Background(), []byte(encodeBase64(sqsMessage(innerMessage)))))
+	s.Assert().Equal("inner", s.value)
+	s.Assert().Equal("sqs-1", s.msg.MessageID)
+}
+
+func (s *TransformRawSuite) TestKeepsSourceHooks() {
+	src := &hookedTestSource{testSource: testSource{name: "hooked"}}
+	r := s.router(TransformRaw(src, decodeBase64))
+
+	s.Require().NoError(r.Process(context.Background(), []byte(encodeBase64(innerMessage))))
+	s.Assert().True(src.parsed)
+}
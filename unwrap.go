@@ -0,0 +1,239 @@
+package dispatch
+
+import (
+	"maps"
+	"time"
+)
+
+// Unwrapper peels one envelope layer, such as an SQS message or an SNS
+// notification, off a message so the layers can be composed with Unwrap
+// instead of being parsed by one hand-written source.
+//
+// Example:
+//
+//	type snsUnwrapper struct{}
+//
+//	func (snsUnwrapper) Discriminator() dispatch.Discriminator {
+//	    return dispatch.FieldEquals("Type", "Notification")
+//	}
+//
+//	func (snsUnwrapper) Unwrap(v dispatch.View, raw []byte) (dispatch.Layer, error) {
+//	    body, _ := v.GetString("Message")
+//	    id, _ := v.GetString("MessageId")
+//	    return dispatch.Layer{Body: []byte(body), Meta: dispatch.Message{MessageID: id}}, nil
+//	}
+type Unwrapper interface {
+	// Discriminator matches messages wrapped in this envelope. It is
+	// evaluated against a View from JSONInspector.
+	Discriminator() Discriminator
+
+	// Unwrap returns the message inside the envelope and the envelope's
+	// metadata. view is the envelope's View, which the Discriminator
+	// matched.
+	Unwrap(view View, raw []byte) (Layer, error)
+}
+
+// Layer is an envelope peeled off by an Unwrapper.
+type Layer struct {
+	// Body is the message the envelope wraps.
+	Body []byte
+
+	// Meta holds the envelope's metadata, such as its MessageID,
+	// Timestamp, or Attributes. Key and Payload are ignored.
+	Meta Message
+}
+
+// UnwrapperFunc creates an Unwrapper from a discriminator and an unwrap
+// function:
+//
+//	sqsLayer := dispatch.UnwrapperFunc(
+//	    dispatch.HasFields("messageId", "body"),
+//	    func(v dispatch.View, raw []byte) (dispatch.Layer, error) {
+//	        body, _ := v.GetString("body")
+//	        id, _ := v.GetString("messageId")
+//	        return dispatch.Layer{Body: []byte(body), Meta: dispatch.Message{MessageID: id}}, nil
+//	    },
+//	)
+func UnwrapperFunc(disc Discriminator, unwrap func(view View, raw []byte) (Layer, error)) Unwrapper {
+	return &unwrapperFunc{disc: disc, unwrap: unwrap}
+}
+
+type unwrapperFunc struct {
+	disc   Discriminator
+	unwrap func(View, []byte) (Layer, error)
+}
+
+func (u *unwrapperFunc) Discriminator() Discriminator { return u.disc }
+
+func (u *unwrapperFunc) Unwrap(view View, raw []byte) (Layer, error) {
+	return u.unwrap(view, raw)
+}
+
+// Unwrap returns a Source that peels the envelopes described by unwrappers,
+// outermost first, before matching and parsing the message inside with
+// source. Each unwrapper is applied if its discriminator matches the
+// current layer and skipped otherwise, so a layer that is only sometimes
+// present, such as SNS with raw message delivery, can be listed anyway.
+// The returned source is named after source and keeps its hooks.
+//
+// Metadata accumulates from every layer: fields the source leaves empty are
+// taken from the innermost layer that sets them, and Attributes from all
+// layers are merged, with inner layers taking precedence.
+//
+// Envelopes are matched against Views from JSONInspector, and the innermost
+// message against source's own inspector if it implements InspectorProvider,
+// or JSONInspector. A layer that fails to unwrap does not match.
+//
+// Example:
+//
+//	// Lambda SQS record → SNS notification → EventBridge event
+//	r.AddSource(dispatch.Unwrap(eventbridge.NewSource(), sqsLayer, snsLayer))
+func Unwrap(source Source, unwrappers ...Unwrapper) Source {
+	inner := sourceInspector(source)
+	if inner == nil {
+		inner = JSONInspector()
+	}
+	return &hookedSource{Source: source, insp: &unwrapInspector{
+		layers: unwrappers,
+		outer:  JSONInspector(),
+		inner:  inner,
+	}}
+}
+
+// unwrapInspector peels envelopes off a message and inspects the innermost
+// one. It is a pointer so it can be compared by the router's view cache.
+type unwrapInspector struct {
+	layers []Unwrapper
+	outer  Inspector
+	inner  Inspector
+}
+
+// Inspect returns an *unwrappedView of the innermost message.
+func (u *unwrapInspector) Inspect(raw []byte) (View, error) {
+	var metas []Message
+	for _, layer := range u.layers {
+		view, err := u.outer.Inspect(raw)
+		if err != nil {
+			break // not an envelope; leave it to the inner inspector
+		}
+		if !layer.Discriminator().Match(view) {
+			continue
+		}
+		l, err := layer.Unwrap(view, raw)
+		if err != nil {
+			return nil, err
+		}
+		raw = l.Body
+		metas = append(metas, l.Meta)
+	}
+	view, err := u.inner.Inspect(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &unwrappedView{View: view, body: raw, metas: metas}, nil
+}
+
+// unwrappedView is the View of the innermost message, carrying its bytes
+// and the metadata of the layers around it for ParseView. TransformRaw uses
+// it without layers. It forwards the optional View interfaces the inner View
+// implements.
+type unwrappedView struct {
+	View
+	body  []byte
+	metas []Message // outermost first
+}
+
+func (v *unwrappedView) GetInt(path string) (int64, bool) {
+	if nv, ok := v.View.(NumberView); ok {
+		return nv.GetInt(path)
+	}
+	return 0, false
+}
+
+func (v *unwrappedView) GetFloat(path string) (float64, bool) {
+	if nv, ok := v.View.(NumberView); ok {
+		return nv.GetFloat(path)
+	}
+	return 0, false
+}
+
+func (v *unwrappedView) GetBool(path string) (bool, bool) {
+	if bv, ok := v.View.(BoolView); ok {
+		return bv.GetBool(path)
+	}
+	return false, false
+}
+
+func (v *unwrappedView) Elements(path string) ([]View, bool) {
+	if av, ok := v.View.(ArrayView); ok {
+		return av.Elements(path)
+	}
+	return nil, false
+}
+
+func (v *unwrappedView) GetView(path string) (View, bool) {
+	return GetView(v.View, path)
+}
+
+func (v *unwrappedView) GetTime(path string) (time.Time, bool) {
+	if tv, ok := v.View.(TimeView); ok {
+		return tv.GetTime(path)
+	}
+	return time.Time{}, false
+}
+
+// parse parses the innermost message with source and fills in metadata the
+// source left empty from the layers around it.
+func (v *unwrappedView) parse(source Source) (Message, error) {
+	msg, err := parseSource(source, v.View, v.body)
+	if err != nil {
+		return msg, err
+	}
+	for i := len(v.metas) - 1; i >= 0; i-- {
+		msg.inherit(v.metas[i])
+	}
+	return msg, nil
+}
+
+// inherit sets fields of m that are empty from meta, and adds attributes
+// and defaults from meta that m does not have. The earlier of the two
+// deadlines wins.
+func (m *Message) inherit(meta Message) {
+	if m.Version == "" {
+		m.Version = meta.Version
+	}
+	if m.MessageID == "" {
+		m.MessageID = meta.MessageID
+	}
+	if m.CorrelationID == "" {
+		m.CorrelationID = meta.CorrelationID
+	}
+	if m.Timestamp.IsZero() {
+		m.Timestamp = meta.Timestamp
+	}
+	if m.Deadline.IsZero() || (!meta.Deadline.IsZero() && meta.Deadline.Before(m.Deadline)) {
+		m.Deadline = meta.Deadline
+	}
+	if m.Priority == 0 {
+		m.Priority = meta.Priority
+	}
+	if m.ReplyTo == "" {
+		m.ReplyTo = meta.ReplyTo
+	}
+	if m.Lease == nil {
+		m.Lease = meta.Lease
+	}
+	if m.Replier == nil {
+		m.Replier = meta.Replier
+	}
+	if len(meta.Attributes) > 0 {
+		attrs := maps.Clone(meta.Attributes)
+		maps.Copy(attrs, m.Attributes)
+		m.Attributes = attrs
+	}
+	if len(meta.Defaults) > 0 {
+		defaults := maps.Clone(meta.Defaults)
+		maps.Copy(defaults, m.Defaults)
+		m.Defaults = defaults
+	}
+}
@@ -0,0 +1,215 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// sqsLayer unwraps {"messageId": ..., "body": "..."} records.
+var sqsLayer = UnwrapperFunc(HasFields("messageId", "body"), func(v View, raw []byte) (Layer, error) {
+	body, _ := v.GetString("body")
+	id, _ := v.GetString("messageId")
+	return Layer{Body: []byte(body), Meta: Message{
+		MessageID:  id,
+		ReplyTo:    "queue",
+		Attributes: map[string]string{"layer": "sqs", "queue": "orders"},
+	}}, nil
+})
+
+// snsLayer unwraps {"Type": "Notification", "Message": "..."} notifications.
+var snsLayer = UnwrapperFunc(FieldEquals("Type", "Notification"), func(v View, raw []byte) (Layer, error) {
+	body, ok := v.GetString("Message")
+	if !ok {
+		return Layer{}, errors.New("notification has no message")
+	}
+	id, _ := v.GetString("MessageId")
+	return Layer{Body: []byte(body), Meta: Message{
+		MessageID:  id,
+		Timestamp:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Attributes: map[string]string{"layer": "sns"},
+	}}, nil
+})
+
+// wrap returns v as a JSON string, as envelopes embed the message they wrap.
+func wrap(v string) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// hookedTestSource is a testSource with an OnParse hook.
+type hookedTestSource struct {
+	testSource
+	parsed bool
+}
+
+func (s *hookedTestSource) OnParse(ctx context.Context, key string) context.Context {
+	s.parsed = true
+	return ctx
+}
+
+type UnwrapSuite struct {
+	suite.Suite
+	router *Router
+	msg    Message
+	value  string
+}
+
+func TestUnwrapSuite(t *testing.T) {
+	suite.Run(t, new(UnwrapSuite))
+}
+
+func (s *UnwrapSuite) SetupTest() {
+	s.msg, s.value = Message{}, ""
+	s.router = New()
+	s.router.AddSource(Unwrap(&testSource{name: "orders"}, sqsLayer, snsLayer))
+	RegisterProcFunc(s.router, "test", func(ctx context.Context, p testPayload) error {
+		s.msg, _ = MessageFromContext(ctx)
+		s.value = p.Value
+		return nil
+	})
+}
+
+const innerMessage = `{"type": "test", "payload": {"value": "inner"}}`
+
+func snsMessage(inner string) string {
+	return `{"Type": "Notification", "MessageId": "sns-1", "Message": ` + wrap(inner) + `}`
+}
+
+func sqsMessage(inner string) string {
+	return `{"messageId": "sqs-1", "body": ` + wrap(inner) + `}`
+}
+
+func (s *UnwrapSuite) TestPeelsNestedEnvelopes() {
+	s.Require().NoError(s.router.Process(context.Background(), []byte(sqsMessage(snsMessage(innerMessage)))))
+
+	s.Assert().Equal("inner", s.value)
+	s.Assert().Equal("test", s.msg.Key)
+}
+
+func (s *UnwrapSuite) TestAccumulatesMetadata() {
+	s.Require().NoError(s.router.Process(context.Background(), []byte(sqsMessage(snsMessage(innerMessage)))))
+
+	s.Assert().Equal("sns-1", s.msg.MessageID, "the innermost layer that sets a field wins")
+	s.Assert().Equal("queue", s.msg.ReplyTo)
+	s.Assert().Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), s.msg.Timestamp)
+	s.Assert().Equal(map[string]string{"layer": "sns", "queue": "orders"}, s.msg.Attributes)
+}
+
+func (s *UnwrapSuite) TestSourceMetadataWins() {
+	r := New()
+	r.AddSource(Unwrap(SourceFunc("orders", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", MessageID: "own", Payload: []byte(`{}`), Attributes: map[string]string{"layer": "source"}}, nil
+	}), sqsLayer))
+	RegisterProcFunc(r, "test", func(ctx context.Context, p testPayload) error {
+		s.msg, _ = MessageFromContext(ctx)
+		return nil
+	})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(sqsMessage(innerMessage))))
+	s.Assert().Equal("own", s.msg.MessageID)
+	s.Assert().Equal(map[string]string{"layer": "source", "queue": "orders"}, s.msg.Attributes)
+}
+
+func (s *UnwrapSuite) TestEarliestDeadlineWins() {
+	early := time.Now().Add(time.Minute)
+	late := early.Add(time.Hour)
+	layer := UnwrapperFunc(HasFields("body"), func(v View, raw []byte) (Layer, error) {
+		body, _ := v.GetString("body")
+		return Layer{Body: []byte(body), Meta: Message{Deadline: early}}, nil
+	})
+	r := New()
+	r.AddSource(Unwrap(SourceFunc("orders", HasFields("type"), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Deadline: late, Payload: []byte(`{}`)}, nil
+	}), layer))
+	RegisterProcFunc(r, "test", func(ctx context.Context, p testPayload) error {
+		s.msg, _ = MessageFromContext(ctx)
+		return nil
+	})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(`{"body": `+wrap(innerMessage)+`}`)))
+	s.Assert().True(s.msg.Deadline.Equal(early))
+}
+
+func (s *UnwrapSuite) TestSkipsAbsentLayers() {
+	s.Require().NoError(s.router.Process(context.Background(), []byte(sqsMessage(innerMessage))))
+	s.Assert().Equal("sqs-1", s.msg.MessageID)
+
+	s.Require().NoError(s.router.Process(context.Background(), []byte(innerMessage)))
+	s.Assert().Equal("inner", s.value)
+}
+
+func (s *UnwrapSuite) TestInnerMessageMustMatch() {
+	err := s.router.Process(context.Background(), []byte(sqsMessage(`{"other": true}`)))
+
+	s.Assert().ErrorIs(err, ErrNoSource)
+}
+
+func (s *UnwrapSuite) TestFailedLayerDoesNotMatch() {
+	err := s.router.Process(context.Background(), []byte(`{"Type": "Notification"}`))
+
+	s.Assert().ErrorIs(err, ErrNoSource)
+}
+
+func (s *UnwrapSuite) TestOptionalViewInterfaces() {
+	r := New()
+	r.AddSource(Unwrap(SourceFunc("big", FieldGreaterThan("amount", 100), func(raw []byte) (Message, error) {
+		return Message{Key: "test", Payload: []byte(`{"value": "big"}`)}, nil
+	}), sqsLayer))
+	RegisterProcFunc(r, "test", func(ctx context.Context, p testPayload) error {
+		s.value = p.Value
+		return nil
+	})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(sqsMessage(`{"amount": 500}`))))
+	s.Assert().Equal("big", s.value)
+	s.Assert().ErrorIs(r.Process(context.Background(), []byte(sqsMessage(`{"amount": 5}`))), ErrNoSource)
+}
+
+func (s *UnwrapSuite) TestKeepsSourceHooksAndName() {
+	var source string
+	src := &hookedTestSource{testSource: testSource{name: "hooked"}}
+	r := New(WithOnParse(func(ctx context.Context, name, key string) context.Context {
+		source = name
+		return ctx
+	}))
+	r.AddSource(Unwrap(src, sqsLayer))
+	RegisterProcFunc(r, "test", func(ctx context.Context, p testPayload) error { return nil })
+
+	s.Require().NoError(r.Process(context.Background(), []byte(sqsMessage(innerMessage))))
+
+	s.Assert().True(src.parsed)
+	s.Assert().Equal("hooked", source)
+}
+
+func (s *UnwrapSuite) TestParse() {
+	src := Unwrap(&testSource{name: "orders"}, sqsLayer, snsLayer)
+
+	msg, err := src.Parse([]byte(sqsMessage(snsMessage(innerMessage))))
+	s.Require().NoError(err)
+	s.Assert().Equal("test", msg.Key)
+	s.Assert().Equal("sns-1", msg.MessageID)
+
+	_, err = src.Parse([]byte(`{"Type": "Notification"}`))
+	s.Assert().Error(err)
+}
+
+func (s *UnwrapSuite) TestWithSourceHooks() {
+	var called bool
+	r := New()
+	r.AddSource(WithSourceHooks(Unwrap(&testSource{name: "orders"}, sqsLayer), SourceHooks{
+		OnDispatch: func(ctx context.Context, key string) { called = true },
+	}))
+	RegisterProcFunc(r, "test", func(ctx context.Context, p testPayload) error {
+		s.msg, _ = MessageFromContext(ctx)
+		return nil
+	})
+
+	s.Require().NoError(r.Process(context.Background(), []byte(sqsMessage(innerMessage))))
+	s.Assert().True(called)
+	s.Assert().Equal("sqs-1", s.msg.MessageID)
+}
@@ -0,0 +1,237 @@
+package dispatch
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrWorkersStopped is returned by Submit when the worker pool hasn't been
+// started or has been stopped.
+var ErrWorkersStopped = errors.New("dispatch: workers not running")
+
+// workerPool processes submitted messages on a fixed number of goroutines,
+// highest Message.Priority first.
+type workerPool struct {
+	mu     sync.RWMutex  // held for reading while submitting, for writing to stop
+	slots  chan struct{} // one per queued message, to bound the queue
+	ready  chan struct{} // one per queued message; closed to stop workers
+	closed bool
+	wg     sync.WaitGroup
+
+	queueMu sync.Mutex // guards queue and seq
+	queue   jobQueue
+	seq     uint64
+
+	pauseMu     sync.Mutex // guards resumeAt and resumeTimer
+	resumeAt    time.Time  // workers start no messages before then
+	resumeTimer *time.Timer
+}
+
+type job struct {
+	ctx    context.Context
+	p      *parsed
+	seq    uint64 // submission order, to keep equal priorities FIFO
+	result chan<- error
+}
+
+// jobQueue is a heap of jobs ordered by descending priority, then by
+// submission order.
+type jobQueue []job
+
+func (q jobQueue) Len() int { return len(q) }
+
+func (q jobQueue) Less(i, j int) bool {
+	if pi, pj := q[i].p.msg.Priority, q[j].p.msg.Priority; pi != pj {
+		return pi > pj
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q jobQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *jobQueue) Push(x any) { *q = append(*q, x.(job)) }
+
+func (q *jobQueue) Pop() any {
+	old := *q
+	j := old[len(old)-1]
+	old[len(old)-1] = job{}
+	*q = old[:len(old)-1]
+	return j
+}
+
+// push queues j. The caller must hold a slot.
+func (p *workerPool) push(j job) {
+	p.queueMu.Lock()
+	j.seq = p.seq
+	p.seq++
+	heap.Push(&p.queue, j)
+	p.queueMu.Unlock()
+	p.ready <- struct{}{}
+}
+
+// pop removes the highest-priority job and frees its slot.
+func (p *workerPool) pop() job {
+	p.queueMu.Lock()
+	j := heap.Pop(&p.queue).(job)
+	p.queueMu.Unlock()
+	<-p.slots
+	return j
+}
+
+// StartWorkers starts n goroutines that process messages passed to Submit,
+// decoupling ingestion from processing. The queue holds up to n messages
+// waiting for a worker; when it is full, Submit blocks, applying backpressure
+// to the caller. Values of n below 1 start a single worker.
+//
+// Workers take queued messages in descending Message.Priority order, so
+// urgent commands are not stuck behind bulk backfill events. Messages with
+// equal priority are processed in the order they were submitted.
+//
+// When a handler returns a Backpressure error, every worker waits out its
+// delay before starting another message, and the WithOnPause and
+// WithOnResume hooks are called.
+//
+// Call StopWorkers to drain the queue and stop the workers. Calling
+// StartWorkers while workers are running has no effect.
+//
+// Example:
+//
+//	r.StartWorkers(runtime.GOMAXPROCS(0))
+//	defer r.StopWorkers(context.Background())
+//
+//	for msg := range consumer.Messages() {
+//	    done := r.Submit(ctx, msg.Body)
+//	    go func() {
+//	        if err := <-done; err == nil {
+//	            msg.Ack()
+//	        }
+//	    }()
+//	}
+func (r *Router) StartWorkers(n int) {
+	r.workersMu.Lock()
+	defer r.workersMu.Unlock()
+	if r.workers != nil {
+		return
+	}
+	n = max(n, 1)
+	p := &workerPool{slots: make(chan struct{}, n), ready: make(chan struct{}, n)}
+	p.wg.Add(n)
+	for range n {
+		go func() {
+			defer p.wg.Done()
+			for range p.ready {
+				j := p.pop()
+				err := p.waitPaused(j.ctx)
+				if err == nil {
+					err = r.dispatch(j.ctx, j.p)
+					r.pauseFor(j.ctx, p, err)
+				}
+				j.result <- err
+				r.end()
+			}
+		}()
+	}
+	r.workers = p
+}
+
+// Submit queues raw for processing by the workers started with StartWorkers
+// and returns a channel that receives the result of Process once it
+// finishes. The channel is buffered, so callers may ignore it.
+//
+// raw is matched and parsed before it is queued, so its priority is known;
+// if that fails, the channel receives the result right away. Submit then
+// blocks while the queue is full. If ctx is done first, the message is not
+// queued and the channel receives ctx.Err(). If the workers aren't running,
+// the channel receives ErrWorkersStopped, or ErrShutdown after Shutdown. ctx
+// is also the context the message is processed with, and opts apply to it as
+// they do for Process.
+func (r *Router) Submit(ctx context.Context, raw []byte, opts ...ProcessOption) <-chan error {
+	result := make(chan error, 1)
+
+	if !r.begin() {
+		result <- ErrShutdown
+		return result
+	}
+
+	r.workersMu.Lock()
+	p := r.workers
+	r.workersMu.Unlock()
+	if p == nil {
+		r.end()
+		result <- ErrWorkersStopped
+		return result
+	}
+
+	msg, err := r.parse(ctx, raw, newProcessConfig(opts))
+	if msg == nil {
+		r.end()
+		result <- err
+		return result
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		r.end()
+		result <- ErrWorkersStopped
+		return result
+	}
+	select {
+	case p.slots <- struct{}{}:
+		p.push(job{ctx: ctx, p: msg, result: result})
+	case <-ctx.Done():
+		r.end()
+		result <- ctx.Err()
+	}
+	return result
+}
+
+// StopWorkers stops accepting submissions and waits for queued and running
+// messages to finish. If ctx is done first, it returns ctx.Err() while the
+// workers keep draining in the background. Workers can be started again
+// after StopWorkers returns.
+func (r *Router) StopWorkers(ctx context.Context) error {
+	r.workersMu.Lock()
+	p := r.workers
+	r.workers = nil
+	r.workersMu.Unlock()
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	p.closed = true
+	close(p.ready)
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// StartWorkers starts the compiled router's worker pool. See
+// Router.StartWorkers.
+func (c *CompiledRouter) StartWorkers(n int) {
+	c.r.StartWorkers(n)
+}
+
+// Submit queues raw for the compiled router's workers. See Router.Submit.
+func (c *CompiledRouter) Submit(ctx context.Context, raw []byte, opts ...ProcessOption) <-chan error {
+	return c.r.Submit(ctx, raw, opts...)
+}
+
+// StopWorkers stops the compiled router's workers. See Router.StopWorkers.
+func (c *CompiledRouter) StopWorkers(ctx context.Context) error {
+	return c.r.StopWorkers(ctx)
+}
@@ -0,0 +1,172 @@
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WorkersSuite struct {
+	suite.Suite
+	router *Router
+}
+
+func TestWorkersSuite(t *testing.T) {
+	suite.Run(t, new(WorkersSuite))
+}
+
+func (s *WorkersSuite) SetupTest() {
+	s.router = New()
+	s.router.AddSource(&testSource{name: "test"})
+}
+
+func (s *WorkersSuite) TestSubmitProcessesMessage() {
+	var calls atomic.Int32
+	RegisterProcFunc(s.router, "test", func(ctx context.Context, p struct{}) error {
+		calls.Add(1)
+		return nil
+	})
+	s.router.StartWorkers(2)
+	defer s.router.StopWorkers(context.Background())
+
+	err := <-s.router.Submit(context.Background(), []byte(`{"type": "test", "payload": {}}`))
+
+	s.Require().NoError(err)
+	s.Assert().Equal(int32(1), calls.Load())
+}
+
+func (s *WorkersSuite) TestSubmitReturnsProcessError() {
+	wantErr := errors.New("boom")
+	RegisterProcFunc(s.router, "test", func(ctx context.Context, p struct{}) error {
+		return wantErr
+	})
+	s.router.StartWorkers(1)
+	defer s.router.StopWorkers(context.Background())
+
+	err := <-s.router.Submit(context.Background(), []byte(`{"type": "test", "payload": {}}`))
+
+	s.Assert().ErrorIs(err, wantErr)
+}
+
+func (s *WorkersSuite) TestSubmitWithoutWorkers() {
+	err := <-s.router.Submit(context.Background(), []byte(`{}`))
+
+	s.Assert().ErrorIs(err, ErrWorkersStopped)
+}
+
+func (s *WorkersSuite) TestSubmitBlocksWhenQueueFull() {
+	release := make(chan struct{})
+	RegisterProcFunc(s.router, "test", func(ctx context.Context, p struct{}) error {
+		<-release
+		return nil
+	})
+	s.router.StartWorkers(1)
+	defer s.router.StopWorkers(context.Background())
+
+	msg := []byte(`{"type": "test", "payload": {}}`)
+	running := s.router.Submit(context.Background(), msg)
+	// Wait for the worker to take the first message so the queue is empty.
+	s.Require().Eventually(func() bool { return len(s.router.workers.slots) == 0 }, time.Second, time.Millisecond)
+	queued := s.router.Submit(context.Background(), msg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := <-s.router.Submit(ctx, msg)
+	s.Assert().ErrorIs(err, context.DeadlineExceeded)
+
+	close(release)
+	s.Assert().NoError(<-running)
+	s.Assert().NoError(<-queued)
+}
+
+func (s *WorkersSuite) TestHandlesHigherPriorityFirst() {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var order []string
+	r := New()
+	r.AddSource(SourceFunc("test", HasFields("id"), func(raw []byte) (Message, error) {
+		var env struct {
+			ID       string `json:"id"`
+			Priority int    `json:"priority"`
+		}
+		err := json.Unmarshal(raw, &env)
+		return Message{Key: "test", MessageID: env.ID, Priority: env.Priority, Payload: []byte(`{}`)}, err
+	}))
+	RegisterProcFunc(r, "test", func(ctx context.Context, p struct{}) error {
+		mu.Lock()
+		order = append(order, MessageID(ctx))
+		mu.Unlock()
+		if MessageID(ctx) == "busy" {
+			<-release
+		}
+		return nil
+	})
+	r.StartWorkers(2)
+	defer r.StopWorkers(context.Background())
+
+	busy := []byte(`{"id": "busy"}`)
+	results := []<-chan error{r.Submit(context.Background(), busy), r.Submit(context.Background(), busy)}
+	s.Require().Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(order) == 2
+	}, time.Second, time.Millisecond)
+	results = append(results,
+		r.Submit(context.Background(), []byte(`{"id": "bulk"}`)),
+		r.Submit(context.Background(), []byte(`{"id": "urgent", "priority": 10}`)),
+	)
+
+	release <- struct{}{}
+	s.Require().NoError(<-results[3])
+	s.Require().NoError(<-results[2])
+	close(release)
+	for _, result := range results[:2] {
+		s.Require().NoError(<-result)
+	}
+
+	s.Assert().Equal([]string{"busy", "busy", "urgent", "bulk"}, order)
+}
+
+func (s *WorkersSuite) TestStopWorkersDrainsQueue() {
+	var calls atomic.Int32
+	RegisterProcFunc(s.router, "test", func(ctx context.Context, p struct{}) error {
+		time.Sleep(time.Millisecond)
+		calls.Add(1)
+		return nil
+	})
+	s.router.StartWorkers(2)
+
+	var results []<-chan error
+	for range 6 {
+		results = append(results, s.router.Submit(context.Background(), []byte(`{"type": "test", "payload": {}}`)))
+	}
+	s.Require().NoError(s.router.StopWorkers(context.Background()))
+
+	s.Assert().Equal(int32(6), calls.Load())
+	for _, result := range results {
+		s.Assert().NoError(<-result)
+	}
+	s.Assert().ErrorIs(<-s.router.Submit(context.Background(), []byte(`{}`)), ErrWorkersStopped)
+}
+
+func (s *WorkersSuite) TestStopWorkersHonorsDeadline() {
+	release := make(chan struct{})
+	defer close(release)
+	RegisterProcFunc(s.router, "test", func(ctx context.Context, p struct{}) error {
+		<-release
+		return nil
+	})
+	s.router.StartWorkers(1)
+	s.router.Submit(context.Background(), []byte(`{"type": "test", "payload": {}}`))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	s.Assert().ErrorIs(s.router.StopWorkers(ctx), context.DeadlineExceeded)
+}